@@ -2,8 +2,12 @@ package main
 
 import (
 	"database/sql"
+	"flag"
 	"fmt"
+	"io"
 	stdlog "log"
+	"net/http"
+	"net/url"
 	"os"
 	"os/signal"
 	"strconv"
@@ -12,12 +16,18 @@ import (
 	"time"
 
 	"daemon-go/internal/app"
+	"daemon-go/internal/backtest"
 	"daemon-go/internal/bus"
+	"daemon-go/internal/capture"
 	"daemon-go/internal/config"
 	"daemon-go/internal/db"
 	"daemon-go/internal/exchange"
+	"daemon-go/internal/exchange/mockexchange"
 	"daemon-go/internal/market"
+	"daemon-go/internal/orders"
+	"daemon-go/internal/risk"
 	"daemon-go/internal/state"
+	"daemon-go/internal/worker"
 	"daemon-go/pkg/log"
 )
 
@@ -146,6 +156,247 @@ func testHTXAdapter() {
 	fmt.Printf("✅ Тестирование завершено\n")
 }
 
+// testMockExchanges прогоняет адаптеры против локальных имитаций бирж:
+// Start, подписка, получение стакана, разрыв соединения, переподключение и повторная подписка
+func testMockExchanges(names []string) {
+	protocols := mockexchange.Protocols
+	if len(names) > 0 {
+		protocols = protocols[:0:0]
+		for _, name := range names {
+			protocols = append(protocols, mockexchange.Protocol(strings.ToLower(name)))
+		}
+	}
+
+	failed := 0
+	for _, protocol := range protocols {
+		if err := runMockExchange(protocol); err != nil {
+			failed++
+			fmt.Printf("❌ %s: %v\n", protocol, err)
+		} else {
+			fmt.Printf("✅ %s: start, subscribe, parse, reconnect - OK\n", protocol)
+		}
+	}
+
+	fmt.Printf("📊 Итого: %d из %d бирж прошли проверку\n", len(protocols)-failed, len(protocols))
+	if failed > 0 {
+		os.Exit(1)
+	}
+}
+
+// runMockExchange проверяет один адаптер против имитации биржи
+func runMockExchange(protocol mockexchange.Protocol) error {
+	srv := mockexchange.NewDefaultServer(protocol)
+	defer srv.Close()
+
+	ex := srv.Exchange()
+	messageBus := bus.GetInstance()
+	messages := messageBus.Subscribe(ex.Name, 100)
+	defer messageBus.Unsubscribe(ex.Name, messages)
+
+	adapter := exchange.NewAdapter(ex)
+	if err := adapter.Start(); err != nil {
+		return fmt.Errorf("start: %w", err)
+	}
+	defer adapter.Stop()
+
+	pair := mockexchange.DefaultPair(protocol)
+	if err := adapter.SubscribeMarkets([]string{pair}, "spot", 5); err != nil {
+		return fmt.Errorf("subscribe: %w", err)
+	}
+	if err := waitOrderBook(messages, 5*time.Second); err != nil {
+		return fmt.Errorf("before reconnect: %w", err)
+	}
+
+	// Разрыв соединения: адаптер должен переподключиться и повторить подписку
+	srv.DropConnections()
+	if !srv.WaitConnections(2, 15*time.Second) {
+		return fmt.Errorf("adapter did not reconnect")
+	}
+	if err := waitOrderBook(messages, 10*time.Second); err != nil {
+		return fmt.Errorf("after reconnect: %w", err)
+	}
+	return nil
+}
+
+// waitOrderBook ждет orderbook и trade сообщения из шины
+func waitOrderBook(messages chan market.UnifiedMessage, timeout time.Duration) error {
+	deadline := time.After(timeout)
+	var book, trade bool
+	for !book || !trade {
+		select {
+		case msg := <-messages:
+			switch msg.MessageType {
+			case market.MessageTypeOrderBook:
+				book = true
+			case market.MessageTypeTrade:
+				trade = true
+			}
+		case <-deadline:
+			if !book {
+				return fmt.Errorf("no orderbook message within %v", timeout)
+			}
+			return fmt.Errorf("no trade message within %v", timeout)
+		}
+	}
+	return nil
+}
+
+// replayCapture воспроизводит запись трафика через парсеры в шину сообщений.
+// Сообщения обрабатывает TradeWorker в режиме мониторинга, по окончании выводится его статистика.
+// Использование: ctdaemon replay <file> [speed], speed: 1 - исходная скорость (по умолчанию), 0 - без пауз
+func replayCapture(args []string) {
+	if len(args) < 1 {
+		fmt.Printf("Usage: ctdaemon replay <file> [speed]\n")
+		os.Exit(2)
+	}
+	path := args[0]
+	speed := 1.0
+	if len(args) > 1 {
+		v, err := strconv.ParseFloat(args[1], 64)
+		if err != nil || v < 0 {
+			fmt.Printf("❌ Invalid speed %q: expected number >= 0\n", args[1])
+			os.Exit(2)
+		}
+		speed = v
+	}
+
+	tradeWorker := worker.NewTradeWorker(worker.DefaultTradeWorkerConfig())
+	if err := tradeWorker.Start(); err != nil {
+		fmt.Printf("❌ Failed to start trade worker: %v\n", err)
+		os.Exit(1)
+	}
+	defer tradeWorker.Stop()
+
+	fmt.Printf("▶️  Replaying %s at speed %v...\n", path, speed)
+	stats, err := capture.NewReplayer(speed).Replay(path)
+	if err != nil {
+		fmt.Printf("❌ Replay failed: %v\n", err)
+		if stats == nil {
+			os.Exit(1)
+		}
+	}
+
+	// Даем воркеру обработать хвост очереди и пройти цикл поиска арбитража
+	time.Sleep(2 * time.Second)
+
+	fmt.Printf("📊 Replay: %s\n", stats)
+	for exchange, count := range stats.ByExchange {
+		fmt.Printf("   %s: %d messages\n", exchange, count)
+	}
+	fmt.Printf("📈 TradeWorker: %+v\n", tradeWorker.GetStats())
+	for _, opp := range tradeWorker.GetOpportunities() {
+		fmt.Printf("   %s buy %s @ %.8f, sell %s @ %.8f, profit %.4f%%\n",
+			opp.Symbol, opp.BuyExchange, opp.BuyPrice, opp.SellExchange, opp.SellPrice, opp.ProfitPercent)
+	}
+}
+
+// runBacktest прогоняет арбитражную стратегию по истории PRICE_SPOT_LOG
+func runBacktest(args []string) {
+	fs := flag.NewFlagSet("backtest", flag.ExitOnError)
+	pairsArg := fs.String("pairs", "", "comma-separated SPOT_TRADE_PAIR IDs")
+	fromArg := fs.String("from", "", "period start (RFC3339 or 2006-01-02)")
+	toArg := fs.String("to", "", "period end (RFC3339 or 2006-01-02)")
+	fee := fs.Float64("fee", 0.001, "taker fee rate for exchanges without EXCHANGE_FEE rows")
+	latency := fs.Duration("latency", 200*time.Millisecond, "order arrival latency")
+	fill := fs.Float64("fill", 1, "share of level volume available to us (0..1]")
+	minProfit := fs.Float64("min-profit", 0, "minimum profit percent (0 - worker default)")
+	withRisk := fs.Bool("risk", false, "apply [risk] limits from config")
+	verbose := fs.Bool("v", false, "log every opportunity and execution")
+	fs.Parse(args)
+
+	pairIDs, err := parsePairIDs(*pairsArg)
+	if err != nil || len(pairIDs) == 0 {
+		fmt.Printf("Usage: ctdaemon backtest --pairs 1,2 --from 2025-01-01 --to 2025-01-02 [--fee 0.001] [--latency 200ms] [--fill 1] [--min-profit 0.1] [--risk]\n")
+		os.Exit(2)
+	}
+	from, errFrom := parseBacktestTime(*fromArg)
+	to, errTo := parseBacktestTime(*toArg)
+	if errFrom != nil || errTo != nil {
+		fmt.Printf("❌ Invalid period: from=%q to=%q\n", *fromArg, *toArg)
+		os.Exit(2)
+	}
+
+	const cfgPath = "config/config.conf"
+	cfg, err := config.LoadConfig(cfgPath)
+	if err != nil {
+		fmt.Printf("❌ Failed to load config (%s): %v\n", cfgPath, err)
+		os.Exit(1)
+	}
+
+	if !*verbose {
+		log.SetGlobalLevel(log.WarnLevel)
+		stdlog.SetOutput(io.Discard)
+	}
+
+	driver, err := db.NewDriver(cfg.Database.Type, map[string]string{
+		"host":     cfg.Database.Host,
+		"port":     strconv.Itoa(cfg.Database.Port),
+		"user":     cfg.Database.User,
+		"password": cfg.Database.Password,
+		"database": cfg.Database.Database,
+	})
+	if err != nil {
+		fmt.Printf("❌ Failed to create DB driver: %v\n", err)
+		os.Exit(1)
+	}
+	if err := driver.Connect(); err != nil {
+		fmt.Printf("❌ Failed to connect to DB: %v\n", err)
+		os.Exit(1)
+	}
+	defer driver.Close()
+
+	btConfig := backtest.DefaultConfig()
+	btConfig.PairIDs = pairIDs
+	btConfig.From = from
+	btConfig.To = to
+	btConfig.FeeRate = *fee
+	if fees, err := worker.LoadFeeSchedule(driver, market.FeeRate{Maker: *fee, Taker: *fee}); err != nil {
+		fmt.Printf("⚠️  Exchange fees not loaded, using %v for all pairs: %v\n", *fee, err)
+	} else {
+		btConfig.Fees = fees
+	}
+	btConfig.Latency = *latency
+	btConfig.FillRatio = *fill
+	if *minProfit > 0 {
+		btConfig.Worker.MinProfitPercent = *minProfit
+	}
+	if *withRisk {
+		limits := risk.LimitsFromConfig(cfg)
+		btConfig.Risk = &limits
+	}
+
+	fmt.Printf("⏪ Backtesting pairs %v from %s to %s...\n", pairIDs, from.Format(time.RFC3339), to.Format(time.RFC3339))
+	report, err := backtest.NewEngine(driver, btConfig).Run()
+	if err != nil {
+		fmt.Printf("❌ Backtest failed: %v\n", err)
+		os.Exit(1)
+	}
+	report.Print(os.Stdout)
+}
+
+func parsePairIDs(s string) ([]int, error) {
+	var ids []int
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		id, err := strconv.Atoi(part)
+		if err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+func parseBacktestTime(s string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	return time.ParseInLocation("2006-01-02", s, time.Local)
+}
+
 func handleStartCommand() {
 	// Проверяем, не запущен ли уже daemon
 	stateFile := "state/daemon.state"
@@ -239,6 +490,101 @@ func handleStatusCommand() {
 	}
 }
 
+// handleHaltCommand включает kill switch: блокирует новые ордера, отменяет открытые ордера
+// на всех аккаунтах бирж и сохраняет флаг halted. Если демон запущен, команда передается
+// ему через API, иначе выполняется локально.
+// Использование: ctdaemon halt [reason]
+func handleHaltCommand(args []string) {
+	const cfgPath = "config/config.conf"
+
+	cfg, err := config.LoadConfig(cfgPath)
+	if err != nil {
+		fmt.Printf("Failed to load config: %v\n", err)
+		os.Exit(1)
+	}
+	reason := strings.Join(args, " ")
+
+	if body, ok := daemonAction(cfg, "halt", url.Values{"reason": {reason}}); ok {
+		fmt.Printf("Daemon halted: %s\n", strings.TrimSpace(body))
+		return
+	}
+
+	fmt.Printf("Daemon is not running, halting locally...\n")
+	driver, err := db.NewDriver(cfg.Database.Type, map[string]string{
+		"host":     cfg.Database.Host,
+		"port":     strconv.Itoa(cfg.Database.Port),
+		"user":     cfg.Database.User,
+		"password": cfg.Database.Password,
+		"database": cfg.Database.Database,
+	})
+	var accounts risk.AccountSource
+	var symbols risk.OpenSymbolSource
+	if err == nil {
+		err = driver.Connect()
+	}
+	if err != nil {
+		// Флаг сохраняется в любом случае: демон не начнет торговать после запуска
+		fmt.Printf("⚠️  DB unavailable, open orders are NOT canceled: %v\n", err)
+	} else {
+		defer driver.Close()
+		accounts = risk.AccountsFromDB(driver)
+		symbols = orders.NewDBJournal(driver).OpenSymbols
+	}
+
+	killSwitch := risk.NewKillSwitch(cfg.Daemon.StateFile, accounts)
+	killSwitch.SetOpenSymbols(symbols)
+	report := killSwitch.Halt(reason)
+	for _, acc := range report.Accounts {
+		fmt.Printf("   %s account %d: canceled %d of %d\n", acc.Exchange, acc.AccountID, acc.Canceled, acc.Open)
+		for _, e := range acc.Errors {
+			fmt.Printf("      ❌ %s\n", e)
+		}
+	}
+	fmt.Printf("🛑 Trading halted: %d of %d open orders canceled, %d accounts with errors\n",
+		report.Canceled, report.Open, report.Failed)
+	if report.Failed > 0 {
+		os.Exit(1)
+	}
+}
+
+// handleRearmCommand выключает kill switch и разрешает новые ордера.
+// Использование: ctdaemon rearm
+func handleRearmCommand() {
+	const cfgPath = "config/config.conf"
+
+	cfg, err := config.LoadConfig(cfgPath)
+	if err != nil {
+		fmt.Printf("Failed to load config: %v\n", err)
+		os.Exit(1)
+	}
+
+	if body, ok := daemonAction(cfg, "rearm", nil); ok {
+		fmt.Print(body)
+		return
+	}
+	risk.NewKillSwitch(cfg.Daemon.StateFile, nil).Rearm()
+	fmt.Printf("Trading re-armed\n")
+}
+
+// daemonAction выполняет POST /daemon?action=... на запущенном демоне; params передаются формой.
+// ok=false, если демон не запущен (API недоступен)
+func daemonAction(cfg *config.Config, action string, params url.Values) (string, bool) {
+	if params == nil {
+		params = url.Values{}
+	}
+	client := &http.Client{Timeout: 60 * time.Second}
+	resp, err := client.PostForm(fmt.Sprintf("http://127.0.0.1:%d/daemon?action=%s", cfg.Daemon.HttpPort, url.QueryEscape(action)), params)
+	if err != nil {
+		return "", false
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", false
+	}
+	return string(body), true
+}
+
 func findDaemonProcess() (*os.Process, error) {
 	// Читаем список процессов
 	processes, err := os.ReadDir("/proc")
@@ -306,6 +652,7 @@ func mainDaemon() {
 	exchange.SetOrderBookConfig(cfg)
 	fmt.Printf("[LOG][DEBUG] OrderBook config set: DebugLogRaw=%t, DebugLogMsg=%t\n",
 		cfg.OrderBook.DebugLogRaw, cfg.OrderBook.DebugLogMsg)
+	exchange.SetFuturesEndpoints(cfg.Futures.Endpoints)
 
 	// Выбор режима логирования: global или modular
 	if cfg.Logging.Mode == "modular" {
@@ -398,6 +745,15 @@ func mainDaemon() {
 		logger.Fatal("db connect failed: %v", lastErr)
 	}
 
+	// Запись сырого трафика бирж для ctdaemon replay
+	if cfg.Capture.Enabled {
+		if _, err := capture.Start(cfg.Capture.Dir, cfg.Capture.Compress); err != nil {
+			logger.Error("Failed to start traffic capture: %v", err)
+		} else {
+			defer capture.Stop()
+		}
+	}
+
 	logger.Debug("[DEBUG] Initializing Manager...")
 	manager := app.NewManager(cfg, driver, logger)
 	logger.Debug("[DEBUG] Manager initialized: %+v", manager)
@@ -463,6 +819,7 @@ func mainDaemonWithAutoStart() {
 	exchange.SetOrderBookConfig(cfg)
 	fmt.Printf("[LOG][DEBUG] OrderBook config set: DebugLogRaw=%t, DebugLogMsg=%t\n",
 		cfg.OrderBook.DebugLogRaw, cfg.OrderBook.DebugLogMsg)
+	exchange.SetFuturesEndpoints(cfg.Futures.Endpoints)
 
 	// Выбор режима логирования: global или modular
 	if cfg.Logging.Mode == "modular" {
@@ -511,9 +868,8 @@ func mainDaemonWithAutoStart() {
 			log.Close()
 		}
 
-		// При завершении daemon сбрасываем состояние на inactive
-		daemonState := &state.DaemonState{Active: false}
-		state.SaveState(cfg.Daemon.StateFile, daemonState)
+		// При завершении daemon сбрасываем состояние на inactive (флаг kill switch сохраняется)
+		state.SetActive(cfg.Daemon.StateFile, false)
 		log.Close()
 	}()
 
@@ -555,6 +911,15 @@ func mainDaemonWithAutoStart() {
 		logger.Fatal("db connect failed: %v", lastErr)
 	}
 
+	// Запись сырого трафика бирж для ctdaemon replay
+	if cfg.Capture.Enabled {
+		if _, err := capture.Start(cfg.Capture.Dir, cfg.Capture.Compress); err != nil {
+			logger.Error("Failed to start traffic capture: %v", err)
+		} else {
+			defer capture.Stop()
+		}
+	}
+
 	logger.Debug("[DEBUG] Initializing Manager...")
 	manager := app.NewManager(cfg, driver, logger)
 	logger.Debug("[DEBUG] Manager initialized: %+v", manager)
@@ -617,6 +982,15 @@ func main() {
 		case "test-htx":
 			testHTXAdapter()
 			return
+		case "test-mock":
+			testMockExchanges(os.Args[2:])
+			return
+		case "replay":
+			replayCapture(os.Args[2:])
+			return
+		case "backtest":
+			runBacktest(os.Args[2:])
+			return
 		case "start":
 			handleStartCommand()
 			return
@@ -626,6 +1000,12 @@ func main() {
 		case "status":
 			handleStatusCommand()
 			return
+		case "halt":
+			handleHaltCommand(os.Args[2:])
+			return
+		case "rearm":
+			handleRearmCommand()
+			return
 		}
 	}
 
@@ -6,7 +6,11 @@ import (
 	"net/http"
 	"sync"
 
+	"daemon-go/internal/balance"
 	"daemon-go/internal/db"
+	"daemon-go/internal/exchange"
+	"daemon-go/internal/orders"
+	"daemon-go/internal/risk"
 	"daemon-go/internal/worker"
 	"daemon-go/pkg/log"
 )
@@ -30,10 +34,13 @@ type Server struct {
 	stopWork       func()
 	logger         *log.Logger
 	getDataMonitor func() *worker.DataMonitor // изменено на функцию getter
+	getBalances    func() *balance.Service
+	getOrders      func() *orders.Manager
+	killSwitch     *risk.KillSwitch
 }
 
 // NewServer создаёт новый API-сервер
-func NewServer(cfg ServerConfig, driver db.DBDriver, traderWorkers map[int]*worker.TraderWorker, workersMutex *sync.Mutex, stopChan chan struct{}, reloadConfig func(string) error, startWork func() error, stopWork func(), getDataMonitor func() *worker.DataMonitor) *Server {
+func NewServer(cfg ServerConfig, driver db.DBDriver, traderWorkers map[int]*worker.TraderWorker, workersMutex *sync.Mutex, stopChan chan struct{}, reloadConfig func(string) error, startWork func() error, stopWork func(), getDataMonitor func() *worker.DataMonitor, getBalances func() *balance.Service, getOrders func() *orders.Manager, killSwitch *risk.KillSwitch) *Server {
 	logger := log.New("api")
 	return &Server{
 		cfg:            cfg,
@@ -46,6 +53,9 @@ func NewServer(cfg ServerConfig, driver db.DBDriver, traderWorkers map[int]*work
 		stopWork:       stopWork,
 		logger:         logger,
 		getDataMonitor: getDataMonitor,
+		getBalances:    getBalances,
+		getOrders:      getOrders,
+		killSwitch:     killSwitch,
 	}
 }
 
@@ -115,7 +125,27 @@ func (s *Server) handleStatus(w http.ResponseWriter, _ *http.Request) {
 		workersInfo := dataMonitor.GetWorkersInfo()
 		status["data_workers"] = workersInfo
 		s.logger.Debug("[API][DEBUG] DataWorkers info: %d workers", len(workersInfo))
-	} // Статус демона: RUNNING если есть активные воркеры, иначе STOPPED
+	}
+
+	// Метрики ресинхронизации стаканов (разрывы последовательности обновлений)
+	status["orderbook_resyncs"] = exchange.GetResyncStats()
+
+	// Балансы аккаунтов бирж
+	if balances := s.getBalances(); balances != nil {
+		status["balances"] = balances.GetStats()
+	}
+
+	// Журнал ордеров
+	if orderManager := s.getOrders(); orderManager != nil {
+		status["orders"] = orderManager.GetStats()
+	}
+
+	// Состояние kill switch
+	if s.killSwitch != nil {
+		status["kill_switch"] = s.killSwitch.GetStats()
+	}
+
+	// Статус демона: RUNNING если есть активные воркеры, иначе STOPPED
 	if activeCount > 0 {
 		status["daemon_status"] = "RUNNING"
 	} else {
@@ -129,10 +159,16 @@ func (s *Server) handleStatus(w http.ResponseWriter, _ *http.Request) {
 	}
 }
 
-// handleDaemon управляет демоном (stop/reload)
+// handleDaemon управляет демоном (stop/reload/start/halt/rearm).
+// halt и rearm меняют состояние торговли и принимаются только методом POST
 func (s *Server) handleDaemon(w http.ResponseWriter, r *http.Request) {
 	action := r.URL.Query().Get("action")
 	s.logger.Debug("[API][DEBUG] handleDaemon action=%s", action)
+	if (action == "halt" || action == "rearm") && r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "Method not allowed: use POST", http.StatusMethodNotAllowed)
+		return
+	}
 	switch action {
 	case "stop":
 		s.logger.Debug("[API][DEBUG] Stopping business logic via API (stopWork)")
@@ -174,6 +210,26 @@ func (s *Server) handleDaemon(w http.ResponseWriter, r *http.Request) {
 		} else {
 			fmt.Fprintln(w, "StopWork not supported")
 		}
+	case "halt":
+		if s.killSwitch == nil {
+			fmt.Fprintln(w, "Halt not supported")
+			return
+		}
+		reason := r.FormValue("reason")
+		s.logger.Warn("[API] Kill switch requested from %s: %s", r.RemoteAddr, reason)
+		report := s.killSwitch.Halt(reason)
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(report); err != nil {
+			s.logger.Debug("[API][DEBUG] JSON encode error: %v", err)
+		}
+	case "rearm":
+		if s.killSwitch == nil {
+			fmt.Fprintln(w, "Rearm not supported")
+			return
+		}
+		s.logger.Warn("[API] Re-arm requested from %s", r.RemoteAddr)
+		s.killSwitch.Rearm()
+		fmt.Fprintln(w, "Trading re-armed")
 	default:
 		s.logger.Debug("[API][DEBUG] Unknown action: %s", action)
 		fmt.Fprintln(w, "Unknown action")
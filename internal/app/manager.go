@@ -3,43 +3,65 @@ package app
 import (
 	"context"
 	"fmt"
+	"strings"
 	"sync"
 	"time"
 
 	"daemon-go/internal/api"
+	"daemon-go/internal/balance"
+	"daemon-go/internal/bus"
+	"daemon-go/internal/catalog"
 	"daemon-go/internal/config"
 	"daemon-go/internal/db"
+	"daemon-go/internal/exchange"
+	"daemon-go/internal/market"
+	"daemon-go/internal/orders"
+	"daemon-go/internal/rebalance"
+	"daemon-go/internal/risk"
 	"daemon-go/internal/service"
 	"daemon-go/internal/state"
 	"daemon-go/internal/worker"
+	"daemon-go/internal/worker/executor"
 	"daemon-go/pkg/log"
 )
 
 type Manager struct {
-	cfg           *config.Config
-	db            db.DBDriver
-	logger        *log.Logger
-	apiServer     *api.Server
-	serviceDaemon *service.Daemon
-	tradeMonitor  *worker.TradeMonitor
-	dataMonitor   *worker.DataMonitor
-	priceMonitor  *worker.PriceMonitor
-	traderWorkers map[int]*worker.TraderWorker
-	workersMutex  sync.Mutex
-	stopChan      chan struct{}
-	stopOnce      sync.Once
-	ctx           context.Context
-	cancel        context.CancelFunc
-	workStarted   bool // singleton-флаг
+	cfg            *config.Config
+	db             db.DBDriver
+	logger         *log.Logger
+	apiServer      *api.Server
+	serviceDaemon  *service.Daemon
+	tradeMonitor   *worker.TradeMonitor
+	dataMonitor    *worker.DataMonitor
+	priceMonitor   *worker.PriceMonitor
+	candleMonitor  *worker.CandleMonitor
+	fundingMonitor *worker.FundingMonitor
+	killSwitch     *risk.KillSwitch
+	riskEngine     *risk.Engine // общий риск-движок: лимиты бирж и аккаунтов для всех трейдер-воркеров
+	balances       *balance.Service
+	orders         *orders.Manager
+	symbolInfo     *exchange.SymbolInfoCache
+	userData       []exchange.UserDataAdapter         // приватные потоки аккаунтов из сервиса балансов
+	gateways       map[string]exchange.TradingAdapter // [exchange] торговые адаптеры аккаунтов для исполнителей
+	traderWorkers  map[int]*worker.TraderWorker
+	workersMutex   sync.Mutex
+	stopChan       chan struct{}
+	stopOnce       sync.Once
+	ctx            context.Context
+	cancel         context.CancelFunc
+	workStarted    bool // singleton-флаг
 }
 
 func NewManager(cfg *config.Config, dbDriver db.DBDriver, logger *log.Logger) *Manager {
 	ctx, cancel := context.WithCancel(context.Background())
+	killSwitch := risk.NewKillSwitch(cfg.Daemon.StateFile, risk.AccountsFromDB(dbDriver))
+	killSwitch.SetOpenSymbols(orders.NewDBJournal(dbDriver).OpenSymbols)
 	return &Manager{
 		cfg:           cfg,
 		db:            dbDriver,
 		logger:        logger,
 		traderWorkers: make(map[int]*worker.TraderWorker),
+		killSwitch:    killSwitch,
 		stopChan:      make(chan struct{}),
 		ctx:           ctx,
 		cancel:        cancel,
@@ -86,9 +108,11 @@ func (m *Manager) Start() {
 	startWork := func() error { return m.StartWork() }
 	stopWork := func() { m.StopWork() }
 	getDataMonitor := func() *worker.DataMonitor { return m.dataMonitor }
+	getBalances := func() *balance.Service { return m.balances }
+	getOrders := func() *orders.Manager { return m.orders }
 	m.logger.Debug("[START][DEBUG] Creating API server with config: %+v", apiCfg)
 	m.logger.Info("[START] Initializing API server on :%d", apiCfg.Port)
-	m.apiServer = api.NewServer(apiCfg, m.db, m.traderWorkers, &m.workersMutex, m.stopChan, reloadConfig, startWork, stopWork, getDataMonitor)
+	m.apiServer = api.NewServer(apiCfg, m.db, m.traderWorkers, &m.workersMutex, m.stopChan, reloadConfig, startWork, stopWork, getDataMonitor, getBalances, getOrders, m.killSwitch)
 	go func() {
 		m.logger.Debug("[START][DEBUG] API server goroutine about to start")
 		m.logger.Info("[START] API server goroutine started")
@@ -98,6 +122,24 @@ func (m *Manager) Start() {
 	m.logger.Info("[START] Manager initialized: API and service daemon running, waiting for start command...")
 }
 
+// KillSwitch возвращает глобальный kill switch; StartWork подключает его к риск-движкам
+// всех трейдер-воркеров через TradingEnv
+func (m *Manager) KillSwitch() *risk.KillSwitch {
+	return m.killSwitch
+}
+
+// Orders возвращает менеджер ордеров (nil, пока работа не запущена); StartWork
+// подключает его к исполнителям трейдер-воркеров через TradingEnv
+func (m *Manager) Orders() *orders.Manager {
+	return m.orders
+}
+
+// SymbolInfo возвращает кэш торговых правил символов (nil, пока работа не запущена);
+// StartWork подключает его к исполнителям трейдер-воркеров через TradingEnv
+func (m *Manager) SymbolInfo() *exchange.SymbolInfoCache {
+	return m.symbolInfo
+}
+
 // StartWork запускает бизнес-логику: TradeMonitor и трейдер-воркеры
 func (m *Manager) StartWork() error {
 	if m.workStarted {
@@ -107,6 +149,9 @@ func (m *Manager) StartWork() error {
 	m.workStarted = true
 	// Сохраняем состояние: Active=true
 	state.SetActive(m.cfg.Daemon.StateFile, true)
+	if halted, reason := m.killSwitch.IsHalted(); halted {
+		m.logger.Warn("[WORK] Kill switch is engaged (%s): new orders are blocked until re-arm", reason)
+	}
 	m.logger.Info("[WORK] Starting ServiceDaemon...")
 	m.logger.Debug("[WORK][DEBUG] Creating ServiceDaemon with db=%T", m.db)
 	m.serviceDaemon = service.NewDaemon(m.db)
@@ -117,15 +162,6 @@ func (m *Manager) StartWork() error {
 	}()
 
 	m.logger.Info("[WORK] Starting TradeMonitor, DataMonitor и trader workers...")
-	// TradeMonitor
-	m.logger.Info("[WORK] Initializing TradeMonitor (pollInterval=%d)", m.cfg.Daemon.PollInterval)
-	m.logger.Debug("[WORK][DEBUG] Creating TradeMonitor with db=%T, traderWorkers=%d", m.db, len(m.traderWorkers))
-	m.tradeMonitor = worker.NewTradeMonitor(m.db, &m.traderWorkers, &m.workersMutex, m.stopChan, m.cfg.Daemon.PollInterval)
-	go func() {
-		m.logger.Debug("[WORK][DEBUG] TradeMonitor goroutine about to start")
-		m.logger.Info("[WORK] TradeMonitor goroutine started")
-		m.tradeMonitor.Start()
-	}()
 	// DataMonitor
 	m.logger.Info("[WORK] Initializing DataMonitor...")
 	m.dataMonitor = worker.NewDataMonitor(m.logger, m.db)
@@ -146,10 +182,198 @@ func (m *Manager) StartWork() error {
 		}
 	}()
 
+	// Свечи пар мониторинга
+	if m.cfg.Candles.Enabled {
+		m.logger.Info("[WORK] Initializing CandleMonitor (intervals=%s, flush=%ds)...", m.cfg.Candles.Intervals, m.cfg.Candles.FlushInterval)
+		m.candleMonitor = worker.NewCandleMonitor(m.db, m.candleIntervals(), time.Duration(m.cfg.Candles.FlushInterval)*time.Second)
+		candleMonitor := m.candleMonitor
+		go func() {
+			if err := candleMonitor.Start(); err != nil {
+				m.logger.Error("Failed to start CandleMonitor: %v", err)
+			}
+		}()
+	}
+
+	// Ставки финансирования бессрочных контрактов
+	if m.cfg.Funding.Enabled {
+		m.logger.Info("[WORK] Initializing FundingMonitor (flush=%ds)...", m.cfg.Funding.FlushInterval)
+		m.fundingMonitor = worker.NewFundingMonitor(m.db, time.Duration(m.cfg.Funding.FlushInterval)*time.Second)
+		if err := m.fundingMonitor.Start(); err != nil {
+			m.logger.Error("Failed to start FundingMonitor: %v", err)
+		}
+	}
+
+	// Балансы аккаунтов бирж
+	m.logger.Info("[WORK] Initializing balance service (refresh=%ds)...", m.cfg.Balance.RefreshInterval)
+	m.orders = orders.NewManager(orders.NewDBJournal(m.db))
+	m.symbolInfo = exchange.NewSymbolInfoCache(time.Hour)
+	m.balances = m.newBalanceService()
+	orderManager := m.orders
+	symbolInfo := m.symbolInfo
+	userData := m.userData
+	if m.cfg.Rebalance.Enabled {
+		m.logger.Info("[WORK] Enabling rebalance planner (assets=%s, interval=%ds)", m.cfg.Rebalance.Assets, m.cfg.Rebalance.Interval)
+		m.serviceDaemon.SetRebalancer(rebalance.NewPlanner(rebalance.ConfigFromApp(m.cfg), m.balances, rebalance.NewDBStore(m.db)))
+	}
+	if m.cfg.Catalog.Enabled {
+		m.logger.Info("[WORK] Enabling symbol catalog sync (interval=%ds, quotes=%s)", m.cfg.Catalog.Interval, m.cfg.Catalog.Quotes)
+		m.serviceDaemon.SetCatalogSync(catalog.NewSyncer(catalog.ConfigFromApp(m.cfg), catalog.NewDBStore(m.db), nil))
+	}
+
+	// TradeMonitor: трейдер-воркеры исполняют сигналы через торговые адаптеры аккаунтов
+	m.logger.Info("[WORK] Initializing TradeMonitor (pollInterval=%d, execution=%v)", m.cfg.Daemon.PollInterval, m.cfg.Execution.Enabled)
+	m.logger.Debug("[WORK][DEBUG] Creating TradeMonitor with db=%T, traderWorkers=%d", m.db, len(m.traderWorkers))
+	m.tradeMonitor = worker.NewTradeMonitor(m.db, &m.traderWorkers, &m.workersMutex, m.stopChan, m.cfg.Daemon.PollInterval)
+	m.tradeMonitor.SetTradingEnv(m.tradingEnv())
+	tradeMonitor := m.tradeMonitor
+
+	// Сервисы аккаунтов запускаются до TradeMonitor: первые сигналы проверяются по загруженным
+	// балансам и торговым правилам, а ордера попадают в уже сверенный с биржами журнал
+	go func() {
+		if err := m.balances.Start(); err != nil {
+			m.logger.Error("Failed to start balance service: %v", err)
+		}
+		if err := symbolInfo.Start(); err != nil {
+			m.logger.Error("Failed to load symbol rules: %v", err)
+		}
+		// Менеджер ордеров сверяет журнал с открытыми ордерами бирж
+		if err := orderManager.Start(); err != nil {
+			m.logger.Error("Failed to start order manager: %v", err)
+		}
+		// Приватные потоки запускаются после подписки сервиса балансов на шину
+		for _, ud := range userData {
+			if err := ud.StartUserData(); err != nil {
+				m.logger.Error("Failed to start user data stream: %v", err)
+			}
+		}
+
+		m.logger.Debug("[WORK][DEBUG] TradeMonitor goroutine about to start")
+		m.logger.Info("[WORK] TradeMonitor goroutine started")
+		tradeMonitor.Start()
+	}()
+
 	m.logger.Info("[WORK] ServiceDaemon, TradeMonitor, DataMonitor, PriceMonitor и workers started")
 	return nil
 }
 
+// newBalanceService регистрирует в сервисе балансов по одному активному аккаунту
+// с ключами API на биржу; те же аккаунты регистрируются в менеджере ордеров и кэше
+// торговых правил, их адаптеры запоминаются в m.gateways, а приватные потоки - в m.userData.
+// Если биржа торгует бессрочными контрактами, адаптер контрактов аккаунта регистрируется
+// в m.gateways и менеджере ордеров под bus.FuturesTopic(биржа)
+func (m *Manager) newBalanceService() *balance.Service {
+	service := balance.NewService(time.Duration(m.cfg.Balance.RefreshInterval) * time.Second)
+	m.gateways = make(map[string]exchange.TradingAdapter)
+	accounts, err := exchange.LoadTradingAccounts(m.db)
+	if err != nil {
+		m.logger.Error("[WORK] Failed to load exchange accounts for balances: %v", err)
+		return service
+	}
+	registered := make(map[string]int)
+	for _, acc := range accounts {
+		if !acc.Active || acc.Exchange.ApiKey == "" {
+			continue
+		}
+		if id, ok := registered[acc.Exchange.Name]; ok {
+			m.logger.Warn("[WORK] Exchange %s has several trading accounts, balances tracked for account %d only", acc.Exchange.Name, id)
+			continue
+		}
+		adapter, err := exchange.NewTradingAdapter(acc.Exchange)
+		if err != nil {
+			m.logger.Warn("[WORK] Balances not tracked for account %d: %v", acc.ID, err)
+			continue
+		}
+		service.Register(acc.Exchange.Name, adapter)
+		m.orders.Register(acc.Exchange.Name, adapter)
+		if source, ok := adapter.(exchange.SymbolRulesSource); ok {
+			m.symbolInfo.Register(acc.Exchange.Name, source)
+		}
+		registered[acc.Exchange.Name] = acc.ID
+		m.gateways[acc.Exchange.Name] = adapter
+		if ud, ok := adapter.(exchange.UserDataAdapter); ok {
+			m.userData = append(m.userData, ud)
+		}
+		// Ноги бессрочных контрактов (cash-and-carry) адресуются площадке bus.FuturesTopic биржи.
+		// Маржа контрактов не резервируется сервисом балансов: ее учитывает стратегия
+		if perp, err := exchange.NewFuturesTradingAdapter(acc.Exchange); err == nil {
+			venue := perp.ExchangeName()
+			m.orders.Register(venue, perp)
+			m.gateways[venue] = perp
+		}
+	}
+	return service
+}
+
+// tradingEnv собирает зависимости исполнения трейдер-воркеров: шлюзы - торговые адаптеры
+// аккаунтов из newBalanceService, настройки исполнителя - из секций [execution] и [hedge],
+// лимиты риск-движков - из секции [risk], балансы, журнал ордеров и торговые правила -
+// сервисы аккаунтов, дисбаланс ног и позиции cash-and-carry пишутся в журнал ордеров.
+// Общий риск-движок переживает перезапуск работы: резервы открытых ордеров и дневной
+// результат сохраняются, лимиты обновляются из конфига
+func (m *Manager) tradingEnv() *worker.TradingEnv {
+	limits := risk.LimitsFromConfig(m.cfg)
+	if m.riskEngine == nil {
+		m.riskEngine = risk.NewEngine(limits.Global(), nil)
+		m.riskEngine.SetKillSwitch(m.killSwitch)
+	} else {
+		m.riskEngine.SetLimits(limits.Global())
+	}
+	journal := orders.NewDBJournal(m.db)
+	env := &worker.TradingEnv{
+		EnableExecution: m.cfg.Execution.Enabled,
+		Executor: executor.Config{
+			FillTimeout:     time.Duration(m.cfg.Execution.FillTimeoutMs) * time.Millisecond,
+			PollInterval:    time.Duration(m.cfg.Execution.PollIntervalMs) * time.Millisecond,
+			CancelOnTimeout: m.cfg.Execution.CancelOnTimeout,
+			HistorySize:     executor.DefaultConfig().HistorySize,
+			Hedge:           executor.HedgePolicyFromConfig(m.cfg.Hedge),
+		},
+		Gateways:       make(map[string]executor.OrderGateway, len(m.gateways)),
+		Risk:           &limits,
+		SharedRisk:     m.riskEngine,
+		KillSwitch:     m.killSwitch,
+		Balances:       m.balances,
+		Orders:         m.orders,
+		SymbolRules:    m.symbolInfo,
+		Imbalances:     journal,
+		CarryJournal:   journal,
+		TradeHedges:    make(map[int]executor.HedgePolicy, len(m.cfg.TradeHedges)),
+		FundingHistory: make(map[string]worker.FundingHistory),
+	}
+	for id, hedge := range m.cfg.TradeHedges {
+		env.TradeHedges[id] = executor.HedgePolicyFromConfig(hedge)
+	}
+	for name, adapter := range m.gateways {
+		env.Gateways[name] = adapter
+		if perpExchange, ok := bus.FuturesExchange(name); ok {
+			if history, ok := adapter.(exchange.FundingHistorySource); ok {
+				env.FundingHistory[perpExchange] = history
+			}
+		}
+	}
+	if env.EnableExecution && len(env.Gateways) == 0 {
+		m.logger.Warn("[WORK] Execution enabled but no trading accounts with API keys: signals will not be executed")
+	}
+	return env
+}
+
+// candleIntervals разбирает интервалы локальных свечей из [candles] intervals; неверные пропускаются
+func (m *Manager) candleIntervals() []time.Duration {
+	var intervals []time.Duration
+	for _, name := range strings.Split(m.cfg.Candles.Intervals, ",") {
+		if name = strings.TrimSpace(name); name == "" {
+			continue
+		}
+		d, err := market.ParseKlineInterval(name)
+		if err != nil {
+			m.logger.Warn("[WORK] Skipping candle interval: %v", err)
+			continue
+		}
+		intervals = append(intervals, d)
+	}
+	return intervals
+}
+
 // StopWork останавливает TradeMonitor и всех трейдер-воркеров
 func (m *Manager) StopWork() {
 	if !m.workStarted {
@@ -183,6 +407,35 @@ func (m *Manager) StopWork() {
 		m.priceMonitor.Stop()
 		m.priceMonitor = nil
 	}
+	if m.candleMonitor != nil {
+		m.logger.Info("[WORK] Stopping CandleMonitor...")
+		m.candleMonitor.Stop()
+		m.candleMonitor = nil
+	}
+	if m.fundingMonitor != nil {
+		m.logger.Info("[WORK] Stopping FundingMonitor...")
+		m.fundingMonitor.Stop()
+		m.fundingMonitor = nil
+	}
+	for _, ud := range m.userData {
+		_ = ud.StopUserData()
+	}
+	m.userData = nil
+	m.gateways = nil
+	if m.symbolInfo != nil {
+		m.symbolInfo.Stop()
+		m.symbolInfo = nil
+	}
+	if m.orders != nil {
+		m.logger.Info("[WORK] Stopping order manager...")
+		m.orders.Stop()
+		m.orders = nil
+	}
+	if m.balances != nil {
+		m.logger.Info("[WORK] Stopping balance service...")
+		m.balances.Stop()
+		m.balances = nil
+	}
 	m.workersMutex.Lock()
 	for id, w := range m.traderWorkers {
 		if w != nil {
@@ -0,0 +1,198 @@
+package backtest
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"daemon-go/internal/db"
+	"daemon-go/internal/market"
+	"daemon-go/internal/risk"
+	"daemon-go/internal/worker"
+	"daemon-go/internal/worker/executor"
+	"daemon-go/pkg/log"
+)
+
+// Config - параметры прогона
+type Config struct {
+	PairIDs []int
+	From    time.Time
+	To      time.Time
+
+	Worker    *worker.TradeWorkerConfig // параметры стратегии (мин. профит, объемы, биржи)
+	FeeRate   float64                   // комиссия taker по умолчанию (0.001 = 0.1%)
+	Fees      *market.FeeSchedule       // комиссии по биржам и парам (nil - FeeRate для всех)
+	Latency   time.Duration             // задержка от обнаружения возможности до прихода ордера на биржу
+	FillRatio float64                   // доля объема уровня, которую удается забрать (0..1]
+	Risk      *risk.Limits              // pre-trade лимиты (nil - без риск-менеджмента)
+}
+
+// DefaultConfig возвращает конфигурацию прогона по умолчанию
+func DefaultConfig() *Config {
+	return &Config{
+		Worker:    worker.DefaultTradeWorkerConfig(),
+		FeeRate:   0.001,
+		Latency:   200 * time.Millisecond,
+		FillRatio: 1,
+	}
+}
+
+// Engine прогоняет историю стаканов через TradeWorker и симулированный executor
+type Engine struct {
+	db     db.DBDriver
+	config *Config
+	logger *log.Logger
+}
+
+// NewEngine создает движок бэктеста
+func NewEngine(driver db.DBDriver, config *Config) *Engine {
+	if config == nil {
+		config = DefaultConfig()
+	}
+	if config.Worker == nil {
+		config.Worker = worker.DefaultTradeWorkerConfig()
+	}
+	return &Engine{
+		db:     driver,
+		config: config,
+		logger: log.New("backtest"),
+	}
+}
+
+// Run загружает историю из БД и выполняет прогон
+func (e *Engine) Run() (*Report, error) {
+	if !e.config.To.After(e.config.From) {
+		return nil, fmt.Errorf("backtest: invalid period %s - %s", e.config.From, e.config.To)
+	}
+
+	loader := NewLoader(e.db)
+	pairs, err := loader.LoadPairs(e.config.PairIDs)
+	if err != nil {
+		return nil, err
+	}
+	snapshots, err := loader.LoadSnapshots(e.config.PairIDs, e.config.From, e.config.To)
+	if err != nil {
+		return nil, err
+	}
+	e.logger.Info("[BACKTEST] Loaded %d snapshots for %d pairs (%s - %s)",
+		len(snapshots), len(pairs), e.config.From.Format(time.RFC3339), e.config.To.Format(time.RFC3339))
+
+	return e.RunSnapshots(pairs, snapshots)
+}
+
+// RunSnapshots выполняет прогон по уже загруженным снимкам.
+// Снимки группируются по времени: на каждом шаге стаканы обновляются, TradeWorker ищет
+// возможности и исполняет их через симулированные шлюзы
+func (e *Engine) RunSnapshots(pairs map[int]PairInfo, snapshots []Snapshot) (*Report, error) {
+	sort.SliceStable(snapshots, func(i, j int) bool {
+		return snapshots[i].Timestamp.Before(snapshots[j].Timestamp)
+	})
+
+	history := NewBookHistory()
+	for _, s := range snapshots {
+		pair, ok := pairs[s.PairID]
+		if !ok {
+			return nil, fmt.Errorf("backtest: unknown pair %d in price log", s.PairID)
+		}
+		history.Add(pair.Exchange, pair.Symbol, s.Timestamp, s.Bids, s.Asks)
+	}
+
+	fees := e.config.Fees
+	if fees == nil {
+		fees = market.NewFeeSchedule(market.FeeRate{Maker: e.config.FeeRate, Taker: e.config.FeeRate})
+	}
+
+	clock := &Clock{}
+	exec := executor.NewExecutor(&executor.Config{
+		FillTimeout:     0, // симулированные ордера финальны сразу после размещения
+		PollInterval:    time.Millisecond,
+		CancelOnTimeout: true,
+		HistorySize:     1,
+	})
+	for _, exchange := range pairExchanges(pairs) {
+		exec.RegisterGateway(exchange, NewSimulatedGateway(exchange, history, clock,
+			fees, e.config.Latency, e.config.FillRatio))
+	}
+
+	workerConfig := *e.config.Worker
+	workerConfig.EnableExecution = true
+	tw := worker.NewTradeWorker(&workerConfig)
+	tw.SetExecutor(exec)
+	tw.SetFeeSchedule(fees)
+	if e.config.Risk != nil {
+		riskEngine := risk.NewEngine(*e.config.Risk, tw)
+		riskEngine.SetClock(clock.Now)
+		exec.SetPreTradeCheck(riskEngine)
+	}
+
+	report := newReport(e.config.From, e.config.To, len(pairs))
+	report.Snapshots = len(snapshots)
+
+	for start := 0; start < len(snapshots); {
+		ts := snapshots[start].Timestamp
+		end := start
+		for end < len(snapshots) && snapshots[end].Timestamp.Equal(ts) {
+			end++
+		}
+
+		clock.Set(ts)
+		for _, s := range snapshots[start:end] {
+			pair := pairs[s.PairID]
+			if err := tw.HandleMessage(snapshotMessage(pair, s)); err != nil {
+				e.logger.Warn("[BACKTEST] Pair %d at %s: %v", s.PairID, ts.Format(time.RFC3339), err)
+			}
+		}
+		start = end
+		report.Steps++
+
+		opportunities := tw.ScanOpportunities()
+		report.Opportunities += len(opportunities)
+		for _, opp := range opportunities {
+			result := tw.ExecuteOpportunity(opp)
+			if result == nil {
+				continue
+			}
+			report.addTrade(ts, opp, result)
+		}
+	}
+
+	report.finish()
+	e.logger.Info("[BACKTEST] Finished: steps=%d, opportunities=%d, trades=%d, pnl=%.6f, max drawdown=%.6f",
+		report.Steps, report.Opportunities, report.Trades, report.PnL, report.MaxDrawdown)
+	return report, nil
+}
+
+// snapshotMessage превращает строку PRICE_SPOT_LOG в сообщение orderbook, как от адаптера
+func snapshotMessage(pair PairInfo, s Snapshot) market.UnifiedMessage {
+	return market.UnifiedMessage{
+		Exchange:    pair.Exchange,
+		Symbol:      pair.Symbol,
+		PairID:      pair.PairID,
+		MessageType: market.MessageTypeOrderBook,
+		Timestamp:   s.Timestamp,
+		Data: market.UnifiedOrderBook{
+			Symbol:     pair.Symbol,
+			Timestamp:  s.Timestamp,
+			Bids:       s.Bids,
+			Asks:       s.Asks,
+			Depth:      len(s.Bids),
+			UpdateType: market.OrderBookUpdateTypeSnapshot,
+		},
+	}
+}
+
+// pairExchanges возвращает список бирж из набора пар
+func pairExchanges(pairs map[int]PairInfo) []string {
+	seen := make(map[string]bool)
+	var exchanges []string
+	for _, p := range pairs {
+		name := strings.ToLower(p.Exchange)
+		if !seen[name] {
+			seen[name] = true
+			exchanges = append(exchanges, name)
+		}
+	}
+	sort.Strings(exchanges)
+	return exchanges
+}
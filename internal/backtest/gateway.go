@@ -0,0 +1,204 @@
+package backtest
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"daemon-go/internal/market"
+	"daemon-go/internal/worker/executor"
+)
+
+// Clock - симулированное время прогона
+type Clock struct {
+	mu  sync.RWMutex
+	now time.Time
+}
+
+// Set устанавливает текущее время симуляции
+func (c *Clock) Set(t time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = t
+}
+
+// Now возвращает текущее время симуляции
+func (c *Clock) Now() time.Time {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.now
+}
+
+// bookPoint - снимок стакана пары на момент времени
+type bookPoint struct {
+	ts   time.Time
+	bids []market.PriceLevel
+	asks []market.PriceLevel
+}
+
+// BookHistory - история снимков по (exchange, symbol) для поиска состояния рынка на момент времени
+type BookHistory struct {
+	books map[string][]bookPoint // [exchange|symbol], по возрастанию времени
+}
+
+// NewBookHistory создает пустую историю
+func NewBookHistory() *BookHistory {
+	return &BookHistory{books: make(map[string][]bookPoint)}
+}
+
+// Add добавляет снимок; снимки одной пары должны добавляться по возрастанию времени
+func (h *BookHistory) Add(exchange, symbol string, ts time.Time, bids, asks []market.PriceLevel) {
+	key := exchange + "|" + symbol
+	h.books[key] = append(h.books[key], bookPoint{ts: ts, bids: bids, asks: asks})
+}
+
+// at возвращает последний снимок не позже t
+func (h *BookHistory) at(exchange, symbol string, t time.Time) (bookPoint, bool) {
+	points := h.books[exchange+"|"+symbol]
+	i := sort.Search(len(points), func(i int) bool { return points[i].ts.After(t) })
+	if i == 0 {
+		return bookPoint{}, false
+	}
+	return points[i-1], true
+}
+
+// SimulatedGateway исполняет ордера против истории стаканов (реализует executor.OrderGateway).
+// Ордер попадает на рынок через Latency после текущего времени симуляции и исполняется
+// как IOC: по доступным уровням в пределах лимитной цены, неисполненный остаток отменяется
+type SimulatedGateway struct {
+	mu        sync.Mutex
+	exchange  string
+	history   *BookHistory
+	clock     *Clock
+	fees      *market.FeeSchedule // комиссия taker от суммы в quote валюте
+	latency   time.Duration
+	fillRatio float64 // доля объема уровня, доступная нам (очередь, конкуренты)
+
+	orders   map[string]*market.Order
+	consumed map[string]float64 // объем, уже выбранный нами из уровня снимка
+	seq      int64
+}
+
+var _ executor.OrderGateway = (*SimulatedGateway)(nil)
+
+// NewSimulatedGateway создает шлюз симуляции для биржи
+func NewSimulatedGateway(exchange string, history *BookHistory, clock *Clock, fees *market.FeeSchedule, latency time.Duration, fillRatio float64) *SimulatedGateway {
+	if fillRatio <= 0 || fillRatio > 1 {
+		fillRatio = 1
+	}
+	return &SimulatedGateway{
+		exchange:  exchange,
+		history:   history,
+		clock:     clock,
+		fees:      fees,
+		latency:   latency,
+		fillRatio: fillRatio,
+		orders:    make(map[string]*market.Order),
+		consumed:  make(map[string]float64),
+	}
+}
+
+// PlaceOrder исполняет ордер по стакану на момент прихода на биржу
+func (g *SimulatedGateway) PlaceOrder(req market.OrderRequest) (*market.Order, error) {
+	arrival := g.clock.Now().Add(g.latency)
+	book, ok := g.history.at(g.exchange, req.Symbol, arrival)
+	if !ok {
+		return nil, fmt.Errorf("backtest: no %s market data for %s at %s", g.exchange, req.Symbol, arrival.Format(time.RFC3339))
+	}
+
+	levels := book.asks
+	if req.Side == market.TradeSideSell {
+		levels = book.bids
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.seq++
+	order := &market.Order{
+		Exchange:      g.exchange,
+		Symbol:        req.Symbol,
+		OrderID:       fmt.Sprintf("sim-%s-%d", g.exchange, g.seq),
+		ClientOrderID: req.ClientOrderID,
+		Side:          req.Side,
+		OrderType:     req.OrderType,
+		Price:         req.Price,
+		Volume:        req.Volume,
+		CreatedAt:     arrival,
+		UpdatedAt:     arrival,
+	}
+
+	remaining := req.Volume
+	quote := 0.0
+	for _, level := range levels {
+		if remaining <= 0 {
+			break
+		}
+		if req.OrderType != market.OrderTypeMarket && !priceAcceptable(req.Side, level.Price, req.Price) {
+			break
+		}
+
+		key := fmt.Sprintf("%d|%s|%g", book.ts.UnixNano(), req.Side, level.Price)
+		available := level.Volume*g.fillRatio - g.consumed[key]
+		if available <= 0 {
+			continue
+		}
+		take := math.Min(available, remaining)
+		g.consumed[key] += take
+		remaining -= take
+		order.FilledVolume += take
+		quote += take * level.Price
+	}
+
+	if order.FilledVolume > 0 {
+		order.AvgPrice = quote / order.FilledVolume
+		order.Fee = quote * g.fees.Get(g.exchange, req.Symbol).Taker
+		if _, q, found := strings.Cut(req.Symbol, "/"); found {
+			order.FeeCurrency = q
+		}
+	}
+
+	// IOC: остаток отменяется сразу
+	if remaining <= req.Volume*1e-9 {
+		order.Status = market.OrderStatusFilled
+	} else {
+		order.Status = market.OrderStatusCanceled
+	}
+
+	g.orders[order.OrderID] = order
+	result := *order
+	return &result, nil
+}
+
+// CancelOrder отменяет ордер (все ордера симуляции уже финальные)
+func (g *SimulatedGateway) CancelOrder(symbol, orderID string) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if _, ok := g.orders[orderID]; !ok {
+		return fmt.Errorf("backtest: order %s not found", orderID)
+	}
+	return nil
+}
+
+// GetOrder возвращает состояние ордера
+func (g *SimulatedGateway) GetOrder(symbol, orderID string) (*market.Order, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	order, ok := g.orders[orderID]
+	if !ok {
+		return nil, fmt.Errorf("backtest: order %s not found", orderID)
+	}
+	result := *order
+	return &result, nil
+}
+
+// priceAcceptable проверяет, что уровень не хуже лимитной цены
+func priceAcceptable(side market.TradeSide, levelPrice, limit float64) bool {
+	if side == market.TradeSideSell {
+		return levelPrice >= limit
+	}
+	return levelPrice <= limit
+}
@@ -0,0 +1,163 @@
+// Package backtest - прогон арбитражной логики TradeWorker по истории PRICE_SPOT_LOG
+// с симуляцией исполнения (комиссии, задержка, частичные исполнения)
+package backtest
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"daemon-go/internal/db"
+	"daemon-go/internal/market"
+	sqlMySQL "daemon-go/internal/sql/mysql"
+	sqlPostgres "daemon-go/internal/sql/postgres"
+)
+
+// PairInfo - пара из SPOT_TRADE_PAIR
+type PairInfo struct {
+	PairID   int
+	Exchange string // имя биржи в нижнем регистре
+	Symbol   string // BTC/USDT
+}
+
+// Snapshot - 5-уровневый снимок стакана из PRICE_SPOT_LOG
+type Snapshot struct {
+	PairID    int
+	Timestamp time.Time
+	Bids      []market.PriceLevel
+	Asks      []market.PriceLevel
+}
+
+// Loader загружает пары и историю стаканов из БД
+type Loader struct {
+	db db.DBDriver
+}
+
+// NewLoader создает загрузчик истории
+func NewLoader(driver db.DBDriver) *Loader {
+	return &Loader{db: driver}
+}
+
+// LoadPairs возвращает биржу и символ для каждой пары
+func (l *Loader) LoadPairs(pairIDs []int) (map[int]PairInfo, error) {
+	if len(pairIDs) == 0 {
+		return nil, fmt.Errorf("backtest: no pair ids")
+	}
+
+	query := l.pairsQuery()
+	query = fmt.Sprintf(query, l.placeholders(len(pairIDs), 1))
+
+	rows, err := l.db.Query(query, intArgs(pairIDs)...)
+	if err != nil {
+		return nil, fmt.Errorf("backtest: load pairs: %w", err)
+	}
+	defer rows.Close()
+
+	pairs := make(map[int]PairInfo, len(pairIDs))
+	for rows.Next() {
+		var p PairInfo
+		if err := rows.Scan(&p.PairID, &p.Exchange, &p.Symbol); err != nil {
+			return nil, fmt.Errorf("backtest: scan pair: %w", err)
+		}
+		p.Symbol = strings.ToUpper(p.Symbol)
+		pairs[p.PairID] = p
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("backtest: load pairs: %w", err)
+	}
+
+	for _, id := range pairIDs {
+		if _, ok := pairs[id]; !ok {
+			return nil, fmt.Errorf("backtest: pair %d not found", id)
+		}
+	}
+	return pairs, nil
+}
+
+// LoadSnapshots загружает снимки за период [from, to), отсортированные по времени
+func (l *Loader) LoadSnapshots(pairIDs []int, from, to time.Time) ([]Snapshot, error) {
+	if len(pairIDs) == 0 {
+		return nil, fmt.Errorf("backtest: no pair ids")
+	}
+
+	query := fmt.Sprintf(l.priceLogQuery(), l.placeholders(len(pairIDs), 3))
+	args := append([]interface{}{from, to}, intArgs(pairIDs)...)
+
+	rows, err := l.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("backtest: load price log: %w", err)
+	}
+	defer rows.Close()
+
+	var snapshots []Snapshot
+	for rows.Next() {
+		var s Snapshot
+		var asks, bids [5][2]float64
+		if err := rows.Scan(
+			&s.PairID, &s.Timestamp,
+			&asks[0][0], &asks[0][1], &asks[1][0], &asks[1][1], &asks[2][0], &asks[2][1],
+			&asks[3][0], &asks[3][1], &asks[4][0], &asks[4][1],
+			&bids[0][0], &bids[0][1], &bids[1][0], &bids[1][1], &bids[2][0], &bids[2][1],
+			&bids[3][0], &bids[3][1], &bids[4][0], &bids[4][1],
+		); err != nil {
+			return nil, fmt.Errorf("backtest: scan price log: %w", err)
+		}
+		s.Asks = levelsFromColumns(asks)
+		s.Bids = levelsFromColumns(bids)
+		snapshots = append(snapshots, s)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("backtest: load price log: %w", err)
+	}
+	return snapshots, nil
+}
+
+// levelsFromColumns собирает уровни из колонок, пропуская пустые (меньше 5 уровней в снимке)
+func levelsFromColumns(columns [5][2]float64) []market.PriceLevel {
+	levels := make([]market.PriceLevel, 0, len(columns))
+	for _, c := range columns {
+		if c[0] > 0 && c[1] > 0 {
+			levels = append(levels, market.PriceLevel{Price: c[0], Volume: c[1]})
+		}
+	}
+	return levels
+}
+
+// placeholders формирует список плейсхолдеров IN для типа БД; start - номер первого ($n для postgres)
+func (l *Loader) placeholders(n, start int) string {
+	parts := make([]string, n)
+	for i := range parts {
+		if l.db.GetType() == "postgres" {
+			parts[i] = fmt.Sprintf("$%d", start+i)
+		} else {
+			parts[i] = "?"
+		}
+	}
+	return strings.Join(parts, ",")
+}
+
+func (l *Loader) pairsQuery() string {
+	switch l.db.GetType() {
+	case "postgres":
+		return sqlPostgres.BacktestPairs
+	default: // MySQL
+		return sqlMySQL.BacktestPairs
+	}
+}
+
+func (l *Loader) priceLogQuery() string {
+	switch l.db.GetType() {
+	case "postgres":
+		return sqlPostgres.BacktestPriceLog
+	default: // MySQL
+		return sqlMySQL.BacktestPriceLog
+	}
+}
+
+func intArgs(values []int) []interface{} {
+	args := make([]interface{}, len(values))
+	for i, v := range values {
+		args[i] = v
+	}
+	return args
+}
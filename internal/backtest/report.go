@@ -0,0 +1,175 @@
+package backtest
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"time"
+
+	"daemon-go/internal/market"
+	"daemon-go/internal/worker"
+	"daemon-go/internal/worker/executor"
+)
+
+// TradeRecord - результат одной симулированной сделки
+type TradeRecord struct {
+	Time           time.Time                `json:"time"`
+	Symbol         string                   `json:"symbol"`
+	BuyExchange    string                   `json:"buy_exchange"`
+	SellExchange   string                   `json:"sell_exchange"`
+	Volume         float64                  `json:"volume"`      // запрошенный объем
+	BuyFilled      float64                  `json:"buy_filled"`  // исполнено на покупке
+	SellFilled     float64                  `json:"sell_filled"` // исполнено на продаже
+	Fees           float64                  `json:"fees"`
+	ExpectedProfit float64                  `json:"expected_profit"`
+	RealizedProfit float64                  `json:"realized_profit"`
+	Status         executor.ExecutionStatus `json:"status"`
+}
+
+// SymbolStats - итоги по символу
+type SymbolStats struct {
+	Trades int     `json:"trades"`
+	PnL    float64 `json:"pnl"`
+	Fees   float64 `json:"fees"`
+}
+
+// Report - итоги бэктеста
+type Report struct {
+	From  time.Time `json:"from"`
+	To    time.Time `json:"to"`
+	Pairs int       `json:"pairs"`
+
+	Snapshots     int `json:"snapshots"`     // строк PRICE_SPOT_LOG
+	Steps         int `json:"steps"`         // уникальных моментов времени
+	Opportunities int `json:"opportunities"` // найдено возможностей
+	Trades        int `json:"trades"`        // размещено сделок
+	Completed     int `json:"completed"`
+	Partial       int `json:"partial"`
+	Failed        int `json:"failed"`
+	Winning       int `json:"winning"`
+
+	ExpectedPnL float64 `json:"expected_pnl"` // оценка TradeWorker по лучшим ценам
+	PnL         float64 `json:"pnl"`          // реализованная прибыль с комиссиями
+	Fees        float64 `json:"fees"`
+	Unhedged    float64 `json:"unhedged"` // стоимость расхождения объемов ног в quote валюте
+	MaxDrawdown float64 `json:"max_drawdown"`
+	WinRate     float64 `json:"win_rate"` // доля прибыльных среди исполненных сделок, %
+
+	BySymbol map[string]*SymbolStats `json:"by_symbol"`
+	TradeLog []TradeRecord           `json:"trade_log"`
+
+	peak float64
+}
+
+func newReport(from, to time.Time, pairs int) *Report {
+	return &Report{
+		From:     from,
+		To:       to,
+		Pairs:    pairs,
+		BySymbol: make(map[string]*SymbolStats),
+	}
+}
+
+// addTrade учитывает результат сделки и обновляет кривую PnL
+func (r *Report) addTrade(ts time.Time, opp worker.ArbitrageOpportunity, result *executor.ExecutionResult) {
+	record := TradeRecord{
+		Time:           ts,
+		Symbol:         opp.Symbol,
+		BuyExchange:    opp.BuyExchange,
+		SellExchange:   opp.SellExchange,
+		Volume:         opp.MaxVolume,
+		ExpectedProfit: result.ExpectedProfit,
+		RealizedProfit: result.RealizedProfit,
+		Status:         result.Status,
+	}
+
+	var buyPrice, sellPrice float64
+	for _, leg := range result.Legs {
+		record.Fees += leg.Fee
+		switch leg.Leg.Request.Side {
+		case market.TradeSideBuy:
+			record.BuyFilled = leg.FilledVolume
+			buyPrice = leg.AvgPrice
+		case market.TradeSideSell:
+			record.SellFilled = leg.FilledVolume
+			sellPrice = leg.AvgPrice
+		}
+	}
+
+	r.Trades++
+	switch result.Status {
+	case executor.ExecutionStatusCompleted:
+		r.Completed++
+	case executor.ExecutionStatusPartial:
+		r.Partial++
+	default:
+		r.Failed++
+	}
+	if result.Status != executor.ExecutionStatusFailed && result.RealizedProfit > 0 {
+		r.Winning++
+	}
+
+	// Неперекрытый остаток одной из ног - открытая позиция, оцениваем по цене исполнения
+	if diff := record.BuyFilled - record.SellFilled; diff > 0 {
+		r.Unhedged += diff * buyPrice
+	} else if diff < 0 {
+		r.Unhedged += -diff * sellPrice
+	}
+
+	r.ExpectedPnL += result.ExpectedProfit
+	r.PnL += result.RealizedProfit
+	r.Fees += record.Fees
+	if r.PnL > r.peak {
+		r.peak = r.PnL
+	}
+	r.MaxDrawdown = math.Max(r.MaxDrawdown, r.peak-r.PnL)
+
+	stats := r.BySymbol[opp.Symbol]
+	if stats == nil {
+		stats = &SymbolStats{}
+		r.BySymbol[opp.Symbol] = stats
+	}
+	stats.Trades++
+	stats.PnL += result.RealizedProfit
+	stats.Fees += record.Fees
+
+	r.TradeLog = append(r.TradeLog, record)
+}
+
+// finish рассчитывает производные показатели
+func (r *Report) finish() {
+	if executed := r.Completed + r.Partial; executed > 0 {
+		r.WinRate = float64(r.Winning) / float64(executed) * 100
+	}
+}
+
+// Print выводит отчет в текстовом виде
+func (r *Report) Print(w io.Writer) {
+	fmt.Fprintf(w, "Period:        %s - %s\n", r.From.Format(time.RFC3339), r.To.Format(time.RFC3339))
+	fmt.Fprintf(w, "Pairs:         %d\n", r.Pairs)
+	fmt.Fprintf(w, "Snapshots:     %d (%d steps)\n", r.Snapshots, r.Steps)
+	fmt.Fprintf(w, "Opportunities: %d\n", r.Opportunities)
+	fmt.Fprintf(w, "Trades:        %d (completed %d, partial %d, failed %d)\n", r.Trades, r.Completed, r.Partial, r.Failed)
+	fmt.Fprintf(w, "Win rate:      %.2f%%\n", r.WinRate)
+	fmt.Fprintf(w, "Expected PnL:  %.6f\n", r.ExpectedPnL)
+	fmt.Fprintf(w, "Realized PnL:  %.6f\n", r.PnL)
+	fmt.Fprintf(w, "Fees:          %.6f\n", r.Fees)
+	fmt.Fprintf(w, "Unhedged:      %.6f\n", r.Unhedged)
+	fmt.Fprintf(w, "Max drawdown:  %.6f\n", r.MaxDrawdown)
+
+	if len(r.BySymbol) == 0 {
+		return
+	}
+	symbols := make([]string, 0, len(r.BySymbol))
+	for symbol := range r.BySymbol {
+		symbols = append(symbols, symbol)
+	}
+	sort.Strings(symbols)
+
+	fmt.Fprintf(w, "By symbol:\n")
+	for _, symbol := range symbols {
+		s := r.BySymbol[symbol]
+		fmt.Fprintf(w, "  %-12s trades=%d pnl=%.6f fees=%.6f\n", symbol, s.Trades, s.PnL, s.Fees)
+	}
+}
@@ -0,0 +1,481 @@
+package balance
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"daemon-go/internal/bus"
+	"daemon-go/internal/market"
+	"daemon-go/internal/worker/executor"
+	"daemon-go/pkg/log"
+)
+
+// Source - источник балансов аккаунта биржи (exchange.TradingAdapter)
+type Source interface {
+	GetBalances() ([]market.Balance, error)
+}
+
+// InsufficientError - на бирже не хватает доступного баланса под ордер
+type InsufficientError struct {
+	Exchange  string
+	Asset     string
+	Required  float64
+	Available float64
+}
+
+func (e *InsufficientError) Error() string {
+	return fmt.Sprintf("balance: insufficient %s on %s: required %.8f, available %.8f",
+		e.Asset, e.Exchange, e.Required, e.Available)
+}
+
+// AssetBalance - состояние актива на бирже
+type AssetBalance struct {
+	Asset     string    `json:"asset"`
+	Free      float64   `json:"free"`      // свободно по данным биржи
+	Locked    float64   `json:"locked"`    // заблокировано в ордерах по данным биржи
+	Reserved  float64   `json:"reserved"`  // зарезервировано под ордера в процессе размещения
+	Available float64   `json:"available"` // Free - Reserved
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// reservation - баланс, занятый ордером, пока балансы биржи его не учитывают.
+// После подтверждения размещения (placedAt) резерв снимается первой сверкой через REST,
+// запрошенной позже подтверждения, или первым обновлением актива из потока, полученным позже него:
+// free биржи уже уменьшен ордером, и резерв вычитался бы дважды
+type reservation struct {
+	exchange string
+	asset    string
+	amount   float64
+	placedAt time.Time // локальное время подтверждения размещения; zero - ордер еще не размещен
+}
+
+// Service - балансы аккаунтов бирж: загрузка через REST при старте и периодическая
+// сверка, обновления из приватных потоков (MessageTypeBalance в шине сообщений)
+// и резервирование баланса под размещаемые ордера (реализует executor.PreTradeCheck).
+// Балансы ведутся по имени биржи - одному торговому аккаунту на биржу, как шлюзы executor
+type Service struct {
+	mu              sync.RWMutex
+	sources         map[string]Source                     // [exchange]
+	balances        map[string]map[string]*market.Balance // [exchange][asset]
+	updatedAt       map[string]map[string]time.Time       // [exchange][asset] -> локальное время получения
+	reserved        map[string]map[string]float64         // [exchange][asset]
+	reservations    map[string]reservation                // [clientOrderID]
+	subscriptions   map[string]chan market.UnifiedMessage // [exchange]
+	refreshInterval time.Duration
+	refreshing      map[string]bool // [exchange] -> сверка уже запущена
+	messageBus      *bus.MessageBus
+	stopChan        chan struct{}
+	logger          *log.Logger
+
+	restRefreshes int64
+	streamUpdates int64
+	rejects       int64
+}
+
+var (
+	_ executor.PreTradeCheck     = (*Service)(nil)
+	_ executor.PlacementObserver = (*Service)(nil)
+)
+
+// NewService создает сервис балансов; refreshInterval - период сверки через REST (0 - только при старте)
+func NewService(refreshInterval time.Duration) *Service {
+	return &Service{
+		sources:         make(map[string]Source),
+		balances:        make(map[string]map[string]*market.Balance),
+		updatedAt:       make(map[string]map[string]time.Time),
+		reserved:        make(map[string]map[string]float64),
+		reservations:    make(map[string]reservation),
+		subscriptions:   make(map[string]chan market.UnifiedMessage),
+		refreshInterval: refreshInterval,
+		refreshing:      make(map[string]bool),
+		messageBus:      bus.GetInstance(),
+		logger:          log.New("balance"),
+	}
+}
+
+// Register добавляет аккаунт биржи
+func (s *Service) Register(exchange string, source Source) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sources[strings.ToLower(exchange)] = source
+}
+
+// Start загружает балансы всех аккаунтов, подписывается на обновления из шины
+// и запускает периодическую сверку
+func (s *Service) Start() error {
+	s.mu.Lock()
+	if s.stopChan != nil {
+		s.mu.Unlock()
+		return fmt.Errorf("balance service already started")
+	}
+	s.stopChan = make(chan struct{})
+	exchanges := make([]string, 0, len(s.sources))
+	for exchange := range s.sources {
+		exchanges = append(exchanges, exchange)
+		ch := s.messageBus.Subscribe(exchange, 100)
+		s.subscriptions[exchange] = ch
+		go s.messageProcessor(exchange, ch)
+	}
+	stop := s.stopChan
+	s.mu.Unlock()
+
+	for _, exchange := range exchanges {
+		if err := s.Refresh(exchange); err != nil {
+			s.logger.Error("[BALANCE] Initial load failed for %s: %v", exchange, err)
+		}
+	}
+
+	if s.refreshInterval > 0 {
+		go s.refreshLoop(stop)
+	}
+	s.logger.Info("[BALANCE] Balance service started for %d exchanges", len(exchanges))
+	return nil
+}
+
+// Stop останавливает сверку и отписывается от шины
+func (s *Service) Stop() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.stopChan == nil {
+		return
+	}
+	close(s.stopChan)
+	s.stopChan = nil
+	for exchange, ch := range s.subscriptions {
+		s.messageBus.Unsubscribe(exchange, ch)
+		delete(s.subscriptions, exchange)
+	}
+	s.logger.Info("[BALANCE] Balance service stopped")
+}
+
+// refreshLoop периодически сверяет балансы через REST
+func (s *Service) refreshLoop(stop chan struct{}) {
+	ticker := time.NewTicker(s.refreshInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			if err := s.RefreshAll(); err != nil {
+				s.logger.Warn("[BALANCE] Refresh failed: %v", err)
+			}
+		}
+	}
+}
+
+// messageProcessor применяет обновления балансов из приватного потока биржи
+func (s *Service) messageProcessor(exchange string, ch chan market.UnifiedMessage) {
+	for msg := range ch {
+		if msg.MessageType != market.MessageTypeBalance {
+			continue
+		}
+		update, ok := msg.Data.(market.UnifiedBalanceUpdate)
+		if !ok {
+			continue
+		}
+		s.Apply(exchange, update)
+	}
+}
+
+// Refresh загружает балансы биржи через REST и заменяет ими текущие значения.
+// Активы, обновленные из потока после отправки запроса, не затираются: снимок REST старше них
+func (s *Service) Refresh(exchange string) error {
+	exchange = strings.ToLower(exchange)
+	s.mu.RLock()
+	source, ok := s.sources[exchange]
+	s.mu.RUnlock()
+	if !ok {
+		return fmt.Errorf("balance: no account registered for exchange %s", exchange)
+	}
+
+	requested := time.Now()
+	balances, err := source.GetBalances()
+	if err != nil {
+		return fmt.Errorf("balance: %s: %w", exchange, err)
+	}
+
+	assets := make(map[string]*market.Balance, len(balances))
+	updatedAt := make(map[string]time.Time, len(balances))
+	for _, b := range balances {
+		b := b
+		b.Asset = strings.ToUpper(b.Asset)
+		assets[b.Asset] = &b
+		updatedAt[b.Asset] = requested
+	}
+
+	s.mu.Lock()
+	for asset, ts := range s.updatedAt[exchange] {
+		if ts.After(requested) {
+			assets[asset] = s.balances[exchange][asset]
+			updatedAt[asset] = ts
+		}
+	}
+	s.balances[exchange] = assets
+	s.updatedAt[exchange] = updatedAt
+	s.dropPlaced(exchange, "", requested)
+	s.restRefreshes++
+	s.mu.Unlock()
+
+	s.logger.Debug("[BALANCE] %s: loaded %d assets", exchange, len(assets))
+	return nil
+}
+
+// RefreshAll сверяет балансы всех аккаунтов; возвращает первую ошибку
+func (s *Service) RefreshAll() error {
+	s.mu.RLock()
+	exchanges := make([]string, 0, len(s.sources))
+	for exchange := range s.sources {
+		exchanges = append(exchanges, exchange)
+	}
+	s.mu.RUnlock()
+
+	var firstErr error
+	for _, exchange := range exchanges {
+		if err := s.Refresh(exchange); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// refreshAsync запускает внеочередную сверку биржи (после исполнения ордера), не дублируя запросы
+func (s *Service) refreshAsync(exchange string) {
+	s.mu.Lock()
+	if s.refreshing[exchange] || s.sources[exchange] == nil {
+		s.mu.Unlock()
+		return
+	}
+	s.refreshing[exchange] = true
+	s.mu.Unlock()
+
+	go func() {
+		defer func() {
+			s.mu.Lock()
+			delete(s.refreshing, exchange)
+			s.mu.Unlock()
+		}()
+		if err := s.Refresh(exchange); err != nil {
+			s.logger.Warn("[BALANCE] Refresh after fill failed: %v", err)
+		}
+	}()
+}
+
+// Apply применяет обновление балансов из приватного потока.
+// Порядок с REST определяется локальным временем получения: часы биржи (update.Timestamp)
+// несравнимы с локальным временем запросов сверки
+func (s *Service) Apply(exchange string, update market.UnifiedBalanceUpdate) {
+	exchange = strings.ToLower(exchange)
+	received := time.Now()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.balances[exchange] == nil {
+		s.balances[exchange] = make(map[string]*market.Balance)
+		s.updatedAt[exchange] = make(map[string]time.Time)
+	}
+	for _, b := range update.Balances {
+		b := b
+		b.Asset = strings.ToUpper(b.Asset)
+		s.balances[exchange][b.Asset] = &b
+		s.updatedAt[exchange][b.Asset] = received
+		s.dropPlaced(exchange, b.Asset, received)
+	}
+	s.streamUpdates++
+}
+
+// dropPlaced снимает резервы ордеров биржи, размещение которых подтверждено раньше since:
+// полученные после этого балансы уже учитывают ордер. Пустой asset - все активы.
+// Запись резерва остается до Release (сверка после исполнения). Вызывается под s.mu
+func (s *Service) dropPlaced(exchange, asset string, since time.Time) {
+	for id, r := range s.reservations {
+		if r.exchange != exchange || r.amount == 0 || r.placedAt.IsZero() || !r.placedAt.Before(since) {
+			continue
+		}
+		if asset != "" && r.asset != asset {
+			continue
+		}
+		s.unreserve(r)
+		r.amount = 0
+		s.reservations[id] = r
+	}
+}
+
+// unreserve вычитает резерв из суммы по активу. Вызывается под s.mu
+func (s *Service) unreserve(r reservation) {
+	s.reserved[r.exchange][r.asset] -= r.amount
+	if s.reserved[r.exchange][r.asset] <= 1e-12 {
+		delete(s.reserved[r.exchange], r.asset)
+	}
+}
+
+// Available возвращает доступный для новых ордеров баланс актива (free за вычетом резервов).
+// ok=false, если балансы биржи не загружены (например, аккаунт не подключен)
+func (s *Service) Available(exchange, asset string) (float64, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.available(strings.ToLower(exchange), strings.ToUpper(asset))
+}
+
+// available вызывается под s.mu
+func (s *Service) available(exchange, asset string) (float64, bool) {
+	assets, ok := s.balances[exchange]
+	if !ok {
+		return 0, false
+	}
+	free := 0.0
+	if b := assets[asset]; b != nil {
+		free = b.Free
+	}
+	available := free - s.reserved[exchange][asset]
+	if available < 0 {
+		available = 0
+	}
+	return available, true
+}
+
+// Get возвращает состояние актива на бирже
+func (s *Service) Get(exchange, asset string) AssetBalance {
+	exchange, asset = strings.ToLower(exchange), strings.ToUpper(asset)
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.assetBalance(exchange, asset)
+}
+
+// assetBalance вызывается под s.mu
+func (s *Service) assetBalance(exchange, asset string) AssetBalance {
+	ab := AssetBalance{
+		Asset:     asset,
+		Reserved:  s.reserved[exchange][asset],
+		UpdatedAt: s.updatedAt[exchange][asset],
+	}
+	if b := s.balances[exchange][asset]; b != nil {
+		ab.Free = b.Free
+		ab.Locked = b.Locked
+	}
+	ab.Available, _ = s.available(exchange, asset)
+	return ab
+}
+
+// Snapshot возвращает балансы всех бирж: [exchange] -> активы, отсортированные по имени
+func (s *Service) Snapshot() map[string][]AssetBalance {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	view := make(map[string][]AssetBalance, len(s.balances))
+	for exchange, assets := range s.balances {
+		names := make(map[string]bool, len(assets))
+		for asset, b := range assets {
+			if b.Total() > 0 {
+				names[asset] = true
+			}
+		}
+		for asset := range s.reserved[exchange] {
+			names[asset] = true
+		}
+		list := make([]AssetBalance, 0, len(names))
+		for asset := range names {
+			list = append(list, s.assetBalance(exchange, asset))
+		}
+		sort.Slice(list, func(i, j int) bool { return list[i].Asset < list[j].Asset })
+		view[exchange] = list
+	}
+	return view
+}
+
+// Check резервирует баланс под ордер: quote валюту для покупки, base для продажи.
+// Биржи без загруженных балансов не проверяются
+func (s *Service) Check(exchange string, req market.OrderRequest) error {
+	exchange = strings.ToLower(exchange)
+	asset, amount, ok := requiredBalance(req)
+	if !ok {
+		return nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	available, loaded := s.available(exchange, asset)
+	if !loaded {
+		return nil
+	}
+	if amount > available*(1+1e-9) {
+		s.rejects++
+		err := &InsufficientError{Exchange: exchange, Asset: asset, Required: amount, Available: available}
+		s.logger.Warn("[BALANCE] Rejected %s %s %s %.8f @ %.8f: %v",
+			exchange, req.Symbol, req.Side, req.Volume, req.Price, err)
+		return err
+	}
+
+	if s.reserved[exchange] == nil {
+		s.reserved[exchange] = make(map[string]float64)
+	}
+	s.reserved[exchange][asset] += amount
+	s.reservations[req.ClientOrderID] = reservation{exchange: exchange, asset: asset, amount: amount}
+	return nil
+}
+
+// Placed отмечает подтверждение размещения ордера биржей: резерв держится до первых балансов,
+// полученных после подтверждения (Refresh, Apply)
+func (s *Service) Placed(exchange string, req market.OrderRequest, order *market.Order) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if r, ok := s.reservations[req.ClientOrderID]; ok && r.placedAt.IsZero() {
+		r.placedAt = time.Now()
+		s.reservations[req.ClientOrderID] = r
+	}
+}
+
+// Release снимает резерв ордера; после исполнения балансы биржи сверяются через REST
+func (s *Service) Release(exchange string, req market.OrderRequest, order *market.Order) {
+	s.mu.Lock()
+	r, ok := s.reservations[req.ClientOrderID]
+	if ok {
+		delete(s.reservations, req.ClientOrderID)
+		s.unreserve(r)
+	}
+	s.mu.Unlock()
+
+	if ok && order != nil && order.FilledVolume > 0 {
+		s.refreshAsync(r.exchange)
+	}
+}
+
+// RecordPnL не используется: балансы обновляются по данным биржи
+func (s *Service) RecordPnL(pnl float64) {}
+
+// GetStats возвращает состояние сервиса балансов
+func (s *Service) GetStats() map[string]interface{} {
+	snapshot := s.Snapshot()
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return map[string]interface{}{
+		"exchanges":      len(s.sources),
+		"reservations":   len(s.reservations),
+		"rest_refreshes": s.restRefreshes,
+		"stream_updates": s.streamUpdates,
+		"rejects":        s.rejects,
+		"balances":       snapshot,
+	}
+}
+
+// requiredBalance возвращает актив и количество, которые ордер списывает с баланса.
+// Для рыночной покупки без цены сумма неизвестна - такой ордер не резервируется
+func requiredBalance(req market.OrderRequest) (string, float64, bool) {
+	symbol, err := market.ParseSymbol(req.Symbol, "spot")
+	if err != nil || symbol.BaseCurrency == "" || symbol.QuoteCurrency == "" {
+		return "", 0, false
+	}
+	if req.Side == market.TradeSideSell {
+		return strings.ToUpper(symbol.BaseCurrency), req.Volume, true
+	}
+	if req.Price <= 0 {
+		return "", 0, false
+	}
+	return strings.ToUpper(symbol.QuoteCurrency), req.Volume * req.Price, true
+}
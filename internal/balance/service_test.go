@@ -0,0 +1,174 @@
+package balance
+
+import (
+	"errors"
+	"math"
+	"sync"
+	"testing"
+	"time"
+
+	"daemon-go/internal/market"
+)
+
+// fakeSource возвращает заданные балансы; during вызывается внутри запроса (поток во время REST)
+type fakeSource struct {
+	mu       sync.Mutex
+	balances []market.Balance
+	during   func()
+}
+
+func (f *fakeSource) set(balances ...market.Balance) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.balances = balances
+	f.during = nil
+}
+
+func (f *fakeSource) GetBalances() ([]market.Balance, error) {
+	f.mu.Lock()
+	balances, during := f.balances, f.during
+	f.mu.Unlock()
+	if during != nil {
+		during()
+	}
+	return balances, nil
+}
+
+func newTestService(t *testing.T, balances ...market.Balance) (*Service, *fakeSource) {
+	t.Helper()
+	source := &fakeSource{balances: balances}
+	s := NewService(0)
+	s.Register("binance", source)
+	if err := s.Refresh("binance"); err != nil {
+		t.Fatal(err)
+	}
+	return s, source
+}
+
+func buyOrder(id string) market.OrderRequest {
+	return market.OrderRequest{Symbol: "BTC/USDT", Side: market.TradeSideBuy, Price: 100, Volume: 1, ClientOrderID: id}
+}
+
+func assertAvailable(t *testing.T, s *Service, asset string, want float64) {
+	t.Helper()
+	got, ok := s.Available("binance", asset)
+	if !ok || math.Abs(got-want) > 1e-9 {
+		t.Fatalf("available %s %.8f (loaded %v), want %.8f", asset, got, ok, want)
+	}
+}
+
+// Размещенный ордер уже уменьшил free биржи: резерв снимается балансами, полученными после подтверждения
+func TestReservationDroppedAfterPlacement(t *testing.T) {
+	tests := []struct {
+		name   string
+		update func(s *Service, source *fakeSource)
+	}{
+		{
+			name: "rest refresh",
+			update: func(s *Service, source *fakeSource) {
+				source.set(market.Balance{Asset: "USDT", Free: 900, Locked: 100})
+				if err := s.Refresh("binance"); err != nil {
+					t.Fatal(err)
+				}
+			},
+		},
+		{
+			name: "stream update",
+			update: func(s *Service, source *fakeSource) {
+				s.Apply("binance", market.UnifiedBalanceUpdate{Balances: []market.Balance{{Asset: "USDT", Free: 900, Locked: 100}}})
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, source := newTestService(t, market.Balance{Asset: "USDT", Free: 1000})
+			req := buyOrder("o1")
+			if err := s.Check("binance", req); err != nil {
+				t.Fatal(err)
+			}
+			assertAvailable(t, s, "USDT", 900)
+
+			s.Placed("binance", req, &market.Order{ClientOrderID: "o1", Status: market.OrderStatusNew})
+			time.Sleep(time.Millisecond)
+			tt.update(s, source)
+			assertAvailable(t, s, "USDT", 900)
+
+			s.Release("binance", req, &market.Order{ClientOrderID: "o1", Status: market.OrderStatusNew})
+			assertAvailable(t, s, "USDT", 900)
+			if got := s.Get("binance", "USDT").Reserved; got != 0 {
+				t.Fatalf("reserved %.8f after release, want 0", got)
+			}
+		})
+	}
+}
+
+// До подтверждения размещения резерв держится: сверка могла не увидеть ордер
+func TestReservationHeldUntilPlacement(t *testing.T) {
+	s, _ := newTestService(t, market.Balance{Asset: "USDT", Free: 1000}, market.Balance{Asset: "BTC", Free: 1})
+	req := buyOrder("o1")
+	if err := s.Check("binance", req); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := s.Refresh("binance"); err != nil {
+		t.Fatal(err)
+	}
+	assertAvailable(t, s, "USDT", 900)
+
+	// Обновление другого актива после подтверждения не снимает резерв USDT
+	s.Placed("binance", req, &market.Order{ClientOrderID: "o1"})
+	time.Sleep(time.Millisecond)
+	s.Apply("binance", market.UnifiedBalanceUpdate{Balances: []market.Balance{{Asset: "BTC", Free: 1}}})
+	assertAvailable(t, s, "USDT", 900)
+}
+
+// Снимок REST, запрошенный до обновления из потока, не затирает его; время биржи в обновлении не учитывается
+func TestStreamUpdateOrdering(t *testing.T) {
+	s, source := newTestService(t, market.Balance{Asset: "USDT", Free: 1000}, market.Balance{Asset: "BTC", Free: 1})
+
+	// Часы биржи отстают от локальных: обновление все равно применяется
+	s.Apply("binance", market.UnifiedBalanceUpdate{
+		Timestamp: time.Now().Add(-time.Hour),
+		Balances:  []market.Balance{{Asset: "USDT", Free: 800}},
+	})
+	assertAvailable(t, s, "USDT", 800)
+
+	source.mu.Lock()
+	source.balances = []market.Balance{{Asset: "USDT", Free: 800}, {Asset: "BTC", Free: 2}}
+	source.during = func() {
+		time.Sleep(time.Millisecond)
+		s.Apply("binance", market.UnifiedBalanceUpdate{Balances: []market.Balance{{Asset: "USDT", Free: 700}}})
+	}
+	source.mu.Unlock()
+	if err := s.Refresh("binance"); err != nil {
+		t.Fatal(err)
+	}
+	assertAvailable(t, s, "USDT", 700)
+	assertAvailable(t, s, "BTC", 2)
+}
+
+// Ордер сверх доступного баланса отклоняется без резерва
+func TestCheckRejectsInsufficientBalance(t *testing.T) {
+	s, _ := newTestService(t, market.Balance{Asset: "USDT", Free: 150}, market.Balance{Asset: "BTC", Free: 0.5})
+
+	tests := []struct {
+		name  string
+		req   market.OrderRequest
+		asset string
+	}{
+		{name: "buy over quote balance", req: market.OrderRequest{Symbol: "BTC/USDT", Side: market.TradeSideBuy, Price: 100, Volume: 2, ClientOrderID: "b"}, asset: "USDT"},
+		{name: "sell over base balance", req: market.OrderRequest{Symbol: "BTC/USDT", Side: market.TradeSideSell, Price: 100, Volume: 1, ClientOrderID: "s"}, asset: "BTC"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := s.Check("binance", tt.req)
+			var insufficient *InsufficientError
+			if !errors.As(err, &insufficient) || insufficient.Asset != tt.asset {
+				t.Fatalf("error %v, want insufficient %s", err, tt.asset)
+			}
+			if got := s.Get("binance", tt.asset).Reserved; got != 0 {
+				t.Fatalf("reserved %.8f after reject", got)
+			}
+		})
+	}
+}
@@ -3,6 +3,7 @@ package bus
 import (
 	"daemon-go/internal/market"
 	"daemon-go/pkg/log"
+	"strings"
 	"sync"
 )
 
@@ -29,6 +30,20 @@ func GetInstance() *MessageBus {
 	return instance
 }
 
+// futuresSuffix - суффикс топика бессрочных контрактов биржи
+const futuresSuffix = ".futures"
+
+// FuturesTopic возвращает имя, под которым публикуются данные бессрочных контрактов биржи.
+// Спотовые потребители (TradeWorker, PriceMonitor) подписаны на имя биржи и их не получают
+func FuturesTopic(exchange string) string {
+	return exchange + futuresSuffix
+}
+
+// FuturesExchange возвращает биржу топика бессрочных контрактов; ok=false - топик не FuturesTopic
+func FuturesExchange(topic string) (exchange string, ok bool) {
+	return strings.CutSuffix(topic, futuresSuffix)
+}
+
 // Subscribe подписывается на сообщения от конкретной биржи
 func (mb *MessageBus) Subscribe(exchange string, bufferSize int) chan market.UnifiedMessage {
 	mb.mu.Lock()
@@ -0,0 +1,245 @@
+// Package capture - запись сырого трафика бирж в файл и его воспроизведение.
+//
+// Формат файла: заголовок "CTCAP" + версия (1 байт), далее кадры
+//
+//	timestamp int64 (unix nano) | len(exchange) uint8 | exchange | message type uint8 | len(data) uint32 | data
+//
+// Все числа big-endian. Файл может быть целиком сжат gzip, это определяется при чтении автоматически.
+// Приватные потоки пользовательских данных пишутся под отдельным именем (UserDataStream) и при
+// воспроизведении пропускаются: публичный парсер биржи их не разбирает
+package capture
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+const (
+	fileMagic   = "CTCAP"
+	fileVersion = 1
+
+	// maxFrameSize - защита от поврежденного файла при чтении
+	maxFrameSize = 64 << 20
+)
+
+// ErrInvalidFormat - файл не является записью трафика или поврежден
+var ErrInvalidFormat = errors.New("capture: invalid file format")
+
+// userDataSuffix - суффикс имени потока приватных пользовательских данных биржи
+const userDataSuffix = ".userdata"
+
+// UserDataStream возвращает имя, под которым пишутся кадры приватного потока биржи
+func UserDataStream(exchange string) string {
+	return exchange + userDataSuffix
+}
+
+// Frame - один кадр WebSocket, полученный от биржи
+type Frame struct {
+	Timestamp   time.Time
+	Exchange    string
+	MessageType int // websocket.TextMessage / websocket.BinaryMessage
+	Data        []byte
+}
+
+// Writer пишет кадры в файл записи
+type Writer struct {
+	mu     sync.Mutex
+	file   *os.File
+	gz     *gzip.Writer
+	buf    *bufio.Writer
+	frames int64
+	closed bool
+}
+
+// NewWriter создает файл записи; compress включает сжатие gzip
+func NewWriter(path string, compress bool) (*Writer, error) {
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, fmt.Errorf("capture: create %s: %w", path, err)
+	}
+
+	w := &Writer{file: file}
+	var out io.Writer = file
+	if compress {
+		w.gz = gzip.NewWriter(file)
+		out = w.gz
+	}
+	w.buf = bufio.NewWriterSize(out, 64*1024)
+
+	if _, err := w.buf.WriteString(fileMagic); err != nil {
+		file.Close()
+		return nil, fmt.Errorf("capture: write header: %w", err)
+	}
+	if err := w.buf.WriteByte(fileVersion); err != nil {
+		file.Close()
+		return nil, fmt.Errorf("capture: write header: %w", err)
+	}
+	return w, nil
+}
+
+// WriteFrame записывает кадр
+func (w *Writer) WriteFrame(frame Frame) error {
+	if len(frame.Exchange) > 255 {
+		return fmt.Errorf("capture: exchange name too long: %q", frame.Exchange)
+	}
+
+	var header [8 + 1]byte
+	binary.BigEndian.PutUint64(header[:8], uint64(frame.Timestamp.UnixNano()))
+	header[8] = byte(len(frame.Exchange))
+
+	var meta [1 + 4]byte
+	meta[0] = byte(frame.MessageType)
+	binary.BigEndian.PutUint32(meta[1:], uint32(len(frame.Data)))
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.closed {
+		return nil
+	}
+
+	for _, part := range [][]byte{header[:], []byte(frame.Exchange), meta[:], frame.Data} {
+		if _, err := w.buf.Write(part); err != nil {
+			return fmt.Errorf("capture: write frame: %w", err)
+		}
+	}
+	w.frames++
+	return nil
+}
+
+// Flush сбрасывает буферизованные кадры на диск
+func (w *Writer) Flush() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.closed {
+		return nil
+	}
+	if err := w.buf.Flush(); err != nil {
+		return fmt.Errorf("capture: flush: %w", err)
+	}
+	if w.gz != nil {
+		if err := w.gz.Flush(); err != nil {
+			return fmt.Errorf("capture: flush gzip: %w", err)
+		}
+	}
+	return nil
+}
+
+// Frames возвращает количество записанных кадров
+func (w *Writer) Frames() int64 {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.frames
+}
+
+// Close дописывает буфер и закрывает файл
+func (w *Writer) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.closed {
+		return nil
+	}
+	w.closed = true
+
+	if err := w.buf.Flush(); err != nil {
+		w.file.Close()
+		return fmt.Errorf("capture: flush: %w", err)
+	}
+	if w.gz != nil {
+		if err := w.gz.Close(); err != nil {
+			w.file.Close()
+			return fmt.Errorf("capture: close gzip: %w", err)
+		}
+	}
+	return w.file.Close()
+}
+
+// Reader читает кадры из файла записи
+type Reader struct {
+	file *os.File
+	gz   *gzip.Reader
+	buf  *bufio.Reader
+}
+
+// Open открывает файл записи (сжатый или нет) и проверяет заголовок
+func Open(path string) (*Reader, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("capture: open %s: %w", path, err)
+	}
+
+	r := &Reader{file: file, buf: bufio.NewReaderSize(file, 64*1024)}
+
+	// gzip определяем по сигнатуре 1f 8b
+	if magic, err := r.buf.Peek(2); err == nil && magic[0] == 0x1f && magic[1] == 0x8b {
+		r.gz, err = gzip.NewReader(r.buf)
+		if err != nil {
+			file.Close()
+			return nil, fmt.Errorf("capture: open gzip: %w", err)
+		}
+		r.buf = bufio.NewReaderSize(r.gz, 64*1024)
+	}
+
+	header := make([]byte, len(fileMagic)+1)
+	if _, err := io.ReadFull(r.buf, header); err != nil || !bytes.Equal(header[:len(fileMagic)], []byte(fileMagic)) {
+		r.Close()
+		return nil, ErrInvalidFormat
+	}
+	if header[len(fileMagic)] != fileVersion {
+		r.Close()
+		return nil, fmt.Errorf("capture: unsupported version %d", header[len(fileMagic)])
+	}
+	return r, nil
+}
+
+// Next читает следующий кадр; в конце файла возвращает io.EOF
+func (r *Reader) Next() (Frame, error) {
+	var header [8 + 1]byte
+	if _, err := io.ReadFull(r.buf, header[:]); err != nil {
+		if err == io.EOF {
+			return Frame{}, io.EOF
+		}
+		return Frame{}, fmt.Errorf("capture: read frame header: %w", err)
+	}
+
+	exchange := make([]byte, header[8])
+	if _, err := io.ReadFull(r.buf, exchange); err != nil {
+		return Frame{}, fmt.Errorf("capture: read exchange: %w", err)
+	}
+
+	var meta [1 + 4]byte
+	if _, err := io.ReadFull(r.buf, meta[:]); err != nil {
+		return Frame{}, fmt.Errorf("capture: read frame meta: %w", err)
+	}
+	size := binary.BigEndian.Uint32(meta[1:])
+	if size > maxFrameSize {
+		return Frame{}, ErrInvalidFormat
+	}
+
+	data := make([]byte, size)
+	if _, err := io.ReadFull(r.buf, data); err != nil {
+		return Frame{}, fmt.Errorf("capture: read frame data: %w", err)
+	}
+
+	return Frame{
+		Timestamp:   time.Unix(0, int64(binary.BigEndian.Uint64(header[:8]))),
+		Exchange:    string(exchange),
+		MessageType: int(meta[0]),
+		Data:        data,
+	}, nil
+}
+
+// Close закрывает файл
+func (r *Reader) Close() error {
+	if r.gz != nil {
+		r.gz.Close()
+	}
+	return r.file.Close()
+}
@@ -0,0 +1,106 @@
+package capture
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"daemon-go/pkg/log"
+)
+
+// flushInterval - период сброса записи на диск
+const flushInterval = time.Second
+
+var (
+	recorderMu sync.RWMutex
+	recorder   *Writer
+	stopFlush  chan struct{}
+	logger     = log.New("capture")
+)
+
+// Start включает запись трафика всех бирж в новый файл в каталоге dir
+func Start(dir string, compress bool) (string, error) {
+	recorderMu.Lock()
+	defer recorderMu.Unlock()
+
+	if recorder != nil {
+		return "", fmt.Errorf("capture: already started")
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("capture: create dir %s: %w", dir, err)
+	}
+
+	name := "capture-" + time.Now().Format("20060102-150405") + ".ctcap"
+	if compress {
+		name += ".gz"
+	}
+	path := filepath.Join(dir, name)
+
+	w, err := NewWriter(path, compress)
+	if err != nil {
+		return "", err
+	}
+	recorder = w
+	stopFlush = make(chan struct{})
+	go flushLoop(w, stopFlush)
+
+	logger.Info("[CAPTURE] Recording raw exchange traffic to %s", path)
+	return path, nil
+}
+
+// Record записывает полученный от биржи кадр, если запись включена
+func Record(exchange string, msgType int, data []byte) {
+	recorderMu.RLock()
+	w := recorder
+	recorderMu.RUnlock()
+	if w == nil {
+		return
+	}
+
+	frame := Frame{Timestamp: time.Now(), Exchange: exchange, MessageType: msgType, Data: data}
+	if err := w.WriteFrame(frame); err != nil {
+		logger.Error("[CAPTURE] %v", err)
+	}
+}
+
+// IsRecording проверяет, включена ли запись
+func IsRecording() bool {
+	recorderMu.RLock()
+	defer recorderMu.RUnlock()
+	return recorder != nil
+}
+
+// Stop останавливает запись и закрывает файл
+func Stop() error {
+	recorderMu.Lock()
+	w := recorder
+	recorder = nil
+	if stopFlush != nil {
+		close(stopFlush)
+		stopFlush = nil
+	}
+	recorderMu.Unlock()
+
+	if w == nil {
+		return nil
+	}
+	logger.Info("[CAPTURE] Recording stopped, %d frames written", w.Frames())
+	return w.Close()
+}
+
+func flushLoop(w *Writer, stop chan struct{}) {
+	ticker := time.NewTicker(flushInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			if err := w.Flush(); err != nil {
+				logger.Error("[CAPTURE] %v", err)
+			}
+		}
+	}
+}
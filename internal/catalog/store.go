@@ -0,0 +1,227 @@
+package catalog
+
+import (
+	"database/sql"
+	"fmt"
+	"sort"
+
+	"daemon-go/internal/db"
+	sqlMySQL "daemon-go/internal/sql/mysql"
+	sqlPostgres "daemon-go/internal/sql/postgres"
+)
+
+// Store - справочник бирж, монет и спотовых пар
+type Store interface {
+	Exchanges() ([]db.Exchange, error)
+	SyncExchange(exchangeID int, pairs []Pair) (Result, error)
+}
+
+// queries - SQL справочника для драйвера БД
+type queries struct {
+	exchanges, coins, insertCoin, pairs, insertPair, updatePair string
+}
+
+// DBStore - справочник в таблицах EXCHANGE, COIN и SPOT_TRADE_PAIR
+type DBStore struct {
+	db db.DBDriver
+	q  queries
+}
+
+// NewDBStore создает справочник в БД
+func NewDBStore(driver db.DBDriver) *DBStore {
+	q := queries{
+		exchanges:  sqlMySQL.CatalogExchanges,
+		coins:      sqlMySQL.CatalogCoins,
+		insertCoin: sqlMySQL.InsertCoin,
+		pairs:      sqlMySQL.CatalogSpotPairs,
+		insertPair: sqlMySQL.InsertSpotPair,
+		updatePair: sqlMySQL.UpdateSpotPairActive,
+	}
+	if driver.GetType() == "postgres" {
+		q = queries{
+			exchanges:  sqlPostgres.CatalogExchanges,
+			coins:      sqlPostgres.CatalogCoins,
+			insertCoin: sqlPostgres.InsertCoin,
+			pairs:      sqlPostgres.CatalogSpotPairs,
+			insertPair: sqlPostgres.InsertSpotPair,
+			updatePair: sqlPostgres.UpdateSpotPairActive,
+		}
+	}
+	return &DBStore{db: driver, q: q}
+}
+
+// Exchanges загружает активные биржи
+func (s *DBStore) Exchanges() ([]db.Exchange, error) {
+	rows, err := s.db.Query(s.q.exchanges)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var exchanges []db.Exchange
+	for rows.Next() {
+		ex := db.Exchange{Active: true}
+		if err := rows.Scan(&ex.ID, &ex.Name, &ex.Url, &ex.BaseUrl); err != nil {
+			return nil, err
+		}
+		exchanges = append(exchanges, ex)
+	}
+	return exchanges, rows.Err()
+}
+
+// existingPair - пара биржи в справочнике
+type existingPair struct {
+	id     int
+	active bool
+}
+
+// pairUpdate - новое значение ACTIVE пары справочника
+type pairUpdate struct {
+	id     int
+	symbol string
+	active bool
+}
+
+// pairPlan - изменения справочника, приводящие пары биржи к ее списку символов
+type pairPlan struct {
+	coins  []string     // монеты добавляемых пар, которых нет в COIN
+	insert []Pair       // пары с Add, которых нет в SPOT_TRADE_PAIR
+	update []pairUpdate // пары с изменившимся статусом и снятые с торгов
+	result Result
+}
+
+// planPairs сравнивает список пар биржи со справочником: недостающие монеты и пары (с Add)
+// добавляются, у пар с изменившимся статусом обновляется ACTIVE, активные пары биржи,
+// отсутствующие в списке, выключаются
+func planPairs(coins map[string]int, existing map[string]existingPair, pairs []Pair) pairPlan {
+	plan := pairPlan{result: Result{Listed: len(pairs)}}
+
+	newCoins := make(map[string]bool)
+	seen := make(map[string]bool, len(pairs))
+	for _, p := range pairs {
+		key := p.Symbol()
+		seen[key] = true
+		if e, ok := existing[key]; ok {
+			if e.active == p.Active {
+				continue
+			}
+			plan.update = append(plan.update, pairUpdate{id: e.id, symbol: key, active: p.Active})
+			if p.Active {
+				plan.result.Activated++
+			} else {
+				plan.result.Deactivated++
+			}
+			continue
+		}
+		if !p.Add {
+			continue
+		}
+		for _, symbol := range []string{p.Base, p.Quote} {
+			if _, ok := coins[symbol]; !ok && !newCoins[symbol] {
+				newCoins[symbol] = true
+				plan.coins = append(plan.coins, symbol)
+			}
+		}
+		plan.insert = append(plan.insert, p)
+	}
+	plan.result.NewCoins = len(plan.coins)
+	plan.result.Added = len(plan.insert)
+
+	var delisted []string
+	for key, e := range existing {
+		if !seen[key] && e.active {
+			delisted = append(delisted, key)
+		}
+	}
+	sort.Strings(delisted)
+	for _, key := range delisted {
+		plan.update = append(plan.update, pairUpdate{id: existing[key].id, symbol: key, active: false})
+	}
+	plan.result.Delisted = len(delisted)
+	return plan
+}
+
+// SyncExchange приводит пары биржи к списку pairs одной транзакцией (planPairs)
+func (s *DBStore) SyncExchange(exchangeID int, pairs []Pair) (Result, error) {
+	tx, err := s.db.BeginTx()
+	if err != nil {
+		return Result{Listed: len(pairs)}, fmt.Errorf("begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	coins, err := s.loadCoins(tx)
+	if err != nil {
+		return Result{Listed: len(pairs)}, fmt.Errorf("load coins: %w", err)
+	}
+	existing, err := s.loadPairs(tx, exchangeID)
+	if err != nil {
+		return Result{Listed: len(pairs)}, fmt.Errorf("load pairs: %w", err)
+	}
+	plan := planPairs(coins, existing, pairs)
+
+	for _, symbol := range plan.coins {
+		if _, err := tx.Exec(s.q.insertCoin, symbol); err != nil {
+			return plan.result, fmt.Errorf("insert coin %s: %w", symbol, err)
+		}
+	}
+	if len(plan.coins) > 0 {
+		if coins, err = s.loadCoins(tx); err != nil {
+			return plan.result, fmt.Errorf("reload coins: %w", err)
+		}
+	}
+	for _, u := range plan.update {
+		if _, err := tx.Exec(s.q.updatePair, u.active, u.id); err != nil {
+			return plan.result, fmt.Errorf("update pair %s: %w", u.symbol, err)
+		}
+	}
+	for _, p := range plan.insert {
+		if _, err := tx.Exec(s.q.insertPair, exchangeID, coins[p.Base], coins[p.Quote], p.Active); err != nil {
+			return plan.result, fmt.Errorf("insert pair %s: %w", p.Symbol(), err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return plan.result, err
+	}
+	return plan.result, nil
+}
+
+// loadCoins возвращает ID монет по символу
+func (s *DBStore) loadCoins(tx *sql.Tx) (map[string]int, error) {
+	rows, err := tx.Query(s.q.coins)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	coins := make(map[string]int)
+	for rows.Next() {
+		var id int
+		var symbol string
+		if err := rows.Scan(&id, &symbol); err != nil {
+			return nil, err
+		}
+		coins[symbol] = id
+	}
+	return coins, rows.Err()
+}
+
+// loadPairs возвращает пары биржи по символу BASE/QUOTE
+func (s *DBStore) loadPairs(tx *sql.Tx, exchangeID int) (map[string]existingPair, error) {
+	rows, err := tx.Query(s.q.pairs, exchangeID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	pairs := make(map[string]existingPair)
+	for rows.Next() {
+		var e existingPair
+		var base, quote string
+		if err := rows.Scan(&e.id, &base, &quote, &e.active); err != nil {
+			return nil, err
+		}
+		pairs[base+"/"+quote] = e
+	}
+	return pairs, rows.Err()
+}
@@ -0,0 +1,92 @@
+package catalog
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestPlanPairs(t *testing.T) {
+	coins := map[string]int{"BTC": 1, "USDT": 2, "ETH": 3}
+	tests := []struct {
+		name       string
+		existing   map[string]existingPair
+		pairs      []Pair
+		wantCoins  []string
+		wantInsert []string
+		wantUpdate []pairUpdate
+		wantResult Result
+	}{
+		{
+			name:       "new pair with known coins",
+			pairs:      []Pair{{Base: "BTC", Quote: "USDT", Active: true, Add: true}},
+			wantInsert: []string{"BTC/USDT"},
+			wantResult: Result{Listed: 1, Added: 1},
+		},
+		{
+			name: "new coins added once",
+			pairs: []Pair{
+				{Base: "SOL", Quote: "USDC", Active: true, Add: true},
+				{Base: "SOL", Quote: "USDT", Active: true, Add: true},
+			},
+			wantCoins:  []string{"SOL", "USDC"},
+			wantInsert: []string{"SOL/USDC", "SOL/USDT"},
+			wantResult: Result{Listed: 2, Added: 2, NewCoins: 2},
+		},
+		{
+			name:       "filtered quote not added",
+			pairs:      []Pair{{Base: "BTC", Quote: "TRY", Active: true}},
+			wantResult: Result{Listed: 1},
+		},
+		{
+			name:       "filtered quote still updates known pair",
+			existing:   map[string]existingPair{"BTC/TRY": {id: 7, active: true}},
+			pairs:      []Pair{{Base: "BTC", Quote: "TRY", Active: false}},
+			wantUpdate: []pairUpdate{{id: 7, symbol: "BTC/TRY", active: false}},
+			wantResult: Result{Listed: 1, Deactivated: 1},
+		},
+		{
+			name:       "halted pair trades again",
+			existing:   map[string]existingPair{"ETH/USDT": {id: 5, active: false}},
+			pairs:      []Pair{{Base: "ETH", Quote: "USDT", Active: true, Add: true}},
+			wantUpdate: []pairUpdate{{id: 5, symbol: "ETH/USDT", active: true}},
+			wantResult: Result{Listed: 1, Activated: 1},
+		},
+		{
+			name: "delisted pairs deactivated once",
+			existing: map[string]existingPair{
+				"BTC/USDT":  {id: 1, active: true},
+				"XRP/USDT":  {id: 3, active: true},
+				"LTC/USDT":  {id: 2, active: true},
+				"DOGE/USDT": {id: 4, active: false},
+			},
+			pairs: []Pair{{Base: "BTC", Quote: "USDT", Active: true, Add: true}},
+			wantUpdate: []pairUpdate{
+				{id: 2, symbol: "LTC/USDT", active: false},
+				{id: 3, symbol: "XRP/USDT", active: false},
+			},
+			wantResult: Result{Listed: 1, Delisted: 2},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plan := planPairs(coins, tt.existing, tt.pairs)
+
+			var insert []string
+			for _, p := range plan.insert {
+				insert = append(insert, p.Symbol())
+			}
+			if fmt.Sprint(plan.coins) != fmt.Sprint(tt.wantCoins) {
+				t.Errorf("coins %v, want %v", plan.coins, tt.wantCoins)
+			}
+			if fmt.Sprint(insert) != fmt.Sprint(tt.wantInsert) {
+				t.Errorf("insert %v, want %v", insert, tt.wantInsert)
+			}
+			if fmt.Sprintf("%+v", plan.update) != fmt.Sprintf("%+v", tt.wantUpdate) {
+				t.Errorf("update %+v, want %+v", plan.update, tt.wantUpdate)
+			}
+			if plan.result != tt.wantResult {
+				t.Errorf("result %+v, want %+v", plan.result, tt.wantResult)
+			}
+		})
+	}
+}
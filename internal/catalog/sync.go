@@ -0,0 +1,201 @@
+package catalog
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"daemon-go/internal/config"
+	"daemon-go/internal/db"
+	"daemon-go/internal/exchange"
+	"daemon-go/internal/market"
+	"daemon-go/pkg/log"
+)
+
+// Pair - спотовая пара из списка символов биржи
+type Pair struct {
+	Base   string
+	Quote  string
+	Active bool // торговля открыта; false - пара приостановлена биржей
+	Add    bool // добавить пару, если ее нет в справочнике (quote валюта проходит фильтр)
+}
+
+// Symbol возвращает унифицированный символ BASE/QUOTE
+func (p Pair) Symbol() string {
+	return p.Base + "/" + p.Quote
+}
+
+// Result - итог синхронизации пар одной биржи
+type Result struct {
+	Exchange    string    `json:"exchange"`
+	Listed      int       `json:"listed"`      // пар в списке биржи
+	NewCoins    int       `json:"new_coins"`   // добавлено монет
+	Added       int       `json:"added"`       // добавлено пар
+	Activated   int       `json:"activated"`   // пары снова торгуются
+	Deactivated int       `json:"deactivated"` // торговля приостановлена
+	Delisted    int       `json:"delisted"`    // пары нет в списке биржи
+	Error       string    `json:"error,omitempty"`
+	SyncedAt    time.Time `json:"synced_at"`
+}
+
+// SourceFactory создает источник списка символов для биржи из EXCHANGE
+type SourceFactory func(ex db.Exchange) (exchange.SymbolRulesSource, error)
+
+// AdapterSource - источник символов через REST биржевого адаптера
+func AdapterSource(ex db.Exchange) (exchange.SymbolRulesSource, error) {
+	source, ok := exchange.NewAdapter(ex).(exchange.SymbolRulesSource)
+	if !ok {
+		return nil, fmt.Errorf("exchange %s does not provide symbol list", ex.Name)
+	}
+	return source, nil
+}
+
+// Config - настройки синхронизации
+type Config struct {
+	Interval time.Duration // минимальный интервал между синхронизациями
+	Quotes   []string      // quote валюты добавляемых пар; пусто - все
+}
+
+// ConfigFromApp строит настройки из секции [catalog] конфига
+func ConfigFromApp(cfg *config.Config) Config {
+	c := Config{Interval: time.Duration(cfg.Catalog.Interval) * time.Second}
+	for _, quote := range strings.Split(cfg.Catalog.Quotes, ",") {
+		if quote = strings.ToUpper(strings.TrimSpace(quote)); quote != "" {
+			c.Quotes = append(c.Quotes, quote)
+		}
+	}
+	return c
+}
+
+// Syncer загружает списки символов активных бирж и приводит к ним COIN и SPOT_TRADE_PAIR:
+// новые пары добавляются, приостановленные и снятые с торгов выключаются (ACTIVE = 0),
+// поэтому DataMonitor перестает подписываться на несуществующие символы
+type Syncer struct {
+	mu          sync.Mutex
+	config      Config
+	store       Store
+	newSource   SourceFactory
+	lastRun     time.Time
+	lastResults []Result
+	logger      *log.Logger
+
+	runs   int64
+	errors int64
+}
+
+// NewSyncer создает синхронизацию справочника; newSource nil - AdapterSource
+func NewSyncer(cfg Config, store Store, newSource SourceFactory) *Syncer {
+	if newSource == nil {
+		newSource = AdapterSource
+	}
+	return &Syncer{
+		config:    cfg,
+		store:     store,
+		newSource: newSource,
+		logger:    log.New("catalog"),
+	}
+}
+
+// Run синхронизирует все активные биржи, если с прошлого запуска прошел Interval.
+// Ошибка одной биржи не останавливает остальные; возвращается первая ошибка
+func (s *Syncer) Run() ([]Result, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if time.Since(s.lastRun) < s.config.Interval {
+		return nil, nil
+	}
+	s.lastRun = time.Now()
+	s.runs++
+
+	exchanges, err := s.store.Exchanges()
+	if err != nil {
+		s.errors++
+		return nil, fmt.Errorf("catalog: load exchanges: %w", err)
+	}
+
+	var firstErr error
+	results := make([]Result, 0, len(exchanges))
+	for _, ex := range exchanges {
+		r, err := s.syncExchange(ex)
+		if err != nil {
+			s.errors++
+			r.Error = err.Error()
+			s.logger.Error("[CATALOG] %s: %v", ex.Name, err)
+			if firstErr == nil {
+				firstErr = fmt.Errorf("catalog: %s: %w", ex.Name, err)
+			}
+		} else {
+			s.logger.Info("[CATALOG] %s: %d pairs listed, %d added, %d reactivated, %d halted, %d delisted, %d new coins",
+				ex.Name, r.Listed, r.Added, r.Activated, r.Deactivated, r.Delisted, r.NewCoins)
+		}
+		results = append(results, r)
+	}
+	s.lastResults = results
+	return results, firstErr
+}
+
+// syncExchange загружает список символов биржи и синхронизирует ее пары. Пустой список
+// считается ошибкой загрузки: иначе сбой биржи выключил бы все ее пары
+func (s *Syncer) syncExchange(ex db.Exchange) (Result, error) {
+	result := Result{Exchange: ex.Name, SyncedAt: time.Now()}
+
+	source, err := s.newSource(ex)
+	if err != nil {
+		return result, err
+	}
+	rules, err := source.GetSymbolRules()
+	if err != nil {
+		return result, err
+	}
+	pairs := s.listedPairs(rules)
+	if len(pairs) == 0 {
+		return result, fmt.Errorf("empty symbol list")
+	}
+
+	synced, err := s.store.SyncExchange(ex.ID, pairs)
+	synced.Exchange, synced.SyncedAt = result.Exchange, result.SyncedAt
+	return synced, err
+}
+
+// listedPairs строит пары из списка символов без дублей. Фильтр quote валют ограничивает
+// только добавление: статус уже известных пар обновляется независимо от фильтра
+func (s *Syncer) listedPairs(rules []market.SymbolRules) []Pair {
+	quotes := make(map[string]bool, len(s.config.Quotes))
+	for _, q := range s.config.Quotes {
+		quotes[q] = true
+	}
+
+	seen := make(map[string]bool, len(rules))
+	pairs := make([]Pair, 0, len(rules))
+	for _, r := range rules {
+		p := Pair{Base: strings.ToUpper(r.BaseCurrency), Quote: strings.ToUpper(r.QuoteCurrency), Active: r.Trading}
+		if p.Base == "" || p.Quote == "" || seen[p.Symbol()] {
+			continue
+		}
+		p.Add = len(quotes) == 0 || quotes[p.Quote]
+		seen[p.Symbol()] = true
+		pairs = append(pairs, p)
+	}
+	return pairs
+}
+
+// LastResults возвращает итоги последнего запуска
+func (s *Syncer) LastResults() []Result {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.lastResults
+}
+
+// GetStats возвращает статистику синхронизации
+func (s *Syncer) GetStats() map[string]interface{} {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return map[string]interface{}{
+		"runs":         s.runs,
+		"errors":       s.errors,
+		"last_run":     s.lastRun,
+		"last_results": s.lastResults,
+	}
+}
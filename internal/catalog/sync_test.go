@@ -0,0 +1,127 @@
+package catalog
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"daemon-go/internal/db"
+	"daemon-go/internal/exchange"
+	"daemon-go/internal/market"
+)
+
+// rulesSource - список символов биржи теста
+type rulesSource struct {
+	rules []market.SymbolRules
+	err   error
+}
+
+func (s rulesSource) GetSymbolRules() ([]market.SymbolRules, error) { return s.rules, s.err }
+
+// recordingStore запоминает пары, переданные в SyncExchange, по ID биржи
+type recordingStore struct {
+	exchanges []db.Exchange
+	synced    map[int][]Pair
+}
+
+func (s *recordingStore) Exchanges() ([]db.Exchange, error) { return s.exchanges, nil }
+
+func (s *recordingStore) SyncExchange(exchangeID int, pairs []Pair) (Result, error) {
+	s.synced[exchangeID] = pairs
+	return Result{Listed: len(pairs)}, nil
+}
+
+func rule(base, quote string, trading bool) market.SymbolRules {
+	return market.SymbolRules{Symbol: base + "/" + quote, BaseCurrency: base, QuoteCurrency: quote, Trading: trading}
+}
+
+func TestSyncerRun(t *testing.T) {
+	tests := []struct {
+		name      string
+		quotes    []string
+		source    rulesSource
+		wantPairs string
+		wantErr   string
+	}{
+		{
+			name:      "pairs normalized and deduplicated",
+			source:    rulesSource{rules: []market.SymbolRules{rule("btc", "usdt", true), rule("BTC", "USDT", true), rule("ETH", "BTC", false)}},
+			wantPairs: "[{BTC USDT true true} {ETH BTC false true}]",
+		},
+		{
+			name:      "quote filter limits additions only",
+			quotes:    []string{"USDT"},
+			source:    rulesSource{rules: []market.SymbolRules{rule("BTC", "USDT", true), rule("ETH", "BTC", true)}},
+			wantPairs: "[{BTC USDT true true} {ETH BTC true false}]",
+		},
+		{
+			name:      "symbols without currencies skipped",
+			source:    rulesSource{rules: []market.SymbolRules{rule("", "USDT", true), rule("BTC", "USDT", true)}},
+			wantPairs: "[{BTC USDT true true}]",
+		},
+		{
+			name:    "empty list is an error, pairs untouched",
+			source:  rulesSource{},
+			wantErr: "catalog: binance: empty symbol list",
+		},
+		{
+			name:    "source failure",
+			source:  rulesSource{err: errors.New("timeout")},
+			wantErr: "catalog: binance: timeout",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := &recordingStore{
+				exchanges: []db.Exchange{{ID: 1, Name: "binance"}, {ID: 2, Name: "bybit"}},
+				synced:    make(map[int][]Pair),
+			}
+			sources := map[string]exchange.SymbolRulesSource{
+				"binance": tt.source,
+				"bybit":   rulesSource{rules: []market.SymbolRules{rule("BTC", "USDT", true)}},
+			}
+			s := NewSyncer(Config{Quotes: tt.quotes}, store, func(ex db.Exchange) (exchange.SymbolRulesSource, error) {
+				return sources[ex.Name], nil
+			})
+
+			results, err := s.Run()
+
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("error %v, want %q", err, tt.wantErr)
+				}
+				if _, ok := store.synced[1]; ok {
+					t.Fatalf("failed exchange synced: %+v", store.synced[1])
+				}
+			} else if err != nil {
+				t.Fatal(err)
+			}
+			if got := fmt.Sprint(store.synced[1]); tt.wantErr == "" && got != tt.wantPairs {
+				t.Errorf("pairs %s, want %s", got, tt.wantPairs)
+			}
+			// Ошибка одной биржи не останавливает остальные
+			if len(results) != 2 || results[1].Exchange != "bybit" || results[1].Listed != 1 || len(store.synced[2]) != 1 {
+				t.Errorf("results %+v, synced %+v", results, store.synced)
+			}
+		})
+	}
+}
+
+// Повторный запуск раньше Interval ничего не делает
+func TestSyncerRunInterval(t *testing.T) {
+	store := &recordingStore{exchanges: []db.Exchange{{ID: 1, Name: "binance"}}, synced: make(map[int][]Pair)}
+	s := NewSyncer(Config{Interval: time.Hour}, store, func(db.Exchange) (exchange.SymbolRulesSource, error) {
+		return rulesSource{rules: []market.SymbolRules{rule("BTC", "USDT", true)}}, nil
+	})
+
+	if results, err := s.Run(); err != nil || len(results) != 1 {
+		t.Fatalf("first run: %+v, %v", results, err)
+	}
+	if results, err := s.Run(); err != nil || results != nil {
+		t.Fatalf("second run: %+v, %v", results, err)
+	}
+	if stats := s.GetStats(); stats["runs"] != int64(1) {
+		t.Fatalf("stats %v", stats)
+	}
+}
@@ -2,6 +2,8 @@ package config
 
 import (
 	"errors"
+	"strconv"
+	"strings"
 
 	"gopkg.in/ini.v1"
 )
@@ -38,6 +40,57 @@ type Config struct {
 		DebugLogRaw bool // логирование чистых сообщений от и к бирже в json
 		DebugLogMsg bool // логирование уже unified message в json
 	}
+	Capture struct {
+		Enabled  bool   // запись сырого трафика бирж для последующего replay
+		Dir      string // каталог файлов записи
+		Compress bool   // сжатие файлов записи gzip
+	}
+	Risk struct {
+		MaxOrderNotional    float64 // максимальная стоимость одного ордера в quote валюте (0 - без ограничения)
+		MaxExchangeNotional float64 // максимальная стоимость открытых ордеров на одной бирже
+		DailyLossLimit      float64 // дневной лимит убытка (0 - без ограничения)
+		MaxOpenOrders       int     // максимальное количество открытых ордеров
+		MaxBookAgeMs        int     // ордер отклоняется, если стакан старше (0 - без проверки)
+		PriceBandPercent    float64 // допустимое отклонение цены ордера от mid, % (0 - без проверки)
+		BBOOnly             bool    // цена ордера не глубже лучших цен стакана
+		ReservationTTLSec   int     // лимиты ордера без финального статуса освобождаются через (0 - не освобождаются)
+	}
+	Execution struct {
+		Enabled         bool // исполнение сигналов трейдер-воркеров ордерами на биржах
+		FillTimeoutMs   int  // сколько ждать исполнения ордера, мс
+		PollIntervalMs  int  // интервал опроса статуса ордера, мс
+		CancelOnTimeout bool // отменять неисполненный остаток по таймауту
+	}
+	Hedge       Hedge         // политика дисбаланса ног по умолчанию, секция [hedge]
+	TradeHedges map[int]Hedge // [TRADE.ID] политики записей TRADE, секции [hedge.<ID>]
+	Balance     struct {
+		RefreshInterval int // интервал сверки балансов через REST, секунды (0 - только при старте)
+	}
+	Rebalance struct {
+		Enabled          bool    // планирование переводов между биржами
+		Interval         int     // интервал планирования, секунды
+		Assets           string  // активы через запятую: USDT,BTC
+		Weights          string  // целевые доли бирж: binance:2,kucoin:1 (пусто - поровну)
+		ThresholdPercent float64 // перевод, если баланс биржи ниже целевого более чем на этот процент
+		NetworkFees      string  // оценка комиссии сети за перевод: USDT:1,BTC:0.0002
+	}
+	Catalog struct {
+		Enabled  bool   // синхронизация справочника символов бирж в COIN и SPOT_TRADE_PAIR
+		Interval int    // интервал синхронизации, секунды
+		Quotes   string // quote валюты добавляемых пар через запятую (пусто - все)
+	}
+	Candles struct {
+		Enabled       bool   // запись свечей пар мониторинга в PRICE_SPOT_KLINE
+		Intervals     string // интервалы локальных свечей из сделок: 1s,1m,5m
+		FlushInterval int    // интервал записи закрытых свечей в БД, секунды
+	}
+	Futures struct {
+		Endpoints map[string]string // адреса бессрочных контрактов: binance_rest, binance_ws, ... (не задано - по умолчанию)
+	}
+	Funding struct {
+		Enabled       bool // сбор ставок финансирования и запись расчетов в FUNDING_RATE_HISTORY
+		FlushInterval int  // интервал записи расчетов в БД, секунды
+	}
 }
 
 // LoadConfig загружает конфиг из файла
@@ -71,9 +124,90 @@ func LoadConfig(path string) (*Config, error) {
 	cfg.OrderBook.DebugLogRaw = file.Section("orderbook").Key("debug_log_raw").MustBool(false)
 	cfg.OrderBook.DebugLogMsg = file.Section("orderbook").Key("debug_log_msg").MustBool(false)
 
+	cfg.Capture.Enabled = file.Section("capture").Key("enabled").MustBool(false)
+	cfg.Capture.Dir = file.Section("capture").Key("dir").MustString("logs/capture")
+	cfg.Capture.Compress = file.Section("capture").Key("compress").MustBool(true)
+
+	cfg.Risk.MaxOrderNotional = file.Section("risk").Key("max_order_notional").MustFloat64(0)
+	cfg.Risk.MaxExchangeNotional = file.Section("risk").Key("max_exchange_notional").MustFloat64(0)
+	cfg.Risk.DailyLossLimit = file.Section("risk").Key("daily_loss_limit").MustFloat64(0)
+	cfg.Risk.MaxOpenOrders = file.Section("risk").Key("max_open_orders").MustInt(0)
+	cfg.Risk.MaxBookAgeMs = file.Section("risk").Key("max_book_age_ms").MustInt(0)
+	cfg.Risk.PriceBandPercent = file.Section("risk").Key("price_band_percent").MustFloat64(0)
+	cfg.Risk.BBOOnly = file.Section("risk").Key("bbo_only").MustBool(false)
+	cfg.Risk.ReservationTTLSec = file.Section("risk").Key("reservation_ttl_sec").MustInt(300)
+
+	cfg.Execution.Enabled = file.Section("execution").Key("enabled").MustBool(false)
+	cfg.Execution.FillTimeoutMs = file.Section("execution").Key("fill_timeout_ms").MustInt(10000)
+	cfg.Execution.PollIntervalMs = file.Section("execution").Key("poll_interval_ms").MustInt(250)
+	cfg.Execution.CancelOnTimeout = file.Section("execution").Key("cancel_on_timeout").MustBool(true)
+
+	cfg.Hedge = loadHedge(file.Section("hedge"))
+	cfg.TradeHedges = loadTradeHedges(file)
+
+	cfg.Balance.RefreshInterval = file.Section("balance").Key("refresh_interval").MustInt(60)
+
+	cfg.Rebalance.Enabled = file.Section("rebalance").Key("enabled").MustBool(false)
+	cfg.Rebalance.Interval = file.Section("rebalance").Key("interval").MustInt(300)
+	cfg.Rebalance.Assets = file.Section("rebalance").Key("assets").String()
+	cfg.Rebalance.Weights = file.Section("rebalance").Key("weights").String()
+	cfg.Rebalance.ThresholdPercent = file.Section("rebalance").Key("threshold_percent").MustFloat64(30)
+	cfg.Rebalance.NetworkFees = file.Section("rebalance").Key("network_fees").String()
+
+	cfg.Catalog.Enabled = file.Section("catalog").Key("enabled").MustBool(false)
+	cfg.Catalog.Interval = file.Section("catalog").Key("interval").MustInt(21600)
+	cfg.Catalog.Quotes = file.Section("catalog").Key("quotes").String()
+
+	cfg.Candles.Enabled = file.Section("candles").Key("enabled").MustBool(false)
+	cfg.Candles.Intervals = file.Section("candles").Key("intervals").MustString("1s,1m,5m")
+	cfg.Candles.FlushInterval = file.Section("candles").Key("flush_interval").MustInt(5)
+
+	cfg.Futures.Endpoints = file.Section("futures").KeysHash()
+
+	cfg.Funding.Enabled = file.Section("funding").Key("enabled").MustBool(false)
+	cfg.Funding.FlushInterval = file.Section("funding").Key("flush_interval").MustInt(5)
+
 	return cfg, nil
 }
 
+// Hedge - политика устранения дисбаланса ног сделки
+type Hedge struct {
+	Actions        string  // действия по порядку через запятую: retry, cross, unwind (пусто - только фиксация)
+	TimeoutMs      int     // на все действия, мс (0 - fill_timeout_ms исполнения)
+	MaxSlippage    float64 // допуск цены retry/cross, доля
+	CrossExchanges string  // биржи для cross через запятую (пусто - все, кроме бирж сделки)
+	MinVolume      float64 // меньший дисбаланс не хеджируется
+}
+
+// loadHedge читает политику дисбаланса из секции; секция [hedge.<ID>] наследует
+// незаданные ключи из [hedge]
+func loadHedge(section *ini.Section) Hedge {
+	return Hedge{
+		Actions:        section.Key("actions").String(),
+		TimeoutMs:      section.Key("timeout_ms").MustInt(0),
+		MaxSlippage:    section.Key("max_slippage").MustFloat64(0),
+		CrossExchanges: section.Key("cross_exchanges").String(),
+		MinVolume:      section.Key("min_volume").MustFloat64(0),
+	}
+}
+
+// loadTradeHedges читает политики дисбаланса записей TRADE из секций [hedge.<ID>]
+func loadTradeHedges(file *ini.File) map[int]Hedge {
+	hedges := make(map[int]Hedge)
+	for _, section := range file.Sections() {
+		idStr, ok := strings.CutPrefix(section.Name(), "hedge.")
+		if !ok {
+			continue
+		}
+		id, err := strconv.Atoi(idStr)
+		if err != nil {
+			continue
+		}
+		hedges[id] = loadHedge(section)
+	}
+	return hedges
+}
+
 // GetConfigForLogging returns a copy of config with masked sensitive data for logging
 func GetConfigForLogging(cfg *Config) *Config {
 	if cfg == nil {
@@ -3,6 +3,7 @@ package db
 import (
 	"database/sql"
 	"fmt"
+	"sort"
 	"strings"
 
 	sqlMySQL "daemon-go/internal/sql/mysql"
@@ -25,7 +26,24 @@ type MySQLDriver struct {
 
 // GetActivePairsForDataMonitor возвращает пары (EXCHANGE_ID, PAIR_ID, SYMBOL, MARKET_TYPE) для DataMonitor
 func (m *MySQLDriver) GetActivePairsForDataMonitor() ([]DataMonitorPair, error) {
-	rows, err := m.DB.Query(sqlMySQL.DataMonitorPairs)
+	pairs, err := m.queryDataMonitorPairs(sqlMySQL.DataMonitorPairs)
+	if err != nil {
+		return nil, err
+	}
+	// Бессрочные контракты - отдельным запросом: без их таблиц спот продолжает работать
+	futures, err := m.queryDataMonitorPairs(sqlMySQL.DataMonitorFuturesPairs)
+	if err != nil {
+		mysqlLogger.Warn("DataMonitor futures pairs skipped: %v", err)
+		return pairs, nil
+	}
+	pairs = append(pairs, futures...)
+	sort.SliceStable(pairs, func(i, j int) bool { return pairs[i].ExchangeID < pairs[j].ExchangeID })
+	return pairs, nil
+}
+
+// queryDataMonitorPairs выполняет запрос пар DataMonitor
+func (m *MySQLDriver) queryDataMonitorPairs(query string) ([]DataMonitorPair, error) {
+	rows, err := m.DB.Query(query)
 	if err != nil {
 		return nil, err
 	}
@@ -42,7 +60,7 @@ func (m *MySQLDriver) GetActivePairsForDataMonitor() ([]DataMonitorPair, error)
 		p.MarketType = strings.ToLower(p.MarketType)
 		pairs = append(pairs, p)
 	}
-	return pairs, nil
+	return pairs, rows.Err()
 }
 
 func (m *MySQLDriver) Connect() error {
@@ -78,10 +96,17 @@ func (m *MySQLDriver) GetActiveTrades() ([]TradeCase, error) {
 	var trades []TradeCase
 	for rows.Next() {
 		var t TradeCase
-		if err := rows.Scan(&t.ID); err != nil {
-			mysqlLogger.Error("Error scanning trade: %v", err)
+		var tradeType sql.NullInt64
+		if err := rows.Scan(&t.ID, &tradeType, &t.MaxAmountTrade, &t.FinProtection, &t.BBOOnly); err != nil {
+			mysqlLogger.Error("Skipping active trade: scan error: %v", err)
 			continue
 		}
+		t.Type = DefaultTradeType
+		if tradeType.Valid {
+			t.Type = int(tradeType.Int64)
+		} else {
+			mysqlLogger.Warn("TRADE %d has no TYPE, using default type %d", t.ID, DefaultTradeType)
+		}
 		trades = append(trades, t)
 	}
 	return trades, nil
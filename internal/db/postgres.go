@@ -3,6 +3,8 @@ package db
 import (
 	"database/sql"
 	"fmt"
+	"sort"
+	"strings"
 
 	sqlPostgres "daemon-go/internal/sql/postgres"
 	"daemon-go/pkg/log"
@@ -57,10 +59,17 @@ func (p *PostgresDriver) GetActiveTrades() ([]TradeCase, error) {
 	var trades []TradeCase
 	for rows.Next() {
 		var t TradeCase
-		if err := rows.Scan(&t.ID); err != nil {
-			pgLogger.Error("Error scanning trade: %v", err)
+		var tradeType sql.NullInt64
+		if err := rows.Scan(&t.ID, &tradeType, &t.MaxAmountTrade, &t.FinProtection, &t.BBOOnly); err != nil {
+			pgLogger.Error("Skipping active trade: scan error: %v", err)
 			continue
 		}
+		t.Type = DefaultTradeType
+		if tradeType.Valid {
+			t.Type = int(tradeType.Int64)
+		} else {
+			pgLogger.Warn("TRADE %d has no TYPE, using default type %d", t.ID, DefaultTradeType)
+		}
 		trades = append(trades, t)
 	}
 	return trades, nil
@@ -68,7 +77,24 @@ func (p *PostgresDriver) GetActiveTrades() ([]TradeCase, error) {
 
 // Заглушка для универсального метода DataMonitor (реализовать по аналогии с MySQL при необходимости)
 func (p *PostgresDriver) GetActivePairsForDataMonitor() ([]DataMonitorPair, error) {
-	rows, err := p.DB.Query(sqlPostgres.DataMonitorPairs)
+	pairs, err := p.queryDataMonitorPairs(sqlPostgres.DataMonitorPairs)
+	if err != nil {
+		return nil, err
+	}
+	// Бессрочные контракты - отдельным запросом: без их таблиц спот продолжает работать
+	futures, err := p.queryDataMonitorPairs(sqlPostgres.DataMonitorFuturesPairs)
+	if err != nil {
+		pgLogger.Warn("DataMonitor futures pairs skipped: %v", err)
+		return pairs, nil
+	}
+	pairs = append(pairs, futures...)
+	sort.SliceStable(pairs, func(i, j int) bool { return pairs[i].ExchangeID < pairs[j].ExchangeID })
+	return pairs, nil
+}
+
+// queryDataMonitorPairs выполняет запрос пар DataMonitor
+func (p *PostgresDriver) queryDataMonitorPairs(query string) ([]DataMonitorPair, error) {
+	rows, err := p.DB.Query(query)
 	if err != nil {
 		return nil, err
 	}
@@ -81,9 +107,11 @@ func (p *PostgresDriver) GetActivePairsForDataMonitor() ([]DataMonitorPair, erro
 			pgLogger.Error("Error scanning DataMonitorPair: %v", err)
 			continue
 		}
+		// Приводим MarketType к нижнему регистру для совместимости с адаптерами
+		p.MarketType = strings.ToLower(p.MarketType)
 		pairs = append(pairs, p)
 	}
-	return pairs, nil
+	return pairs, rows.Err()
 }
 
 // GetExchangeByName возвращает Exchange по имени
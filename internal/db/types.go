@@ -34,9 +34,28 @@ type DBDriver interface {
 	GetType() string
 }
 
-// TradeCase структура для торгов
+// DefaultTradeType - тип торгов для записей TRADE без TYPE (межбиржевой арбитраж)
+const DefaultTradeType = 1
+
+// TradeCase структура для торгов (активная запись TRADE)
 type TradeCase struct {
-	ID int
+	ID             int
+	Type           int     // TRADE.TYPE - определяет стратегию
+	MaxAmountTrade float64 // TRADE.MAX_AMOUNT_TRADE - предел стоимости ордера (0 - без ограничения)
+	FinProtection  bool    // TRADE.FIN_PROTECTION - остановка торговли по дневному лимиту убытка
+	BBOOnly        bool    // TRADE.BBO_ONLY - цены ордеров не глубже лучших цен стакана
+}
+
+// Trade возвращает риск-настройки записи в виде Trade
+func (t TradeCase) Trade() Trade {
+	return Trade{
+		ID:             t.ID,
+		Type:           t.Type,
+		Active:         true,
+		MaxAmountTrade: t.MaxAmountTrade,
+		FinProtection:  t.FinProtection,
+		BBOOnly:        t.BBOOnly,
+	}
 }
 
 // Trade — структура, соответствующая записи из таблицы TRADE
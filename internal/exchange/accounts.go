@@ -0,0 +1,46 @@
+package exchange
+
+import (
+	"fmt"
+
+	"daemon-go/internal/db"
+	sqlMySQL "daemon-go/internal/sql/mysql"
+	sqlPostgres "daemon-go/internal/sql/postgres"
+)
+
+// TradingAccount - аккаунт биржи с ключами API (EXCHANGE_ACCOUNTS)
+type TradingAccount struct {
+	ID       int         // EXCHANGE_ACCOUNTS.ID
+	Active   bool        // EXCHANGE_ACCOUNTS.ACTIVE
+	Exchange db.Exchange // биржа с ключами аккаунта для NewTradingAdapter
+}
+
+// LoadTradingAccounts загружает аккаунты бирж с ключами API, включая неактивные
+func LoadTradingAccounts(driver db.DBDriver) ([]TradingAccount, error) {
+	query := sqlMySQL.TradingAccounts
+	if driver.GetType() == "postgres" {
+		query = sqlPostgres.TradingAccounts
+	}
+
+	rows, err := driver.Query(query)
+	if err != nil {
+		return nil, fmt.Errorf("load trading accounts: %w", err)
+	}
+	defer rows.Close()
+
+	var accounts []TradingAccount
+	for rows.Next() {
+		var acc TradingAccount
+		ex := &acc.Exchange
+		if err := rows.Scan(&acc.ID, &ex.ID, &ex.Name, &ex.Url, &ex.BaseUrl,
+			&ex.ApiKey, &ex.ApiSecret, &ex.Passphrase, &acc.Active); err != nil {
+			return nil, fmt.Errorf("scan trading account: %w", err)
+		}
+		ex.Active = acc.Active
+		accounts = append(accounts, acc)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("load trading accounts: %w", err)
+	}
+	return accounts, nil
+}
@@ -2,6 +2,7 @@ package exchange
 
 import (
 	"daemon-go/internal/bus"
+	"daemon-go/internal/capture"
 	"daemon-go/internal/db"
 	"daemon-go/internal/market"
 	"daemon-go/internal/market/parsers"
@@ -24,7 +25,9 @@ type BinanceAdapter struct {
 	logger         *log.Logger
 	parser         *parsers.BinanceParser
 	messageBus     *bus.MessageBus
-	pairIDMap      map[string]int // symbol -> pairID маппинг
+	bookSync       *OrderBookSync  // контроль последовательности обновлений стакана
+	pairIDMap      map[string]int  // symbol -> pairID маппинг
+	userData       *userDataStream // приватный поток ордеров и балансов
 }
 
 // SubscribeMarkets для Binance с подробным логированием
@@ -42,14 +45,18 @@ func (a *BinanceAdapter) SubscribeMarkets(pairs []string, marketType string, dep
 
 	a.logger.Debug("[BINANCE_ADAPTER] WebSocket is connected, proceeding with subscriptions")
 
+	// Стакан - diff поток @depth@100ms (U/u): начальный снимок и разрывы восстанавливает
+	// OrderBookSync по REST /api/v3/depth; частичный @depth<N> не дает последовательности
 	var streams []string
 	for _, pair := range pairs {
 		symbol := pair
 		symbol = strings.ReplaceAll(strings.ToLower(symbol), "-", "")
 		a.logger.Debug("[BINANCE_ADAPTER] Processing pair: %s -> symbol: %s", pair, symbol)
 
-		streams = append(streams, fmt.Sprintf("%s@depth%d", symbol, depth))
+		streams = append(streams, fmt.Sprintf("%s@depth@100ms", symbol))
 		streams = append(streams, fmt.Sprintf("%s@bookTicker", symbol))
+		streams = append(streams, fmt.Sprintf("%s@aggTrade", symbol))
+		streams = append(streams, fmt.Sprintf("%s@kline_1m", symbol))
 	}
 
 	sub := map[string]interface{}{
@@ -88,8 +95,10 @@ func (a *BinanceAdapter) UnsubscribeMarkets(pairs []string, marketType string, d
 		symbol = strings.ReplaceAll(strings.ToLower(symbol), "-", "")
 		a.logger.Debug("[BINANCE_ADAPTER] Unsubscribing from pair: %s -> symbol: %s", pair, symbol)
 
-		streams = append(streams, fmt.Sprintf("%s@depth%d", symbol, depth))
+		streams = append(streams, fmt.Sprintf("%s@depth@100ms", symbol))
 		streams = append(streams, fmt.Sprintf("%s@bookTicker", symbol))
+		streams = append(streams, fmt.Sprintf("%s@aggTrade", symbol))
+		streams = append(streams, fmt.Sprintf("%s@kline_1m", symbol))
 	}
 
 	unsub := map[string]interface{}{
@@ -119,7 +128,7 @@ func NewBinanceAdapter(ex db.Exchange) *BinanceAdapter {
 	if ex.WsUrl.Valid && ex.WsUrl.String != "" {
 		wsClient = NewCexWsClient(ex.WsUrl.String)
 	}
-	return &BinanceAdapter{
+	a := &BinanceAdapter{
 		exchange:   ex,
 		rest:       NewCexRestClient(ex.BaseUrl),
 		ws:         wsClient,
@@ -129,6 +138,10 @@ func NewBinanceAdapter(ex db.Exchange) *BinanceAdapter {
 		messageBus: bus.GetInstance(),
 		pairIDMap:  make(map[string]int),
 	}
+	a.bookSync = NewOrderBookSync("binance", a.fetchOrderBookSnapshot, func(msg market.UnifiedMessage) {
+		a.messageBus.Publish("binance", msg)
+	})
+	return a
 }
 
 func (a *BinanceAdapter) Start() error {
@@ -171,7 +184,7 @@ func (a *BinanceAdapter) readLoopWithReconnect() {
 			return
 		}
 
-		_, message, err := a.ws.ReadMessage()
+		msgType, message, err := a.ws.ReadMessage()
 		if err != nil {
 			a.logger.Error("[BINANCE_ADAPTER] Read error: %v, reconnecting...", err)
 			for {
@@ -183,6 +196,7 @@ func (a *BinanceAdapter) readLoopWithReconnect() {
 				a.logger.Debug("[BINANCE_ADAPTER] Attempting reconnection...")
 				if err := a.ws.Reconnect(); err == nil {
 					a.logger.Info("[BINANCE_ADAPTER] Reconnected successfully, resubscribing...")
+					a.bookSync.Reset()
 					if err := a.SubscribeMarkets(a.lastPairs, a.lastMarketType, a.lastDepth); err != nil {
 						a.logger.Error("[BINANCE_ADAPTER] Resubscribe error: %v", err)
 					} else {
@@ -195,6 +209,7 @@ func (a *BinanceAdapter) readLoopWithReconnect() {
 			}
 			continue
 		}
+		capture.Record("binance", msgType, message)
 
 		// Парсим WebSocket сообщение
 		if len(message) > 0 {
@@ -214,7 +229,11 @@ func (a *BinanceAdapter) readLoopWithReconnect() {
 				} else {
 					a.logger.Debug("[BINANCE_ADAPTER] Publishing message for pair %s (no ID mapping)", msg.Symbol)
 				}
-				a.messageBus.Publish("binance", msg)
+				if msg.MessageType == market.MessageTypeOrderBook {
+					a.bookSync.Process(msg)
+				} else {
+					a.messageBus.Publish("binance", msg)
+				}
 			}
 		}
 	}
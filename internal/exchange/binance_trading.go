@@ -0,0 +1,254 @@
+package exchange
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"daemon-go/internal/market"
+)
+
+// binanceOrder - ответ Binance по ордеру (/api/v3/order)
+type binanceOrder struct {
+	Symbol              string `json:"symbol"`
+	OrderID             int64  `json:"orderId"`
+	ClientOrderID       string `json:"clientOrderId"`
+	Price               string `json:"price"`
+	OrigQty             string `json:"origQty"`
+	ExecutedQty         string `json:"executedQty"`
+	CummulativeQuoteQty string `json:"cummulativeQuoteQty"`
+	Status              string `json:"status"`
+	Type                string `json:"type"`
+	Side                string `json:"side"`
+	Time                int64  `json:"time"`
+	TransactTime        int64  `json:"transactTime"`
+	UpdateTime          int64  `json:"updateTime"`
+	Fills               []struct {
+		Commission      string `json:"commission"`
+		CommissionAsset string `json:"commissionAsset"`
+	} `json:"fills"`
+}
+
+// binanceTrade - комиссия сделки ордера (/api/v3/myTrades, /fapi/v1/userTrades)
+type binanceTrade struct {
+	Commission      string `json:"commission"`
+	CommissionAsset string `json:"commissionAsset"`
+}
+
+// addBinanceTradeFees записывает в ордер сумму комиссий его сделок
+func addBinanceTradeFees(order *market.Order, trades []binanceTrade) {
+	order.Fee = 0
+	for _, t := range trades {
+		order.Fee += parseFloatString(t.Commission)
+		order.FeeCurrency = t.CommissionAsset
+	}
+}
+
+// signedRequest выполняет подписанный запрос к Binance: подпись HMAC-SHA256 (hex) от query string
+func (a *BinanceAdapter) signedRequest(method, path string, params url.Values, result interface{}) error {
+	if err := checkCredentials("BinanceAdapter", a.exchange.ApiKey, a.exchange.ApiSecret); err != nil {
+		return err
+	}
+	if params == nil {
+		params = url.Values{}
+	}
+	params.Set("timestamp", nowMillis())
+	params.Set("recvWindow", strconv.Itoa(recvWindow))
+	query := params.Encode()
+	query += "&signature=" + signHex(a.exchange.ApiSecret, query)
+
+	body, status, err := a.rest.DoSigned(method, path+"?"+query, nil, map[string]string{
+		"X-MBX-APIKEY": a.exchange.ApiKey,
+	})
+	if err != nil {
+		return fmt.Errorf("BinanceAdapter: %s %s: %w", method, path, err)
+	}
+	if status != http.StatusOK {
+		var apiErr struct {
+			Code int    `json:"code"`
+			Msg  string `json:"msg"`
+		}
+		_ = json.Unmarshal(body, &apiErr)
+		return fmt.Errorf("BinanceAdapter: %s %s: status %d, code %d: %s", method, path, status, apiErr.Code, apiErr.Msg)
+	}
+	if result == nil {
+		return nil
+	}
+	return json.Unmarshal(body, result)
+}
+
+// PlaceOrder размещает лимитный или рыночный ордер
+func (a *BinanceAdapter) PlaceOrder(req market.OrderRequest) (*market.Order, error) {
+	symbol, err := exchangeSymbol(req.Symbol, "")
+	if err != nil {
+		return nil, fmt.Errorf("BinanceAdapter: %w", err)
+	}
+
+	params := url.Values{}
+	params.Set("symbol", symbol)
+	params.Set("side", strings.ToUpper(string(req.Side)))
+	params.Set("quantity", formatFloat(req.Volume))
+	params.Set("newOrderRespType", "FULL")
+	if req.ClientOrderID != "" {
+		params.Set("newClientOrderId", req.ClientOrderID)
+	}
+	switch req.OrderType {
+	case market.OrderTypeMarket:
+		params.Set("type", "MARKET")
+	default:
+		params.Set("type", "LIMIT")
+		params.Set("timeInForce", "GTC")
+		params.Set("price", formatFloat(req.Price))
+	}
+
+	var resp binanceOrder
+	if err := a.signedRequest(http.MethodPost, "/api/v3/order", params, &resp); err != nil {
+		return nil, err
+	}
+	a.logger.Info("[BINANCE_ADAPTER] Order placed: %s %s %s %s@%s id=%d",
+		symbol, resp.Side, resp.Type, resp.OrigQty, resp.Price, resp.OrderID)
+
+	return a.convertOrder(resp, req.Symbol), nil
+}
+
+// CancelOrder отменяет ордер
+func (a *BinanceAdapter) CancelOrder(symbol, orderID string) error {
+	exSymbol, err := exchangeSymbol(symbol, "")
+	if err != nil {
+		return fmt.Errorf("BinanceAdapter: %w", err)
+	}
+	params := url.Values{}
+	params.Set("symbol", exSymbol)
+	params.Set("orderId", orderID)
+	return a.signedRequest(http.MethodDelete, "/api/v3/order", params, nil)
+}
+
+// GetOrder возвращает текущее состояние ордера. Ответ /api/v3/order не содержит комиссий:
+// для исполненного объема они суммируются по сделкам ордера (/api/v3/myTrades)
+func (a *BinanceAdapter) GetOrder(symbol, orderID string) (*market.Order, error) {
+	exSymbol, err := exchangeSymbol(symbol, "")
+	if err != nil {
+		return nil, fmt.Errorf("BinanceAdapter: %w", err)
+	}
+	params := url.Values{}
+	params.Set("symbol", exSymbol)
+	params.Set("orderId", orderID)
+
+	var resp binanceOrder
+	if err := a.signedRequest(http.MethodGet, "/api/v3/order", params, &resp); err != nil {
+		return nil, err
+	}
+	order := a.convertOrder(resp, symbol)
+	if order.FilledVolume <= 0 {
+		return order, nil
+	}
+
+	var trades []binanceTrade
+	if err := a.signedRequest(http.MethodGet, "/api/v3/myTrades", params, &trades); err != nil {
+		return nil, err
+	}
+	addBinanceTradeFees(order, trades)
+	return order, nil
+}
+
+// GetOpenOrders возвращает открытые ордера (по символу или все при пустом symbol)
+func (a *BinanceAdapter) GetOpenOrders(symbol string) ([]market.Order, error) {
+	params := url.Values{}
+	if symbol != "" {
+		exSymbol, err := exchangeSymbol(symbol, "")
+		if err != nil {
+			return nil, fmt.Errorf("BinanceAdapter: %w", err)
+		}
+		params.Set("symbol", exSymbol)
+	}
+
+	var resp []binanceOrder
+	if err := a.signedRequest(http.MethodGet, "/api/v3/openOrders", params, &resp); err != nil {
+		return nil, err
+	}
+
+	orders := make([]market.Order, 0, len(resp))
+	for _, o := range resp {
+		orders = append(orders, *a.convertOrder(o, unifySymbol(o.Symbol)))
+	}
+	return orders, nil
+}
+
+// GetBalances возвращает ненулевые балансы спотового аккаунта
+func (a *BinanceAdapter) GetBalances() ([]market.Balance, error) {
+	var resp struct {
+		Balances []struct {
+			Asset  string `json:"asset"`
+			Free   string `json:"free"`
+			Locked string `json:"locked"`
+		} `json:"balances"`
+	}
+	if err := a.signedRequest(http.MethodGet, "/api/v3/account", nil, &resp); err != nil {
+		return nil, err
+	}
+
+	balances := make([]market.Balance, 0)
+	for _, b := range resp.Balances {
+		balance := market.Balance{
+			Asset:  b.Asset,
+			Free:   parseFloatString(b.Free),
+			Locked: parseFloatString(b.Locked),
+		}
+		if balance.Total() > 0 {
+			balances = append(balances, balance)
+		}
+	}
+	return balances, nil
+}
+
+// convertOrder переводит ответ Binance в market.Order
+func (a *BinanceAdapter) convertOrder(o binanceOrder, symbol string) *market.Order {
+	filled := parseFloatString(o.ExecutedQty)
+	order := &market.Order{
+		Exchange:      "binance",
+		Symbol:        symbol,
+		OrderID:       strconv.FormatInt(o.OrderID, 10),
+		ClientOrderID: o.ClientOrderID,
+		Status:        binanceOrderStatus(o.Status),
+		Side:          market.TradeSide(strings.ToLower(o.Side)),
+		OrderType:     market.OrderType(strings.ToLower(o.Type)),
+		Price:         parseFloatString(o.Price),
+		Volume:        parseFloatString(o.OrigQty),
+		FilledVolume:  filled,
+		AvgPrice:      avgPrice(parseFloatString(o.CummulativeQuoteQty), filled),
+		CreatedAt:     millisToTime(o.Time),
+		UpdatedAt:     millisToTime(o.UpdateTime),
+	}
+	if order.CreatedAt.IsZero() {
+		order.CreatedAt = millisToTime(o.TransactTime)
+		order.UpdatedAt = order.CreatedAt
+	}
+	for _, f := range o.Fills {
+		order.Fee += parseFloatString(f.Commission)
+		order.FeeCurrency = f.CommissionAsset
+	}
+	return order
+}
+
+// binanceOrderStatus переводит статус ордера Binance в унифицированный
+func binanceOrderStatus(status string) market.OrderStatus {
+	switch status {
+	case "NEW", "PENDING_NEW":
+		return market.OrderStatusNew
+	case "PARTIALLY_FILLED", "PENDING_CANCEL":
+		return market.OrderStatusPartiallyFilled
+	case "FILLED":
+		return market.OrderStatusFilled
+	case "CANCELED":
+		return market.OrderStatusCanceled
+	case "REJECTED":
+		return market.OrderStatusRejected
+	case "EXPIRED", "EXPIRED_IN_MATCH":
+		return market.OrderStatusExpired
+	default:
+		return market.OrderStatusNew
+	}
+}
@@ -0,0 +1,38 @@
+package exchange
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"daemon-go/internal/db"
+)
+
+// Ответ /api/v3/order без комиссий: GetOrder берет их из сделок ордера
+func TestBinanceGetOrderFeesFromTrades(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v3/order":
+			_, _ = w.Write([]byte(`{"symbol":"BTCUSDT","orderId":7,"price":"100","origQty":"2","executedQty":"2",
+				"cummulativeQuoteQty":"200","status":"FILLED","type":"LIMIT","side":"BUY","time":1700000000000}`))
+		case "/api/v3/myTrades":
+			if r.URL.Query().Get("orderId") != "7" {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			_, _ = w.Write([]byte(`[{"commission":"0.001","commissionAsset":"BTC"},{"commission":"0.001","commissionAsset":"BTC"}]`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	a := NewBinanceAdapter(db.Exchange{Name: "binance", BaseUrl: srv.URL, ApiKey: "key", ApiSecret: "secret"})
+	order, err := a.GetOrder("BTC/USDT", "7")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if order.AvgPrice != 100 || order.Fee != 0.002 || order.FeeCurrency != "BTC" {
+		t.Fatalf("order %+v, want avg 100 and fee 0.002 BTC", order)
+	}
+}
@@ -0,0 +1,198 @@
+package exchange
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"daemon-go/internal/market"
+)
+
+// binanceUserDataWsURL - WebSocket для user data stream, к URL добавляется listenKey
+const binanceUserDataWsURL = "wss://stream.binance.com:9443/ws/"
+
+// binanceExecutionReport - событие executionReport user data stream.
+// Ключи отличаются только регистром (c/C, o/O, q/Q ...), а encoding/json сопоставляет
+// их без учета регистра, поэтому парные поля объявлены явно
+type binanceExecutionReport struct {
+	Event           string `json:"e"`
+	EventTime       int64  `json:"E"`
+	Symbol          string `json:"s"`
+	ClientOrderID   string `json:"c"`
+	Side            string `json:"S"`
+	Type            string `json:"o"`
+	Quantity        string `json:"q"`
+	Price           string `json:"p"`
+	OrigClientID    string `json:"C"` // исходный clientOrderId для отмены
+	ExecutionType   string `json:"x"`
+	Status          string `json:"X"`
+	OrderID         int64  `json:"i"`
+	LastQty         string `json:"l"`
+	CumQty          string `json:"z"`
+	LastPrice       string `json:"L"`
+	Commission      string `json:"n"`
+	CommissionAsset string `json:"N"`
+	TradeTime       int64  `json:"T"`
+	TradeID         int64  `json:"t"`
+	CumQuoteQty     string `json:"Z"`
+	CreationTime    int64  `json:"O"`
+	QuoteQty        string `json:"Q"`
+	StopPrice       string `json:"P"`
+	Ignore          int64  `json:"I"`
+}
+
+// binanceAccountPosition - событие outboundAccountPosition (абсолютные балансы)
+type binanceAccountPosition struct {
+	Event     string `json:"e"`
+	EventTime int64  `json:"E"`
+	Balances  []struct {
+		Asset  string `json:"a"`
+		Free   string `json:"f"`
+		Locked string `json:"l"`
+	} `json:"B"`
+}
+
+// StartUserData запускает user data stream: listenKey получается через REST
+// и продлевается каждые 30 минут
+func (a *BinanceAdapter) StartUserData() error {
+	if err := checkCredentials("BinanceAdapter", a.exchange.ApiKey, a.exchange.ApiSecret); err != nil {
+		return err
+	}
+	if a.userData == nil {
+		var listenKey string
+		stream := newUserDataStream("binance")
+		stream.dial = func() (string, error) {
+			key, err := a.createListenKey()
+			if err != nil {
+				return "", err
+			}
+			listenKey = key
+			return binanceUserDataWsURL + key, nil
+		}
+		stream.handle = func(_ *CexWsClient, _ int, data []byte) ([]market.UnifiedMessage, error) {
+			return parseBinanceUserData(data)
+		}
+		stream.keepAlive = func() error { return a.keepAliveListenKey(listenKey) }
+		stream.keepAliveInterval = 30 * time.Minute
+		a.userData = stream
+	}
+	return a.userData.start()
+}
+
+// StopUserData останавливает user data stream
+func (a *BinanceAdapter) StopUserData() error {
+	if a.userData == nil {
+		return nil
+	}
+	return a.userData.stopStream()
+}
+
+// createListenKey создает listenKey (запрос только с X-MBX-APIKEY, без подписи)
+func (a *BinanceAdapter) createListenKey() (string, error) {
+	body, status, err := a.rest.DoSigned(http.MethodPost, "/api/v3/userDataStream", nil, map[string]string{
+		"X-MBX-APIKEY": a.exchange.ApiKey,
+	})
+	if err != nil {
+		return "", fmt.Errorf("BinanceAdapter: create listenKey: %w", err)
+	}
+	if status != http.StatusOK {
+		return "", fmt.Errorf("BinanceAdapter: create listenKey: status %d: %s", status, string(body))
+	}
+	var resp struct {
+		ListenKey string `json:"listenKey"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return "", fmt.Errorf("BinanceAdapter: create listenKey: %w", err)
+	}
+	return resp.ListenKey, nil
+}
+
+// keepAliveListenKey продлевает listenKey (без продления он истекает через 60 минут)
+func (a *BinanceAdapter) keepAliveListenKey(listenKey string) error {
+	body, status, err := a.rest.DoSigned(http.MethodPut, "/api/v3/userDataStream?listenKey="+listenKey, nil, map[string]string{
+		"X-MBX-APIKEY": a.exchange.ApiKey,
+	})
+	if err != nil {
+		return fmt.Errorf("BinanceAdapter: keep-alive listenKey: %w", err)
+	}
+	if status != http.StatusOK {
+		return fmt.Errorf("BinanceAdapter: keep-alive listenKey: status %d: %s", status, string(body))
+	}
+	return nil
+}
+
+// parseBinanceUserData разбирает событие user data stream
+func parseBinanceUserData(data []byte) ([]market.UnifiedMessage, error) {
+	var head struct {
+		Event string `json:"e"`
+	}
+	if err := json.Unmarshal(data, &head); err != nil {
+		return nil, fmt.Errorf("binance user data: %w", err)
+	}
+
+	switch head.Event {
+	case "executionReport":
+		var r binanceExecutionReport
+		if err := json.Unmarshal(data, &r); err != nil {
+			return nil, fmt.Errorf("binance executionReport: %w", err)
+		}
+		return []market.UnifiedMessage{orderEventMessage(convertBinanceExecutionReport(r))}, nil
+
+	case "outboundAccountPosition":
+		var p binanceAccountPosition
+		if err := json.Unmarshal(data, &p); err != nil {
+			return nil, fmt.Errorf("binance outboundAccountPosition: %w", err)
+		}
+		balances := make([]market.Balance, 0, len(p.Balances))
+		for _, b := range p.Balances {
+			balances = append(balances, market.Balance{
+				Asset:  b.Asset,
+				Free:   parseFloatString(b.Free),
+				Locked: parseFloatString(b.Locked),
+			})
+		}
+		return []market.UnifiedMessage{balanceMessage(millisToTime(p.EventTime), balances, p)}, nil
+
+	default:
+		// balanceUpdate (дельта) не нужен: за ним приходит outboundAccountPosition
+		return nil, nil
+	}
+}
+
+// convertBinanceExecutionReport переводит executionReport в UnifiedOrderEvent
+func convertBinanceExecutionReport(r binanceExecutionReport) market.UnifiedOrderEvent {
+	volume := parseFloatString(r.Quantity)
+	filled := parseFloatString(r.CumQty)
+	clientID := r.ClientOrderID
+	if r.OrigClientID != "" {
+		clientID = r.OrigClientID
+	}
+
+	event := market.UnifiedOrderEvent{
+		Symbol:          unifySymbol(r.Symbol),
+		Timestamp:       millisToTime(r.EventTime),
+		OrderID:         strconv.FormatInt(r.OrderID, 10),
+		ClientOrderID:   clientID,
+		Status:          binanceOrderStatus(r.Status),
+		Side:            market.TradeSide(strings.ToLower(r.Side)),
+		OrderType:       market.OrderType(strings.ToLower(r.Type)),
+		Price:           parseFloatString(r.Price),
+		Volume:          volume,
+		FilledVolume:    filled,
+		RemainingVolume: remaining(volume, filled),
+		AvgPrice:        avgPrice(parseFloatString(r.CumQuoteQty), filled),
+		FeeCurrency:     r.CommissionAsset,
+		Raw:             r,
+	}
+	if r.ExecutionType == "TRADE" {
+		event.TradeID = strconv.FormatInt(r.TradeID, 10)
+		event.LastFillPrice = parseFloatString(r.LastPrice)
+		event.LastFillVolume = parseFloatString(r.LastQty)
+		event.LastFillFee = parseFloatString(r.Commission)
+		event.Timestamp = millisToTime(r.TradeTime)
+	}
+	return event
+}
@@ -2,7 +2,9 @@ package exchange
 
 import (
 	"daemon-go/internal/bus"
+	"daemon-go/internal/capture"
 	"daemon-go/internal/db"
+	"daemon-go/internal/market"
 	"daemon-go/internal/market/parsers"
 	"daemon-go/pkg/log"
 	"encoding/json"
@@ -23,7 +25,9 @@ type BybitAdapter struct {
 	logger         *log.Logger
 	parser         *parsers.BybitParser
 	messageBus     *bus.MessageBus
-	pairIDMap      map[string]int // symbol -> pairID маппинг
+	bookSync       *OrderBookSync  // контроль последовательности обновлений стакана
+	pairIDMap      map[string]int  // symbol -> pairID маппинг
+	userData       *userDataStream // приватный поток ордеров и балансов
 }
 
 // SubscribeMarkets для Bybit с подробным логированием
@@ -80,7 +84,41 @@ func (a *BybitAdapter) SubscribeMarkets(pairs []string, marketType string, depth
 			return fmt.Errorf("BybitAdapter: ws ticker sub: %w", err)
 		}
 
-		a.logger.Info("[BYBIT_ADAPTER] Successfully subscribed to %s (orderbook + ticker)", symbol)
+		// Подписка на сделки для Bybit - используем формат publicTrade.{symbol}
+		subTrade := map[string]interface{}{
+			"op":   "subscribe",
+			"args": []string{fmt.Sprintf("publicTrade.%s", symbol)},
+		}
+		dataTrade, err := json.Marshal(subTrade)
+		if err != nil {
+			a.logger.Error("[BYBIT_ADAPTER] Failed to marshal trade subscription: %v", err)
+			return fmt.Errorf("BybitAdapter: marshal trade sub: %w", err)
+		}
+
+		a.logger.Debug("[BYBIT_ADAPTER] Sending trade subscription: %s", string(dataTrade))
+		if err := a.ws.WriteMessage(1, dataTrade); err != nil {
+			a.logger.Error("[BYBIT_ADAPTER] Failed to send trade subscription: %v", err)
+			return fmt.Errorf("BybitAdapter: ws trade sub: %w", err)
+		}
+
+		// Подписка на минутные свечи для Bybit - используем формат kline.1.{symbol}
+		subKline := map[string]interface{}{
+			"op":   "subscribe",
+			"args": []string{fmt.Sprintf("kline.1.%s", symbol)},
+		}
+		dataKline, err := json.Marshal(subKline)
+		if err != nil {
+			a.logger.Error("[BYBIT_ADAPTER] Failed to marshal kline subscription: %v", err)
+			return fmt.Errorf("BybitAdapter: marshal kline sub: %w", err)
+		}
+
+		a.logger.Debug("[BYBIT_ADAPTER] Sending kline subscription: %s", string(dataKline))
+		if err := a.ws.WriteMessage(1, dataKline); err != nil {
+			a.logger.Error("[BYBIT_ADAPTER] Failed to send kline subscription: %v", err)
+			return fmt.Errorf("BybitAdapter: ws kline sub: %w", err)
+		}
+
+		a.logger.Info("[BYBIT_ADAPTER] Successfully subscribed to %s (orderbook + ticker + trades + klines)", symbol)
 	}
 
 	a.logger.Info("[BYBIT_ADAPTER] All subscriptions completed successfully")
@@ -95,7 +133,7 @@ func NewBybitAdapter(ex db.Exchange) *BybitAdapter {
 	}
 
 	logger := log.New("bybit_adapter")
-	return &BybitAdapter{
+	a := &BybitAdapter{
 		exchange:   ex,
 		rest:       NewCexRestClient(ex.BaseUrl),
 		ws:         wsClient,
@@ -105,6 +143,10 @@ func NewBybitAdapter(ex db.Exchange) *BybitAdapter {
 		messageBus: bus.GetInstance(),
 		pairIDMap:  make(map[string]int),
 	}
+	a.bookSync = NewOrderBookSync("bybit", a.fetchOrderBookSnapshot, func(msg market.UnifiedMessage) {
+		a.messageBus.Publish("bybit", msg)
+	})
+	return a
 }
 
 // UnsubscribeMarkets реализует отписку от пар для Bybit
@@ -151,6 +193,36 @@ func (a *BybitAdapter) UnsubscribeMarkets(pairs []string, marketType string, dep
 			return fmt.Errorf("BybitAdapter: ws ticker unsub: %w", err)
 		}
 
+		// Отписка от сделок для Bybit
+		unsubTrade := map[string]interface{}{
+			"op":   "unsubscribe",
+			"args": []string{fmt.Sprintf("publicTrade.%s", symbol)},
+		}
+		dataTrade, err := json.Marshal(unsubTrade)
+		if err != nil {
+			a.logger.Error("[BYBIT_ADAPTER] Failed to marshal trade unsubscription: %v", err)
+			return fmt.Errorf("BybitAdapter: marshal trade unsub: %w", err)
+		}
+		if err := a.ws.WriteMessage(1, dataTrade); err != nil {
+			a.logger.Error("[BYBIT_ADAPTER] Failed to send trade unsubscription: %v", err)
+			return fmt.Errorf("BybitAdapter: ws trade unsub: %w", err)
+		}
+
+		// Отписка от свечей для Bybit
+		unsubKline := map[string]interface{}{
+			"op":   "unsubscribe",
+			"args": []string{fmt.Sprintf("kline.1.%s", symbol)},
+		}
+		dataKline, err := json.Marshal(unsubKline)
+		if err != nil {
+			a.logger.Error("[BYBIT_ADAPTER] Failed to marshal kline unsubscription: %v", err)
+			return fmt.Errorf("BybitAdapter: marshal kline unsub: %w", err)
+		}
+		if err := a.ws.WriteMessage(1, dataKline); err != nil {
+			a.logger.Error("[BYBIT_ADAPTER] Failed to send kline unsubscription: %v", err)
+			return fmt.Errorf("BybitAdapter: ws kline unsub: %w", err)
+		}
+
 		a.logger.Info("[BYBIT_ADAPTER] Successfully unsubscribed from %s", symbol)
 	}
 
@@ -197,7 +269,7 @@ func (a *BybitAdapter) readLoopWithReconnect() {
 			return
 		}
 
-		_, message, err := a.ws.ReadMessage()
+		msgType, message, err := a.ws.ReadMessage()
 		if err != nil {
 			a.logger.Error("[BYBIT_ADAPTER] Read error: %v, reconnecting...", err)
 			for {
@@ -208,6 +280,7 @@ func (a *BybitAdapter) readLoopWithReconnect() {
 				time.Sleep(3 * time.Second)
 				if err := a.ws.Reconnect(); err == nil {
 					a.logger.Info("[BYBIT_ADAPTER] Reconnected, resubscribing...")
+					a.bookSync.Reset()
 					if err := a.SubscribeMarkets(a.lastPairs, a.lastMarketType, a.lastDepth); err != nil {
 						a.logger.Error("[BYBIT_ADAPTER] Resubscribe error: %v", err)
 					}
@@ -218,6 +291,7 @@ func (a *BybitAdapter) readLoopWithReconnect() {
 			}
 			continue
 		}
+		capture.Record("bybit", msgType, message)
 
 		// Логируем сырое сообщение для отладки
 		a.logger.Info("[BYBIT_ADAPTER] RAW MESSAGE: %s", string(message))
@@ -241,7 +315,13 @@ func (a *BybitAdapter) readLoopWithReconnect() {
 				a.logger.Info("[BYBIT_ADAPTER] PARSED MESSAGE: Type=%s, Symbol=%s, PairID=%d",
 					msg.MessageType, msg.Symbol, msg.PairID)
 
-				a.messageBus.Publish("bybit", msg)
+				if msg.MessageType == market.MessageTypeOrderBook {
+					a.bookSync.Process(msg)
+				} else {
+					for _, m := range market.SplitTrades(msg) {
+						a.messageBus.Publish("bybit", m)
+					}
+				}
 			}
 		}
 	}
@@ -0,0 +1,298 @@
+package exchange
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"daemon-go/internal/market"
+)
+
+// bybitResponse - общий конверт ответа Bybit v5
+type bybitResponse struct {
+	RetCode int             `json:"retCode"`
+	RetMsg  string          `json:"retMsg"`
+	Result  json.RawMessage `json:"result"`
+}
+
+// bybitOrder - ордер Bybit v5
+type bybitOrder struct {
+	OrderID     string `json:"orderId"`
+	OrderLinkID string `json:"orderLinkId"`
+	Symbol      string `json:"symbol"`
+	Price       string `json:"price"`
+	Qty         string `json:"qty"`
+	Side        string `json:"side"`
+	OrderType   string `json:"orderType"`
+	OrderStatus string `json:"orderStatus"`
+	AvgPrice    string `json:"avgPrice"`
+	CumExecQty  string `json:"cumExecQty"`
+	CumExecFee  string `json:"cumExecFee"`
+	CreatedTime string `json:"createdTime"`
+	UpdatedTime string `json:"updatedTime"`
+}
+
+// signedRequest выполняет подписанный запрос к Bybit v5:
+// sign = HMAC-SHA256(timestamp + apiKey + recvWindow + (query | body))
+func (a *BybitAdapter) signedRequest(method, path string, params url.Values, payload interface{}, result interface{}) error {
+	if err := checkCredentials("BybitAdapter", a.exchange.ApiKey, a.exchange.ApiSecret); err != nil {
+		return err
+	}
+
+	var body []byte
+	signPayload := ""
+	fullPath := path
+	if payload != nil {
+		var err error
+		body, err = json.Marshal(payload)
+		if err != nil {
+			return fmt.Errorf("BybitAdapter: marshal: %w", err)
+		}
+		signPayload = string(body)
+	} else if params != nil {
+		signPayload = params.Encode()
+		fullPath += "?" + signPayload
+	}
+
+	timestamp := nowMillis()
+	window := strconv.Itoa(recvWindow)
+	headers := map[string]string{
+		"X-BAPI-API-KEY":     a.exchange.ApiKey,
+		"X-BAPI-TIMESTAMP":   timestamp,
+		"X-BAPI-RECV-WINDOW": window,
+		"X-BAPI-SIGN":        signHex(a.exchange.ApiSecret, bybitSignPayload(timestamp, a.exchange.ApiKey, window, signPayload)),
+	}
+
+	respBody, status, err := a.rest.DoSigned(method, fullPath, body, headers)
+	if err != nil {
+		return fmt.Errorf("BybitAdapter: %s %s: %w", method, path, err)
+	}
+	if status != http.StatusOK {
+		return fmt.Errorf("BybitAdapter: %s %s: status %d: %s", method, path, status, string(respBody))
+	}
+
+	var resp bybitResponse
+	if err := json.Unmarshal(respBody, &resp); err != nil {
+		return fmt.Errorf("BybitAdapter: decode: %w", err)
+	}
+	if resp.RetCode != 0 {
+		return fmt.Errorf("BybitAdapter: %s %s: retCode %d: %s", method, path, resp.RetCode, resp.RetMsg)
+	}
+	if result == nil {
+		return nil
+	}
+	return json.Unmarshal(resp.Result, result)
+}
+
+// bybitSignPayload - строка подписи Bybit v5: timestamp + api key + recv window + query (GET) или тело JSON (POST)
+func bybitSignPayload(timestamp, apiKey, recvWindow, payload string) string {
+	return timestamp + apiKey + recvWindow + payload
+}
+
+// PlaceOrder размещает лимитный или рыночный ордер на споте
+func (a *BybitAdapter) PlaceOrder(req market.OrderRequest) (*market.Order, error) {
+	symbol, err := exchangeSymbol(req.Symbol, "")
+	if err != nil {
+		return nil, fmt.Errorf("BybitAdapter: %w", err)
+	}
+
+	payload := map[string]string{
+		"category": "spot",
+		"symbol":   symbol,
+		"side":     bybitSide(req.Side),
+		"qty":      formatFloat(req.Volume),
+	}
+	if req.ClientOrderID != "" {
+		payload["orderLinkId"] = req.ClientOrderID
+	}
+	switch req.OrderType {
+	case market.OrderTypeMarket:
+		payload["orderType"] = "Market"
+		// По умолчанию рыночная покупка на споте задается в quote валюте
+		payload["marketUnit"] = "baseCoin"
+	default:
+		payload["orderType"] = "Limit"
+		payload["timeInForce"] = "GTC"
+		payload["price"] = formatFloat(req.Price)
+	}
+
+	var resp struct {
+		OrderID     string `json:"orderId"`
+		OrderLinkID string `json:"orderLinkId"`
+	}
+	if err := a.signedRequest(http.MethodPost, "/v5/order/create", nil, payload, &resp); err != nil {
+		return nil, err
+	}
+	a.logger.Info("[BYBIT_ADAPTER] Order placed: %s %s %s %s id=%s",
+		symbol, payload["side"], payload["orderType"], payload["qty"], resp.OrderID)
+
+	return &market.Order{
+		Exchange:      "bybit",
+		Symbol:        req.Symbol,
+		OrderID:       resp.OrderID,
+		ClientOrderID: resp.OrderLinkID,
+		Status:        market.OrderStatusNew,
+		Side:          req.Side,
+		OrderType:     req.OrderType,
+		Price:         req.Price,
+		Volume:        req.Volume,
+	}, nil
+}
+
+// CancelOrder отменяет ордер
+func (a *BybitAdapter) CancelOrder(symbol, orderID string) error {
+	exSymbol, err := exchangeSymbol(symbol, "")
+	if err != nil {
+		return fmt.Errorf("BybitAdapter: %w", err)
+	}
+	payload := map[string]string{
+		"category": "spot",
+		"symbol":   exSymbol,
+		"orderId":  orderID,
+	}
+	return a.signedRequest(http.MethodPost, "/v5/order/cancel", nil, payload, nil)
+}
+
+// GetOrder возвращает состояние ордера: сначала среди активных, затем в истории
+func (a *BybitAdapter) GetOrder(symbol, orderID string) (*market.Order, error) {
+	exSymbol, err := exchangeSymbol(symbol, "")
+	if err != nil {
+		return nil, fmt.Errorf("BybitAdapter: %w", err)
+	}
+
+	for _, path := range []string{"/v5/order/realtime", "/v5/order/history"} {
+		params := url.Values{}
+		params.Set("category", "spot")
+		params.Set("symbol", exSymbol)
+		params.Set("orderId", orderID)
+
+		var resp struct {
+			List []bybitOrder `json:"list"`
+		}
+		if err := a.signedRequest(http.MethodGet, path, params, nil, &resp); err != nil {
+			return nil, err
+		}
+		if len(resp.List) > 0 {
+			return convertBybitOrder(resp.List[0], symbol), nil
+		}
+	}
+
+	return nil, fmt.Errorf("BybitAdapter: order %s not found", orderID)
+}
+
+// GetOpenOrders возвращает открытые ордера
+func (a *BybitAdapter) GetOpenOrders(symbol string) ([]market.Order, error) {
+	params := url.Values{}
+	params.Set("category", "spot")
+	params.Set("openOnly", "0")
+	if symbol != "" {
+		exSymbol, err := exchangeSymbol(symbol, "")
+		if err != nil {
+			return nil, fmt.Errorf("BybitAdapter: %w", err)
+		}
+		params.Set("symbol", exSymbol)
+	}
+
+	var resp struct {
+		List []bybitOrder `json:"list"`
+	}
+	if err := a.signedRequest(http.MethodGet, "/v5/order/realtime", params, nil, &resp); err != nil {
+		return nil, err
+	}
+
+	orders := make([]market.Order, 0, len(resp.List))
+	for _, o := range resp.List {
+		order := convertBybitOrder(o, unifySymbol(o.Symbol))
+		if !order.Status.IsFinal() {
+			orders = append(orders, *order)
+		}
+	}
+	return orders, nil
+}
+
+// GetBalances возвращает балансы единого торгового аккаунта
+func (a *BybitAdapter) GetBalances() ([]market.Balance, error) {
+	params := url.Values{}
+	params.Set("accountType", "UNIFIED")
+
+	var resp struct {
+		List []struct {
+			Coin []struct {
+				Coin          string `json:"coin"`
+				WalletBalance string `json:"walletBalance"`
+				Locked        string `json:"locked"`
+			} `json:"coin"`
+		} `json:"list"`
+	}
+	if err := a.signedRequest(http.MethodGet, "/v5/account/wallet-balance", params, nil, &resp); err != nil {
+		return nil, err
+	}
+
+	balances := make([]market.Balance, 0)
+	for _, account := range resp.List {
+		for _, c := range account.Coin {
+			total := parseFloatString(c.WalletBalance)
+			locked := parseFloatString(c.Locked)
+			if total <= 0 {
+				continue
+			}
+			balances = append(balances, market.Balance{
+				Asset:  c.Coin,
+				Free:   total - locked,
+				Locked: locked,
+			})
+		}
+	}
+	return balances, nil
+}
+
+// convertBybitOrder переводит ордер Bybit в market.Order
+func convertBybitOrder(o bybitOrder, symbol string) *market.Order {
+	created, _ := strconv.ParseInt(o.CreatedTime, 10, 64)
+	updated, _ := strconv.ParseInt(o.UpdatedTime, 10, 64)
+	return &market.Order{
+		Exchange:      "bybit",
+		Symbol:        symbol,
+		OrderID:       o.OrderID,
+		ClientOrderID: o.OrderLinkID,
+		Status:        bybitOrderStatus(o.OrderStatus),
+		Side:          market.TradeSide(strings.ToLower(o.Side)),
+		OrderType:     market.OrderType(strings.ToLower(o.OrderType)),
+		Price:         parseFloatString(o.Price),
+		Volume:        parseFloatString(o.Qty),
+		FilledVolume:  parseFloatString(o.CumExecQty),
+		AvgPrice:      parseFloatString(o.AvgPrice),
+		Fee:           parseFloatString(o.CumExecFee),
+		CreatedAt:     millisToTime(created),
+		UpdatedAt:     millisToTime(updated),
+	}
+}
+
+// bybitSide переводит сторону в формат Bybit (Buy/Sell)
+func bybitSide(side market.TradeSide) string {
+	if side == market.TradeSideSell {
+		return "Sell"
+	}
+	return "Buy"
+}
+
+// bybitOrderStatus переводит статус ордера Bybit в унифицированный
+func bybitOrderStatus(status string) market.OrderStatus {
+	switch status {
+	case "New", "Created", "Untriggered":
+		return market.OrderStatusNew
+	case "PartiallyFilled":
+		return market.OrderStatusPartiallyFilled
+	case "Filled":
+		return market.OrderStatusFilled
+	case "Cancelled", "PartiallyFilledCanceled", "Deactivated":
+		return market.OrderStatusCanceled
+	case "Rejected":
+		return market.OrderStatusRejected
+	default:
+		return market.OrderStatusNew
+	}
+}
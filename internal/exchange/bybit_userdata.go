@@ -0,0 +1,234 @@
+package exchange
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"daemon-go/internal/market"
+)
+
+// bybitPrivateWsURL - приватный WebSocket Bybit v5
+const bybitPrivateWsURL = "wss://stream.bybit.com/v5/private"
+
+// bybitPrivateMessage - сообщение приватного потока Bybit (ответ на op или данные topic)
+type bybitPrivateMessage struct {
+	Op           string          `json:"op"`
+	Success      *bool           `json:"success"`
+	RetMsg       string          `json:"ret_msg"`
+	Topic        string          `json:"topic"`
+	CreationTime int64           `json:"creationTime"`
+	Data         json.RawMessage `json:"data"`
+}
+
+// bybitOrderUpdate - элемент topic order
+type bybitOrderUpdate struct {
+	Category     string `json:"category"`
+	Symbol       string `json:"symbol"`
+	OrderID      string `json:"orderId"`
+	OrderLinkID  string `json:"orderLinkId"`
+	Side         string `json:"side"`
+	OrderType    string `json:"orderType"`
+	Price        string `json:"price"`
+	Qty          string `json:"qty"`
+	OrderStatus  string `json:"orderStatus"`
+	AvgPrice     string `json:"avgPrice"`
+	CumExecQty   string `json:"cumExecQty"`
+	CumExecValue string `json:"cumExecValue"`
+	CumExecFee   string `json:"cumExecFee"`
+	FeeCurrency  string `json:"feeCurrency"`
+	UpdatedTime  string `json:"updatedTime"`
+}
+
+// bybitExecution - элемент topic execution (одно исполнение)
+type bybitExecution struct {
+	Category    string `json:"category"`
+	Symbol      string `json:"symbol"`
+	OrderID     string `json:"orderId"`
+	OrderLinkID string `json:"orderLinkId"`
+	Side        string `json:"side"`
+	OrderType   string `json:"orderType"`
+	OrderPrice  string `json:"orderPrice"`
+	OrderQty    string `json:"orderQty"`
+	LeavesQty   string `json:"leavesQty"`
+	ExecID      string `json:"execId"`
+	ExecType    string `json:"execType"`
+	ExecPrice   string `json:"execPrice"`
+	ExecQty     string `json:"execQty"`
+	ExecFee     string `json:"execFee"`
+	FeeCurrency string `json:"feeCurrency"`
+	ExecTime    string `json:"execTime"`
+}
+
+// bybitWallet - элемент topic wallet
+type bybitWallet struct {
+	AccountType string `json:"accountType"`
+	Coin        []struct {
+		Coin          string `json:"coin"`
+		WalletBalance string `json:"walletBalance"`
+		Locked        string `json:"locked"`
+		Free          string `json:"free"` // только для классического спотового аккаунта
+	} `json:"coin"`
+}
+
+// StartUserData подключается к приватному потоку Bybit и подписывается на order, execution и wallet
+func (a *BybitAdapter) StartUserData() error {
+	if err := checkCredentials("BybitAdapter", a.exchange.ApiKey, a.exchange.ApiSecret); err != nil {
+		return err
+	}
+	if a.userData == nil {
+		stream := newUserDataStream("bybit")
+		stream.dial = func() (string, error) { return bybitPrivateWsURL, nil }
+		stream.login = a.userDataLogin
+		stream.handle = func(_ *CexWsClient, _ int, data []byte) ([]market.UnifiedMessage, error) {
+			return parseBybitUserData(data)
+		}
+		stream.ping = func() []byte { return []byte(`{"op":"ping"}`) }
+		stream.pingInterval = 20 * time.Second
+		a.userData = stream
+	}
+	return a.userData.start()
+}
+
+// StopUserData останавливает приватный поток
+func (a *BybitAdapter) StopUserData() error {
+	if a.userData == nil {
+		return nil
+	}
+	return a.userData.stopStream()
+}
+
+// userDataLogin отправляет auth (подпись hex HMAC от "GET/realtime"+expires) и подписки
+func (a *BybitAdapter) userDataLogin(ws *CexWsClient) error {
+	expires := strconv.FormatInt(time.Now().Add(10*time.Second).UnixMilli(), 10)
+	auth := map[string]interface{}{
+		"op":   "auth",
+		"args": []string{a.exchange.ApiKey, expires, signHex(a.exchange.ApiSecret, "GET/realtime"+expires)},
+	}
+	sub := map[string]interface{}{
+		"op":   "subscribe",
+		"args": []string{"order", "execution", "wallet"},
+	}
+	if err := writeJSON(ws, auth); err != nil {
+		return err
+	}
+	return writeJSON(ws, sub)
+}
+
+// parseBybitUserData разбирает сообщение приватного потока Bybit.
+// Учитываются только спотовые ордера, как и в торговом адаптере
+func parseBybitUserData(data []byte) ([]market.UnifiedMessage, error) {
+	var msg bybitPrivateMessage
+	if err := json.Unmarshal(data, &msg); err != nil {
+		return nil, fmt.Errorf("bybit user data: %w", err)
+	}
+	if msg.Op != "" {
+		if msg.Op == "auth" && msg.Success != nil && !*msg.Success {
+			return nil, fmt.Errorf("bybit user data: auth failed: %s", msg.RetMsg)
+		}
+		return nil, nil
+	}
+
+	var result []market.UnifiedMessage
+	switch msg.Topic {
+	case "order":
+		var orders []bybitOrderUpdate
+		if err := json.Unmarshal(msg.Data, &orders); err != nil {
+			return nil, fmt.Errorf("bybit order: %w", err)
+		}
+		for _, o := range orders {
+			if o.Category != "spot" {
+				continue
+			}
+			result = append(result, orderEventMessage(convertBybitOrderUpdate(o)))
+		}
+
+	case "execution":
+		var execs []bybitExecution
+		if err := json.Unmarshal(msg.Data, &execs); err != nil {
+			return nil, fmt.Errorf("bybit execution: %w", err)
+		}
+		for _, e := range execs {
+			if e.Category != "spot" || e.ExecType != "Trade" {
+				continue
+			}
+			result = append(result, orderEventMessage(convertBybitExecution(e)))
+		}
+
+	case "wallet":
+		var wallets []bybitWallet
+		if err := json.Unmarshal(msg.Data, &wallets); err != nil {
+			return nil, fmt.Errorf("bybit wallet: %w", err)
+		}
+		var balances []market.Balance
+		for _, w := range wallets {
+			for _, c := range w.Coin {
+				locked := parseFloatString(c.Locked)
+				free := parseFloatString(c.WalletBalance) - locked
+				if c.Free != "" {
+					free = parseFloatString(c.Free)
+				}
+				balances = append(balances, market.Balance{Asset: c.Coin, Free: free, Locked: locked})
+			}
+		}
+		result = append(result, balanceMessage(millisToTime(msg.CreationTime), balances, wallets))
+	}
+	return result, nil
+}
+
+// convertBybitOrderUpdate переводит обновление ордера Bybit в UnifiedOrderEvent
+func convertBybitOrderUpdate(o bybitOrderUpdate) market.UnifiedOrderEvent {
+	volume := parseFloatString(o.Qty)
+	filled := parseFloatString(o.CumExecQty)
+	updated, _ := strconv.ParseInt(o.UpdatedTime, 10, 64)
+	return market.UnifiedOrderEvent{
+		Symbol:          unifySymbol(o.Symbol),
+		Timestamp:       millisToTime(updated),
+		OrderID:         o.OrderID,
+		ClientOrderID:   o.OrderLinkID,
+		Status:          bybitOrderStatus(o.OrderStatus),
+		Side:            market.TradeSide(strings.ToLower(o.Side)),
+		OrderType:       market.OrderType(strings.ToLower(o.OrderType)),
+		Price:           parseFloatString(o.Price),
+		Volume:          volume,
+		FilledVolume:    filled,
+		RemainingVolume: remaining(volume, filled),
+		AvgPrice:        avgPrice(parseFloatString(o.CumExecValue), filled),
+		Fee:             parseFloatString(o.CumExecFee),
+		FeeCurrency:     o.FeeCurrency,
+		Raw:             o,
+	}
+}
+
+// convertBybitExecution переводит исполнение Bybit в UnifiedOrderEvent;
+// статус выводится из неисполненного остатка
+func convertBybitExecution(e bybitExecution) market.UnifiedOrderEvent {
+	volume := parseFloatString(e.OrderQty)
+	leaves := parseFloatString(e.LeavesQty)
+	status := market.OrderStatusPartiallyFilled
+	if leaves == 0 {
+		status = market.OrderStatusFilled
+	}
+	execTime, _ := strconv.ParseInt(e.ExecTime, 10, 64)
+	return market.UnifiedOrderEvent{
+		Symbol:          unifySymbol(e.Symbol),
+		Timestamp:       millisToTime(execTime),
+		OrderID:         e.OrderID,
+		ClientOrderID:   e.OrderLinkID,
+		Status:          status,
+		Side:            market.TradeSide(strings.ToLower(e.Side)),
+		OrderType:       market.OrderType(strings.ToLower(e.OrderType)),
+		Price:           parseFloatString(e.OrderPrice),
+		Volume:          volume,
+		FilledVolume:    remaining(volume, leaves),
+		RemainingVolume: leaves,
+		FeeCurrency:     e.FeeCurrency,
+		TradeID:         e.ExecID,
+		LastFillPrice:   parseFloatString(e.ExecPrice),
+		LastFillVolume:  parseFloatString(e.ExecQty),
+		LastFillFee:     parseFloatString(e.ExecFee),
+		Raw:             e,
+	}
+}
@@ -77,6 +77,38 @@ func (c *CexRestClient) PostJSON(path string, payload, result interface{}) error
 	return json.Unmarshal(respBodyBytes, result)
 }
 
+// DoSigned выполняет запрос с заранее подписанными заголовками и возвращает тело ответа и HTTP статус.
+// path включает query string; body может быть nil
+func (c *CexRestClient) DoSigned(method, path string, body []byte, headers map[string]string) ([]byte, int, error) {
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+
+	req, err := http.NewRequest(method, c.BaseURL+path, reader)
+	if err != nil {
+		return nil, 0, fmt.Errorf("CexRestClient request error: %w", err)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := c.Client.Do(req)
+	if err != nil {
+		return nil, 0, fmt.Errorf("CexRestClient %s error: %w", method, err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, resp.StatusCode, fmt.Errorf("CexRestClient read error: %w", err)
+	}
+	return respBody, resp.StatusCode, nil
+}
+
 // DoRequest — универсальный метод для любых http.Request
 func (c *CexRestClient) DoRequest(req *http.Request) (*http.Response, error) {
 	return c.Client.Do(req)
@@ -2,7 +2,9 @@ package exchange
 
 import (
 	"daemon-go/internal/bus"
+	"daemon-go/internal/capture"
 	"daemon-go/internal/db"
+	"daemon-go/internal/market"
 	"daemon-go/internal/market/parsers"
 	"daemon-go/pkg/log"
 	"encoding/json"
@@ -23,6 +25,8 @@ type CoinexAdapter struct {
 	logger         *log.Logger
 	parser         *parsers.CoinexParser
 	messageBus     *bus.MessageBus
+	pairIDMap      map[string]int  // symbol -> pairID маппинг
+	userData       *userDataStream // приватный поток ордеров и балансов
 }
 
 // CoinexAdapter реализует интерфейс Adapter
@@ -100,6 +104,11 @@ func (a *CoinexAdapter) SubscribeMarkets(pairs []string, marketType string, dept
 		a.logger.Debug("[COINEX_ADAPTER] Ticker subscription sent successfully for pair %s", pair)
 	}
 
+	// Подписка на сделки: deals.subscribe заменяет список рынков, поэтому все пары одним запросом
+	if err := a.subscribeDeals(pairs); err != nil {
+		return err
+	}
+
 	a.logger.Debug("[COINEX_ADAPTER] SubscribeMarkets completed successfully")
 	return nil
 }
@@ -154,6 +163,61 @@ func (a *CoinexAdapter) UnsubscribeMarkets(pairs []string, marketType string, de
 		}
 	}
 
+	// deals.unsubscribe снимает подписку на сделки всех рынков: оставшиеся пары подписываются заново
+	unsubDeals := map[string]interface{}{
+		"method": "deals.unsubscribe",
+		"params": []interface{}{},
+		"id":     6,
+	}
+	dataDeals, err := json.Marshal(unsubDeals)
+	if err != nil {
+		return fmt.Errorf("CoinexAdapter: marshal deals unsub: %w", err)
+	}
+	if err := a.ws.WriteMessage(1, dataDeals); err != nil {
+		return fmt.Errorf("CoinexAdapter: ws deals unsub: %w", err)
+	}
+
+	removed := make(map[string]bool, len(pairs))
+	for _, pair := range pairs {
+		removed[pair] = true
+	}
+	var remaining []string
+	for _, pair := range a.lastPairs {
+		if !removed[pair] {
+			remaining = append(remaining, pair)
+		}
+	}
+	a.lastPairs = remaining
+	if len(remaining) > 0 {
+		return a.subscribeDeals(remaining)
+	}
+	return nil
+}
+
+// subscribeDeals подписывается на сделки рынков (deals.update)
+func (a *CoinexAdapter) subscribeDeals(pairs []string) error {
+	params := make([]interface{}, 0, len(pairs))
+	for _, pair := range pairs {
+		params = append(params, strings.ReplaceAll(pair, " ", ""))
+	}
+	subDeals := map[string]interface{}{
+		"method": "deals.subscribe",
+		"params": params,
+		"id":     5,
+	}
+
+	dataDeals, err := json.Marshal(subDeals)
+	if err != nil {
+		return fmt.Errorf("CoinexAdapter: marshal deals sub: %w", err)
+	}
+
+	if getOrderBookConfig().OrderBook.DebugLogRaw {
+		a.logger.Debug("[COINEX_ADAPTER] SENDING deals subscribe to CoinEx: %s", string(dataDeals))
+	}
+
+	if err := a.ws.WriteMessage(1, dataDeals); err != nil {
+		return fmt.Errorf("CoinexAdapter: ws deals sub: %w", err)
+	}
 	return nil
 }
 
@@ -172,6 +236,7 @@ func NewCoinexAdapter(ex db.Exchange) *CoinexAdapter {
 		logger:     log.New("coinex_adapter"),
 		parser:     parsers.NewCoinexParser(),
 		messageBus: bus.GetInstance(),
+		pairIDMap:  make(map[string]int),
 	}
 }
 
@@ -212,7 +277,7 @@ func (a *CoinexAdapter) readLoopWithReconnect() {
 			return
 		}
 
-		_, msgData, err := a.ws.ReadMessage()
+		msgType, msgData, err := a.ws.ReadMessage()
 		if err != nil {
 			a.logger.Error("[COINEX_ADAPTER] Read error: %v, reconnecting...", err)
 
@@ -235,6 +300,7 @@ func (a *CoinexAdapter) readLoopWithReconnect() {
 			}
 			continue
 		}
+		capture.Record("coinex", msgType, msgData)
 
 		// Логируем полученное сообщение
 		if getOrderBookConfig().OrderBook.DebugLogRaw {
@@ -268,8 +334,16 @@ func (a *CoinexAdapter) processMessage(data []byte) {
 		}
 	}
 
-	// Публикуем в message bus (передаем по значению, а не по указателю)
-	a.messageBus.Publish("coinex", *unifiedMsg)
+	// Добавляем PairID в сообщение
+	msg := *unifiedMsg
+	if pairID, exists := a.pairIDMap[msg.Symbol]; exists {
+		msg.PairID = pairID
+	}
+
+	// Публикуем в message bus (передаем по значению, а не по указателю), пачку сделок - по одной
+	for _, m := range market.SplitTrades(msg) {
+		a.messageBus.Publish("coinex", m)
+	}
 	a.logger.Debug("[COINEX_ADAPTER] Message published to message bus")
 }
 
@@ -293,6 +367,11 @@ func (a *CoinexAdapter) ExchangeName() string {
 
 // SubscribeMarketsWithPairID подписывается на рынки с сохранением PairID
 func (a *CoinexAdapter) SubscribeMarketsWithPairID(marketPairs []MarketPair, marketType string, depth int) error {
+	// Сохраняем маппинг symbol -> pairID
+	for _, pair := range marketPairs {
+		a.pairIDMap[pair.Symbol] = pair.PairID
+	}
+
 	// Извлекаем символы из MarketPair для совместимости с существующим методом
 	symbols := make([]string, len(marketPairs))
 	for i, mp := range marketPairs {
@@ -305,6 +384,11 @@ func (a *CoinexAdapter) SubscribeMarketsWithPairID(marketPairs []MarketPair, mar
 
 // UnsubscribeMarketsWithPairID отписывается от рынков
 func (a *CoinexAdapter) UnsubscribeMarketsWithPairID(marketPairs []MarketPair, marketType string, depth int) error {
+	// Удаляем маппинг
+	for _, pair := range marketPairs {
+		delete(a.pairIDMap, pair.Symbol)
+	}
+
 	// Извлекаем символы из MarketPair для совместимости с существующим методом
 	symbols := make([]string, len(marketPairs))
 	for i, mp := range marketPairs {
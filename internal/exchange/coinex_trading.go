@@ -0,0 +1,264 @@
+package exchange
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"daemon-go/internal/market"
+)
+
+// coinexResponse - общий конверт ответа CoinEx API v2
+type coinexResponse struct {
+	Code    int             `json:"code"`
+	Message string          `json:"message"`
+	Data    json.RawMessage `json:"data"`
+}
+
+// coinexOrder - ордер CoinEx API v2
+type coinexOrder struct {
+	OrderID      int64  `json:"order_id"`
+	ClientID     string `json:"client_id"`
+	Market       string `json:"market"`
+	Side         string `json:"side"`
+	Type         string `json:"type"`
+	Amount       string `json:"amount"`
+	Price        string `json:"price"`
+	FilledAmount string `json:"filled_amount"`
+	FilledValue  string `json:"filled_value"`
+	BaseFee      string `json:"base_fee"`
+	QuoteFee     string `json:"quote_fee"`
+	Status       string `json:"status"`
+	CreatedAt    int64  `json:"created_at"`
+	UpdatedAt    int64  `json:"updated_at"`
+}
+
+// signedRequest выполняет подписанный запрос к CoinEx API v2:
+// sign = hex(HMAC-SHA256(method + request_path + body + timestamp))
+func (a *CoinexAdapter) signedRequest(method, path string, params url.Values, payload interface{}, result interface{}) error {
+	if err := checkCredentials("CoinexAdapter", a.exchange.ApiKey, a.exchange.ApiSecret); err != nil {
+		return err
+	}
+
+	requestPath := path
+	if len(params) > 0 {
+		requestPath += "?" + params.Encode()
+	}
+
+	var body []byte
+	if payload != nil {
+		var err error
+		body, err = json.Marshal(payload)
+		if err != nil {
+			return fmt.Errorf("CoinexAdapter: marshal: %w", err)
+		}
+	}
+
+	timestamp := nowMillis()
+	headers := map[string]string{
+		"X-COINEX-KEY":       a.exchange.ApiKey,
+		"X-COINEX-SIGN":      signHex(a.exchange.ApiSecret, coinexSignPayload(method, requestPath, string(body), timestamp)),
+		"X-COINEX-TIMESTAMP": timestamp,
+	}
+
+	respBody, status, err := a.rest.DoSigned(method, requestPath, body, headers)
+	if err != nil {
+		return fmt.Errorf("CoinexAdapter: %s %s: %w", method, path, err)
+	}
+	if status != http.StatusOK {
+		return fmt.Errorf("CoinexAdapter: %s %s: status %d: %s", method, path, status, string(respBody))
+	}
+
+	var resp coinexResponse
+	if err := json.Unmarshal(respBody, &resp); err != nil {
+		return fmt.Errorf("CoinexAdapter: decode: %w", err)
+	}
+	if resp.Code != 0 {
+		return fmt.Errorf("CoinexAdapter: %s %s: code %d: %s", method, path, resp.Code, resp.Message)
+	}
+	if result == nil {
+		return nil
+	}
+	return json.Unmarshal(resp.Data, result)
+}
+
+// coinexSignPayload - строка подписи CoinEx v2: метод + путь с query + тело + timestamp
+func coinexSignPayload(method, requestPath, body, timestamp string) string {
+	return method + requestPath + body + timestamp
+}
+
+// PlaceOrder размещает лимитный или рыночный ордер на споте
+func (a *CoinexAdapter) PlaceOrder(req market.OrderRequest) (*market.Order, error) {
+	base, quote, err := splitSymbol(req.Symbol)
+	if err != nil {
+		return nil, fmt.Errorf("CoinexAdapter: %w", err)
+	}
+	symbol := base + quote
+
+	payload := map[string]string{
+		"market":      symbol,
+		"market_type": "SPOT",
+		"side":        string(req.Side),
+		"type":        string(req.OrderType),
+		"amount":      formatFloat(req.Volume),
+	}
+	if req.ClientOrderID != "" {
+		payload["client_id"] = req.ClientOrderID
+	}
+	if req.OrderType == market.OrderTypeLimit {
+		payload["price"] = formatFloat(req.Price)
+	} else {
+		// Объем рыночного ордера задаем в базовой валюте
+		payload["ccy"] = base
+	}
+
+	var resp coinexOrder
+	if err := a.signedRequest(http.MethodPost, "/v2/spot/order", nil, payload, &resp); err != nil {
+		return nil, err
+	}
+	a.logger.Info("[COINEX_ADAPTER] Order placed: %s %s %s %s id=%d",
+		symbol, payload["side"], payload["type"], payload["amount"], resp.OrderID)
+
+	order := convertCoinexOrder(resp, req.Symbol)
+	if order.Status == "" {
+		order.Status = market.OrderStatusNew
+	}
+	return order, nil
+}
+
+// CancelOrder отменяет ордер
+func (a *CoinexAdapter) CancelOrder(symbol, orderID string) error {
+	exSymbol, err := exchangeSymbol(symbol, "")
+	if err != nil {
+		return fmt.Errorf("CoinexAdapter: %w", err)
+	}
+	id, err := strconv.ParseInt(orderID, 10, 64)
+	if err != nil {
+		return fmt.Errorf("CoinexAdapter: invalid order id %q: %w", orderID, err)
+	}
+	payload := map[string]interface{}{
+		"market":      exSymbol,
+		"market_type": "SPOT",
+		"order_id":    id,
+	}
+	return a.signedRequest(http.MethodPost, "/v2/spot/cancel-order", nil, payload, nil)
+}
+
+// GetOrder возвращает состояние ордера
+func (a *CoinexAdapter) GetOrder(symbol, orderID string) (*market.Order, error) {
+	exSymbol, err := exchangeSymbol(symbol, "")
+	if err != nil {
+		return nil, fmt.Errorf("CoinexAdapter: %w", err)
+	}
+	params := url.Values{}
+	params.Set("market", exSymbol)
+	params.Set("order_id", orderID)
+
+	var resp coinexOrder
+	if err := a.signedRequest(http.MethodGet, "/v2/spot/order-status", params, nil, &resp); err != nil {
+		return nil, err
+	}
+	return convertCoinexOrder(resp, symbol), nil
+}
+
+// GetOpenOrders возвращает открытые ордера
+func (a *CoinexAdapter) GetOpenOrders(symbol string) ([]market.Order, error) {
+	params := url.Values{}
+	params.Set("market_type", "SPOT")
+	if symbol != "" {
+		exSymbol, err := exchangeSymbol(symbol, "")
+		if err != nil {
+			return nil, fmt.Errorf("CoinexAdapter: %w", err)
+		}
+		params.Set("market", exSymbol)
+	}
+
+	var resp []coinexOrder
+	if err := a.signedRequest(http.MethodGet, "/v2/spot/pending-order", params, nil, &resp); err != nil {
+		return nil, err
+	}
+
+	orders := make([]market.Order, 0, len(resp))
+	for _, o := range resp {
+		order := convertCoinexOrder(o, unifySymbol(o.Market))
+		if order.Status == "" {
+			order.Status = market.OrderStatusNew
+		}
+		orders = append(orders, *order)
+	}
+	return orders, nil
+}
+
+// GetBalances возвращает балансы спотового аккаунта
+func (a *CoinexAdapter) GetBalances() ([]market.Balance, error) {
+	var resp []struct {
+		Ccy       string `json:"ccy"`
+		Available string `json:"available"`
+		Frozen    string `json:"frozen"`
+	}
+	if err := a.signedRequest(http.MethodGet, "/v2/assets/spot/balance", nil, nil, &resp); err != nil {
+		return nil, err
+	}
+
+	balances := make([]market.Balance, 0, len(resp))
+	for _, b := range resp {
+		balance := market.Balance{
+			Asset:  b.Ccy,
+			Free:   parseFloatString(b.Available),
+			Locked: parseFloatString(b.Frozen),
+		}
+		if balance.Total() > 0 {
+			balances = append(balances, balance)
+		}
+	}
+	return balances, nil
+}
+
+// convertCoinexOrder переводит ордер CoinEx в market.Order
+func convertCoinexOrder(o coinexOrder, symbol string) *market.Order {
+	filled := parseFloatString(o.FilledAmount)
+	order := &market.Order{
+		Exchange:      "coinex",
+		Symbol:        symbol,
+		OrderID:       strconv.FormatInt(o.OrderID, 10),
+		ClientOrderID: o.ClientID,
+		Status:        coinexOrderStatus(o.Status),
+		Side:          market.TradeSide(strings.ToLower(o.Side)),
+		OrderType:     market.OrderType(strings.ToLower(o.Type)),
+		Price:         parseFloatString(o.Price),
+		Volume:        parseFloatString(o.Amount),
+		FilledVolume:  filled,
+		AvgPrice:      avgPrice(parseFloatString(o.FilledValue), filled),
+		CreatedAt:     millisToTime(o.CreatedAt),
+		UpdatedAt:     millisToTime(o.UpdatedAt),
+	}
+
+	// Комиссия списывается либо в базовой, либо в котируемой валюте
+	base, quote, _ := splitSymbol(symbol)
+	if fee := parseFloatString(o.QuoteFee); fee > 0 {
+		order.Fee, order.FeeCurrency = fee, quote
+	} else if fee := parseFloatString(o.BaseFee); fee > 0 {
+		order.Fee, order.FeeCurrency = fee, base
+	}
+
+	return order
+}
+
+// coinexOrderStatus переводит статус ордера CoinEx в унифицированный
+func coinexOrderStatus(status string) market.OrderStatus {
+	switch status {
+	case "open":
+		return market.OrderStatusNew
+	case "part_filled":
+		return market.OrderStatusPartiallyFilled
+	case "filled":
+		return market.OrderStatusFilled
+	case "part_canceled", "canceled":
+		return market.OrderStatusCanceled
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,224 @@
+package exchange
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"daemon-go/internal/market"
+)
+
+// coinexPrivateWsURL - WebSocket API v2 CoinEx (спот); приватные методы требуют server.sign
+const coinexPrivateWsURL = "wss://socket.coinex.com/v2/spot"
+
+// coinexPrivateMessage - ответ или push сообщение WebSocket v2 CoinEx
+type coinexPrivateMessage struct {
+	ID      *int64          `json:"id"`
+	Method  string          `json:"method"`
+	Code    int             `json:"code"`
+	Message string          `json:"message"`
+	Data    json.RawMessage `json:"data"`
+}
+
+// coinexOrderUpdate - данные push order.update
+type coinexOrderUpdate struct {
+	Event string `json:"event"` // put, update, modify, finish
+	Order struct {
+		OrderID          int64  `json:"order_id"`
+		Market           string `json:"market"`
+		Type             string `json:"type"`
+		Side             string `json:"side"`
+		Amount           string `json:"amount"`
+		Price            string `json:"price"`
+		UnfilledAmount   string `json:"unfilled_amount"`
+		FilledAmount     string `json:"filled_amount"`
+		FilledValue      string `json:"filled_value"`
+		ClientID         string `json:"client_id"`
+		BaseFee          string `json:"base_fee"`
+		QuoteFee         string `json:"quote_fee"`
+		LastFilledAmount string `json:"last_filled_amount"`
+		LastFilledPrice  string `json:"last_filled_price"`
+		UpdatedAt        int64  `json:"updated_at"`
+	} `json:"order"`
+}
+
+// coinexBalanceUpdate - данные push balance.update
+type coinexBalanceUpdate struct {
+	BalanceList []struct {
+		Ccy       string `json:"ccy"`
+		Available string `json:"available"`
+		Frozen    string `json:"frozen"`
+		UpdatedAt int64  `json:"updated_at"`
+	} `json:"balance_list"`
+}
+
+// coinexRequestID - счетчик id запросов WebSocket v2
+var coinexRequestID int64
+
+// StartUserData подключается к WebSocket v2 CoinEx, подписывает сессию (server.sign)
+// и подписывается на ордера и балансы
+func (a *CoinexAdapter) StartUserData() error {
+	if err := checkCredentials("CoinexAdapter", a.exchange.ApiKey, a.exchange.ApiSecret); err != nil {
+		return err
+	}
+	if a.userData == nil {
+		stream := newUserDataStream("coinex")
+		stream.dial = func() (string, error) { return coinexPrivateWsURL, nil }
+		stream.login = a.userDataLogin
+		stream.handle = func(_ *CexWsClient, _ int, data []byte) ([]market.UnifiedMessage, error) {
+			data, err := decompressGzip(data)
+			if err != nil {
+				return nil, err
+			}
+			return parseCoinexUserData(data)
+		}
+		stream.ping = func() []byte {
+			return coinexRequest("server.ping", map[string]interface{}{})
+		}
+		stream.pingInterval = 20 * time.Second
+		a.userData = stream
+	}
+	return a.userData.start()
+}
+
+// StopUserData останавливает приватный поток
+func (a *CoinexAdapter) StopUserData() error {
+	if a.userData == nil {
+		return nil
+	}
+	return a.userData.stopStream()
+}
+
+// userDataLogin выполняет server.sign (hex HMAC от timestamp), дожидается ответа и подписывается
+func (a *CoinexAdapter) userDataLogin(ws *CexWsClient) error {
+	timestamp := time.Now().UnixMilli()
+	ts := strconv.FormatInt(timestamp, 10)
+	sign := coinexRequest("server.sign", map[string]interface{}{
+		"access_id":  a.exchange.ApiKey,
+		"signed_str": signHex(a.exchange.ApiSecret, ts),
+		"timestamp":  timestamp,
+	})
+	if err := ws.WriteMessage(1, sign); err != nil {
+		return err
+	}
+
+	for {
+		_, data, err := ws.ReadMessage()
+		if err != nil {
+			return err
+		}
+		if data, err = decompressGzip(data); err != nil {
+			return err
+		}
+		var msg coinexPrivateMessage
+		if err := json.Unmarshal(data, &msg); err != nil {
+			return err
+		}
+		if msg.ID == nil {
+			continue
+		}
+		if msg.Code != 0 {
+			return fmt.Errorf("server.sign failed: code %d: %s", msg.Code, msg.Message)
+		}
+		break
+	}
+
+	if err := ws.WriteMessage(1, coinexRequest("order.subscribe", map[string]interface{}{"market_list": []string{}})); err != nil {
+		return err
+	}
+	return ws.WriteMessage(1, coinexRequest("balance.subscribe", map[string]interface{}{"ccy_list": []string{}}))
+}
+
+// coinexRequest формирует запрос WebSocket v2 с новым id
+func coinexRequest(method string, params interface{}) []byte {
+	data, _ := json.Marshal(map[string]interface{}{
+		"method": method,
+		"params": params,
+		"id":     atomic.AddInt64(&coinexRequestID, 1),
+	})
+	return data
+}
+
+// parseCoinexUserData разбирает push сообщение WebSocket v2 CoinEx
+func parseCoinexUserData(data []byte) ([]market.UnifiedMessage, error) {
+	var msg coinexPrivateMessage
+	if err := json.Unmarshal(data, &msg); err != nil {
+		return nil, fmt.Errorf("coinex user data: %w", err)
+	}
+	if msg.ID != nil && msg.Code != 0 {
+		return nil, fmt.Errorf("coinex user data: request %d failed: code %d: %s", *msg.ID, msg.Code, msg.Message)
+	}
+
+	switch msg.Method {
+	case "order.update":
+		var u coinexOrderUpdate
+		if err := json.Unmarshal(msg.Data, &u); err != nil {
+			return nil, fmt.Errorf("coinex order update: %w", err)
+		}
+		return []market.UnifiedMessage{orderEventMessage(convertCoinexOrderUpdate(u))}, nil
+
+	case "balance.update":
+		var u coinexBalanceUpdate
+		if err := json.Unmarshal(msg.Data, &u); err != nil {
+			return nil, fmt.Errorf("coinex balance update: %w", err)
+		}
+		var updated int64
+		balances := make([]market.Balance, 0, len(u.BalanceList))
+		for _, b := range u.BalanceList {
+			balances = append(balances, market.Balance{
+				Asset:  b.Ccy,
+				Free:   parseFloatString(b.Available),
+				Locked: parseFloatString(b.Frozen),
+			})
+			if b.UpdatedAt > updated {
+				updated = b.UpdatedAt
+			}
+		}
+		return []market.UnifiedMessage{balanceMessage(millisToTime(updated), balances, u)}, nil
+	}
+	return nil, nil
+}
+
+// convertCoinexOrderUpdate переводит order.update в UnifiedOrderEvent. Статус явно не
+// передается: finish с неисполненным остатком означает отмену. CoinEx не присылает ID
+// сделки, поэтому исполнение определяется по last_filled_amount
+func convertCoinexOrderUpdate(u coinexOrderUpdate) market.UnifiedOrderEvent {
+	o := u.Order
+	volume := parseFloatString(o.Amount)
+	filled := parseFloatString(o.FilledAmount)
+	unfilled := parseFloatString(o.UnfilledAmount)
+	finished := u.Event == "finish"
+
+	base, quote, _ := splitSymbol(unifySymbol(o.Market))
+	fee, feeCurrency := parseFloatString(o.BaseFee), base
+	if fee == 0 {
+		fee, feeCurrency = parseFloatString(o.QuoteFee), quote
+	}
+
+	event := market.UnifiedOrderEvent{
+		Symbol:          unifySymbol(o.Market),
+		Timestamp:       millisToTime(o.UpdatedAt),
+		OrderID:         strconv.FormatInt(o.OrderID, 10),
+		ClientOrderID:   o.ClientID,
+		Status:          resolveFillStatus(!finished, finished && unfilled > 0, filled, volume),
+		Side:            market.TradeSide(strings.ToLower(o.Side)),
+		OrderType:       market.OrderType(strings.ToLower(o.Type)),
+		Price:           parseFloatString(o.Price),
+		Volume:          volume,
+		FilledVolume:    filled,
+		RemainingVolume: unfilled,
+		AvgPrice:        avgPrice(parseFloatString(o.FilledValue), filled),
+		Fee:             fee,
+		FeeCurrency:     feeCurrency,
+		LastFillPrice:   parseFloatString(o.LastFilledPrice),
+		LastFillVolume:  parseFloatString(o.LastFilledAmount),
+		Raw:             u,
+	}
+	if finished && unfilled == 0 {
+		event.Status = market.OrderStatusFilled
+	}
+	return event
+}
@@ -96,6 +96,8 @@ func (a *EnhancedBinanceAdapter) SubscribeMarkets(pairs []string, marketType str
 		streams = append(streams, fmt.Sprintf("%s@depth%d", symbol, depth))
 		streams = append(streams, fmt.Sprintf("%s@ticker", symbol))
 		streams = append(streams, fmt.Sprintf("%s@bookTicker", symbol))
+		streams = append(streams, fmt.Sprintf("%s@aggTrade", symbol))
+		streams = append(streams, fmt.Sprintf("%s@kline_1m", symbol))
 	}
 
 	sub := map[string]interface{}{
@@ -130,6 +132,8 @@ func (a *EnhancedBinanceAdapter) UnsubscribeMarkets(pairs []string, marketType s
 		streams = append(streams, fmt.Sprintf("%s@depth%d", symbol, depth))
 		streams = append(streams, fmt.Sprintf("%s@ticker", symbol))
 		streams = append(streams, fmt.Sprintf("%s@bookTicker", symbol))
+		streams = append(streams, fmt.Sprintf("%s@aggTrade", symbol))
+		streams = append(streams, fmt.Sprintf("%s@kline_1m", symbol))
 	}
 
 	unsub := map[string]interface{}{
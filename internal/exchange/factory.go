@@ -2,7 +2,9 @@ package exchange
 
 import (
 	"daemon-go/internal/db"
+	"daemon-go/internal/market"
 	"daemon-go/pkg/log"
+	"fmt"
 	"strings"
 )
 
@@ -40,6 +42,32 @@ func NewAdapter(ex db.Exchange) Adapter {
 	}
 }
 
+// NewMarketAdapter создает адаптер рынка: spot - спотовый адаптер биржи (NewAdapter),
+// futures - FuturesAdapter линейных бессрочных контрактов
+func NewMarketAdapter(ex db.Exchange, marketType string) Adapter {
+	if market.NormalizeMarketType(marketType) != market.MarketTypeFutures {
+		return NewAdapter(ex)
+	}
+	factoryLogger.Debug("Creating FuturesAdapter for %s", ex.Name)
+	adapter, err := NewFuturesAdapter(ex)
+	if err != nil {
+		factoryLogger.Warn("Futures market of '%s' is not supported (%v), using StubAdapter", ex.Name, err)
+		return &StubAdapter{name: ex.Name}
+	}
+	return adapter
+}
+
+// NewTradingAdapter создает адаптер с поддержкой торговых операций.
+// Ключи API берутся из db.Exchange (ApiKey/ApiSecret/Passphrase)
+func NewTradingAdapter(ex db.Exchange) (TradingAdapter, error) {
+	adapter := NewAdapter(ex)
+	trading, ok := adapter.(TradingAdapter)
+	if !ok {
+		return nil, fmt.Errorf("exchange %s does not support trading", ex.Name)
+	}
+	return trading, nil
+}
+
 // StubAdapter - для неизвестных бирж
 
 type StubAdapter struct {
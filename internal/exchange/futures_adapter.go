@@ -0,0 +1,533 @@
+package exchange
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"daemon-go/internal/bus"
+	"daemon-go/internal/db"
+	"daemon-go/internal/market"
+	"daemon-go/pkg/log"
+)
+
+// FuturesEndpoints - REST и WebSocket адреса рынка линейных бессрочных контрактов биржи.
+// В EXCHANGE хранятся адреса спота, поэтому адреса деривативов задаются отдельно
+type FuturesEndpoints struct {
+	RestURL    string
+	WsURL      string // KuCoin получает адрес WebSocket через bullet-public
+	IndexWsURL string // поток индексов и маркировочной цены, если биржа отдает их отдельно (HTX ws_index)
+}
+
+// defaultFuturesEndpoints - адреса по умолчанию, переопределяются секцией [futures] конфига
+var defaultFuturesEndpoints = map[string]FuturesEndpoints{
+	"binance": {RestURL: "https://fapi.binance.com", WsURL: "wss://fstream.binance.com/stream"},
+	"bybit":   {RestURL: "https://api.bybit.com", WsURL: "wss://stream.bybit.com/v5/public/linear"},
+	"kucoin":  {RestURL: "https://api-futures.kucoin.com"},
+	"htx":     {RestURL: "https://api.hbdm.com", WsURL: "wss://api.hbdm.com/linear-swap-ws", IndexWsURL: "wss://api.hbdm.com/ws_index"},
+}
+
+var (
+	futuresEndpointsMu sync.RWMutex
+	futuresEndpoints   = defaultFuturesEndpoints
+)
+
+// SetFuturesEndpoints переопределяет адреса бирж (тестовые сети, прокси); ключи
+// <exchange>_rest, <exchange>_ws и <exchange>_index_ws, пустые значения оставляют адрес по умолчанию
+func SetFuturesEndpoints(overrides map[string]string) {
+	futuresEndpointsMu.Lock()
+	defer futuresEndpointsMu.Unlock()
+
+	endpoints := make(map[string]FuturesEndpoints, len(defaultFuturesEndpoints))
+	for name, e := range defaultFuturesEndpoints {
+		if url := overrides[name+"_rest"]; url != "" {
+			e.RestURL = url
+		}
+		if url := overrides[name+"_ws"]; url != "" {
+			e.WsURL = url
+		}
+		if url := overrides[name+"_index_ws"]; url != "" {
+			e.IndexWsURL = url
+		}
+		endpoints[name] = e
+	}
+	futuresEndpoints = endpoints
+}
+
+// GetFuturesEndpoints возвращает адреса рынка бессрочных контрактов биржи
+func GetFuturesEndpoints(exchange string) (FuturesEndpoints, bool) {
+	futuresEndpointsMu.RLock()
+	defer futuresEndpointsMu.RUnlock()
+	e, ok := futuresEndpoints[strings.ToLower(exchange)]
+	return e, ok
+}
+
+// futuresStream - одно WebSocket соединение протокола биржи
+type futuresStream struct {
+	name     string                                                              // для логов: market, index
+	dial     func(rest *CexRestClient, e FuturesEndpoints) (string, error)       // URL подключения (KuCoin получает токен)
+	requests func(symbols []string, depth int, unsubscribe bool) []interface{}   // сообщения подписки/отписки
+	handle   func(ws *CexWsClient, data []byte) ([]market.UnifiedMessage, error) // разбор сообщения, ответы на ping сервера
+
+	ping         func() []byte // ping клиента (nil - не нужен)
+	pingInterval time.Duration
+}
+
+// futuresProtocol - протокол рынка бессрочных контрактов конкретной биржи
+type futuresProtocol struct {
+	pingPath string                                     // REST проверка доступности при старте
+	symbol   func(unified *market.UnifiedSymbol) string // символ контракта на бирже
+	streams  []futuresStream
+
+	contractSizes func(rest *CexRestClient) (map[string]float64, error) // base валюты в контракте по унифицированному символу (nil - объемы в base)
+
+	poll         func(rest *CexRestClient) ([]market.UnifiedMessage, error) // данные, которых нет в публичных потоках (ставки KuCoin и HTX)
+	pollInterval time.Duration
+}
+
+// futuresConn - текущее соединение потока
+type futuresConn struct {
+	stream futuresStream
+	mu     sync.Mutex
+	ws     *CexWsClient
+}
+
+// FuturesAdapter реализует Adapter для линейных бессрочных контрактов (Binance USDⓈ-M, Bybit linear,
+// KuCoin Futures, HTX USDT swaps). Протокол биржи задается futuresProtocol, сообщения публикуются
+// в шину под bus.FuturesTopic(биржа) с унифицированными символами futures (BTCUSDT).
+// Стаканы приходят снимками верхних уровней (Bybit - снимок и delta), объемы пересчитываются в base валюту
+type FuturesAdapter struct {
+	exchange   db.Exchange
+	name       string // имя биржи в нижнем регистре
+	endpoints  FuturesEndpoints
+	protocol   futuresProtocol
+	rest       *CexRestClient
+	logger     *log.Logger
+	messageBus *bus.MessageBus
+	conns      []*futuresConn
+
+	mu            sync.Mutex
+	active        bool
+	stop          chan struct{}
+	symbols       map[string]string  // [унифицированный символ] символ биржи, подписанные контракты
+	pairIDs       map[string]int     // [унифицированный символ] PairID из FUTURES_TRADE_PAIR
+	contractSizes map[string]float64 // [унифицированный символ] base валюты в контракте
+	depth         int
+}
+
+// NewFuturesAdapter создает адаптер бессрочных контрактов биржи из db.Exchange
+func NewFuturesAdapter(ex db.Exchange) (*FuturesAdapter, error) {
+	name := strings.ToLower(ex.Name)
+	endpoints, ok := GetFuturesEndpoints(name)
+	if !ok {
+		return nil, fmt.Errorf("exchange %s does not support futures", ex.Name)
+	}
+
+	var protocol futuresProtocol
+	switch name {
+	case "binance":
+		protocol = binanceFuturesProtocol()
+	case "bybit":
+		protocol = bybitLinearProtocol()
+	case "kucoin":
+		protocol = kucoinFuturesProtocol()
+	case "htx":
+		protocol = htxSwapProtocol()
+	}
+
+	a := &FuturesAdapter{
+		exchange:   ex,
+		name:       name,
+		endpoints:  endpoints,
+		protocol:   protocol,
+		rest:       NewCexRestClient(endpoints.RestURL),
+		logger:     log.New(name + "_futures_adapter"),
+		messageBus: bus.GetInstance(),
+		symbols:    make(map[string]string),
+		pairIDs:    make(map[string]int),
+		depth:      5,
+	}
+	for _, stream := range protocol.streams {
+		a.conns = append(a.conns, &futuresConn{stream: stream})
+	}
+	return a, nil
+}
+
+// Start проверяет REST, загружает размеры контрактов и подключает все потоки протокола
+func (a *FuturesAdapter) Start() error {
+	a.logger.Info("[FUTURES_ADAPTER] Starting %s futures adapter...", a.name)
+
+	var result interface{}
+	if err := a.rest.GetJSON(a.protocol.pingPath, &result); err != nil {
+		return fmt.Errorf("FuturesAdapter %s: ping failed: %w", a.name, err)
+	}
+	if a.protocol.contractSizes != nil {
+		sizes, err := a.protocol.contractSizes(a.rest)
+		if err != nil {
+			return fmt.Errorf("FuturesAdapter %s: contract sizes: %w", a.name, err)
+		}
+		a.mu.Lock()
+		a.contractSizes = sizes
+		a.mu.Unlock()
+		a.logger.Info("[FUTURES_ADAPTER] %s: loaded contract sizes for %d contracts", a.name, len(sizes))
+	}
+
+	for _, conn := range a.conns {
+		ws, err := a.connect(conn)
+		if err != nil {
+			a.closeConns()
+			return fmt.Errorf("FuturesAdapter %s: %s ws connect failed: %w", a.name, conn.stream.name, err)
+		}
+		conn.mu.Lock()
+		conn.ws = ws
+		conn.mu.Unlock()
+	}
+
+	a.mu.Lock()
+	a.active = true
+	a.stop = make(chan struct{})
+	stop := a.stop
+	a.mu.Unlock()
+
+	for _, conn := range a.conns {
+		go a.readLoop(conn, stop)
+		if conn.stream.ping != nil && conn.stream.pingInterval > 0 {
+			c := conn
+			go a.timerLoop(stop, c.stream.pingInterval, func() { a.sendPing(c) })
+		}
+	}
+	if a.protocol.poll != nil && a.protocol.pollInterval > 0 {
+		go a.pollOnce()
+		go a.timerLoop(stop, a.protocol.pollInterval, a.pollOnce)
+	}
+	a.logger.Info("[FUTURES_ADAPTER] %s futures adapter started (%d streams)", a.name, len(a.conns))
+	return nil
+}
+
+// connect открывает соединение потока и подписывается на все запомненные контракты
+func (a *FuturesAdapter) connect(conn *futuresConn) (*CexWsClient, error) {
+	wsURL, err := conn.stream.dial(a.rest, a.endpoints)
+	if err != nil {
+		return nil, err
+	}
+	ws := NewCexWsClient(wsURL)
+	if err := ws.Connect(); err != nil {
+		return nil, err
+	}
+
+	symbols, depth := a.subscribed()
+	if err := a.send(conn, ws, symbols, depth, false); err != nil {
+		_ = ws.Close()
+		return nil, fmt.Errorf("resubscribe: %w", err)
+	}
+	return ws, nil
+}
+
+// subscribed возвращает символы биржи подписанных контрактов
+func (a *FuturesAdapter) subscribed() ([]string, int) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	symbols := make([]string, 0, len(a.symbols))
+	for _, symbol := range a.symbols {
+		symbols = append(symbols, symbol)
+	}
+	return symbols, a.depth
+}
+
+// send отправляет запросы подписки/отписки на контракты в соединение потока
+func (a *FuturesAdapter) send(conn *futuresConn, ws *CexWsClient, symbols []string, depth int, unsubscribe bool) error {
+	if len(symbols) == 0 {
+		return nil
+	}
+	for _, req := range conn.stream.requests(symbols, depth, unsubscribe) {
+		if err := writeJSON(ws, req); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// sendAll отправляет запросы во все подключенные потоки
+func (a *FuturesAdapter) sendAll(symbols []string, depth int, unsubscribe bool) error {
+	for _, conn := range a.conns {
+		ws := conn.current()
+		if ws == nil {
+			continue
+		}
+		if err := a.send(conn, ws, symbols, depth, unsubscribe); err != nil {
+			return fmt.Errorf("%s: %w", conn.stream.name, err)
+		}
+	}
+	return nil
+}
+
+// current возвращает текущее соединение потока
+func (c *futuresConn) current() *CexWsClient {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.ws
+}
+
+// isActive возвращает true, пока адаптер не остановлен
+func (a *FuturesAdapter) isActive() bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.active
+}
+
+// readLoop читает и публикует сообщения потока; при ошибке переподключается и подписывается заново
+func (a *FuturesAdapter) readLoop(conn *futuresConn, stop chan struct{}) {
+	for {
+		ws := conn.current()
+		if !a.isActive() || ws == nil {
+			return
+		}
+
+		_, data, err := ws.ReadMessage()
+		if err != nil {
+			a.logger.Error("[FUTURES_ADAPTER] %s %s read error: %v, reconnecting...", a.name, conn.stream.name, err)
+			if !a.reconnect(conn, stop) {
+				return
+			}
+			continue
+		}
+
+		msgs, err := conn.stream.handle(ws, data)
+		if err != nil {
+			a.logger.Error("[FUTURES_ADAPTER] %s %s parse error: %v", a.name, conn.stream.name, err)
+			continue
+		}
+		for _, msg := range msgs {
+			a.publish(msg)
+		}
+	}
+}
+
+// reconnect восстанавливает соединение потока до успеха или остановки адаптера
+func (a *FuturesAdapter) reconnect(conn *futuresConn, stop chan struct{}) bool {
+	for {
+		select {
+		case <-stop:
+			return false
+		case <-time.After(3 * time.Second):
+		}
+
+		ws, err := a.connect(conn)
+		if err != nil {
+			a.logger.Error("[FUTURES_ADAPTER] %s %s reconnect failed: %v, retrying...", a.name, conn.stream.name, err)
+			continue
+		}
+		if !a.isActive() {
+			_ = ws.Close()
+			return false
+		}
+
+		conn.mu.Lock()
+		old := conn.ws
+		conn.ws = ws
+		conn.mu.Unlock()
+		if old != nil {
+			_ = old.Close()
+		}
+		a.logger.Info("[FUTURES_ADAPTER] %s %s reconnected and resubscribed", a.name, conn.stream.name)
+		return true
+	}
+}
+
+// publish проставляет PairID, пересчитывает объемы стакана в base валюту и публикует сообщение.
+// Сообщения по контрактам без подписки (ответы REST по всем контрактам) отбрасываются
+func (a *FuturesAdapter) publish(msg market.UnifiedMessage) {
+	a.mu.Lock()
+	_, subscribed := a.symbols[msg.Symbol]
+	msg.PairID = a.pairIDs[msg.Symbol]
+	size, scaled := a.contractSizes[msg.Symbol]
+	a.mu.Unlock()
+	if !subscribed {
+		return
+	}
+
+	if book, ok := msg.Data.(market.UnifiedOrderBook); ok && scaled {
+		book.Bids = scaleLevels(book.Bids, size)
+		book.Asks = scaleLevels(book.Asks, size)
+		msg.Data = book
+	}
+	if msg.Timestamp.IsZero() {
+		msg.Timestamp = time.Now()
+	}
+	a.messageBus.Publish(bus.FuturesTopic(a.name), msg)
+}
+
+// scaleLevels переводит объемы уровней из контрактов в base валюту
+func scaleLevels(levels []market.PriceLevel, size float64) []market.PriceLevel {
+	scaled := make([]market.PriceLevel, len(levels))
+	for i, level := range levels {
+		scaled[i] = market.PriceLevel{Price: level.Price, Volume: level.Volume * size}
+	}
+	return scaled
+}
+
+// sendPing отправляет ping клиента в текущее соединение потока
+func (a *FuturesAdapter) sendPing(conn *futuresConn) {
+	ws := conn.current()
+	if !a.isActive() || ws == nil {
+		return
+	}
+	if err := ws.WriteMessage(1, conn.stream.ping()); err != nil {
+		a.logger.Debug("[FUTURES_ADAPTER] %s %s ping failed: %v", a.name, conn.stream.name, err)
+	}
+}
+
+// pollOnce загружает через REST данные, которых нет в потоках
+func (a *FuturesAdapter) pollOnce() {
+	msgs, err := a.protocol.poll(a.rest)
+	if err != nil {
+		a.logger.Warn("[FUTURES_ADAPTER] %s poll failed: %v", a.name, err)
+		return
+	}
+	for _, msg := range msgs {
+		a.publish(msg)
+	}
+}
+
+// timerLoop периодически вызывает fn до остановки адаптера
+func (a *FuturesAdapter) timerLoop(stop chan struct{}, interval time.Duration, fn func()) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			fn()
+		}
+	}
+}
+
+// closeConns закрывает соединения всех потоков
+func (a *FuturesAdapter) closeConns() {
+	for _, conn := range a.conns {
+		conn.mu.Lock()
+		if conn.ws != nil {
+			_ = conn.ws.Close()
+			conn.ws = nil
+		}
+		conn.mu.Unlock()
+	}
+}
+
+func (a *FuturesAdapter) Stop() error {
+	a.mu.Lock()
+	if !a.active {
+		a.mu.Unlock()
+		return nil
+	}
+	a.active = false
+	close(a.stop)
+	a.mu.Unlock()
+
+	a.closeConns()
+	return nil
+}
+
+func (a *FuturesAdapter) IsActive() bool {
+	if !a.isActive() {
+		return false
+	}
+	for _, conn := range a.conns {
+		if ws := conn.current(); ws == nil || !ws.IsConnected() {
+			return false
+		}
+	}
+	return true
+}
+
+func (a *FuturesAdapter) ExchangeName() string {
+	return a.exchange.Name
+}
+
+// SubscribeMarkets подписывается на контракты; pairs - BTCUSDT или BTC/USDT. Контракты запоминаются
+// и до подключения, подписка отправляется при старте и после каждого переподключения
+func (a *FuturesAdapter) SubscribeMarkets(pairs []string, marketType string, depth int) error {
+	a.mu.Lock()
+	a.depth = depth
+	var added []string
+	for _, pair := range pairs {
+		unified, err := market.ParseSymbol(pair, market.MarketTypeFutures)
+		if err != nil {
+			a.logger.Warn("[FUTURES_ADAPTER] %s: skip pair %s: %v", a.name, pair, err)
+			continue
+		}
+		if _, ok := a.symbols[unified.Symbol]; ok {
+			continue
+		}
+		symbol := a.protocol.symbol(unified)
+		a.symbols[unified.Symbol] = symbol
+		added = append(added, symbol)
+	}
+	active := a.active
+	a.mu.Unlock()
+
+	if !active {
+		return nil
+	}
+	if err := a.sendAll(added, depth, false); err != nil {
+		return fmt.Errorf("FuturesAdapter %s: ws sub: %w", a.name, err)
+	}
+	a.logger.Info("[FUTURES_ADAPTER] %s: subscribed to %d contracts", a.name, len(added))
+	return nil
+}
+
+// UnsubscribeMarkets отписывается от контрактов
+func (a *FuturesAdapter) UnsubscribeMarkets(pairs []string, marketType string, depth int) error {
+	a.mu.Lock()
+	var removed []string
+	for _, pair := range pairs {
+		unified, err := market.ParseSymbol(pair, market.MarketTypeFutures)
+		if err != nil {
+			continue
+		}
+		if symbol, ok := a.symbols[unified.Symbol]; ok {
+			removed = append(removed, symbol)
+			delete(a.symbols, unified.Symbol)
+		}
+	}
+	active := a.active
+	a.mu.Unlock()
+
+	if !active {
+		return nil
+	}
+	if err := a.sendAll(removed, depth, true); err != nil {
+		return fmt.Errorf("FuturesAdapter %s: ws unsub: %w", a.name, err)
+	}
+	return nil
+}
+
+// SubscribeMarketsWithPairID подписывается на контракты с сохранением PairID
+func (a *FuturesAdapter) SubscribeMarketsWithPairID(pairs []MarketPair, marketType string, depth int) error {
+	symbols := make([]string, 0, len(pairs))
+	a.mu.Lock()
+	for _, pair := range pairs {
+		if unified, err := market.ParseSymbol(pair.Symbol, market.MarketTypeFutures); err == nil {
+			a.pairIDs[unified.Symbol] = pair.PairID
+		}
+		symbols = append(symbols, pair.Symbol)
+	}
+	a.mu.Unlock()
+	return a.SubscribeMarkets(symbols, marketType, depth)
+}
+
+// UnsubscribeMarketsWithPairID отписывается от контрактов
+func (a *FuturesAdapter) UnsubscribeMarketsWithPairID(pairs []MarketPair, marketType string, depth int) error {
+	symbols := make([]string, 0, len(pairs))
+	a.mu.Lock()
+	for _, pair := range pairs {
+		if unified, err := market.ParseSymbol(pair.Symbol, market.MarketTypeFutures); err == nil {
+			delete(a.pairIDs, unified.Symbol)
+		}
+		symbols = append(symbols, pair.Symbol)
+	}
+	a.mu.Unlock()
+	return a.UnsubscribeMarkets(symbols, marketType, depth)
+}
@@ -0,0 +1,281 @@
+package exchange
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"daemon-go/internal/market"
+	"daemon-go/internal/market/parsers"
+)
+
+// binanceFuturesProtocol - Binance USDⓈ-M (fstream, combined streams): частичный стакан,
+// bookTicker и markPrice (маркировочная и индексная цены, ставка финансирования). Ping сервера
+// обрабатывает WebSocket клиент, объемы в base валюте
+func binanceFuturesProtocol() futuresProtocol {
+	parser := parsers.NewBinanceFuturesParser()
+	return futuresProtocol{
+		pingPath: "/fapi/v1/ping",
+		symbol: func(unified *market.UnifiedSymbol) string {
+			return unified.BaseCurrency + unified.QuoteCurrency
+		},
+		streams: []futuresStream{{
+			name: "market",
+			dial: marketWsURL,
+			requests: func(symbols []string, depth int, unsubscribe bool) []interface{} {
+				// Частичный стакан доступен только на 5, 10 и 20 уровней
+				levels := 20
+				if depth <= 5 {
+					levels = 5
+				} else if depth <= 10 {
+					levels = 10
+				}
+				var streams []string
+				for _, symbol := range symbols {
+					s := strings.ToLower(symbol)
+					streams = append(streams,
+						fmt.Sprintf("%s@depth%d@100ms", s, levels),
+						fmt.Sprintf("%s@bookTicker", s),
+						fmt.Sprintf("%s@markPrice@1s", s))
+				}
+				method, id := "SUBSCRIBE", 1
+				if unsubscribe {
+					method, id = "UNSUBSCRIBE", 2
+				}
+				return []interface{}{map[string]interface{}{"method": method, "params": streams, "id": id}}
+			},
+			handle: func(ws *CexWsClient, data []byte) ([]market.UnifiedMessage, error) {
+				return parser.ParseMessage(data)
+			},
+		}},
+	}
+}
+
+// bybitLinearProtocol - Bybit v5 public linear: orderbook.50 (снимок и delta) и tickers
+// (маркировочная и индексная цены, ставка финансирования). Объемы в base валюте
+func bybitLinearProtocol() futuresProtocol {
+	parser := parsers.NewBybitLinearParser()
+	return futuresProtocol{
+		pingPath: "/v5/market/time",
+		symbol: func(unified *market.UnifiedSymbol) string {
+			return unified.BaseCurrency + unified.QuoteCurrency
+		},
+		streams: []futuresStream{{
+			name: "market",
+			dial: marketWsURL,
+			requests: func(symbols []string, depth int, unsubscribe bool) []interface{} {
+				// Глубина linear: 1, 50, 200, 500
+				levels := 50
+				if depth <= 1 {
+					levels = 1
+				}
+				var args []string
+				for _, symbol := range symbols {
+					args = append(args, fmt.Sprintf("orderbook.%d.%s", levels, symbol), "tickers."+symbol)
+				}
+				op := "subscribe"
+				if unsubscribe {
+					op = "unsubscribe"
+				}
+				// Bybit принимает не более 10 топиков в одном запросе
+				var reqs []interface{}
+				for start := 0; start < len(args); start += 10 {
+					end := start + 10
+					if end > len(args) {
+						end = len(args)
+					}
+					reqs = append(reqs, map[string]interface{}{"op": op, "args": args[start:end]})
+				}
+				return reqs
+			},
+			handle: func(ws *CexWsClient, data []byte) ([]market.UnifiedMessage, error) {
+				return parser.ParseMessage(data)
+			},
+			ping:         func() []byte { return []byte(`{"op":"ping"}`) },
+			pingInterval: 20 * time.Second,
+		}},
+	}
+}
+
+// kucoinFuturesProtocol - KuCoin Futures: level2Depth5/50 и /contract/instrument (маркировочная
+// и индексная цены), ставки финансирования - REST contracts/active раз в минуту. Адрес WebSocket
+// выдает bullet-public futures API, BTC на бирже называется XBT (XBTUSDTM), объемы в лотах
+func kucoinFuturesProtocol() futuresProtocol {
+	parser := parsers.NewKucoinFuturesParser()
+	registry := market.NewSymbolRegistry()
+	return futuresProtocol{
+		pingPath: "/api/v1/timestamp",
+		symbol: func(unified *market.UnifiedSymbol) string {
+			return registry.ConvertToExchange("kucoin", unified)
+		},
+		streams: []futuresStream{{
+			name: "market",
+			dial: func(rest *CexRestClient, _ FuturesEndpoints) (string, error) {
+				wsURL, token, err := getWsUrlAndTokenKucoin(rest)
+				if err != nil {
+					return "", err
+				}
+				return wsURL + "?token=" + token, nil
+			},
+			requests: func(symbols []string, depth int, unsubscribe bool) []interface{} {
+				levels := 50
+				if depth <= 5 {
+					levels = 5
+				}
+				reqType := "subscribe"
+				if unsubscribe {
+					reqType = "unsubscribe"
+				}
+				list := strings.Join(symbols, ",")
+				id := time.Now().UnixNano()
+				return []interface{}{
+					map[string]interface{}{"id": strconv.FormatInt(id, 10), "type": reqType,
+						"topic": fmt.Sprintf("/contractMarket/level2Depth%d:%s", levels, list), "response": true},
+					map[string]interface{}{"id": strconv.FormatInt(id+1, 10), "type": reqType,
+						"topic": "/contract/instrument:" + list, "response": true},
+				}
+			},
+			handle: func(ws *CexWsClient, data []byte) ([]market.UnifiedMessage, error) {
+				return parser.ParseMessage(data)
+			},
+			ping: func() []byte {
+				return []byte(fmt.Sprintf(`{"id":"%d","type":"ping"}`, time.Now().UnixMilli()))
+			},
+			pingInterval: 18 * time.Second,
+		}},
+		contractSizes: func(rest *CexRestClient) (map[string]float64, error) {
+			var resp struct {
+				Code string `json:"code"`
+				Data []struct {
+					Symbol     string  `json:"symbol"`
+					Multiplier float64 `json:"multiplier"`
+					IsInverse  bool    `json:"isInverse"`
+				} `json:"data"`
+			}
+			if err := rest.GetJSON("/api/v1/contracts/active", &resp); err != nil {
+				return nil, err
+			}
+			if resp.Code != "200000" {
+				return nil, fmt.Errorf("contracts: code %s", resp.Code)
+			}
+			sizes := make(map[string]float64, len(resp.Data))
+			for _, c := range resp.Data {
+				if c.IsInverse || c.Multiplier <= 0 {
+					continue
+				}
+				if unified, err := registry.ConvertToUnified("kucoin", c.Symbol, market.MarketTypeFutures); err == nil {
+					sizes[unified.Symbol] = c.Multiplier
+				}
+			}
+			return sizes, nil
+		},
+		poll: func(rest *CexRestClient) ([]market.UnifiedMessage, error) {
+			var raw json.RawMessage
+			if err := rest.GetJSON("/api/v1/contracts/active", &raw); err != nil {
+				return nil, err
+			}
+			return parser.ParseContracts(raw)
+		},
+		pollInterval: time.Minute,
+	}
+}
+
+// htxSwapProtocol - HTX USDT swaps: стакан step6 в linear-swap-ws, маркировочная цена и базис
+// (индекс) в ws_index; оба потока шлют gzip сообщения и ping сервера. Ставка финансирования -
+// REST swap_batch_funding_rate раз в минуту, объемы в контрактах (contract_size из swap_contract_info)
+func htxSwapProtocol() futuresProtocol {
+	parser := parsers.NewHTXSwapParser()
+	htxHandle := func(ws *CexWsClient, data []byte) ([]market.UnifiedMessage, error) {
+		data, err := decompressGzip(data)
+		if err != nil {
+			return nil, err
+		}
+		var ping struct {
+			Ping int64 `json:"ping"`
+		}
+		if err := json.Unmarshal(data, &ping); err == nil && ping.Ping != 0 {
+			return nil, writeJSON(ws, map[string]int64{"pong": ping.Ping})
+		}
+		return parser.ParseMessage(data)
+	}
+	return futuresProtocol{
+		pingPath: "/api/v1/timestamp",
+		symbol: func(unified *market.UnifiedSymbol) string {
+			return unified.BaseCurrency + "-" + unified.QuoteCurrency
+		},
+		streams: []futuresStream{{
+			name:     "market",
+			dial:     marketWsURL,
+			requests: htxSubRequests("depth.step6"),
+			handle:   htxHandle,
+		}, {
+			name: "index",
+			dial: func(_ *CexRestClient, e FuturesEndpoints) (string, error) {
+				if e.IndexWsURL == "" {
+					return "", fmt.Errorf("index ws url is not configured")
+				}
+				return e.IndexWsURL, nil
+			},
+			requests: htxSubRequests("mark_price.1min", "basis.1min.close"),
+			handle:   htxHandle,
+		}},
+		contractSizes: func(rest *CexRestClient) (map[string]float64, error) {
+			var resp struct {
+				Status string `json:"status"`
+				Data   []struct {
+					ContractCode string  `json:"contract_code"`
+					ContractSize float64 `json:"contract_size"`
+				} `json:"data"`
+			}
+			if err := rest.GetJSON("/linear-swap-api/v1/swap_contract_info", &resp); err != nil {
+				return nil, err
+			}
+			if resp.Status != "ok" {
+				return nil, fmt.Errorf("contract info: status %s", resp.Status)
+			}
+			sizes := make(map[string]float64, len(resp.Data))
+			for _, c := range resp.Data {
+				if c.ContractSize <= 0 {
+					continue
+				}
+				if unified, err := market.ParseSymbol(c.ContractCode, market.MarketTypeFutures); err == nil {
+					sizes[unified.Symbol] = c.ContractSize
+				}
+			}
+			return sizes, nil
+		},
+		poll: func(rest *CexRestClient) ([]market.UnifiedMessage, error) {
+			var raw json.RawMessage
+			if err := rest.GetJSON("/linear-swap-api/v1/swap_batch_funding_rate", &raw); err != nil {
+				return nil, err
+			}
+			return parser.ParseFundingRates(raw)
+		},
+		pollInterval: time.Minute,
+	}
+}
+
+// htxSubRequests формирует sub/unsub запросы HTX на каналы market.$contract.$channel
+func htxSubRequests(channels ...string) func(symbols []string, depth int, unsubscribe bool) []interface{} {
+	return func(symbols []string, depth int, unsubscribe bool) []interface{} {
+		op := "sub"
+		if unsubscribe {
+			op = "unsub"
+		}
+		var reqs []interface{}
+		for _, symbol := range symbols {
+			for _, ch := range channels {
+				topic := fmt.Sprintf("market.%s.%s", symbol, ch)
+				reqs = append(reqs, map[string]interface{}{op: topic, "id": topic})
+			}
+		}
+		return reqs
+	}
+}
+
+// marketWsURL - адрес основного потока биржи из FuturesEndpoints
+func marketWsURL(_ *CexRestClient, e FuturesEndpoints) (string, error) {
+	return e.WsURL, nil
+}
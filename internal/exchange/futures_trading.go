@@ -0,0 +1,449 @@
+package exchange
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"daemon-go/internal/bus"
+	"daemon-go/internal/db"
+	"daemon-go/internal/market"
+	"daemon-go/pkg/log"
+)
+
+// FundingHistorySource - история расчетов финансирования по позициям аккаунта в бессрочных
+// контрактах: платежи по символу с момента since в порядке времени
+type FundingHistorySource interface {
+	GetFundingPayments(symbol string, since time.Time) ([]market.FundingPayment, error)
+}
+
+// Проверка реализации интерфейсов адаптерами бессрочных контрактов
+var (
+	_ TradingAdapter       = (*BinanceFuturesTradingAdapter)(nil)
+	_ TradingAdapter       = (*BybitLinearTradingAdapter)(nil)
+	_ FundingHistorySource = (*BinanceFuturesTradingAdapter)(nil)
+	_ FundingHistorySource = (*BybitLinearTradingAdapter)(nil)
+)
+
+// NewFuturesTradingAdapter создает торговый адаптер линейных бессрочных контрактов биржи
+// (Binance USDⓈ-M, Bybit linear) на REST адресе из GetFuturesEndpoints. ExchangeName адаптера -
+// bus.FuturesTopic(биржа): под этим именем шлюз регистрируется в исполнителе и журнале ордеров.
+// Символы - унифицированные символы futures (BTCUSDT), объемы в base валюте
+func NewFuturesTradingAdapter(ex db.Exchange) (TradingAdapter, error) {
+	name := strings.ToLower(ex.Name)
+	endpoints, ok := GetFuturesEndpoints(name)
+	if !ok {
+		return nil, fmt.Errorf("exchange %s does not support futures", ex.Name)
+	}
+	switch name {
+	case "binance":
+		return &BinanceFuturesTradingAdapter{
+			account: &BinanceAdapter{exchange: ex, rest: NewCexRestClient(endpoints.RestURL), logger: log.New("binance_futures_trading")},
+		}, nil
+	case "bybit":
+		return &BybitLinearTradingAdapter{
+			account: &BybitAdapter{exchange: ex, rest: NewCexRestClient(endpoints.RestURL), logger: log.New("bybit_linear_trading")},
+		}, nil
+	default:
+		return nil, fmt.Errorf("exchange %s does not support futures trading", ex.Name)
+	}
+}
+
+// futuresSymbol переводит унифицированный символ контракта (BTCUSDT, BTC/USDT) в символ Binance и Bybit
+func futuresSymbol(symbol string) (string, error) {
+	unified, err := market.ParseSymbol(symbol, market.MarketTypeFutures)
+	if err != nil {
+		return "", err
+	}
+	return unified.BaseCurrency + unified.QuoteCurrency, nil
+}
+
+// BinanceFuturesTradingAdapter - ордера, балансы и расчеты финансирования Binance USDⓈ-M (fapi).
+// Запросы подписываются так же, как на споте (BinanceAdapter.signedRequest)
+type BinanceFuturesTradingAdapter struct {
+	account *BinanceAdapter // ключи аккаунта и REST клиент fapi
+}
+
+// binanceFuturesOrder - ответ Binance по ордеру (/fapi/v1/order)
+type binanceFuturesOrder struct {
+	Symbol        string `json:"symbol"`
+	OrderID       int64  `json:"orderId"`
+	ClientOrderID string `json:"clientOrderId"`
+	Price         string `json:"price"`
+	AvgPrice      string `json:"avgPrice"`
+	OrigQty       string `json:"origQty"`
+	ExecutedQty   string `json:"executedQty"`
+	Status        string `json:"status"`
+	Type          string `json:"type"`
+	Side          string `json:"side"`
+	Time          int64  `json:"time"`
+	UpdateTime    int64  `json:"updateTime"`
+}
+
+// ExchangeName возвращает имя площадки контрактов (binance.futures)
+func (a *BinanceFuturesTradingAdapter) ExchangeName() string {
+	return bus.FuturesTopic("binance")
+}
+
+// PlaceOrder размещает лимитный или рыночный ордер на контракт
+func (a *BinanceFuturesTradingAdapter) PlaceOrder(req market.OrderRequest) (*market.Order, error) {
+	symbol, err := futuresSymbol(req.Symbol)
+	if err != nil {
+		return nil, fmt.Errorf("BinanceFuturesTradingAdapter: %w", err)
+	}
+
+	params := url.Values{}
+	params.Set("symbol", symbol)
+	params.Set("side", strings.ToUpper(string(req.Side)))
+	params.Set("quantity", formatFloat(req.Volume))
+	params.Set("newOrderRespType", "RESULT")
+	if req.ClientOrderID != "" {
+		params.Set("newClientOrderId", req.ClientOrderID)
+	}
+	switch req.OrderType {
+	case market.OrderTypeMarket:
+		params.Set("type", "MARKET")
+	default:
+		params.Set("type", "LIMIT")
+		params.Set("timeInForce", "GTC")
+		params.Set("price", formatFloat(req.Price))
+	}
+
+	var resp binanceFuturesOrder
+	if err := a.account.signedRequest(http.MethodPost, "/fapi/v1/order", params, &resp); err != nil {
+		return nil, err
+	}
+	a.account.logger.Info("[BINANCE_FUTURES] Order placed: %s %s %s %s@%s id=%d",
+		symbol, resp.Side, resp.Type, resp.OrigQty, resp.Price, resp.OrderID)
+
+	return a.convertOrder(resp), nil
+}
+
+// CancelOrder отменяет ордер
+func (a *BinanceFuturesTradingAdapter) CancelOrder(symbol, orderID string) error {
+	exSymbol, err := futuresSymbol(symbol)
+	if err != nil {
+		return fmt.Errorf("BinanceFuturesTradingAdapter: %w", err)
+	}
+	params := url.Values{}
+	params.Set("symbol", exSymbol)
+	params.Set("orderId", orderID)
+	return a.account.signedRequest(http.MethodDelete, "/fapi/v1/order", params, nil)
+}
+
+// GetOrder возвращает состояние ордера. Ордер fapi не содержит комиссий: для исполненного
+// объема они суммируются по сделкам ордера (/fapi/v1/userTrades)
+func (a *BinanceFuturesTradingAdapter) GetOrder(symbol, orderID string) (*market.Order, error) {
+	exSymbol, err := futuresSymbol(symbol)
+	if err != nil {
+		return nil, fmt.Errorf("BinanceFuturesTradingAdapter: %w", err)
+	}
+	params := url.Values{}
+	params.Set("symbol", exSymbol)
+	params.Set("orderId", orderID)
+
+	var resp binanceFuturesOrder
+	if err := a.account.signedRequest(http.MethodGet, "/fapi/v1/order", params, &resp); err != nil {
+		return nil, err
+	}
+	order := a.convertOrder(resp)
+	if order.FilledVolume <= 0 {
+		return order, nil
+	}
+
+	var trades []binanceTrade
+	if err := a.account.signedRequest(http.MethodGet, "/fapi/v1/userTrades", params, &trades); err != nil {
+		return nil, err
+	}
+	addBinanceTradeFees(order, trades)
+	return order, nil
+}
+
+// GetOpenOrders возвращает открытые ордера (по символу или все при пустом symbol)
+func (a *BinanceFuturesTradingAdapter) GetOpenOrders(symbol string) ([]market.Order, error) {
+	params := url.Values{}
+	if symbol != "" {
+		exSymbol, err := futuresSymbol(symbol)
+		if err != nil {
+			return nil, fmt.Errorf("BinanceFuturesTradingAdapter: %w", err)
+		}
+		params.Set("symbol", exSymbol)
+	}
+
+	var resp []binanceFuturesOrder
+	if err := a.account.signedRequest(http.MethodGet, "/fapi/v1/openOrders", params, &resp); err != nil {
+		return nil, err
+	}
+	orders := make([]market.Order, 0, len(resp))
+	for _, o := range resp {
+		orders = append(orders, *a.convertOrder(o))
+	}
+	return orders, nil
+}
+
+// GetBalances возвращает ненулевые балансы маржи фьючерсного аккаунта
+func (a *BinanceFuturesTradingAdapter) GetBalances() ([]market.Balance, error) {
+	var resp []struct {
+		Asset            string `json:"asset"`
+		Balance          string `json:"balance"`
+		AvailableBalance string `json:"availableBalance"`
+	}
+	if err := a.account.signedRequest(http.MethodGet, "/fapi/v2/balance", nil, &resp); err != nil {
+		return nil, err
+	}
+
+	balances := make([]market.Balance, 0)
+	for _, b := range resp {
+		total := parseFloatString(b.Balance)
+		if total <= 0 {
+			continue
+		}
+		free := parseFloatString(b.AvailableBalance)
+		balances = append(balances, market.Balance{Asset: b.Asset, Free: free, Locked: total - free})
+	}
+	return balances, nil
+}
+
+// GetFundingPayments возвращает расчеты финансирования по контракту (/fapi/v1/income, FUNDING_FEE)
+func (a *BinanceFuturesTradingAdapter) GetFundingPayments(symbol string, since time.Time) ([]market.FundingPayment, error) {
+	exSymbol, err := futuresSymbol(symbol)
+	if err != nil {
+		return nil, fmt.Errorf("BinanceFuturesTradingAdapter: %w", err)
+	}
+	params := url.Values{}
+	params.Set("symbol", exSymbol)
+	params.Set("incomeType", "FUNDING_FEE")
+	params.Set("limit", "1000")
+	if !since.IsZero() {
+		params.Set("startTime", strconv.FormatInt(since.UnixMilli(), 10))
+	}
+
+	var resp []struct {
+		Symbol string `json:"symbol"`
+		Income string `json:"income"`
+		Asset  string `json:"asset"`
+		Time   int64  `json:"time"`
+	}
+	if err := a.account.signedRequest(http.MethodGet, "/fapi/v1/income", params, &resp); err != nil {
+		return nil, err
+	}
+	payments := make([]market.FundingPayment, 0, len(resp))
+	for _, p := range resp {
+		payments = append(payments, market.FundingPayment{
+			Symbol: p.Symbol,
+			Asset:  p.Asset,
+			Amount: parseFloatString(p.Income),
+			Time:   millisToTime(p.Time),
+		})
+	}
+	return payments, nil
+}
+
+// convertOrder переводит ответ fapi в market.Order
+func (a *BinanceFuturesTradingAdapter) convertOrder(o binanceFuturesOrder) *market.Order {
+	return &market.Order{
+		Exchange:      a.ExchangeName(),
+		Symbol:        o.Symbol,
+		OrderID:       strconv.FormatInt(o.OrderID, 10),
+		ClientOrderID: o.ClientOrderID,
+		Status:        binanceOrderStatus(o.Status),
+		Side:          market.TradeSide(strings.ToLower(o.Side)),
+		OrderType:     market.OrderType(strings.ToLower(o.Type)),
+		Price:         parseFloatString(o.Price),
+		Volume:        parseFloatString(o.OrigQty),
+		FilledVolume:  parseFloatString(o.ExecutedQty),
+		AvgPrice:      parseFloatString(o.AvgPrice),
+		CreatedAt:     millisToTime(o.Time),
+		UpdatedAt:     millisToTime(o.UpdateTime),
+	}
+}
+
+// BybitLinearTradingAdapter - ордера и расчеты финансирования Bybit v5 категории linear.
+// Маржа - единый торговый аккаунт, общий со спотом
+type BybitLinearTradingAdapter struct {
+	account *BybitAdapter // ключи аккаунта и подпись запросов v5
+}
+
+// ExchangeName возвращает имя площадки контрактов (bybit.futures)
+func (a *BybitLinearTradingAdapter) ExchangeName() string {
+	return bus.FuturesTopic("bybit")
+}
+
+// PlaceOrder размещает лимитный или рыночный ордер на контракт (режим одной позиции)
+func (a *BybitLinearTradingAdapter) PlaceOrder(req market.OrderRequest) (*market.Order, error) {
+	symbol, err := futuresSymbol(req.Symbol)
+	if err != nil {
+		return nil, fmt.Errorf("BybitLinearTradingAdapter: %w", err)
+	}
+
+	payload := map[string]string{
+		"category": "linear",
+		"symbol":   symbol,
+		"side":     bybitSide(req.Side),
+		"qty":      formatFloat(req.Volume),
+	}
+	if req.ClientOrderID != "" {
+		payload["orderLinkId"] = req.ClientOrderID
+	}
+	switch req.OrderType {
+	case market.OrderTypeMarket:
+		payload["orderType"] = "Market"
+	default:
+		payload["orderType"] = "Limit"
+		payload["timeInForce"] = "GTC"
+		payload["price"] = formatFloat(req.Price)
+	}
+
+	var resp struct {
+		OrderID     string `json:"orderId"`
+		OrderLinkID string `json:"orderLinkId"`
+	}
+	if err := a.account.signedRequest(http.MethodPost, "/v5/order/create", nil, payload, &resp); err != nil {
+		return nil, err
+	}
+	a.account.logger.Info("[BYBIT_LINEAR] Order placed: %s %s %s %s id=%s",
+		symbol, payload["side"], payload["orderType"], payload["qty"], resp.OrderID)
+
+	return &market.Order{
+		Exchange:      a.ExchangeName(),
+		Symbol:        symbol,
+		OrderID:       resp.OrderID,
+		ClientOrderID: resp.OrderLinkID,
+		Status:        market.OrderStatusNew,
+		Side:          req.Side,
+		OrderType:     req.OrderType,
+		Price:         req.Price,
+		Volume:        req.Volume,
+	}, nil
+}
+
+// CancelOrder отменяет ордер
+func (a *BybitLinearTradingAdapter) CancelOrder(symbol, orderID string) error {
+	exSymbol, err := futuresSymbol(symbol)
+	if err != nil {
+		return fmt.Errorf("BybitLinearTradingAdapter: %w", err)
+	}
+	payload := map[string]string{
+		"category": "linear",
+		"symbol":   exSymbol,
+		"orderId":  orderID,
+	}
+	return a.account.signedRequest(http.MethodPost, "/v5/order/cancel", nil, payload, nil)
+}
+
+// GetOrder возвращает состояние ордера: сначала среди активных, затем в истории
+func (a *BybitLinearTradingAdapter) GetOrder(symbol, orderID string) (*market.Order, error) {
+	exSymbol, err := futuresSymbol(symbol)
+	if err != nil {
+		return nil, fmt.Errorf("BybitLinearTradingAdapter: %w", err)
+	}
+
+	for _, path := range []string{"/v5/order/realtime", "/v5/order/history"} {
+		params := url.Values{}
+		params.Set("category", "linear")
+		params.Set("symbol", exSymbol)
+		params.Set("orderId", orderID)
+
+		var resp struct {
+			List []bybitOrder `json:"list"`
+		}
+		if err := a.account.signedRequest(http.MethodGet, path, params, nil, &resp); err != nil {
+			return nil, err
+		}
+		if len(resp.List) > 0 {
+			return a.convertOrder(resp.List[0]), nil
+		}
+	}
+
+	return nil, fmt.Errorf("BybitLinearTradingAdapter: order %s not found", orderID)
+}
+
+// GetOpenOrders возвращает открытые ордера; без символа - по контрактам с котировкой в USDT
+func (a *BybitLinearTradingAdapter) GetOpenOrders(symbol string) ([]market.Order, error) {
+	params := url.Values{}
+	params.Set("category", "linear")
+	params.Set("openOnly", "0")
+	if symbol != "" {
+		exSymbol, err := futuresSymbol(symbol)
+		if err != nil {
+			return nil, fmt.Errorf("BybitLinearTradingAdapter: %w", err)
+		}
+		params.Set("symbol", exSymbol)
+	} else {
+		params.Set("settleCoin", "USDT")
+	}
+
+	var resp struct {
+		List []bybitOrder `json:"list"`
+	}
+	if err := a.account.signedRequest(http.MethodGet, "/v5/order/realtime", params, nil, &resp); err != nil {
+		return nil, err
+	}
+
+	orders := make([]market.Order, 0, len(resp.List))
+	for _, o := range resp.List {
+		order := a.convertOrder(o)
+		if !order.Status.IsFinal() {
+			orders = append(orders, *order)
+		}
+	}
+	return orders, nil
+}
+
+// GetBalances возвращает балансы единого торгового аккаунта
+func (a *BybitLinearTradingAdapter) GetBalances() ([]market.Balance, error) {
+	return a.account.GetBalances()
+}
+
+// GetFundingPayments возвращает расчеты финансирования по контракту (/v5/account/transaction-log,
+// SETTLEMENT). В журнале Bybit положительный funding - расход, поэтому знак меняется.
+// Bybit отдает не больше 7 дней от startTime, остальное догружается следующими запросами
+func (a *BybitLinearTradingAdapter) GetFundingPayments(symbol string, since time.Time) ([]market.FundingPayment, error) {
+	exSymbol, err := futuresSymbol(symbol)
+	if err != nil {
+		return nil, fmt.Errorf("BybitLinearTradingAdapter: %w", err)
+	}
+	params := url.Values{}
+	params.Set("accountType", "UNIFIED")
+	params.Set("category", "linear")
+	params.Set("type", "SETTLEMENT")
+	params.Set("symbol", exSymbol)
+	params.Set("limit", "50")
+	if !since.IsZero() {
+		params.Set("startTime", strconv.FormatInt(since.UnixMilli(), 10))
+	}
+
+	var resp struct {
+		List []struct {
+			Symbol          string `json:"symbol"`
+			Currency        string `json:"currency"`
+			Funding         string `json:"funding"`
+			TransactionTime string `json:"transactionTime"`
+		} `json:"list"`
+	}
+	if err := a.account.signedRequest(http.MethodGet, "/v5/account/transaction-log", params, nil, &resp); err != nil {
+		return nil, err
+	}
+	payments := make([]market.FundingPayment, 0, len(resp.List))
+	for i := len(resp.List) - 1; i >= 0; i-- { // Bybit отдает новые записи первыми
+		p := resp.List[i]
+		ms, _ := strconv.ParseInt(p.TransactionTime, 10, 64)
+		payments = append(payments, market.FundingPayment{
+			Symbol: p.Symbol,
+			Asset:  p.Currency,
+			Amount: -parseFloatString(p.Funding),
+			Time:   millisToTime(ms),
+		})
+	}
+	return payments, nil
+}
+
+// convertOrder переводит ордер Bybit linear в market.Order
+func (a *BybitLinearTradingAdapter) convertOrder(o bybitOrder) *market.Order {
+	order := convertBybitOrder(o, o.Symbol)
+	order.Exchange = a.ExchangeName()
+	return order
+}
@@ -0,0 +1,100 @@
+package exchange
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"daemon-go/internal/db"
+)
+
+// Ордер Binance USDⓈ-M: подписанный запрос fapi, комиссия - сумма сделок ордера
+func TestBinanceFuturesTradingGetOrderFees(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.RawQuery
+		i := strings.LastIndex(query, "&signature=")
+		if i < 0 || query[i+len("&signature="):] != signHex("secret", query[:i]) || r.Header.Get("X-MBX-APIKEY") != "key" {
+			w.WriteHeader(http.StatusUnauthorized)
+			_, _ = w.Write([]byte(`{"code":-1022,"msg":"Signature for this request is not valid."}`))
+			return
+		}
+		switch r.URL.Path {
+		case "/fapi/v1/order":
+			_, _ = w.Write([]byte(`{"symbol":"BTCUSDT","orderId":42,"clientOrderId":"c1","price":"101","avgPrice":"101.5",
+				"origQty":"2","executedQty":"2","status":"FILLED","type":"LIMIT","side":"SELL","time":1700000000000,"updateTime":1700000001000}`))
+		case "/fapi/v1/userTrades":
+			_, _ = w.Write([]byte(`[{"commission":"0.04","commissionAsset":"USDT"},{"commission":"0.06","commissionAsset":"USDT"}]`))
+		case "/fapi/v1/income":
+			if r.URL.Query().Get("incomeType") != "FUNDING_FEE" || r.URL.Query().Get("startTime") != "1700000000000" {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			_, _ = w.Write([]byte(`[{"symbol":"BTCUSDT","incomeType":"FUNDING_FEE","income":"-0.125","asset":"USDT","time":1700006400000}]`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+	SetFuturesEndpoints(map[string]string{"binance_rest": srv.URL})
+	defer SetFuturesEndpoints(nil)
+
+	adapter, err := NewFuturesTradingAdapter(db.Exchange{Name: "Binance", ApiKey: "key", ApiSecret: "secret"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if adapter.ExchangeName() != "binance.futures" {
+		t.Fatalf("exchange name %q", adapter.ExchangeName())
+	}
+
+	order, err := adapter.GetOrder("BTCUSDT", "42")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if order.Exchange != "binance.futures" || order.FilledVolume != 2 || order.AvgPrice != 101.5 {
+		t.Fatalf("order %+v", order)
+	}
+	if order.Fee < 0.1-1e-9 || order.Fee > 0.1+1e-9 || order.FeeCurrency != "USDT" {
+		t.Fatalf("fee %.8f %s, want 0.1 USDT", order.Fee, order.FeeCurrency)
+	}
+
+	payments, err := adapter.(FundingHistorySource).GetFundingPayments("BTCUSDT", time.UnixMilli(1700000000000))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(payments) != 1 || payments[0].Amount != -0.125 || !payments[0].Time.Equal(time.UnixMilli(1700006400000)) {
+		t.Fatalf("payments %+v", payments)
+	}
+}
+
+// Bybit журналирует расход финансирования положительным funding: знак платежа меняется,
+// записи возвращаются в порядке времени
+func TestBybitLinearFundingPayments(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v5/account/transaction-log" || r.URL.Query().Get("type") != "SETTLEMENT" ||
+			r.URL.Query().Get("category") != "linear" || r.Header.Get("X-BAPI-SIGN") == "" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		_, _ = w.Write([]byte(`{"retCode":0,"retMsg":"OK","result":{"list":[
+			{"symbol":"BTCUSDT","currency":"USDT","funding":"-0.3","transactionTime":"1700028800000"},
+			{"symbol":"BTCUSDT","currency":"USDT","funding":"0.1","transactionTime":"1700000000000"}]}}`))
+	}))
+	defer srv.Close()
+	SetFuturesEndpoints(map[string]string{"bybit_rest": srv.URL})
+	defer SetFuturesEndpoints(nil)
+
+	adapter, err := NewFuturesTradingAdapter(db.Exchange{Name: "bybit", ApiKey: "key", ApiSecret: "secret"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	payments, err := adapter.(FundingHistorySource).GetFundingPayments("BTCUSDT", time.Time{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(payments) != 2 || payments[0].Amount != -0.1 || payments[1].Amount != 0.3 ||
+		!payments[0].Time.Before(payments[1].Time) {
+		t.Fatalf("payments %+v", payments)
+	}
+}
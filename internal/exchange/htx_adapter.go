@@ -2,7 +2,9 @@ package exchange
 
 import (
 	"daemon-go/internal/bus"
+	"daemon-go/internal/capture"
 	"daemon-go/internal/db"
+	"daemon-go/internal/market"
 	"daemon-go/internal/market/parsers"
 	"daemon-go/pkg/log"
 	"encoding/json"
@@ -11,6 +13,9 @@ import (
 	"time"
 )
 
+// htxMbpLevels - глубина diff потока mbp (150 уровней доступны на /ws, 5/20/400 - только на /feed)
+const htxMbpLevels = 150
+
 // HtxAdapter реализует Adapter для биржи HTX
 type HtxAdapter struct {
 	exchange       db.Exchange
@@ -23,6 +28,10 @@ type HtxAdapter struct {
 	logger         *log.Logger
 	parser         *parsers.HTXParser
 	messageBus     *bus.MessageBus
+	bookSync       *OrderBookSync  // контроль последовательности обновлений стакана
+	pairIDMap      map[string]int  // symbol -> pairID маппинг
+	accountID      int64           // ID спотового аккаунта для торговых запросов
+	userData       *userDataStream // приватный поток ордеров и балансов
 }
 
 // SubscribeMarkets для HTX с подробным логированием
@@ -45,9 +54,10 @@ func (a *HtxAdapter) SubscribeMarkets(pairs []string, marketType string, depth i
 		symbol = strings.ReplaceAll(strings.ToLower(symbol), "-", "")
 		a.logger.Debug("[HTX_ADAPTER] Processing pair: %s -> symbol: %s", pair, symbol)
 
-		// Подписка на order book для HTX
+		// Подписка на diff order book для HTX (mbp, seqNum/prevSeqNum): начальный снимок и разрывы
+		// восстанавливает OrderBookSync запросом req того же канала (REST /market/depth без seqNum)
 		subOrderbook := map[string]interface{}{
-			"sub": fmt.Sprintf("market.%s.depth.step%d", symbol, depth),
+			"sub": fmt.Sprintf("market.%s.mbp.%d", symbol, htxMbpLevels),
 			"id":  fmt.Sprintf("sub-%s-%d", symbol, depth),
 		}
 
@@ -90,6 +100,48 @@ func (a *HtxAdapter) SubscribeMarkets(pairs []string, marketType string, depth i
 		}
 
 		a.logger.Debug("[HTX_ADAPTER] Ticker subscription sent successfully for pair %s", pair)
+
+		// Подписка на сделки для HTX
+		subTrade := map[string]interface{}{
+			"sub": fmt.Sprintf("market.%s.trade.detail", symbol),
+			"id":  fmt.Sprintf("sub-trade-%s", symbol),
+		}
+
+		dataTrade, err := json.Marshal(subTrade)
+		if err != nil {
+			return fmt.Errorf("HtxAdapter: marshal trade sub: %w", err)
+		}
+
+		if getOrderBookConfig().OrderBook.DebugLogRaw {
+			a.logger.Debug("[HTX_ADAPTER] SENDING trade subscribe to HTX: %s", string(dataTrade))
+		}
+
+		if err := a.ws.WriteMessage(1, dataTrade); err != nil {
+			return fmt.Errorf("HtxAdapter: ws trade sub: %w", err)
+		}
+
+		a.logger.Debug("[HTX_ADAPTER] Trade subscription sent successfully for pair %s", pair)
+
+		// Подписка на минутные свечи для HTX
+		subKline := map[string]interface{}{
+			"sub": fmt.Sprintf("market.%s.kline.1min", symbol),
+			"id":  fmt.Sprintf("sub-kline-%s", symbol),
+		}
+
+		dataKline, err := json.Marshal(subKline)
+		if err != nil {
+			return fmt.Errorf("HtxAdapter: marshal kline sub: %w", err)
+		}
+
+		if getOrderBookConfig().OrderBook.DebugLogRaw {
+			a.logger.Debug("[HTX_ADAPTER] SENDING kline subscribe to HTX: %s", string(dataKline))
+		}
+
+		if err := a.ws.WriteMessage(1, dataKline); err != nil {
+			return fmt.Errorf("HtxAdapter: ws kline sub: %w", err)
+		}
+
+		a.logger.Debug("[HTX_ADAPTER] Kline subscription sent successfully for pair %s", pair)
 	}
 
 	a.logger.Debug("[HTX_ADAPTER] SubscribeMarkets completed successfully")
@@ -103,7 +155,7 @@ func NewHtxAdapter(ex db.Exchange) *HtxAdapter {
 		wsClient = NewCexWsClient(ex.WsUrl.String)
 	}
 
-	return &HtxAdapter{
+	a := &HtxAdapter{
 		exchange:   ex,
 		rest:       NewCexRestClient(ex.BaseUrl),
 		ws:         wsClient,
@@ -111,7 +163,38 @@ func NewHtxAdapter(ex db.Exchange) *HtxAdapter {
 		logger:     log.New("htx_adapter"),
 		parser:     parsers.NewHTXParser(),
 		messageBus: bus.GetInstance(),
+		pairIDMap:  make(map[string]int),
 	}
+	a.bookSync = NewOrderBookSync("htx", nil, func(msg market.UnifiedMessage) {
+		a.messageBus.Publish("htx", msg)
+	})
+	a.bookSync.SetSnapshotRequester(a.requestOrderBookSnapshot)
+	return a
+}
+
+// requestOrderBookSnapshot запрашивает снимок mbp через WS req: ответ содержит seqNum,
+// к которому OrderBookSync привязывает буферизованные diff
+func (a *HtxAdapter) requestOrderBookSnapshot(symbol string) error {
+	exSymbol, err := exchangeSymbol(symbol, "")
+	if err != nil {
+		return fmt.Errorf("HtxAdapter: %w", err)
+	}
+	if a.ws == nil || !a.ws.IsConnected() {
+		return fmt.Errorf("HtxAdapter: ws not connected")
+	}
+
+	channel := fmt.Sprintf("market.%s.mbp.%d", strings.ToLower(exSymbol), htxMbpLevels)
+	data, err := json.Marshal(map[string]interface{}{
+		"req": channel,
+		"id":  "snapshot-" + strings.ToLower(exSymbol),
+	})
+	if err != nil {
+		return fmt.Errorf("HtxAdapter: marshal snapshot req: %w", err)
+	}
+	if err := a.ws.WriteMessage(1, data); err != nil {
+		return fmt.Errorf("HtxAdapter: ws snapshot req: %w", err)
+	}
+	return nil
 }
 func (a *HtxAdapter) UnsubscribeMarkets(pairs []string, marketType string, depth int) error {
 	if a.ws == nil || !a.ws.IsConnected() {
@@ -124,7 +207,7 @@ func (a *HtxAdapter) UnsubscribeMarkets(pairs []string, marketType string, depth
 
 		// Отписка от order book
 		unsubOrderbook := map[string]interface{}{
-			"unsub": fmt.Sprintf("market.%s.depth.step%d", symbol, depth),
+			"unsub": fmt.Sprintf("market.%s.mbp.%d", symbol, htxMbpLevels),
 			"id":    fmt.Sprintf("unsub-%s-%d", symbol, depth),
 		}
 
@@ -159,6 +242,36 @@ func (a *HtxAdapter) UnsubscribeMarkets(pairs []string, marketType string, depth
 		if err := a.ws.WriteMessage(1, dataTicker); err != nil {
 			return fmt.Errorf("HtxAdapter: ws ticker unsub: %w", err)
 		}
+
+		// Отписка от сделок
+		unsubTrade := map[string]interface{}{
+			"unsub": fmt.Sprintf("market.%s.trade.detail", symbol),
+			"id":    fmt.Sprintf("unsub-trade-%s", symbol),
+		}
+
+		dataTrade, err := json.Marshal(unsubTrade)
+		if err != nil {
+			return fmt.Errorf("HtxAdapter: marshal trade unsub: %w", err)
+		}
+
+		if err := a.ws.WriteMessage(1, dataTrade); err != nil {
+			return fmt.Errorf("HtxAdapter: ws trade unsub: %w", err)
+		}
+
+		// Отписка от свечей
+		unsubKline := map[string]interface{}{
+			"unsub": fmt.Sprintf("market.%s.kline.1min", symbol),
+			"id":    fmt.Sprintf("unsub-kline-%s", symbol),
+		}
+
+		dataKline, err := json.Marshal(unsubKline)
+		if err != nil {
+			return fmt.Errorf("HtxAdapter: marshal kline unsub: %w", err)
+		}
+
+		if err := a.ws.WriteMessage(1, dataKline); err != nil {
+			return fmt.Errorf("HtxAdapter: ws kline unsub: %w", err)
+		}
 	}
 
 	return nil
@@ -202,7 +315,7 @@ func (a *HtxAdapter) readLoopWithReconnect() {
 			return
 		}
 
-		_, msgData, err := a.ws.ReadMessage()
+		msgType, msgData, err := a.ws.ReadMessage()
 		if err != nil {
 			a.logger.Error("[HTX_ADAPTER] Read error: %v, reconnecting...", err)
 
@@ -215,6 +328,7 @@ func (a *HtxAdapter) readLoopWithReconnect() {
 				time.Sleep(3 * time.Second)
 				if err := a.ws.Reconnect(); err == nil {
 					a.logger.Info("[HTX_ADAPTER] Reconnected, resubscribing...")
+					a.bookSync.Reset()
 					if err := a.SubscribeMarkets(a.lastPairs, a.lastMarketType, a.lastDepth); err != nil {
 						a.logger.Error("[HTX_ADAPTER] Resubscribe error: %v", err)
 					}
@@ -225,6 +339,7 @@ func (a *HtxAdapter) readLoopWithReconnect() {
 			}
 			continue
 		}
+		capture.Record("htx", msgType, msgData)
 
 		// Логируем полученное сообщение
 		if getOrderBookConfig().OrderBook.DebugLogRaw {
@@ -268,8 +383,20 @@ func (a *HtxAdapter) processMessage(data []byte) {
 		a.logger.Debug("[HTX_ADAPTER] Processed message: %s %s %s", unifiedMsg.Exchange, unifiedMsg.Symbol, unifiedMsg.MessageType)
 	}
 
-	// Отправляем в message bus
-	a.messageBus.Publish("htx", *unifiedMsg)
+	// Добавляем PairID в сообщение
+	msg := *unifiedMsg
+	if pairID, exists := a.pairIDMap[msg.Symbol]; exists {
+		msg.PairID = pairID
+	}
+
+	// Отправляем в message bus, стаканы - через контроль последовательности
+	if msg.MessageType == market.MessageTypeOrderBook {
+		a.bookSync.Process(msg)
+	} else {
+		for _, m := range market.SplitTrades(msg) {
+			a.messageBus.Publish("htx", m)
+		}
+	}
 }
 
 // handlePingPong обрабатывает ping/pong сообщения от HTX
@@ -336,6 +463,11 @@ func (a *HtxAdapter) ExchangeName() string {
 
 // SubscribeMarketsWithPairID подписывается на рынки с сохранением PairID
 func (a *HtxAdapter) SubscribeMarketsWithPairID(marketPairs []MarketPair, marketType string, depth int) error {
+	// Сохраняем маппинг symbol -> pairID
+	for _, pair := range marketPairs {
+		a.pairIDMap[pair.Symbol] = pair.PairID
+	}
+
 	// Извлекаем символы из MarketPair для совместимости с существующим методом
 	symbols := make([]string, len(marketPairs))
 	for i, mp := range marketPairs {
@@ -348,6 +480,11 @@ func (a *HtxAdapter) SubscribeMarketsWithPairID(marketPairs []MarketPair, market
 
 // UnsubscribeMarketsWithPairID отписывается от рынков
 func (a *HtxAdapter) UnsubscribeMarketsWithPairID(marketPairs []MarketPair, marketType string, depth int) error {
+	// Удаляем маппинг
+	for _, pair := range marketPairs {
+		delete(a.pairIDMap, pair.Symbol)
+	}
+
 	// Извлекаем символы из MarketPair для совместимости с существующим методом
 	symbols := make([]string, len(marketPairs))
 	for i, mp := range marketPairs {
@@ -0,0 +1,320 @@
+package exchange
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"daemon-go/internal/market"
+)
+
+// htxResponse - общий конверт ответа HTX
+type htxResponse struct {
+	Status  string          `json:"status"`
+	ErrCode string          `json:"err-code"`
+	ErrMsg  string          `json:"err-msg"`
+	Data    json.RawMessage `json:"data"`
+}
+
+// htxOrder - ордер HTX
+type htxOrder struct {
+	ID               int64  `json:"id"`
+	ClientOrderID    string `json:"client-order-id"`
+	Symbol           string `json:"symbol"`
+	Amount           string `json:"amount"`
+	Price            string `json:"price"`
+	Type             string `json:"type"` // buy-limit, sell-market, ...
+	State            string `json:"state"`
+	FieldAmount      string `json:"field-amount"`
+	FieldCashAmount  string `json:"field-cash-amount"`
+	FieldFees        string `json:"field-fees"`
+	FilledAmount     string `json:"filled-amount"`
+	FilledCashAmount string `json:"filled-cash-amount"`
+	FilledFees       string `json:"filled-fees"`
+	CreatedAt        int64  `json:"created-at"`
+}
+
+// signedRequest выполняет подписанный запрос к HTX (Signature Version 2):
+// подписывается "METHOD\nhost\npath\nsorted_query", подпись передается в query
+func (a *HtxAdapter) signedRequest(method, path string, params url.Values, payload interface{}, result interface{}) error {
+	if err := checkCredentials("HtxAdapter", a.exchange.ApiKey, a.exchange.ApiSecret); err != nil {
+		return err
+	}
+
+	base, err := url.Parse(a.rest.BaseURL)
+	if err != nil {
+		return fmt.Errorf("HtxAdapter: parse base url: %w", err)
+	}
+
+	if params == nil {
+		params = url.Values{}
+	}
+	params.Set("AccessKeyId", a.exchange.ApiKey)
+	params.Set("SignatureMethod", "HmacSHA256")
+	params.Set("SignatureVersion", "2")
+	params.Set("Timestamp", time.Now().UTC().Format("2006-01-02T15:04:05"))
+
+	// url.Values.Encode сортирует параметры по ключу
+	query := params.Encode()
+	query += "&Signature=" + url.QueryEscape(signBase64(a.exchange.ApiSecret, htxSignPayload(method, base.Host, path, query)))
+
+	var body []byte
+	if payload != nil {
+		body, err = json.Marshal(payload)
+		if err != nil {
+			return fmt.Errorf("HtxAdapter: marshal: %w", err)
+		}
+	}
+
+	respBody, status, err := a.rest.DoSigned(method, path+"?"+query, body, nil)
+	if err != nil {
+		return fmt.Errorf("HtxAdapter: %s %s: %w", method, path, err)
+	}
+	if status != http.StatusOK {
+		return fmt.Errorf("HtxAdapter: %s %s: status %d: %s", method, path, status, string(respBody))
+	}
+
+	var resp htxResponse
+	if err := json.Unmarshal(respBody, &resp); err != nil {
+		return fmt.Errorf("HtxAdapter: decode: %w", err)
+	}
+	if resp.Status != "ok" {
+		return fmt.Errorf("HtxAdapter: %s %s: %s: %s", method, path, resp.ErrCode, resp.ErrMsg)
+	}
+	if result == nil {
+		return nil
+	}
+	return json.Unmarshal(resp.Data, result)
+}
+
+// htxSignPayload - строка подписи HTX (Signature Version 2): метод, хост в нижнем регистре,
+// путь и отсортированная закодированная query, разделенные переводом строки
+func htxSignPayload(method, host, path, query string) string {
+	return method + "\n" + strings.ToLower(host) + "\n" + path + "\n" + query
+}
+
+// spotAccountID возвращает (и кэширует) ID спотового аккаунта, обязательный для торговых запросов HTX
+func (a *HtxAdapter) spotAccountID() (int64, error) {
+	if a.accountID != 0 {
+		return a.accountID, nil
+	}
+
+	var accounts []struct {
+		ID    int64  `json:"id"`
+		Type  string `json:"type"`
+		State string `json:"state"`
+	}
+	if err := a.signedRequest(http.MethodGet, "/v1/account/accounts", nil, nil, &accounts); err != nil {
+		return 0, err
+	}
+	for _, acc := range accounts {
+		if acc.Type == "spot" && acc.State == "working" {
+			a.accountID = acc.ID
+			return acc.ID, nil
+		}
+	}
+	return 0, fmt.Errorf("HtxAdapter: spot account not found")
+}
+
+// PlaceOrder размещает лимитный или рыночный ордер.
+// Рыночная покупка на HTX задается суммой в quote валюте, поэтому для нее нужна оценочная цена
+func (a *HtxAdapter) PlaceOrder(req market.OrderRequest) (*market.Order, error) {
+	symbol, err := exchangeSymbol(req.Symbol, "")
+	if err != nil {
+		return nil, fmt.Errorf("HtxAdapter: %w", err)
+	}
+	symbol = strings.ToLower(symbol)
+
+	accountID, err := a.spotAccountID()
+	if err != nil {
+		return nil, err
+	}
+
+	payload := map[string]string{
+		"account-id": strconv.FormatInt(accountID, 10),
+		"symbol":     symbol,
+		"type":       string(req.Side) + "-" + string(req.OrderType),
+		"amount":     formatFloat(req.Volume),
+		"source":     "spot-api",
+	}
+	if req.ClientOrderID != "" {
+		payload["client-order-id"] = req.ClientOrderID
+	}
+	switch {
+	case req.OrderType == market.OrderTypeLimit:
+		payload["price"] = formatFloat(req.Price)
+	case req.Side == market.TradeSideBuy:
+		if req.Price <= 0 {
+			return nil, fmt.Errorf("HtxAdapter: market buy requires price to compute quote amount")
+		}
+		payload["amount"] = formatFloat(req.Volume * req.Price)
+	}
+
+	var orderID string
+	if err := a.signedRequest(http.MethodPost, "/v1/order/orders/place", nil, payload, &orderID); err != nil {
+		return nil, err
+	}
+	a.logger.Info("[HTX_ADAPTER] Order placed: %s %s %s id=%s", symbol, payload["type"], payload["amount"], orderID)
+
+	return &market.Order{
+		Exchange:      "htx",
+		Symbol:        req.Symbol,
+		OrderID:       orderID,
+		ClientOrderID: req.ClientOrderID,
+		Status:        market.OrderStatusNew,
+		Side:          req.Side,
+		OrderType:     req.OrderType,
+		Price:         req.Price,
+		Volume:        req.Volume,
+	}, nil
+}
+
+// CancelOrder отменяет ордер
+func (a *HtxAdapter) CancelOrder(symbol, orderID string) error {
+	return a.signedRequest(http.MethodPost, "/v1/order/orders/"+orderID+"/submitcancel", nil, map[string]string{}, nil)
+}
+
+// GetOrder возвращает состояние ордера
+func (a *HtxAdapter) GetOrder(symbol, orderID string) (*market.Order, error) {
+	var resp htxOrder
+	if err := a.signedRequest(http.MethodGet, "/v1/order/orders/"+orderID, nil, nil, &resp); err != nil {
+		return nil, err
+	}
+	return convertHtxOrder(resp, symbol), nil
+}
+
+// GetOpenOrders возвращает открытые ордера спотового аккаунта по символу:
+// /v1/order/openOrders без symbol не принимается (ErrSymbolRequired)
+func (a *HtxAdapter) GetOpenOrders(symbol string) ([]market.Order, error) {
+	if symbol == "" {
+		return nil, fmt.Errorf("HtxAdapter: open orders: %w", ErrSymbolRequired)
+	}
+	accountID, err := a.spotAccountID()
+	if err != nil {
+		return nil, err
+	}
+
+	params := url.Values{}
+	params.Set("account-id", strconv.FormatInt(accountID, 10))
+	exSymbol, err := exchangeSymbol(symbol, "")
+	if err != nil {
+		return nil, fmt.Errorf("HtxAdapter: %w", err)
+	}
+	params.Set("symbol", strings.ToLower(exSymbol))
+
+	var resp []htxOrder
+	if err := a.signedRequest(http.MethodGet, "/v1/order/openOrders", params, nil, &resp); err != nil {
+		return nil, err
+	}
+
+	orders := make([]market.Order, 0, len(resp))
+	for _, o := range resp {
+		orders = append(orders, *convertHtxOrder(o, unifySymbol(o.Symbol)))
+	}
+	return orders, nil
+}
+
+// GetBalances возвращает балансы спотового аккаунта
+func (a *HtxAdapter) GetBalances() ([]market.Balance, error) {
+	accountID, err := a.spotAccountID()
+	if err != nil {
+		return nil, err
+	}
+
+	var resp struct {
+		List []struct {
+			Currency string `json:"currency"`
+			Type     string `json:"type"` // trade | frozen
+			Balance  string `json:"balance"`
+		} `json:"list"`
+	}
+	path := fmt.Sprintf("/v1/account/accounts/%d/balance", accountID)
+	if err := a.signedRequest(http.MethodGet, path, nil, nil, &resp); err != nil {
+		return nil, err
+	}
+
+	byAsset := make(map[string]*market.Balance)
+	order := make([]string, 0)
+	for _, item := range resp.List {
+		amount := parseFloatString(item.Balance)
+		if amount == 0 {
+			continue
+		}
+		asset := strings.ToUpper(item.Currency)
+		b, ok := byAsset[asset]
+		if !ok {
+			b = &market.Balance{Asset: asset}
+			byAsset[asset] = b
+			order = append(order, asset)
+		}
+		if item.Type == "frozen" {
+			b.Locked += amount
+		} else {
+			b.Free += amount
+		}
+	}
+
+	balances := make([]market.Balance, 0, len(order))
+	for _, asset := range order {
+		balances = append(balances, *byAsset[asset])
+	}
+	return balances, nil
+}
+
+// convertHtxOrder переводит ордер HTX в market.Order
+func convertHtxOrder(o htxOrder, symbol string) *market.Order {
+	side, orderType := market.TradeSideBuy, market.OrderTypeLimit
+	if parts := strings.SplitN(o.Type, "-", 2); len(parts) == 2 {
+		side = market.TradeSide(parts[0])
+		orderType = market.OrderType(parts[1])
+	}
+
+	// Поля field-* в ответе get-order, filled-* в openOrders
+	filled := parseFloatString(o.FieldAmount) + parseFloatString(o.FilledAmount)
+	filledCash := parseFloatString(o.FieldCashAmount) + parseFloatString(o.FilledCashAmount)
+	fee := parseFloatString(o.FieldFees) + parseFloatString(o.FilledFees)
+
+	volume := parseFloatString(o.Amount)
+	if side == market.TradeSideBuy && orderType == market.OrderTypeMarket {
+		// Объем рыночной покупки задан в quote валюте
+		volume = filled
+	}
+
+	return &market.Order{
+		Exchange:      "htx",
+		Symbol:        symbol,
+		OrderID:       strconv.FormatInt(o.ID, 10),
+		ClientOrderID: o.ClientOrderID,
+		Status:        htxOrderStatus(o.State),
+		Side:          side,
+		OrderType:     orderType,
+		Price:         parseFloatString(o.Price),
+		Volume:        volume,
+		FilledVolume:  filled,
+		AvgPrice:      avgPrice(filledCash, filled),
+		Fee:           fee,
+		CreatedAt:     millisToTime(o.CreatedAt),
+	}
+}
+
+// htxOrderStatus переводит статус ордера HTX в унифицированный
+func htxOrderStatus(state string) market.OrderStatus {
+	switch state {
+	case "created", "submitted":
+		return market.OrderStatusNew
+	case "partial-filled":
+		return market.OrderStatusPartiallyFilled
+	case "filled":
+		return market.OrderStatusFilled
+	case "canceled", "partial-canceled":
+		return market.OrderStatusCanceled
+	case "rejected":
+		return market.OrderStatusRejected
+	default:
+		return market.OrderStatusNew
+	}
+}
@@ -0,0 +1,251 @@
+package exchange
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"daemon-go/internal/market"
+)
+
+// htxPrivateWsURL - WebSocket v2 HTX для приватных данных (ордера и балансы)
+const htxPrivateWsURL = "wss://api.huobi.pro/ws/v2"
+
+// htxPrivateMessage - сообщение WebSocket v2 HTX
+type htxPrivateMessage struct {
+	Action  string          `json:"action"`
+	Ch      string          `json:"ch"`
+	Code    int             `json:"code"`
+	Message string          `json:"message"`
+	Data    json.RawMessage `json:"data"`
+}
+
+// htxOrderUpdate - событие orders#{symbol}
+type htxOrderUpdate struct {
+	EventType       string `json:"eventType"` // creation, trade, cancellation, deletion
+	Symbol          string `json:"symbol"`
+	OrderID         int64  `json:"orderId"`
+	ClientOrderID   string `json:"clientOrderId"`
+	Type            string `json:"type"` // buy-limit, sell-market ...
+	OrderPrice      string `json:"orderPrice"`
+	OrderSize       string `json:"orderSize"`
+	OrderStatus     string `json:"orderStatus"`
+	OrderCreateTime int64  `json:"orderCreateTime"`
+	TradePrice      string `json:"tradePrice"`
+	TradeVolume     string `json:"tradeVolume"`
+	TradeID         int64  `json:"tradeId"`
+	TradeTime       int64  `json:"tradeTime"`
+	ExecAmt         string `json:"execAmt"`
+	RemainAmt       string `json:"remainAmt"`
+	LastActTime     int64  `json:"lastActTime"`
+}
+
+// htxAccountUpdate - событие accounts.update#1 (баланс и доступная сумма)
+type htxAccountUpdate struct {
+	Currency   string `json:"currency"`
+	AccountID  int64  `json:"accountId"`
+	Balance    string `json:"balance"`
+	Available  string `json:"available"`
+	ChangeTime int64  `json:"changeTime"`
+}
+
+// StartUserData подключается к WebSocket v2 HTX с аутентификацией (Signature Version 2.1)
+// и подписывается на ордера и балансы спотового аккаунта
+func (a *HtxAdapter) StartUserData() error {
+	if err := checkCredentials("HtxAdapter", a.exchange.ApiKey, a.exchange.ApiSecret); err != nil {
+		return err
+	}
+	accountID, err := a.spotAccountID()
+	if err != nil {
+		return err
+	}
+	if a.userData == nil {
+		stream := newUserDataStream("htx")
+		stream.dial = func() (string, error) { return htxPrivateWsURL, nil }
+		stream.login = a.userDataLogin
+		stream.handle = func(ws *CexWsClient, _ int, data []byte) ([]market.UnifiedMessage, error) {
+			data, err := decompressGzip(data)
+			if err != nil {
+				return nil, err
+			}
+			if pong, ok := htxPong(data); ok {
+				return nil, ws.WriteMessage(1, pong)
+			}
+			return parseHtxUserData(data, accountID)
+		}
+		a.userData = stream
+	}
+	return a.userData.start()
+}
+
+// StopUserData останавливает приватный поток
+func (a *HtxAdapter) StopUserData() error {
+	if a.userData == nil {
+		return nil
+	}
+	return a.userData.stopStream()
+}
+
+// userDataLogin отправляет auth, дожидается ответа и подписывается на orders#* и accounts.update#1
+func (a *HtxAdapter) userDataLogin(ws *CexWsClient) error {
+	u, err := url.Parse(htxPrivateWsURL)
+	if err != nil {
+		return err
+	}
+	timestamp := time.Now().UTC().Format("2006-01-02T15:04:05")
+	params := url.Values{}
+	params.Set("accessKey", a.exchange.ApiKey)
+	params.Set("signatureMethod", "HmacSHA256")
+	params.Set("signatureVersion", "2.1")
+	params.Set("timestamp", timestamp)
+	signPayload := "GET\n" + strings.ToLower(u.Host) + "\n" + u.Path + "\n" + params.Encode()
+
+	auth := map[string]interface{}{
+		"action": "req",
+		"ch":     "auth",
+		"params": map[string]string{
+			"authType":         "api",
+			"accessKey":        a.exchange.ApiKey,
+			"signatureMethod":  "HmacSHA256",
+			"signatureVersion": "2.1",
+			"timestamp":        timestamp,
+			"signature":        signBase64(a.exchange.ApiSecret, signPayload),
+		},
+	}
+	if err := writeJSON(ws, auth); err != nil {
+		return err
+	}
+
+	// Подписки принимаются только после успешной аутентификации
+	for {
+		_, data, err := ws.ReadMessage()
+		if err != nil {
+			return err
+		}
+		if data, err = decompressGzip(data); err != nil {
+			return err
+		}
+		if pong, ok := htxPong(data); ok {
+			if err := ws.WriteMessage(1, pong); err != nil {
+				return err
+			}
+			continue
+		}
+		var msg htxPrivateMessage
+		if err := json.Unmarshal(data, &msg); err != nil {
+			return err
+		}
+		if msg.Ch != "auth" {
+			continue
+		}
+		if msg.Code != 200 {
+			return fmt.Errorf("auth failed: code %d: %s", msg.Code, msg.Message)
+		}
+		break
+	}
+
+	for _, ch := range []string{"orders#*", "accounts.update#1"} {
+		if err := writeJSON(ws, map[string]string{"action": "sub", "ch": ch}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// htxPong возвращает ответ на ping сервера WebSocket v2
+func htxPong(data []byte) ([]byte, bool) {
+	var msg htxPrivateMessage
+	if err := json.Unmarshal(data, &msg); err != nil || msg.Action != "ping" {
+		return nil, false
+	}
+	pong, err := json.Marshal(map[string]interface{}{"action": "pong", "data": msg.Data})
+	if err != nil {
+		return nil, false
+	}
+	return pong, true
+}
+
+// parseHtxUserData разбирает push сообщение WebSocket v2 HTX
+func parseHtxUserData(data []byte, accountID int64) ([]market.UnifiedMessage, error) {
+	var msg htxPrivateMessage
+	if err := json.Unmarshal(data, &msg); err != nil {
+		return nil, fmt.Errorf("htx user data: %w", err)
+	}
+	if msg.Action == "sub" && msg.Code != 200 {
+		return nil, fmt.Errorf("htx user data: subscribe %s failed: code %d: %s", msg.Ch, msg.Code, msg.Message)
+	}
+	if msg.Action != "push" {
+		return nil, nil
+	}
+
+	switch {
+	case strings.HasPrefix(msg.Ch, "orders#"):
+		var o htxOrderUpdate
+		if err := json.Unmarshal(msg.Data, &o); err != nil {
+			return nil, fmt.Errorf("htx order update: %w", err)
+		}
+		if o.EventType == "deletion" {
+			// удаление условного ордера до срабатывания
+			return nil, nil
+		}
+		return []market.UnifiedMessage{orderEventMessage(convertHtxOrderUpdate(o))}, nil
+
+	case strings.HasPrefix(msg.Ch, "accounts.update"):
+		var u htxAccountUpdate
+		if err := json.Unmarshal(msg.Data, &u); err != nil {
+			return nil, fmt.Errorf("htx account update: %w", err)
+		}
+		if u.AccountID != accountID || u.Currency == "" {
+			return nil, nil
+		}
+		available := parseFloatString(u.Available)
+		balances := []market.Balance{{
+			Asset:  strings.ToUpper(u.Currency),
+			Free:   available,
+			Locked: remaining(parseFloatString(u.Balance), available),
+		}}
+		return []market.UnifiedMessage{balanceMessage(millisToTime(u.ChangeTime), balances, u)}, nil
+	}
+	return nil, nil
+}
+
+// convertHtxOrderUpdate переводит событие ордера HTX в UnifiedOrderEvent
+func convertHtxOrderUpdate(o htxOrderUpdate) market.UnifiedOrderEvent {
+	side, orderType := market.TradeSideBuy, market.OrderTypeLimit
+	if parts := strings.SplitN(o.Type, "-", 2); len(parts) == 2 {
+		side = market.TradeSide(parts[0])
+		orderType = market.OrderType(parts[1])
+	}
+
+	volume := parseFloatString(o.OrderSize)
+	filled := parseFloatString(o.ExecAmt)
+	ts := o.LastActTime
+	if ts == 0 {
+		ts = o.OrderCreateTime
+	}
+
+	event := market.UnifiedOrderEvent{
+		Symbol:          unifySymbol(o.Symbol),
+		Timestamp:       millisToTime(ts),
+		OrderID:         strconv.FormatInt(o.OrderID, 10),
+		ClientOrderID:   o.ClientOrderID,
+		Status:          htxOrderStatus(o.OrderStatus),
+		Side:            side,
+		OrderType:       orderType,
+		Price:           parseFloatString(o.OrderPrice),
+		Volume:          volume,
+		FilledVolume:    filled,
+		RemainingVolume: parseFloatString(o.RemainAmt),
+		Raw:             o,
+	}
+	if o.EventType == "trade" {
+		event.TradeID = strconv.FormatInt(o.TradeID, 10)
+		event.LastFillPrice = parseFloatString(o.TradePrice)
+		event.LastFillVolume = parseFloatString(o.TradeVolume)
+		event.Timestamp = millisToTime(o.TradeTime)
+	}
+	return event
+}
@@ -2,6 +2,7 @@ package exchange
 
 import (
 	"daemon-go/internal/bus"
+	"daemon-go/internal/capture"
 	"daemon-go/internal/config"
 	"daemon-go/internal/db"
 	"daemon-go/internal/market"
@@ -38,7 +39,7 @@ func getOrderBookConfig() config.Config {
 	}
 }
 
-// SubscribeOrderBookAndBestPrice подписывает на order book (5/10) и best price для указанных пар
+// SubscribeOrderBookAndBestPrice подписывает на diff order book и best price для указанных пар
 func (a *KucoinAdapter) SubscribeOrderBookAndBestPrice(pairs []string, depth int) error {
 	if a.ws == nil || !a.ws.IsConnected() {
 		return fmt.Errorf("KucoinAdapter: ws not connected")
@@ -47,16 +48,10 @@ func (a *KucoinAdapter) SubscribeOrderBookAndBestPrice(pairs []string, depth int
 		// Конвертируем символ из формата "ERG/USDT" в "ERG-USDT" для KuCoin
 		kucoinPair := strings.Replace(pair, "/", "-", -1)
 
-		// Используем правильные топики KuCoin для спот данных
-		var orderbookTopic, tickerTopic string
-		if depth == 5 {
-			orderbookTopic = "/spotMarket/level2Depth5:" + kucoinPair
-		} else if depth == 20 {
-			orderbookTopic = "/spotMarket/level2Depth20:" + kucoinPair
-		} else {
-			orderbookTopic = "/spotMarket/level2Depth5:" + kucoinPair
-		}
-		tickerTopic = "/spotMarket/level1:" + kucoinPair
+		// Diff поток /market/level2 (sequenceStart/sequenceEnd): начальный снимок и разрывы
+		// восстанавливает OrderBookSync по REST level2_100; level2Depth5/20 - снимки без последовательности
+		orderbookTopic := "/market/level2:" + kucoinPair
+		tickerTopic := "/spotMarket/level1:" + kucoinPair
 
 		subMsg := map[string]interface{}{
 			"id":       fmt.Sprintf("sub-%s-%d", kucoinPair, depth),
@@ -108,7 +103,9 @@ type KucoinAdapter struct {
 	logger         *log.Logger
 	parser         *parsers.KucoinParser
 	messageBus     *bus.MessageBus
-	pairIDMap      map[string]int // symbol -> pairID маппинг
+	bookSync       *OrderBookSync  // контроль последовательности обновлений стакана
+	pairIDMap      map[string]int  // symbol -> pairID маппинг
+	userData       *userDataStream // приватный поток ордеров и балансов
 }
 
 // UnsubscribeMarkets реализует отписку от пар для Kucoin
@@ -120,16 +117,9 @@ func (a *KucoinAdapter) UnsubscribeMarkets(pairs []string, marketType string, de
 		// Конвертируем символ из формата "ERG/USDT" в "ERG-USDT" для KuCoin
 		kucoinPair := strings.Replace(pair, "/", "-", -1)
 		if strings.ToLower(marketType) == "spot" {
-			// Используем правильные топики KuCoin для спот данных
-			var orderbookTopic, tickerTopic string
-			if depth == 5 {
-				orderbookTopic = "/spotMarket/level2Depth5:" + kucoinPair
-			} else if depth == 20 {
-				orderbookTopic = "/spotMarket/level2Depth20:" + kucoinPair
-			} else {
-				orderbookTopic = "/spotMarket/level2Depth5:" + kucoinPair
-			}
-			tickerTopic = "/spotMarket/level1:" + kucoinPair
+			// Diff поток стакана и best price
+			orderbookTopic := "/market/level2:" + kucoinPair
+			tickerTopic := "/spotMarket/level1:" + kucoinPair
 
 			unsubMsg := map[string]interface{}{
 				"id":       fmt.Sprintf("unsub-%s-%d", kucoinPair, depth),
@@ -164,6 +154,34 @@ func (a *KucoinAdapter) UnsubscribeMarkets(pairs []string, marketType string, de
 			if err := a.ws.WriteMessage(1, tickerData); err != nil {
 				return fmt.Errorf("KucoinAdapter: ws ticker unsub: %w", err)
 			}
+			// Сделки
+			matchMsg := map[string]interface{}{
+				"id":       fmt.Sprintf("unsub-match-%s", kucoinPair),
+				"type":     "unsubscribe",
+				"topic":    "/market/match:" + kucoinPair,
+				"response": true,
+			}
+			matchData, err := json.Marshal(matchMsg)
+			if err != nil {
+				return fmt.Errorf("KucoinAdapter: marshal match unsub: %w", err)
+			}
+			if err := a.ws.WriteMessage(1, matchData); err != nil {
+				return fmt.Errorf("KucoinAdapter: ws match unsub: %w", err)
+			}
+			// Свечи
+			candlesMsg := map[string]interface{}{
+				"id":       fmt.Sprintf("unsub-candles-%s", kucoinPair),
+				"type":     "unsubscribe",
+				"topic":    "/market/candles:" + kucoinPair + "_1min",
+				"response": true,
+			}
+			candlesData, err := json.Marshal(candlesMsg)
+			if err != nil {
+				return fmt.Errorf("KucoinAdapter: marshal candles unsub: %w", err)
+			}
+			if err := a.ws.WriteMessage(1, candlesData); err != nil {
+				return fmt.Errorf("KucoinAdapter: ws candles unsub: %w", err)
+			}
 		} else {
 			return fmt.Errorf("KucoinAdapter: marketType %s not supported", marketType)
 		}
@@ -198,17 +216,9 @@ func (a *KucoinAdapter) SubscribeMarkets(pairs []string, marketType string, dept
 			kucoinPair := strings.Replace(pair, "/", "-", -1)
 			a.logger.Debug("[KUCOIN_ADAPTER] Converting symbol %s to KuCoin format: %s", pair, kucoinPair)
 
-			// Используем правильные топики KuCoin для спот данных
-			var orderbookTopic, tickerTopic string
-			if depth == 5 {
-				orderbookTopic = "/spotMarket/level2Depth5:" + kucoinPair
-			} else if depth == 20 {
-				orderbookTopic = "/spotMarket/level2Depth20:" + kucoinPair
-			} else {
-				// По умолчанию depth 5
-				orderbookTopic = "/spotMarket/level2Depth5:" + kucoinPair
-			}
-			tickerTopic = "/spotMarket/level1:" + kucoinPair
+			// Diff поток стакана и best price
+			orderbookTopic := "/market/level2:" + kucoinPair
+			tickerTopic := "/spotMarket/level1:" + kucoinPair
 
 			a.logger.Debug("[KUCOIN_ADAPTER] Orderbook topic for %s: %s", pair, orderbookTopic)
 			subMsg := map[string]interface{}{
@@ -252,6 +262,48 @@ func (a *KucoinAdapter) SubscribeMarkets(pairs []string, marketType string, dept
 				return fmt.Errorf("KucoinAdapter: ws ticker sub: %w", err)
 			}
 			a.logger.Debug("[KUCOIN_ADAPTER] Ticker subscription sent successfully for pair %s", pair)
+
+			// Сделки (публичная лента)
+			matchMsg := map[string]interface{}{
+				"id":       fmt.Sprintf("sub-match-%s", kucoinPair),
+				"type":     "subscribe",
+				"topic":    "/market/match:" + kucoinPair,
+				"response": true,
+			}
+			matchData, err := json.Marshal(matchMsg)
+			if err != nil {
+				a.logger.Error("[KUCOIN_ADAPTER] Failed to marshal match subscription: %v", err)
+				return fmt.Errorf("KucoinAdapter: marshal match sub: %w", err)
+			}
+			if getOrderBookConfig().OrderBook.DebugLogRaw {
+				a.logger.Debug("[KUCOIN_ADAPTER] SENDING match subscribe to KuCoin: %s", string(matchData))
+			}
+			if err := a.ws.WriteMessage(1, matchData); err != nil {
+				a.logger.Error("[KUCOIN_ADAPTER] Failed to send match subscription: %v", err)
+				return fmt.Errorf("KucoinAdapter: ws match sub: %w", err)
+			}
+			a.logger.Debug("[KUCOIN_ADAPTER] Match subscription sent successfully for pair %s", pair)
+
+			// Минутные свечи
+			candlesMsg := map[string]interface{}{
+				"id":       fmt.Sprintf("sub-candles-%s", kucoinPair),
+				"type":     "subscribe",
+				"topic":    "/market/candles:" + kucoinPair + "_1min",
+				"response": true,
+			}
+			candlesData, err := json.Marshal(candlesMsg)
+			if err != nil {
+				a.logger.Error("[KUCOIN_ADAPTER] Failed to marshal candles subscription: %v", err)
+				return fmt.Errorf("KucoinAdapter: marshal candles sub: %w", err)
+			}
+			if getOrderBookConfig().OrderBook.DebugLogRaw {
+				a.logger.Debug("[KUCOIN_ADAPTER] SENDING candles subscribe to KuCoin: %s", string(candlesData))
+			}
+			if err := a.ws.WriteMessage(1, candlesData); err != nil {
+				a.logger.Error("[KUCOIN_ADAPTER] Failed to send candles subscription: %v", err)
+				return fmt.Errorf("KucoinAdapter: ws candles sub: %w", err)
+			}
+			a.logger.Debug("[KUCOIN_ADAPTER] Candles subscription sent successfully for pair %s", pair)
 		} else {
 			// TODO: добавить поддержку futures, если появится у Kucoin
 			a.logger.Error("[KUCOIN_ADAPTER] Unsupported market type: %s", marketType)
@@ -264,7 +316,7 @@ func (a *KucoinAdapter) SubscribeMarkets(pairs []string, marketType string, dept
 
 // NewKucoinAdapter создает KucoinAdapter на основе данных из db.Exchange
 func NewKucoinAdapter(ex db.Exchange) *KucoinAdapter {
-	return &KucoinAdapter{
+	a := &KucoinAdapter{
 		exchange:   ex,
 		rest:       NewCexRestClient(ex.BaseUrl),
 		ws:         nil, // ws будет инициализирован динамически
@@ -274,6 +326,10 @@ func NewKucoinAdapter(ex db.Exchange) *KucoinAdapter {
 		messageBus: bus.GetInstance(),
 		pairIDMap:  make(map[string]int),
 	}
+	a.bookSync = NewOrderBookSync("kucoin", a.fetchOrderBookSnapshot, func(msg market.UnifiedMessage) {
+		a.messageBus.Publish("kucoin", msg)
+	})
+	return a
 }
 
 // getWsUrlAndTokenKucoin — получает WS URL и токен через REST
@@ -403,7 +459,7 @@ func (a *KucoinAdapter) readLoopWithReconnect() {
 			return
 		}
 
-		_, message, err := a.ws.ReadMessage()
+		msgType, message, err := a.ws.ReadMessage()
 		if err != nil {
 			a.logger.Error("[KUCOIN_ADAPTER] Read error: %v, reconnecting...", err)
 			for {
@@ -414,6 +470,7 @@ func (a *KucoinAdapter) readLoopWithReconnect() {
 				time.Sleep(3 * time.Second)
 				if err := a.ws.Reconnect(); err == nil {
 					a.logger.Info("[KUCOIN_ADAPTER] Reconnected, resubscribing...")
+					a.bookSync.Reset()
 					if err := a.SubscribeMarkets(a.lastPairs, a.lastMarketType, a.lastDepth); err != nil {
 						a.logger.Error("[KUCOIN_ADAPTER] Resubscribe error: %v", err)
 					} else {
@@ -426,6 +483,7 @@ func (a *KucoinAdapter) readLoopWithReconnect() {
 			}
 			continue
 		}
+		capture.Record("kucoin", msgType, message)
 
 		// Парсим WebSocket сообщение
 		if len(message) > 0 {
@@ -456,7 +514,11 @@ func (a *KucoinAdapter) readLoopWithReconnect() {
 						a.logger.Debug("[KUCOIN_ADAPTER] Publishing unified message: %+v", msg)
 					}
 				}
-				a.messageBus.Publish("kucoin", msg)
+				if msg.MessageType == market.MessageTypeOrderBook {
+					a.bookSync.Process(msg)
+				} else {
+					a.messageBus.Publish("kucoin", msg)
+				}
 				a.logger.Debug("[KUCOIN_ADAPTER] Message published to message bus")
 			} else {
 				a.logger.Debug("[KUCOIN_ADAPTER] Parsed message is nil (probably ack/pong)")
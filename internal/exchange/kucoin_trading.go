@@ -0,0 +1,229 @@
+package exchange
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"daemon-go/internal/market"
+)
+
+// kucoinResponse - общий конверт ответа KuCoin
+type kucoinResponse struct {
+	Code string          `json:"code"`
+	Msg  string          `json:"msg"`
+	Data json.RawMessage `json:"data"`
+}
+
+// kucoinOrder - ордер KuCoin (/api/v1/orders/{orderId})
+type kucoinOrder struct {
+	ID          string `json:"id"`
+	ClientOid   string `json:"clientOid"`
+	Symbol      string `json:"symbol"`
+	Type        string `json:"type"`
+	Side        string `json:"side"`
+	Price       string `json:"price"`
+	Size        string `json:"size"`
+	DealSize    string `json:"dealSize"`
+	DealFunds   string `json:"dealFunds"`
+	Fee         string `json:"fee"`
+	FeeCurrency string `json:"feeCurrency"`
+	IsActive    bool   `json:"isActive"`
+	CancelExist bool   `json:"cancelExist"`
+	CreatedAt   int64  `json:"createdAt"`
+}
+
+// signedRequest выполняет подписанный запрос к KuCoin (ключи API v2):
+// sign = base64(HMAC-SHA256(timestamp + method + endpoint + body)), passphrase тоже подписывается
+func (a *KucoinAdapter) signedRequest(method, path string, params url.Values, payload interface{}, result interface{}) error {
+	if err := checkCredentials("KucoinAdapter", a.exchange.ApiKey, a.exchange.ApiSecret); err != nil {
+		return err
+	}
+
+	endpoint := path
+	if len(params) > 0 {
+		endpoint += "?" + params.Encode()
+	}
+
+	var body []byte
+	if payload != nil {
+		var err error
+		body, err = json.Marshal(payload)
+		if err != nil {
+			return fmt.Errorf("KucoinAdapter: marshal: %w", err)
+		}
+	}
+
+	timestamp := nowMillis()
+	headers := map[string]string{
+		"KC-API-KEY":         a.exchange.ApiKey,
+		"KC-API-SIGN":        signBase64(a.exchange.ApiSecret, kucoinSignPayload(timestamp, method, endpoint, string(body))),
+		"KC-API-TIMESTAMP":   timestamp,
+		"KC-API-PASSPHRASE":  signBase64(a.exchange.ApiSecret, a.exchange.Passphrase),
+		"KC-API-KEY-VERSION": "2",
+	}
+
+	respBody, status, err := a.rest.DoSigned(method, endpoint, body, headers)
+	if err != nil {
+		return fmt.Errorf("KucoinAdapter: %s %s: %w", method, path, err)
+	}
+
+	var resp kucoinResponse
+	if err := json.Unmarshal(respBody, &resp); err != nil {
+		return fmt.Errorf("KucoinAdapter: %s %s: status %d: decode: %w", method, path, status, err)
+	}
+	if status != http.StatusOK || resp.Code != "200000" {
+		return fmt.Errorf("KucoinAdapter: %s %s: status %d, code %s: %s", method, path, status, resp.Code, resp.Msg)
+	}
+	if result == nil {
+		return nil
+	}
+	return json.Unmarshal(resp.Data, result)
+}
+
+// kucoinSignPayload - строка подписи KuCoin: timestamp + метод + путь с query + тело
+func kucoinSignPayload(timestamp, method, endpoint, body string) string {
+	return timestamp + method + endpoint + body
+}
+
+// PlaceOrder размещает лимитный или рыночный ордер
+func (a *KucoinAdapter) PlaceOrder(req market.OrderRequest) (*market.Order, error) {
+	symbol, err := exchangeSymbol(req.Symbol, "-")
+	if err != nil {
+		return nil, fmt.Errorf("KucoinAdapter: %w", err)
+	}
+
+	clientOid := req.ClientOrderID
+	if clientOid == "" {
+		clientOid = nowMillis()
+	}
+
+	payload := map[string]string{
+		"clientOid": clientOid,
+		"symbol":    symbol,
+		"side":      string(req.Side),
+		"size":      formatFloat(req.Volume),
+	}
+	switch req.OrderType {
+	case market.OrderTypeMarket:
+		payload["type"] = "market"
+	default:
+		payload["type"] = "limit"
+		payload["price"] = formatFloat(req.Price)
+		payload["timeInForce"] = "GTC"
+	}
+
+	var resp struct {
+		OrderID string `json:"orderId"`
+	}
+	if err := a.signedRequest(http.MethodPost, "/api/v1/orders", nil, payload, &resp); err != nil {
+		return nil, err
+	}
+	a.logger.Info("[KUCOIN_ADAPTER] Order placed: %s %s %s %s id=%s",
+		symbol, payload["side"], payload["type"], payload["size"], resp.OrderID)
+
+	return &market.Order{
+		Exchange:      "kucoin",
+		Symbol:        req.Symbol,
+		OrderID:       resp.OrderID,
+		ClientOrderID: clientOid,
+		Status:        market.OrderStatusNew,
+		Side:          req.Side,
+		OrderType:     req.OrderType,
+		Price:         req.Price,
+		Volume:        req.Volume,
+	}, nil
+}
+
+// CancelOrder отменяет ордер
+func (a *KucoinAdapter) CancelOrder(symbol, orderID string) error {
+	return a.signedRequest(http.MethodDelete, "/api/v1/orders/"+orderID, nil, nil, nil)
+}
+
+// GetOrder возвращает состояние ордера
+func (a *KucoinAdapter) GetOrder(symbol, orderID string) (*market.Order, error) {
+	var resp kucoinOrder
+	if err := a.signedRequest(http.MethodGet, "/api/v1/orders/"+orderID, nil, nil, &resp); err != nil {
+		return nil, err
+	}
+	return convertKucoinOrder(resp, symbol), nil
+}
+
+// GetOpenOrders возвращает активные ордера
+func (a *KucoinAdapter) GetOpenOrders(symbol string) ([]market.Order, error) {
+	params := url.Values{}
+	params.Set("status", "active")
+	if symbol != "" {
+		exSymbol, err := exchangeSymbol(symbol, "-")
+		if err != nil {
+			return nil, fmt.Errorf("KucoinAdapter: %w", err)
+		}
+		params.Set("symbol", exSymbol)
+	}
+
+	var resp struct {
+		Items []kucoinOrder `json:"items"`
+	}
+	if err := a.signedRequest(http.MethodGet, "/api/v1/orders", params, nil, &resp); err != nil {
+		return nil, err
+	}
+
+	orders := make([]market.Order, 0, len(resp.Items))
+	for _, o := range resp.Items {
+		orders = append(orders, *convertKucoinOrder(o, unifySymbol(o.Symbol)))
+	}
+	return orders, nil
+}
+
+// GetBalances возвращает балансы торгового аккаунта
+func (a *KucoinAdapter) GetBalances() ([]market.Balance, error) {
+	params := url.Values{}
+	params.Set("type", "trade")
+
+	var resp []struct {
+		Currency  string `json:"currency"`
+		Balance   string `json:"balance"`
+		Available string `json:"available"`
+		Holds     string `json:"holds"`
+	}
+	if err := a.signedRequest(http.MethodGet, "/api/v1/accounts", params, nil, &resp); err != nil {
+		return nil, err
+	}
+
+	balances := make([]market.Balance, 0, len(resp))
+	for _, b := range resp {
+		balance := market.Balance{
+			Asset:  b.Currency,
+			Free:   parseFloatString(b.Available),
+			Locked: parseFloatString(b.Holds),
+		}
+		if balance.Total() > 0 {
+			balances = append(balances, balance)
+		}
+	}
+	return balances, nil
+}
+
+// convertKucoinOrder переводит ордер KuCoin в market.Order
+func convertKucoinOrder(o kucoinOrder, symbol string) *market.Order {
+	volume := parseFloatString(o.Size)
+	filled := parseFloatString(o.DealSize)
+	return &market.Order{
+		Exchange:      "kucoin",
+		Symbol:        symbol,
+		OrderID:       o.ID,
+		ClientOrderID: o.ClientOid,
+		Status:        resolveFillStatus(o.IsActive, o.CancelExist, filled, volume),
+		Side:          market.TradeSide(strings.ToLower(o.Side)),
+		OrderType:     market.OrderType(strings.ToLower(o.Type)),
+		Price:         parseFloatString(o.Price),
+		Volume:        volume,
+		FilledVolume:  filled,
+		AvgPrice:      avgPrice(parseFloatString(o.DealFunds), filled),
+		Fee:           parseFloatString(o.Fee),
+		FeeCurrency:   o.FeeCurrency,
+		CreatedAt:     millisToTime(o.CreatedAt),
+	}
+}
@@ -0,0 +1,194 @@
+package exchange
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"daemon-go/internal/market"
+)
+
+// kucoinPrivateMessage - сообщение приватного канала KuCoin
+type kucoinPrivateMessage struct {
+	Type    string          `json:"type"`
+	Topic   string          `json:"topic"`
+	Subject string          `json:"subject"`
+	Code    int             `json:"code"`
+	Data    json.RawMessage `json:"data"`
+}
+
+// kucoinOrderChange - событие /spotMarket/tradeOrdersV2
+type kucoinOrderChange struct {
+	Symbol     string `json:"symbol"`
+	OrderType  string `json:"orderType"`
+	Side       string `json:"side"`
+	OrderID    string `json:"orderId"`
+	Type       string `json:"type"` // received, open, match, filled, canceled, update
+	Size       string `json:"size"`
+	FilledSize string `json:"filledSize"`
+	Price      string `json:"price"`
+	ClientOid  string `json:"clientOid"`
+	RemainSize string `json:"remainSize"`
+	MatchPrice string `json:"matchPrice"`
+	MatchSize  string `json:"matchSize"`
+	TradeID    string `json:"tradeId"`
+	Ts         int64  `json:"ts"` // наносекунды
+}
+
+// kucoinBalanceChange - событие /account/balance
+type kucoinBalanceChange struct {
+	Currency  string `json:"currency"`
+	Available string `json:"available"`
+	Hold      string `json:"hold"`
+	Time      string `json:"time"`
+}
+
+// StartUserData подключается к приватному каналу KuCoin (токен bullet-private)
+// и подписывается на изменения ордеров и балансов
+func (a *KucoinAdapter) StartUserData() error {
+	if err := checkCredentials("KucoinAdapter", a.exchange.ApiKey, a.exchange.ApiSecret); err != nil {
+		return err
+	}
+	if a.userData == nil {
+		stream := newUserDataStream("kucoin")
+		stream.dial = a.privateWsURL
+		stream.login = kucoinUserDataLogin
+		stream.handle = func(_ *CexWsClient, _ int, data []byte) ([]market.UnifiedMessage, error) {
+			return parseKucoinUserData(data)
+		}
+		stream.ping = func() []byte {
+			return []byte(fmt.Sprintf(`{"id":"%s","type":"ping"}`, nowMillis()))
+		}
+		stream.pingInterval = 18 * time.Second
+		a.userData = stream
+	}
+	return a.userData.start()
+}
+
+// StopUserData останавливает приватный поток
+func (a *KucoinAdapter) StopUserData() error {
+	if a.userData == nil {
+		return nil
+	}
+	return a.userData.stopStream()
+}
+
+// privateWsURL получает токен приватного канала и возвращает URL подключения
+func (a *KucoinAdapter) privateWsURL() (string, error) {
+	var resp struct {
+		Token           string `json:"token"`
+		InstanceServers []struct {
+			Endpoint string `json:"endpoint"`
+		} `json:"instanceServers"`
+	}
+	if err := a.signedRequest(http.MethodPost, "/api/v1/bullet-private", nil, nil, &resp); err != nil {
+		return "", err
+	}
+	if resp.Token == "" || len(resp.InstanceServers) == 0 {
+		return "", fmt.Errorf("KucoinAdapter: bullet-private returned no token or servers")
+	}
+	return fmt.Sprintf("%s?token=%s&connectId=%s", resp.InstanceServers[0].Endpoint, resp.Token, nowMillis()), nil
+}
+
+// kucoinUserDataLogin дожидается welcome и подписывается на приватные topic
+func kucoinUserDataLogin(ws *CexWsClient) error {
+	_, data, err := ws.ReadMessage()
+	if err != nil {
+		return err
+	}
+	var welcome kucoinPrivateMessage
+	if err := json.Unmarshal(data, &welcome); err != nil || welcome.Type != "welcome" {
+		return fmt.Errorf("unexpected first message: %s", string(data))
+	}
+
+	for i, topic := range []string{"/spotMarket/tradeOrdersV2", "/account/balance"} {
+		sub := map[string]interface{}{
+			"id":             fmt.Sprintf("%s%d", nowMillis(), i),
+			"type":           "subscribe",
+			"topic":          topic,
+			"privateChannel": true,
+			"response":       true,
+		}
+		if err := writeJSON(ws, sub); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// parseKucoinUserData разбирает сообщение приватного канала KuCoin
+func parseKucoinUserData(data []byte) ([]market.UnifiedMessage, error) {
+	var msg kucoinPrivateMessage
+	if err := json.Unmarshal(data, &msg); err != nil {
+		return nil, fmt.Errorf("kucoin user data: %w", err)
+	}
+	if msg.Type == "error" {
+		return nil, fmt.Errorf("kucoin user data: error %d: %s", msg.Code, string(msg.Data))
+	}
+	if msg.Type != "message" {
+		return nil, nil
+	}
+
+	switch msg.Topic {
+	case "/spotMarket/tradeOrdersV2":
+		var o kucoinOrderChange
+		if err := json.Unmarshal(msg.Data, &o); err != nil {
+			return nil, fmt.Errorf("kucoin order change: %w", err)
+		}
+		return []market.UnifiedMessage{orderEventMessage(convertKucoinOrderChange(o))}, nil
+
+	case "/account/balance":
+		var b kucoinBalanceChange
+		if err := json.Unmarshal(msg.Data, &b); err != nil {
+			return nil, fmt.Errorf("kucoin balance change: %w", err)
+		}
+		ms, _ := strconv.ParseInt(b.Time, 10, 64)
+		balances := []market.Balance{{
+			Asset:  b.Currency,
+			Free:   parseFloatString(b.Available),
+			Locked: parseFloatString(b.Hold),
+		}}
+		return []market.UnifiedMessage{balanceMessage(millisToTime(ms), balances, b)}, nil
+	}
+	return nil, nil
+}
+
+// convertKucoinOrderChange переводит событие ордера KuCoin в UnifiedOrderEvent.
+// KuCoin не присылает статус явно, он выводится из типа события и объемов
+func convertKucoinOrderChange(o kucoinOrderChange) market.UnifiedOrderEvent {
+	volume := parseFloatString(o.Size)
+	filled := parseFloatString(o.FilledSize)
+	active := o.Type != "filled" && o.Type != "canceled"
+	if o.Type == "match" && parseFloatString(o.RemainSize) == 0 {
+		active = false
+	}
+	status := resolveFillStatus(active, o.Type == "canceled", filled, volume)
+	if o.Type == "filled" {
+		// рыночный ордер на сумму приходит без size
+		status = market.OrderStatusFilled
+	}
+
+	event := market.UnifiedOrderEvent{
+		Symbol:          unifySymbol(o.Symbol),
+		Timestamp:       millisToTime(o.Ts / int64(time.Millisecond)),
+		OrderID:         o.OrderID,
+		ClientOrderID:   o.ClientOid,
+		Status:          status,
+		Side:            market.TradeSide(strings.ToLower(o.Side)),
+		OrderType:       market.OrderType(strings.ToLower(o.OrderType)),
+		Price:           parseFloatString(o.Price),
+		Volume:          volume,
+		FilledVolume:    filled,
+		RemainingVolume: parseFloatString(o.RemainSize),
+		Raw:             o,
+	}
+	if o.Type == "match" {
+		event.TradeID = o.TradeID
+		event.LastFillPrice = parseFloatString(o.MatchPrice)
+		event.LastFillVolume = parseFloatString(o.MatchSize)
+	}
+	return event
+}
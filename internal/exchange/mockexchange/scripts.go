@@ -0,0 +1,153 @@
+package mockexchange
+
+import (
+	"fmt"
+	"time"
+
+	"daemon-go/internal/market"
+)
+
+// frameDelay - пауза между кадрами сценария по умолчанию
+const frameDelay = 100 * time.Millisecond
+
+// mockTs - фиксированное время кадров сценария по умолчанию (мс)
+const mockTs = 1700000000000
+
+// DefaultPair возвращает пару в формате, который адаптер биржи принимает в SubscribeMarkets
+func DefaultPair(protocol Protocol) string {
+	switch protocol {
+	case ProtocolHtx:
+		return "btcusdt"
+	case ProtocolKucoin, ProtocolPoloniex:
+		return "BTC/USDT"
+	default:
+		return "BTCUSDT"
+	}
+}
+
+// DefaultScript возвращает сценарий по умолчанию: REST ping и снимок стакана,
+// после подписки - снимок BTC/USDT, одно инкрементальное обновление (где протокол их различает)
+// и одна публичная сделка; у бирж со свечным потоком - еще текущая минутная свеча
+func DefaultScript(protocol Protocol) Script {
+	switch protocol {
+	case ProtocolBinance:
+		return Script{
+			REST: map[string]string{
+				"/api/v3/ping":  `{}`,
+				"/api/v3/depth": `{"lastUpdateId":100,"bids":[["50000.00","1.5"],["49999.00","2.0"]],"asks":[["50001.00","1.0"],["50002.00","3.0"]]}`,
+			},
+			OnSubscribe: []Frame{
+				{Delay: frameDelay, Data: `{"stream":"btcusdt@depth@100ms","data":{"e":"depthUpdate","E":1700000000000,"s":"BTCUSDT","U":99,"u":100,"b":[["50000.00","1.5"]],"a":[["50001.00","1.0"]]}}`},
+				{Delay: frameDelay, Data: `{"stream":"btcusdt@depth@100ms","data":{"e":"depthUpdate","E":1700000000100,"s":"BTCUSDT","U":101,"u":101,"b":[["50000.00","1.2"]],"a":[["50001.00","0"]]}}`},
+				{Delay: frameDelay, Data: `{"stream":"btcusdt@bookTicker","data":{"u":101,"s":"BTCUSDT","b":"50000.00","B":"1.2","a":"50002.00","A":"3.0"}}`},
+				{Delay: frameDelay, Data: `{"stream":"btcusdt@aggTrade","data":{"e":"aggTrade","E":1700000000200,"s":"BTCUSDT","a":500,"p":"50001.00","q":"0.25","T":1700000000200,"m":false}}`},
+				{Delay: frameDelay, Data: `{"stream":"btcusdt@kline_1m","data":{"e":"kline","E":1700000000300,"s":"BTCUSDT","k":{"t":1699999980000,"T":1700000039999,"s":"BTCUSDT","i":"1m","o":"50000.00","c":"50001.00","h":"50002.00","l":"49999.00","v":"1.25","n":12,"x":false,"q":"62501.25"}}}`},
+			},
+		}
+
+	case ProtocolBybit:
+		return Script{
+			REST: map[string]string{
+				"/v5/market/time":      `{"retCode":0,"retMsg":"OK","result":{"timeSecond":"1700000000","timeNano":"1700000000000000000"}}`,
+				"/v5/market/orderbook": `{"retCode":0,"retMsg":"OK","result":{"s":"BTCUSDT","b":[["50000.00","1.5"]],"a":[["50001.00","1.0"]],"ts":1700000000000,"u":1}}`,
+			},
+			OnSubscribe: []Frame{
+				{Delay: frameDelay, Data: `{"topic":"orderbook.5.BTCUSDT","type":"snapshot","ts":1700000000000,"data":{"s":"BTCUSDT","b":[["50000.00","1.5"],["49999.00","2.0"]],"a":[["50001.00","1.0"],["50002.00","3.0"]],"u":1,"seq":1000}}`},
+				{Delay: frameDelay, Data: `{"topic":"orderbook.5.BTCUSDT","type":"delta","ts":1700000000100,"data":{"s":"BTCUSDT","b":[["50000.00","1.2"]],"a":[["50001.00","0"]],"u":2,"seq":1001}}`},
+				{Delay: frameDelay, Data: `{"topic":"publicTrade.BTCUSDT","type":"snapshot","ts":1700000000200,"data":[{"T":1700000000200,"s":"BTCUSDT","S":"Buy","v":"0.25","p":"50001.00","i":"mock-trade-1","BT":false}]}`},
+				{Delay: frameDelay, Data: `{"topic":"kline.1.BTCUSDT","type":"snapshot","ts":1700000000300,"data":[{"start":1699999980000,"end":1700000039999,"interval":"1","open":"50000.00","close":"50001.00","high":"50002.00","low":"49999.00","volume":"1.25","turnover":"62501.25","confirm":false,"timestamp":1700000000300}]}`},
+			},
+		}
+
+	case ProtocolKucoin:
+		return Script{
+			REST: map[string]string{
+				"/timestamp":                          `{"code":"200000","data":1700000000000}`,
+				"/api/v1/timestamp":                   `{"code":"200000","data":1700000000000}`,
+				"/api/v1/market/orderbook/level2_100": `{"code":"200000","data":{"sequence":"100","time":1700000000000,"bids":[["50000.0","1.5"]],"asks":[["50001.0","1.0"]]}}`,
+			},
+			OnConnect: []Frame{
+				{Data: `{"id":"mock-welcome","type":"welcome"}`},
+			},
+			OnSubscribe: []Frame{
+				{Delay: frameDelay, Data: `{"type":"message","topic":"/market/level2:BTC-USDT","subject":"trade.l2update","data":{"changes":{"asks":[["50002.0","3.0","101"]],"bids":[["49999.0","2.0","101"]]},"sequenceStart":101,"sequenceEnd":101,"symbol":"BTC-USDT","time":1700000000000}}`},
+				{Delay: frameDelay, Data: `{"type":"message","topic":"/market/level2:BTC-USDT","subject":"trade.l2update","data":{"changes":{"asks":[["50001.0","0","102"]],"bids":[["50000.0","1.2","102"]]},"sequenceStart":102,"sequenceEnd":102,"symbol":"BTC-USDT","time":1700000000100}}`},
+				{Delay: frameDelay, Data: `{"type":"message","topic":"/spotMarket/level1:BTC-USDT","subject":"trade.ticker","data":{"sequence":"100","price":"50000.5","size":"0.1","bestBid":"50000.0","bestBidSize":"1.5","bestAsk":"50001.0","bestAskSize":"1.0","time":1700000000000}}`},
+				{Delay: frameDelay, Data: `{"type":"message","topic":"/market/match:BTC-USDT","subject":"trade.l3match","data":{"symbol":"BTC-USDT","sequence":"101","side":"buy","size":"0.25","price":"50001.0","takerOrderId":"mock-taker","makerOrderId":"mock-maker","tradeId":"mock-trade-1","time":"1700000000200000000"}}`},
+				{Delay: frameDelay, Data: `{"type":"message","topic":"/market/candles:BTC-USDT_1min","subject":"trade.candles.update","data":{"symbol":"BTC-USDT","candles":["1699999980","50000.0","50001.0","50002.0","49999.0","1.25","62501.25"],"time":1700000000300000000}}`},
+			},
+		}
+
+	case ProtocolHtx:
+		return Script{
+			REST: map[string]string{
+				"/v1/common/timestamp": `{"status":"ok","data":1700000000000}`,
+			},
+			OnRequest: map[string][]Frame{
+				"market.btcusdt.mbp.150": {
+					{Data: `{"id":"snapshot-btcusdt","rep":"market.btcusdt.mbp.150","status":"ok","ts":1700000000000,"data":{"seqNum":100,"bids":[[50000.0,1.5]],"asks":[[50001.0,1.0]]}}`},
+				},
+			},
+			OnSubscribe: []Frame{
+				{Delay: frameDelay, Data: `{"ch":"market.btcusdt.mbp.150","ts":1700000000000,"tick":{"seqNum":101,"prevSeqNum":100,"bids":[[49999.0,2.0]],"asks":[[50002.0,3.0]]}}`},
+				{Delay: frameDelay, Data: `{"ch":"market.btcusdt.mbp.150","ts":1700000000100,"tick":{"seqNum":102,"prevSeqNum":101,"bids":[[50000.0,1.2]],"asks":[[50001.0,0]]}}`},
+				{Delay: frameDelay, Data: `{"ch":"market.btcusdt.ticker","ts":1700000000100,"tick":{"open":49000.0,"high":51000.0,"low":48500.0,"close":50000.5,"amount":120.5,"vol":6025000.0,"count":1500,"bid":50000.0,"bidSize":1.5,"ask":50001.0,"askSize":1.0}}`},
+				{Delay: frameDelay, Data: `{"ch":"market.btcusdt.trade.detail","ts":1700000000200,"tick":{"id":1,"ts":1700000000200,"data":[{"id":1,"ts":1700000000200,"tradeId":500,"amount":0.25,"price":50001.0,"direction":"buy"}]}}`},
+				{Delay: frameDelay, Data: `{"ch":"market.btcusdt.kline.1min","ts":1700000000300,"tick":{"id":1699999980,"open":50000.0,"close":50001.0,"low":49999.0,"high":50002.0,"amount":1.25,"vol":62501.25,"count":12}}`},
+			},
+			PingInterval: 5 * time.Second,
+		}
+
+	case ProtocolCoinex:
+		return Script{
+			REST: map[string]string{
+				"/v1/market/list": `{"code":0,"data":["BTCUSDT"],"message":"OK"}`,
+			},
+			OnSubscribe: []Frame{
+				{Delay: frameDelay, Data: `{"method":"depth.update","params":[true,{"asks":[["50001.00","1.0"],["50002.00","3.0"]],"bids":[["50000.00","1.5"],["49999.00","2.0"]],"last":"50000.50","time":1700000000000},"BTCUSDT"],"id":null}`},
+				{Delay: frameDelay, Data: `{"method":"depth.update","params":[false,{"asks":[["50001.00","0"]],"bids":[["50000.00","1.2"]],"last":"50000.50","time":1700000000100},"BTCUSDT"],"id":null}`},
+				{Delay: frameDelay, Data: `{"method":"deals.update","params":["BTCUSDT",[{"id":500,"time":1700000000.2,"price":"50001.00","amount":"0.25","type":"buy"}]],"id":null}`},
+			},
+		}
+
+	case ProtocolPoloniex:
+		return poloniexScript()
+	}
+
+	return Script{}
+}
+
+// poloniexScript - сценарий Poloniex: book_lv2 снимок и diff с корректными контрольными суммами.
+// Цены идут с хвостовыми нулями: сумма считается по строкам биржи, а не по float
+func poloniexScript() Script {
+	bids := []market.PriceLevel{
+		{Price: 50000, Volume: 1.5, RawPrice: "50000.00", RawVolume: "1.50"},
+		{Price: 49999, Volume: 2, RawPrice: "49999.00", RawVolume: "2.00"},
+	}
+	asks := []market.PriceLevel{
+		{Price: 50001, Volume: 1, RawPrice: "50001.00", RawVolume: "1.00"},
+		{Price: 50002, Volume: 3, RawPrice: "50002.00", RawVolume: "3.00"},
+	}
+	checksum := market.InterleavedCRC32(25)
+	snapshotSum := checksum(bids, asks)
+
+	// diff: объем лучшего bid 1.20, лучший ask снят
+	bids[0].Volume, bids[0].RawVolume = 1.2, "1.20"
+	updateSum := checksum(bids, asks[1:])
+
+	return Script{
+		REST: map[string]string{
+			"/markets": `[{"symbol":"BTC_USDT","baseCurrencyName":"BTC","quoteCurrencyName":"USDT","state":"NORMAL"}]`,
+		},
+		OnSubscribe: []Frame{
+			{Delay: frameDelay, Data: fmt.Sprintf(`{"channel":"book_lv2","action":"snapshot","data":[{"symbol":"BTC_USDT","createTime":%d,"asks":[["50001.00","1.00"],["50002.00","3.00"]],"bids":[["50000.00","1.50"],["49999.00","2.00"]],"lastId":0,"id":1,"ts":%d,"checksum":%d}]}`,
+				mockTs, mockTs, snapshotSum)},
+			{Delay: frameDelay, Data: fmt.Sprintf(`{"channel":"book_lv2","action":"update","data":[{"symbol":"BTC_USDT","createTime":%d,"asks":[["50001.00","0.00"]],"bids":[["50000.00","1.20"]],"lastId":1,"id":2,"ts":%d,"checksum":%d}]}`,
+				mockTs+100, mockTs+100, updateSum)},
+			{Delay: frameDelay, Data: fmt.Sprintf(`{"channel":"trades","data":[{"symbol":"BTC_USDT","amount":"12500.25","takerSide":"buy","quantity":"0.25","createTime":%d,"price":"50001","id":"500","ts":%d}]}`,
+				mockTs+200, mockTs+200)},
+			{Delay: frameDelay, Data: fmt.Sprintf(`{"channel":"candles_minute_1","data":[{"symbol":"BTC_USDT","amount":"62501.25","high":"50002","quantity":"1.25","tradeCount":12,"low":"49999","closeTime":%d,"startTime":%d,"close":"50001","open":"50000","ts":%d}]}`,
+				mockTs+39999, mockTs-20000, mockTs+300)},
+		},
+	}
+}
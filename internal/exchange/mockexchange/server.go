@@ -0,0 +1,414 @@
+// Package mockexchange - локальные REST/WebSocket серверы, имитирующие протоколы бирж.
+// Позволяют прогнать Start, подписку, переподключение и парсинг адаптеров без доступа к сети
+package mockexchange
+
+import (
+	"bytes"
+	"compress/gzip"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"time"
+
+	"daemon-go/internal/db"
+	"daemon-go/pkg/log"
+
+	"github.com/gorilla/websocket"
+)
+
+// Protocol - протокол биржи, который имитирует сервер
+type Protocol string
+
+const (
+	ProtocolBinance  Protocol = "binance"
+	ProtocolBybit    Protocol = "bybit"
+	ProtocolKucoin   Protocol = "kucoin"
+	ProtocolHtx      Protocol = "htx"
+	ProtocolCoinex   Protocol = "coinex"
+	ProtocolPoloniex Protocol = "poloniex"
+)
+
+// Protocols - все поддерживаемые протоколы
+var Protocols = []Protocol{ProtocolBinance, ProtocolBybit, ProtocolKucoin, ProtocolHtx, ProtocolCoinex, ProtocolPoloniex}
+
+// Frame - сообщение сервера; Data - JSON (для HTX сжимается gzip при отправке)
+type Frame struct {
+	Delay time.Duration // пауза перед отправкой
+	Data  string
+}
+
+// Script - сценарий сервера
+type Script struct {
+	OnConnect    []Frame            // кадры сразу после подключения
+	OnSubscribe  []Frame            // кадры после первой подписки в соединении (повторяются после переподключения)
+	OnRequest    map[string][]Frame // ответы на запросы {"req": ...} по имени канала (HTX)
+	REST         map[string]string  // ответы REST по пути без query
+	PingInterval time.Duration      // период серверных ping (только HTX, 0 - не отправлять)
+}
+
+// mockConn - WebSocket соединение клиента
+type mockConn struct {
+	mu         sync.Mutex
+	conn       *websocket.Conn
+	subscribed bool
+}
+
+// Server - имитация биржи на httptest сервере: REST по Script.REST, WebSocket на /ws и /stream
+type Server struct {
+	protocol Protocol
+	script   Script
+	http     *httptest.Server
+	upgrader websocket.Upgrader
+	logger   *log.Logger
+
+	mu       sync.Mutex
+	rest     map[string]string
+	conns    map[*mockConn]struct{}
+	received [][]byte
+	connects int
+	pongs    int
+	closed   bool
+}
+
+// NewServer запускает сервер с указанным сценарием
+func NewServer(protocol Protocol, script Script) *Server {
+	s := &Server{
+		protocol: protocol,
+		script:   script,
+		logger:   log.New("mockexchange"),
+		rest:     make(map[string]string),
+		conns:    make(map[*mockConn]struct{}),
+	}
+	for path, body := range script.REST {
+		s.rest[path] = body
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/ws", s.handleWs)
+	mux.HandleFunc("/stream", s.handleWs)
+	mux.HandleFunc("/", s.handleREST)
+	s.http = httptest.NewServer(mux)
+
+	// KuCoin выдает адрес WebSocket через bullet-public, он известен только после запуска
+	if protocol == ProtocolKucoin {
+		s.SetREST("/api/v1/bullet-public", fmt.Sprintf(
+			`{"code":"200000","data":{"token":"mock-token","instanceServers":[{"endpoint":"%s","encrypt":false,"protocol":"websocket","pingInterval":18000,"pingTimeout":10000}]}}`,
+			s.WsURL()+"/ws"))
+	}
+
+	s.logger.Info("[MOCK_EXCHANGE] %s server started at %s", protocol, s.http.URL)
+	return s
+}
+
+// NewDefaultServer запускает сервер со сценарием по умолчанию для протокола
+func NewDefaultServer(protocol Protocol) *Server {
+	return NewServer(protocol, DefaultScript(protocol))
+}
+
+// URL возвращает базовый HTTP адрес сервера
+func (s *Server) URL() string {
+	return s.http.URL
+}
+
+// WsURL возвращает базовый WebSocket адрес сервера (без пути)
+func (s *Server) WsURL() string {
+	return "ws" + strings.TrimPrefix(s.http.URL, "http")
+}
+
+// Exchange возвращает описание биржи для exchange.NewAdapter, указывающее на сервер
+func (s *Server) Exchange() db.Exchange {
+	wsPath := "/ws"
+	if s.protocol == ProtocolBinance {
+		wsPath = "/stream"
+	}
+	wsURL := sql.NullString{String: s.WsURL() + wsPath, Valid: true}
+	return db.Exchange{
+		ID:           1,
+		Name:         string(s.protocol),
+		Active:       true,
+		Url:          s.http.URL,
+		BaseUrl:      s.http.URL,
+		WebsocketUrl: wsURL,
+		WsUrl:        wsURL,
+	}
+}
+
+// SetREST задает ответ REST для пути
+func (s *Server) SetREST(path, body string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.rest[path] = body
+}
+
+// Received возвращает копию всех сообщений, полученных от клиентов
+func (s *Server) Received() [][]byte {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	result := make([][]byte, len(s.received))
+	copy(result, s.received)
+	return result
+}
+
+// Connections возвращает количество WebSocket подключений с момента запуска
+func (s *Server) Connections() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.connects
+}
+
+// Pongs возвращает количество pong ответов клиента на серверные ping (HTX)
+func (s *Server) Pongs() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.pongs
+}
+
+// Send отправляет кадр во все открытые соединения
+func (s *Server) Send(data string) {
+	for _, c := range s.activeConns() {
+		if err := s.write(c, data); err != nil {
+			s.logger.Warn("[MOCK_EXCHANGE] %s send failed: %v", s.protocol, err)
+		}
+	}
+}
+
+// DropConnections разрывает все WebSocket соединения, чтобы проверить переподключение адаптера
+func (s *Server) DropConnections() {
+	for _, c := range s.activeConns() {
+		c.mu.Lock()
+		_ = c.conn.Close()
+		c.mu.Unlock()
+	}
+	s.logger.Info("[MOCK_EXCHANGE] %s connections dropped", s.protocol)
+}
+
+// WaitConnections ждет, пока число подключений достигнет n
+func (s *Server) WaitConnections(n int, timeout time.Duration) bool {
+	deadline := time.Now().Add(timeout)
+	for time.Now().Before(deadline) {
+		if s.Connections() >= n {
+			return true
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+	return false
+}
+
+// Close останавливает сервер и закрывает соединения
+func (s *Server) Close() {
+	s.mu.Lock()
+	s.closed = true
+	s.mu.Unlock()
+	s.DropConnections()
+	s.http.Close()
+}
+
+func (s *Server) activeConns() []*mockConn {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	conns := make([]*mockConn, 0, len(s.conns))
+	for c := range s.conns {
+		conns = append(conns, c)
+	}
+	return conns
+}
+
+func (s *Server) handleREST(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	body, ok := s.rest[r.URL.Path]
+	s.mu.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	if !ok {
+		s.logger.Debug("[MOCK_EXCHANGE] %s REST %s %s: not scripted", s.protocol, r.Method, r.URL.Path)
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte(`{"code":404,"msg":"not found"}`))
+		return
+	}
+	_, _ = w.Write([]byte(body))
+}
+
+func (s *Server) handleWs(w http.ResponseWriter, r *http.Request) {
+	conn, err := s.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		s.logger.Error("[MOCK_EXCHANGE] %s upgrade failed: %v", s.protocol, err)
+		return
+	}
+	c := &mockConn{conn: conn}
+
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		_ = conn.Close()
+		return
+	}
+	s.conns[c] = struct{}{}
+	s.connects++
+	s.mu.Unlock()
+
+	done := make(chan struct{})
+	defer func() {
+		close(done)
+		s.mu.Lock()
+		delete(s.conns, c)
+		s.mu.Unlock()
+		_ = conn.Close()
+	}()
+
+	go s.playFrames(c, s.script.OnConnect)
+	if s.protocol == ProtocolHtx && s.script.PingInterval > 0 {
+		go s.pingLoop(c, done)
+	}
+
+	for {
+		_, message, err := conn.ReadMessage()
+		if err != nil {
+			return
+		}
+		s.handleClientMessage(c, message)
+	}
+}
+
+// handleClientMessage отвечает на ping и подписки клиента
+func (s *Server) handleClientMessage(c *mockConn, message []byte) {
+	s.mu.Lock()
+	s.received = append(s.received, message)
+	s.mu.Unlock()
+
+	var req map[string]interface{}
+	if err := json.Unmarshal(message, &req); err != nil {
+		s.logger.Warn("[MOCK_EXCHANGE] %s invalid client message: %s", s.protocol, string(message))
+		return
+	}
+
+	if _, ok := req["pong"]; ok {
+		s.mu.Lock()
+		s.pongs++
+		s.mu.Unlock()
+		return
+	}
+	// Общий WriteLoop шлет server.ping всем биржам, отвечает на него только CoinEx
+	if req["method"] == "server.ping" {
+		if s.protocol == ProtocolCoinex {
+			_ = s.write(c, `{"error":null,"result":"pong","id":999}`)
+		}
+		return
+	}
+	if req["type"] == "ping" || req["op"] == "ping" || req["event"] == "ping" {
+		_ = s.write(c, pongFrame(s.protocol, req))
+		return
+	}
+
+	if channel, ok := req["req"].(string); ok {
+		if frames, ok := s.script.OnRequest[channel]; ok {
+			go s.playFrames(c, frames)
+		}
+		return
+	}
+
+	if ack := ackFrame(s.protocol, req); ack != "" {
+		_ = s.write(c, ack)
+	}
+
+	c.mu.Lock()
+	first := !c.subscribed
+	c.subscribed = true
+	c.mu.Unlock()
+	if first {
+		go s.playFrames(c, s.script.OnSubscribe)
+	}
+}
+
+func (s *Server) playFrames(c *mockConn, frames []Frame) {
+	for _, frame := range frames {
+		if frame.Delay > 0 {
+			time.Sleep(frame.Delay)
+		}
+		if err := s.write(c, frame.Data); err != nil {
+			return
+		}
+	}
+}
+
+func (s *Server) pingLoop(c *mockConn, done chan struct{}) {
+	ticker := time.NewTicker(s.script.PingInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+			if err := s.write(c, fmt.Sprintf(`{"ping":%d}`, time.Now().UnixMilli())); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// write отправляет кадр клиенту; HTX получает gzip в бинарных кадрах
+func (s *Server) write(c *mockConn, data string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if s.protocol != ProtocolHtx {
+		return c.conn.WriteMessage(websocket.TextMessage, []byte(data))
+	}
+
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write([]byte(data)); err != nil {
+		return fmt.Errorf("mockexchange: gzip: %w", err)
+	}
+	if err := zw.Close(); err != nil {
+		return fmt.Errorf("mockexchange: gzip: %w", err)
+	}
+	return c.conn.WriteMessage(websocket.BinaryMessage, buf.Bytes())
+}
+
+// ackFrame формирует подтверждение подписки в формате биржи
+func ackFrame(protocol Protocol, req map[string]interface{}) string {
+	switch protocol {
+	case ProtocolBinance:
+		return fmt.Sprintf(`{"result":null,"id":%v}`, jsonValue(req["id"]))
+	case ProtocolBybit:
+		return fmt.Sprintf(`{"success":true,"ret_msg":"","op":%s,"conn_id":"mock"}`, jsonValue(req["op"]))
+	case ProtocolKucoin:
+		return fmt.Sprintf(`{"id":%s,"type":"ack"}`, jsonValue(req["id"]))
+	case ProtocolHtx:
+		if sub, ok := req["sub"]; ok {
+			return fmt.Sprintf(`{"id":%s,"status":"ok","subbed":%s,"ts":%d}`,
+				jsonValue(req["id"]), jsonValue(sub), time.Now().UnixMilli())
+		}
+		return fmt.Sprintf(`{"id":%s,"status":"ok","unsubbed":%s,"ts":%d}`,
+			jsonValue(req["id"]), jsonValue(req["unsub"]), time.Now().UnixMilli())
+	case ProtocolCoinex:
+		return fmt.Sprintf(`{"error":null,"result":{"status":"success"},"id":%v}`, jsonValue(req["id"]))
+	default:
+		// Poloniex не подтверждает подписку отдельным кадром в сценарии по умолчанию
+		return ""
+	}
+}
+
+// pongFrame формирует ответ на клиентский ping
+func pongFrame(protocol Protocol, req map[string]interface{}) string {
+	switch protocol {
+	case ProtocolKucoin:
+		return fmt.Sprintf(`{"id":%s,"type":"pong"}`, jsonValue(req["id"]))
+	case ProtocolBybit:
+		return `{"success":true,"ret_msg":"pong","op":"ping"}`
+	default:
+		return `{"event":"pong"}`
+	}
+}
+
+func jsonValue(v interface{}) string {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return "null"
+	}
+	return string(data)
+}
@@ -0,0 +1,96 @@
+package exchange_test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"daemon-go/internal/bus"
+	"daemon-go/internal/exchange"
+	"daemon-go/internal/exchange/mockexchange"
+	"daemon-go/internal/market"
+)
+
+// TestAdaptersAgainstMockExchanges прогоняет адаптеры против имитаций бирж (как ctdaemon test-mock):
+// Start, подписка, стакан после diff и сделка, разрыв соединения и повторная подписка
+func TestAdaptersAgainstMockExchanges(t *testing.T) {
+	if testing.Short() {
+		t.Skip("mock exchange sessions take several seconds per exchange")
+	}
+	for _, protocol := range mockexchange.Protocols {
+		t.Run(string(protocol), func(t *testing.T) {
+			srv := mockexchange.NewDefaultServer(protocol)
+			defer srv.Close()
+
+			ex := srv.Exchange()
+			messageBus := bus.GetInstance()
+			messages := messageBus.Subscribe(ex.Name, 100)
+			defer messageBus.Unsubscribe(ex.Name, messages)
+
+			adapter := exchange.NewAdapter(ex)
+			if err := adapter.Start(); err != nil {
+				t.Fatalf("start: %v", err)
+			}
+			defer adapter.Stop()
+
+			if err := adapter.SubscribeMarkets([]string{mockexchange.DefaultPair(protocol)}, "spot", 5); err != nil {
+				t.Fatalf("subscribe: %v", err)
+			}
+			if err := waitScriptMessages(messages, 5*time.Second); err != nil {
+				t.Fatalf("before reconnect: %v", err)
+			}
+
+			srv.DropConnections()
+			if !srv.WaitConnections(2, 15*time.Second) {
+				t.Fatal("adapter did not reconnect")
+			}
+			if err := waitScriptMessages(messages, 10*time.Second); err != nil {
+				t.Fatalf("after reconnect: %v", err)
+			}
+		})
+	}
+}
+
+// waitScriptMessages ждет стакан с примененным diff сценария (лучший bid 50000 объемом 1.2)
+// и сделку сценария (0.25 по 50001)
+func waitScriptMessages(messages chan market.UnifiedMessage, timeout time.Duration) error {
+	deadline := time.After(timeout)
+	var book, trade bool
+	for !book || !trade {
+		select {
+		case msg := <-messages:
+			switch msg.MessageType {
+			case market.MessageTypeOrderBook:
+				if ob := orderBookData(msg.Data); ob != nil && len(ob.Bids) > 0 &&
+					ob.Bids[0].Price == 50000 && ob.Bids[0].Volume == 1.2 {
+					book = true
+				}
+			case market.MessageTypeTrade:
+				tr, ok := msg.Data.(market.UnifiedTrade)
+				if !ok {
+					return fmt.Errorf("trade message data %T", msg.Data)
+				}
+				if tr.Price != 50001 || tr.Volume != 0.25 {
+					return fmt.Errorf("trade %v@%v, want 0.25@50001", tr.Volume, tr.Price)
+				}
+				trade = true
+			}
+		case <-deadline:
+			if !book {
+				return fmt.Errorf("no order book with the scripted diff within %v", timeout)
+			}
+			return fmt.Errorf("no trade message within %v", timeout)
+		}
+	}
+	return nil
+}
+
+func orderBookData(data interface{}) *market.UnifiedOrderBook {
+	switch ob := data.(type) {
+	case *market.UnifiedOrderBook:
+		return ob
+	case market.UnifiedOrderBook:
+		return &ob
+	}
+	return nil
+}
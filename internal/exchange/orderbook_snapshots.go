@@ -0,0 +1,137 @@
+package exchange
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"daemon-go/internal/market"
+)
+
+// snapshotDepth - глубина REST снимка для ресинхронизации
+const snapshotDepth = 100
+
+// parseStringLevels переводит уровни вида [["price","volume",...]] в PriceLevel
+func parseStringLevels(raw [][]string) []market.PriceLevel {
+	levels := make([]market.PriceLevel, 0, len(raw))
+	for _, level := range raw {
+		if len(level) >= 2 {
+			levels = append(levels, market.PriceLevel{
+				Price:     parseFloatString(level[0]),
+				Volume:    parseFloatString(level[1]),
+				RawPrice:  level[0],
+				RawVolume: level[1],
+			})
+		}
+	}
+	return levels
+}
+
+// fetchOrderBookSnapshot загружает снимок стакана Binance (/api/v3/depth)
+func (a *BinanceAdapter) fetchOrderBookSnapshot(symbol string) (*market.UnifiedOrderBook, error) {
+	exSymbol, err := exchangeSymbol(symbol, "")
+	if err != nil {
+		return nil, fmt.Errorf("BinanceAdapter: %w", err)
+	}
+
+	var resp struct {
+		LastUpdateID int64      `json:"lastUpdateId"`
+		Bids         [][]string `json:"bids"`
+		Asks         [][]string `json:"asks"`
+	}
+	path := fmt.Sprintf("/api/v3/depth?symbol=%s&limit=%d", exSymbol, snapshotDepth)
+	if err := a.rest.GetJSON(path, &resp); err != nil {
+		return nil, fmt.Errorf("BinanceAdapter: depth snapshot: %w", err)
+	}
+
+	bids := parseStringLevels(resp.Bids)
+	asks := parseStringLevels(resp.Asks)
+	return &market.UnifiedOrderBook{
+		Symbol:       symbol,
+		Timestamp:    time.Now(),
+		Bids:         bids,
+		Asks:         asks,
+		Depth:        len(bids) + len(asks),
+		UpdateType:   market.OrderBookUpdateTypeSnapshot,
+		LastUpdateID: resp.LastUpdateID,
+	}, nil
+}
+
+// fetchOrderBookSnapshot загружает снимок стакана Bybit (/v5/market/orderbook)
+func (a *BybitAdapter) fetchOrderBookSnapshot(symbol string) (*market.UnifiedOrderBook, error) {
+	exSymbol, err := exchangeSymbol(symbol, "")
+	if err != nil {
+		return nil, fmt.Errorf("BybitAdapter: %w", err)
+	}
+
+	var resp struct {
+		RetCode int    `json:"retCode"`
+		RetMsg  string `json:"retMsg"`
+		Result  struct {
+			Bids     [][]string `json:"b"`
+			Asks     [][]string `json:"a"`
+			Ts       int64      `json:"ts"`
+			UpdateID int64      `json:"u"`
+		} `json:"result"`
+	}
+	path := fmt.Sprintf("/v5/market/orderbook?category=spot&symbol=%s&limit=%d", exSymbol, snapshotDepth)
+	if err := a.rest.GetJSON(path, &resp); err != nil {
+		return nil, fmt.Errorf("BybitAdapter: orderbook snapshot: %w", err)
+	}
+	if resp.RetCode != 0 {
+		return nil, fmt.Errorf("BybitAdapter: orderbook snapshot: retCode %d: %s", resp.RetCode, resp.RetMsg)
+	}
+
+	bids := parseStringLevels(resp.Result.Bids)
+	asks := parseStringLevels(resp.Result.Asks)
+	return &market.UnifiedOrderBook{
+		Symbol:       symbol,
+		Timestamp:    millisToTime(resp.Result.Ts),
+		Bids:         bids,
+		Asks:         asks,
+		Depth:        len(bids) + len(asks),
+		UpdateType:   market.OrderBookUpdateTypeSnapshot,
+		LastUpdateID: resp.Result.UpdateID,
+	}, nil
+}
+
+// fetchOrderBookSnapshot загружает снимок стакана KuCoin (/api/v1/market/orderbook/level2_100)
+func (a *KucoinAdapter) fetchOrderBookSnapshot(symbol string) (*market.UnifiedOrderBook, error) {
+	exSymbol, err := exchangeSymbol(symbol, "-")
+	if err != nil {
+		return nil, fmt.Errorf("KucoinAdapter: %w", err)
+	}
+
+	var resp struct {
+		Code string `json:"code"`
+		Data struct {
+			Sequence string     `json:"sequence"`
+			Time     int64      `json:"time"`
+			Bids     [][]string `json:"bids"`
+			Asks     [][]string `json:"asks"`
+		} `json:"data"`
+	}
+	if err := a.rest.GetJSON("/api/v1/market/orderbook/level2_100?symbol="+exSymbol, &resp); err != nil {
+		return nil, fmt.Errorf("KucoinAdapter: level2 snapshot: %w", err)
+	}
+	if resp.Code != "200000" {
+		return nil, fmt.Errorf("KucoinAdapter: level2 snapshot: code %s", resp.Code)
+	}
+
+	sequence, err := strconv.ParseInt(resp.Data.Sequence, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("KucoinAdapter: level2 snapshot: invalid sequence %q", resp.Data.Sequence)
+	}
+
+	bids := parseStringLevels(resp.Data.Bids)
+	asks := parseStringLevels(resp.Data.Asks)
+	return &market.UnifiedOrderBook{
+		Symbol:       symbol,
+		Timestamp:    millisToTime(resp.Data.Time),
+		Bids:         bids,
+		Asks:         asks,
+		Depth:        len(bids) + len(asks),
+		UpdateType:   market.OrderBookUpdateTypeSnapshot,
+		LastUpdateID: sequence,
+	}, nil
+}
@@ -0,0 +1,541 @@
+package exchange
+
+import (
+	"sync"
+	"time"
+
+	"daemon-go/internal/market"
+	"daemon-go/pkg/log"
+)
+
+// SnapshotFetcher загружает REST снимок стакана по унифицированному символу (BTC/USDT).
+// LastUpdateID снимка должен соответствовать последовательности WS обновлений
+type SnapshotFetcher func(symbol string) (*market.UnifiedOrderBook, error)
+
+// SnapshotRequester просит биржу прислать снимок стакана в WS поток (HTX req, повторная
+// подписка Poloniex). Снимок приходит обычным orderbook сообщением с ID последовательности
+// и становится базой для буферизованных diff
+type SnapshotRequester func(symbol string) error
+
+// Параметры ресинхронизации
+const (
+	resyncMaxBuffer    = 1000            // максимум буферизованных diff сообщений на символ
+	resyncMaxRetries   = 5               // попыток получения снимка подряд
+	resyncRetryDelay   = 1 * time.Second // пауза между попытками
+	resyncSnapshotWait = 5 * time.Second // ожидание снимка из WS после запроса
+)
+
+// sequenceResult - результат проверки последовательности обновления
+type sequenceResult int
+
+const (
+	sequenceOK  sequenceResult = iota // обновление продолжает цепочку
+	sequenceOld                       // обновление уже учтено в снимке, пропускаем
+	sequenceGap                       // пропущены обновления
+)
+
+// checkSequence проверяет, продолжает ли обновление цепочку после lastID.
+// lastID = 0 означает, что цепочка еще не привязана, и обновление принимается как якорь
+func checkSequence(lastID int64, ob market.UnifiedOrderBook) sequenceResult {
+	if lastID == 0 {
+		return sequenceOK
+	}
+	if ob.LastUpdateID <= lastID {
+		return sequenceOld
+	}
+
+	// HTX: каждое сообщение ссылается на предыдущее
+	if ob.PrevUpdateID > 0 {
+		if ob.PrevUpdateID == lastID {
+			return sequenceOK
+		}
+		return sequenceGap
+	}
+
+	// Binance/KuCoin/Bybit: диапазон [first, last] должен начинаться не позже lastID+1
+	first := ob.FirstUpdateID
+	if first == 0 {
+		first = ob.LastUpdateID
+	}
+	if first > lastID+1 {
+		return sequenceGap
+	}
+	return sequenceOK
+}
+
+// symbolSequence - состояние последовательности одного символа
+type symbolSequence struct {
+	lastID    int64
+	synced    bool                    // цепочка согласована со снимком
+	resyncing bool                    // идет получение снимка
+	done      chan struct{}           // закрывается, когда снимок текущей ресинхронизации применен
+	buffer    []market.UnifiedMessage // diff сообщения, пришедшие во время ресинхронизации
+	book      *market.LocalOrderBook  // локальный стакан для сверки checksum (только для бирж, присылающих его)
+	last      market.UnifiedMessage   // последнее сообщение символа - шаблон для сообщения об устаревании
+}
+
+// OrderBookSync контролирует последовательность обновлений стаканов биржи:
+// при разрыве или несовпадении checksum публикует сообщение об устаревании стакана, буферизует diff,
+// получает снимок (REST или запросом в WS) и публикует снимок вместе с буферизованными обновлениями.
+// Публикация идет после освобождения mu под pubMu: подписчики шины не блокируют
+// проверку последовательности, а порядок сообщений сохраняется
+type OrderBookSync struct {
+	mu       sync.Mutex
+	pubMu    sync.Mutex
+	exchange string
+	fetch    SnapshotFetcher
+	request  SnapshotRequester
+	publish  func(market.UnifiedMessage)
+	symbols  map[string]*symbolSequence // [unified symbol]
+	logger   *log.Logger
+
+	maxRetries   int
+	retryDelay   time.Duration
+	snapshotWait time.Duration
+}
+
+// NewOrderBookSync создает контроль последовательности для биржи
+func NewOrderBookSync(exchange string, fetch SnapshotFetcher, publish func(market.UnifiedMessage)) *OrderBookSync {
+	return &OrderBookSync{
+		exchange: exchange,
+		fetch:    fetch,
+		publish:  publish,
+		symbols:  make(map[string]*symbolSequence),
+		logger:   log.New("orderbook_sync"),
+
+		maxRetries:   resyncMaxRetries,
+		retryDelay:   resyncRetryDelay,
+		snapshotWait: resyncSnapshotWait,
+	}
+}
+
+// SetSnapshotRequester задает запрос снимка через WS. Используется вместо REST загрузки
+// для бирж, чей REST снимок не содержит ID последовательности (HTX, Poloniex)
+func (s *OrderBookSync) SetSnapshotRequester(request SnapshotRequester) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.request = request
+}
+
+// Process обрабатывает orderbook сообщение и публикует его, если последовательность не нарушена.
+// Сообщения без ID обновлений публикуются без проверки
+func (s *OrderBookSync) Process(msg market.UnifiedMessage) {
+	ob, ok := msg.Data.(market.UnifiedOrderBook)
+	if !ok || (ob.LastUpdateID == 0 && ob.FirstUpdateID == 0) {
+		s.pubMu.Lock()
+		defer s.pubMu.Unlock()
+		s.publish(msg)
+		return
+	}
+
+	s.mu.Lock()
+	out := s.accept(msg, ob)
+	if len(out) == 0 {
+		s.mu.Unlock()
+		return
+	}
+	s.publishUnlock(out...)
+}
+
+// accept проверяет последовательность и checksum обновления и возвращает сообщения для публикации:
+// само обновление, снимок с буферизованными diff или сообщение об устаревании стакана.
+// Пустой результат - обновление устарело или буферизовано. Вызывается под s.mu
+func (s *OrderBookSync) accept(msg market.UnifiedMessage, ob market.UnifiedOrderBook) []market.UnifiedMessage {
+	state := s.state(msg.Symbol)
+	state.last = msg
+
+	if ob.UpdateType != market.OrderBookUpdateTypeIncremental {
+		if state.resyncing {
+			// Снимок из WS во время ресинхронизации - база для буферизованных diff
+			out, _ := s.connectSnapshot(msg, state)
+			return out
+		}
+		// Снимок из WS сбрасывает цепочку
+		state.lastID = ob.LastUpdateID
+		state.synced = true
+		state.buffer = nil
+		return s.verifyChecksum(msg, state)
+	}
+
+	if state.resyncing {
+		s.bufferMessage(state, msg)
+		return nil
+	}
+
+	if !state.synced {
+		// Diff поток без начального снимка: сразу запрашиваем снимок
+		invalidated := s.startResync(msg, state, "initial snapshot")
+		s.bufferMessage(state, msg)
+		return []market.UnifiedMessage{invalidated}
+	}
+
+	switch checkSequence(state.lastID, ob) {
+	case sequenceOld:
+		return nil
+	case sequenceGap:
+		s.logger.Warn("[ORDERBOOK_SYNC] %s %s: sequence gap, last=%d, got first=%d last=%d prev=%d",
+			s.exchange, msg.Symbol, state.lastID, ob.FirstUpdateID, ob.LastUpdateID, ob.PrevUpdateID)
+		recordSequenceGap(s.exchange, msg.Symbol)
+		invalidated := s.startResync(msg, state, "sequence gap")
+		s.bufferMessage(state, msg)
+		return []market.UnifiedMessage{invalidated}
+	}
+
+	state.lastID = ob.LastUpdateID
+	return s.verifyChecksum(msg, state)
+}
+
+// publishUnlock освобождает s.mu и публикует сообщения по порядку: pubMu берется до
+// освобождения s.mu, чтобы следующее принятое сообщение не обогнало эти
+func (s *OrderBookSync) publishUnlock(msgs ...market.UnifiedMessage) {
+	s.pubMu.Lock()
+	s.mu.Unlock()
+	defer s.pubMu.Unlock()
+	for _, msg := range msgs {
+		s.publish(msg)
+	}
+}
+
+// verifyChecksum применяет обновление к локальному стакану, сверяет checksum биржи и возвращает
+// сообщения для публикации. При несовпадении вместо обновления публикуется устаревание стакана,
+// чтобы подписчики не считали по поврежденному стакану, и запускается ресинхронизация (вызывается под s.mu)
+func (s *OrderBookSync) verifyChecksum(msg market.UnifiedMessage, state *symbolSequence) []market.UnifiedMessage {
+	ob := msg.Data.(market.UnifiedOrderBook)
+	if ob.Checksum == 0 && state.book == nil {
+		return []market.UnifiedMessage{msg}
+	}
+	if state.book == nil {
+		state.book = market.NewLocalOrderBook(s.exchange, msg.Symbol)
+	}
+
+	if ob.UpdateType == market.OrderBookUpdateTypeIncremental {
+		if err := state.book.ApplyDiff(ob); err != nil {
+			// Локальный стакан еще не получил снимок - сверять не с чем
+			return []market.UnifiedMessage{msg}
+		}
+	} else {
+		state.book.ApplySnapshot(ob)
+	}
+
+	if err := state.book.VerifyChecksum(ob.Checksum); err != nil {
+		s.logger.Warn("[ORDERBOOK_SYNC] %s %s: checksum mismatch at update %d (checksum %d)",
+			s.exchange, msg.Symbol, ob.LastUpdateID, ob.Checksum)
+		recordChecksumMismatch(s.exchange, msg.Symbol)
+		// Обновление с неверной суммой не публикуем: снимок заменит стакан целиком
+		return []market.UnifiedMessage{s.startResync(msg, state, "checksum mismatch")}
+	}
+	return []market.UnifiedMessage{msg}
+}
+
+// Reset сбрасывает состояние всех символов (например, после переподключения WS): текущие
+// ресинхронизации прекращаются, подписчикам публикуется устаревание стаканов до нового снимка
+func (s *OrderBookSync) Reset() {
+	s.mu.Lock()
+	var out []market.UnifiedMessage
+	for _, state := range s.symbols {
+		if state.synced || state.resyncing {
+			out = append(out, invalidation(state.last))
+		}
+		state.synced = false
+		state.resyncing = false
+		state.done = nil
+		state.buffer = nil
+		if state.book != nil {
+			state.book.Reset()
+		}
+	}
+	if len(out) == 0 {
+		s.mu.Unlock()
+		return
+	}
+	s.publishUnlock(out...)
+}
+
+// IsStale проверяет, помечен ли стакан символа как устаревший
+func (s *OrderBookSync) IsStale(symbol string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	state, ok := s.symbols[symbol]
+	return ok && (!state.synced || state.resyncing)
+}
+
+func (s *OrderBookSync) state(symbol string) *symbolSequence {
+	state, ok := s.symbols[symbol]
+	if !ok {
+		state = &symbolSequence{}
+		s.symbols[symbol] = state
+	}
+	return state
+}
+
+func (s *OrderBookSync) bufferMessage(state *symbolSequence, msg market.UnifiedMessage) {
+	if len(state.buffer) >= resyncMaxBuffer {
+		// Старые diff уже не понадобятся, если снимок окажется новее
+		state.buffer = state.buffer[1:]
+	}
+	state.buffer = append(state.buffer, msg)
+}
+
+// invalidation строит сообщение об устаревании стакана: пустой стакан с типом invalidated,
+// по которому подписчики убирают стакан из расчетов до следующего снимка
+func invalidation(template market.UnifiedMessage) market.UnifiedMessage {
+	msg := template
+	msg.MessageType = market.MessageTypeOrderBook
+	msg.Timestamp = time.Now()
+	msg.Data = market.UnifiedOrderBook{
+		Symbol:        template.Symbol,
+		UnifiedSymbol: template.UnifiedSymbol,
+		Timestamp:     msg.Timestamp,
+		UpdateType:    market.OrderBookUpdateTypeInvalidated,
+	}
+	return msg
+}
+
+// startResync помечает стакан устаревшим, очищает буфер, запускает получение снимка и возвращает
+// сообщение об устаревании для публикации (вызывается под s.mu)
+func (s *OrderBookSync) startResync(msg market.UnifiedMessage, state *symbolSequence, reason string) market.UnifiedMessage {
+	state.synced = false
+	state.resyncing = true
+	state.done = make(chan struct{})
+	state.buffer = nil
+	if state.book != nil {
+		state.book.Reset()
+	}
+
+	s.logger.Info("[ORDERBOOK_SYNC] %s %s: resync started (%s)", s.exchange, msg.Symbol, reason)
+	go s.resync(msg, state.done)
+	return invalidation(msg)
+}
+
+// resync получает снимок и применяет буферизованные diff. done - канал текущей ресинхронизации:
+// если символ сброшен или ресинхронизация перезапущена, горутина завершается
+func (s *OrderBookSync) resync(trigger market.UnifiedMessage, done chan struct{}) {
+	symbol := trigger.Symbol
+	started := time.Now()
+
+	s.mu.Lock()
+	request := s.request
+	s.mu.Unlock()
+
+	for attempt := 1; attempt <= s.maxRetries; attempt++ {
+		if !s.isCurrent(symbol, done) {
+			return
+		}
+
+		if request != nil {
+			if err := request(symbol); err != nil {
+				s.logger.Error("[ORDERBOOK_SYNC] %s %s: snapshot request failed (attempt %d): %v",
+					s.exchange, symbol, attempt, err)
+				time.Sleep(s.retryDelay)
+				continue
+			}
+			select {
+			case <-done:
+				recordResync(s.exchange, symbol, time.Since(started), true)
+				s.logger.Info("[ORDERBOOK_SYNC] %s %s: resync completed in %v", s.exchange, symbol, time.Since(started))
+				return
+			case <-time.After(s.snapshotWait):
+				// Снимок не пришел или не стыкуется с буфером - запрашиваем снова
+				s.logger.Warn("[ORDERBOOK_SYNC] %s %s: no connecting snapshot within %v (attempt %d)",
+					s.exchange, symbol, s.snapshotWait, attempt)
+			}
+			continue
+		}
+
+		snapshot, err := s.fetch(symbol)
+		if err != nil {
+			s.logger.Error("[ORDERBOOK_SYNC] %s %s: snapshot fetch failed (attempt %d): %v",
+				s.exchange, symbol, attempt, err)
+			time.Sleep(s.retryDelay)
+			continue
+		}
+
+		if s.applySnapshot(trigger, snapshot, done) {
+			recordResync(s.exchange, symbol, time.Since(started), true)
+			s.logger.Info("[ORDERBOOK_SYNC] %s %s: resync completed in %v (snapshot id %d)",
+				s.exchange, symbol, time.Since(started), snapshot.LastUpdateID)
+			return
+		}
+
+		// Снимок старше буферизованных diff - пробуем снова
+		time.Sleep(s.retryDelay)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	state := s.state(symbol)
+	if state.done != done {
+		return
+	}
+	recordResync(s.exchange, symbol, time.Since(started), false)
+	s.logger.Error("[ORDERBOOK_SYNC] %s %s: resync failed after %d attempts", s.exchange, symbol, s.maxRetries)
+	// Стакан остается устаревшим: следующий diff запустит новую ресинхронизацию
+	state.resyncing = false
+	state.done = nil
+	state.buffer = nil
+}
+
+// isCurrent проверяет, что ресинхронизация с каналом done еще не завершена и не заменена другой
+func (s *OrderBookSync) isCurrent(symbol string, done chan struct{}) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	state, ok := s.symbols[symbol]
+	return ok && state.resyncing && state.done == done
+}
+
+// applySnapshot публикует REST снимок и буферизованные diff, продолжающие его цепочку.
+// Возвращает false, если снимок не содержит ID последовательности или между снимком и буфером разрыв
+func (s *OrderBookSync) applySnapshot(trigger market.UnifiedMessage, snapshot *market.UnifiedOrderBook, done chan struct{}) bool {
+	if snapshot.LastUpdateID == 0 {
+		s.logger.Warn("[ORDERBOOK_SYNC] %s %s: snapshot has no update id, cannot connect buffered diffs",
+			s.exchange, trigger.Symbol)
+		return false
+	}
+
+	s.mu.Lock()
+	state := s.state(trigger.Symbol)
+	if !state.resyncing || state.done != done {
+		// Пока загружался снимок, пришел снимок из WS или символ сброшен
+		s.mu.Unlock()
+		return true
+	}
+
+	snapshot.UpdateType = market.OrderBookUpdateTypeSnapshot
+	if snapshot.UnifiedSymbol == nil {
+		snapshot.UnifiedSymbol = trigger.UnifiedSymbol
+	}
+	if snapshot.Symbol == "" {
+		snapshot.Symbol = trigger.Symbol
+	}
+	snapshotMsg := trigger
+	snapshotMsg.Timestamp = time.Now()
+	snapshotMsg.Data = *snapshot
+
+	out, ok := s.connectSnapshot(snapshotMsg, state)
+	if !ok {
+		s.mu.Unlock()
+		return false
+	}
+	s.publishUnlock(out...)
+	return true
+}
+
+// connectSnapshot привязывает буферизованные diff к снимку: diff, уже учтенные в снимке, отбрасываются,
+// при разрыве снимок не принимается и ресинхронизация продолжается. Возвращает снимок и
+// продолжающие его diff для публикации (вызывается под s.mu)
+func (s *OrderBookSync) connectSnapshot(snapshotMsg market.UnifiedMessage, state *symbolSequence) ([]market.UnifiedMessage, bool) {
+	snapshot := snapshotMsg.Data.(market.UnifiedOrderBook)
+
+	lastID := snapshot.LastUpdateID
+	pending := make([]market.UnifiedMessage, 0, len(state.buffer))
+	for _, msg := range state.buffer {
+		ob := msg.Data.(market.UnifiedOrderBook)
+		switch checkSequence(lastID, ob) {
+		case sequenceOld:
+			continue
+		case sequenceGap:
+			s.logger.Warn("[ORDERBOOK_SYNC] %s %s: snapshot %d does not connect to buffered diff %d-%d",
+				s.exchange, snapshotMsg.Symbol, snapshot.LastUpdateID, ob.FirstUpdateID, ob.LastUpdateID)
+			return nil, false
+		}
+		lastID = ob.LastUpdateID
+		pending = append(pending, msg)
+	}
+
+	if state.book != nil || snapshot.Checksum != 0 {
+		if state.book == nil {
+			state.book = market.NewLocalOrderBook(s.exchange, snapshotMsg.Symbol)
+		}
+		state.book.ApplySnapshot(snapshot)
+		if snapshot.Checksum != 0 {
+			if err := state.book.VerifyChecksum(snapshot.Checksum); err != nil {
+				s.logger.Warn("[ORDERBOOK_SYNC] %s %s: snapshot checksum mismatch, waiting for next snapshot",
+					s.exchange, snapshotMsg.Symbol)
+				recordChecksumMismatch(s.exchange, snapshotMsg.Symbol)
+				state.book.Reset()
+				return nil, false
+			}
+		}
+		for _, msg := range pending {
+			_ = state.book.ApplyDiff(msg.Data.(market.UnifiedOrderBook))
+		}
+	}
+
+	state.lastID = lastID
+	state.synced = true
+	state.resyncing = false
+	state.buffer = nil
+	if state.done != nil {
+		close(state.done)
+		state.done = nil
+	}
+
+	return append([]market.UnifiedMessage{snapshotMsg}, pending...), true
+}
+
+// ResyncStats - метрики ресинхронизации стакана одного символа
+type ResyncStats struct {
+	Gaps               int64         `json:"gaps"`                // обнаружено разрывов последовательности
+	Resyncs            int64         `json:"resyncs"`             // успешных ресинхронизаций
+	Failures           int64         `json:"failures"`            // неудачных ресинхронизаций
+	ChecksumMismatches int64         `json:"checksum_mismatches"` // несовпадений контрольной суммы
+	LastResync         time.Time     `json:"last_resync"`         // время последней ресинхронизации
+	LastDuration       time.Duration `json:"last_duration"`       // длительность последней ресинхронизации
+}
+
+var (
+	resyncStatsMu sync.Mutex
+	resyncStats   = make(map[string]map[string]*ResyncStats) // [exchange][symbol]
+)
+
+func resyncStatsFor(exchange, symbol string) *ResyncStats {
+	if resyncStats[exchange] == nil {
+		resyncStats[exchange] = make(map[string]*ResyncStats)
+	}
+	stats, ok := resyncStats[exchange][symbol]
+	if !ok {
+		stats = &ResyncStats{}
+		resyncStats[exchange][symbol] = stats
+	}
+	return stats
+}
+
+func recordSequenceGap(exchange, symbol string) {
+	resyncStatsMu.Lock()
+	defer resyncStatsMu.Unlock()
+	resyncStatsFor(exchange, symbol).Gaps++
+}
+
+func recordChecksumMismatch(exchange, symbol string) {
+	resyncStatsMu.Lock()
+	defer resyncStatsMu.Unlock()
+	resyncStatsFor(exchange, symbol).ChecksumMismatches++
+}
+
+func recordResync(exchange, symbol string, duration time.Duration, success bool) {
+	resyncStatsMu.Lock()
+	defer resyncStatsMu.Unlock()
+	stats := resyncStatsFor(exchange, symbol)
+	if success {
+		stats.Resyncs++
+	} else {
+		stats.Failures++
+	}
+	stats.LastResync = time.Now()
+	stats.LastDuration = duration
+}
+
+// GetResyncStats возвращает копию метрик ресинхронизации по биржам и символам
+func GetResyncStats() map[string]map[string]ResyncStats {
+	resyncStatsMu.Lock()
+	defer resyncStatsMu.Unlock()
+
+	result := make(map[string]map[string]ResyncStats, len(resyncStats))
+	for exchange, symbols := range resyncStats {
+		result[exchange] = make(map[string]ResyncStats, len(symbols))
+		for symbol, stats := range symbols {
+			result[exchange][symbol] = *stats
+		}
+	}
+	return result
+}
@@ -0,0 +1,416 @@
+package exchange
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"daemon-go/internal/market"
+)
+
+// newTestSync создает OrderBookSync с короткими паузами ресинхронизации, публикующий в канал
+func newTestSync(fetch SnapshotFetcher) (*OrderBookSync, chan market.UnifiedMessage) {
+	out := make(chan market.UnifiedMessage, 100)
+	s := NewOrderBookSync("test", fetch, func(msg market.UnifiedMessage) { out <- msg })
+	s.maxRetries = 3
+	s.retryDelay = 10 * time.Millisecond
+	s.snapshotWait = 100 * time.Millisecond
+	return s, out
+}
+
+func bookMsg(ob market.UnifiedOrderBook) market.UnifiedMessage {
+	ob.Symbol = "BTC/USDT"
+	return market.UnifiedMessage{
+		Exchange:    "test",
+		Symbol:      "BTC/USDT",
+		MessageType: market.MessageTypeOrderBook,
+		Data:        ob,
+	}
+}
+
+// rangeDiff - diff Binance/KuCoin с диапазоном [first, last]
+func rangeDiff(first, last int64) market.UnifiedMessage {
+	return bookMsg(market.UnifiedOrderBook{
+		UpdateType:    market.OrderBookUpdateTypeIncremental,
+		FirstUpdateID: first,
+		LastUpdateID:  last,
+		Bids:          []market.PriceLevel{{Price: 100, Volume: float64(last)}},
+	})
+}
+
+// chainDiff - diff HTX со ссылкой на предыдущий seqNum
+func chainDiff(prev, seq int64) market.UnifiedMessage {
+	return bookMsg(market.UnifiedOrderBook{
+		UpdateType:   market.OrderBookUpdateTypeIncremental,
+		PrevUpdateID: prev,
+		LastUpdateID: seq,
+	})
+}
+
+func snapshotMsg(id int64) market.UnifiedMessage {
+	return bookMsg(market.UnifiedOrderBook{
+		UpdateType:   market.OrderBookUpdateTypeSnapshot,
+		LastUpdateID: id,
+		Bids:         []market.PriceLevel{{Price: 100, Volume: 1}},
+	})
+}
+
+// receive ждет n опубликованных сообщений
+func receive(t *testing.T, out chan market.UnifiedMessage, n int) []market.UnifiedOrderBook {
+	t.Helper()
+	books := make([]market.UnifiedOrderBook, 0, n)
+	for len(books) < n {
+		select {
+		case msg := <-out:
+			books = append(books, msg.Data.(market.UnifiedOrderBook))
+		case <-time.After(2 * time.Second):
+			t.Fatalf("got %d messages, want %d", len(books), n)
+		}
+	}
+	return books
+}
+
+func expectNone(t *testing.T, out chan market.UnifiedMessage) {
+	t.Helper()
+	select {
+	case msg := <-out:
+		ob := msg.Data.(market.UnifiedOrderBook)
+		t.Fatalf("unexpected message %s id %d", ob.UpdateType, ob.LastUpdateID)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+// waitResyncDone ждет завершения ресинхронизации символа
+func waitResyncDone(t *testing.T, s *OrderBookSync) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		s.mu.Lock()
+		resyncing := s.state("BTC/USDT").resyncing
+		s.mu.Unlock()
+		if !resyncing {
+			return
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatal("resync did not finish")
+}
+
+func updateTypes(books []market.UnifiedOrderBook) []market.OrderBookUpdateType {
+	types := make([]market.OrderBookUpdateType, len(books))
+	for i, ob := range books {
+		types[i] = ob.UpdateType
+	}
+	return types
+}
+
+func TestCheckSequence(t *testing.T) {
+	tests := []struct {
+		name   string
+		lastID int64
+		ob     market.UnifiedOrderBook
+		want   sequenceResult
+	}{
+		{"unanchored", 0, market.UnifiedOrderBook{FirstUpdateID: 50, LastUpdateID: 60}, sequenceOK},
+		{"range continues", 100, market.UnifiedOrderBook{FirstUpdateID: 101, LastUpdateID: 105}, sequenceOK},
+		{"range overlaps", 100, market.UnifiedOrderBook{FirstUpdateID: 95, LastUpdateID: 105}, sequenceOK},
+		{"range already applied", 100, market.UnifiedOrderBook{FirstUpdateID: 90, LastUpdateID: 100}, sequenceOld},
+		{"range gap", 100, market.UnifiedOrderBook{FirstUpdateID: 102, LastUpdateID: 105}, sequenceGap},
+		{"single id gap", 100, market.UnifiedOrderBook{LastUpdateID: 102}, sequenceGap},
+		{"chain continues", 100, market.UnifiedOrderBook{PrevUpdateID: 100, LastUpdateID: 101}, sequenceOK},
+		{"chain gap", 100, market.UnifiedOrderBook{PrevUpdateID: 101, LastUpdateID: 102}, sequenceGap},
+		{"chain old", 100, market.UnifiedOrderBook{PrevUpdateID: 99, LastUpdateID: 100}, sequenceOld},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := checkSequence(tt.lastID, tt.ob); got != tt.want {
+				t.Errorf("checkSequence(%d) = %d, want %d", tt.lastID, got, tt.want)
+			}
+		})
+	}
+}
+
+// Разрыв последовательности: публикуется устаревание стакана, затем REST снимок
+// и буферизованные diff, которые продолжают его цепочку
+func TestOrderBookSyncGapReplaysBuffer(t *testing.T) {
+	fetched := make(chan struct{})
+	release := make(chan struct{})
+	s, out := newTestSync(func(symbol string) (*market.UnifiedOrderBook, error) {
+		close(fetched)
+		<-release
+		ob := snapshotMsg(113).Data.(market.UnifiedOrderBook)
+		return &ob, nil
+	})
+
+	s.Process(snapshotMsg(100))
+	s.Process(rangeDiff(101, 102))
+	if books := receive(t, out, 2); books[1].LastUpdateID != 102 {
+		t.Fatalf("diff 102 not published: %+v", books[1])
+	}
+
+	s.Process(rangeDiff(110, 112)) // разрыв: 103-109 пропущены
+	invalidated := receive(t, out, 1)[0]
+	if invalidated.UpdateType != market.OrderBookUpdateTypeInvalidated || len(invalidated.Bids) != 0 {
+		t.Fatalf("gap published %s with %d bids, want empty invalidated book", invalidated.UpdateType, len(invalidated.Bids))
+	}
+	if !s.IsStale("BTC/USDT") {
+		t.Fatal("book not stale during resync")
+	}
+
+	<-fetched
+	s.Process(rangeDiff(113, 114)) // перекрывается со снимком 113
+	s.Process(rangeDiff(115, 116))
+	expectNone(t, out)
+	close(release)
+
+	// Diff 110-112, запустивший ресинхронизацию, учтен в снимке 113 и отбрасывается
+	books := receive(t, out, 3)
+	if books[0].UpdateType != market.OrderBookUpdateTypeSnapshot || books[0].LastUpdateID != 113 {
+		t.Fatalf("first message after resync = %s %d, want snapshot 113", books[0].UpdateType, books[0].LastUpdateID)
+	}
+	if books[1].LastUpdateID != 114 || books[2].LastUpdateID != 116 {
+		t.Fatalf("replayed diffs %d, %d, want 114, 116", books[1].LastUpdateID, books[2].LastUpdateID)
+	}
+	expectNone(t, out)
+	waitResyncDone(t, s)
+
+	s.Process(rangeDiff(117, 117))
+	if books := receive(t, out, 1); books[0].LastUpdateID != 117 {
+		t.Fatalf("diff after resync = %d, want 117", books[0].LastUpdateID)
+	}
+	if s.IsStale("BTC/USDT") {
+		t.Fatal("book still stale after resync")
+	}
+}
+
+// Снимок из WS (HTX req, переподписка Poloniex): буферизованные diff, уже учтенные в снимке, отбрасываются
+func TestOrderBookSyncRequestedSnapshotDropsStaleDiffs(t *testing.T) {
+	requested := make(chan string, 10)
+	s, out := newTestSync(nil)
+	s.SetSnapshotRequester(func(symbol string) error {
+		requested <- symbol
+		return nil
+	})
+
+	// Diff поток без снимка: запрашивается снимок, diff буферизуются
+	s.Process(chainDiff(100, 101))
+	if books := receive(t, out, 1); books[0].UpdateType != market.OrderBookUpdateTypeInvalidated {
+		t.Fatalf("first diff published %s, want invalidated", books[0].UpdateType)
+	}
+	select {
+	case symbol := <-requested:
+		if symbol != "BTC/USDT" {
+			t.Fatalf("requested snapshot for %q", symbol)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("snapshot not requested")
+	}
+	s.Process(chainDiff(101, 102))
+	s.Process(chainDiff(102, 103))
+	expectNone(t, out)
+
+	// Снимок seqNum 102: diff 101 и 102 в нем учтены
+	s.Process(snapshotMsg(102))
+	books := receive(t, out, 2)
+	if got := updateTypes(books); got[0] != market.OrderBookUpdateTypeSnapshot || got[1] != market.OrderBookUpdateTypeIncremental {
+		t.Fatalf("published %v, want snapshot and one diff", got)
+	}
+	if books[0].LastUpdateID != 102 || books[1].LastUpdateID != 103 {
+		t.Fatalf("published ids %d, %d, want 102, 103", books[0].LastUpdateID, books[1].LastUpdateID)
+	}
+	expectNone(t, out)
+	waitResyncDone(t, s)
+
+	s.Process(chainDiff(103, 104))
+	if books := receive(t, out, 1); books[0].LastUpdateID != 104 {
+		t.Fatalf("diff after snapshot = %d, want 104", books[0].LastUpdateID)
+	}
+}
+
+// Снимок, не стыкующийся с буфером, не принимается: ресинхронизация продолжается и запрашивает снимок снова
+func TestOrderBookSyncRequestedSnapshotWithGapRetries(t *testing.T) {
+	requested := make(chan string, 10)
+	s, out := newTestSync(nil)
+	s.SetSnapshotRequester(func(symbol string) error {
+		requested <- symbol
+		return nil
+	})
+
+	s.Process(chainDiff(100, 101))
+	receive(t, out, 1)
+	<-requested
+	s.Process(chainDiff(105, 106))
+
+	s.Process(snapshotMsg(100)) // до diff 105 не хватает 102-104
+	expectNone(t, out)
+	if !s.IsStale("BTC/USDT") {
+		t.Fatal("book marked synced after snapshot with a gap")
+	}
+	select {
+	case <-requested:
+	case <-time.After(time.Second):
+		t.Fatal("snapshot not requested again")
+	}
+
+	s.Process(snapshotMsg(105))
+	books := receive(t, out, 2)
+	if books[0].LastUpdateID != 105 || books[1].LastUpdateID != 106 {
+		t.Fatalf("published ids %d, %d, want 105, 106", books[0].LastUpdateID, books[1].LastUpdateID)
+	}
+}
+
+// Неудачная ресинхронизация: стакан остается устаревшим, флаг ресинхронизации снимается,
+// следующий diff запускает новую попытку
+func TestOrderBookSyncFailedResync(t *testing.T) {
+	fetches := make(chan struct{}, 100)
+	s, out := newTestSync(func(symbol string) (*market.UnifiedOrderBook, error) {
+		fetches <- struct{}{}
+		return nil, errors.New("exchange unavailable")
+	})
+
+	s.Process(snapshotMsg(100))
+	receive(t, out, 1)
+	s.Process(rangeDiff(105, 106))
+	if books := receive(t, out, 1); books[0].UpdateType != market.OrderBookUpdateTypeInvalidated {
+		t.Fatalf("gap published %s, want invalidated", books[0].UpdateType)
+	}
+
+	waitResyncDone(t, s)
+	if len(fetches) != s.maxRetries {
+		t.Fatalf("fetch attempts = %d, want %d", len(fetches), s.maxRetries)
+	}
+	if !s.IsStale("BTC/USDT") {
+		t.Fatal("book not stale after failed resync")
+	}
+	s.mu.Lock()
+	buffered := len(s.state("BTC/USDT").buffer)
+	s.mu.Unlock()
+	if buffered != 0 {
+		t.Fatalf("buffer holds %d diffs after failed resync", buffered)
+	}
+	expectNone(t, out)
+
+	// Следующий diff снова запускает ресинхронизацию
+	s.Process(rangeDiff(107, 107))
+	if books := receive(t, out, 1); books[0].UpdateType != market.OrderBookUpdateTypeInvalidated {
+		t.Fatalf("diff after failed resync published %s, want invalidated", books[0].UpdateType)
+	}
+}
+
+// REST снимок без ID последовательности не может стать базой для буфера
+func TestOrderBookSyncRejectsSnapshotWithoutID(t *testing.T) {
+	s, out := newTestSync(func(symbol string) (*market.UnifiedOrderBook, error) {
+		ob := snapshotMsg(0).Data.(market.UnifiedOrderBook)
+		return &ob, nil
+	})
+
+	s.Process(rangeDiff(101, 101))
+	receive(t, out, 1)
+	waitResyncDone(t, s)
+	expectNone(t, out)
+	if !s.IsStale("BTC/USDT") {
+		t.Fatal("book synced from a snapshot without update id")
+	}
+}
+
+// Reset (переподключение WS) прерывает ресинхронизацию и публикует устаревание стакана
+func TestOrderBookSyncResetClearsResync(t *testing.T) {
+	requested := make(chan string, 10)
+	s, out := newTestSync(nil)
+	s.SetSnapshotRequester(func(symbol string) error {
+		requested <- symbol
+		return nil
+	})
+
+	s.Process(chainDiff(100, 101))
+	receive(t, out, 1)
+	<-requested
+
+	s.Reset()
+	if books := receive(t, out, 1); books[0].UpdateType != market.OrderBookUpdateTypeInvalidated {
+		t.Fatalf("reset published %s, want invalidated", books[0].UpdateType)
+	}
+	s.mu.Lock()
+	state := s.state("BTC/USDT")
+	resyncing, buffered := state.resyncing, len(state.buffer)
+	s.mu.Unlock()
+	if resyncing || buffered != 0 {
+		t.Fatalf("after reset resyncing=%v buffer=%d", resyncing, buffered)
+	}
+
+	// Снимок новой подписки принимается как база без старого буфера
+	s.Process(snapshotMsg(200))
+	s.Process(chainDiff(200, 201))
+	books := receive(t, out, 2)
+	if books[0].LastUpdateID != 200 || books[1].LastUpdateID != 201 {
+		t.Fatalf("published ids %d, %d, want 200, 201", books[0].LastUpdateID, books[1].LastUpdateID)
+	}
+
+	// Прерванная ресинхронизация не запрашивает снимок повторно
+	time.Sleep(2 * s.snapshotWait)
+	select {
+	case <-requested:
+		t.Fatal("aborted resync requested another snapshot")
+	default:
+	}
+}
+
+// Несовпадение checksum: вместо поврежденного обновления публикуется устаревание стакана,
+// стакан восстанавливается по следующему снимку
+func TestOrderBookSyncChecksumMismatchInvalidates(t *testing.T) {
+	requested := make(chan string, 10)
+	s, out := newTestSync(nil)
+	s.exchange = "poloniex"
+	s.SetSnapshotRequester(func(symbol string) error {
+		requested <- symbol
+		return nil
+	})
+
+	checksum := market.InterleavedCRC32(25)
+	bids := []market.PriceLevel{{Price: 100, Volume: 1, RawPrice: "100", RawVolume: "1"}}
+	asks := []market.PriceLevel{{Price: 101, Volume: 2, RawPrice: "101", RawVolume: "2"}}
+	snapshot := func(id int64) market.UnifiedMessage {
+		return bookMsg(market.UnifiedOrderBook{
+			UpdateType:   market.OrderBookUpdateTypeSnapshot,
+			LastUpdateID: id,
+			Bids:         bids,
+			Asks:         asks,
+			Checksum:     int64(checksum(bids, asks)),
+		})
+	}
+
+	s.Process(snapshot(1))
+	if books := receive(t, out, 1); books[0].UpdateType != market.OrderBookUpdateTypeSnapshot {
+		t.Fatalf("snapshot with valid checksum published %s", books[0].UpdateType)
+	}
+
+	s.Process(bookMsg(market.UnifiedOrderBook{
+		UpdateType:   market.OrderBookUpdateTypeIncremental,
+		PrevUpdateID: 1,
+		LastUpdateID: 2,
+		Bids:         []market.PriceLevel{{Price: 100, Volume: 3, RawPrice: "100", RawVolume: "3"}},
+		Checksum:     int64(checksum(bids, asks)), // сумма до diff - не совпадет
+	}))
+	books := receive(t, out, 1)
+	if books[0].UpdateType != market.OrderBookUpdateTypeInvalidated || len(books[0].Bids) != 0 {
+		t.Fatalf("checksum mismatch published %s with %d bids, want empty invalidated book",
+			books[0].UpdateType, len(books[0].Bids))
+	}
+	if !s.IsStale("BTC/USDT") {
+		t.Fatal("book not stale after checksum mismatch")
+	}
+	select {
+	case <-requested:
+	case <-time.After(time.Second):
+		t.Fatal("snapshot not requested after checksum mismatch")
+	}
+
+	s.Process(snapshot(5))
+	if books := receive(t, out, 1); books[0].UpdateType != market.OrderBookUpdateTypeSnapshot || books[0].LastUpdateID != 5 {
+		t.Fatalf("after resync published %s %d, want snapshot 5", books[0].UpdateType, books[0].LastUpdateID)
+	}
+	waitResyncDone(t, s)
+	if s.IsStale("BTC/USDT") {
+		t.Fatal("book still stale after resync snapshot")
+	}
+}
@@ -2,7 +2,9 @@ package exchange
 
 import (
 	"daemon-go/internal/bus"
+	"daemon-go/internal/capture"
 	"daemon-go/internal/db"
+	"daemon-go/internal/market"
 	"daemon-go/internal/market/parsers"
 	"daemon-go/pkg/log"
 	"encoding/json"
@@ -23,7 +25,9 @@ type PoloniexAdapter struct {
 	logger         *log.Logger
 	parser         *parsers.PoloniexParser
 	messageBus     *bus.MessageBus
-	pairIDMap      map[string]int // symbol -> pairID маппинг
+	bookSync       *OrderBookSync  // контроль последовательности и checksum стакана
+	pairIDMap      map[string]int  // symbol -> pairID маппинг
+	userData       *userDataStream // приватный поток ордеров и балансов
 }
 
 // SubscribeMarkets для Poloniex с подробным логированием
@@ -46,10 +50,11 @@ func (a *PoloniexAdapter) SubscribeMarkets(pairs []string, marketType string, de
 		symbol = strings.ReplaceAll(strings.ToLower(symbol), "-", "")
 		a.logger.Debug("[POLONIEX_ADAPTER] Processing pair: %s -> symbol: %s", pair, symbol)
 
-		// Подписка на orderbook для Poloniex - используем /contractMarket/level2Depth5:{symbol}
+		// Подписка на orderbook для Poloniex - book_lv2 (снимок + diff с id/lastId и checksum)
 		subOrderbook := map[string]interface{}{
-			"command": "subscribe",
-			"channel": fmt.Sprintf("contractMarket/level2Depth5:%s", symbol),
+			"event":   "subscribe",
+			"channel": []string{"book_lv2"},
+			"symbols": []string{poloniexSymbol(pair)},
 		}
 		dataOrderbook, err := json.Marshal(subOrderbook)
 		if err != nil {
@@ -80,7 +85,43 @@ func (a *PoloniexAdapter) SubscribeMarkets(pairs []string, marketType string, de
 			return fmt.Errorf("PoloniexAdapter: ws ticker sub: %w", err)
 		}
 
-		a.logger.Info("[POLONIEX_ADAPTER] Successfully subscribed to %s (orderbook + ticker)", symbol)
+		// Подписка на сделки для Poloniex - канал trades
+		subTrade := map[string]interface{}{
+			"event":   "subscribe",
+			"channel": []string{"trades"},
+			"symbols": []string{poloniexSymbol(pair)},
+		}
+		dataTrade, err := json.Marshal(subTrade)
+		if err != nil {
+			a.logger.Error("[POLONIEX_ADAPTER] Failed to marshal trade subscription: %v", err)
+			return fmt.Errorf("PoloniexAdapter: marshal trade sub: %w", err)
+		}
+
+		a.logger.Debug("[POLONIEX_ADAPTER] Sending trade subscription: %s", string(dataTrade))
+		if err := a.ws.WriteMessage(1, dataTrade); err != nil {
+			a.logger.Error("[POLONIEX_ADAPTER] Failed to send trade subscription: %v", err)
+			return fmt.Errorf("PoloniexAdapter: ws trade sub: %w", err)
+		}
+
+		// Подписка на минутные свечи для Poloniex - канал candles_minute_1
+		subKline := map[string]interface{}{
+			"event":   "subscribe",
+			"channel": []string{"candles_minute_1"},
+			"symbols": []string{poloniexSymbol(pair)},
+		}
+		dataKline, err := json.Marshal(subKline)
+		if err != nil {
+			a.logger.Error("[POLONIEX_ADAPTER] Failed to marshal kline subscription: %v", err)
+			return fmt.Errorf("PoloniexAdapter: marshal kline sub: %w", err)
+		}
+
+		a.logger.Debug("[POLONIEX_ADAPTER] Sending kline subscription: %s", string(dataKline))
+		if err := a.ws.WriteMessage(1, dataKline); err != nil {
+			a.logger.Error("[POLONIEX_ADAPTER] Failed to send kline subscription: %v", err)
+			return fmt.Errorf("PoloniexAdapter: ws kline sub: %w", err)
+		}
+
+		a.logger.Info("[POLONIEX_ADAPTER] Successfully subscribed to %s (orderbook + ticker + trades + klines)", symbol)
 	}
 
 	a.logger.Info("[POLONIEX_ADAPTER] All subscriptions completed successfully")
@@ -103,8 +144,9 @@ func (a *PoloniexAdapter) UnsubscribeMarkets(pairs []string, marketType string,
 
 		// Отписка от orderbook для Poloniex
 		unsubOrderbook := map[string]interface{}{
-			"command": "unsubscribe",
-			"channel": fmt.Sprintf("contractMarket/level2Depth5:%s", symbol),
+			"event":   "unsubscribe",
+			"channel": []string{"book_lv2"},
+			"symbols": []string{poloniexSymbol(pair)},
 		}
 		dataOrderbook, err := json.Marshal(unsubOrderbook)
 		if err != nil {
@@ -131,6 +173,38 @@ func (a *PoloniexAdapter) UnsubscribeMarkets(pairs []string, marketType string,
 			return fmt.Errorf("PoloniexAdapter: ws ticker unsub: %w", err)
 		}
 
+		// Отписка от сделок для Poloniex
+		unsubTrade := map[string]interface{}{
+			"event":   "unsubscribe",
+			"channel": []string{"trades"},
+			"symbols": []string{poloniexSymbol(pair)},
+		}
+		dataTrade, err := json.Marshal(unsubTrade)
+		if err != nil {
+			a.logger.Error("[POLONIEX_ADAPTER] Failed to marshal trade unsubscription: %v", err)
+			return fmt.Errorf("PoloniexAdapter: marshal trade unsub: %w", err)
+		}
+		if err := a.ws.WriteMessage(1, dataTrade); err != nil {
+			a.logger.Error("[POLONIEX_ADAPTER] Failed to send trade unsubscription: %v", err)
+			return fmt.Errorf("PoloniexAdapter: ws trade unsub: %w", err)
+		}
+
+		// Отписка от свечей для Poloniex
+		unsubKline := map[string]interface{}{
+			"event":   "unsubscribe",
+			"channel": []string{"candles_minute_1"},
+			"symbols": []string{poloniexSymbol(pair)},
+		}
+		dataKline, err := json.Marshal(unsubKline)
+		if err != nil {
+			a.logger.Error("[POLONIEX_ADAPTER] Failed to marshal kline unsubscription: %v", err)
+			return fmt.Errorf("PoloniexAdapter: marshal kline unsub: %w", err)
+		}
+		if err := a.ws.WriteMessage(1, dataKline); err != nil {
+			a.logger.Error("[POLONIEX_ADAPTER] Failed to send kline unsubscription: %v", err)
+			return fmt.Errorf("PoloniexAdapter: ws kline unsub: %w", err)
+		}
+
 		a.logger.Info("[POLONIEX_ADAPTER] Successfully unsubscribed from %s", symbol)
 	}
 
@@ -146,7 +220,7 @@ func NewPoloniexAdapter(ex db.Exchange) *PoloniexAdapter {
 	}
 
 	logger := log.New("poloniex_adapter")
-	return &PoloniexAdapter{
+	a := &PoloniexAdapter{
 		exchange:   ex,
 		rest:       NewCexRestClient(ex.BaseUrl),
 		ws:         wsClient,
@@ -156,13 +230,46 @@ func NewPoloniexAdapter(ex db.Exchange) *PoloniexAdapter {
 		messageBus: bus.GetInstance(),
 		pairIDMap:  make(map[string]int),
 	}
+	a.bookSync = NewOrderBookSync("poloniex", nil, func(msg market.UnifiedMessage) {
+		a.messageBus.Publish("poloniex", msg)
+	})
+	a.bookSync.SetSnapshotRequester(a.requestOrderBookSnapshot)
+	return a
+}
+
+// requestOrderBookSnapshot переподписывается на book_lv2 символа: первое сообщение новой подписки -
+// снимок с id, к которому OrderBookSync привязывает буферизованные diff (REST снимок id не содержит)
+func (a *PoloniexAdapter) requestOrderBookSnapshot(symbol string) error {
+	if a.ws == nil || !a.ws.IsConnected() {
+		return fmt.Errorf("PoloniexAdapter: ws not connected")
+	}
+
+	for _, event := range []string{"unsubscribe", "subscribe"} {
+		data, err := json.Marshal(map[string]interface{}{
+			"event":   event,
+			"channel": []string{"book_lv2"},
+			"symbols": []string{poloniexSymbol(symbol)},
+		})
+		if err != nil {
+			return fmt.Errorf("PoloniexAdapter: marshal book_lv2 %s: %w", event, err)
+		}
+		if err := a.ws.WriteMessage(1, data); err != nil {
+			return fmt.Errorf("PoloniexAdapter: ws book_lv2 %s: %w", event, err)
+		}
+	}
+	return nil
+}
+
+// poloniexSymbol переводит пару в формат символа Poloniex (BTC_USDT)
+func poloniexSymbol(pair string) string {
+	return strings.Replace(unifySymbol(pair), "/", "_", 1)
 }
 
 func (a *PoloniexAdapter) Start() error {
 	a.logger.Info("[POLONIEX_ADAPTER] Starting adapter...")
 
-	// Ping REST API для проверки доступности
-	var result map[string]interface{}
+	// Ping REST API для проверки доступности (/markets возвращает массив пар)
+	var result []map[string]interface{}
 	err := a.rest.GetJSON("/markets", &result)
 	if err != nil {
 		a.logger.Error("[POLONIEX_ADAPTER] REST API ping failed: %v", err)
@@ -197,7 +304,7 @@ func (a *PoloniexAdapter) readLoopWithReconnect() {
 			return
 		}
 
-		_, message, err := a.ws.ReadMessage()
+		msgType, message, err := a.ws.ReadMessage()
 		if err != nil {
 			a.logger.Error("[POLONIEX_ADAPTER] Read error: %v, reconnecting...", err)
 			for {
@@ -208,6 +315,7 @@ func (a *PoloniexAdapter) readLoopWithReconnect() {
 				time.Sleep(3 * time.Second)
 				if err := a.ws.Reconnect(); err == nil {
 					a.logger.Info("[POLONIEX_ADAPTER] Reconnected, resubscribing...")
+					a.bookSync.Reset()
 					if err := a.SubscribeMarkets(a.lastPairs, a.lastMarketType, a.lastDepth); err != nil {
 						a.logger.Error("[POLONIEX_ADAPTER] Resubscribe error: %v", err)
 					}
@@ -218,6 +326,7 @@ func (a *PoloniexAdapter) readLoopWithReconnect() {
 			}
 			continue
 		}
+		capture.Record("poloniex", msgType, message)
 
 		// Логируем сырое сообщение для отладки
 		a.logger.Info("[POLONIEX_ADAPTER] RAW MESSAGE: %s", string(message))
@@ -241,7 +350,13 @@ func (a *PoloniexAdapter) readLoopWithReconnect() {
 				a.logger.Info("[POLONIEX_ADAPTER] PARSED MESSAGE: Type=%s, Symbol=%s, PairID=%d",
 					msg.MessageType, msg.Symbol, msg.PairID)
 
-				a.messageBus.Publish("poloniex", msg)
+				if msg.MessageType == market.MessageTypeOrderBook {
+					a.bookSync.Process(msg)
+				} else {
+					for _, m := range market.SplitTrades(msg) {
+						a.messageBus.Publish("poloniex", m)
+					}
+				}
 			}
 		}
 	}
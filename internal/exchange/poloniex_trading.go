@@ -0,0 +1,274 @@
+package exchange
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+
+	"daemon-go/internal/market"
+)
+
+// poloniexOrder - ордер Poloniex API v3
+type poloniexOrder struct {
+	ID             string `json:"id"`
+	ClientOrderID  string `json:"clientOrderId"`
+	Symbol         string `json:"symbol"`
+	State          string `json:"state"`
+	Type           string `json:"type"`
+	Side           string `json:"side"`
+	Price          string `json:"price"`
+	Quantity       string `json:"quantity"`
+	Amount         string `json:"amount"`
+	FilledQuantity string `json:"filledQuantity"`
+	FilledAmount   string `json:"filledAmount"`
+	AvgPrice       string `json:"avgPrice"`
+	CreateTime     int64  `json:"createTime"`
+	UpdateTime     int64  `json:"updateTime"`
+}
+
+// signedRequest выполняет подписанный запрос к Poloniex API v3:
+// подписывается "METHOD\n/path\nparams&signTimestamp=...", для тела используется requestBody=<json>
+func (a *PoloniexAdapter) signedRequest(method, path string, params url.Values, payload interface{}, result interface{}) error {
+	if err := checkCredentials("PoloniexAdapter", a.exchange.ApiKey, a.exchange.ApiSecret); err != nil {
+		return err
+	}
+
+	timestamp := nowMillis()
+
+	var body []byte
+	if payload != nil {
+		var err error
+		body, err = json.Marshal(payload)
+		if err != nil {
+			return fmt.Errorf("PoloniexAdapter: marshal: %w", err)
+		}
+	}
+	signPayload := poloniexSignPayload(method, path, params, body, timestamp)
+
+	requestPath := path
+	if len(params) > 0 {
+		requestPath += "?" + params.Encode()
+	}
+
+	headers := map[string]string{
+		"key":           a.exchange.ApiKey,
+		"signTimestamp": timestamp,
+		"signature":     signBase64(a.exchange.ApiSecret, signPayload),
+	}
+
+	respBody, status, err := a.rest.DoSigned(method, requestPath, body, headers)
+	if err != nil {
+		return fmt.Errorf("PoloniexAdapter: %s %s: %w", method, path, err)
+	}
+	if status != http.StatusOK {
+		var apiErr struct {
+			Code    int    `json:"code"`
+			Message string `json:"message"`
+		}
+		_ = json.Unmarshal(respBody, &apiErr)
+		return fmt.Errorf("PoloniexAdapter: %s %s: status %d, code %d: %s", method, path, status, apiErr.Code, apiErr.Message)
+	}
+	if result == nil {
+		return nil
+	}
+	return json.Unmarshal(respBody, result)
+}
+
+// poloniexSignPayload - строка подписи Poloniex: метод, путь и параметры запроса с requestBody
+// (тело JSON) и signTimestamp. Значения подписываются без URL-кодирования, ключи отсортированы
+func poloniexSignPayload(method, path string, params url.Values, body []byte, timestamp string) string {
+	signParams := url.Values{}
+	for k, v := range params {
+		signParams[k] = v
+	}
+	if body != nil {
+		signParams.Set("requestBody", string(body))
+	}
+	signParams.Set("signTimestamp", timestamp)
+
+	keys := make([]string, 0, len(signParams))
+	for k := range signParams {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	pairs := make([]string, 0, len(keys))
+	for _, k := range keys {
+		pairs = append(pairs, k+"="+signParams.Get(k))
+	}
+	return method + "\n" + path + "\n" + strings.Join(pairs, "&")
+}
+
+// PlaceOrder размещает лимитный или рыночный ордер.
+// Рыночная покупка на Poloniex задается суммой в quote валюте, поэтому для нее нужна оценочная цена
+func (a *PoloniexAdapter) PlaceOrder(req market.OrderRequest) (*market.Order, error) {
+	symbol, err := exchangeSymbol(req.Symbol, "_")
+	if err != nil {
+		return nil, fmt.Errorf("PoloniexAdapter: %w", err)
+	}
+
+	payload := map[string]string{
+		"symbol": symbol,
+		"side":   strings.ToUpper(string(req.Side)),
+		"type":   strings.ToUpper(string(req.OrderType)),
+	}
+	if req.ClientOrderID != "" {
+		payload["clientOrderId"] = req.ClientOrderID
+	}
+	switch {
+	case req.OrderType == market.OrderTypeLimit:
+		payload["price"] = formatFloat(req.Price)
+		payload["quantity"] = formatFloat(req.Volume)
+		payload["timeInForce"] = "GTC"
+	case req.Side == market.TradeSideBuy:
+		if req.Price <= 0 {
+			return nil, fmt.Errorf("PoloniexAdapter: market buy requires price to compute quote amount")
+		}
+		payload["amount"] = formatFloat(req.Volume * req.Price)
+	default:
+		payload["quantity"] = formatFloat(req.Volume)
+	}
+
+	var resp struct {
+		ID            string `json:"id"`
+		ClientOrderID string `json:"clientOrderId"`
+	}
+	if err := a.signedRequest(http.MethodPost, "/orders", nil, payload, &resp); err != nil {
+		return nil, err
+	}
+	a.logger.Info("[POLONIEX_ADAPTER] Order placed: %s %s %s id=%s", symbol, payload["side"], payload["type"], resp.ID)
+
+	return &market.Order{
+		Exchange:      "poloniex",
+		Symbol:        req.Symbol,
+		OrderID:       resp.ID,
+		ClientOrderID: resp.ClientOrderID,
+		Status:        market.OrderStatusNew,
+		Side:          req.Side,
+		OrderType:     req.OrderType,
+		Price:         req.Price,
+		Volume:        req.Volume,
+	}, nil
+}
+
+// CancelOrder отменяет ордер
+func (a *PoloniexAdapter) CancelOrder(symbol, orderID string) error {
+	return a.signedRequest(http.MethodDelete, "/orders/"+orderID, nil, nil, nil)
+}
+
+// GetOrder возвращает состояние ордера
+func (a *PoloniexAdapter) GetOrder(symbol, orderID string) (*market.Order, error) {
+	var resp poloniexOrder
+	if err := a.signedRequest(http.MethodGet, "/orders/"+orderID, nil, nil, &resp); err != nil {
+		return nil, err
+	}
+	return convertPoloniexOrder(resp, symbol), nil
+}
+
+// GetOpenOrders возвращает открытые ордера
+func (a *PoloniexAdapter) GetOpenOrders(symbol string) ([]market.Order, error) {
+	params := url.Values{}
+	if symbol != "" {
+		exSymbol, err := exchangeSymbol(symbol, "_")
+		if err != nil {
+			return nil, fmt.Errorf("PoloniexAdapter: %w", err)
+		}
+		params.Set("symbol", exSymbol)
+	}
+
+	var resp []poloniexOrder
+	if err := a.signedRequest(http.MethodGet, "/orders", params, nil, &resp); err != nil {
+		return nil, err
+	}
+
+	orders := make([]market.Order, 0, len(resp))
+	for _, o := range resp {
+		orders = append(orders, *convertPoloniexOrder(o, unifySymbol(o.Symbol)))
+	}
+	return orders, nil
+}
+
+// GetBalances возвращает балансы спотового аккаунта
+func (a *PoloniexAdapter) GetBalances() ([]market.Balance, error) {
+	var resp []struct {
+		AccountType string `json:"accountType"`
+		Balances    []struct {
+			Currency  string `json:"currency"`
+			Available string `json:"available"`
+			Hold      string `json:"hold"`
+		} `json:"balances"`
+	}
+	if err := a.signedRequest(http.MethodGet, "/accounts/balances", nil, nil, &resp); err != nil {
+		return nil, err
+	}
+
+	balances := make([]market.Balance, 0)
+	for _, account := range resp {
+		if account.AccountType != "" && !strings.EqualFold(account.AccountType, "SPOT") {
+			continue
+		}
+		for _, b := range account.Balances {
+			balance := market.Balance{
+				Asset:  b.Currency,
+				Free:   parseFloatString(b.Available),
+				Locked: parseFloatString(b.Hold),
+			}
+			if balance.Total() > 0 {
+				balances = append(balances, balance)
+			}
+		}
+	}
+	return balances, nil
+}
+
+// convertPoloniexOrder переводит ордер Poloniex в market.Order
+func convertPoloniexOrder(o poloniexOrder, symbol string) *market.Order {
+	filled := parseFloatString(o.FilledQuantity)
+	avg := parseFloatString(o.AvgPrice)
+	if avg == 0 {
+		avg = avgPrice(parseFloatString(o.FilledAmount), filled)
+	}
+	return &market.Order{
+		Exchange:      "poloniex",
+		Symbol:        symbol,
+		OrderID:       o.ID,
+		ClientOrderID: o.ClientOrderID,
+		Status:        poloniexOrderStatus(o.State),
+		Side:          market.TradeSide(strings.ToLower(o.Side)),
+		OrderType:     poloniexOrderType(o.Type),
+		Price:         parseFloatString(o.Price),
+		Volume:        parseFloatString(o.Quantity),
+		FilledVolume:  filled,
+		AvgPrice:      avg,
+		CreatedAt:     millisToTime(o.CreateTime),
+		UpdatedAt:     millisToTime(o.UpdateTime),
+	}
+}
+
+// poloniexOrderType переводит тип ордера Poloniex (LIMIT, LIMIT_MAKER, MARKET) в унифицированный
+func poloniexOrderType(t string) market.OrderType {
+	if strings.EqualFold(t, "MARKET") {
+		return market.OrderTypeMarket
+	}
+	return market.OrderTypeLimit
+}
+
+// poloniexOrderStatus переводит статус ордера Poloniex в унифицированный
+func poloniexOrderStatus(state string) market.OrderStatus {
+	switch state {
+	case "NEW", "PENDING_NEW":
+		return market.OrderStatusNew
+	case "PARTIALLY_FILLED", "PENDING_CANCEL":
+		return market.OrderStatusPartiallyFilled
+	case "FILLED":
+		return market.OrderStatusFilled
+	case "CANCELED", "PARTIALLY_CANCELED":
+		return market.OrderStatusCanceled
+	case "FAILED", "REJECTED":
+		return market.OrderStatusRejected
+	default:
+		return market.OrderStatusNew
+	}
+}
@@ -0,0 +1,215 @@
+package exchange
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"daemon-go/internal/market"
+)
+
+// poloniexPrivateWsURL - приватный WebSocket Poloniex v3
+const poloniexPrivateWsURL = "wss://ws.poloniex.com/ws/private"
+
+// poloniexPrivateMessage - сообщение приватного WebSocket Poloniex
+type poloniexPrivateMessage struct {
+	Event   string          `json:"event"`
+	Channel string          `json:"channel"`
+	Message string          `json:"message"`
+	Data    json.RawMessage `json:"data"`
+}
+
+// poloniexOrderUpdate - элемент канала orders
+type poloniexOrderUpdate struct {
+	Symbol         string `json:"symbol"`
+	Type           string `json:"type"`
+	Quantity       string `json:"quantity"`
+	OrderID        string `json:"orderId"`
+	TradeFee       string `json:"tradeFee"`
+	ClientOrderID  string `json:"clientOrderId"`
+	FeeCurrency    string `json:"feeCurrency"`
+	EventType      string `json:"eventType"` // place, trade, canceled
+	Side           string `json:"side"`
+	FilledQuantity string `json:"filledQuantity"`
+	FilledAmount   string `json:"filledAmount"`
+	State          string `json:"state"`
+	Price          string `json:"price"`
+	TradeQty       string `json:"tradeQty"`
+	TradePrice     string `json:"tradePrice"`
+	TradeID        string `json:"tradeId"`
+	Ts             int64  `json:"ts"`
+}
+
+// poloniexBalanceUpdate - элемент канала balances
+type poloniexBalanceUpdate struct {
+	AccountType string `json:"accountType"`
+	Currency    string `json:"currency"`
+	Available   string `json:"available"`
+	Hold        string `json:"hold"`
+	Ts          int64  `json:"ts"`
+}
+
+// StartUserData подключается к приватному WebSocket Poloniex и подписывается на orders и balances
+func (a *PoloniexAdapter) StartUserData() error {
+	if err := checkCredentials("PoloniexAdapter", a.exchange.ApiKey, a.exchange.ApiSecret); err != nil {
+		return err
+	}
+	if a.userData == nil {
+		stream := newUserDataStream("poloniex")
+		stream.dial = func() (string, error) { return poloniexPrivateWsURL, nil }
+		stream.login = a.userDataLogin
+		stream.handle = func(_ *CexWsClient, _ int, data []byte) ([]market.UnifiedMessage, error) {
+			return parsePoloniexUserData(data)
+		}
+		stream.ping = func() []byte { return []byte(`{"event":"ping"}`) }
+		stream.pingInterval = 20 * time.Second
+		a.userData = stream
+	}
+	return a.userData.start()
+}
+
+// StopUserData останавливает приватный поток
+func (a *PoloniexAdapter) StopUserData() error {
+	if a.userData == nil {
+		return nil
+	}
+	return a.userData.stopStream()
+}
+
+// userDataLogin подписывается на канал auth (base64 HMAC от "GET\n/ws\nsignTimestamp=..."),
+// дожидается ответа и подписывается на приватные каналы
+func (a *PoloniexAdapter) userDataLogin(ws *CexWsClient) error {
+	timestamp := nowMillis()
+	auth := map[string]interface{}{
+		"event":   "subscribe",
+		"channel": []string{"auth"},
+		"params": map[string]string{
+			"key":              a.exchange.ApiKey,
+			"signTimestamp":    timestamp,
+			"signatureMethod":  "HmacSHA256",
+			"signatureVersion": "2",
+			"signature":        signBase64(a.exchange.ApiSecret, "GET\n/ws\nsignTimestamp="+timestamp),
+		},
+	}
+	if err := writeJSON(ws, auth); err != nil {
+		return err
+	}
+
+	for {
+		_, data, err := ws.ReadMessage()
+		if err != nil {
+			return err
+		}
+		var msg poloniexPrivateMessage
+		if err := json.Unmarshal(data, &msg); err != nil {
+			return err
+		}
+		if msg.Channel != "auth" {
+			continue
+		}
+		var result struct {
+			Success bool   `json:"success"`
+			Message string `json:"message"`
+		}
+		if err := json.Unmarshal(msg.Data, &result); err != nil {
+			return err
+		}
+		if !result.Success {
+			return fmt.Errorf("auth failed: %s", result.Message)
+		}
+		break
+	}
+
+	if err := writeJSON(ws, map[string]interface{}{
+		"event":   "subscribe",
+		"channel": []string{"orders"},
+		"symbols": []string{"all"},
+	}); err != nil {
+		return err
+	}
+	return writeJSON(ws, map[string]interface{}{
+		"event":   "subscribe",
+		"channel": []string{"balances"},
+	})
+}
+
+// parsePoloniexUserData разбирает сообщение приватного WebSocket Poloniex
+func parsePoloniexUserData(data []byte) ([]market.UnifiedMessage, error) {
+	var msg poloniexPrivateMessage
+	if err := json.Unmarshal(data, &msg); err != nil {
+		return nil, fmt.Errorf("poloniex user data: %w", err)
+	}
+	if msg.Event == "error" {
+		return nil, fmt.Errorf("poloniex user data: %s", msg.Message)
+	}
+	if msg.Event != "" {
+		return nil, nil
+	}
+
+	var result []market.UnifiedMessage
+	switch msg.Channel {
+	case "orders":
+		var orders []poloniexOrderUpdate
+		if err := json.Unmarshal(msg.Data, &orders); err != nil {
+			return nil, fmt.Errorf("poloniex orders: %w", err)
+		}
+		for _, o := range orders {
+			result = append(result, orderEventMessage(convertPoloniexOrderUpdate(o)))
+		}
+
+	case "balances":
+		var updates []poloniexBalanceUpdate
+		if err := json.Unmarshal(msg.Data, &updates); err != nil {
+			return nil, fmt.Errorf("poloniex balances: %w", err)
+		}
+		var ts int64
+		balances := make([]market.Balance, 0, len(updates))
+		for _, b := range updates {
+			if b.AccountType != "" && b.AccountType != "SPOT" {
+				continue
+			}
+			balances = append(balances, market.Balance{
+				Asset:  b.Currency,
+				Free:   parseFloatString(b.Available),
+				Locked: parseFloatString(b.Hold),
+			})
+			if b.Ts > ts {
+				ts = b.Ts
+			}
+		}
+		if len(balances) > 0 {
+			result = append(result, balanceMessage(millisToTime(ts), balances, updates))
+		}
+	}
+	return result, nil
+}
+
+// convertPoloniexOrderUpdate переводит событие канала orders в UnifiedOrderEvent
+func convertPoloniexOrderUpdate(o poloniexOrderUpdate) market.UnifiedOrderEvent {
+	volume := parseFloatString(o.Quantity)
+	filled := parseFloatString(o.FilledQuantity)
+	event := market.UnifiedOrderEvent{
+		Symbol:          unifySymbol(o.Symbol),
+		Timestamp:       millisToTime(o.Ts),
+		OrderID:         o.OrderID,
+		ClientOrderID:   o.ClientOrderID,
+		Status:          poloniexOrderStatus(o.State),
+		Side:            market.TradeSide(strings.ToLower(o.Side)),
+		OrderType:       poloniexOrderType(o.Type),
+		Price:           parseFloatString(o.Price),
+		Volume:          volume,
+		FilledVolume:    filled,
+		RemainingVolume: remaining(volume, filled),
+		AvgPrice:        avgPrice(parseFloatString(o.FilledAmount), filled),
+		FeeCurrency:     o.FeeCurrency,
+		Raw:             o,
+	}
+	if o.EventType == "trade" {
+		event.TradeID = o.TradeID
+		event.LastFillPrice = parseFloatString(o.TradePrice)
+		event.LastFillVolume = parseFloatString(o.TradeQty)
+		event.LastFillFee = parseFloatString(o.TradeFee)
+	}
+	return event
+}
@@ -0,0 +1,116 @@
+package exchange
+
+import (
+	"net/url"
+	"testing"
+)
+
+// HMAC-SHA256 в hex и base64: тестовый вектор RFC 4231 (test case 2)
+// и пример подписи из документации Binance (SIGNED endpoint, query string)
+func TestSignHMAC(t *testing.T) {
+	tests := []struct {
+		name    string
+		sign    func(secret, payload string) string
+		secret  string
+		payload string
+		want    string
+	}{
+		{
+			name: "rfc4231 hex", sign: signHex,
+			secret: "Jefe", payload: "what do ya want for nothing?",
+			want: "5bdcc146bf60754e6a042426089575c75a003f089d2739839dec58b964ec3843",
+		},
+		{
+			name: "rfc4231 base64", sign: signBase64,
+			secret: "Jefe", payload: "what do ya want for nothing?",
+			want: "W9zBRr9gdU5qBCQmCJV1x1oAPwidJzmDnexYuWTsOEM=",
+		},
+		{
+			name: "binance documented example", sign: signHex,
+			secret:  "NhqPtmdSJYdKjVHjA7PZj4Mge3R5YNiP1e3UZjInClVN65XAbvqqM6A7H5fATj0j",
+			payload: "symbol=LTCBTC&side=BUY&type=LIMIT&timeInForce=GTC&quantity=1&price=0.1&recvWindow=5000&timestamp=1499827319559",
+			want:    "c8db56825ae71d6d79447849e617115f4a920fa2acdcab2b053c4b2838bd6b71",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.sign(tt.secret, tt.payload); got != tt.want {
+				t.Fatalf("signature %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+// Строки подписи бирж для примеров запросов из документации
+func TestSignPayloads(t *testing.T) {
+	tests := []struct {
+		name string
+		got  string
+		want string
+	}{
+		{
+			name: "bybit get",
+			got:  bybitSignPayload("1658384314791", "XXXXXXXXXX", "5000", "category=option&symbol=BTC-29JUL22-25000-C"),
+			want: "1658384314791XXXXXXXXXX5000category=option&symbol=BTC-29JUL22-25000-C",
+		},
+		{
+			name: "bybit post",
+			got:  bybitSignPayload("1658385579423", "XXXXXXXXXX", "5000", `{"category":"spot","symbol":"BTCUSDT"}`),
+			want: `1658385579423XXXXXXXXXX5000{"category":"spot","symbol":"BTCUSDT"}`,
+		},
+		{
+			name: "kucoin get",
+			got:  kucoinSignPayload("1547015186532", "GET", "/api/v1/deposit-addresses?currency=BTC", ""),
+			want: "1547015186532GET/api/v1/deposit-addresses?currency=BTC",
+		},
+		{
+			name: "kucoin post",
+			got:  kucoinSignPayload("1547015186532", "POST", "/api/v1/deposit-addresses", `{"currency":"BTC"}`),
+			want: `1547015186532POST/api/v1/deposit-addresses{"currency":"BTC"}`,
+		},
+		{
+			name: "htx get",
+			got: htxSignPayload("GET", "API.HUOBI.PRO", "/v1/order/orders", url.Values{
+				"AccessKeyId":      {"e2xxxxxx-99xxxxxx-84xxxxxx-7xxxx"},
+				"SignatureMethod":  {"HmacSHA256"},
+				"SignatureVersion": {"2"},
+				"Timestamp":        {"2017-05-11T15:19:30"},
+				"order-id":         {"1234567890"},
+			}.Encode()),
+			want: "GET\napi.huobi.pro\n/v1/order/orders\n" +
+				"AccessKeyId=e2xxxxxx-99xxxxxx-84xxxxxx-7xxxx&SignatureMethod=HmacSHA256&SignatureVersion=2&Timestamp=2017-05-11T15%3A19%3A30&order-id=1234567890",
+		},
+		{
+			name: "coinex get",
+			got:  coinexSignPayload("GET", "/v2/spot/pending-order?market=BTCUSDT&market_type=SPOT&side=buy&page=1&limit=10", "", "1700490703564"),
+			want: "GET/v2/spot/pending-order?market=BTCUSDT&market_type=SPOT&side=buy&page=1&limit=10" + "1700490703564",
+		},
+		{
+			name: "coinex post",
+			got:  coinexSignPayload("POST", "/v2/spot/order", `{"market":"BTCUSDT"}`, "1700490703564"),
+			want: `POST/v2/spot/order{"market":"BTCUSDT"}1700490703564`,
+		},
+		{
+			name: "poloniex get",
+			got:  poloniexSignPayload("GET", "/orders", url.Values{"symbol": {"ETH_USDT"}, "limit": {"5"}}, nil, "1659259836247"),
+			want: "GET\n/orders\nlimit=5&signTimestamp=1659259836247&symbol=ETH_USDT",
+		},
+		{
+			name: "poloniex post",
+			got:  poloniexSignPayload("POST", "/orders", nil, []byte(`{"symbol":"ETH_USDT","type":"LIMIT"}`), "1659259836247"),
+			want: "POST\n/orders\nrequestBody={\"symbol\":\"ETH_USDT\",\"type\":\"LIMIT\"}&signTimestamp=1659259836247",
+		},
+		{
+			name: "poloniex values unescaped",
+			got:  poloniexSignPayload("DELETE", "/orders/cancelByIds", url.Values{"clientOrderIds": {"a b,c"}}, nil, "1"),
+			want: "DELETE\n/orders/cancelByIds\nclientOrderIds=a b,c&signTimestamp=1",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.got != tt.want {
+				t.Fatalf("payload\n%q\nwant\n%q", tt.got, tt.want)
+			}
+		})
+	}
+}
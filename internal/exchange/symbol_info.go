@@ -0,0 +1,161 @@
+package exchange
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"daemon-go/internal/market"
+	"daemon-go/pkg/log"
+)
+
+// SymbolInfoCache - кэш торговых правил символов по биржам. Правила загружаются через
+// REST exchange-info каждой биржи при старте и раз в refreshInterval
+type SymbolInfoCache struct {
+	mu              sync.RWMutex
+	sources         map[string]SymbolRulesSource             // [exchange]
+	rules           map[string]map[string]market.SymbolRules // [exchange][symbol]
+	loadedAt        map[string]time.Time                     // [exchange]
+	refreshInterval time.Duration
+	stopChan        chan struct{}
+	logger          *log.Logger
+
+	refreshes int64
+	errors    int64
+}
+
+// NewSymbolInfoCache создает кэш правил; refreshInterval - период перезагрузки (0 - только при старте)
+func NewSymbolInfoCache(refreshInterval time.Duration) *SymbolInfoCache {
+	return &SymbolInfoCache{
+		sources:         make(map[string]SymbolRulesSource),
+		rules:           make(map[string]map[string]market.SymbolRules),
+		loadedAt:        make(map[string]time.Time),
+		refreshInterval: refreshInterval,
+		logger:          log.New("symbol_info"),
+	}
+}
+
+// Register добавляет биржу
+func (c *SymbolInfoCache) Register(exchange string, source SymbolRulesSource) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.sources[strings.ToLower(exchange)] = source
+}
+
+// Start загружает правила всех бирж и запускает периодическую перезагрузку
+func (c *SymbolInfoCache) Start() error {
+	c.mu.Lock()
+	if c.stopChan != nil {
+		c.mu.Unlock()
+		return fmt.Errorf("symbol info cache already started")
+	}
+	c.stopChan = make(chan struct{})
+	stop := c.stopChan
+	c.mu.Unlock()
+
+	err := c.RefreshAll()
+	if c.refreshInterval > 0 {
+		go c.refreshLoop(stop)
+	}
+	return err
+}
+
+// Stop останавливает перезагрузку
+func (c *SymbolInfoCache) Stop() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.stopChan != nil {
+		close(c.stopChan)
+		c.stopChan = nil
+	}
+}
+
+// refreshLoop периодически перезагружает правила
+func (c *SymbolInfoCache) refreshLoop(stop chan struct{}) {
+	ticker := time.NewTicker(c.refreshInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			_ = c.RefreshAll()
+		}
+	}
+}
+
+// Refresh загружает правила биржи и заменяет ими текущие
+func (c *SymbolInfoCache) Refresh(exchange string) error {
+	exchange = strings.ToLower(exchange)
+	c.mu.RLock()
+	source, ok := c.sources[exchange]
+	c.mu.RUnlock()
+	if !ok {
+		return fmt.Errorf("symbol info: exchange %s not registered", exchange)
+	}
+
+	list, err := source.GetSymbolRules()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err != nil {
+		c.errors++
+		c.logger.Error("[SYMBOL_INFO] %s: load rules failed: %v", exchange, err)
+		return err
+	}
+
+	rules := make(map[string]market.SymbolRules, len(list))
+	for _, r := range list {
+		rules[r.Symbol] = r
+	}
+	c.rules[exchange] = rules
+	c.loadedAt[exchange] = time.Now()
+	c.refreshes++
+	c.logger.Info("[SYMBOL_INFO] %s: loaded rules for %d symbols", exchange, len(rules))
+	return nil
+}
+
+// RefreshAll перезагружает правила всех бирж; возвращает первую ошибку
+func (c *SymbolInfoCache) RefreshAll() error {
+	c.mu.RLock()
+	exchanges := make([]string, 0, len(c.sources))
+	for exchange := range c.sources {
+		exchanges = append(exchanges, exchange)
+	}
+	c.mu.RUnlock()
+
+	var firstErr error
+	for _, exchange := range exchanges {
+		if err := c.Refresh(exchange); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// Rules возвращает правила символа на бирже; ok=false, если правила не загружены
+func (c *SymbolInfoCache) Rules(exchange, symbol string) (market.SymbolRules, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	r, ok := c.rules[strings.ToLower(exchange)][strings.ToUpper(symbol)]
+	return r, ok
+}
+
+// GetStats возвращает состояние кэша правил
+func (c *SymbolInfoCache) GetStats() map[string]interface{} {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	exchanges := make(map[string]interface{}, len(c.rules))
+	for exchange, rules := range c.rules {
+		exchanges[exchange] = map[string]interface{}{
+			"symbols":   len(rules),
+			"loaded_at": c.loadedAt[exchange],
+		}
+	}
+	return map[string]interface{}{
+		"exchanges": exchanges,
+		"refreshes": c.refreshes,
+		"errors":    c.errors,
+	}
+}
@@ -0,0 +1,268 @@
+package exchange
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"daemon-go/internal/market"
+)
+
+// SymbolRulesSource - загрузка торговых правил всех спотовых символов биржи
+type SymbolRulesSource interface {
+	GetSymbolRules() ([]market.SymbolRules, error)
+}
+
+// Проверка реализации интерфейса адаптерами
+var (
+	_ SymbolRulesSource = (*BinanceAdapter)(nil)
+	_ SymbolRulesSource = (*BybitAdapter)(nil)
+	_ SymbolRulesSource = (*KucoinAdapter)(nil)
+	_ SymbolRulesSource = (*HtxAdapter)(nil)
+	_ SymbolRulesSource = (*CoinexAdapter)(nil)
+	_ SymbolRulesSource = (*PoloniexAdapter)(nil)
+)
+
+// newSymbolRules заполняет общие поля правил; точность выводится из шагов
+func newSymbolRules(exchange, base, quote string, tickSize, lotSize float64, trading bool) market.SymbolRules {
+	base, quote = strings.ToUpper(base), strings.ToUpper(quote)
+	return market.SymbolRules{
+		Exchange:          exchange,
+		Symbol:            base + "/" + quote,
+		BaseCurrency:      base,
+		QuoteCurrency:     quote,
+		TickSize:          tickSize,
+		LotSize:           lotSize,
+		PricePrecision:    market.PrecisionFromStep(tickSize),
+		QuantityPrecision: market.PrecisionFromStep(lotSize),
+		Trading:           trading,
+		UpdatedAt:         time.Now(),
+	}
+}
+
+// GetSymbolRules загружает правила символов Binance (/api/v3/exchangeInfo):
+// фильтры PRICE_FILTER, LOT_SIZE и NOTIONAL (MIN_NOTIONAL в старом формате)
+func (a *BinanceAdapter) GetSymbolRules() ([]market.SymbolRules, error) {
+	var resp struct {
+		Symbols []struct {
+			Symbol     string `json:"symbol"`
+			Status     string `json:"status"`
+			BaseAsset  string `json:"baseAsset"`
+			QuoteAsset string `json:"quoteAsset"`
+			Filters    []struct {
+				FilterType  string `json:"filterType"`
+				TickSize    string `json:"tickSize"`
+				StepSize    string `json:"stepSize"`
+				MinQty      string `json:"minQty"`
+				MaxQty      string `json:"maxQty"`
+				MinNotional string `json:"minNotional"`
+			} `json:"filters"`
+		} `json:"symbols"`
+	}
+	if err := a.rest.GetJSON("/api/v3/exchangeInfo", &resp); err != nil {
+		return nil, fmt.Errorf("BinanceAdapter: exchange info: %w", err)
+	}
+
+	rules := make([]market.SymbolRules, 0, len(resp.Symbols))
+	for _, s := range resp.Symbols {
+		r := newSymbolRules("binance", s.BaseAsset, s.QuoteAsset, 0, 0, s.Status == "TRADING")
+		for _, f := range s.Filters {
+			switch f.FilterType {
+			case "PRICE_FILTER":
+				r.TickSize = parseFloatString(f.TickSize)
+				r.PricePrecision = market.PrecisionFromStep(r.TickSize)
+			case "LOT_SIZE":
+				r.LotSize = parseFloatString(f.StepSize)
+				r.QuantityPrecision = market.PrecisionFromStep(r.LotSize)
+				r.MinQty = parseFloatString(f.MinQty)
+				r.MaxQty = parseFloatString(f.MaxQty)
+			case "NOTIONAL", "MIN_NOTIONAL":
+				r.MinNotional = parseFloatString(f.MinNotional)
+			}
+		}
+		rules = append(rules, r)
+	}
+	return rules, nil
+}
+
+// GetSymbolRules загружает правила символов Bybit (/v5/market/instruments-info, category=spot)
+func (a *BybitAdapter) GetSymbolRules() ([]market.SymbolRules, error) {
+	var resp struct {
+		RetCode int    `json:"retCode"`
+		RetMsg  string `json:"retMsg"`
+		Result  struct {
+			List []struct {
+				Symbol        string `json:"symbol"`
+				BaseCoin      string `json:"baseCoin"`
+				QuoteCoin     string `json:"quoteCoin"`
+				Status        string `json:"status"`
+				LotSizeFilter struct {
+					BasePrecision string `json:"basePrecision"`
+					MinOrderQty   string `json:"minOrderQty"`
+					MaxOrderQty   string `json:"maxOrderQty"`
+					MinOrderAmt   string `json:"minOrderAmt"`
+				} `json:"lotSizeFilter"`
+				PriceFilter struct {
+					TickSize string `json:"tickSize"`
+				} `json:"priceFilter"`
+			} `json:"list"`
+		} `json:"result"`
+	}
+	if err := a.rest.GetJSON("/v5/market/instruments-info?category=spot", &resp); err != nil {
+		return nil, fmt.Errorf("BybitAdapter: instruments info: %w", err)
+	}
+	if resp.RetCode != 0 {
+		return nil, fmt.Errorf("BybitAdapter: instruments info: %d %s", resp.RetCode, resp.RetMsg)
+	}
+
+	rules := make([]market.SymbolRules, 0, len(resp.Result.List))
+	for _, s := range resp.Result.List {
+		r := newSymbolRules("bybit", s.BaseCoin, s.QuoteCoin,
+			parseFloatString(s.PriceFilter.TickSize), parseFloatString(s.LotSizeFilter.BasePrecision),
+			s.Status == "Trading")
+		r.MinQty = parseFloatString(s.LotSizeFilter.MinOrderQty)
+		r.MaxQty = parseFloatString(s.LotSizeFilter.MaxOrderQty)
+		r.MinNotional = parseFloatString(s.LotSizeFilter.MinOrderAmt)
+		rules = append(rules, r)
+	}
+	return rules, nil
+}
+
+// GetSymbolRules загружает правила символов KuCoin (/api/v2/symbols)
+func (a *KucoinAdapter) GetSymbolRules() ([]market.SymbolRules, error) {
+	var resp struct {
+		Code string `json:"code"`
+		Data []struct {
+			Symbol         string `json:"symbol"`
+			BaseCurrency   string `json:"baseCurrency"`
+			QuoteCurrency  string `json:"quoteCurrency"`
+			BaseMinSize    string `json:"baseMinSize"`
+			BaseMaxSize    string `json:"baseMaxSize"`
+			BaseIncrement  string `json:"baseIncrement"`
+			PriceIncrement string `json:"priceIncrement"`
+			MinFunds       string `json:"minFunds"`
+			EnableTrading  bool   `json:"enableTrading"`
+		} `json:"data"`
+	}
+	if err := a.rest.GetJSON("/api/v2/symbols", &resp); err != nil {
+		return nil, fmt.Errorf("KucoinAdapter: symbols: %w", err)
+	}
+	if resp.Code != "200000" {
+		return nil, fmt.Errorf("KucoinAdapter: symbols: code %s", resp.Code)
+	}
+
+	rules := make([]market.SymbolRules, 0, len(resp.Data))
+	for _, s := range resp.Data {
+		r := newSymbolRules("kucoin", s.BaseCurrency, s.QuoteCurrency,
+			parseFloatString(s.PriceIncrement), parseFloatString(s.BaseIncrement), s.EnableTrading)
+		r.MinQty = parseFloatString(s.BaseMinSize)
+		r.MaxQty = parseFloatString(s.BaseMaxSize)
+		r.MinNotional = parseFloatString(s.MinFunds)
+		rules = append(rules, r)
+	}
+	return rules, nil
+}
+
+// GetSymbolRules загружает правила символов HTX (/v1/common/symbols); шаги задаются точностью
+func (a *HtxAdapter) GetSymbolRules() ([]market.SymbolRules, error) {
+	var resp struct {
+		Status string `json:"status"`
+		ErrMsg string `json:"err-msg"`
+		Data   []struct {
+			BaseCurrency          string  `json:"base-currency"`
+			QuoteCurrency         string  `json:"quote-currency"`
+			PricePrecision        int     `json:"price-precision"`
+			AmountPrecision       int     `json:"amount-precision"`
+			State                 string  `json:"state"`
+			MinOrderAmt           float64 `json:"min-order-amt"`
+			MaxOrderAmt           float64 `json:"max-order-amt"`
+			LimitOrderMinOrderAmt float64 `json:"limit-order-min-order-amt"`
+			MinOrderValue         float64 `json:"min-order-value"`
+		} `json:"data"`
+	}
+	if err := a.rest.GetJSON("/v1/common/symbols", &resp); err != nil {
+		return nil, fmt.Errorf("HtxAdapter: symbols: %w", err)
+	}
+	if resp.Status != "ok" {
+		return nil, fmt.Errorf("HtxAdapter: symbols: %s", resp.ErrMsg)
+	}
+
+	rules := make([]market.SymbolRules, 0, len(resp.Data))
+	for _, s := range resp.Data {
+		r := newSymbolRules("htx", s.BaseCurrency, s.QuoteCurrency,
+			market.StepFromPrecision(s.PricePrecision), market.StepFromPrecision(s.AmountPrecision),
+			s.State == "online")
+		r.MinQty = s.MinOrderAmt
+		if s.LimitOrderMinOrderAmt > r.MinQty {
+			r.MinQty = s.LimitOrderMinOrderAmt
+		}
+		r.MaxQty = s.MaxOrderAmt
+		r.MinNotional = s.MinOrderValue
+		rules = append(rules, r)
+	}
+	return rules, nil
+}
+
+// GetSymbolRules загружает правила рынков CoinEx (/v2/spot/market); шаги задаются точностью
+func (a *CoinexAdapter) GetSymbolRules() ([]market.SymbolRules, error) {
+	var resp struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+		Data    []struct {
+			Market            string `json:"market"`
+			BaseCcy           string `json:"base_ccy"`
+			QuoteCcy          string `json:"quote_ccy"`
+			BaseCcyPrecision  int    `json:"base_ccy_precision"`
+			QuoteCcyPrecision int    `json:"quote_ccy_precision"`
+			MinAmount         string `json:"min_amount"`
+			Status            string `json:"status"`
+		} `json:"data"`
+	}
+	if err := a.rest.GetJSON("/v2/spot/market", &resp); err != nil {
+		return nil, fmt.Errorf("CoinexAdapter: markets: %w", err)
+	}
+	if resp.Code != 0 {
+		return nil, fmt.Errorf("CoinexAdapter: markets: code %d: %s", resp.Code, resp.Message)
+	}
+
+	rules := make([]market.SymbolRules, 0, len(resp.Data))
+	for _, s := range resp.Data {
+		r := newSymbolRules("coinex", s.BaseCcy, s.QuoteCcy,
+			market.StepFromPrecision(s.QuoteCcyPrecision), market.StepFromPrecision(s.BaseCcyPrecision),
+			s.Status == "online")
+		r.MinQty = parseFloatString(s.MinAmount)
+		rules = append(rules, r)
+	}
+	return rules, nil
+}
+
+// GetSymbolRules загружает правила символов Poloniex (/markets); шаги задаются масштабом
+func (a *PoloniexAdapter) GetSymbolRules() ([]market.SymbolRules, error) {
+	var resp []struct {
+		Symbol            string `json:"symbol"`
+		BaseCurrencyName  string `json:"baseCurrencyName"`
+		QuoteCurrencyName string `json:"quoteCurrencyName"`
+		State             string `json:"state"`
+		SymbolTradeLimit  struct {
+			PriceScale    int    `json:"priceScale"`
+			QuantityScale int    `json:"quantityScale"`
+			MinQuantity   string `json:"minQuantity"`
+			MinAmount     string `json:"minAmount"`
+		} `json:"symbolTradeLimit"`
+	}
+	if err := a.rest.GetJSON("/markets", &resp); err != nil {
+		return nil, fmt.Errorf("PoloniexAdapter: markets: %w", err)
+	}
+
+	rules := make([]market.SymbolRules, 0, len(resp))
+	for _, s := range resp {
+		limit := s.SymbolTradeLimit
+		r := newSymbolRules("poloniex", s.BaseCurrencyName, s.QuoteCurrencyName,
+			market.StepFromPrecision(limit.PriceScale), market.StepFromPrecision(limit.QuantityScale),
+			s.State == "NORMAL")
+		r.MinQty = parseFloatString(limit.MinQuantity)
+		r.MinNotional = parseFloatString(limit.MinAmount)
+		rules = append(rules, r)
+	}
+	return rules, nil
+}
@@ -0,0 +1,188 @@
+package exchange
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"daemon-go/internal/market"
+)
+
+// TradingAdapter - интерфейс торговых операций через подписанные REST запросы.
+// Символы передаются в унифицированном формате (BTC/USDT)
+type TradingAdapter interface {
+	ExchangeName() string
+	PlaceOrder(req market.OrderRequest) (*market.Order, error)
+	CancelOrder(symbol, orderID string) error
+	GetOrder(symbol, orderID string) (*market.Order, error)
+	GetOpenOrders(symbol string) ([]market.Order, error)
+	GetBalances() ([]market.Balance, error)
+}
+
+// Проверка реализации интерфейса адаптерами
+var (
+	_ TradingAdapter = (*BinanceAdapter)(nil)
+	_ TradingAdapter = (*BybitAdapter)(nil)
+	_ TradingAdapter = (*KucoinAdapter)(nil)
+	_ TradingAdapter = (*HtxAdapter)(nil)
+	_ TradingAdapter = (*CoinexAdapter)(nil)
+	_ TradingAdapter = (*PoloniexAdapter)(nil)
+)
+
+// ErrSymbolRequired - биржа возвращает открытые ордера только по символу: GetOpenOrders("") не поддерживается
+var ErrSymbolRequired = errors.New("symbol is required")
+
+// OpenOrdersLister - источник открытых ордеров аккаунта (TradingAdapter)
+type OpenOrdersLister interface {
+	GetOpenOrders(symbol string) ([]market.Order, error)
+}
+
+// ListOpenOrders возвращает открытые ордера аккаунта одним запросом по всем символам.
+// На биржах, где символ обязателен (ErrSymbolRequired), ордера запрашиваются по каждому из symbols -
+// символам, по которым известны открытые ордера (журнал ордеров). Ошибка по символу не прерывает
+// запросы остальных: возвращаются найденные ордера и объединенная ошибка
+func ListOpenOrders(lister OpenOrdersLister, symbols []string) ([]market.Order, error) {
+	orders, err := lister.GetOpenOrders("")
+	if err == nil || !errors.Is(err, ErrSymbolRequired) {
+		return orders, err
+	}
+
+	orders = nil
+	var errs []error
+	seen := make(map[string]bool, len(symbols))
+	for _, symbol := range symbols {
+		if seen[symbol] {
+			continue
+		}
+		seen[symbol] = true
+		open, err := lister.GetOpenOrders(symbol)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", symbol, err))
+			continue
+		}
+		orders = append(orders, open...)
+	}
+	return orders, errors.Join(errs...)
+}
+
+// recvWindow - допустимое окно времени для подписанных запросов (мс)
+const recvWindow = 5000
+
+// hmacSHA256 вычисляет HMAC-SHA256 подпись
+func hmacSHA256(secret, payload string) []byte {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(payload))
+	return mac.Sum(nil)
+}
+
+// signHex возвращает HMAC-SHA256 подпись в hex (Binance, Bybit, CoinEx)
+func signHex(secret, payload string) string {
+	return hex.EncodeToString(hmacSHA256(secret, payload))
+}
+
+// signBase64 возвращает HMAC-SHA256 подпись в base64 (KuCoin, HTX, Poloniex)
+func signBase64(secret, payload string) string {
+	return base64.StdEncoding.EncodeToString(hmacSHA256(secret, payload))
+}
+
+// nowMillis возвращает текущее время в миллисекундах
+func nowMillis() string {
+	return strconv.FormatInt(time.Now().UnixMilli(), 10)
+}
+
+// formatFloat форматирует число без экспоненты и лишних нулей
+func formatFloat(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
+
+// parseFloatString разбирает число из строки, пустая строка дает 0
+func parseFloatString(s string) float64 {
+	if s == "" {
+		return 0
+	}
+	v, _ := strconv.ParseFloat(s, 64)
+	return v
+}
+
+// millisToTime конвертирует миллисекунды в time.Time
+func millisToTime(ms int64) time.Time {
+	if ms == 0 {
+		return time.Time{}
+	}
+	return time.UnixMilli(ms)
+}
+
+// splitSymbol разбивает унифицированный символ BTC/USDT на базовую и котируемую валюты
+func splitSymbol(symbol string) (string, string, error) {
+	parts := strings.Split(strings.ToUpper(symbol), "/")
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return "", "", fmt.Errorf("invalid symbol %q, expected BASE/QUOTE", symbol)
+	}
+	return parts[0], parts[1], nil
+}
+
+// exchangeSymbol переводит унифицированный символ в формат биржи: base+sep+quote
+func exchangeSymbol(symbol, sep string) (string, error) {
+	base, quote, err := splitSymbol(symbol)
+	if err != nil {
+		return "", err
+	}
+	return base + sep + quote, nil
+}
+
+// knownQuotes - котируемые валюты для разбора символов без разделителя
+var knownQuotes = []string{"USDT", "USDC", "FDUSD", "TUSD", "BUSD", "USD", "EUR", "BTC", "ETH", "BNB", "TRY"}
+
+// unifySymbol переводит символ биржи (BTCUSDT, BTC-USDT, btc_usdt) в формат BTC/USDT
+func unifySymbol(raw string) string {
+	s := strings.ToUpper(raw)
+	for _, sep := range []string{"-", "_", "/"} {
+		if strings.Contains(s, sep) {
+			return strings.Replace(s, sep, "/", 1)
+		}
+	}
+	for _, quote := range knownQuotes {
+		if strings.HasSuffix(s, quote) && len(s) > len(quote) {
+			return s[:len(s)-len(quote)] + "/" + quote
+		}
+	}
+	return s
+}
+
+// checkCredentials проверяет наличие ключей API
+func checkCredentials(name, apiKey, apiSecret string) error {
+	if apiKey == "" || apiSecret == "" {
+		return fmt.Errorf("%s: api credentials not configured", name)
+	}
+	return nil
+}
+
+// avgPrice считает среднюю цену исполнения по объему и сумме в quote валюте
+func avgPrice(filledQuote, filledVolume float64) float64 {
+	if filledVolume <= 0 {
+		return 0
+	}
+	return filledQuote / filledVolume
+}
+
+// resolveFillStatus вычисляет статус для бирж, не возвращающих его явно
+func resolveFillStatus(active, canceled bool, filled, volume float64) market.OrderStatus {
+	switch {
+	case active && filled > 0:
+		return market.OrderStatusPartiallyFilled
+	case active:
+		return market.OrderStatusNew
+	case canceled:
+		return market.OrderStatusCanceled
+	case volume > 0 && filled >= volume:
+		return market.OrderStatusFilled
+	default:
+		return market.OrderStatusCanceled
+	}
+}
@@ -0,0 +1,292 @@
+package exchange
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"daemon-go/internal/bus"
+	"daemon-go/internal/capture"
+	"daemon-go/internal/market"
+	"daemon-go/pkg/log"
+)
+
+// UserDataAdapter - адаптер с приватным WebSocket потоком аккаунта.
+// События ордеров (MessageTypeOrderEvent) и балансов (MessageTypeBalance)
+// публикуются в шину под именем биржи, как и рыночные данные
+type UserDataAdapter interface {
+	StartUserData() error
+	StopUserData() error
+}
+
+// Проверка реализации интерфейса адаптерами
+var (
+	_ UserDataAdapter = (*BinanceAdapter)(nil)
+	_ UserDataAdapter = (*BybitAdapter)(nil)
+	_ UserDataAdapter = (*KucoinAdapter)(nil)
+	_ UserDataAdapter = (*HtxAdapter)(nil)
+	_ UserDataAdapter = (*CoinexAdapter)(nil)
+	_ UserDataAdapter = (*PoloniexAdapter)(nil)
+)
+
+// userDataStream - приватный WebSocket поток с аутентификацией, ping и переподключением.
+// Протокол конкретной биржи задается функциями dial/login/handle
+type userDataStream struct {
+	exchange string // имя биржи для шины (binance, bybit, ...)
+	logger   *log.Logger
+	bus      *bus.MessageBus
+
+	dial   func() (string, error)                                                           // URL подключения (listenKey/токен получаются здесь)
+	login  func(ws *CexWsClient) error                                                      // аутентификация и подписки после подключения
+	handle func(ws *CexWsClient, msgType int, data []byte) ([]market.UnifiedMessage, error) // разбор сообщения, ответы на ping сервера
+
+	ping         func() []byte // ping клиента (nil - не нужен)
+	pingInterval time.Duration
+
+	keepAlive         func() error // продление сессии (Binance listenKey)
+	keepAliveInterval time.Duration
+
+	mu     sync.Mutex
+	ws     *CexWsClient
+	active bool
+	stop   chan struct{}
+}
+
+// start подключается, проходит аутентификацию и запускает чтение в фоне
+func (s *userDataStream) start() error {
+	s.mu.Lock()
+	if s.active {
+		s.mu.Unlock()
+		return nil
+	}
+	s.mu.Unlock()
+
+	ws, err := s.connect()
+	if err != nil {
+		return fmt.Errorf("%s user data: %w", s.exchange, err)
+	}
+
+	s.mu.Lock()
+	s.ws = ws
+	s.active = true
+	s.stop = make(chan struct{})
+	stop := s.stop
+	s.mu.Unlock()
+
+	s.logger.Info("[USER_DATA] %s private stream started", s.exchange)
+	go s.readLoop(stop)
+	if s.ping != nil && s.pingInterval > 0 {
+		go s.timerLoop(stop, s.pingInterval, s.sendPing)
+	}
+	if s.keepAlive != nil && s.keepAliveInterval > 0 {
+		go s.timerLoop(stop, s.keepAliveInterval, func() {
+			if err := s.keepAlive(); err != nil {
+				s.logger.Warn("[USER_DATA] %s keep-alive failed: %v", s.exchange, err)
+			}
+		})
+	}
+	return nil
+}
+
+// stopStream закрывает поток
+func (s *userDataStream) stopStream() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.active {
+		return nil
+	}
+	s.active = false
+	close(s.stop)
+	s.logger.Info("[USER_DATA] %s private stream stopped", s.exchange)
+	if s.ws != nil {
+		return s.ws.Close()
+	}
+	return nil
+}
+
+// connect открывает новое соединение и выполняет login
+func (s *userDataStream) connect() (*CexWsClient, error) {
+	wsURL, err := s.dial()
+	if err != nil {
+		return nil, err
+	}
+	ws := NewCexWsClient(wsURL)
+	if err := ws.Connect(); err != nil {
+		return nil, err
+	}
+	if s.login != nil {
+		if err := s.login(ws); err != nil {
+			_ = ws.Close()
+			return nil, fmt.Errorf("login: %w", err)
+		}
+	}
+	return ws, nil
+}
+
+// current возвращает текущее соединение, если поток активен
+func (s *userDataStream) current() (*CexWsClient, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.ws, s.active
+}
+
+// readLoop читает сообщения и публикует события; при ошибке переподключается
+// с повторной аутентификацией (listenKey/токен запрашиваются заново)
+func (s *userDataStream) readLoop(stop chan struct{}) {
+	for {
+		ws, active := s.current()
+		if !active || ws == nil {
+			return
+		}
+
+		msgType, data, err := ws.ReadMessage()
+		if err != nil {
+			s.logger.Error("[USER_DATA] %s read error: %v, reconnecting...", s.exchange, err)
+			if !s.reconnect(stop) {
+				return
+			}
+			continue
+		}
+		capture.Record(capture.UserDataStream(s.exchange), msgType, data)
+
+		msgs, err := s.handle(ws, msgType, data)
+		if err != nil {
+			s.logger.Error("[USER_DATA] %s parse error: %v", s.exchange, err)
+			continue
+		}
+		for _, msg := range msgs {
+			msg.Exchange = s.exchange
+			if msg.Timestamp.IsZero() {
+				msg.Timestamp = time.Now()
+			}
+			s.bus.Publish(s.exchange, msg)
+		}
+	}
+}
+
+// reconnect пытается восстановить соединение до успеха или остановки потока
+func (s *userDataStream) reconnect(stop chan struct{}) bool {
+	for {
+		select {
+		case <-stop:
+			return false
+		case <-time.After(3 * time.Second):
+		}
+
+		ws, err := s.connect()
+		if err != nil {
+			s.logger.Error("[USER_DATA] %s reconnect failed: %v, retrying...", s.exchange, err)
+			continue
+		}
+
+		s.mu.Lock()
+		if !s.active {
+			s.mu.Unlock()
+			_ = ws.Close()
+			return false
+		}
+		old := s.ws
+		s.ws = ws
+		s.mu.Unlock()
+		if old != nil {
+			_ = old.Close()
+		}
+		s.logger.Info("[USER_DATA] %s private stream reconnected", s.exchange)
+		return true
+	}
+}
+
+// sendPing отправляет ping клиента в текущее соединение
+func (s *userDataStream) sendPing() {
+	ws, active := s.current()
+	if !active || ws == nil {
+		return
+	}
+	if err := ws.WriteMessage(1, s.ping()); err != nil {
+		s.logger.Debug("[USER_DATA] %s ping failed: %v", s.exchange, err)
+	}
+}
+
+// timerLoop периодически вызывает fn до остановки потока
+func (s *userDataStream) timerLoop(stop chan struct{}, interval time.Duration, fn func()) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			fn()
+		}
+	}
+}
+
+// newUserDataStream создает поток с общими для всех бирж зависимостями
+func newUserDataStream(exchange string) *userDataStream {
+	return &userDataStream{
+		exchange: exchange,
+		logger:   log.New(exchange + "_userdata"),
+		bus:      bus.GetInstance(),
+	}
+}
+
+// orderEventMessage оборачивает событие ордера в сообщение шины
+func orderEventMessage(event market.UnifiedOrderEvent) market.UnifiedMessage {
+	return market.UnifiedMessage{
+		Symbol:      event.Symbol,
+		MessageType: market.MessageTypeOrderEvent,
+		Timestamp:   event.Timestamp,
+		Data:        event,
+	}
+}
+
+// balanceMessage оборачивает обновление балансов в сообщение шины. Без времени биржи
+// используется время получения: сервис балансов отбрасывает обновления старше REST снимка
+func balanceMessage(ts time.Time, balances []market.Balance, raw interface{}) market.UnifiedMessage {
+	if ts.IsZero() {
+		ts = time.Now()
+	}
+	return market.UnifiedMessage{
+		MessageType: market.MessageTypeBalance,
+		Timestamp:   ts,
+		Data: market.UnifiedBalanceUpdate{
+			Timestamp: ts,
+			Balances:  balances,
+			Raw:       raw,
+		},
+	}
+}
+
+// remaining возвращает неисполненный остаток ордера
+func remaining(volume, filled float64) float64 {
+	if volume <= filled {
+		return 0
+	}
+	return volume - filled
+}
+
+// writeJSON сериализует и отправляет сообщение в WebSocket
+func writeJSON(ws *CexWsClient, v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return ws.WriteMessage(1, data)
+}
+
+// decompressGzip распаковывает gzip сообщение (HTX, CoinEx v2); несжатые данные возвращаются как есть
+func decompressGzip(data []byte) ([]byte, error) {
+	if len(data) < 2 || data[0] != 0x1f || data[1] != 0x8b {
+		return data, nil
+	}
+	reader, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("gzip reader: %w", err)
+	}
+	defer reader.Close()
+	return io.ReadAll(reader)
+}
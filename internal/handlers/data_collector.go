@@ -11,6 +11,8 @@ type DataCollector struct {
 	orderBooks map[string]map[string]*market.UnifiedOrderBook // [exchange][symbol]
 	tickers    map[string]map[string]*market.UnifiedTicker    // [exchange][symbol]
 	bestPrices map[string]map[string]*market.UnifiedBestPrice // [exchange][symbol]
+	lastTrades map[string]map[string]*market.UnifiedTrade     // [exchange][symbol]
+	klines     map[string]map[string]*market.UnifiedKline     // [exchange][symbol/interval]
 }
 
 func NewDataCollector() *DataCollector {
@@ -18,6 +20,8 @@ func NewDataCollector() *DataCollector {
 		orderBooks: make(map[string]map[string]*market.UnifiedOrderBook),
 		tickers:    make(map[string]map[string]*market.UnifiedTicker),
 		bestPrices: make(map[string]map[string]*market.UnifiedBestPrice),
+		lastTrades: make(map[string]map[string]*market.UnifiedTrade),
+		klines:     make(map[string]map[string]*market.UnifiedKline),
 	}
 }
 
@@ -30,6 +34,10 @@ func (h *DataCollector) HandleMessage(msg market.UnifiedMessage) error {
 		return h.handleTicker(msg)
 	case market.MessageTypeBestPrice:
 		return h.handleBestPrice(msg)
+	case market.MessageTypeTrade:
+		return h.handleTrade(msg)
+	case market.MessageTypeKline:
+		return h.handleKline(msg)
 	default:
 		log.Printf("[DataCollector] Unknown message type: %s", msg.MessageType)
 		return nil
@@ -42,6 +50,12 @@ func (h *DataCollector) handleOrderBook(msg market.UnifiedMessage) error {
 		return fmt.Errorf("invalid orderbook data type")
 	}
 
+	// Стакан устарел до ресинхронизации - убираем его
+	if orderBook.UpdateType == market.OrderBookUpdateTypeInvalidated {
+		delete(h.orderBooks[msg.Exchange], msg.Symbol)
+		return nil
+	}
+
 	// Инициализируем карты если нужно
 	if h.orderBooks[msg.Exchange] == nil {
 		h.orderBooks[msg.Exchange] = make(map[string]*market.UnifiedOrderBook)
@@ -98,6 +112,46 @@ func (h *DataCollector) handleBestPrice(msg market.UnifiedMessage) error {
 	return nil
 }
 
+func (h *DataCollector) handleTrade(msg market.UnifiedMessage) error {
+	trade, ok := msg.Data.(market.UnifiedTrade)
+	if !ok {
+		return fmt.Errorf("invalid trade data type")
+	}
+
+	// Инициализируем карты если нужно
+	if h.lastTrades[msg.Exchange] == nil {
+		h.lastTrades[msg.Exchange] = make(map[string]*market.UnifiedTrade)
+	}
+
+	// Сохраняем последнюю сделку
+	h.lastTrades[msg.Exchange][msg.Symbol] = &trade
+
+	log.Printf("[DataCollector] Trade: %s %s - %s %.8f @ %.8f",
+		msg.Exchange, msg.Symbol, trade.Side, trade.Volume, trade.Price)
+
+	return nil
+}
+
+func (h *DataCollector) handleKline(msg market.UnifiedMessage) error {
+	kline, ok := msg.Data.(market.UnifiedKline)
+	if !ok {
+		return fmt.Errorf("invalid kline data type")
+	}
+
+	// Инициализируем карты если нужно
+	if h.klines[msg.Exchange] == nil {
+		h.klines[msg.Exchange] = make(map[string]*market.UnifiedKline)
+	}
+
+	// Сохраняем текущую свечу интервала
+	h.klines[msg.Exchange][msg.Symbol+"/"+kline.Interval] = &kline
+
+	log.Printf("[DataCollector] Kline updated: %s %s %s - O: %.8f, H: %.8f, L: %.8f, C: %.8f",
+		msg.Exchange, msg.Symbol, kline.Interval, kline.Open, kline.High, kline.Low, kline.Close)
+
+	return nil
+}
+
 // GetStats возвращает статистику по собранным данным
 func (h *DataCollector) GetStats() map[string]interface{} {
 	stats := make(map[string]interface{})
@@ -107,6 +161,8 @@ func (h *DataCollector) GetStats() map[string]interface{} {
 	stats["orderbook_updates"] = h.getTotalOrderBooks()
 	stats["ticker_updates"] = h.getTotalTickers()
 	stats["best_price_updates"] = h.getTotalBestPrices()
+	stats["trade_updates"] = h.getTotalTrades()
+	stats["kline_updates"] = h.getTotalKlines()
 
 	return stats
 }
@@ -144,3 +200,19 @@ func (h *DataCollector) getTotalBestPrices() int {
 	}
 	return count
 }
+
+func (h *DataCollector) getTotalTrades() int {
+	count := 0
+	for _, exchangeData := range h.lastTrades {
+		count += len(exchangeData)
+	}
+	return count
+}
+
+func (h *DataCollector) getTotalKlines() int {
+	count := 0
+	for _, exchangeData := range h.klines {
+		count += len(exchangeData)
+	}
+	return count
+}
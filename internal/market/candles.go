@@ -0,0 +1,138 @@
+package market
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ParseKlineInterval возвращает длительность унифицированного интервала свечи: 1s, 1m, 5m, 1h, 1d
+func ParseKlineInterval(interval string) (time.Duration, error) {
+	interval = strings.TrimSpace(interval)
+	if len(interval) < 2 {
+		return 0, fmt.Errorf("invalid kline interval %q", interval)
+	}
+	n, err := strconv.Atoi(interval[:len(interval)-1])
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf("invalid kline interval %q", interval)
+	}
+	unit := map[byte]time.Duration{'s': time.Second, 'm': time.Minute, 'h': time.Hour, 'd': 24 * time.Hour}
+	d, ok := unit[interval[len(interval)-1]]
+	if !ok {
+		return 0, fmt.Errorf("invalid kline interval %q", interval)
+	}
+	return time.Duration(n) * d, nil
+}
+
+// KlineIntervalName возвращает унифицированное имя интервала: 5*time.Minute -> 5m
+func KlineIntervalName(d time.Duration) string {
+	switch {
+	case d%(24*time.Hour) == 0:
+		return fmt.Sprintf("%dd", d/(24*time.Hour))
+	case d%time.Hour == 0:
+		return fmt.Sprintf("%dh", d/time.Hour)
+	case d%time.Minute == 0:
+		return fmt.Sprintf("%dm", d/time.Minute)
+	default:
+		return fmt.Sprintf("%ds", d/time.Second)
+	}
+}
+
+// candleKey - свеча символа биржи одного интервала
+type candleKey struct {
+	exchange string
+	symbol   string
+	interval time.Duration
+}
+
+// CandleAggregator строит свечи заданных интервалов из потока сделок для бирж без свечного
+// потока. Свеча закрывается первой сделкой следующего интервала или Flush по времени;
+// интервалы без сделок свечей не дают. Сделки закрытых интервалов считаются опоздавшими
+// и пропускаются. Не потокобезопасен
+type CandleAggregator struct {
+	intervals []time.Duration
+	candles   map[candleKey]*UnifiedKline
+	closed    map[candleKey]time.Time // конец последней закрытой свечи ключа
+
+	late int64 // сделки старше текущей или закрытой свечи, пропущены
+}
+
+// NewCandleAggregator создает агрегатор свечей интервалов intervals
+func NewCandleAggregator(intervals []time.Duration) *CandleAggregator {
+	return &CandleAggregator{
+		intervals: intervals,
+		candles:   make(map[candleKey]*UnifiedKline),
+		closed:    make(map[candleKey]time.Time),
+	}
+}
+
+// AddTrade добавляет сделку во все интервалы; возвращает свечи, закрытые этой сделкой
+func (a *CandleAggregator) AddTrade(exchange string, trade UnifiedTrade) []UnifiedKline {
+	if trade.Price <= 0 {
+		return nil
+	}
+	var closed []UnifiedKline
+	for _, interval := range a.intervals {
+		key := candleKey{exchange: exchange, symbol: trade.Symbol, interval: interval}
+		openTime := trade.Timestamp.Truncate(interval)
+
+		candle, ok := a.candles[key]
+		if (ok && openTime.Before(candle.OpenTime)) || openTime.Before(a.closed[key]) {
+			a.late++
+			continue
+		}
+		if ok && openTime.After(candle.OpenTime) {
+			candle.Closed = true
+			closed = append(closed, *candle)
+			a.closed[key] = candle.CloseTime
+			ok = false
+		}
+		if !ok {
+			candle = &UnifiedKline{
+				Symbol:        trade.Symbol,
+				UnifiedSymbol: trade.UnifiedSymbol,
+				Interval:      KlineIntervalName(interval),
+				OpenTime:      openTime,
+				CloseTime:     openTime.Add(interval),
+				Open:          trade.Price,
+				High:          trade.Price,
+				Low:           trade.Price,
+				Source:        KlineSourceLocal,
+			}
+			a.candles[key] = candle
+		}
+
+		if trade.Price > candle.High {
+			candle.High = trade.Price
+		}
+		if trade.Price < candle.Low {
+			candle.Low = trade.Price
+		}
+		candle.Close = trade.Price
+		candle.Volume += trade.Volume
+		candle.QuoteVolume += trade.Volume * trade.Price
+		candle.Trades++
+	}
+	return closed
+}
+
+// Flush закрывает свечи, интервал которых закончился к now; exchange свечи - в ключе результата
+func (a *CandleAggregator) Flush(now time.Time) map[string][]UnifiedKline {
+	closed := make(map[string][]UnifiedKline)
+	for key, candle := range a.candles {
+		if now.Before(candle.CloseTime) {
+			continue
+		}
+		candle.Closed = true
+		closed[key.exchange] = append(closed[key.exchange], *candle)
+		a.closed[key] = candle.CloseTime
+		delete(a.candles, key)
+	}
+	return closed
+}
+
+// Late возвращает число пропущенных опоздавших сделок
+func (a *CandleAggregator) Late() int64 {
+	return a.late
+}
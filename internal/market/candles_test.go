@@ -0,0 +1,114 @@
+package market
+
+import (
+	"testing"
+	"time"
+)
+
+func candleTrade(ts time.Time, price, volume float64) UnifiedTrade {
+	return UnifiedTrade{Symbol: "BTC/USDT", Timestamp: ts, Price: price, Volume: volume}
+}
+
+func TestCandleAggregatorBuildsAndClosesCandles(t *testing.T) {
+	base := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	agg := NewCandleAggregator([]time.Duration{time.Minute})
+
+	for _, tr := range []UnifiedTrade{
+		candleTrade(base.Add(1*time.Second), 100, 1),
+		candleTrade(base.Add(20*time.Second), 105, 2),
+		candleTrade(base.Add(40*time.Second), 95, 1),
+		candleTrade(base.Add(59*time.Second), 101, 0.5),
+	} {
+		if closed := agg.AddTrade("coinex", tr); len(closed) != 0 {
+			t.Fatalf("candle closed by a trade of the same interval: %+v", closed)
+		}
+	}
+
+	closed := agg.AddTrade("coinex", candleTrade(base.Add(61*time.Second), 102, 1))
+	if len(closed) != 1 {
+		t.Fatalf("expected 1 closed candle, got %d", len(closed))
+	}
+	c := closed[0]
+	if !c.OpenTime.Equal(base) || !c.CloseTime.Equal(base.Add(time.Minute)) {
+		t.Errorf("candle bounds %v-%v, want %v-%v", c.OpenTime, c.CloseTime, base, base.Add(time.Minute))
+	}
+	if c.Open != 100 || c.High != 105 || c.Low != 95 || c.Close != 101 {
+		t.Errorf("OHLC %v/%v/%v/%v, want 100/105/95/101", c.Open, c.High, c.Low, c.Close)
+	}
+	if c.Volume != 4.5 || c.QuoteVolume != 100+210+95+50.5 || c.Trades != 4 {
+		t.Errorf("volume %v quote %v trades %d", c.Volume, c.QuoteVolume, c.Trades)
+	}
+	if !c.Closed || c.Interval != "1m" || c.Source != KlineSourceLocal {
+		t.Errorf("closed=%v interval=%q source=%q", c.Closed, c.Interval, c.Source)
+	}
+}
+
+func TestCandleAggregatorLateTrades(t *testing.T) {
+	base := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	agg := NewCandleAggregator([]time.Duration{time.Minute})
+
+	agg.AddTrade("coinex", candleTrade(base.Add(10*time.Second), 100, 1))
+	agg.AddTrade("coinex", candleTrade(base.Add(70*time.Second), 101, 1))
+
+	// Сделка закрытого интервала не открывает его заново
+	if closed := agg.AddTrade("coinex", candleTrade(base.Add(30*time.Second), 99, 1)); len(closed) != 0 {
+		t.Fatalf("late trade closed candles: %+v", closed)
+	}
+	if agg.Late() != 1 {
+		t.Fatalf("late = %d, want 1", agg.Late())
+	}
+
+	// То же после закрытия по времени: интервал без текущей свечи остается закрытым
+	flushed := agg.Flush(base.Add(2 * time.Minute))
+	if len(flushed["coinex"]) != 1 {
+		t.Fatalf("flush closed %d candles, want 1", len(flushed["coinex"]))
+	}
+	agg.AddTrade("coinex", candleTrade(base.Add(90*time.Second), 98, 1))
+	if agg.Late() != 2 {
+		t.Fatalf("late = %d, want 2", agg.Late())
+	}
+	if flushed := agg.Flush(base.Add(time.Hour)); len(flushed) != 0 {
+		t.Fatalf("late trade produced candles: %+v", flushed)
+	}
+}
+
+func TestCandleAggregatorFlushKeepsOpenCandles(t *testing.T) {
+	base := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	agg := NewCandleAggregator([]time.Duration{time.Minute, 5 * time.Minute})
+
+	agg.AddTrade("coinex", candleTrade(base.Add(10*time.Second), 100, 1))
+
+	flushed := agg.Flush(base.Add(time.Minute))
+	if len(flushed["coinex"]) != 1 || flushed["coinex"][0].Interval != "1m" {
+		t.Fatalf("flush at 1m: %+v, want only the 1m candle", flushed)
+	}
+	flushed = agg.Flush(base.Add(5 * time.Minute))
+	if len(flushed["coinex"]) != 1 || flushed["coinex"][0].Interval != "5m" {
+		t.Fatalf("flush at 5m: %+v, want only the 5m candle", flushed)
+	}
+}
+
+func TestCandleAggregatorSkipsInvalidPrice(t *testing.T) {
+	agg := NewCandleAggregator([]time.Duration{time.Minute})
+	agg.AddTrade("coinex", candleTrade(time.Now(), 0, 1))
+	if flushed := agg.Flush(time.Now().Add(time.Hour)); len(flushed) != 0 {
+		t.Fatalf("zero price trade produced candles: %+v", flushed)
+	}
+}
+
+func TestKlineIntervals(t *testing.T) {
+	for _, name := range []string{"1s", "1m", "5m", "1h", "1d"} {
+		d, err := ParseKlineInterval(name)
+		if err != nil {
+			t.Fatalf("ParseKlineInterval(%q): %v", name, err)
+		}
+		if got := KlineIntervalName(d); got != name {
+			t.Errorf("KlineIntervalName(%v) = %q, want %q", d, got, name)
+		}
+	}
+	for _, name := range []string{"", "m", "0m", "5x", "-1h"} {
+		if _, err := ParseKlineInterval(name); err == nil {
+			t.Errorf("ParseKlineInterval(%q) accepted invalid interval", name)
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package market
+
+import (
+	"errors"
+	"hash/crc32"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// ErrChecksumMismatch - контрольная сумма локального стакана не совпала с присланной биржей
+var ErrChecksumMismatch = errors.New("orderbook checksum mismatch")
+
+// ChecksumFunc вычисляет контрольную сумму стакана по правилам конкретной биржи.
+// bids отсортированы по убыванию цены, asks - по возрастанию
+type ChecksumFunc func(bids, asks []PriceLevel) int64
+
+var (
+	checksumMu    sync.RWMutex
+	checksumFuncs = map[string]ChecksumFunc{
+		// Poloniex: CRC32 по 25 уровням, чередуя bid/ask в формате price:size.
+		// Тот же алгоритм использует OKX (поле checksum в books) - при добавлении биржи достаточно регистрации
+		"poloniex": InterleavedCRC32(25),
+	}
+)
+
+// RegisterChecksum регистрирует алгоритм контрольной суммы для биржи
+func RegisterChecksum(exchange string, fn ChecksumFunc) {
+	checksumMu.Lock()
+	defer checksumMu.Unlock()
+	checksumFuncs[exchange] = fn
+}
+
+// GetChecksumFunc возвращает алгоритм контрольной суммы биржи
+func GetChecksumFunc(exchange string) (ChecksumFunc, bool) {
+	checksumMu.RLock()
+	defer checksumMu.RUnlock()
+	fn, ok := checksumFuncs[exchange]
+	return fn, ok
+}
+
+// InterleavedCRC32 возвращает алгоритм CRC32 по строке "bid1:size1:ask1:size1:bid2:...",
+// где стороны чередуются на глубину depth; результат - знаковый int32, как его присылают биржи.
+// Берутся исходные строки биржи: "50000.10" и "50000.1" дают разные суммы. Уровни без
+// исходных строк форматируются без лишних нулей
+func InterleavedCRC32(depth int) ChecksumFunc {
+	return func(bids, asks []PriceLevel) int64 {
+		parts := make([]string, 0, depth*4)
+		for i := 0; i < depth; i++ {
+			if i < len(bids) {
+				parts = append(parts, checksumPrice(bids[i]), checksumVolume(bids[i]))
+			}
+			if i < len(asks) {
+				parts = append(parts, checksumPrice(asks[i]), checksumVolume(asks[i]))
+			}
+		}
+		return int64(int32(crc32.ChecksumIEEE([]byte(strings.Join(parts, ":")))))
+	}
+}
+
+func checksumPrice(level PriceLevel) string {
+	if level.RawPrice != "" {
+		return level.RawPrice
+	}
+	return strconv.FormatFloat(level.Price, 'f', -1, 64)
+}
+
+func checksumVolume(level PriceLevel) string {
+	if level.RawVolume != "" {
+		return level.RawVolume
+	}
+	return strconv.FormatFloat(level.Volume, 'f', -1, 64)
+}
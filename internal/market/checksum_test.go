@@ -0,0 +1,81 @@
+package market
+
+import (
+	"errors"
+	"hash/crc32"
+	"testing"
+)
+
+func TestInterleavedCRC32UsesRawStrings(t *testing.T) {
+	bids := []PriceLevel{{Price: 50000, Volume: 1.5, RawPrice: "50000.00", RawVolume: "1.50"}}
+	asks := []PriceLevel{{Price: 50001, Volume: 2, RawPrice: "50001.00", RawVolume: "2.00"}}
+
+	want := int64(int32(crc32.ChecksumIEEE([]byte("50000.00:1.50:50001.00:2.00"))))
+	if got := InterleavedCRC32(25)(bids, asks); got != want {
+		t.Fatalf("checksum %d, want %d", got, want)
+	}
+}
+
+func TestInterleavedCRC32FormatsLevelsWithoutRaw(t *testing.T) {
+	bids := []PriceLevel{{Price: 50000, Volume: 1.5}, {Price: 49999, Volume: 2}}
+	asks := []PriceLevel{{Price: 50001, Volume: 1}}
+
+	want := int64(int32(crc32.ChecksumIEEE([]byte("50000:1.5:50001:1:49999:2"))))
+	if got := InterleavedCRC32(25)(bids, asks); got != want {
+		t.Fatalf("checksum %d, want %d", got, want)
+	}
+	// Глубина ограничивает число уровней каждой стороны
+	want = int64(int32(crc32.ChecksumIEEE([]byte("50000:1.5:50001:1"))))
+	if got := InterleavedCRC32(1)(bids, asks); got != want {
+		t.Fatalf("depth 1 checksum %d, want %d", got, want)
+	}
+}
+
+func TestLocalOrderBookVerifyChecksumAfterDiff(t *testing.T) {
+	book := NewLocalOrderBook("poloniex", "BTC/USDT")
+	book.ApplySnapshot(UnifiedOrderBook{
+		Bids: []PriceLevel{{Price: 50000, Volume: 1.5, RawPrice: "50000.00", RawVolume: "1.50"}},
+		Asks: []PriceLevel{{Price: 50001, Volume: 1, RawPrice: "50001.00", RawVolume: "1.00"}},
+	})
+	if err := book.ApplyDiff(UnifiedOrderBook{
+		Bids: []PriceLevel{{Price: 50000, Volume: 1.2, RawPrice: "50000.00", RawVolume: "1.20"}},
+	}); err != nil {
+		t.Fatalf("ApplyDiff: %v", err)
+	}
+
+	sum := int64(int32(crc32.ChecksumIEEE([]byte("50000.00:1.20:50001.00:1.00"))))
+	if err := book.VerifyChecksum(sum); err != nil {
+		t.Fatalf("VerifyChecksum: %v", err)
+	}
+	if err := book.VerifyChecksum(sum + 1); err != ErrChecksumMismatch {
+		t.Fatalf("VerifyChecksum with wrong sum: %v, want ErrChecksumMismatch", err)
+	}
+}
+
+// После сообщения об устаревании (ресинхронизация по checksum) diff не применяются до нового снимка
+func TestOrderBookEngineInvalidatedDropsBook(t *testing.T) {
+	engine := NewOrderBookEngine(5)
+	if _, err := engine.Apply("poloniex", UnifiedOrderBook{
+		Symbol:     "BTC/USDT",
+		UpdateType: OrderBookUpdateTypeSnapshot,
+		Bids:       []PriceLevel{{Price: 50000, Volume: 1.5}},
+		Asks:       []PriceLevel{{Price: 50001, Volume: 1}},
+	}); err != nil {
+		t.Fatalf("snapshot: %v", err)
+	}
+
+	if _, err := engine.Apply("poloniex", UnifiedOrderBook{
+		Symbol:     "BTC/USDT",
+		UpdateType: OrderBookUpdateTypeInvalidated,
+	}); !errors.Is(err, ErrOrderBookInvalidated) {
+		t.Fatalf("invalidated update: %v, want ErrOrderBookInvalidated", err)
+	}
+
+	if _, err := engine.Apply("poloniex", UnifiedOrderBook{
+		Symbol:     "BTC/USDT",
+		UpdateType: OrderBookUpdateTypeIncremental,
+		Bids:       []PriceLevel{{Price: 50000, Volume: 1.2}},
+	}); !errors.Is(err, ErrOrderBookNotInitialized) {
+		t.Fatalf("diff after invalidation: %v, want ErrOrderBookNotInitialized", err)
+	}
+}
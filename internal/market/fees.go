@@ -0,0 +1,64 @@
+package market
+
+import (
+	"strings"
+	"sync"
+)
+
+// FeeRate - комиссии maker/taker в долях (0.001 = 0.1%)
+type FeeRate struct {
+	Maker float64 `json:"maker"`
+	Taker float64 `json:"taker"`
+}
+
+// FeeSchedule - комиссии по биржам и парам.
+// Поиск идет от частного к общему: пара на бирже, биржа, значение по умолчанию
+type FeeSchedule struct {
+	mu          sync.RWMutex
+	defaultRate FeeRate
+	exchanges   map[string]FeeRate // [exchange]
+	pairs       map[string]FeeRate // [exchange|symbol]
+}
+
+// NewFeeSchedule создает таблицу комиссий со значением по умолчанию
+func NewFeeSchedule(defaultRate FeeRate) *FeeSchedule {
+	return &FeeSchedule{
+		defaultRate: defaultRate,
+		exchanges:   make(map[string]FeeRate),
+		pairs:       make(map[string]FeeRate),
+	}
+}
+
+// SetExchange задает комиссию биржи для всех пар без собственной записи
+func (s *FeeSchedule) SetExchange(exchange string, rate FeeRate) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.exchanges[strings.ToLower(exchange)] = rate
+}
+
+// SetPair задает комиссию для пары на бирже
+func (s *FeeSchedule) SetPair(exchange, symbol string, rate FeeRate) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.pairs[feeKey(exchange, symbol)] = rate
+}
+
+// Get возвращает комиссию для пары на бирже
+func (s *FeeSchedule) Get(exchange, symbol string) FeeRate {
+	if s == nil {
+		return FeeRate{}
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if rate, ok := s.pairs[feeKey(exchange, symbol)]; ok {
+		return rate
+	}
+	if rate, ok := s.exchanges[strings.ToLower(exchange)]; ok {
+		return rate
+	}
+	return s.defaultRate
+}
+
+func feeKey(exchange, symbol string) string {
+	return strings.ToLower(exchange) + "|" + strings.ToUpper(symbol)
+}
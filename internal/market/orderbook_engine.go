@@ -0,0 +1,299 @@
+package market
+
+import (
+	"errors"
+	"sort"
+	"sync"
+	"time"
+)
+
+// ErrOrderBookNotInitialized - инкрементальное обновление пришло раньше полного снимка
+var ErrOrderBookNotInitialized = errors.New("orderbook not initialized: snapshot required before diffs")
+
+// ErrOrderBookInvalidated - биржевой поток объявил стакан устаревшим (идет ресинхронизация)
+var ErrOrderBookInvalidated = errors.New("orderbook invalidated: waiting for resync snapshot")
+
+// LocalOrderBook - полный локальный стакан одной пары на одной бирже.
+// Уровни хранятся по цене, объем 0 в обновлении удаляет уровень
+type LocalOrderBook struct {
+	mu            sync.RWMutex
+	exchange      string
+	symbol        string
+	unifiedSymbol *UnifiedSymbol
+	bids          map[float64]PriceLevel // price -> уровень с исходными строками биржи
+	asks          map[float64]PriceLevel // price -> уровень с исходными строками биржи
+	initialized   bool                   // получен хотя бы один снимок
+	lastUpdate    time.Time
+	updates       int64 // количество примененных обновлений с последнего снимка
+}
+
+// NewLocalOrderBook создает пустой локальный стакан
+func NewLocalOrderBook(exchange, symbol string) *LocalOrderBook {
+	return &LocalOrderBook{
+		exchange: exchange,
+		symbol:   symbol,
+		bids:     make(map[float64]PriceLevel),
+		asks:     make(map[float64]PriceLevel),
+	}
+}
+
+// ApplySnapshot полностью заменяет содержимое стакана
+func (b *LocalOrderBook) ApplySnapshot(ob UnifiedOrderBook) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.bids = make(map[float64]PriceLevel, len(ob.Bids))
+	b.asks = make(map[float64]PriceLevel, len(ob.Asks))
+	applyLevels(b.bids, ob.Bids)
+	applyLevels(b.asks, ob.Asks)
+
+	if ob.UnifiedSymbol != nil {
+		b.unifiedSymbol = ob.UnifiedSymbol
+	}
+	b.initialized = true
+	b.lastUpdate = ob.Timestamp
+	b.updates = 0
+}
+
+// ApplyDiff применяет инкрементальное обновление по уровням цен
+func (b *LocalOrderBook) ApplyDiff(ob UnifiedOrderBook) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if !b.initialized {
+		return ErrOrderBookNotInitialized
+	}
+
+	applyLevels(b.bids, ob.Bids)
+	applyLevels(b.asks, ob.Asks)
+
+	b.lastUpdate = ob.Timestamp
+	b.updates++
+	return nil
+}
+
+// applyLevels обновляет уровни: объем 0 удаляет уровень, иначе заменяет его
+func applyLevels(side map[float64]PriceLevel, levels []PriceLevel) {
+	for _, level := range levels {
+		if level.Price <= 0 {
+			continue
+		}
+		if level.Volume <= 0 {
+			delete(side, level.Price)
+			continue
+		}
+		side[level.Price] = level
+	}
+}
+
+// Reset сбрасывает стакан до получения нового снимка
+func (b *LocalOrderBook) Reset() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.bids = make(map[float64]PriceLevel)
+	b.asks = make(map[float64]PriceLevel)
+	b.initialized = false
+	b.updates = 0
+}
+
+// VerifyChecksum сверяет контрольную сумму стакана с присланной биржей.
+// Если биржа не присылает checksum или алгоритм не зарегистрирован, проверка пропускается
+func (b *LocalOrderBook) VerifyChecksum(checksum int64) error {
+	if checksum == 0 {
+		return nil
+	}
+	fn, ok := GetChecksumFunc(b.exchange)
+	if !ok {
+		return nil
+	}
+
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	// Биржи присылают CRC32 как знаковое или беззнаковое число - сравниваем младшие 32 бита
+	if int32(fn(sortedLevels(b.bids, true, 0), sortedLevels(b.asks, false, 0))) != int32(checksum) {
+		return ErrChecksumMismatch
+	}
+	return nil
+}
+
+// IsInitialized проверяет, получен ли снимок
+func (b *LocalOrderBook) IsInitialized() bool {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return b.initialized
+}
+
+// LastUpdate возвращает время последнего обновления
+func (b *LocalOrderBook) LastUpdate() time.Time {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return b.lastUpdate
+}
+
+// Top возвращает срез стакана из n лучших уровней с каждой стороны (n <= 0 - весь стакан)
+func (b *LocalOrderBook) Top(n int) UnifiedOrderBook {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	bids := sortedLevels(b.bids, true, n)
+	asks := sortedLevels(b.asks, false, n)
+
+	return UnifiedOrderBook{
+		Symbol:        b.symbol,
+		UnifiedSymbol: b.unifiedSymbol,
+		Timestamp:     b.lastUpdate,
+		Bids:          bids,
+		Asks:          asks,
+		Depth:         len(bids) + len(asks),
+		UpdateType:    OrderBookUpdateTypeSnapshot,
+	}
+}
+
+// BestBid возвращает лучший bid
+func (b *LocalOrderBook) BestBid() (PriceLevel, bool) {
+	levels := b.Top(1).Bids
+	if len(levels) == 0 {
+		return PriceLevel{}, false
+	}
+	return levels[0], true
+}
+
+// BestAsk возвращает лучший ask
+func (b *LocalOrderBook) BestAsk() (PriceLevel, bool) {
+	levels := b.Top(1).Asks
+	if len(levels) == 0 {
+		return PriceLevel{}, false
+	}
+	return levels[0], true
+}
+
+// sortedLevels сортирует уровни: bids по убыванию цены, asks по возрастанию
+func sortedLevels(side map[float64]PriceLevel, desc bool, n int) []PriceLevel {
+	prices := make([]float64, 0, len(side))
+	for price := range side {
+		prices = append(prices, price)
+	}
+	if desc {
+		sort.Sort(sort.Reverse(sort.Float64Slice(prices)))
+	} else {
+		sort.Float64s(prices)
+	}
+	if n > 0 && len(prices) > n {
+		prices = prices[:n]
+	}
+
+	levels := make([]PriceLevel, len(prices))
+	for i, price := range prices {
+		levels[i] = side[price]
+	}
+	return levels
+}
+
+// OrderBookEngine хранит локальные стаканы по (exchange, symbol) и применяет к ним обновления
+type OrderBookEngine struct {
+	mu    sync.RWMutex
+	books map[string]*LocalOrderBook // [exchange|symbol]
+	depth int                        // глубина возвращаемых срезов (0 - весь стакан)
+}
+
+// NewOrderBookEngine создает движок стаканов; depth задает глубину срезов из Apply
+func NewOrderBookEngine(depth int) *OrderBookEngine {
+	return &OrderBookEngine{
+		books: make(map[string]*LocalOrderBook),
+		depth: depth,
+	}
+}
+
+func bookKey(exchange, symbol string) string {
+	return exchange + "|" + symbol
+}
+
+// book возвращает стакан, создавая его при необходимости
+func (e *OrderBookEngine) book(exchange, symbol string) *LocalOrderBook {
+	key := bookKey(exchange, symbol)
+
+	e.mu.RLock()
+	b, ok := e.books[key]
+	e.mu.RUnlock()
+	if ok {
+		return b
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if b, ok = e.books[key]; !ok {
+		b = NewLocalOrderBook(exchange, symbol)
+		e.books[key] = b
+	}
+	return b
+}
+
+// Apply применяет обновление (снимок или diff) и возвращает актуальный срез стакана.
+// При несовпадении контрольной суммы или сообщении об устаревании стакан сбрасывается
+// до следующего снимка
+func (e *OrderBookEngine) Apply(exchange string, ob UnifiedOrderBook) (UnifiedOrderBook, error) {
+	b := e.book(exchange, ob.Symbol)
+
+	if ob.UpdateType == OrderBookUpdateTypeInvalidated {
+		b.Reset()
+		return UnifiedOrderBook{}, ErrOrderBookInvalidated
+	}
+	if ob.UpdateType == OrderBookUpdateTypeIncremental {
+		if err := b.ApplyDiff(ob); err != nil {
+			return UnifiedOrderBook{}, err
+		}
+	} else {
+		b.ApplySnapshot(ob)
+	}
+
+	if err := b.VerifyChecksum(ob.Checksum); err != nil {
+		b.Reset()
+		return UnifiedOrderBook{}, err
+	}
+
+	return b.Top(e.depth), nil
+}
+
+// GetBook возвращает локальный стакан пары
+func (e *OrderBookEngine) GetBook(exchange, symbol string) (*LocalOrderBook, bool) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	b, ok := e.books[bookKey(exchange, symbol)]
+	return b, ok
+}
+
+// GetTop возвращает срез из n лучших уровней стакана пары
+func (e *OrderBookEngine) GetTop(exchange, symbol string, n int) (UnifiedOrderBook, bool) {
+	b, ok := e.GetBook(exchange, symbol)
+	if !ok || !b.IsInitialized() {
+		return UnifiedOrderBook{}, false
+	}
+	return b.Top(n), true
+}
+
+// Reset сбрасывает стакан пары (например, при переподключении)
+func (e *OrderBookEngine) Reset(exchange, symbol string) {
+	if b, ok := e.GetBook(exchange, symbol); ok {
+		b.Reset()
+	}
+}
+
+// ResetExchange сбрасывает все стаканы биржи
+func (e *OrderBookEngine) ResetExchange(exchange string) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	for _, b := range e.books {
+		if b.exchange == exchange {
+			b.Reset()
+		}
+	}
+}
+
+// Remove удаляет стакан пары
+func (e *OrderBookEngine) Remove(exchange, symbol string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	delete(e.books, bookKey(exchange, symbol))
+}
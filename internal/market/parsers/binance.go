@@ -3,6 +3,8 @@ package parsers
 import (
 	"encoding/json"
 	"fmt"
+	"strconv"
+	"strings"
 	"time"
 
 	"daemon-go/internal/market"
@@ -13,7 +15,9 @@ type BinanceParser struct {
 	symbolRegistry *market.SymbolRegistry
 }
 
-// BinanceDepthMessage - формат orderbook от Binance
+// BinanceDepthMessage - формат orderbook от Binance.
+// Diff поток (<symbol>@depth@100ms) содержит s/U/u/b/a,
+// частичный стакан (<symbol>@depth5@100ms) - lastUpdateId/bids/asks без символа
 type BinanceDepthMessage struct {
 	Stream string `json:"stream"`
 	Data   struct {
@@ -22,6 +26,9 @@ type BinanceDepthMessage struct {
 		FinalUpdateID int64      `json:"u"`
 		Bids          [][]string `json:"b"`
 		Asks          [][]string `json:"a"`
+		LastUpdateID  int64      `json:"lastUpdateId"`
+		PartialBids   [][]string `json:"bids"`
+		PartialAsks   [][]string `json:"asks"`
 	} `json:"data"`
 }
 
@@ -53,6 +60,43 @@ type BinanceBookTickerMessage struct {
 	} `json:"data"`
 }
 
+// BinanceTradeMessage - формат сделки от Binance: <symbol>@trade (t - ID сделки)
+// и <symbol>@aggTrade (a - ID агрегированной сделки). m=true - покупатель мейкер, тейкер продает
+type BinanceTradeMessage struct {
+	Stream string `json:"stream"`
+	Data   struct {
+		Symbol       string `json:"s"`
+		TradeID      int64  `json:"t"`
+		AggTradeID   int64  `json:"a"`
+		Price        string `json:"p"`
+		Quantity     string `json:"q"`
+		TradeTime    int64  `json:"T"`
+		BuyerIsMaker bool   `json:"m"`
+	} `json:"data"`
+}
+
+// BinanceKlineMessage - формат свечи от Binance (<symbol>@kline_<interval>); T - последняя
+// миллисекунда свечи, x - свеча закрыта
+type BinanceKlineMessage struct {
+	Stream string `json:"stream"`
+	Data   struct {
+		Symbol string `json:"s"`
+		Kline  struct {
+			StartTime   int64  `json:"t"`
+			CloseTime   int64  `json:"T"`
+			Interval    string `json:"i"`
+			Open        string `json:"o"`
+			Close       string `json:"c"`
+			High        string `json:"h"`
+			Low         string `json:"l"`
+			Volume      string `json:"v"`
+			QuoteVolume string `json:"q"`
+			Trades      int64  `json:"n"`
+			Closed      bool   `json:"x"`
+		} `json:"k"`
+	} `json:"data"`
+}
+
 func NewBinanceParser() *BinanceParser {
 	return &BinanceParser{
 		symbolRegistry: market.NewSymbolRegistry(),
@@ -82,6 +126,10 @@ func (p *BinanceParser) ParseMessage(exchange string, rawData []byte) (*market.U
 		return p.parseTicker(rawData, timestamp)
 	case contains(streamMessage.Stream, "@bookTicker"):
 		return p.parseBestPrice(rawData, timestamp)
+	case contains(streamMessage.Stream, "@trade"), contains(streamMessage.Stream, "@aggTrade"):
+		return p.parseTrade(rawData, timestamp)
+	case contains(streamMessage.Stream, "@kline_"):
+		return p.parseKline(rawData, timestamp)
 	default:
 		return nil, fmt.Errorf("unknown stream type: %s", streamMessage.Stream)
 	}
@@ -93,10 +141,26 @@ func (p *BinanceParser) parseOrderBook(rawData []byte, timestamp time.Time) (*ma
 		return nil, fmt.Errorf("failed to parse orderbook: %w", err)
 	}
 
+	// Diff поток отличается наличием U/u, частичный стакан приходит полным снимком
+	updateType := market.OrderBookUpdateTypeSnapshot
+	if msg.Data.FinalUpdateID > 0 {
+		updateType = market.OrderBookUpdateTypeIncremental
+	} else {
+		msg.Data.Bids = msg.Data.PartialBids
+		msg.Data.Asks = msg.Data.PartialAsks
+		msg.Data.FinalUpdateID = msg.Data.LastUpdateID
+	}
+
+	// В частичном стакане символа нет, берем его из имени потока (btcusdt@depth5@100ms)
+	symbol := msg.Data.Symbol
+	if symbol == "" {
+		symbol = strings.ToUpper(strings.SplitN(msg.Stream, "@", 2)[0])
+	}
+
 	// Конвертируем символ Binance в унифицированный формат
-	unifiedSymbol, err := p.symbolRegistry.ConvertToUnified("binance", msg.Data.Symbol, "spot")
+	unifiedSymbol, err := p.symbolRegistry.ConvertToUnified("binance", symbol, "spot")
 	if err != nil {
-		return nil, fmt.Errorf("failed to convert symbol %s: %w", msg.Data.Symbol, err)
+		return nil, fmt.Errorf("failed to convert symbol %s: %w", symbol, err)
 	}
 
 	bids := make([]market.PriceLevel, 0, len(msg.Data.Bids))
@@ -130,8 +194,10 @@ func (p *BinanceParser) parseOrderBook(rawData []byte, timestamp time.Time) (*ma
 		Bids:          bids,
 		Asks:          asks,
 		Depth:         len(bids) + len(asks),
-		UpdateType:    market.OrderBookUpdateTypeSnapshot, // Binance depth streams обычно snapshot
+		UpdateType:    updateType,
 		Raw:           msg,
+		FirstUpdateID: msg.Data.FirstUpdateID,
+		LastUpdateID:  msg.Data.FinalUpdateID,
 	}
 
 	return &market.UnifiedMessage{
@@ -213,3 +279,87 @@ func (p *BinanceParser) parseBestPrice(rawData []byte, timestamp time.Time) (*ma
 		Data:          bestPrice,
 	}, nil
 }
+
+func (p *BinanceParser) parseTrade(rawData []byte, timestamp time.Time) (*market.UnifiedMessage, error) {
+	var msg BinanceTradeMessage
+	if err := json.Unmarshal(rawData, &msg); err != nil {
+		return nil, fmt.Errorf("failed to parse trade: %w", err)
+	}
+
+	unifiedSymbol, err := p.symbolRegistry.ConvertToUnified("binance", msg.Data.Symbol, "spot")
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert symbol %s: %w", msg.Data.Symbol, err)
+	}
+
+	tradeID := msg.Data.TradeID
+	if contains(msg.Stream, "@aggTrade") {
+		tradeID = msg.Data.AggTradeID
+	}
+	side := market.TradeSideBuy
+	if msg.Data.BuyerIsMaker {
+		side = market.TradeSideSell
+	}
+	if msg.Data.TradeTime > 0 {
+		timestamp = time.UnixMilli(msg.Data.TradeTime)
+	}
+
+	trade := market.UnifiedTrade{
+		Symbol:        unifiedSymbol.Symbol,
+		UnifiedSymbol: unifiedSymbol,
+		Timestamp:     timestamp,
+		TradeID:       strconv.FormatInt(tradeID, 10),
+		Price:         parseFloat(msg.Data.Price),
+		Volume:        parseFloat(msg.Data.Quantity),
+		Side:          side,
+		Raw:           msg,
+	}
+
+	return &market.UnifiedMessage{
+		Exchange:      "binance",
+		Symbol:        unifiedSymbol.Symbol,
+		UnifiedSymbol: unifiedSymbol,
+		MessageType:   market.MessageTypeTrade,
+		Timestamp:     timestamp,
+		Data:          trade,
+	}, nil
+}
+
+func (p *BinanceParser) parseKline(rawData []byte, timestamp time.Time) (*market.UnifiedMessage, error) {
+	var msg BinanceKlineMessage
+	if err := json.Unmarshal(rawData, &msg); err != nil {
+		return nil, fmt.Errorf("failed to parse kline: %w", err)
+	}
+
+	unifiedSymbol, err := p.symbolRegistry.ConvertToUnified("binance", msg.Data.Symbol, "spot")
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert symbol %s: %w", msg.Data.Symbol, err)
+	}
+
+	k := msg.Data.Kline
+	kline := market.UnifiedKline{
+		Symbol:        unifiedSymbol.Symbol,
+		UnifiedSymbol: unifiedSymbol,
+		Interval:      k.Interval,
+		OpenTime:      time.UnixMilli(k.StartTime),
+		CloseTime:     time.UnixMilli(k.CloseTime + 1),
+		Open:          parseFloat(k.Open),
+		High:          parseFloat(k.High),
+		Low:           parseFloat(k.Low),
+		Close:         parseFloat(k.Close),
+		Volume:        parseFloat(k.Volume),
+		QuoteVolume:   parseFloat(k.QuoteVolume),
+		Trades:        k.Trades,
+		Closed:        k.Closed,
+		Source:        market.KlineSourceExchange,
+		Raw:           msg,
+	}
+
+	return &market.UnifiedMessage{
+		Exchange:      "binance",
+		Symbol:        unifiedSymbol.Symbol,
+		UnifiedSymbol: unifiedSymbol,
+		MessageType:   market.MessageTypeKline,
+		Timestamp:     timestamp,
+		Data:          kline,
+	}, nil
+}
@@ -0,0 +1,151 @@
+package parsers
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"daemon-go/internal/market"
+)
+
+// BinanceFuturesParser - парсер потоков Binance USDⓈ-M (fstream): стакан, лучшие цены,
+// маркировочная и индексная цены и ставка финансирования
+type BinanceFuturesParser struct {
+	symbolRegistry *market.SymbolRegistry
+}
+
+// BinanceFuturesDepthMessage - частичный стакан <symbol>@depth<N>@100ms. В отличие от спота
+// приходит с s/U/u и уровнями в b/a, но каждый раз содержит полный срез верхних уровней.
+// Поля, различающиеся только регистром (e/E, u/U), объявлены парами: encoding/json сопоставляет
+// ключи без учета регистра, если нет точного совпадения
+type BinanceFuturesDepthMessage struct {
+	Stream string `json:"stream"`
+	Data   struct {
+		EventType       string          `json:"e"`
+		EventTime       int64           `json:"E"`
+		TransactionTime int64           `json:"T"`
+		Symbol          string          `json:"s"`
+		FirstUpdateID   int64           `json:"U"`
+		FinalUpdateID   int64           `json:"u"`
+		Bids            [][]json.Number `json:"b"`
+		Asks            [][]json.Number `json:"a"`
+	} `json:"data"`
+}
+
+// BinanceMarkPriceMessage - поток <symbol>@markPrice@1s: p - маркировочная цена, i - индекс,
+// r - ставка текущего периода, T - время ее расчета. Прогноза следующей ставки Binance не публикует
+type BinanceMarkPriceMessage struct {
+	Stream string `json:"stream"`
+	Data   struct {
+		EventType            string `json:"e"`
+		EventTime            int64  `json:"E"`
+		Symbol               string `json:"s"`
+		MarkPrice            string `json:"p"`
+		EstimatedSettlePrice string `json:"P"`
+		IndexPrice           string `json:"i"`
+		FundingRate          string `json:"r"`
+		NextFundingTime      int64  `json:"T"`
+	} `json:"data"`
+}
+
+func NewBinanceFuturesParser() *BinanceFuturesParser {
+	return &BinanceFuturesParser{
+		symbolRegistry: market.NewSymbolRegistry(),
+	}
+}
+
+// ParseMessage разбирает сообщение потока; ответы на подписку (без stream) пропускаются
+func (p *BinanceFuturesParser) ParseMessage(rawData []byte) ([]market.UnifiedMessage, error) {
+	var streamMessage struct {
+		Stream string `json:"stream"`
+	}
+	if err := json.Unmarshal(rawData, &streamMessage); err != nil {
+		return nil, fmt.Errorf("failed to parse stream: %w", err)
+	}
+
+	switch {
+	case streamMessage.Stream == "":
+		return nil, nil
+	case contains(streamMessage.Stream, "@depth"):
+		return p.parseOrderBook(rawData)
+	case contains(streamMessage.Stream, "@bookTicker"):
+		return p.parseBestPrice(rawData)
+	case contains(streamMessage.Stream, "@markPrice"):
+		return p.parseMarkPrice(rawData)
+	default:
+		return nil, fmt.Errorf("unknown futures stream type: %s", streamMessage.Stream)
+	}
+}
+
+func (p *BinanceFuturesParser) parseOrderBook(rawData []byte) ([]market.UnifiedMessage, error) {
+	var msg BinanceFuturesDepthMessage
+	if err := json.Unmarshal(rawData, &msg); err != nil {
+		return nil, fmt.Errorf("failed to parse futures orderbook: %w", err)
+	}
+
+	symbol := msg.Data.Symbol
+	if symbol == "" {
+		symbol = strings.ToUpper(strings.SplitN(msg.Stream, "@", 2)[0])
+	}
+	unifiedSymbol, err := p.symbolRegistry.ConvertToUnified("binance", symbol, market.MarketTypeFutures)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert symbol %s: %w", symbol, err)
+	}
+
+	return []market.UnifiedMessage{futuresOrderBook("binance", unifiedSymbol, unixMilli(msg.Data.TransactionTime),
+		numberLevels(msg.Data.Bids), numberLevels(msg.Data.Asks),
+		market.OrderBookUpdateTypeSnapshot, msg.Data.FinalUpdateID, msg)}, nil
+}
+
+func (p *BinanceFuturesParser) parseBestPrice(rawData []byte) ([]market.UnifiedMessage, error) {
+	var msg BinanceBookTickerMessage
+	if err := json.Unmarshal(rawData, &msg); err != nil {
+		return nil, fmt.Errorf("failed to parse futures book ticker: %w", err)
+	}
+
+	unifiedSymbol, err := p.symbolRegistry.ConvertToUnified("binance", msg.Data.Symbol, market.MarketTypeFutures)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert symbol %s: %w", msg.Data.Symbol, err)
+	}
+
+	timestamp := time.Now()
+	return []market.UnifiedMessage{{
+		Exchange:      "binance",
+		Symbol:        unifiedSymbol.Symbol,
+		UnifiedSymbol: unifiedSymbol,
+		MessageType:   market.MessageTypeBestPrice,
+		Timestamp:     timestamp,
+		Data: market.UnifiedBestPrice{
+			Symbol:        unifiedSymbol.Symbol,
+			UnifiedSymbol: unifiedSymbol,
+			Timestamp:     timestamp,
+			BestBid:       parseFloat(msg.Data.BidPrice),
+			BestAsk:       parseFloat(msg.Data.AskPrice),
+			BidVolume:     parseFloat(msg.Data.BidQty),
+			AskVolume:     parseFloat(msg.Data.AskQty),
+			Raw:           msg,
+		},
+	}}, nil
+}
+
+// parseMarkPrice разбирает поток markPrice в маркировочную цену, индекс и ставку финансирования
+func (p *BinanceFuturesParser) parseMarkPrice(rawData []byte) ([]market.UnifiedMessage, error) {
+	var msg BinanceMarkPriceMessage
+	if err := json.Unmarshal(rawData, &msg); err != nil {
+		return nil, fmt.Errorf("failed to parse mark price: %w", err)
+	}
+
+	unifiedSymbol, err := p.symbolRegistry.ConvertToUnified("binance", msg.Data.Symbol, market.MarketTypeFutures)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert symbol %s: %w", msg.Data.Symbol, err)
+	}
+
+	timestamp := unixMilli(msg.Data.EventTime)
+	return []market.UnifiedMessage{
+		markPriceMessage("binance", unifiedSymbol, timestamp, parseFloat(msg.Data.MarkPrice), msg),
+		indexPriceMessage("binance", unifiedSymbol, timestamp, parseFloat(msg.Data.IndexPrice), msg),
+		fundingRateMessage("binance", unifiedSymbol, timestamp, parseFloat(msg.Data.FundingRate),
+			0, false, fundingTime(msg.Data.NextFundingTime), msg),
+	}, nil
+}
@@ -3,6 +3,8 @@ package parsers
 import (
 	"encoding/json"
 	"fmt"
+	"strconv"
+	"strings"
 	"time"
 
 	"daemon-go/internal/market"
@@ -26,8 +28,8 @@ type BybitOrderBookData struct {
 	Symbol string      `json:"s"`
 	Bids   [][]string  `json:"b"`
 	Asks   [][]string  `json:"a"`
-	Update interface{} `json:"u"` // может быть int или string
-	Seq    int64       `json:"seq"`
+	Update interface{} `json:"u"`   // может быть int или string
+	Seq    int64       `json:"seq"` // кросс-последовательность, для контроля разрывов используется u
 }
 
 // BybitTickerData - формат ticker от Bybit
@@ -46,6 +48,31 @@ type BybitTickerData struct {
 	PrevPrice24h string `json:"prevPrice24h"`
 }
 
+// BybitTradeData - формат сделки от Bybit (publicTrade.{symbol}), в data массив сделок
+type BybitTradeData struct {
+	Timestamp int64  `json:"T"`
+	Symbol    string `json:"s"`
+	Side      string `json:"S"` // сторона тейкера: Buy/Sell
+	Volume    string `json:"v"`
+	Price     string `json:"p"`
+	TradeID   string `json:"i"`
+}
+
+// BybitKlineData - формат свечи от Bybit (kline.{interval}.{symbol}), в data массив свечей.
+// interval - минуты числом или D/W/M, confirm - свеча закрыта
+type BybitKlineData struct {
+	Start    int64  `json:"start"`
+	End      int64  `json:"end"` // последняя миллисекунда свечи
+	Interval string `json:"interval"`
+	Open     string `json:"open"`
+	Close    string `json:"close"`
+	High     string `json:"high"`
+	Low      string `json:"low"`
+	Volume   string `json:"volume"`
+	Turnover string `json:"turnover"`
+	Confirm  bool   `json:"confirm"`
+}
+
 func NewBybitParser() *BybitParser {
 	return &BybitParser{
 		symbolRegistry: market.NewSymbolRegistry(),
@@ -73,6 +100,10 @@ func (p *BybitParser) ParseMessage(exchange string, rawData []byte) (*market.Uni
 		return p.parseOrderBook(wsMsg, timestamp)
 	case contains(wsMsg.Topic, "tickers"):
 		return p.parseTicker(wsMsg, timestamp)
+	case contains(wsMsg.Topic, "publicTrade"):
+		return p.parseTrades(wsMsg, timestamp)
+	case strings.HasPrefix(wsMsg.Topic, "kline."):
+		return p.parseKline(wsMsg, timestamp)
 	default:
 		return nil, fmt.Errorf("unknown Bybit topic: %s", wsMsg.Topic)
 	}
@@ -130,9 +161,12 @@ func (p *BybitParser) parseOrderBook(wsMsg BybitWebSocketMessage, timestamp time
 		}
 	}
 
-	// Определяем тип обновления на основе поля type
+	updateID := parseUpdateID(orderBookData.Update)
+
+	// Определяем тип обновления на основе поля type.
+	// Delta с u=1 означает перезапуск сервиса Bybit и должна трактоваться как снимок
 	var updateType market.OrderBookUpdateType
-	if wsMsg.Type == "snapshot" {
+	if wsMsg.Type == "snapshot" || updateID == 1 {
 		updateType = market.OrderBookUpdateTypeSnapshot
 	} else {
 		updateType = market.OrderBookUpdateTypeIncremental // delta или другие типы
@@ -147,6 +181,8 @@ func (p *BybitParser) parseOrderBook(wsMsg BybitWebSocketMessage, timestamp time
 		Depth:         len(bids) + len(asks),
 		UpdateType:    updateType,
 		Raw:           wsMsg,
+		FirstUpdateID: updateID,
+		LastUpdateID:  updateID,
 	}
 
 	return &market.UnifiedMessage{
@@ -201,3 +237,120 @@ func (p *BybitParser) parseTicker(wsMsg BybitWebSocketMessage, timestamp time.Ti
 		Data:          ticker,
 	}, nil
 }
+
+// parseTrades разбирает пачку сделок; Data сообщения - []market.UnifiedTrade (см. market.SplitTrades)
+func (p *BybitParser) parseTrades(wsMsg BybitWebSocketMessage, timestamp time.Time) (*market.UnifiedMessage, error) {
+	var tradesData []BybitTradeData
+
+	dataBytes, err := json.Marshal(wsMsg.Data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal Bybit trade data: %w", err)
+	}
+
+	if err := json.Unmarshal(dataBytes, &tradesData); err != nil {
+		return nil, fmt.Errorf("failed to parse Bybit trade data: %w", err)
+	}
+	if len(tradesData) == 0 {
+		return nil, nil
+	}
+
+	unifiedSymbol, err := p.symbolRegistry.ConvertToUnified("bybit", tradesData[0].Symbol, "spot")
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert Bybit symbol %s: %w", tradesData[0].Symbol, err)
+	}
+
+	trades := make([]market.UnifiedTrade, 0, len(tradesData))
+	for _, t := range tradesData {
+		side := market.TradeSideBuy
+		if t.Side == "Sell" {
+			side = market.TradeSideSell
+		}
+		tradeTime := timestamp
+		if t.Timestamp > 0 {
+			tradeTime = time.UnixMilli(t.Timestamp)
+		}
+		trades = append(trades, market.UnifiedTrade{
+			Symbol:        unifiedSymbol.Symbol,
+			UnifiedSymbol: unifiedSymbol,
+			Timestamp:     tradeTime,
+			TradeID:       t.TradeID,
+			Price:         parseFloat(t.Price),
+			Volume:        parseFloat(t.Volume),
+			Side:          side,
+			Raw:           t,
+		})
+	}
+
+	return &market.UnifiedMessage{
+		Exchange:      "bybit",
+		Symbol:        unifiedSymbol.Symbol,
+		UnifiedSymbol: unifiedSymbol,
+		MessageType:   market.MessageTypeTrade,
+		Timestamp:     timestamp,
+		Data:          trades,
+	}, nil
+}
+
+func (p *BybitParser) parseKline(wsMsg BybitWebSocketMessage, timestamp time.Time) (*market.UnifiedMessage, error) {
+	var klinesData []BybitKlineData
+
+	dataBytes, err := json.Marshal(wsMsg.Data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal Bybit kline data: %w", err)
+	}
+
+	if err := json.Unmarshal(dataBytes, &klinesData); err != nil {
+		return nil, fmt.Errorf("failed to parse Bybit kline data: %w", err)
+	}
+	if len(klinesData) == 0 {
+		return nil, nil
+	}
+
+	// Символа в данных нет, берем его из topic (kline.1.BTCUSDT)
+	symbol := wsMsg.Topic[strings.LastIndex(wsMsg.Topic, ".")+1:]
+	unifiedSymbol, err := p.symbolRegistry.ConvertToUnified("bybit", symbol, "spot")
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert Bybit symbol %s: %w", symbol, err)
+	}
+
+	// В пачке последняя свеча - текущая
+	k := klinesData[len(klinesData)-1]
+	kline := market.UnifiedKline{
+		Symbol:        unifiedSymbol.Symbol,
+		UnifiedSymbol: unifiedSymbol,
+		Interval:      klineInterval(k.Interval),
+		OpenTime:      time.UnixMilli(k.Start),
+		CloseTime:     time.UnixMilli(k.End + 1),
+		Open:          parseFloat(k.Open),
+		High:          parseFloat(k.High),
+		Low:           parseFloat(k.Low),
+		Close:         parseFloat(k.Close),
+		Volume:        parseFloat(k.Volume),
+		QuoteVolume:   parseFloat(k.Turnover),
+		Closed:        k.Confirm,
+		Source:        market.KlineSourceExchange,
+		Raw:           k,
+	}
+
+	return &market.UnifiedMessage{
+		Exchange:      "bybit",
+		Symbol:        unifiedSymbol.Symbol,
+		UnifiedSymbol: unifiedSymbol,
+		MessageType:   market.MessageTypeKline,
+		Timestamp:     timestamp,
+		Data:          kline,
+	}, nil
+}
+
+// parseUpdateID разбирает поле u, которое Bybit присылает числом или строкой
+func parseUpdateID(v interface{}) int64 {
+	switch u := v.(type) {
+	case float64:
+		return int64(u)
+	case string:
+		id, _ := strconv.ParseInt(u, 10, 64)
+		return id
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,153 @@
+package parsers
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+
+	"daemon-go/internal/market"
+)
+
+// BybitLinearParser - парсер публичного потока Bybit v5 linear: стакан и tickers
+// (маркировочная и индексная цены, ставка финансирования). Delta tickers накладывается
+// на последний снимок символа, чтобы ставка всегда публиковалась вместе со временем расчета
+type BybitLinearParser struct {
+	symbolRegistry *market.SymbolRegistry
+
+	mu      sync.Mutex
+	tickers map[string]BybitLinearTickerData // [символ биржи] последнее состояние tickers
+}
+
+// BybitLinearMessage - сообщение потока linear; ответы на op (subscribe, pong) приходят без topic
+type BybitLinearMessage struct {
+	Topic string          `json:"topic"`
+	Type  string          `json:"type"` // snapshot или delta
+	Ts    int64           `json:"ts"`
+	Data  json.RawMessage `json:"data"`
+}
+
+// BybitLinearOrderBookData - стакан orderbook.{depth}.{symbol}
+type BybitLinearOrderBookData struct {
+	Symbol   string          `json:"s"`
+	Bids     [][]json.Number `json:"b"`
+	Asks     [][]json.Number `json:"a"`
+	UpdateID int64           `json:"u"`
+}
+
+// BybitLinearTickerData - tickers.{symbol}. Delta содержит только изменившиеся поля,
+// поэтому пустые значения означают "без изменений"
+type BybitLinearTickerData struct {
+	Symbol          string `json:"symbol"`
+	MarkPrice       string `json:"markPrice"`
+	IndexPrice      string `json:"indexPrice"`
+	FundingRate     string `json:"fundingRate"`
+	NextFundingTime string `json:"nextFundingTime"`
+}
+
+func NewBybitLinearParser() *BybitLinearParser {
+	return &BybitLinearParser{
+		symbolRegistry: market.NewSymbolRegistry(),
+		tickers:        make(map[string]BybitLinearTickerData),
+	}
+}
+
+// ParseMessage разбирает сообщение потока; служебные ответы пропускаются
+func (p *BybitLinearParser) ParseMessage(rawData []byte) ([]market.UnifiedMessage, error) {
+	var wsMsg BybitLinearMessage
+	if err := json.Unmarshal(rawData, &wsMsg); err != nil {
+		return nil, fmt.Errorf("failed to parse Bybit linear message: %w", err)
+	}
+
+	switch {
+	case wsMsg.Topic == "":
+		return nil, nil
+	case strings.HasPrefix(wsMsg.Topic, "orderbook."):
+		return p.parseOrderBook(wsMsg)
+	case strings.HasPrefix(wsMsg.Topic, "tickers."):
+		return p.parseTicker(wsMsg)
+	default:
+		return nil, fmt.Errorf("unknown Bybit linear topic: %s", wsMsg.Topic)
+	}
+}
+
+func (p *BybitLinearParser) parseOrderBook(wsMsg BybitLinearMessage) ([]market.UnifiedMessage, error) {
+	var data BybitLinearOrderBookData
+	if err := json.Unmarshal(wsMsg.Data, &data); err != nil {
+		return nil, fmt.Errorf("failed to parse Bybit linear orderbook: %w", err)
+	}
+
+	unifiedSymbol, err := p.symbolRegistry.ConvertToUnified("bybit", data.Symbol, market.MarketTypeFutures)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert Bybit symbol %s: %w", data.Symbol, err)
+	}
+
+	// Как и на споте, delta с u=1 - перезапуск сервиса Bybit, трактуется как снимок
+	updateType := market.OrderBookUpdateTypeIncremental
+	if wsMsg.Type == "snapshot" || data.UpdateID == 1 {
+		updateType = market.OrderBookUpdateTypeSnapshot
+	}
+
+	return []market.UnifiedMessage{futuresOrderBook("bybit", unifiedSymbol, unixMilli(wsMsg.Ts),
+		numberLevels(data.Bids), numberLevels(data.Asks), updateType, data.UpdateID, wsMsg)}, nil
+}
+
+// parseTicker накладывает сообщение на состояние символа и публикует изменившиеся
+// маркировочную цену, индекс и ставку финансирования
+func (p *BybitLinearParser) parseTicker(wsMsg BybitLinearMessage) ([]market.UnifiedMessage, error) {
+	var data BybitLinearTickerData
+	if err := json.Unmarshal(wsMsg.Data, &data); err != nil {
+		return nil, fmt.Errorf("failed to parse Bybit linear ticker: %w", err)
+	}
+
+	symbol := data.Symbol
+	if symbol == "" {
+		symbol = strings.TrimPrefix(wsMsg.Topic, "tickers.")
+	}
+	unifiedSymbol, err := p.symbolRegistry.ConvertToUnified("bybit", symbol, market.MarketTypeFutures)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert Bybit symbol %s: %w", symbol, err)
+	}
+
+	p.mu.Lock()
+	state := p.tickers[symbol]
+	if wsMsg.Type == "snapshot" {
+		state = BybitLinearTickerData{Symbol: symbol}
+	}
+	state = mergeBybitTicker(state, data)
+	p.tickers[symbol] = state
+	p.mu.Unlock()
+
+	timestamp := unixMilli(wsMsg.Ts)
+	var messages []market.UnifiedMessage
+	if data.MarkPrice != "" {
+		messages = append(messages, markPriceMessage("bybit", unifiedSymbol, timestamp, parseFloat(state.MarkPrice), data))
+	}
+	if data.IndexPrice != "" {
+		messages = append(messages, indexPriceMessage("bybit", unifiedSymbol, timestamp, parseFloat(state.IndexPrice), data))
+	}
+	if (data.FundingRate != "" || data.NextFundingTime != "") && state.FundingRate != "" {
+		next, _ := strconv.ParseInt(state.NextFundingTime, 10, 64)
+		messages = append(messages, fundingRateMessage("bybit", unifiedSymbol, timestamp, parseFloat(state.FundingRate),
+			0, false, fundingTime(next), state))
+	}
+	return messages, nil
+}
+
+// mergeBybitTicker переносит непустые поля delta в состояние
+func mergeBybitTicker(state, delta BybitLinearTickerData) BybitLinearTickerData {
+	if delta.MarkPrice != "" {
+		state.MarkPrice = delta.MarkPrice
+	}
+	if delta.IndexPrice != "" {
+		state.IndexPrice = delta.IndexPrice
+	}
+	if delta.FundingRate != "" {
+		state.FundingRate = delta.FundingRate
+	}
+	if delta.NextFundingTime != "" {
+		state.NextFundingTime = delta.NextFundingTime
+	}
+	return state
+}
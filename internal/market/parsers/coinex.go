@@ -3,6 +3,7 @@ package parsers
 import (
 	"encoding/json"
 	"fmt"
+	"strconv"
 	"time"
 
 	"daemon-go/internal/market"
@@ -40,6 +41,15 @@ type CoinexDepthData struct {
 // CoinexTickerParams - параметры ticker сообщения от CoinEx
 type CoinexTickerParams []interface{}
 
+// CoinexDeal - сделка от CoinEx (deals.update: [market, [deal, ...]]); type - сторона тейкера
+type CoinexDeal struct {
+	ID     int64   `json:"id"`
+	Time   float64 `json:"time"` // секунды с дробной частью
+	Price  string  `json:"price"`
+	Amount string  `json:"amount"`
+	Type   string  `json:"type"`
+}
+
 func NewCoinexParser() *CoinexParser {
 	return &CoinexParser{
 		symbolRegistry: market.NewSymbolRegistry(),
@@ -64,6 +74,8 @@ func (p *CoinexParser) ParseMessage(exchange string, rawData []byte) (*market.Un
 		return p.parseDepthUpdate(wsMsg, timestamp)
 	case "state.update":
 		return p.parseStateUpdate(wsMsg, timestamp)
+	case "deals.update":
+		return p.parseDealsUpdate(wsMsg, timestamp)
 	case "server.ping":
 		// Пинг сообщения не нужно обрабатывать как unified messages
 		return nil, nil
@@ -249,3 +261,62 @@ func (p *CoinexParser) parseStateUpdate(wsMsg CoinexWebSocketMessage, timestamp
 		Data:          &ticker,
 	}, nil
 }
+
+// parseDealsUpdate разбирает пачку сделок; Data сообщения - []market.UnifiedTrade (см. market.SplitTrades)
+func (p *CoinexParser) parseDealsUpdate(wsMsg CoinexWebSocketMessage, timestamp time.Time) (*market.UnifiedMessage, error) {
+	paramsArray, ok := wsMsg.Params.([]interface{})
+	if !ok || len(paramsArray) < 2 {
+		return nil, fmt.Errorf("invalid CoinEx deals update params format")
+	}
+
+	symbol, ok := paramsArray[0].(string)
+	if !ok {
+		return nil, fmt.Errorf("invalid CoinEx symbol in deals update")
+	}
+
+	dealsBytes, err := json.Marshal(paramsArray[1])
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal CoinEx deals: %w", err)
+	}
+
+	var deals []CoinexDeal
+	if err := json.Unmarshal(dealsBytes, &deals); err != nil {
+		return nil, fmt.Errorf("failed to parse CoinEx deals: %w", err)
+	}
+
+	unifiedSymbol, err := p.symbolRegistry.ConvertToUnified("coinex", symbol, "spot")
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert CoinEx symbol %s: %w", symbol, err)
+	}
+
+	trades := make([]market.UnifiedTrade, 0, len(deals))
+	for _, d := range deals {
+		side := market.TradeSideBuy
+		if d.Type == "sell" {
+			side = market.TradeSideSell
+		}
+		tradeTime := timestamp
+		if d.Time > 0 {
+			tradeTime = time.UnixMicro(int64(d.Time * 1e6))
+		}
+		trades = append(trades, market.UnifiedTrade{
+			Symbol:        unifiedSymbol.Symbol,
+			UnifiedSymbol: unifiedSymbol,
+			Timestamp:     tradeTime,
+			TradeID:       strconv.FormatInt(d.ID, 10),
+			Price:         parseFloat(d.Price),
+			Volume:        parseFloat(d.Amount),
+			Side:          side,
+			Raw:           d,
+		})
+	}
+
+	return &market.UnifiedMessage{
+		Exchange:      "coinex",
+		Symbol:        unifiedSymbol.Symbol,
+		UnifiedSymbol: unifiedSymbol,
+		MessageType:   market.MessageTypeTrade,
+		Timestamp:     timestamp,
+		Data:          trades,
+	}, nil
+}
@@ -0,0 +1,123 @@
+package parsers
+
+import (
+	"encoding/json"
+	"time"
+
+	"daemon-go/internal/market"
+)
+
+// Общие функции парсеров линейных бессрочных контрактов (BinanceFuturesParser, BybitLinearParser,
+// KucoinFuturesParser, HTXSwapParser). Символы разбираются с типом рынка futures: BTCUSDT
+
+// numberLevels разбирает уровни стакана [[цена, объем]]; биржи присылают числа и строками, и числами
+func numberLevels(levels [][]json.Number) []market.PriceLevel {
+	result := make([]market.PriceLevel, 0, len(levels))
+	for _, level := range levels {
+		if len(level) < 2 {
+			continue
+		}
+		price, _ := level[0].Float64()
+		volume, _ := level[1].Float64()
+		result = append(result, market.PriceLevel{Price: price, Volume: volume})
+	}
+	return result
+}
+
+// unixMilli переводит миллисекунды биржи во время; 0 - текущее время
+func unixMilli(ms int64) time.Time {
+	if ms <= 0 {
+		return time.Now()
+	}
+	return time.UnixMilli(ms)
+}
+
+// futuresOrderBook собирает сообщение стакана бессрочного контракта
+func futuresOrderBook(exchange string, symbol *market.UnifiedSymbol, ts time.Time, bids, asks []market.PriceLevel,
+	updateType market.OrderBookUpdateType, updateID int64, raw interface{}) market.UnifiedMessage {
+	return market.UnifiedMessage{
+		Exchange:      exchange,
+		Symbol:        symbol.Symbol,
+		UnifiedSymbol: symbol,
+		MessageType:   market.MessageTypeOrderBook,
+		Timestamp:     ts,
+		Data: market.UnifiedOrderBook{
+			Symbol:        symbol.Symbol,
+			UnifiedSymbol: symbol,
+			Timestamp:     ts,
+			Bids:          bids,
+			Asks:          asks,
+			Depth:         len(bids) + len(asks),
+			UpdateType:    updateType,
+			Raw:           raw,
+			LastUpdateID:  updateID,
+		},
+	}
+}
+
+// markPriceMessage собирает сообщение маркировочной цены
+func markPriceMessage(exchange string, symbol *market.UnifiedSymbol, ts time.Time, markPrice float64, raw interface{}) market.UnifiedMessage {
+	return market.UnifiedMessage{
+		Exchange:      exchange,
+		Symbol:        symbol.Symbol,
+		UnifiedSymbol: symbol,
+		MessageType:   market.MessageTypeMarkPrice,
+		Timestamp:     ts,
+		Data: market.UnifiedMarkPrice{
+			Symbol:        symbol.Symbol,
+			UnifiedSymbol: symbol,
+			Timestamp:     ts,
+			MarkPrice:     markPrice,
+			Raw:           raw,
+		},
+	}
+}
+
+// indexPriceMessage собирает сообщение индексной цены
+func indexPriceMessage(exchange string, symbol *market.UnifiedSymbol, ts time.Time, indexPrice float64, raw interface{}) market.UnifiedMessage {
+	return market.UnifiedMessage{
+		Exchange:      exchange,
+		Symbol:        symbol.Symbol,
+		UnifiedSymbol: symbol,
+		MessageType:   market.MessageTypeIndexPrice,
+		Timestamp:     ts,
+		Data: market.UnifiedIndexPrice{
+			Symbol:        symbol.Symbol,
+			UnifiedSymbol: symbol,
+			Timestamp:     ts,
+			IndexPrice:    indexPrice,
+			Raw:           raw,
+		},
+	}
+}
+
+// fundingRateMessage собирает сообщение ставки финансирования; прогноз передается с флагом hasPredicted,
+// так как нулевая и отрицательная ставки допустимы
+func fundingRateMessage(exchange string, symbol *market.UnifiedSymbol, ts time.Time, rate float64,
+	predicted float64, hasPredicted bool, nextFundingTime time.Time, raw interface{}) market.UnifiedMessage {
+	return market.UnifiedMessage{
+		Exchange:      exchange,
+		Symbol:        symbol.Symbol,
+		UnifiedSymbol: symbol,
+		MessageType:   market.MessageTypeFundingRate,
+		Timestamp:     ts,
+		Data: market.UnifiedFundingRate{
+			Symbol:          symbol.Symbol,
+			UnifiedSymbol:   symbol,
+			Timestamp:       ts,
+			FundingRate:     rate,
+			PredictedRate:   predicted,
+			HasPredicted:    hasPredicted,
+			NextFundingTime: nextFundingTime,
+			Raw:             raw,
+		},
+	}
+}
+
+// fundingTime переводит время расчета в миллисекундах во время; 0 - неизвестно (нулевое время)
+func fundingTime(ms int64) time.Time {
+	if ms <= 0 {
+		return time.Time{}
+	}
+	return time.UnixMilli(ms)
+}
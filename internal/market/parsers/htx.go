@@ -6,6 +6,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"strconv"
 	"strings"
 	"time"
 
@@ -24,15 +25,21 @@ type HTXWebSocketMessage struct {
 	Tick interface{} `json:"tick"`
 	ID   string      `json:"id,omitempty"`
 	Rep  string      `json:"rep,omitempty"`
+	// Ответ на запрос req: статус и данные вместо tick
+	Status string      `json:"status,omitempty"`
+	Data   interface{} `json:"data,omitempty"`
 }
 
-// HTXOrderBookTick - формат orderbook tick от HTX
+// HTXOrderBookTick - формат orderbook tick от HTX.
+// Канал market.$symbol.mbp.$levels присылает инкрементальные обновления с seqNum/prevSeqNum
 type HTXOrderBookTick struct {
-	ID      int64       `json:"id"`
-	Ts      int64       `json:"ts"`
-	Version int64       `json:"version"`
-	Bids    [][]float64 `json:"bids"`
-	Asks    [][]float64 `json:"asks"`
+	ID         int64       `json:"id"`
+	Ts         int64       `json:"ts"`
+	Version    int64       `json:"version"`
+	SeqNum     int64       `json:"seqNum"`
+	PrevSeqNum int64       `json:"prevSeqNum"`
+	Bids       [][]float64 `json:"bids"`
+	Asks       [][]float64 `json:"asks"`
 }
 
 // HTXTickerTick - формат ticker tick от HTX
@@ -62,6 +69,33 @@ type HTXBBOTick struct {
 	AskSize float64 `json:"askSize"`
 }
 
+// HTXTradeTick - формат сделок от HTX (market.$symbol.trade.detail), в data пачка сделок.
+// direction - сторона тейкера
+type HTXTradeTick struct {
+	ID   int64 `json:"id"`
+	Ts   int64 `json:"ts"`
+	Data []struct {
+		TradeID   int64   `json:"tradeId"`
+		Ts        int64   `json:"ts"`
+		Amount    float64 `json:"amount"`
+		Price     float64 `json:"price"`
+		Direction string  `json:"direction"`
+	} `json:"data"`
+}
+
+// HTXKlineTick - формат свечи от HTX (market.$symbol.kline.$period): id - начало свечи в секундах,
+// amount - объем в base валюте, vol - в quote валюте. Признака закрытия свечи нет
+type HTXKlineTick struct {
+	ID     int64   `json:"id"`
+	Open   float64 `json:"open"`
+	Close  float64 `json:"close"`
+	Low    float64 `json:"low"`
+	High   float64 `json:"high"`
+	Amount float64 `json:"amount"`
+	Vol    float64 `json:"vol"`
+	Count  int64   `json:"count"`
+}
+
 func NewHTXParser() *HTXParser {
 	return &HTXParser{
 		symbolRegistry: market.NewSymbolRegistry(),
@@ -141,6 +175,16 @@ func (p *HTXParser) ParseMessage(exchange string, rawData []byte) (*market.Unifi
 		timestamp = time.Unix(wsMsg.Ts/1000, (wsMsg.Ts%1000)*1000000)
 	}
 
+	// Ответ на req market.$symbol.mbp.$levels - полный снимок с seqNum, база для diff потока
+	if contains(wsMsg.Rep, ".mbp.") {
+		if wsMsg.Status != "" && wsMsg.Status != "ok" {
+			return nil, fmt.Errorf("HTX snapshot request %s failed: status %s", wsMsg.Rep, wsMsg.Status)
+		}
+		wsMsg.Ch = wsMsg.Rep
+		wsMsg.Tick = wsMsg.Data
+		return p.parseOrderBook(wsMsg, timestamp)
+	}
+
 	// Обрабатываем подтверждения подписок
 	if wsMsg.ID != "" && wsMsg.Ch == "" {
 		return nil, nil // Подтверждение подписки - не нужно обрабатывать
@@ -148,12 +192,16 @@ func (p *HTXParser) ParseMessage(exchange string, rawData []byte) (*market.Unifi
 
 	// Определяем тип сообщения по каналу
 	switch {
-	case contains(wsMsg.Ch, "depth"):
+	case contains(wsMsg.Ch, "depth"), contains(wsMsg.Ch, ".mbp."):
 		return p.parseOrderBook(wsMsg, timestamp)
 	case contains(wsMsg.Ch, "ticker"):
 		return p.parseTicker(wsMsg, timestamp)
 	case contains(wsMsg.Ch, "bbo"):
 		return p.parseBBO(wsMsg, timestamp)
+	case contains(wsMsg.Ch, ".trade.detail"):
+		return p.parseTrades(wsMsg, timestamp)
+	case contains(wsMsg.Ch, ".kline."):
+		return p.parseKline(wsMsg, timestamp)
 	case wsMsg.Ch == "":
 		return nil, nil // Пустой канал - пропускаем
 	default:
@@ -205,6 +253,12 @@ func (p *HTXParser) parseOrderBook(wsMsg HTXWebSocketMessage, timestamp time.Tim
 		}
 	}
 
+	// HTX depth, mbp.refresh и ответ на req mbp - полные снимки, mbp - инкрементальные обновления
+	updateType := market.OrderBookUpdateTypeSnapshot
+	if contains(wsMsg.Ch, ".mbp.") && !contains(wsMsg.Ch, ".refresh.") && wsMsg.Rep == "" {
+		updateType = market.OrderBookUpdateTypeIncremental
+	}
+
 	orderbook := market.UnifiedOrderBook{
 		Symbol:        unifiedSymbol.Symbol,
 		UnifiedSymbol: unifiedSymbol,
@@ -212,8 +266,10 @@ func (p *HTXParser) parseOrderBook(wsMsg HTXWebSocketMessage, timestamp time.Tim
 		Bids:          bids,
 		Asks:          asks,
 		Depth:         len(bids) + len(asks),
-		UpdateType:    market.OrderBookUpdateTypeSnapshot, // HTX depth - полные снимки
+		UpdateType:    updateType,
 		Raw:           wsMsg,
+		LastUpdateID:  tickData.SeqNum,
+		PrevUpdateID:  tickData.PrevSeqNum,
 	}
 
 	return &market.UnifiedMessage{
@@ -314,11 +370,117 @@ func (p *HTXParser) parseBBO(wsMsg HTXWebSocketMessage, timestamp time.Time) (*m
 	}, nil
 }
 
+// parseTrades разбирает пачку сделок; Data сообщения - []market.UnifiedTrade (см. market.SplitTrades)
+func (p *HTXParser) parseTrades(wsMsg HTXWebSocketMessage, timestamp time.Time) (*market.UnifiedMessage, error) {
+	var tradeData HTXTradeTick
+
+	tickBytes, err := json.Marshal(wsMsg.Tick)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal HTX trade tick: %w", err)
+	}
+
+	if err := json.Unmarshal(tickBytes, &tradeData); err != nil {
+		return nil, fmt.Errorf("failed to parse HTX trade tick: %w", err)
+	}
+
+	symbol := p.extractSymbolFromChannel(wsMsg.Ch)
+	unifiedSymbol, err := p.symbolRegistry.ConvertToUnified("htx", symbol, "spot")
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert HTX symbol %s: %w", symbol, err)
+	}
+
+	trades := make([]market.UnifiedTrade, 0, len(tradeData.Data))
+	for _, t := range tradeData.Data {
+		side := market.TradeSideBuy
+		if t.Direction == "sell" {
+			side = market.TradeSideSell
+		}
+		tradeTime := timestamp
+		if t.Ts > 0 {
+			tradeTime = time.UnixMilli(t.Ts)
+		}
+		trades = append(trades, market.UnifiedTrade{
+			Symbol:        unifiedSymbol.Symbol,
+			UnifiedSymbol: unifiedSymbol,
+			Timestamp:     tradeTime,
+			TradeID:       strconv.FormatInt(t.TradeID, 10),
+			Price:         t.Price,
+			Volume:        t.Amount,
+			Side:          side,
+			Raw:           t,
+		})
+	}
+
+	return &market.UnifiedMessage{
+		Exchange:      "htx",
+		Symbol:        unifiedSymbol.Symbol,
+		UnifiedSymbol: unifiedSymbol,
+		MessageType:   market.MessageTypeTrade,
+		Timestamp:     timestamp,
+		Data:          trades,
+	}, nil
+}
+
+func (p *HTXParser) parseKline(wsMsg HTXWebSocketMessage, timestamp time.Time) (*market.UnifiedMessage, error) {
+	var tick HTXKlineTick
+
+	tickBytes, err := json.Marshal(wsMsg.Tick)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal HTX kline tick: %w", err)
+	}
+
+	if err := json.Unmarshal(tickBytes, &tick); err != nil {
+		return nil, fmt.Errorf("failed to parse HTX kline tick: %w", err)
+	}
+
+	symbol := p.extractSymbolFromChannel(wsMsg.Ch)
+	unifiedSymbol, err := p.symbolRegistry.ConvertToUnified("htx", symbol, "spot")
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert HTX symbol %s: %w", symbol, err)
+	}
+
+	// market.btcusdt.kline.1min -> 1m
+	interval := klineInterval(wsMsg.Ch[strings.LastIndex(wsMsg.Ch, ".")+1:])
+	duration, err := market.ParseKlineInterval(interval)
+	if err != nil {
+		return nil, fmt.Errorf("HTX kline channel %s: %w", wsMsg.Ch, err)
+	}
+
+	openTime := time.Unix(tick.ID, 0)
+	kline := market.UnifiedKline{
+		Symbol:        unifiedSymbol.Symbol,
+		UnifiedSymbol: unifiedSymbol,
+		Interval:      interval,
+		OpenTime:      openTime,
+		CloseTime:     openTime.Add(duration),
+		Open:          tick.Open,
+		High:          tick.High,
+		Low:           tick.Low,
+		Close:         tick.Close,
+		Volume:        tick.Amount,
+		QuoteVolume:   tick.Vol,
+		Trades:        tick.Count,
+		Source:        market.KlineSourceExchange,
+		Raw:           tick,
+	}
+
+	return &market.UnifiedMessage{
+		Exchange:      "htx",
+		Symbol:        unifiedSymbol.Symbol,
+		UnifiedSymbol: unifiedSymbol,
+		MessageType:   market.MessageTypeKline,
+		Timestamp:     timestamp,
+		Data:          kline,
+	}, nil
+}
+
 // extractSymbolFromChannel извлекает символ из канала HTX
 // Примеры каналов:
 // market.btcusdt.depth.step0
 // market.btcusdt.ticker
 // market.btcusdt.bbo
+// market.btcusdt.trade.detail
+// market.btcusdt.kline.1min
 func (p *HTXParser) extractSymbolFromChannel(channel string) string {
 	parts := strings.Split(channel, ".")
 	if len(parts) >= 2 && parts[0] == "market" {
@@ -0,0 +1,140 @@
+package parsers
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"daemon-go/internal/market"
+)
+
+// HTXSwapParser - парсер линейных бессрочных контрактов HTX: стакан market.$contract.depth.step6
+// (linear-swap-ws), маркировочная цена market.$contract.mark_price.1min и индекс
+// market.$contract.basis.1min.close (ws_index). Ставка финансирования приходит только в отдельном
+// notification потоке, поэтому читается через REST swap_batch_funding_rate (ParseFundingRates).
+// Сообщения должны быть распакованы из gzip, объемы стакана - в контрактах
+type HTXSwapParser struct {
+	symbolRegistry *market.SymbolRegistry
+}
+
+// HTXSwapMessage - сообщение потока linear-swap-ws; ответы на sub приходят без ch
+type HTXSwapMessage struct {
+	Ch   string          `json:"ch"`
+	Ts   int64           `json:"ts"`
+	Tick json.RawMessage `json:"tick"`
+}
+
+// HTXSwapDepthTick - снимок 20 уровней стакана (step6)
+type HTXSwapDepthTick struct {
+	Bids    [][]json.Number `json:"bids"`
+	Asks    [][]json.Number `json:"asks"`
+	Version int64           `json:"version"`
+	Ts      int64           `json:"ts"`
+}
+
+// HTXSwapKlineTick - свеча маркировочной цены; close - текущая маркировочная цена
+type HTXSwapKlineTick struct {
+	ID    int64       `json:"id"`
+	Close json.Number `json:"close"`
+}
+
+// HTXSwapBasisTick - свеча базиса: index_price - индекс, contract_price - цена контракта
+type HTXSwapBasisTick struct {
+	ID            int64  `json:"id"`
+	IndexPrice    string `json:"index_price"`
+	ContractPrice string `json:"contract_price"`
+	Basis         string `json:"basis"`
+}
+
+// HTXSwapFundingRate - элемент ответа swap_batch_funding_rate: funding_rate будет списана
+// в funding_time, estimated_rate - прогноз следующего периода (null, пока не рассчитан)
+type HTXSwapFundingRate struct {
+	ContractCode    string `json:"contract_code"`
+	FundingRate     string `json:"funding_rate"`
+	EstimatedRate   string `json:"estimated_rate"`
+	FundingTime     string `json:"funding_time"`
+	NextFundingTime string `json:"next_funding_time"`
+}
+
+func NewHTXSwapParser() *HTXSwapParser {
+	return &HTXSwapParser{
+		symbolRegistry: market.NewSymbolRegistry(),
+	}
+}
+
+// ParseMessage разбирает распакованное сообщение потока; служебные сообщения пропускаются
+func (p *HTXSwapParser) ParseMessage(rawData []byte) ([]market.UnifiedMessage, error) {
+	var wsMsg HTXSwapMessage
+	if err := json.Unmarshal(rawData, &wsMsg); err != nil {
+		return nil, fmt.Errorf("failed to parse HTX swap message: %w", err)
+	}
+	if wsMsg.Ch == "" {
+		return nil, nil
+	}
+
+	// market.BTC-USDT.depth.step6
+	parts := strings.Split(wsMsg.Ch, ".")
+	if len(parts) < 3 {
+		return nil, fmt.Errorf("invalid HTX swap channel: %s", wsMsg.Ch)
+	}
+	unifiedSymbol, err := p.symbolRegistry.ConvertToUnified("htx", parts[1], market.MarketTypeFutures)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert HTX contract %s: %w", parts[1], err)
+	}
+
+	switch parts[2] {
+	case "depth":
+		var tick HTXSwapDepthTick
+		if err := json.Unmarshal(wsMsg.Tick, &tick); err != nil {
+			return nil, fmt.Errorf("failed to parse HTX swap depth: %w", err)
+		}
+		return []market.UnifiedMessage{futuresOrderBook("htx", unifiedSymbol, unixMilli(wsMsg.Ts),
+			numberLevels(tick.Bids), numberLevels(tick.Asks),
+			market.OrderBookUpdateTypeSnapshot, tick.Version, wsMsg)}, nil
+	case "mark_price":
+		var tick HTXSwapKlineTick
+		if err := json.Unmarshal(wsMsg.Tick, &tick); err != nil {
+			return nil, fmt.Errorf("failed to parse HTX swap mark price: %w", err)
+		}
+		markPrice, _ := tick.Close.Float64()
+		return []market.UnifiedMessage{markPriceMessage("htx", unifiedSymbol, unixMilli(wsMsg.Ts), markPrice, tick)}, nil
+	case "basis":
+		var tick HTXSwapBasisTick
+		if err := json.Unmarshal(wsMsg.Tick, &tick); err != nil {
+			return nil, fmt.Errorf("failed to parse HTX swap basis: %w", err)
+		}
+		return []market.UnifiedMessage{indexPriceMessage("htx", unifiedSymbol, unixMilli(wsMsg.Ts), parseFloat(tick.IndexPrice), tick)}, nil
+	default:
+		return nil, fmt.Errorf("unknown HTX swap channel: %s", wsMsg.Ch)
+	}
+}
+
+// ParseFundingRates разбирает ответ REST /linear-swap-api/v1/swap_batch_funding_rate
+func (p *HTXSwapParser) ParseFundingRates(rawData []byte) ([]market.UnifiedMessage, error) {
+	var resp struct {
+		Status string               `json:"status"`
+		ErrMsg string               `json:"err_msg"`
+		Ts     int64                `json:"ts"`
+		Data   []HTXSwapFundingRate `json:"data"`
+	}
+	if err := json.Unmarshal(rawData, &resp); err != nil {
+		return nil, fmt.Errorf("failed to parse HTX funding rates: %w", err)
+	}
+	if resp.Status != "ok" {
+		return nil, fmt.Errorf("HTX funding rates: %s", resp.ErrMsg)
+	}
+
+	timestamp := unixMilli(resp.Ts)
+	messages := make([]market.UnifiedMessage, 0, len(resp.Data))
+	for _, rate := range resp.Data {
+		unifiedSymbol, err := p.symbolRegistry.ConvertToUnified("htx", rate.ContractCode, market.MarketTypeFutures)
+		if err != nil {
+			continue
+		}
+		next, _ := strconv.ParseInt(rate.FundingTime, 10, 64)
+		messages = append(messages, fundingRateMessage("htx", unifiedSymbol, timestamp, parseFloat(rate.FundingRate),
+			parseFloat(rate.EstimatedRate), rate.EstimatedRate != "", fundingTime(next), rate))
+	}
+	return messages, nil
+}
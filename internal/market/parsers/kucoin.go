@@ -3,6 +3,7 @@ package parsers
 import (
 	"encoding/json"
 	"fmt"
+	"strconv"
 	"strings"
 	"time"
 
@@ -24,13 +25,20 @@ type KucoinWebSocketMessage struct {
 	Ts     int64       `json:"ts"`
 }
 
-// KucoinOrderBookData - формат orderbook от Kucoin
+// KucoinOrderBookData - формат orderbook от Kucoin.
+// Топик /market/level2 присылает инкрементальные изменения в changes: [price, size, sequence]
 type KucoinOrderBookData struct {
 	Symbol    string     `json:"symbol"`
 	Sequence  string     `json:"sequence"`
 	Asks      [][]string `json:"asks"`
 	Bids      [][]string `json:"bids"`
 	Timestamp int64      `json:"timestamp"`
+	Changes   *struct {
+		Asks [][]string `json:"asks"`
+		Bids [][]string `json:"bids"`
+	} `json:"changes"`
+	SequenceStart int64 `json:"sequenceStart"`
+	SequenceEnd   int64 `json:"sequenceEnd"`
 }
 
 // KucoinLevel2DepthData - формат Level2Depth от Kucoin для spotMarket топиков
@@ -53,17 +61,25 @@ type KucoinTickerData struct {
 	Timestamp   int64  `json:"time"`
 }
 
-// KucoinMatch - формат сделки от Kucoin
+// KucoinMatch - формат сделки от Kucoin (/market/match); side - сторона тейкера
 type KucoinMatch struct {
-	Symbol       string `json:"symbol"`
-	Sequence     string `json:"sequence"`
-	Side         string `json:"side"`
-	Size         string `json:"size"`
-	Price        string `json:"price"`
-	TakerOrderID string `json:"takerOrderId"`
-	MakerOrderID string `json:"makerOrderId"`
-	TradeID      string `json:"tradeId"`
-	Timestamp    int64  `json:"time"`
+	Symbol       string      `json:"symbol"`
+	Sequence     string      `json:"sequence"`
+	Side         string      `json:"side"`
+	Size         string      `json:"size"`
+	Price        string      `json:"price"`
+	TakerOrderID string      `json:"takerOrderId"`
+	MakerOrderID string      `json:"makerOrderId"`
+	TradeID      string      `json:"tradeId"`
+	Time         interface{} `json:"time"` // наносекунды, строкой или числом
+}
+
+// KucoinCandles - формат свечи от Kucoin (/market/candles:{symbol}_{type}):
+// candles - [начало в секундах, open, close, high, low, объем, оборот]. Признака закрытия нет
+type KucoinCandles struct {
+	Symbol  string   `json:"symbol"`
+	Candles []string `json:"candles"`
+	Time    int64    `json:"time"`
 }
 
 func NewKucoinParser() *KucoinParser {
@@ -102,6 +118,8 @@ func (p *KucoinParser) ParseMessage(exchange string, rawData []byte) (*market.Un
 		return p.parseTicker(wsMsg, timestamp)
 	case contains(wsMsg.Topic, "/market/match"):
 		return p.parseMatch(wsMsg, timestamp)
+	case contains(wsMsg.Topic, "/market/candles"):
+		return p.parseCandles(wsMsg, timestamp)
 	default:
 		return nil, fmt.Errorf("unknown Kucoin topic: %s", wsMsg.Topic)
 	}
@@ -123,6 +141,8 @@ func (p *KucoinParser) parseOrderBook(wsMsg KucoinWebSocketMessage, timestamp ti
 	}
 
 	var asks, bids [][]string
+	var firstID, lastID int64
+	updateType := market.OrderBookUpdateTypeSnapshot // Level2Depth - полные снимки
 
 	// Пробуем разные форматы данных в зависимости от топика
 	if contains(wsMsg.Topic, "/spotMarket/level2Depth") {
@@ -149,6 +169,13 @@ func (p *KucoinParser) parseOrderBook(wsMsg KucoinWebSocketMessage, timestamp ti
 		}
 		asks = orderBookData.Asks
 		bids = orderBookData.Bids
+		if orderBookData.Changes != nil {
+			asks = orderBookData.Changes.Asks
+			bids = orderBookData.Changes.Bids
+			updateType = market.OrderBookUpdateTypeIncremental
+			firstID = orderBookData.SequenceStart
+			lastID = orderBookData.SequenceEnd
+		}
 		// Используем символ из данных, если доступен
 		if orderBookData.Symbol != "" {
 			symbol = orderBookData.Symbol
@@ -192,8 +219,10 @@ func (p *KucoinParser) parseOrderBook(wsMsg KucoinWebSocketMessage, timestamp ti
 		Bids:          bidLevels,
 		Asks:          askLevels,
 		Depth:         len(bidLevels) + len(askLevels),
-		UpdateType:    market.OrderBookUpdateTypeSnapshot, // Level2Depth - полные снимки
+		UpdateType:    updateType,
 		Raw:           wsMsg,
+		FirstUpdateID: firstID,
+		LastUpdateID:  lastID,
 	}
 
 	return &market.UnifiedMessage{
@@ -284,6 +313,9 @@ func (p *KucoinParser) parseMatch(wsMsg KucoinWebSocketMessage, timestamp time.T
 	} else {
 		side = market.TradeSideSell
 	}
+	if ns := parseUpdateID(matchData.Time); ns > 0 {
+		timestamp = time.Unix(0, ns)
+	}
 
 	trade := market.UnifiedTrade{
 		Symbol:        unifiedSymbol.Symbol,
@@ -305,3 +337,59 @@ func (p *KucoinParser) parseMatch(wsMsg KucoinWebSocketMessage, timestamp time.T
 		Data:          trade,
 	}, nil
 }
+
+func (p *KucoinParser) parseCandles(wsMsg KucoinWebSocketMessage, timestamp time.Time) (*market.UnifiedMessage, error) {
+	var candlesData KucoinCandles
+
+	dataBytes, err := json.Marshal(wsMsg.Data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal Kucoin candles data: %w", err)
+	}
+
+	if err := json.Unmarshal(dataBytes, &candlesData); err != nil {
+		return nil, fmt.Errorf("failed to parse Kucoin candles data: %w", err)
+	}
+	if len(candlesData.Candles) < 7 {
+		return nil, fmt.Errorf("invalid Kucoin candles data: %v", candlesData.Candles)
+	}
+
+	unifiedSymbol, err := p.symbolRegistry.ConvertToUnified("kucoin", candlesData.Symbol, "spot")
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert Kucoin symbol %s: %w", candlesData.Symbol, err)
+	}
+
+	// /market/candles:BTC-USDT_1min -> 1m
+	interval := klineInterval(wsMsg.Topic[strings.LastIndex(wsMsg.Topic, "_")+1:])
+	duration, err := market.ParseKlineInterval(interval)
+	if err != nil {
+		return nil, fmt.Errorf("Kucoin candles topic %s: %w", wsMsg.Topic, err)
+	}
+
+	c := candlesData.Candles
+	start, _ := strconv.ParseInt(c[0], 10, 64)
+	openTime := time.Unix(start, 0)
+	kline := market.UnifiedKline{
+		Symbol:        unifiedSymbol.Symbol,
+		UnifiedSymbol: unifiedSymbol,
+		Interval:      interval,
+		OpenTime:      openTime,
+		CloseTime:     openTime.Add(duration),
+		Open:          parseFloat(c[1]),
+		Close:         parseFloat(c[2]),
+		High:          parseFloat(c[3]),
+		Low:           parseFloat(c[4]),
+		Volume:        parseFloat(c[5]),
+		QuoteVolume:   parseFloat(c[6]),
+		Source:        market.KlineSourceExchange,
+		Raw:           candlesData,
+	}
+
+	return &market.UnifiedMessage{
+		Exchange:      "kucoin",
+		Symbol:        unifiedSymbol.Symbol,
+		UnifiedSymbol: unifiedSymbol,
+		MessageType:   market.MessageTypeKline,
+		Timestamp:     timestamp,
+		Data:          kline,
+	}, nil
+}
@@ -0,0 +1,145 @@
+package parsers
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"daemon-go/internal/market"
+)
+
+// KucoinFuturesParser - парсер публичного потока KuCoin Futures: стакан /contractMarket/level2Depth{5,50}
+// и /contract/instrument (маркировочная и индексная цены). Ставка в потоке приходит без времени расчета
+// и прогноза, поэтому читается из REST /api/v1/contracts/active (ParseContracts). Объемы стакана приходят
+// в контрактах, пересчет в base валюту выполняет адаптер по множителю контракта
+type KucoinFuturesParser struct {
+	symbolRegistry *market.SymbolRegistry
+}
+
+// KucoinFuturesMessage - сообщение потока KuCoin Futures; welcome/ack/pong приходят без topic
+type KucoinFuturesMessage struct {
+	Type    string          `json:"type"`
+	Topic   string          `json:"topic"`
+	Subject string          `json:"subject"`
+	Data    json.RawMessage `json:"data"`
+}
+
+// KucoinFuturesDepthData - снимок верхних уровней стакана
+type KucoinFuturesDepthData struct {
+	Bids      [][]json.Number `json:"bids"`
+	Asks      [][]json.Number `json:"asks"`
+	Sequence  int64           `json:"sequence"`
+	Timestamp int64           `json:"timestamp"`
+}
+
+// KucoinInstrumentData - /contract/instrument, subject mark.index.price
+type KucoinInstrumentData struct {
+	MarkPrice  float64 `json:"markPrice"`
+	IndexPrice float64 `json:"indexPrice"`
+	Timestamp  int64   `json:"timestamp"`
+}
+
+// KucoinFuturesContract - элемент ответа /api/v1/contracts/active: fundingFeeRate - ставка текущего
+// периода, predictedFundingFeeRate - прогноз следующего, nextFundingRateTime - миллисекунд до расчета
+type KucoinFuturesContract struct {
+	Symbol                  string   `json:"symbol"`
+	IsInverse               bool     `json:"isInverse"`
+	FundingFeeRate          *float64 `json:"fundingFeeRate"`
+	PredictedFundingFeeRate *float64 `json:"predictedFundingFeeRate"`
+	NextFundingRateTime     int64    `json:"nextFundingRateTime"`
+}
+
+func NewKucoinFuturesParser() *KucoinFuturesParser {
+	return &KucoinFuturesParser{
+		symbolRegistry: market.NewSymbolRegistry(),
+	}
+}
+
+// ParseMessage разбирает сообщение потока; служебные сообщения пропускаются
+func (p *KucoinFuturesParser) ParseMessage(rawData []byte) ([]market.UnifiedMessage, error) {
+	var wsMsg KucoinFuturesMessage
+	if err := json.Unmarshal(rawData, &wsMsg); err != nil {
+		return nil, fmt.Errorf("failed to parse KuCoin futures message: %w", err)
+	}
+	if wsMsg.Type != "message" {
+		return nil, nil
+	}
+
+	// Символ контракта после двоеточия: /contractMarket/level2Depth5:XBTUSDTM
+	parts := strings.SplitN(wsMsg.Topic, ":", 2)
+	if len(parts) != 2 {
+		return nil, fmt.Errorf("invalid KuCoin futures topic: %s", wsMsg.Topic)
+	}
+	unifiedSymbol, err := p.symbolRegistry.ConvertToUnified("kucoin", parts[1], market.MarketTypeFutures)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert KuCoin symbol %s: %w", parts[1], err)
+	}
+
+	switch {
+	case strings.HasPrefix(parts[0], "/contractMarket/level2Depth"):
+		var data KucoinFuturesDepthData
+		if err := json.Unmarshal(wsMsg.Data, &data); err != nil {
+			return nil, fmt.Errorf("failed to parse KuCoin futures depth: %w", err)
+		}
+		return []market.UnifiedMessage{futuresOrderBook("kucoin", unifiedSymbol, unixMilli(data.Timestamp),
+			numberLevels(data.Bids), numberLevels(data.Asks),
+			market.OrderBookUpdateTypeSnapshot, data.Sequence, wsMsg)}, nil
+	case parts[0] == "/contract/instrument":
+		var data KucoinInstrumentData
+		if err := json.Unmarshal(wsMsg.Data, &data); err != nil {
+			return nil, fmt.Errorf("failed to parse KuCoin instrument: %w", err)
+		}
+		// funding.rate пропускается: ставку вместе со временем расчета дает ParseContracts
+		if wsMsg.Subject != "mark.index.price" {
+			return nil, nil
+		}
+		timestamp := unixMilli(data.Timestamp)
+		return []market.UnifiedMessage{
+			markPriceMessage("kucoin", unifiedSymbol, timestamp, data.MarkPrice, data),
+			indexPriceMessage("kucoin", unifiedSymbol, timestamp, data.IndexPrice, data),
+		}, nil
+	default:
+		return nil, fmt.Errorf("unknown KuCoin futures topic: %s", wsMsg.Topic)
+	}
+}
+
+// ParseContracts разбирает ставки финансирования из ответа REST /api/v1/contracts/active
+func (p *KucoinFuturesParser) ParseContracts(rawData []byte) ([]market.UnifiedMessage, error) {
+	var resp struct {
+		Code string                  `json:"code"`
+		Msg  string                  `json:"msg"`
+		Data []KucoinFuturesContract `json:"data"`
+	}
+	if err := json.Unmarshal(rawData, &resp); err != nil {
+		return nil, fmt.Errorf("failed to parse KuCoin contracts: %w", err)
+	}
+	if resp.Code != "200000" {
+		return nil, fmt.Errorf("KuCoin contracts: code %s: %s", resp.Code, resp.Msg)
+	}
+
+	now := time.Now()
+	messages := make([]market.UnifiedMessage, 0, len(resp.Data))
+	for _, c := range resp.Data {
+		if c.IsInverse || c.FundingFeeRate == nil {
+			continue
+		}
+		unifiedSymbol, err := p.symbolRegistry.ConvertToUnified("kucoin", c.Symbol, market.MarketTypeFutures)
+		if err != nil {
+			continue
+		}
+		// Время расчета приходит как остаток до него; округляется до секунды, чтобы опросы
+		// одного периода давали одно и то же время
+		var next time.Time
+		if c.NextFundingRateTime > 0 {
+			next = now.Add(time.Duration(c.NextFundingRateTime) * time.Millisecond).Round(time.Second)
+		}
+		var predicted float64
+		if c.PredictedFundingFeeRate != nil {
+			predicted = *c.PredictedFundingFeeRate
+		}
+		messages = append(messages, fundingRateMessage("kucoin", unifiedSymbol, now, *c.FundingFeeRate,
+			predicted, c.PredictedFundingFeeRate != nil, next, c))
+	}
+	return messages, nil
+}
@@ -3,6 +3,7 @@ package parsers
 import (
 	"encoding/json"
 	"fmt"
+	"strings"
 	"time"
 
 	"daemon-go/internal/market"
@@ -16,7 +17,8 @@ type PoloniexParser struct {
 // PoloniexWebSocketMessage - общий формат WebSocket сообщений Poloniex
 type PoloniexWebSocketMessage struct {
 	Channel string      `json:"channel"`
-	Data    interface{} `json:"data"` // Может быть массивом или объектом
+	Action  string      `json:"action"` // book_lv2: snapshot или update
+	Data    interface{} `json:"data"`   // Может быть массивом или объектом
 }
 
 // PoloniexOrderBookUpdate - обновление orderbook от Poloniex
@@ -26,6 +28,8 @@ type PoloniexOrderBookUpdate struct {
 	Asks       [][]string `json:"asks"`
 	Bids       [][]string `json:"bids"`
 	ID         int64      `json:"id"`
+	LastID     int64      `json:"lastId"`   // id предыдущего обновления (book_lv2)
+	Checksum   int64      `json:"checksum"` // CRC32 стакана после обновления (book_lv2)
 	Ts         int64      `json:"ts"`
 }
 
@@ -50,6 +54,31 @@ type PoloniexTickerUpdate struct {
 	Ts          int64  `json:"ts"`
 }
 
+// PoloniexTrade - сделка от Poloniex (канал trades); takerSide - сторона тейкера
+type PoloniexTrade struct {
+	Symbol     string `json:"symbol"`
+	ID         string `json:"id"`
+	Price      string `json:"price"`
+	Quantity   string `json:"quantity"`
+	TakerSide  string `json:"takerSide"`
+	CreateTime int64  `json:"createTime"`
+}
+
+// PoloniexCandle - свеча от Poloniex (канал candles_<interval>, например candles_minute_1):
+// quantity - объем в base валюте, amount - в quote валюте, closeTime - последняя миллисекунда свечи
+type PoloniexCandle struct {
+	Symbol     string `json:"symbol"`
+	Open       string `json:"open"`
+	High       string `json:"high"`
+	Low        string `json:"low"`
+	Close      string `json:"close"`
+	Quantity   string `json:"quantity"`
+	Amount     string `json:"amount"`
+	TradeCount int64  `json:"tradeCount"`
+	StartTime  int64  `json:"startTime"`
+	CloseTime  int64  `json:"closeTime"`
+}
+
 func NewPoloniexParser() *PoloniexParser {
 	return &PoloniexParser{
 		symbolRegistry: market.NewSymbolRegistry(),
@@ -74,6 +103,10 @@ func (p *PoloniexParser) ParseMessage(exchange string, rawData []byte) (*market.
 		return p.parseOrderBook(wsMsg, timestamp)
 	case contains(wsMsg.Channel, "ticker"):
 		return p.parseTicker(wsMsg, timestamp)
+	case wsMsg.Channel == "trades":
+		return p.parseTrades(wsMsg, timestamp)
+	case strings.HasPrefix(wsMsg.Channel, "candles_"):
+		return p.parseCandles(wsMsg, timestamp)
 	default:
 		return nil, fmt.Errorf("unknown Poloniex channel: %s", wsMsg.Channel)
 	}
@@ -121,8 +154,10 @@ func (p *PoloniexParser) parseOrderBook(wsMsg PoloniexWebSocketMessage, timestam
 			price := parseFloat(bid[0])
 			volume := parseFloat(bid[1])
 			bids = append(bids, market.PriceLevel{
-				Price:  price,
-				Volume: volume,
+				Price:     price,
+				Volume:    volume,
+				RawPrice:  bid[0],
+				RawVolume: bid[1],
 			})
 		}
 	}
@@ -133,12 +168,20 @@ func (p *PoloniexParser) parseOrderBook(wsMsg PoloniexWebSocketMessage, timestam
 			price := parseFloat(ask[0])
 			volume := parseFloat(ask[1])
 			asks = append(asks, market.PriceLevel{
-				Price:  price,
-				Volume: volume,
+				Price:     price,
+				Volume:    volume,
+				RawPrice:  ask[0],
+				RawVolume: ask[1],
 			})
 		}
 	}
 
+	// book_lv2 присылает снимок при подписке и далее diff; канал book - всегда полный срез
+	updateType := market.OrderBookUpdateTypeSnapshot
+	if wsMsg.Action == "update" {
+		updateType = market.OrderBookUpdateTypeIncremental
+	}
+
 	orderbook := market.UnifiedOrderBook{
 		Symbol:        unifiedSymbol.Symbol,
 		UnifiedSymbol: unifiedSymbol,
@@ -146,8 +189,13 @@ func (p *PoloniexParser) parseOrderBook(wsMsg PoloniexWebSocketMessage, timestam
 		Bids:          bids,
 		Asks:          asks,
 		Depth:         len(bids) + len(asks),
-		UpdateType:    market.OrderBookUpdateTypeIncremental, // Poloniex - инкрементальные обновления
+		UpdateType:    updateType,
 		Raw:           wsMsg,
+		Checksum:      orderBookUpdate.Checksum,
+	}
+	if wsMsg.Channel == "book_lv2" {
+		orderbook.LastUpdateID = orderBookUpdate.ID
+		orderbook.PrevUpdateID = orderBookUpdate.LastID
 	}
 
 	return &market.UnifiedMessage{
@@ -229,3 +277,112 @@ func (p *PoloniexParser) parseTicker(wsMsg PoloniexWebSocketMessage, timestamp t
 		Data:          ticker,
 	}, nil
 }
+
+// parseTrades разбирает пачку сделок; Data сообщения - []market.UnifiedTrade (см. market.SplitTrades)
+func (p *PoloniexParser) parseTrades(wsMsg PoloniexWebSocketMessage, timestamp time.Time) (*market.UnifiedMessage, error) {
+	// Ответ на подписку приходит в том же канале без данных
+	if wsMsg.Data == nil {
+		return nil, nil
+	}
+
+	dataBytes, err := json.Marshal(wsMsg.Data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal Poloniex trades: %w", err)
+	}
+
+	var tradesData []PoloniexTrade
+	if err := json.Unmarshal(dataBytes, &tradesData); err != nil {
+		return nil, fmt.Errorf("failed to parse Poloniex trades: %w", err)
+	}
+	if len(tradesData) == 0 {
+		return nil, nil
+	}
+
+	unifiedSymbol, err := p.symbolRegistry.ConvertToUnified("poloniex", tradesData[0].Symbol, "spot")
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert Poloniex symbol %s: %w", tradesData[0].Symbol, err)
+	}
+
+	trades := make([]market.UnifiedTrade, 0, len(tradesData))
+	for _, t := range tradesData {
+		side := market.TradeSideBuy
+		if t.TakerSide == "sell" {
+			side = market.TradeSideSell
+		}
+		tradeTime := timestamp
+		if t.CreateTime > 0 {
+			tradeTime = time.UnixMilli(t.CreateTime)
+		}
+		trades = append(trades, market.UnifiedTrade{
+			Symbol:        unifiedSymbol.Symbol,
+			UnifiedSymbol: unifiedSymbol,
+			Timestamp:     tradeTime,
+			TradeID:       t.ID,
+			Price:         parseFloat(t.Price),
+			Volume:        parseFloat(t.Quantity),
+			Side:          side,
+			Raw:           t,
+		})
+	}
+
+	return &market.UnifiedMessage{
+		Exchange:      "poloniex",
+		Symbol:        unifiedSymbol.Symbol,
+		UnifiedSymbol: unifiedSymbol,
+		MessageType:   market.MessageTypeTrade,
+		Timestamp:     timestamp,
+		Data:          trades,
+	}, nil
+}
+
+func (p *PoloniexParser) parseCandles(wsMsg PoloniexWebSocketMessage, timestamp time.Time) (*market.UnifiedMessage, error) {
+	// Ответ на подписку приходит в том же канале без данных
+	if wsMsg.Data == nil {
+		return nil, nil
+	}
+
+	dataBytes, err := json.Marshal(wsMsg.Data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal Poloniex candles: %w", err)
+	}
+
+	var candlesData []PoloniexCandle
+	if err := json.Unmarshal(dataBytes, &candlesData); err != nil {
+		return nil, fmt.Errorf("failed to parse Poloniex candles: %w", err)
+	}
+	if len(candlesData) == 0 {
+		return nil, nil
+	}
+
+	c := candlesData[len(candlesData)-1]
+	unifiedSymbol, err := p.symbolRegistry.ConvertToUnified("poloniex", c.Symbol, "spot")
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert Poloniex symbol %s: %w", c.Symbol, err)
+	}
+
+	kline := market.UnifiedKline{
+		Symbol:        unifiedSymbol.Symbol,
+		UnifiedSymbol: unifiedSymbol,
+		Interval:      klineInterval(strings.TrimPrefix(wsMsg.Channel, "candles_")),
+		OpenTime:      time.UnixMilli(c.StartTime),
+		CloseTime:     time.UnixMilli(c.CloseTime + 1),
+		Open:          parseFloat(c.Open),
+		High:          parseFloat(c.High),
+		Low:           parseFloat(c.Low),
+		Close:         parseFloat(c.Close),
+		Volume:        parseFloat(c.Quantity),
+		QuoteVolume:   parseFloat(c.Amount),
+		Trades:        c.TradeCount,
+		Source:        market.KlineSourceExchange,
+		Raw:           c,
+	}
+
+	return &market.UnifiedMessage{
+		Exchange:      "poloniex",
+		Symbol:        unifiedSymbol.Symbol,
+		UnifiedSymbol: unifiedSymbol,
+		MessageType:   market.MessageTypeKline,
+		Timestamp:     timestamp,
+		Data:          kline,
+	}, nil
+}
@@ -3,6 +3,9 @@ package parsers
 import (
 	"strconv"
 	"strings"
+	"time"
+
+	"daemon-go/internal/market"
 )
 
 // Утилиты для парсеров
@@ -15,3 +18,30 @@ func parseFloat(s string) float64 {
 func contains(s, substr string) bool {
 	return strings.Contains(s, substr)
 }
+
+// klineInterval приводит интервал свечи биржи к унифицированному (1m, 5m, 1h, 1d):
+// минуты числом (Bybit "5"), 1min/4hour/1day (KuCoin, HTX), MINUTE_1/HOUR_4/DAY_1 (Poloniex)
+func klineInterval(interval string) string {
+	if n, err := strconv.Atoi(interval); err == nil {
+		return market.KlineIntervalName(time.Duration(n) * time.Minute)
+	}
+	s := strings.ToLower(interval)
+	units := []struct {
+		name string
+		unit time.Duration
+	}{{"min", time.Minute}, {"minute", time.Minute}, {"hour", time.Hour}, {"day", 24 * time.Hour}}
+	for _, u := range units {
+		// 1min, 4hour, 1day
+		if n, err := strconv.Atoi(strings.TrimSuffix(s, u.name)); err == nil && strings.HasSuffix(s, u.name) {
+			return market.KlineIntervalName(time.Duration(n) * u.unit)
+		}
+		// minute_1, hour_4, day_1
+		if n, err := strconv.Atoi(strings.TrimPrefix(s, u.name+"_")); err == nil && strings.HasPrefix(s, u.name+"_") {
+			return market.KlineIntervalName(time.Duration(n) * u.unit)
+		}
+	}
+	if s == "d" {
+		return "1d"
+	}
+	return interval
+}
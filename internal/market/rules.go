@@ -0,0 +1,129 @@
+package market
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SymbolRules - торговые правила символа на бирже: шаг цены и количества, минимальные
+// объем и стоимость ордера. Нулевое значение правила означает отсутствие ограничения
+type SymbolRules struct {
+	Exchange          string    `json:"exchange"`
+	Symbol            string    `json:"symbol"` // унифицированный символ BTC/USDT
+	BaseCurrency      string    `json:"base_currency"`
+	QuoteCurrency     string    `json:"quote_currency"`
+	TickSize          float64   `json:"tick_size"`          // шаг цены
+	LotSize           float64   `json:"lot_size"`           // шаг количества
+	MinQty            float64   `json:"min_qty"`            // минимальное количество
+	MaxQty            float64   `json:"max_qty"`            // максимальное количество
+	MinNotional       float64   `json:"min_notional"`       // минимальная стоимость ордера в quote валюте
+	PricePrecision    int       `json:"price_precision"`    // знаков после запятой в цене
+	QuantityPrecision int       `json:"quantity_precision"` // знаков после запятой в количестве
+	Trading           bool      `json:"trading"`            // торговля по символу открыта
+	UpdatedAt         time.Time `json:"updated_at"`
+}
+
+// RulesError - ордер не проходит торговые правила символа
+type RulesError struct {
+	Exchange string
+	Symbol   string
+	Reason   string
+}
+
+func (e *RulesError) Error() string {
+	return fmt.Sprintf("rules: %s %s: %s", e.Exchange, e.Symbol, e.Reason)
+}
+
+// StepFromPrecision возвращает шаг для количества знаков после запятой: 2 -> 0.01
+func StepFromPrecision(precision int) float64 {
+	if precision < 0 {
+		return 0
+	}
+	return math.Pow10(-precision)
+}
+
+// PrecisionFromStep возвращает количество знаков после запятой шага: 0.001 -> 3
+func PrecisionFromStep(step float64) int {
+	if step <= 0 {
+		return 0
+	}
+	s := strconv.FormatFloat(step, 'f', -1, 64)
+	if i := strings.IndexByte(s, '.'); i >= 0 {
+		return len(s) - i - 1
+	}
+	return 0
+}
+
+// roundToStep округляет значение к шагу: вниз (up=false) или вверх (up=true).
+// Результат обрезается до precision знаков, чтобы убрать погрешность float
+func roundToStep(value, step float64, precision int, up bool) float64 {
+	if step <= 0 {
+		if precision > 0 {
+			return roundPrecision(value, precision, up)
+		}
+		return value
+	}
+	steps := value / step
+	if up {
+		steps = math.Ceil(steps - 1e-9)
+	} else {
+		steps = math.Floor(steps + 1e-9)
+	}
+	if precision <= 0 {
+		precision = PrecisionFromStep(step)
+	}
+	factor := math.Pow10(precision)
+	return math.Round(steps*step*factor) / factor
+}
+
+// roundPrecision округляет значение до precision знаков вниз или вверх
+func roundPrecision(value float64, precision int, up bool) float64 {
+	factor := math.Pow10(precision)
+	if up {
+		return math.Ceil(value*factor-1e-9) / factor
+	}
+	return math.Floor(value*factor+1e-9) / factor
+}
+
+// RoundPrice округляет цену к шагу цены в безопасную для стороны сторону:
+// покупка - вниз, продажа - вверх (цена не становится хуже заданной)
+func (r SymbolRules) RoundPrice(price float64, side TradeSide) float64 {
+	return roundToStep(price, r.TickSize, r.PricePrecision, side == TradeSideSell)
+}
+
+// RoundQuantity округляет количество вниз к шагу количества
+func (r SymbolRules) RoundQuantity(qty float64) float64 {
+	return roundToStep(qty, r.LotSize, r.QuantityPrecision, false)
+}
+
+// Normalize округляет цену и количество ордера к допустимым шагам и проверяет
+// минимальные количество и стоимость. Цена рыночного ордера не округляется
+func (r SymbolRules) Normalize(req OrderRequest) (OrderRequest, error) {
+	if !r.Trading {
+		return req, &RulesError{Exchange: r.Exchange, Symbol: r.Symbol, Reason: "trading is not available"}
+	}
+	if req.OrderType != OrderTypeMarket && req.Price > 0 {
+		req.Price = r.RoundPrice(req.Price, req.Side)
+	}
+	req.Volume = r.RoundQuantity(req.Volume)
+
+	if req.Volume <= 0 {
+		return req, &RulesError{Exchange: r.Exchange, Symbol: r.Symbol, Reason: "quantity rounds to zero"}
+	}
+	if r.MinQty > 0 && req.Volume < r.MinQty {
+		return req, &RulesError{Exchange: r.Exchange, Symbol: r.Symbol,
+			Reason: fmt.Sprintf("quantity %v below minimum %v", req.Volume, r.MinQty)}
+	}
+	if r.MaxQty > 0 && req.Volume > r.MaxQty {
+		return req, &RulesError{Exchange: r.Exchange, Symbol: r.Symbol,
+			Reason: fmt.Sprintf("quantity %v above maximum %v", req.Volume, r.MaxQty)}
+	}
+	if r.MinNotional > 0 && req.Price > 0 && req.Volume*req.Price < r.MinNotional {
+		return req, &RulesError{Exchange: r.Exchange, Symbol: r.Symbol,
+			Reason: fmt.Sprintf("notional %v below minimum %v", req.Volume*req.Price, r.MinNotional)}
+	}
+	return req, nil
+}
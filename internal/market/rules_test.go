@@ -0,0 +1,104 @@
+package market
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestRoundPriceBySide(t *testing.T) {
+	r := SymbolRules{TickSize: 0.01}
+	tests := []struct {
+		price float64
+		side  TradeSide
+		want  float64
+	}{
+		{100.129, TradeSideBuy, 100.12},
+		{100.121, TradeSideSell, 100.13},
+		{100.12, TradeSideBuy, 100.12},
+		{100.12, TradeSideSell, 100.12},
+		{0.3, TradeSideBuy, 0.3}, // 0.3/0.01 = 29.999999999999996
+	}
+	for _, tt := range tests {
+		if got := r.RoundPrice(tt.price, tt.side); got != tt.want {
+			t.Errorf("RoundPrice(%v, %s) = %v, want %v", tt.price, tt.side, got, tt.want)
+		}
+	}
+}
+
+func TestRoundQuantity(t *testing.T) {
+	tests := []struct {
+		name  string
+		rules SymbolRules
+		qty   float64
+		want  float64
+	}{
+		{"lot step", SymbolRules{LotSize: 0.001}, 1.23456, 1.234},
+		{"float noise", SymbolRules{LotSize: 0.1}, 0.3, 0.3},
+		{"coarse lot", SymbolRules{LotSize: 5}, 12, 10},
+		{"precision only", SymbolRules{QuantityPrecision: 2}, 1.239, 1.23},
+		{"no rules", SymbolRules{}, 1.23456, 1.23456},
+	}
+	for _, tt := range tests {
+		if got := tt.rules.RoundQuantity(tt.qty); got != tt.want {
+			t.Errorf("%s: RoundQuantity(%v) = %v, want %v", tt.name, tt.qty, got, tt.want)
+		}
+	}
+}
+
+func TestStepPrecision(t *testing.T) {
+	if got := StepFromPrecision(3); got != 0.001 {
+		t.Errorf("StepFromPrecision(3) = %v", got)
+	}
+	if got := StepFromPrecision(-1); got != 0 {
+		t.Errorf("StepFromPrecision(-1) = %v", got)
+	}
+	for step, want := range map[float64]int{0.001: 3, 0.5: 1, 1: 0, 10: 0, 0: 0} {
+		if got := PrecisionFromStep(step); got != want {
+			t.Errorf("PrecisionFromStep(%v) = %d, want %d", step, got, want)
+		}
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	rules := SymbolRules{
+		Exchange: "binance", Symbol: "BTC/USDT",
+		TickSize: 0.01, LotSize: 0.001, MinQty: 0.001, MaxQty: 10, MinNotional: 5, Trading: true,
+	}
+	limit := OrderRequest{Symbol: "BTC/USDT", Side: TradeSideBuy, OrderType: OrderTypeLimit}
+
+	req := limit
+	req.Price, req.Volume = 50000.129, 0.12345
+	got, err := rules.Normalize(req)
+	if err != nil {
+		t.Fatalf("Normalize: %v", err)
+	}
+	if got.Price != 50000.12 || got.Volume != 0.123 {
+		t.Errorf("normalized %v@%v, want 0.123@50000.12", got.Volume, got.Price)
+	}
+
+	market := OrderRequest{Symbol: "BTC/USDT", Side: TradeSideSell, OrderType: OrderTypeMarket, Price: 50000.129, Volume: 0.01}
+	if got, err := rules.Normalize(market); err != nil || got.Price != 50000.129 {
+		t.Errorf("market order price rounded or rejected: %v, %v", got.Price, err)
+	}
+
+	rejects := []struct {
+		name  string
+		rules SymbolRules
+		price float64
+		qty   float64
+	}{
+		{"rounds to zero", rules, 50000, 0.0004},
+		{"above max", rules, 50000, 11},
+		{"below notional", rules, 1000, 0.002},
+		{"not trading", SymbolRules{Exchange: "binance", Symbol: "BTC/USDT"}, 50000, 1},
+	}
+	for _, tt := range rejects {
+		req := limit
+		req.Price, req.Volume = tt.price, tt.qty
+		_, err := tt.rules.Normalize(req)
+		var rulesErr *RulesError
+		if !errors.As(err, &rulesErr) {
+			t.Errorf("%s: expected RulesError, got %v", tt.name, err)
+		}
+	}
+}
@@ -5,6 +5,22 @@ import (
 	"strings"
 )
 
+// Типы рынков
+const (
+	MarketTypeSpot    = "spot"
+	MarketTypeFutures = "futures" // линейные бессрочные контракты (USDT-M perpetual)
+)
+
+// NormalizeMarketType приводит тип рынка из БД или конфига (SPOT, FUTURES, PERP, SWAP) к spot/futures
+func NormalizeMarketType(marketType string) string {
+	switch strings.ToLower(strings.TrimSpace(marketType)) {
+	case "futures", "future", "perp", "perpetual", "swap", "linear":
+		return MarketTypeFutures
+	default:
+		return MarketTypeSpot
+	}
+}
+
 // UnifiedSymbol - унифицированный формат торговой пары
 type UnifiedSymbol struct {
 	BaseCurrency   string `json:"base_currency"`   // BTC
@@ -157,18 +173,30 @@ type KucoinSymbolConverter struct{}
 func (c *KucoinSymbolConverter) ToExchangeSymbol(unified *UnifiedSymbol) string {
 	// Kucoin использует формат BTC-USDT для спот, XBTUSDTM для фьючерсов
 	if unified.MarketType == "futures" {
-		// Специальная логика для фьючерсов Kucoin
-		return fmt.Sprintf("%s%sM", unified.BaseCurrency, unified.QuoteCurrency)
+		// Специальная логика для фьючерсов Kucoin: BTC называется XBT
+		base := unified.BaseCurrency
+		if base == "BTC" {
+			base = "XBT"
+		}
+		return fmt.Sprintf("%s%sM", base, unified.QuoteCurrency)
 	}
 	return fmt.Sprintf("%s-%s", unified.BaseCurrency, unified.QuoteCurrency)
 }
 
 func (c *KucoinSymbolConverter) FromExchangeSymbol(exchangeSymbol, marketType string) (*UnifiedSymbol, error) {
 	// Обработка специфики Kucoin
-	if marketType == "futures" && strings.HasSuffix(exchangeSymbol, "M") {
-		exchangeSymbol = strings.TrimSuffix(exchangeSymbol, "M")
+	if marketType != "futures" {
+		return ParseSymbol(exchangeSymbol, marketType)
 	}
-	return ParseSymbol(exchangeSymbol, marketType)
+	unified, err := ParseSymbol(strings.TrimSuffix(exchangeSymbol, "M"), marketType)
+	if err != nil {
+		return nil, err
+	}
+	if unified.BaseCurrency == "XBT" {
+		unified = NewUnifiedSymbol("BTC", unified.QuoteCurrency, marketType)
+	}
+	unified.OriginalSymbol = exchangeSymbol
+	return unified, nil
 }
 
 // SymbolRegistry - реестр конвертеров символов
@@ -19,12 +19,16 @@ type UnifiedMessage struct {
 type MessageType string
 
 const (
-	MessageTypeOrderBook  MessageType = "orderbook"
-	MessageTypeTicker     MessageType = "ticker"
-	MessageTypeBestPrice  MessageType = "best_price"
-	MessageTypeTrade      MessageType = "trade"
-	MessageTypeKline      MessageType = "kline"
-	MessageTypeOrderEvent MessageType = "order_event"
+	MessageTypeOrderBook   MessageType = "orderbook"
+	MessageTypeTicker      MessageType = "ticker"
+	MessageTypeBestPrice   MessageType = "best_price"
+	MessageTypeTrade       MessageType = "trade"
+	MessageTypeKline       MessageType = "kline"
+	MessageTypeMarkPrice   MessageType = "mark_price"   // маркировочная цена бессрочного контракта
+	MessageTypeIndexPrice  MessageType = "index_price"  // индекс спотовых цен базового актива контракта
+	MessageTypeFundingRate MessageType = "funding_rate" // ставка финансирования бессрочного контракта
+	MessageTypeOrderEvent  MessageType = "order_event"
+	MessageTypeBalance     MessageType = "balance"
 )
 
 // OrderBookUpdateType - тип обновления order book
@@ -33,6 +37,7 @@ type OrderBookUpdateType string
 const (
 	OrderBookUpdateTypeSnapshot    OrderBookUpdateType = "snapshot"    // полный снимок
 	OrderBookUpdateTypeIncremental OrderBookUpdateType = "incremental" // инкрементальное обновление
+	OrderBookUpdateTypeInvalidated OrderBookUpdateType = "invalidated" // стакан устарел до следующего снимка, уровней нет
 )
 
 // UnifiedOrderBook - унифицированный формат orderbook
@@ -45,12 +50,20 @@ type UnifiedOrderBook struct {
 	Depth         int                 `json:"depth"`
 	UpdateType    OrderBookUpdateType `json:"update_type"`   // тип обновления
 	Raw           interface{}         `json:"raw,omitempty"` // оригинальные данные от биржи
+	// Идентификаторы обновлений для контроля последовательности (0 - биржа не присылает)
+	FirstUpdateID int64 `json:"first_update_id,omitempty"` // первый ID в сообщении (Binance U, KuCoin sequenceStart)
+	LastUpdateID  int64 `json:"last_update_id,omitempty"`  // последний ID (Binance u/lastUpdateId, Bybit u, KuCoin sequenceEnd, HTX seqNum)
+	PrevUpdateID  int64 `json:"prev_update_id,omitempty"`  // ID предыдущего сообщения (HTX prevSeqNum)
+	Checksum      int64 `json:"checksum,omitempty"`        // CRC32 стакана после обновления (0 - биржа не присылает)
 }
 
 // PriceLevel - уровень цены в orderbook
 type PriceLevel struct {
 	Price  float64 `json:"price"`
 	Volume float64 `json:"volume"`
+	// Исходные строки биржи для контрольной суммы стакана (пусто - уровень не из сообщения биржи)
+	RawPrice  string `json:"-"`
+	RawVolume string `json:"-"`
 }
 
 // UnifiedTicker - унифицированный формат ticker
@@ -93,6 +106,85 @@ type UnifiedTrade struct {
 	Raw           interface{}    `json:"raw,omitempty"`
 }
 
+// SplitTrades разворачивает сообщение с пачкой сделок (Data []UnifiedTrade, биржи присылают
+// несколько сделок в одном сообщении) в сообщения по одной сделке; остальные сообщения
+// возвращаются без изменений
+func SplitTrades(msg UnifiedMessage) []UnifiedMessage {
+	trades, ok := msg.Data.([]UnifiedTrade)
+	if !ok {
+		return []UnifiedMessage{msg}
+	}
+	messages := make([]UnifiedMessage, 0, len(trades))
+	for _, trade := range trades {
+		m := msg
+		m.Timestamp = trade.Timestamp
+		m.Data = trade
+		messages = append(messages, m)
+	}
+	return messages
+}
+
+// KlineSource - источник свечи
+type KlineSource string
+
+const (
+	KlineSourceExchange KlineSource = "exchange" // свечной поток биржи
+	KlineSourceLocal    KlineSource = "local"    // собрана локально из сделок (CandleAggregator)
+)
+
+// UnifiedKline - унифицированный формат свечи
+type UnifiedKline struct {
+	Symbol        string         `json:"symbol"`         // унифицированный символ
+	UnifiedSymbol *UnifiedSymbol `json:"unified_symbol"` // полная информация о символе
+	Interval      string         `json:"interval"`       // унифицированный интервал: 1s, 1m, 5m
+	OpenTime      time.Time      `json:"open_time"`
+	CloseTime     time.Time      `json:"close_time"` // начало следующей свечи
+	Open          float64        `json:"open"`
+	High          float64        `json:"high"`
+	Low           float64        `json:"low"`
+	Close         float64        `json:"close"`
+	Volume        float64        `json:"volume"`       // объем в base валюте
+	QuoteVolume   float64        `json:"quote_volume"` // объем в quote валюте
+	Trades        int64          `json:"trades"`       // число сделок (0 - биржа не присылает)
+	Closed        bool           `json:"closed"`       // свеча закрыта; false - формируется или биржа не сообщает
+	Source        KlineSource    `json:"source"`
+	Raw           interface{}    `json:"raw,omitempty"`
+}
+
+// UnifiedMarkPrice - маркировочная цена бессрочного контракта (по ней биржа считает PnL и ликвидации)
+type UnifiedMarkPrice struct {
+	Symbol        string         `json:"symbol"`         // унифицированный символ (BTCUSDT)
+	UnifiedSymbol *UnifiedSymbol `json:"unified_symbol"` // полная информация о символе
+	Timestamp     time.Time      `json:"timestamp"`
+	MarkPrice     float64        `json:"mark_price"`
+	Raw           interface{}    `json:"raw,omitempty"`
+}
+
+// UnifiedIndexPrice - индексная цена бессрочного контракта (взвешенная спотовая цена базового актива
+// по нескольким биржам); разница маркировочной и индексной цены - базис контракта
+type UnifiedIndexPrice struct {
+	Symbol        string         `json:"symbol"`         // унифицированный символ (BTCUSDT)
+	UnifiedSymbol *UnifiedSymbol `json:"unified_symbol"` // полная информация о символе
+	Timestamp     time.Time      `json:"timestamp"`
+	IndexPrice    float64        `json:"index_price"`
+	Raw           interface{}    `json:"raw,omitempty"`
+}
+
+// UnifiedFundingRate - ставка финансирования бессрочного контракта за период (0.0001 = 0.01%).
+// Положительная ставка - длинные позиции платят коротким. FundingRate будет списана в NextFundingTime
+// (Binance, Bybit и KuCoin публикуют ставку текущего периода, меняющуюся до расчета),
+// PredictedRate - прогноз ставки следующего периода, если биржа его публикует (HTX, KuCoin)
+type UnifiedFundingRate struct {
+	Symbol          string         `json:"symbol"`         // унифицированный символ (BTCUSDT)
+	UnifiedSymbol   *UnifiedSymbol `json:"unified_symbol"` // полная информация о символе
+	Timestamp       time.Time      `json:"timestamp"`
+	FundingRate     float64        `json:"funding_rate"`
+	PredictedRate   float64        `json:"predicted_rate"`    // действительна при HasPredicted
+	HasPredicted    bool           `json:"has_predicted"`     // PredictedRate заполнена
+	NextFundingTime time.Time      `json:"next_funding_time"` // время расчета FundingRate (нулевое - неизвестно)
+	Raw             interface{}    `json:"raw,omitempty"`
+}
+
 // TradeSide - сторона сделки
 type TradeSide string
 
@@ -115,9 +207,20 @@ type UnifiedOrderEvent struct {
 	Volume          float64        `json:"volume"`
 	FilledVolume    float64        `json:"filled_volume"`
 	RemainingVolume float64        `json:"remaining_volume"`
-	Fee             float64        `json:"fee"`
+	AvgPrice        float64        `json:"avg_price,omitempty"` // средняя цена исполнения (0 - биржа не сообщает)
+	Fee             float64        `json:"fee"`                 // накопленная комиссия ордера (0 - биржа не сообщает, см. LastFillFee)
 	FeeCurrency     string         `json:"fee_currency"`
-	Raw             interface{}    `json:"raw,omitempty"`
+	// Последнее исполнение, если событие вызвано сделкой (TradeID не пустой)
+	TradeID        string      `json:"trade_id,omitempty"`
+	LastFillPrice  float64     `json:"last_fill_price,omitempty"`
+	LastFillVolume float64     `json:"last_fill_volume,omitempty"`
+	LastFillFee    float64     `json:"last_fill_fee,omitempty"`
+	Raw            interface{} `json:"raw,omitempty"`
+}
+
+// IsFill возвращает true, если событие сообщает об исполнении части ордера
+func (e UnifiedOrderEvent) IsFill() bool {
+	return e.TradeID != "" || e.LastFillVolume > 0
 }
 
 // OrderStatus - статус ордера
@@ -132,6 +235,16 @@ const (
 	OrderStatusExpired         OrderStatus = "expired"
 )
 
+// IsFinal возвращает true, если ордер больше не может измениться
+func (s OrderStatus) IsFinal() bool {
+	switch s {
+	case OrderStatusFilled, OrderStatusCanceled, OrderStatusRejected, OrderStatusExpired:
+		return true
+	default:
+		return false
+	}
+}
+
 // OrderType - тип ордера
 type OrderType string
 
@@ -140,6 +253,35 @@ const (
 	OrderTypeLimit  OrderType = "limit"
 )
 
+// OrderRequest - запрос на размещение ордера
+type OrderRequest struct {
+	Symbol        string    `json:"symbol"` // унифицированный символ (BTC/USDT)
+	Side          TradeSide `json:"side"`
+	OrderType     OrderType `json:"order_type"`
+	Price         float64   `json:"price"`  // для market ордеров не используется
+	Volume        float64   `json:"volume"` // объем в базовой валюте
+	ClientOrderID string    `json:"client_order_id,omitempty"`
+}
+
+// Order - состояние ордера на бирже
+type Order struct {
+	Exchange      string      `json:"exchange"`
+	Symbol        string      `json:"symbol"` // унифицированный символ
+	OrderID       string      `json:"order_id"`
+	ClientOrderID string      `json:"client_order_id,omitempty"`
+	Status        OrderStatus `json:"status"`
+	Side          TradeSide   `json:"side"`
+	OrderType     OrderType   `json:"order_type"`
+	Price         float64     `json:"price"`
+	Volume        float64     `json:"volume"`
+	FilledVolume  float64     `json:"filled_volume"`
+	AvgPrice      float64     `json:"avg_price"` // средняя цена исполнения
+	Fee           float64     `json:"fee"`
+	FeeCurrency   string      `json:"fee_currency,omitempty"`
+	CreatedAt     time.Time   `json:"created_at"`
+	UpdatedAt     time.Time   `json:"updated_at"`
+}
+
 // MessageHandler - интерфейс для обработки унифицированных сообщений
 type MessageHandler interface {
 	HandleMessage(msg UnifiedMessage) error
@@ -150,3 +292,32 @@ type MessageParser interface {
 	ParseMessage(exchange string, rawData []byte) (*UnifiedMessage, error)
 	CanParse(exchange string, rawData []byte) bool
 }
+
+// Balance - баланс актива на бирже
+type Balance struct {
+	Asset  string  `json:"asset"`
+	Free   float64 `json:"free"`   // доступно для торговли
+	Locked float64 `json:"locked"` // заблокировано в ордерах
+}
+
+// Total возвращает общий баланс актива
+func (b Balance) Total() float64 {
+	return b.Free + b.Locked
+}
+
+// FundingPayment - расчет финансирования по позиции аккаунта в бессрочном контракте.
+// Amount положителен, если финансирование получено, и отрицателен, если уплачено
+type FundingPayment struct {
+	Symbol string    `json:"symbol"` // унифицированный символ контракта (BTCUSDT)
+	Asset  string    `json:"asset"`
+	Amount float64   `json:"amount"`
+	Time   time.Time `json:"time"` // время расчета
+}
+
+// UnifiedBalanceUpdate - изменение балансов аккаунта из приватного потока биржи.
+// Balances содержит абсолютные значения free/locked перечисленных активов
+type UnifiedBalanceUpdate struct {
+	Timestamp time.Time   `json:"timestamp"`
+	Balances  []Balance   `json:"balances"`
+	Raw       interface{} `json:"raw,omitempty"`
+}
@@ -0,0 +1,66 @@
+package orders
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	sqlMySQL "daemon-go/internal/sql/mysql"
+	sqlPostgres "daemon-go/internal/sql/postgres"
+	"daemon-go/internal/worker"
+)
+
+var _ worker.CarryJournal = (*DBJournal)(nil)
+
+// SaveCarryPosition сохраняет состояние позиции cash-and-carry в CARRY_POSITION
+func (j *DBJournal) SaveCarryPosition(p worker.CarryPosition) error {
+	query := sqlMySQL.UpsertCarryPosition
+	if j.db.GetType() == "postgres" {
+		query = sqlPostgres.UpsertCarryPosition
+	}
+	return j.exec(query,
+		p.ID, p.Symbol, p.PerpSymbol, p.SpotExchange, p.PerpExchange, p.Volume, p.ClosedVolume,
+		p.SpotEntryPrice, p.PerpEntryPrice, p.SpotExitPrice, p.PerpExitPrice, p.SpreadPnL, p.CarryPnL,
+		p.FundingEvents, p.FundingRate, nullTime(p.NextFundingTime), nullTime(p.LastFundingAt), p.Status, p.OpenedAt,
+		nullTime(p.ClosedAt), p.UpdatedAt,
+	)
+}
+
+// LoadOpenCarryPositions загружает открытые позиции cash-and-carry
+func (j *DBJournal) LoadOpenCarryPositions() ([]worker.CarryPosition, error) {
+	query := sqlMySQL.OpenCarryPositions
+	if j.db.GetType() == "postgres" {
+		query = sqlPostgres.OpenCarryPositions
+	}
+
+	rows, err := j.db.Query(query)
+	if err != nil {
+		return nil, fmt.Errorf("orders: load carry positions: %w", err)
+	}
+	defer rows.Close()
+
+	var positions []worker.CarryPosition
+	for rows.Next() {
+		var p worker.CarryPosition
+		var nextFunding, lastFunding sql.NullTime
+		if err := rows.Scan(&p.ID, &p.Symbol, &p.PerpSymbol, &p.SpotExchange, &p.PerpExchange,
+			&p.Volume, &p.ClosedVolume, &p.SpotEntryPrice, &p.PerpEntryPrice, &p.SpotExitPrice,
+			&p.PerpExitPrice, &p.SpreadPnL, &p.CarryPnL, &p.FundingEvents, &p.FundingRate,
+			&nextFunding, &lastFunding, &p.OpenedAt, &p.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("orders: scan carry position: %w", err)
+		}
+		p.NextFundingTime = nextFunding.Time
+		p.LastFundingAt = lastFunding.Time
+		p.Status = worker.CarryStatusOpen
+		positions = append(positions, p)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("orders: load carry positions: %w", err)
+	}
+	return positions, nil
+}
+
+// nullTime - NULL для нулевого времени
+func nullTime(t time.Time) sql.NullTime {
+	return sql.NullTime{Time: t, Valid: !t.IsZero()}
+}
@@ -0,0 +1,188 @@
+package orders
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"daemon-go/internal/db"
+	"daemon-go/internal/market"
+	sqlMySQL "daemon-go/internal/sql/mysql"
+	sqlPostgres "daemon-go/internal/sql/postgres"
+	"daemon-go/internal/worker/executor"
+)
+
+// Fill - одно исполнение ордера
+type Fill struct {
+	Exchange      string    `json:"exchange"`
+	ClientOrderID string    `json:"client_order_id"`
+	TradeID       string    `json:"trade_id"` // ID сделки биржи; для исполнений по REST опросу - синтетический
+	Price         float64   `json:"price"`
+	Volume        float64   `json:"volume"`
+	Fee           float64   `json:"fee"`
+	FeeCurrency   string    `json:"fee_currency,omitempty"`
+	FilledAt      time.Time `json:"filled_at"`
+}
+
+// Journal - постоянное хранилище состояний ордеров и исполнений.
+// Записи идемпотентны: ордер ключуется по (exchange, client_order_id), исполнение - по (exchange, trade_id)
+type Journal interface {
+	SaveOrder(order Record) error
+	SaveFill(fill Fill) error
+	LoadOpenOrders() ([]Record, error)
+}
+
+// DBJournal - журнал в таблицах ORDER и FILL
+type DBJournal struct {
+	db db.DBDriver
+}
+
+var _ executor.ImbalanceRecorder = (*DBJournal)(nil)
+
+// NewDBJournal создает журнал ордеров в БД
+func NewDBJournal(driver db.DBDriver) *DBJournal {
+	return &DBJournal{db: driver}
+}
+
+// SaveOrder сохраняет текущее состояние ордера
+func (j *DBJournal) SaveOrder(r Record) error {
+	query := sqlMySQL.UpsertOrder
+	if j.db.GetType() == "postgres" {
+		query = sqlPostgres.UpsertOrder
+	}
+	return j.exec(query,
+		r.Exchange, r.ClientOrderID, r.OrderID, r.Symbol, string(r.Side), string(r.OrderType),
+		r.Price, r.Volume, r.FilledVolume, r.AvgPrice, r.Fee, r.FeeCurrency, string(r.Status), r.Error,
+		r.CreatedAt, r.UpdatedAt,
+	)
+}
+
+// SaveFill сохраняет исполнение; ордер должен быть уже записан в журнал
+func (j *DBJournal) SaveFill(f Fill) error {
+	query := sqlMySQL.InsertFill
+	if j.db.GetType() == "postgres" {
+		query = sqlPostgres.InsertFill
+	}
+	return j.exec(query,
+		f.Exchange, f.TradeID, f.Price, f.Volume, f.Fee, f.FeeCurrency, f.FilledAt,
+		f.Exchange, f.ClientOrderID,
+	)
+}
+
+// LoadOpenOrders загружает ордера в нефинальных статусах
+func (j *DBJournal) LoadOpenOrders() ([]Record, error) {
+	query := sqlMySQL.OpenOrders
+	if j.db.GetType() == "postgres" {
+		query = sqlPostgres.OpenOrders
+	}
+
+	rows, err := j.db.Query(query)
+	if err != nil {
+		return nil, fmt.Errorf("orders: load open orders: %w", err)
+	}
+	defer rows.Close()
+
+	var records []Record
+	for rows.Next() {
+		var r Record
+		var side, orderType, status string
+		if err := rows.Scan(&r.Exchange, &r.ClientOrderID, &r.OrderID, &r.Symbol, &side, &orderType,
+			&r.Price, &r.Volume, &r.FilledVolume, &r.AvgPrice, &r.Fee, &r.FeeCurrency, &status,
+			&r.CreatedAt, &r.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("orders: scan open order: %w", err)
+		}
+		r.Side = market.TradeSide(side)
+		r.OrderType = market.OrderType(orderType)
+		r.Status = market.OrderStatus(status)
+		records = append(records, r)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("orders: load open orders: %w", err)
+	}
+	return records, nil
+}
+
+// OpenSymbols возвращает символы ордеров в нефинальных статусах по биржам: [exchange] -> символы
+// (risk.OpenSymbolSource для kill switch)
+func (j *DBJournal) OpenSymbols() (map[string][]string, error) {
+	records, err := j.LoadOpenOrders()
+	if err != nil {
+		return nil, err
+	}
+	symbols := make(map[string][]string)
+	seen := make(map[string]bool, len(records))
+	for _, r := range records {
+		exchange := strings.ToLower(r.Exchange)
+		if r.Symbol == "" || seen[exchange+"|"+r.Symbol] {
+			continue
+		}
+		seen[exchange+"|"+r.Symbol] = true
+		symbols[exchange] = append(symbols[exchange], r.Symbol)
+	}
+	return symbols, nil
+}
+
+// hedgeDetail - хеджирующий ордер в DETAILS события дисбаланса
+type hedgeDetail struct {
+	Action   executor.HedgeAction `json:"action"`
+	Exchange string               `json:"exchange"`
+	OrderID  string               `json:"order_id,omitempty"`
+	Type     market.OrderType     `json:"type"`
+	Price    float64              `json:"price"`
+	Volume   float64              `json:"volume"`
+	Filled   float64              `json:"filled"`
+	AvgPrice float64              `json:"avg_price"`
+	Error    string               `json:"error,omitempty"`
+}
+
+// RecordImbalance сохраняет событие дисбаланса ног в LEG_IMBALANCE
+func (j *DBJournal) RecordImbalance(event executor.ImbalanceEvent) error {
+	details := make([]hedgeDetail, 0, len(event.Attempts))
+	for _, attempt := range event.Attempts {
+		d := hedgeDetail{
+			Action:   attempt.Action,
+			Exchange: attempt.Result.Leg.Exchange,
+			Type:     attempt.Result.Leg.Request.OrderType,
+			Price:    attempt.Result.Leg.Request.Price,
+			Volume:   attempt.Result.Leg.Request.Volume,
+			Filled:   attempt.Result.FilledVolume,
+			AvgPrice: attempt.Result.AvgPrice,
+			Error:    attempt.Result.Error,
+		}
+		if attempt.Result.Order != nil {
+			d.OrderID = attempt.Result.Order.OrderID
+		}
+		details = append(details, d)
+	}
+	data, err := json.Marshal(details)
+	if err != nil {
+		return fmt.Errorf("orders: marshal imbalance details: %w", err)
+	}
+
+	query := sqlMySQL.InsertLegImbalance
+	if j.db.GetType() == "postgres" {
+		query = sqlPostgres.InsertLegImbalance
+	}
+	return j.exec(query,
+		event.TaskID, event.Symbol, event.Imbalance, string(event.HedgeSide), event.Hedged, event.Residual,
+		event.Resolved, string(data), event.DetectedAt, event.FinishedAt,
+	)
+}
+
+// exec выполняет запрос записи в отдельной транзакции (DBDriver не предоставляет Exec)
+func (j *DBJournal) exec(query string, args ...interface{}) error {
+	tx, err := j.db.BeginTx()
+	if err != nil {
+		return fmt.Errorf("orders: begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(query, args...); err != nil {
+		return fmt.Errorf("orders: journal write: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("orders: commit: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,593 @@
+package orders
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"daemon-go/internal/bus"
+	exchangepkg "daemon-go/internal/exchange"
+	"daemon-go/internal/market"
+	"daemon-go/internal/worker/executor"
+	"daemon-go/pkg/log"
+)
+
+// Gateway - операции биржи, необходимые для сверки журнала (exchange.TradingAdapter)
+type Gateway interface {
+	GetOpenOrders(symbol string) ([]market.Order, error)
+	GetOrder(symbol, orderID string) (*market.Order, error)
+}
+
+// ErrDuplicateClientOrderID - ордер с таким client order ID уже отслеживается
+var ErrDuplicateClientOrderID = errors.New("orders: duplicate client order id")
+
+// maxTracked - сколько ордеров держать в памяти; сверх лимита удаляются финальные
+const maxTracked = 10000
+
+// ReconcileResult - итог сверки журнала с открытыми ордерами одной биржи
+type ReconcileResult struct {
+	Exchange string   `json:"exchange"`
+	Open     int      `json:"open"`    // открытых ордеров на бирже
+	Updated  int      `json:"updated"` // ордеров журнала, состояние которых изменилось
+	Adopted  int      `json:"adopted"` // открытые ордера биржи, которых не было в журнале
+	Lost     int      `json:"lost"`    // ордера журнала без ID биржи, не найденные среди открытых
+	Errors   []string `json:"errors,omitempty"`
+}
+
+// Manager - единый источник состояния ордеров. Ведет каждый ордер по статусам
+// market.OrderStatus, принимая обновления от executor (OrderObserver) и события
+// приватных потоков (MessageTypeOrderEvent в шине), и записывает каждый переход
+// и исполнение в журнал. При старте сверяет журнал с открытыми ордерами бирж
+type Manager struct {
+	mu            sync.Mutex                            // также упорядочивает записи в журнал
+	journal       Journal                               // nil - только в памяти
+	gateways      map[string]Gateway                    // [exchange]
+	orders        map[string]*Record                    // [exchange|clientOrderID]
+	byOrderID     map[string]string                     // [exchange|orderID] -> ключ orders
+	subscriptions map[string]chan market.UnifiedMessage // [exchange]
+	messageBus    *bus.MessageBus
+	stopChan      chan struct{}
+	logger        *log.Logger
+
+	transitions   int64
+	fills         int64
+	staleUpdates  int64
+	journalErrors int64
+}
+
+var _ executor.OrderObserver = (*Manager)(nil)
+
+// NewManager создает менеджер ордеров с журналом (nil - без сохранения)
+func NewManager(journal Journal) *Manager {
+	return &Manager{
+		journal:       journal,
+		gateways:      make(map[string]Gateway),
+		orders:        make(map[string]*Record),
+		byOrderID:     make(map[string]string),
+		subscriptions: make(map[string]chan market.UnifiedMessage),
+		messageBus:    bus.GetInstance(),
+		logger:        log.New("orders"),
+	}
+}
+
+// Register добавляет биржу для сверки и приема событий ордеров
+func (m *Manager) Register(exchange string, gateway Gateway) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.gateways[strings.ToLower(exchange)] = gateway
+}
+
+// Start подписывается на события ордеров и сверяет журнал с биржами
+func (m *Manager) Start() error {
+	m.mu.Lock()
+	if m.stopChan != nil {
+		m.mu.Unlock()
+		return fmt.Errorf("order manager already started")
+	}
+	m.stopChan = make(chan struct{})
+	for exchange := range m.gateways {
+		ch := m.messageBus.Subscribe(exchange, 100)
+		m.subscriptions[exchange] = ch
+		go m.messageProcessor(exchange, ch)
+	}
+	m.mu.Unlock()
+
+	for _, res := range m.Reconcile() {
+		m.logger.Info("[ORDERS] Reconciled %s: %d open on exchange, %d updated, %d adopted, %d lost, %d errors",
+			res.Exchange, res.Open, res.Updated, res.Adopted, res.Lost, len(res.Errors))
+	}
+	return nil
+}
+
+// Stop отписывается от шины
+func (m *Manager) Stop() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.stopChan == nil {
+		return
+	}
+	close(m.stopChan)
+	m.stopChan = nil
+	for exchange, ch := range m.subscriptions {
+		m.messageBus.Unsubscribe(exchange, ch)
+		delete(m.subscriptions, exchange)
+	}
+	m.logger.Info("[ORDERS] Order manager stopped")
+}
+
+// messageProcessor применяет события ордеров из приватного потока биржи
+func (m *Manager) messageProcessor(exchange string, ch chan market.UnifiedMessage) {
+	for msg := range ch {
+		if msg.MessageType != market.MessageTypeOrderEvent {
+			continue
+		}
+		if event, ok := msg.Data.(market.UnifiedOrderEvent); ok {
+			m.Apply(exchange, event)
+		}
+	}
+}
+
+// OrderSubmitted регистрирует ордер перед отправкой на биржу. Повторная отправка
+// с тем же client order ID отклоняется, что делает размещение идемпотентным
+func (m *Manager) OrderSubmitted(exchange string, req market.OrderRequest) error {
+	if req.ClientOrderID == "" {
+		return fmt.Errorf("orders: client order id is required")
+	}
+	exchange = strings.ToLower(exchange)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if existing, ok := m.orders[orderKey(exchange, req.ClientOrderID)]; ok {
+		return fmt.Errorf("%w: %s on %s (status %s)", ErrDuplicateClientOrderID, req.ClientOrderID, exchange, existing.Status)
+	}
+
+	now := time.Now()
+	r := &Record{Order: market.Order{
+		Exchange:      exchange,
+		Symbol:        req.Symbol,
+		ClientOrderID: req.ClientOrderID,
+		Status:        market.OrderStatusNew,
+		Side:          req.Side,
+		OrderType:     req.OrderType,
+		Price:         req.Price,
+		Volume:        req.Volume,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	}}
+	m.track(r)
+	m.persist(*r, nil)
+	return nil
+}
+
+// OrderUpdated применяет состояние ордера, полученное через REST
+func (m *Manager) OrderUpdated(exchange string, req market.OrderRequest, order *market.Order) {
+	if order == nil {
+		return
+	}
+	exchange = strings.ToLower(exchange)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	r := m.lookup(exchange, req.ClientOrderID, order.OrderID)
+	if r == nil {
+		m.adopt(exchange, *order, req.ClientOrderID)
+		return
+	}
+	m.apply(r, *order, nil)
+}
+
+// OrderFailed отмечает ордер, который не удалось разместить, как rejected
+func (m *Manager) OrderFailed(exchange string, req market.OrderRequest, err error) {
+	exchange = strings.ToLower(exchange)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	r := m.lookup(exchange, req.ClientOrderID, "")
+	if r == nil {
+		return
+	}
+	if err != nil {
+		r.Error = err.Error()
+	}
+	m.apply(r, market.Order{Status: market.OrderStatusRejected}, nil)
+}
+
+// Apply применяет событие ордера из приватного потока. Ордера, размещенные не через
+// менеджер, игнорируются
+func (m *Manager) Apply(exchange string, event market.UnifiedOrderEvent) {
+	exchange = strings.ToLower(exchange)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	r := m.lookup(exchange, event.ClientOrderID, event.OrderID)
+	if r == nil {
+		return
+	}
+
+	update := market.Order{
+		OrderID:      event.OrderID,
+		Status:       event.Status,
+		Price:        event.Price,
+		FilledVolume: event.FilledVolume,
+		AvgPrice:     event.AvgPrice,
+		Fee:          event.Fee,
+		FeeCurrency:  event.FeeCurrency,
+		UpdatedAt:    event.Timestamp,
+	}
+	var hint *Fill
+	if event.IsFill() {
+		hint = &Fill{
+			TradeID:  event.TradeID,
+			Price:    event.LastFillPrice,
+			Volume:   event.LastFillVolume,
+			Fee:      event.LastFillFee,
+			FilledAt: event.Timestamp,
+		}
+	}
+	m.apply(r, update, hint)
+}
+
+// Reconcile загружает нефинальные ордера журнала и сверяет их с открытыми ордерами бирж:
+// обновляет статусы, запрашивает закрытые ордера по ID и принимает в журнал
+// открытые ордера, о которых журнал не знает
+func (m *Manager) Reconcile() []ReconcileResult {
+	if m.journal != nil {
+		records, err := m.journal.LoadOpenOrders()
+		if err != nil {
+			m.logger.Error("[ORDERS] Failed to load order journal: %v", err)
+		}
+		m.mu.Lock()
+		for i := range records {
+			r := records[i]
+			if _, ok := m.orders[orderKey(r.Exchange, r.ClientOrderID)]; !ok {
+				m.track(&r)
+			}
+		}
+		m.mu.Unlock()
+	}
+
+	m.mu.Lock()
+	exchanges := make([]string, 0, len(m.gateways))
+	for exchange := range m.gateways {
+		exchanges = append(exchanges, exchange)
+	}
+	m.mu.Unlock()
+	sort.Strings(exchanges)
+
+	results := make([]ReconcileResult, 0, len(exchanges))
+	for _, exchange := range exchanges {
+		m.mu.Lock()
+		gw := m.gateways[exchange]
+		m.mu.Unlock()
+		results = append(results, m.reconcileExchange(exchange, gw))
+	}
+	return results
+}
+
+// reconcileExchange сверяет ордера одной биржи
+func (m *Manager) reconcileExchange(exchange string, gw Gateway) ReconcileResult {
+	res := ReconcileResult{Exchange: exchange}
+
+	// Биржи, где символ обязателен, опрашиваются по символам нефинальных ордеров журнала
+	m.mu.Lock()
+	var symbols []string
+	for _, r := range m.orders {
+		if r.Exchange == exchange && !r.Status.IsFinal() {
+			symbols = append(symbols, r.Symbol)
+		}
+	}
+	m.mu.Unlock()
+	sort.Strings(symbols)
+
+	open, err := exchangepkg.ListOpenOrders(gw, symbols)
+	if err != nil {
+		m.logger.Error("[ORDERS] %s: get open orders failed: %v", exchange, err)
+		res.Errors = append(res.Errors, fmt.Sprintf("get open orders: %v", err))
+		return res
+	}
+	res.Open = len(open)
+
+	m.mu.Lock()
+	seen := make(map[string]bool, len(open))
+	for _, o := range open {
+		r := m.lookup(exchange, o.ClientOrderID, o.OrderID)
+		if r == nil {
+			r = m.adopt(exchange, o, o.ClientOrderID)
+			m.logger.Warn("[ORDERS] %s: adopted open order %s (%s %s) missing from journal",
+				exchange, o.OrderID, o.Side, o.Symbol)
+			res.Adopted++
+		} else if m.apply(r, o, nil) {
+			res.Updated++
+		}
+		seen[orderKey(exchange, r.ClientOrderID)] = true
+	}
+
+	// Ордера журнала, которых нет среди открытых: закрылись, пока демон не работал
+	var closed []Record
+	for key, r := range m.orders {
+		if r.Exchange == exchange && !r.Status.IsFinal() && !seen[key] {
+			closed = append(closed, *r)
+		}
+	}
+	m.mu.Unlock()
+
+	for _, c := range closed {
+		if c.OrderID == "" {
+			// Ордер отправлялся, но ответ биржи не был получен и среди открытых его нет
+			m.mu.Lock()
+			if r := m.orders[orderKey(exchange, c.ClientOrderID)]; r != nil {
+				r.Error = "not found on exchange during reconciliation"
+				m.apply(r, market.Order{Status: market.OrderStatusRejected}, nil)
+			}
+			m.mu.Unlock()
+			res.Lost++
+			continue
+		}
+
+		order, err := gw.GetOrder(c.Symbol, c.OrderID)
+		if err != nil {
+			m.logger.Error("[ORDERS] %s: get order %s failed: %v", exchange, c.OrderID, err)
+			res.Errors = append(res.Errors, fmt.Sprintf("get order %s: %v", c.OrderID, err))
+			continue
+		}
+		m.mu.Lock()
+		if r := m.orders[orderKey(exchange, c.ClientOrderID)]; r != nil && m.apply(r, *order, nil) {
+			res.Updated++
+		}
+		m.mu.Unlock()
+	}
+	return res
+}
+
+// Get возвращает ордер по бирже и client order ID
+func (m *Manager) Get(exchange, clientOrderID string) (Record, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	r, ok := m.orders[orderKey(strings.ToLower(exchange), clientOrderID)]
+	if !ok {
+		return Record{}, false
+	}
+	return *r, true
+}
+
+// OpenOrders возвращает ордера в нефинальных статусах
+func (m *Manager) OpenOrders() []Record {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var open []Record
+	for _, r := range m.orders {
+		if !r.Status.IsFinal() {
+			open = append(open, *r)
+		}
+	}
+	sort.Slice(open, func(i, j int) bool { return open[i].CreatedAt.Before(open[j].CreatedAt) })
+	return open
+}
+
+// GetStats возвращает статистику менеджера ордеров
+func (m *Manager) GetStats() map[string]interface{} {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	open := 0
+	for _, r := range m.orders {
+		if !r.Status.IsFinal() {
+			open++
+		}
+	}
+	return map[string]interface{}{
+		"tracked":        len(m.orders),
+		"open":           open,
+		"transitions":    m.transitions,
+		"fills":          m.fills,
+		"stale_updates":  m.staleUpdates,
+		"journal_errors": m.journalErrors,
+	}
+}
+
+// orderKey - ключ ордера в карте: биржа и client order ID (или ID биржи)
+func orderKey(exchange, id string) string {
+	return exchange + "|" + id
+}
+
+// lookup ищет ордер по client order ID, затем по ID биржи (вызывается под m.mu)
+func (m *Manager) lookup(exchange, clientOrderID, orderID string) *Record {
+	if clientOrderID != "" {
+		if r, ok := m.orders[orderKey(exchange, clientOrderID)]; ok {
+			return r
+		}
+	}
+	if orderID != "" {
+		if key, ok := m.byOrderID[orderKey(exchange, orderID)]; ok {
+			return m.orders[key]
+		}
+	}
+	return nil
+}
+
+// track добавляет ордер в индексы (вызывается под m.mu)
+func (m *Manager) track(r *Record) {
+	if len(m.orders) >= maxTracked {
+		m.prune()
+	}
+	key := orderKey(r.Exchange, r.ClientOrderID)
+	m.orders[key] = r
+	if r.OrderID != "" {
+		m.byOrderID[orderKey(r.Exchange, r.OrderID)] = key
+	}
+}
+
+// prune удаляет финальные ордера из памяти (журнал в БД сохраняется)
+func (m *Manager) prune() {
+	for key, r := range m.orders {
+		if r.Status.IsFinal() {
+			delete(m.orders, key)
+			if r.OrderID != "" {
+				delete(m.byOrderID, orderKey(r.Exchange, r.OrderID))
+			}
+		}
+	}
+}
+
+// adopt начинает отслеживать ордер, размещенный не через менеджер (вызывается под m.mu)
+func (m *Manager) adopt(exchange string, order market.Order, clientOrderID string) *Record {
+	r := &Record{Order: order}
+	r.Exchange = exchange
+	r.ClientOrderID = clientOrderID
+	if r.ClientOrderID == "" {
+		r.ClientOrderID = "ext-" + order.OrderID
+	}
+	if r.Status == "" {
+		r.Status = market.OrderStatusNew
+	}
+	now := time.Now()
+	if r.CreatedAt.IsZero() {
+		r.CreatedAt = now
+	}
+	r.UpdatedAt = now
+	m.track(r)
+	m.persist(*r, nil)
+	return r
+}
+
+// apply применяет новое состояние ордера: допустимый переход статуса и/или прирост
+// исполненного объема. Устаревшие обновления (переход назад, из финального статуса)
+// игнорируются. Прирост объема записывается как исполнение: с ID сделки из hint,
+// если событие сообщает ровно этот прирост, иначе с синтетическим ID.
+// Возвращает true, если состояние изменилось (вызывается под m.mu)
+func (m *Manager) apply(r *Record, u market.Order, hint *Fill) bool {
+	if r.Status.IsFinal() {
+		return false
+	}
+
+	status := u.Status
+	if status == "" {
+		status = r.Status
+	}
+	transition := status != r.Status && CanTransition(r.Status, status)
+	delta := u.FilledVolume - r.FilledVolume
+	newOrderID := r.OrderID == "" && u.OrderID != ""
+
+	if !transition && delta <= volumeEpsilon && !newOrderID {
+		if status != r.Status {
+			m.staleUpdates++
+		}
+		return false
+	}
+
+	if newOrderID {
+		r.OrderID = u.OrderID
+		m.byOrderID[orderKey(r.Exchange, r.OrderID)] = orderKey(r.Exchange, r.ClientOrderID)
+	}
+	if u.FeeCurrency != "" {
+		r.FeeCurrency = u.FeeCurrency
+	}
+
+	var fill *Fill
+	if delta > volumeEpsilon {
+		fill = m.fillFor(r, u, delta, hint)
+		filledQuote := r.AvgPrice*r.FilledVolume + fill.Price*delta
+		r.FilledVolume = u.FilledVolume
+		r.AvgPrice = u.AvgPrice
+		if r.AvgPrice <= 0 {
+			r.AvgPrice = filledQuote / r.FilledVolume
+		}
+		if u.Fee > r.Fee {
+			r.Fee = u.Fee
+		} else {
+			r.Fee += fill.Fee
+		}
+		m.fills++
+	}
+
+	if transition {
+		m.logger.Info("[ORDERS] %s %s (%s): %s -> %s, filled %.8f of %.8f",
+			r.Exchange, r.ClientOrderID, r.OrderID, r.Status, status, r.FilledVolume, r.Volume)
+		r.Status = status
+		m.transitions++
+	}
+	r.UpdatedAt = time.Now()
+	m.persist(*r, fill)
+	return true
+}
+
+// fillFor формирует исполнение для прироста объема delta
+func (m *Manager) fillFor(r *Record, u market.Order, delta float64, hint *Fill) *Fill {
+	fill := &Fill{
+		Exchange:      r.Exchange,
+		ClientOrderID: r.ClientOrderID,
+		Volume:        delta,
+		FeeCurrency:   r.FeeCurrency,
+		FilledAt:      u.UpdatedAt,
+	}
+	if fill.FilledAt.IsZero() {
+		fill.FilledAt = time.Now()
+	}
+
+	if hint != nil && hint.TradeID != "" && math.Abs(hint.Volume-delta) <= volumeEpsilon*math.Max(1, delta) {
+		fill.TradeID = hint.TradeID
+		fill.Price = hint.Price
+		fill.Fee = hint.Fee
+		if !hint.FilledAt.IsZero() {
+			fill.FilledAt = hint.FilledAt
+		}
+		return fill
+	}
+
+	// Синтетический ID: ордер и накопленный объем, чтобы повтор того же прироста не задвоился
+	id := r.OrderID
+	if id == "" {
+		id = r.ClientOrderID
+	}
+	fill.TradeID = id + ":" + strconv.FormatFloat(u.FilledVolume, 'f', -1, 64)
+
+	switch {
+	case hint != nil && hint.Price > 0:
+		fill.Price = hint.Price
+	case u.AvgPrice > 0:
+		fill.Price = (u.AvgPrice*u.FilledVolume - r.AvgPrice*r.FilledVolume) / delta
+		if fill.Price <= 0 {
+			fill.Price = u.AvgPrice
+		}
+	default:
+		fill.Price = u.Price
+		if fill.Price <= 0 {
+			fill.Price = r.Price
+		}
+	}
+	if u.Fee > r.Fee {
+		fill.Fee = u.Fee - r.Fee
+	} else if hint != nil {
+		fill.Fee = hint.Fee
+	}
+	return fill
+}
+
+// persist записывает ордер и исполнение в журнал (вызывается под m.mu, чтобы
+// записи одного ордера не переупорядочивались)
+func (m *Manager) persist(r Record, fill *Fill) {
+	if m.journal == nil {
+		return
+	}
+	if err := m.journal.SaveOrder(r); err != nil {
+		m.journalErrors++
+		m.logger.Error("[ORDERS] Journal write failed for %s %s: %v", r.Exchange, r.ClientOrderID, err)
+		return
+	}
+	if fill == nil {
+		return
+	}
+	if err := m.journal.SaveFill(*fill); err != nil {
+		m.journalErrors++
+		m.logger.Error("[ORDERS] Fill journal write failed for %s %s: %v", r.Exchange, r.ClientOrderID, err)
+	}
+}
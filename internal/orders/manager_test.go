@@ -0,0 +1,187 @@
+package orders
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"daemon-go/internal/exchange"
+	"daemon-go/internal/market"
+)
+
+// memJournal - журнал ордеров в памяти
+type memJournal struct {
+	open   []Record
+	orders map[string]Record // [exchange|clientOrderID]
+	fills  []Fill
+}
+
+func newMemJournal(open ...Record) *memJournal {
+	return &memJournal{open: open, orders: make(map[string]Record)}
+}
+
+func (j *memJournal) SaveOrder(r Record) error {
+	j.orders[orderKey(r.Exchange, r.ClientOrderID)] = r
+	return nil
+}
+
+func (j *memJournal) SaveFill(f Fill) error {
+	j.fills = append(j.fills, f)
+	return nil
+}
+
+func (j *memJournal) LoadOpenOrders() ([]Record, error) { return j.open, nil }
+
+// reconcileGateway - биржа теста: открытые ордера и состояния ордеров по ID
+type reconcileGateway struct {
+	open       []market.Order
+	orders     map[string]market.Order // [orderID]
+	openErr    error
+	symbolOnly bool // GetOpenOrders("") -> ErrSymbolRequired, как HTX
+	listed     []string
+}
+
+func (g *reconcileGateway) GetOpenOrders(symbol string) ([]market.Order, error) {
+	g.listed = append(g.listed, symbol)
+	if g.openErr != nil {
+		return nil, g.openErr
+	}
+	if symbol == "" && g.symbolOnly {
+		return nil, fmt.Errorf("open orders: %w", exchange.ErrSymbolRequired)
+	}
+	var open []market.Order
+	for _, o := range g.open {
+		if symbol == "" || o.Symbol == symbol {
+			open = append(open, o)
+		}
+	}
+	return open, nil
+}
+
+func (g *reconcileGateway) GetOrder(symbol, orderID string) (*market.Order, error) {
+	o, ok := g.orders[orderID]
+	if !ok {
+		return nil, fmt.Errorf("order %s not found", orderID)
+	}
+	return &o, nil
+}
+
+func journalOrder(clientOrderID, orderID string, status market.OrderStatus, filled float64) Record {
+	created := time.Unix(1700000000, 0)
+	return Record{Order: market.Order{
+		Exchange: "binance", Symbol: "BTC/USDT", ClientOrderID: clientOrderID, OrderID: orderID,
+		Status: status, Side: market.TradeSideBuy, OrderType: market.OrderTypeLimit,
+		Price: 100, Volume: 2, FilledVolume: filled, AvgPrice: 100, CreatedAt: created, UpdatedAt: created,
+	}}
+}
+
+func TestReconcile(t *testing.T) {
+	type want struct {
+		result ReconcileResult
+		status map[string]market.OrderStatus // [clientOrderID]
+		fills  int
+	}
+	tests := []struct {
+		name    string
+		journal []Record
+		gateway *reconcileGateway
+		want    want
+	}{
+		{
+			name:    "open order progressed",
+			journal: []Record{journalOrder("c1", "1", market.OrderStatusNew, 0)},
+			gateway: &reconcileGateway{open: []market.Order{{Symbol: "BTC/USDT", OrderID: "1", ClientOrderID: "c1",
+				Status: market.OrderStatusPartiallyFilled, FilledVolume: 1, AvgPrice: 100}}},
+			want: want{
+				result: ReconcileResult{Exchange: "binance", Open: 1, Updated: 1},
+				status: map[string]market.OrderStatus{"c1": market.OrderStatusPartiallyFilled},
+				fills:  1,
+			},
+		},
+		{
+			name:    "closed while stopped",
+			journal: []Record{journalOrder("c1", "1", market.OrderStatusPartiallyFilled, 1)},
+			gateway: &reconcileGateway{orders: map[string]market.Order{"1": {OrderID: "1",
+				Status: market.OrderStatusFilled, FilledVolume: 2, AvgPrice: 100}}},
+			want: want{
+				result: ReconcileResult{Exchange: "binance", Updated: 1},
+				status: map[string]market.OrderStatus{"c1": market.OrderStatusFilled},
+				fills:  1,
+			},
+		},
+		{
+			name:    "sent without exchange ack",
+			journal: []Record{journalOrder("c1", "", market.OrderStatusNew, 0)},
+			gateway: &reconcileGateway{},
+			want: want{
+				result: ReconcileResult{Exchange: "binance", Lost: 1},
+				status: map[string]market.OrderStatus{"c1": market.OrderStatusRejected},
+			},
+		},
+		{
+			name:    "open order missing from journal",
+			gateway: &reconcileGateway{open: []market.Order{{Symbol: "ETH/USDT", OrderID: "9", Status: market.OrderStatusNew}}},
+			want: want{
+				result: ReconcileResult{Exchange: "binance", Open: 1, Adopted: 1},
+				status: map[string]market.OrderStatus{"ext-9": market.OrderStatusNew},
+			},
+		},
+		{
+			name:    "get order failed",
+			journal: []Record{journalOrder("c1", "1", market.OrderStatusNew, 0)},
+			gateway: &reconcileGateway{},
+			want: want{
+				result: ReconcileResult{Exchange: "binance", Errors: []string{"get order 1: order 1 not found"}},
+				status: map[string]market.OrderStatus{"c1": market.OrderStatusNew},
+			},
+		},
+		{
+			name:    "open orders unavailable",
+			journal: []Record{journalOrder("c1", "", market.OrderStatusNew, 0)},
+			gateway: &reconcileGateway{openErr: errors.New("timeout")},
+			want: want{
+				result: ReconcileResult{Exchange: "binance", Errors: []string{"get open orders: timeout"}},
+				status: map[string]market.OrderStatus{"c1": market.OrderStatusNew},
+			},
+		},
+		{
+			name:    "symbol required",
+			journal: []Record{journalOrder("c1", "1", market.OrderStatusNew, 0)},
+			gateway: &reconcileGateway{symbolOnly: true, open: []market.Order{
+				{Symbol: "BTC/USDT", OrderID: "1", ClientOrderID: "c1", Status: market.OrderStatusNew}}},
+			want: want{
+				result: ReconcileResult{Exchange: "binance", Open: 1},
+				status: map[string]market.OrderStatus{"c1": market.OrderStatusNew},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			journal := newMemJournal(tt.journal...)
+			m := NewManager(journal)
+			m.Register("Binance", tt.gateway)
+
+			results := m.Reconcile()
+
+			if len(results) != 1 || fmt.Sprintf("%+v", results[0]) != fmt.Sprintf("%+v", tt.want.result) {
+				t.Fatalf("results %+v, want %+v", results, tt.want.result)
+			}
+			for id, status := range tt.want.status {
+				r, ok := m.Get("binance", id)
+				if !ok || r.Status != status {
+					t.Errorf("order %s: %+v (tracked %v), want status %s", id, r, ok, status)
+				}
+				if saved, ok := journal.orders[orderKey("binance", id)]; status != market.OrderStatusNew && (!ok || saved.Status != status) {
+					t.Errorf("order %s journaled as %+v, want status %s", id, saved, status)
+				}
+			}
+			if len(journal.fills) != tt.want.fills {
+				t.Errorf("fills %+v, want %d", journal.fills, tt.want.fills)
+			}
+			if tt.gateway.symbolOnly && fmt.Sprint(tt.gateway.listed) != fmt.Sprint([]string{"", "BTC/USDT"}) {
+				t.Errorf("listed %q, want all-symbols request then journal symbols", tt.gateway.listed)
+			}
+		})
+	}
+}
@@ -0,0 +1,41 @@
+package orders
+
+import (
+	"daemon-go/internal/market"
+)
+
+// Record - ордер в журнале: состояние на бирже и ошибка размещения
+type Record struct {
+	market.Order
+	Error string `json:"error,omitempty"`
+}
+
+// transitions - допустимые переходы статусов ордера. Из финальных статусов
+// (filled, canceled, rejected, expired) переходов нет
+var transitions = map[market.OrderStatus][]market.OrderStatus{
+	market.OrderStatusNew: {
+		market.OrderStatusPartiallyFilled,
+		market.OrderStatusFilled,
+		market.OrderStatusCanceled,
+		market.OrderStatusRejected,
+		market.OrderStatusExpired,
+	},
+	market.OrderStatusPartiallyFilled: {
+		market.OrderStatusFilled,
+		market.OrderStatusCanceled,
+		market.OrderStatusExpired,
+	},
+}
+
+// CanTransition проверяет, допустим ли переход статуса ордера from -> to
+func CanTransition(from, to market.OrderStatus) bool {
+	for _, next := range transitions[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+// volumeEpsilon - точность сравнения объемов исполнения
+const volumeEpsilon = 1e-12
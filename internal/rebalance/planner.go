@@ -0,0 +1,290 @@
+package rebalance
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"daemon-go/internal/balance"
+	"daemon-go/internal/config"
+	"daemon-go/pkg/log"
+)
+
+// amountEpsilon - точность сравнения количеств актива
+const amountEpsilon = 1e-12
+
+// BalanceView - балансы аккаунтов бирж (balance.Service)
+type BalanceView interface {
+	Snapshot() map[string][]balance.AssetBalance
+}
+
+// Transfer - предложенный перевод актива между биржами. Amount списывается на бирже From,
+// на биржу To поступает Amount - NetworkFee
+type Transfer struct {
+	Asset      string    `json:"asset"`
+	From       string    `json:"from"`
+	To         string    `json:"to"`
+	Amount     float64   `json:"amount"`
+	NetworkFee float64   `json:"network_fee"` // оценка комиссии сети
+	Reason     string    `json:"reason"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// Plan - план переводов по итогам одного прохода планировщика
+type Plan struct {
+	CreatedAt time.Time  `json:"created_at"`
+	Transfers []Transfer `json:"transfers"`
+}
+
+// Config - настройки планировщика
+type Config struct {
+	Interval    time.Duration      // минимальный интервал между планированиями
+	Assets      []string           // активы, распределение которых поддерживается
+	Weights     map[string]float64 // [exchange] -> целевая доля; пусто - поровну между биржами с балансами
+	Threshold   float64            // доля отклонения вниз от целевого баланса, с которой нужен перевод
+	NetworkFees map[string]float64 // [asset] -> оценка комиссии сети за перевод
+}
+
+// ConfigFromApp строит настройки из секции [rebalance] конфига
+func ConfigFromApp(cfg *config.Config) Config {
+	c := Config{
+		Interval:    time.Duration(cfg.Rebalance.Interval) * time.Second,
+		Weights:     parsePairs(cfg.Rebalance.Weights, strings.ToLower),
+		Threshold:   cfg.Rebalance.ThresholdPercent / 100,
+		NetworkFees: parsePairs(cfg.Rebalance.NetworkFees, strings.ToUpper),
+	}
+	for _, asset := range strings.Split(cfg.Rebalance.Assets, ",") {
+		if asset = strings.ToUpper(strings.TrimSpace(asset)); asset != "" {
+			c.Assets = append(c.Assets, asset)
+		}
+	}
+	return c
+}
+
+// parsePairs разбирает список вида "key:value,key:value"; некорректные элементы пропускаются
+func parsePairs(s string, normalize func(string) string) map[string]float64 {
+	result := make(map[string]float64)
+	for _, item := range strings.Split(s, ",") {
+		key, value, ok := strings.Cut(strings.TrimSpace(item), ":")
+		if !ok {
+			continue
+		}
+		v, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		if err != nil {
+			continue
+		}
+		result[normalize(strings.TrimSpace(key))] = v
+	}
+	return result
+}
+
+// Planner следит за балансами бирж и предлагает переводы, возвращающие распределение
+// активов к целевым долям. Планы записываются в хранилище и исполняются только после
+// подтверждения оператором
+type Planner struct {
+	mu       sync.Mutex
+	config   Config
+	balances BalanceView
+	store    Store // nil - план только в логе
+	lastRun  time.Time
+	lastPlan Plan
+	logger   *log.Logger
+
+	runs     int64
+	proposed int64
+}
+
+// NewPlanner создает планировщик переводов
+func NewPlanner(cfg Config, balances BalanceView, store Store) *Planner {
+	return &Planner{
+		config:   cfg,
+		balances: balances,
+		store:    store,
+		logger:   log.New("rebalance"),
+	}
+}
+
+// Run строит план, если с прошлого запуска прошел Interval, и сохраняет переводы,
+// для которых в хранилище еще нет необработанного перевода того же актива между теми же биржами
+func (p *Planner) Run() (Plan, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if time.Since(p.lastRun) < p.config.Interval {
+		return Plan{}, nil
+	}
+	p.lastRun = time.Now()
+	p.runs++
+
+	plan := p.Plan()
+	if len(plan.Transfers) == 0 || p.store == nil {
+		p.lastPlan = plan
+		return plan, nil
+	}
+
+	pending, err := p.store.PendingTransfers()
+	if err != nil {
+		return plan, fmt.Errorf("rebalance: load pending transfers: %w", err)
+	}
+	exists := make(map[string]bool, len(pending))
+	for _, t := range pending {
+		exists[transferKey(t)] = true
+	}
+	var fresh []Transfer
+	for _, t := range plan.Transfers {
+		if !exists[transferKey(t)] {
+			fresh = append(fresh, t)
+		}
+	}
+	plan.Transfers = fresh
+	p.lastPlan = plan
+	if len(fresh) == 0 {
+		return plan, nil
+	}
+
+	if err := p.store.SaveTransfers(fresh); err != nil {
+		return plan, fmt.Errorf("rebalance: save transfers: %w", err)
+	}
+	p.proposed += int64(len(fresh))
+	for _, t := range fresh {
+		p.logger.Info("[REBALANCE] Proposed transfer %.8f %s %s -> %s (network fee %.8f): %s",
+			t.Amount, t.Asset, t.From, t.To, t.NetworkFee, t.Reason)
+	}
+	return plan, nil
+}
+
+// Plan строит план переводов по текущим балансам. Целевой баланс биржи - ее доля
+// (Weights) от суммарного количества актива; биржа, баланс которой ниже целевого больше
+// чем на Threshold, пополняется до целевого с бирж, где актива больше целевого
+// (не больше их доступного баланса). Переводы не больше комиссии сети не предлагаются
+func (p *Planner) Plan() Plan {
+	now := time.Now()
+	plan := Plan{CreatedAt: now}
+	if p.balances == nil {
+		return plan
+	}
+	snapshot := p.balances.Snapshot()
+
+	for _, asset := range p.config.Assets {
+		plan.Transfers = append(plan.Transfers, p.planAsset(asset, snapshot, now)...)
+	}
+	return plan
+}
+
+// holding - актив на одной бирже
+type holding struct {
+	exchange  string
+	total     float64 // free + locked
+	available float64 // доступно для вывода
+	target    float64
+}
+
+// planAsset строит переводы одного актива
+func (p *Planner) planAsset(asset string, snapshot map[string][]balance.AssetBalance, now time.Time) []Transfer {
+	var holdings []*holding
+	weightSum, total := 0.0, 0.0
+	for exchange, assets := range snapshot {
+		weight := p.weight(exchange)
+		if weight <= 0 {
+			continue
+		}
+		h := &holding{exchange: exchange}
+		for _, b := range assets {
+			if b.Asset == asset {
+				h.total = b.Free + b.Locked
+				h.available = b.Available
+				break
+			}
+		}
+		holdings = append(holdings, h)
+		weightSum += weight
+		total += h.total
+	}
+	if len(holdings) < 2 || total <= amountEpsilon {
+		return nil
+	}
+
+	var deficits, surpluses []*holding
+	for _, h := range holdings {
+		h.target = total * p.weight(h.exchange) / weightSum
+		switch {
+		case h.total < h.target*(1-p.config.Threshold):
+			deficits = append(deficits, h)
+		case h.total > h.target && h.available > amountEpsilon:
+			surpluses = append(surpluses, h)
+		}
+	}
+	// Сначала самые большие недостачи и избытки: меньше переводов
+	sort.Slice(deficits, func(i, j int) bool {
+		return deficits[i].target-deficits[i].total > deficits[j].target-deficits[j].total
+	})
+	sort.Slice(surpluses, func(i, j int) bool {
+		return surpluses[i].total-surpluses[i].target > surpluses[j].total-surpluses[j].target
+	})
+
+	fee := p.config.NetworkFees[asset]
+	var transfers []Transfer
+	for _, d := range deficits {
+		need := d.target - d.total
+		for _, s := range surpluses {
+			if need <= amountEpsilon {
+				break
+			}
+			spare := math.Min(s.total-s.target, s.available)
+			amount := math.Min(need+fee, spare)
+			if amount <= fee+amountEpsilon {
+				continue
+			}
+			transfers = append(transfers, Transfer{
+				Asset:      asset,
+				From:       s.exchange,
+				To:         d.exchange,
+				Amount:     amount,
+				NetworkFee: fee,
+				Reason: fmt.Sprintf("%s %.8f below target %.8f (%.1f%% of %.8f total)",
+					d.exchange, d.total, d.target, 100*d.target/total, total),
+				CreatedAt: now,
+			})
+			s.total -= amount
+			s.available -= amount
+			need -= amount - fee
+		}
+	}
+	return transfers
+}
+
+// weight возвращает целевую долю биржи; без настроенных долей все биржи равны
+func (p *Planner) weight(exchange string) float64 {
+	if len(p.config.Weights) == 0 {
+		return 1
+	}
+	return p.config.Weights[strings.ToLower(exchange)]
+}
+
+// transferKey - ключ перевода для поиска необработанных дублей
+func transferKey(t Transfer) string {
+	return strings.ToUpper(t.Asset) + "|" + strings.ToLower(t.From) + "|" + strings.ToLower(t.To)
+}
+
+// LastPlan возвращает план последнего запуска
+func (p *Planner) LastPlan() Plan {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.lastPlan
+}
+
+// GetStats возвращает статистику планировщика
+func (p *Planner) GetStats() map[string]interface{} {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return map[string]interface{}{
+		"runs":      p.runs,
+		"proposed":  p.proposed,
+		"last_run":  p.lastRun,
+		"last_plan": p.lastPlan,
+	}
+}
@@ -0,0 +1,71 @@
+package rebalance
+
+import (
+	"fmt"
+
+	"daemon-go/internal/db"
+	sqlMySQL "daemon-go/internal/sql/mysql"
+	sqlPostgres "daemon-go/internal/sql/postgres"
+)
+
+// Store - хранилище планов переводов, которые подтверждает оператор
+type Store interface {
+	PendingTransfers() ([]Transfer, error)
+	SaveTransfers(transfers []Transfer) error
+}
+
+// DBStore - планы переводов в таблице TRANSFER_PLAN. Оператор переводит строки из proposed
+// в approved/rejected, после выполнения перевода - в done
+type DBStore struct {
+	db db.DBDriver
+}
+
+// NewDBStore создает хранилище планов переводов в БД
+func NewDBStore(driver db.DBDriver) *DBStore {
+	return &DBStore{db: driver}
+}
+
+// PendingTransfers загружает переводы в статусах proposed и approved
+func (s *DBStore) PendingTransfers() ([]Transfer, error) {
+	query := sqlMySQL.PendingTransfers
+	if s.db.GetType() == "postgres" {
+		query = sqlPostgres.PendingTransfers
+	}
+
+	rows, err := s.db.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var transfers []Transfer
+	for rows.Next() {
+		var t Transfer
+		if err := rows.Scan(&t.Asset, &t.From, &t.To, &t.Amount, &t.NetworkFee, &t.Reason, &t.CreatedAt); err != nil {
+			return nil, err
+		}
+		transfers = append(transfers, t)
+	}
+	return transfers, rows.Err()
+}
+
+// SaveTransfers записывает переводы в статусе proposed одной транзакцией
+func (s *DBStore) SaveTransfers(transfers []Transfer) error {
+	query := sqlMySQL.InsertTransfer
+	if s.db.GetType() == "postgres" {
+		query = sqlPostgres.InsertTransfer
+	}
+
+	tx, err := s.db.BeginTx()
+	if err != nil {
+		return fmt.Errorf("begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	for _, t := range transfers {
+		if _, err := tx.Exec(query, t.Asset, t.From, t.To, t.Amount, t.NetworkFee, t.Reason, t.CreatedAt); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
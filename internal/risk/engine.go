@@ -0,0 +1,346 @@
+package risk
+
+import (
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"daemon-go/internal/market"
+	"daemon-go/internal/worker/executor"
+	"daemon-go/pkg/log"
+)
+
+// Причины отклонения ордера
+const (
+	ReasonKillSwitch       = "kill_switch"
+	ReasonOrderNotional    = "order_notional"
+	ReasonExchangeNotional = "exchange_notional"
+	ReasonDailyLoss        = "daily_loss"
+	ReasonOpenOrders       = "open_orders"
+	ReasonNoBook           = "no_book"
+	ReasonStaleBook        = "stale_book"
+	ReasonPriceBand        = "price_band"
+	ReasonBBO              = "bbo"
+)
+
+// RejectError - ордер отклонен риск-менеджментом
+type RejectError struct {
+	Reason string
+	Detail string
+}
+
+func (e *RejectError) Error() string {
+	return fmt.Sprintf("risk: rejected (%s): %s", e.Reason, e.Detail)
+}
+
+// BookSource - источник актуальных стаканов для проверки цены ордера
+type BookSource interface {
+	OrderBook(exchange, symbol string) *market.UnifiedOrderBook
+}
+
+// reservation - лимиты, занятые размещенным ордером
+type reservation struct {
+	exchange string
+	notional float64
+	placedAt time.Time
+}
+
+// Engine - pre-trade риск-менеджмент: проверяет каждый ордер перед отправкой на биржу
+// и учитывает открытые ордера и дневной результат (реализует executor.PreTradeCheck)
+type Engine struct {
+	mu     sync.Mutex
+	limits Limits
+	books  BookSource
+	kill   *KillSwitch // глобальный kill switch (nil - только локальный Halt)
+	now    func() time.Time
+	logger *log.Logger
+
+	reservations     map[string]reservation // [clientOrderID]
+	exchangeNotional map[string]float64     // [exchange] -> стоимость открытых ордеров
+	day              time.Time              // начало текущих суток (UTC)
+	dailyPnL         float64
+
+	halted     bool
+	haltReason string
+	rejects    map[string]int64 // [reason] -> количество отклонений
+}
+
+var _ executor.PreTradeCheck = (*Engine)(nil)
+
+// NewEngine создает риск-движок; books может быть nil, тогда проверки по стакану пропускаются
+func NewEngine(limits Limits, books BookSource) *Engine {
+	return &Engine{
+		limits:           limits,
+		books:            books,
+		now:              time.Now,
+		logger:           log.New("risk"),
+		reservations:     make(map[string]reservation),
+		exchangeNotional: make(map[string]float64),
+		rejects:          make(map[string]int64),
+	}
+}
+
+// SetClock задает источник времени (бэктест проверяет возраст стаканов по времени симуляции)
+func (e *Engine) SetClock(now func() time.Time) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.now = now
+}
+
+// SetKillSwitch подключает глобальный kill switch: пока он включен, все ордера отклоняются
+func (e *Engine) SetKillSwitch(kill *KillSwitch) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.kill = kill
+}
+
+// SetLimits заменяет лимиты; занятые открытыми ордерами лимиты сохраняются
+func (e *Engine) SetLimits(limits Limits) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.limits = limits
+}
+
+// Limits возвращает текущие лимиты
+func (e *Engine) Limits() Limits {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.limits
+}
+
+// Check проверяет ордер и при успехе резервирует под него лимиты
+func (e *Engine) Check(exchange string, req market.OrderRequest) error {
+	var book *market.UnifiedOrderBook
+	if e.books != nil {
+		book = e.books.OrderBook(exchange, req.Symbol)
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if err := e.check(exchange, req, book); err != nil {
+		e.rejects[err.Reason]++
+		e.logger.Warn("[RISK] Rejected %s %s %s %.8f @ %.8f: %s",
+			exchange, req.Symbol, req.Side, req.Volume, req.Price, err.Error())
+		return err
+	}
+
+	notional := orderNotional(req, book)
+	e.reservations[req.ClientOrderID] = reservation{exchange: exchange, notional: notional, placedAt: e.now()}
+	e.exchangeNotional[exchange] += notional
+	return nil
+}
+
+// check выполняет проверки по порядку; вызывается под e.mu
+func (e *Engine) check(exchange string, req market.OrderRequest, book *market.UnifiedOrderBook) *RejectError {
+	l := e.limits
+
+	if e.halted {
+		return &RejectError{Reason: ReasonKillSwitch, Detail: e.haltReason}
+	}
+	if e.kill != nil {
+		if halted, reason := e.kill.IsHalted(); halted {
+			return &RejectError{Reason: ReasonKillSwitch, Detail: reason}
+		}
+	}
+
+	e.rollDay()
+	e.expireReservations()
+	if l.DailyLossLimit > 0 && e.dailyPnL <= -l.DailyLossLimit {
+		return &RejectError{Reason: ReasonDailyLoss,
+			Detail: fmt.Sprintf("daily pnl %.6f, limit -%.6f", e.dailyPnL, l.DailyLossLimit)}
+	}
+
+	if l.MaxOpenOrders > 0 && len(e.reservations) >= l.MaxOpenOrders {
+		return &RejectError{Reason: ReasonOpenOrders,
+			Detail: fmt.Sprintf("%d open orders, limit %d", len(e.reservations), l.MaxOpenOrders)}
+	}
+
+	needBook := l.MaxBookAge > 0 || l.PriceBandPercent > 0 || l.BBOOnly || req.Price <= 0
+	if needBook && e.books != nil {
+		if book == nil || len(book.Bids) == 0 || len(book.Asks) == 0 {
+			return &RejectError{Reason: ReasonNoBook, Detail: "no order book for " + req.Symbol}
+		}
+		if l.MaxBookAge > 0 {
+			age := e.now().Sub(book.Timestamp)
+			if book.Timestamp.IsZero() || age > l.MaxBookAge {
+				return &RejectError{Reason: ReasonStaleBook,
+					Detail: fmt.Sprintf("book age %s, limit %s", age.Round(time.Millisecond), l.MaxBookAge)}
+			}
+		}
+	}
+
+	notional := orderNotional(req, book)
+	if l.MaxOrderNotional > 0 && notional > l.MaxOrderNotional {
+		return &RejectError{Reason: ReasonOrderNotional,
+			Detail: fmt.Sprintf("notional %.6f, limit %.6f", notional, l.MaxOrderNotional)}
+	}
+	if l.MaxExchangeNotional > 0 && e.exchangeNotional[exchange]+notional > l.MaxExchangeNotional {
+		return &RejectError{Reason: ReasonExchangeNotional,
+			Detail: fmt.Sprintf("open %.6f + order %.6f, limit %.6f", e.exchangeNotional[exchange], notional, l.MaxExchangeNotional)}
+	}
+
+	if book == nil || req.Price <= 0 || len(book.Bids) == 0 || len(book.Asks) == 0 {
+		return nil
+	}
+	bestBid, bestAsk := book.Bids[0].Price, book.Asks[0].Price
+
+	if l.PriceBandPercent > 0 {
+		mid := (bestBid + bestAsk) / 2
+		if deviation := math.Abs(req.Price-mid) / mid * 100; deviation > l.PriceBandPercent {
+			return &RejectError{Reason: ReasonPriceBand,
+				Detail: fmt.Sprintf("price %.8f deviates %.4f%% from mid %.8f, limit %.4f%%", req.Price, deviation, mid, l.PriceBandPercent)}
+		}
+	}
+
+	if l.BBOOnly {
+		if req.Side == market.TradeSideBuy && req.Price > bestAsk {
+			return &RejectError{Reason: ReasonBBO,
+				Detail: fmt.Sprintf("buy price %.8f above best ask %.8f", req.Price, bestAsk)}
+		}
+		if req.Side == market.TradeSideSell && req.Price < bestBid {
+			return &RejectError{Reason: ReasonBBO,
+				Detail: fmt.Sprintf("sell price %.8f below best bid %.8f", req.Price, bestBid)}
+		}
+	}
+
+	return nil
+}
+
+// Release освобождает лимиты ордера: он исполнен, отменен или не был размещен (order == nil)
+func (e *Engine) Release(exchange string, req market.OrderRequest, order *market.Order) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.release(req.ClientOrderID)
+}
+
+// release снимает резерв ордера; вызывается под e.mu
+func (e *Engine) release(clientOrderID string) {
+	r, ok := e.reservations[clientOrderID]
+	if !ok {
+		return
+	}
+	delete(e.reservations, clientOrderID)
+	e.exchangeNotional[r.exchange] -= r.notional
+	if e.exchangeNotional[r.exchange] <= 1e-9 {
+		delete(e.exchangeNotional, r.exchange)
+	}
+}
+
+// expireReservations освобождает лимиты ордеров старше ReservationTTL - страховка от резерва,
+// для которого Release не был вызван (executor освобождает резерв по завершении каждой ноги);
+// вызывается под e.mu
+func (e *Engine) expireReservations() {
+	if e.limits.ReservationTTL <= 0 {
+		return
+	}
+	now := e.now()
+	for id, r := range e.reservations {
+		if age := now.Sub(r.placedAt); age > e.limits.ReservationTTL {
+			e.logger.Warn("[RISK] Reservation %s on %s (%.6f) expired after %s without final order status",
+				id, r.exchange, r.notional, age.Round(time.Second))
+			e.release(id)
+		}
+	}
+}
+
+// RecordPnL учитывает результат сделки в дневном PnL. При FIN_PROTECTION достижение
+// дневного лимита убытка включает kill switch
+func (e *Engine) RecordPnL(pnl float64) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.rollDay()
+	e.dailyPnL += pnl
+	if e.limits.DailyLossLimit <= 0 || e.dailyPnL > -e.limits.DailyLossLimit {
+		return
+	}
+	e.logger.Warn("[RISK] Daily loss limit reached: pnl %.6f, limit -%.6f", e.dailyPnL, e.limits.DailyLossLimit)
+	if e.limits.HaltOnDailyLoss && !e.halted {
+		e.halt(fmt.Sprintf("daily loss limit reached (%.6f)", e.dailyPnL))
+	}
+}
+
+// Halt включает kill switch: все новые ордера отклоняются до Resume
+func (e *Engine) Halt(reason string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.halt(reason)
+}
+
+func (e *Engine) halt(reason string) {
+	e.halted = true
+	e.haltReason = reason
+	e.logger.Error("[RISK] Trading halted: %s", reason)
+}
+
+// Resume выключает kill switch
+func (e *Engine) Resume() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.halted {
+		e.logger.Warn("[RISK] Trading resumed (was halted: %s)", e.haltReason)
+	}
+	e.halted = false
+	e.haltReason = ""
+}
+
+// IsHalted возвращает состояние kill switch (локального или глобального) и его причину
+func (e *Engine) IsHalted() (bool, string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if !e.halted && e.kill != nil {
+		return e.kill.IsHalted()
+	}
+	return e.halted, e.haltReason
+}
+
+// GetStats возвращает состояние риск-движка
+func (e *Engine) GetStats() map[string]interface{} {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.rollDay()
+	e.expireReservations()
+	exposure := make(map[string]float64, len(e.exchangeNotional))
+	for ex, v := range e.exchangeNotional {
+		exposure[ex] = v
+	}
+	rejects := make(map[string]int64, len(e.rejects))
+	for reason, n := range e.rejects {
+		rejects[reason] = n
+	}
+	halted, haltReason := e.halted, e.haltReason
+	if !halted && e.kill != nil {
+		halted, haltReason = e.kill.IsHalted()
+	}
+	return map[string]interface{}{
+		"halted":            halted,
+		"halt_reason":       haltReason,
+		"open_orders":       len(e.reservations),
+		"exchange_notional": exposure,
+		"daily_pnl":         e.dailyPnL,
+		"rejects":           rejects,
+	}
+}
+
+// rollDay обнуляет дневной PnL при смене суток (UTC); вызывается под e.mu
+func (e *Engine) rollDay() {
+	day := e.now().UTC().Truncate(24 * time.Hour)
+	if !day.Equal(e.day) {
+		e.day = day
+		e.dailyPnL = 0
+	}
+}
+
+// orderNotional оценивает стоимость ордера в quote валюте; для рыночного ордера
+// без цены используется mid стакана
+func orderNotional(req market.OrderRequest, book *market.UnifiedOrderBook) float64 {
+	price := req.Price
+	if price <= 0 && book != nil && len(book.Bids) > 0 && len(book.Asks) > 0 {
+		price = (book.Bids[0].Price + book.Asks[0].Price) / 2
+	}
+	return req.Volume * price
+}
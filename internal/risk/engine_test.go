@@ -0,0 +1,248 @@
+package risk
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"daemon-go/internal/db"
+	"daemon-go/internal/market"
+	"daemon-go/internal/worker/executor"
+)
+
+// staticBooks - источник стаканов теста
+type staticBooks map[string]*market.UnifiedOrderBook
+
+func (b staticBooks) OrderBook(exchange, symbol string) *market.UnifiedOrderBook {
+	return b[exchange+"|"+symbol]
+}
+
+func order(id string, side market.TradeSide, price, volume float64) market.OrderRequest {
+	return market.OrderRequest{
+		Symbol: "BTC/USDT", Side: side, OrderType: market.OrderTypeLimit,
+		Price: price, Volume: volume, ClientOrderID: id,
+	}
+}
+
+func rejectReason(t *testing.T, err error) string {
+	t.Helper()
+	if err == nil {
+		return ""
+	}
+	var reject *RejectError
+	if !errors.As(err, &reject) {
+		t.Fatalf("expected RejectError, got %v", err)
+	}
+	return reject.Reason
+}
+
+func TestEngineNotionalLimits(t *testing.T) {
+	e := NewEngine(Limits{MaxOrderNotional: 1000, MaxExchangeNotional: 1500}, nil)
+
+	if reason := rejectReason(t, e.Check("binance", order("big", market.TradeSideBuy, 100, 11))); reason != ReasonOrderNotional {
+		t.Fatalf("order above MaxOrderNotional: reason %q", reason)
+	}
+
+	first := order("a", market.TradeSideBuy, 100, 9)
+	if err := e.Check("binance", first); err != nil {
+		t.Fatalf("first order rejected: %v", err)
+	}
+	if reason := rejectReason(t, e.Check("binance", order("b", market.TradeSideBuy, 100, 7))); reason != ReasonExchangeNotional {
+		t.Fatalf("order above MaxExchangeNotional: reason %q", reason)
+	}
+	// Лимит биржи не распространяется на другие биржи
+	if err := e.Check("bybit", order("c", market.TradeSideBuy, 100, 7)); err != nil {
+		t.Fatalf("order on another exchange rejected: %v", err)
+	}
+
+	e.Release("binance", first, &market.Order{Status: market.OrderStatusFilled})
+	if err := e.Check("binance", order("b", market.TradeSideBuy, 100, 7)); err != nil {
+		t.Fatalf("order after release rejected: %v", err)
+	}
+}
+
+func TestEngineMaxOpenOrders(t *testing.T) {
+	e := NewEngine(Limits{MaxOpenOrders: 2}, nil)
+	for _, id := range []string{"a", "b"} {
+		if err := e.Check("binance", order(id, market.TradeSideBuy, 100, 1)); err != nil {
+			t.Fatalf("order %s rejected: %v", id, err)
+		}
+	}
+	if reason := rejectReason(t, e.Check("binance", order("c", market.TradeSideBuy, 100, 1))); reason != ReasonOpenOrders {
+		t.Fatalf("third order: reason %q", reason)
+	}
+	e.Release("binance", order("a", market.TradeSideBuy, 100, 1), nil)
+	if err := e.Check("binance", order("c", market.TradeSideBuy, 100, 1)); err != nil {
+		t.Fatalf("order after release rejected: %v", err)
+	}
+}
+
+func TestEngineBookChecks(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	books := staticBooks{"binance|BTC/USDT": {
+		Symbol:    "BTC/USDT",
+		Timestamp: now.Add(-time.Second),
+		Bids:      []market.PriceLevel{{Price: 99, Volume: 1}},
+		Asks:      []market.PriceLevel{{Price: 101, Volume: 1}},
+	}}
+	e := NewEngine(Limits{MaxBookAge: 5 * time.Second, PriceBandPercent: 2, BBOOnly: true}, books)
+	e.SetClock(func() time.Time { return now })
+
+	tests := []struct {
+		name     string
+		exchange string
+		req      market.OrderRequest
+		reason   string
+	}{
+		{"at best ask", "binance", order("1", market.TradeSideBuy, 101, 1), ""},
+		{"above best ask", "binance", order("2", market.TradeSideBuy, 101.5, 1), ReasonBBO},
+		{"below best bid", "binance", order("3", market.TradeSideSell, 98.5, 1), ReasonBBO},
+		{"outside band", "binance", order("4", market.TradeSideBuy, 97, 1), ReasonPriceBand},
+		{"no book", "bybit", order("5", market.TradeSideBuy, 100, 1), ReasonNoBook},
+	}
+	for _, tt := range tests {
+		if reason := rejectReason(t, e.Check(tt.exchange, tt.req)); reason != tt.reason {
+			t.Errorf("%s: reason %q, want %q", tt.name, reason, tt.reason)
+		}
+	}
+
+	now = now.Add(10 * time.Second)
+	if reason := rejectReason(t, e.Check("binance", order("6", market.TradeSideBuy, 100, 1))); reason != ReasonStaleBook {
+		t.Errorf("stale book: reason %q", reason)
+	}
+}
+
+func TestEngineMarketOrderUsesMid(t *testing.T) {
+	books := staticBooks{"binance|BTC/USDT": {
+		Timestamp: time.Now(),
+		Bids:      []market.PriceLevel{{Price: 99, Volume: 1}},
+		Asks:      []market.PriceLevel{{Price: 101, Volume: 1}},
+	}}
+	e := NewEngine(Limits{MaxOrderNotional: 150}, books)
+
+	req := order("m", market.TradeSideBuy, 0, 2)
+	req.OrderType = market.OrderTypeMarket
+	if reason := rejectReason(t, e.Check("binance", req)); reason != ReasonOrderNotional {
+		t.Fatalf("market order notional by mid: reason %q", reason)
+	}
+}
+
+func TestEngineDailyLossHalts(t *testing.T) {
+	e := NewEngine(Limits{DailyLossLimit: 100, HaltOnDailyLoss: true}, nil)
+
+	e.RecordPnL(-60)
+	if halted, _ := e.IsHalted(); halted {
+		t.Fatal("halted before the daily loss limit")
+	}
+	e.RecordPnL(-50)
+	if halted, _ := e.IsHalted(); !halted {
+		t.Fatal("not halted after the daily loss limit")
+	}
+	if reason := rejectReason(t, e.Check("binance", order("a", market.TradeSideBuy, 100, 1))); reason != ReasonKillSwitch {
+		t.Fatalf("order while halted: reason %q", reason)
+	}
+
+	e.Resume()
+	if reason := rejectReason(t, e.Check("binance", order("a", market.TradeSideBuy, 100, 1))); reason != ReasonDailyLoss {
+		t.Fatalf("order after resume with daily loss reached: reason %q", reason)
+	}
+}
+
+func TestEngineDailyLossResetsNextDay(t *testing.T) {
+	now := time.Date(2024, 1, 1, 23, 0, 0, 0, time.UTC)
+	e := NewEngine(Limits{DailyLossLimit: 100}, nil)
+	e.SetClock(func() time.Time { return now })
+
+	e.RecordPnL(-150)
+	if reason := rejectReason(t, e.Check("binance", order("a", market.TradeSideBuy, 100, 1))); reason != ReasonDailyLoss {
+		t.Fatalf("order with daily loss reached: reason %q", reason)
+	}
+	now = now.Add(2 * time.Hour)
+	if err := e.Check("binance", order("a", market.TradeSideBuy, 100, 1)); err != nil {
+		t.Fatalf("order on the next day rejected: %v", err)
+	}
+}
+
+func TestEngineReservationTTL(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	e := NewEngine(Limits{MaxExchangeNotional: 1000, ReservationTTL: time.Minute}, nil)
+	e.SetClock(func() time.Time { return now })
+
+	if err := e.Check("binance", order("stuck", market.TradeSideBuy, 100, 9)); err != nil {
+		t.Fatalf("first order rejected: %v", err)
+	}
+	if reason := rejectReason(t, e.Check("binance", order("b", market.TradeSideBuy, 100, 2))); reason != ReasonExchangeNotional {
+		t.Fatalf("order with reserved notional: reason %q", reason)
+	}
+
+	// Ордер без финального статуса освобождает лимиты по истечении TTL
+	now = now.Add(2 * time.Minute)
+	if err := e.Check("binance", order("b", market.TradeSideBuy, 100, 2)); err != nil {
+		t.Fatalf("order after reservation expiry rejected: %v", err)
+	}
+	if open := e.GetStats()["open_orders"]; open != 1 {
+		t.Fatalf("open orders %v, want 1", open)
+	}
+}
+
+// Общий движок считает открытые ордера всех воркеров, лимиты ордера остаются у движка воркера
+func TestSharedEngineAppliesGlobalLimitsAcrossWorkers(t *testing.T) {
+	limits := Limits{MaxOrderNotional: 1000, MaxExchangeNotional: 1500, MaxOpenOrders: 3, DailyLossLimit: 50}
+	shared := NewEngine(limits.Global(), nil)
+	first := executor.CheckChain{NewEngine(limits.PerTrade(), nil), shared}
+	second := executor.CheckChain{NewEngine(limits.PerTrade(), nil), shared}
+
+	if reason := rejectReason(t, first.Check("binance", order("big", market.TradeSideBuy, 100, 11))); reason != ReasonOrderNotional {
+		t.Fatalf("order above MaxOrderNotional: reason %q", reason)
+	}
+	a := order("a", market.TradeSideBuy, 100, 9)
+	if err := first.Check("binance", a); err != nil {
+		t.Fatalf("first worker order rejected: %v", err)
+	}
+	// Лимит биржи общий: второй воркер не может занять его повторно
+	if reason := rejectReason(t, second.Check("binance", order("b", market.TradeSideBuy, 100, 7))); reason != ReasonExchangeNotional {
+		t.Fatalf("second worker above MaxExchangeNotional: reason %q", reason)
+	}
+	if err := second.Check("bybit", order("c", market.TradeSideBuy, 100, 1)); err != nil {
+		t.Fatalf("second worker order on bybit rejected: %v", err)
+	}
+	if err := first.Check("bybit", order("d", market.TradeSideBuy, 100, 1)); err != nil {
+		t.Fatalf("third open order rejected: %v", err)
+	}
+	if reason := rejectReason(t, second.Check("bybit", order("e", market.TradeSideBuy, 100, 1))); reason != ReasonOpenOrders {
+		t.Fatalf("fourth open order across workers: reason %q", reason)
+	}
+
+	first.Release("binance", a, &market.Order{Status: market.OrderStatusFilled})
+	if err := second.Check("binance", order("b", market.TradeSideBuy, 100, 7)); err != nil {
+		t.Fatalf("order after release rejected: %v", err)
+	}
+
+	// Дневной убыток суммируется по воркерам
+	first.RecordPnL(-30)
+	second.RecordPnL(-25)
+	if reason := rejectReason(t, first.Check("kucoin", order("f", market.TradeSideBuy, 100, 1))); reason != ReasonDailyLoss {
+		t.Fatalf("order after combined daily loss: reason %q", reason)
+	}
+}
+
+func TestLimitsSplitGlobalAndPerTrade(t *testing.T) {
+	limits := Limits{MaxOrderNotional: 1000, MaxExchangeNotional: 1500, MaxOpenOrders: 3,
+		DailyLossLimit: 50, PriceBandPercent: 1, ReservationTTL: time.Minute}
+
+	global := limits.Global()
+	if global.MaxOrderNotional != 0 || global.PriceBandPercent != 0 ||
+		global.MaxExchangeNotional != 1500 || global.MaxOpenOrders != 3 || global.DailyLossLimit != 50 {
+		t.Fatalf("global limits %+v", global)
+	}
+	perTrade := limits.PerTrade()
+	if perTrade.MaxExchangeNotional != 0 || perTrade.MaxOpenOrders != 0 || perTrade.DailyLossLimit != 0 ||
+		perTrade.MaxOrderNotional != 1000 || perTrade.PriceBandPercent != 1 {
+		t.Fatalf("per-trade limits %+v", perTrade)
+	}
+	// FIN_PROTECTION: воркер останавливается по собственному дневному убытку
+	protected := limits.WithTrade(db.Trade{FinProtection: true}).PerTrade()
+	if protected.DailyLossLimit != 50 || !protected.HaltOnDailyLoss {
+		t.Fatalf("per-trade limits with FIN_PROTECTION %+v", protected)
+	}
+}
@@ -0,0 +1,228 @@
+package risk
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"daemon-go/internal/db"
+	"daemon-go/internal/exchange"
+	"daemon-go/internal/state"
+	"daemon-go/pkg/log"
+)
+
+// AccountSource возвращает аккаунты бирж, на которых kill switch отменяет ордера
+type AccountSource func() ([]exchange.TradingAccount, error)
+
+// AccountsFromDB - источник аккаунтов из EXCHANGE_ACCOUNTS
+func AccountsFromDB(driver db.DBDriver) AccountSource {
+	return func() ([]exchange.TradingAccount, error) {
+		return exchange.LoadTradingAccounts(driver)
+	}
+}
+
+// OpenSymbolSource возвращает символы открытых ордеров журнала по биржам: [exchange] -> символы.
+// По ним kill switch запрашивает открытые ордера на биржах, где символ обязателен (HTX)
+type OpenSymbolSource func() (map[string][]string, error)
+
+// AccountCancelResult - результат отмены ордеров на одном аккаунте
+type AccountCancelResult struct {
+	AccountID int      `json:"account_id"`
+	Exchange  string   `json:"exchange"`
+	Open      int      `json:"open"`     // найдено открытых ордеров
+	Canceled  int      `json:"canceled"` // успешно отменено
+	Errors    []string `json:"errors,omitempty"`
+}
+
+// CancelReport - итог отмены всех ордеров
+type CancelReport struct {
+	Accounts []AccountCancelResult `json:"accounts"`
+	Open     int                   `json:"open"`
+	Canceled int                   `json:"canceled"`
+	Failed   int                   `json:"failed"` // аккаунты с ошибками
+}
+
+// KillSwitch - глобальный аварийный останов торговли. Halt блокирует новые ордера во всех
+// подключенных риск-движках, отменяет открытые ордера на всех аккаунтах бирж и сохраняет
+// флаг в state-файле демона; торговля возобновляется только после Rearm
+type KillSwitch struct {
+	mu         sync.RWMutex
+	stateFile  string
+	accounts   AccountSource
+	symbols    OpenSymbolSource
+	newAdapter func(db.Exchange) (exchange.TradingAdapter, error)
+	logger     *log.Logger
+
+	halted   bool
+	reason   string
+	haltedAt time.Time
+}
+
+// NewKillSwitch создает kill switch и восстанавливает его состояние из state-файла
+func NewKillSwitch(stateFile string, accounts AccountSource) *KillSwitch {
+	st := state.LoadState(stateFile)
+	k := &KillSwitch{
+		stateFile:  stateFile,
+		accounts:   accounts,
+		newAdapter: exchange.NewTradingAdapter,
+		logger:     log.New("risk"),
+		halted:     st.Halted,
+		reason:     st.HaltReason,
+		haltedAt:   st.HaltedAt,
+	}
+	if k.halted {
+		k.logger.Warn("[KILL_SWITCH] Trading is halted since %s: %s (re-arm required)",
+			k.haltedAt.Format(time.RFC3339), k.reason)
+	}
+	return k
+}
+
+// SetOpenSymbols задает источник символов открытых ордеров (orders.DBJournal.OpenSymbols)
+func (k *KillSwitch) SetOpenSymbols(symbols OpenSymbolSource) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	k.symbols = symbols
+}
+
+// Halt включает kill switch и отменяет все открытые ордера.
+// Флаг сохраняется до отмены, чтобы перезапуск во время отмены не снял блокировку
+func (k *KillSwitch) Halt(reason string) CancelReport {
+	if reason == "" {
+		reason = "manual halt"
+	}
+
+	k.mu.Lock()
+	k.halted = true
+	k.reason = reason
+	st := state.SetHalted(k.stateFile, true, reason)
+	k.haltedAt = st.HaltedAt
+	k.mu.Unlock()
+
+	k.logger.Error("[KILL_SWITCH] Trading halted: %s", reason)
+	report := k.CancelAll()
+	k.logger.Warn("[KILL_SWITCH] Cancel-all finished: %d of %d open orders canceled, %d accounts with errors",
+		report.Canceled, report.Open, report.Failed)
+	return report
+}
+
+// Rearm выключает kill switch и разрешает новые ордера
+func (k *KillSwitch) Rearm() {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	if k.halted {
+		k.logger.Warn("[KILL_SWITCH] Trading re-armed (was halted: %s)", k.reason)
+	}
+	k.halted = false
+	k.reason = ""
+	k.haltedAt = time.Time{}
+	state.SetHalted(k.stateFile, false, "")
+}
+
+// IsHalted возвращает состояние kill switch и его причину
+func (k *KillSwitch) IsHalted() (bool, string) {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	return k.halted, k.reason
+}
+
+// GetStats возвращает состояние kill switch
+func (k *KillSwitch) GetStats() map[string]interface{} {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+
+	stats := map[string]interface{}{
+		"halted":      k.halted,
+		"halt_reason": k.reason,
+	}
+	if k.halted {
+		stats["halted_at"] = k.haltedAt.Unix()
+	}
+	return stats
+}
+
+// CancelAll отменяет открытые ордера на всех аккаунтах бирж параллельно
+func (k *KillSwitch) CancelAll() CancelReport {
+	var report CancelReport
+	if k.accounts == nil {
+		return report
+	}
+
+	accounts, err := k.accounts()
+	if err != nil {
+		k.logger.Error("[KILL_SWITCH] Failed to load exchange accounts: %v", err)
+		report.Failed = 1
+		report.Accounts = []AccountCancelResult{{Errors: []string{err.Error()}}}
+		return report
+	}
+
+	// Без журнала отменяются ордера бирж, возвращающих открытые ордера по всем символам
+	var symbols map[string][]string
+	k.mu.RLock()
+	source := k.symbols
+	k.mu.RUnlock()
+	if source != nil {
+		if symbols, err = source(); err != nil {
+			k.logger.Error("[KILL_SWITCH] Failed to load open order symbols from journal: %v", err)
+		}
+	}
+
+	results := make([]AccountCancelResult, len(accounts))
+	var wg sync.WaitGroup
+	for i, acc := range accounts {
+		wg.Add(1)
+		go func(i int, acc exchange.TradingAccount) {
+			defer wg.Done()
+			results[i] = k.cancelAccount(acc, symbols[strings.ToLower(acc.Exchange.Name)])
+		}(i, acc)
+	}
+	wg.Wait()
+
+	report.Accounts = results
+	for _, res := range results {
+		report.Open += res.Open
+		report.Canceled += res.Canceled
+		if len(res.Errors) > 0 {
+			report.Failed++
+		}
+	}
+	return report
+}
+
+// cancelAccount отменяет все открытые ордера одного аккаунта; symbols - символы открытых ордеров
+// биржи в журнале
+func (k *KillSwitch) cancelAccount(acc exchange.TradingAccount, symbols []string) AccountCancelResult {
+	res := AccountCancelResult{AccountID: acc.ID, Exchange: acc.Exchange.Name}
+	if acc.Exchange.ApiKey == "" {
+		return res
+	}
+
+	adapter, err := k.newAdapter(acc.Exchange)
+	if err != nil {
+		res.Errors = append(res.Errors, err.Error())
+		return res
+	}
+	// Ордера, найденные до ошибки запроса по одному из символов, все равно отменяются
+	orders, err := exchange.ListOpenOrders(adapter, symbols)
+	if err != nil {
+		k.logger.Error("[KILL_SWITCH] %s account %d: get open orders failed: %v", acc.Exchange.Name, acc.ID, err)
+		res.Errors = append(res.Errors, fmt.Sprintf("get open orders: %v", err))
+	}
+
+	res.Open = len(orders)
+	for _, order := range orders {
+		if err := adapter.CancelOrder(order.Symbol, order.OrderID); err != nil {
+			k.logger.Error("[KILL_SWITCH] %s account %d: cancel %s %s failed: %v",
+				acc.Exchange.Name, acc.ID, order.Symbol, order.OrderID, err)
+			res.Errors = append(res.Errors, fmt.Sprintf("cancel %s %s: %v", order.Symbol, order.OrderID, err))
+			continue
+		}
+		res.Canceled++
+	}
+	if res.Open > 0 {
+		k.logger.Info("[KILL_SWITCH] %s account %d: canceled %d of %d open orders",
+			acc.Exchange.Name, acc.ID, res.Canceled, res.Open)
+	}
+	return res
+}
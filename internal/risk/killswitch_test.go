@@ -0,0 +1,125 @@
+package risk
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"sync"
+	"testing"
+
+	"daemon-go/internal/db"
+	"daemon-go/internal/exchange"
+	"daemon-go/internal/market"
+	"daemon-go/internal/state"
+)
+
+// accountAdapter - аккаунт биржи теста: открытые ордера по символам и отмененные ордера
+type accountAdapter struct {
+	exchange.TradingAdapter
+	mu            sync.Mutex
+	symbolOnly    bool // GetOpenOrders("") -> ErrSymbolRequired, как HTX
+	open          map[string][]string
+	canceled      []string
+	listedSymbols []string
+}
+
+func (a *accountAdapter) GetOpenOrders(symbol string) ([]market.Order, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.listedSymbols = append(a.listedSymbols, symbol)
+	if symbol == "" && a.symbolOnly {
+		return nil, fmt.Errorf("open orders: %w", exchange.ErrSymbolRequired)
+	}
+	var orders []market.Order
+	for s, ids := range a.open {
+		if symbol != "" && s != symbol {
+			continue
+		}
+		for _, id := range ids {
+			orders = append(orders, market.Order{Symbol: s, OrderID: id})
+		}
+	}
+	return orders, nil
+}
+
+func (a *accountAdapter) CancelOrder(symbol, orderID string) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.canceled = append(a.canceled, symbol+":"+orderID)
+	return nil
+}
+
+// Биржи, где символ обязателен, опрашиваются по символам открытых ордеров журнала
+func TestKillSwitchCancelAll(t *testing.T) {
+	tests := []struct {
+		name         string
+		symbolOnly   bool
+		journal      map[string][]string
+		wantCanceled []string
+	}{
+		{
+			name:         "all symbols in one request",
+			journal:      nil,
+			wantCanceled: []string{"BTC/USDT:1", "BTC/USDT:2", "ETH/USDT:3"},
+		},
+		{
+			name:         "symbol required, journal symbols",
+			symbolOnly:   true,
+			journal:      map[string][]string{"htx": {"BTC/USDT", "ETH/USDT", "BTC/USDT"}, "binance": {"XRP/USDT"}},
+			wantCanceled: []string{"BTC/USDT:1", "BTC/USDT:2", "ETH/USDT:3"},
+		},
+		{
+			name:         "symbol required, no journal",
+			symbolOnly:   true,
+			wantCanceled: nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			adapter := &accountAdapter{
+				symbolOnly: tt.symbolOnly,
+				open:       map[string][]string{"BTC/USDT": {"1", "2"}, "ETH/USDT": {"3"}},
+			}
+			accounts := func() ([]exchange.TradingAccount, error) {
+				return []exchange.TradingAccount{{ID: 1, Exchange: db.Exchange{Name: "HTX", ApiKey: "key"}}}, nil
+			}
+			k := NewKillSwitch(filepath.Join(t.TempDir(), "state.json"), accounts)
+			k.newAdapter = func(db.Exchange) (exchange.TradingAdapter, error) { return adapter, nil }
+			if tt.journal != nil {
+				k.SetOpenSymbols(func() (map[string][]string, error) { return tt.journal, nil })
+			}
+
+			report := k.CancelAll()
+
+			sort.Strings(adapter.canceled)
+			if fmt.Sprint(adapter.canceled) != fmt.Sprint(tt.wantCanceled) {
+				t.Fatalf("canceled %v, want %v", adapter.canceled, tt.wantCanceled)
+			}
+			if report.Canceled != len(tt.wantCanceled) || report.Open != len(tt.wantCanceled) || report.Failed != 0 {
+				t.Fatalf("report %+v", report)
+			}
+			if tt.symbolOnly && tt.journal != nil && len(adapter.listedSymbols) != 3 {
+				t.Fatalf("listed %q, want all-symbols request and one request per journal symbol", adapter.listedSymbols)
+			}
+		})
+	}
+}
+
+// Флаг kill switch сохраняется, даже если директории state-файла еще нет, и переживает перезапуск
+func TestKillSwitchHaltPersists(t *testing.T) {
+	stateFile := filepath.Join(t.TempDir(), "run", "state.json")
+
+	NewKillSwitch(stateFile, nil).Halt("test")
+
+	if st := state.LoadState(stateFile); !st.Halted || st.HaltReason != "test" {
+		t.Fatalf("state %+v, want halted", st)
+	}
+	k := NewKillSwitch(stateFile, nil)
+	if halted, reason := k.IsHalted(); !halted || reason != "test" {
+		t.Fatalf("restored halted=%v reason=%q", halted, reason)
+	}
+	k.Rearm()
+	if st := state.LoadState(stateFile); st.Halted {
+		t.Fatalf("state %+v after rearm", st)
+	}
+}
@@ -0,0 +1,74 @@
+package risk
+
+import (
+	"time"
+
+	"daemon-go/internal/config"
+	"daemon-go/internal/db"
+)
+
+// Limits - лимиты риск-менеджмента. Нулевое значение лимита означает отсутствие ограничения
+type Limits struct {
+	MaxOrderNotional    float64       // максимальная стоимость одного ордера в quote валюте
+	MaxExchangeNotional float64       // максимальная стоимость открытых ордеров на одной бирже
+	DailyLossLimit      float64       // дневной лимит убытка (положительное число)
+	MaxOpenOrders       int           // максимальное количество одновременно открытых ордеров
+	MaxBookAge          time.Duration // максимальный возраст стакана на момент проверки
+	PriceBandPercent    float64       // допустимое отклонение цены ордера от mid, %
+	BBOOnly             bool          // цена ордера не глубже лучших цен стакана
+	HaltOnDailyLoss     bool          // при достижении дневного лимита убытка включать kill switch
+	ReservationTTL      time.Duration // лимиты ордера без Release освобождаются через (0 - не освобождаются)
+}
+
+// LimitsFromConfig строит лимиты из секции [risk] конфига
+func LimitsFromConfig(cfg *config.Config) Limits {
+	return Limits{
+		MaxOrderNotional:    cfg.Risk.MaxOrderNotional,
+		MaxExchangeNotional: cfg.Risk.MaxExchangeNotional,
+		DailyLossLimit:      cfg.Risk.DailyLossLimit,
+		MaxOpenOrders:       cfg.Risk.MaxOpenOrders,
+		MaxBookAge:          time.Duration(cfg.Risk.MaxBookAgeMs) * time.Millisecond,
+		PriceBandPercent:    cfg.Risk.PriceBandPercent,
+		BBOOnly:             cfg.Risk.BBOOnly,
+		ReservationTTL:      time.Duration(cfg.Risk.ReservationTTLSec) * time.Second,
+	}
+}
+
+// WithTrade ужесточает лимиты настройками торговли из таблицы TRADE:
+// MAX_AMOUNT_TRADE ограничивает стоимость ордера, BBO_ONLY запрещает цены глубже
+// лучших, FIN_PROTECTION останавливает торговлю при достижении дневного лимита убытка
+func (l Limits) WithTrade(trade db.Trade) Limits {
+	if trade.MaxAmountTrade > 0 && (l.MaxOrderNotional <= 0 || trade.MaxAmountTrade < l.MaxOrderNotional) {
+		l.MaxOrderNotional = trade.MaxAmountTrade
+	}
+	if trade.BBOOnly {
+		l.BBOOnly = true
+	}
+	if trade.FinProtection {
+		l.HaltOnDailyLoss = true
+	}
+	return l
+}
+
+// Global возвращает лимиты общего риск-движка Manager: открытые ордера и их стоимость на бирже
+// и дневной убыток считаются по всем торговым воркерам аккаунтов вместе
+func (l Limits) Global() Limits {
+	return Limits{
+		MaxExchangeNotional: l.MaxExchangeNotional,
+		DailyLossLimit:      l.DailyLossLimit,
+		MaxOpenOrders:       l.MaxOpenOrders,
+		ReservationTTL:      l.ReservationTTL,
+	}
+}
+
+// PerTrade возвращает лимиты риск-движка торгового воркера, проверяемые поверх общего движка:
+// стоимость ордера и проверки цены по стакану. Дневной лимит убытка остается только при
+// FIN_PROTECTION (HaltOnDailyLoss): воркер останавливается по собственному убытку за сутки
+func (l Limits) PerTrade() Limits {
+	l.MaxExchangeNotional = 0
+	l.MaxOpenOrders = 0
+	if !l.HaltOnDailyLoss {
+		l.DailyLossLimit = 0
+	}
+	return l
+}
@@ -5,7 +5,9 @@ import (
 	"sync"
 	"time"
 
+	"daemon-go/internal/catalog"
 	"daemon-go/internal/db"
+	"daemon-go/internal/rebalance"
 	"daemon-go/pkg/log"
 )
 
@@ -14,6 +16,10 @@ type Daemon struct {
 	DB     db.DBDriver
 	logger *log.Logger
 	mu     sync.Mutex
+	// rebalancer - планировщик переводов между биржами (nil - отключен)
+	rebalancer *rebalance.Planner
+	// catalog - синхронизация справочника символов бирж (nil - отключена)
+	catalog *catalog.Syncer
 	// Здесь можно добавить дополнительные поля для состояния сервисных воркеров
 	// например: collectors, executors, monitors
 }
@@ -26,6 +32,34 @@ func NewDaemon(driver db.DBDriver) *Daemon {
 	}
 }
 
+// SetRebalancer задает планировщик переводов, запускаемый в периодических задачах
+func (d *Daemon) SetRebalancer(planner *rebalance.Planner) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.rebalancer = planner
+}
+
+// Rebalancer возвращает планировщик переводов (nil, если не задан)
+func (d *Daemon) Rebalancer() *rebalance.Planner {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.rebalancer
+}
+
+// SetCatalogSync задает синхронизацию справочника символов, запускаемую в периодических задачах
+func (d *Daemon) SetCatalogSync(syncer *catalog.Syncer) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.catalog = syncer
+}
+
+// CatalogSync возвращает синхронизацию справочника символов (nil, если не задана)
+func (d *Daemon) CatalogSync() *catalog.Syncer {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.catalog
+}
+
 // Run запускает сервисные задачи и завершает работу при получении ctx.Done()
 func (d *Daemon) Run(ctx context.Context) {
 	d.logger.Info("Service Daemon running")
@@ -56,6 +90,20 @@ func (d *Daemon) performTasks() {
 	// - очистка устаревших данных в кэше
 	// - проверка состояния подключений к биржам
 	// Реализацию можно дополнять по мере необходимости
+
+	// Синхронизация списков символов бирж (не чаще интервала синхронизации)
+	if d.catalog != nil {
+		if _, err := d.catalog.Run(); err != nil {
+			d.logger.Error("Catalog sync failed: %v", err)
+		}
+	}
+
+	// Планирование переводов между биржами (не чаще интервала планировщика)
+	if d.rebalancer != nil {
+		if _, err := d.rebalancer.Run(); err != nil {
+			d.logger.Error("Rebalance planning failed: %v", err)
+		}
+	}
 }
 
 // cleanup выполняет корректное завершение сервисных воркеров
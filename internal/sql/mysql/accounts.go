@@ -0,0 +1,24 @@
+package mysql
+
+// TradingAccounts возвращает аккаунты бирж с ключами API, включая неактивные
+// (kill switch должен отменить ордера на всех аккаунтах)
+const TradingAccounts = `
+SELECT
+    ea.ID AS EAID,
+    e.ID AS EXID,
+    LOWER(e.NAME) AS EXCHANGE_NAME,
+    e.URL,
+    e.BASE_URL,
+    ea.API_KEY,
+    ea.SECRET_KEY,
+    COALESCE(ea.PASSPHRASE, '') AS PASSPHRASE,
+    ea.ACTIVE
+FROM
+    EXCHANGE_ACCOUNTS ea
+INNER JOIN
+    EXCHANGE e
+        ON e.ID = ea.EXID
+WHERE
+    e.DELETED = 0
+ORDER BY
+    ea.ID ASC`
@@ -0,0 +1,40 @@
+package mysql
+
+// BacktestPairs возвращает биржу и символ для списка PAIR_ID; %s - плейсхолдеры IN (?,?,...)
+const BacktestPairs = `
+SELECT
+    stp.ID,
+    LOWER(e.NAME) AS EXCHANGE_NAME,
+    CONCAT(c1.SYMBOL, '/', c2.SYMBOL) AS SYMBOL
+FROM
+    SPOT_TRADE_PAIR stp
+INNER JOIN
+    EXCHANGE e
+        ON e.ID = stp.EXCHANGE_ID
+INNER JOIN
+    COIN c1
+        ON stp.BASE_CURRENCY_ID = c1.ID
+INNER JOIN
+    COIN c2
+        ON stp.QUOTE_CURRENCY_ID = c2.ID
+WHERE
+    stp.ID IN (%s)`
+
+// BacktestPriceLog выбирает снимки PRICE_SPOT_LOG за период [from, to) по списку пар;
+// первые два параметра - from и to, %s - плейсхолдеры IN (?,?,...)
+const BacktestPriceLog = `
+SELECT
+    PAIR_ID,
+    PRICE_TIMESTAMP,
+    ASKS1_PRICE, ASKS1_VOLUME, ASKS2_PRICE, ASKS2_VOLUME, ASKS3_PRICE, ASKS3_VOLUME,
+    ASKS4_PRICE, ASKS4_VOLUME, ASKS5_PRICE, ASKS5_VOLUME,
+    BIDS1_PRICE, BIDS1_VOLUME, BIDS2_PRICE, BIDS2_VOLUME, BIDS3_PRICE, BIDS3_VOLUME,
+    BIDS4_PRICE, BIDS4_VOLUME, BIDS5_PRICE, BIDS5_VOLUME
+FROM
+    PRICE_SPOT_LOG
+WHERE
+    PRICE_TIMESTAMP >= ?
+    AND PRICE_TIMESTAMP < ?
+    AND PAIR_ID IN (%s)
+ORDER BY
+    PRICE_TIMESTAMP ASC, PAIR_ID ASC`
@@ -0,0 +1,22 @@
+package mysql
+
+// UpsertSpotKline сохраняет свечу в PRICE_SPOT_KLINE. Ключ - (PAIR_ID, KLINE_INTERVAL, OPEN_TIME):
+// повторная запись той же свечи обновляет цены и объемы
+const UpsertSpotKline = `
+			INSERT INTO PRICE_SPOT_KLINE (
+				DATE,
+				OPEN_TIME,
+				CLOSE_TIME,
+				PAIR_ID,
+				KLINE_INTERVAL,
+				OPEN_PRICE, HIGH_PRICE, LOW_PRICE, CLOSE_PRICE,
+				VOLUME, QUOTE_VOLUME, TRADES, SOURCE
+			) VALUES(?,?,?,?,?,?,?,?,?,?,?,?,?)
+			ON DUPLICATE KEY UPDATE
+				HIGH_PRICE = VALUES(HIGH_PRICE),
+				LOW_PRICE = VALUES(LOW_PRICE),
+				CLOSE_PRICE = VALUES(CLOSE_PRICE),
+				VOLUME = VALUES(VOLUME),
+				QUOTE_VOLUME = VALUES(QUOTE_VOLUME),
+				TRADES = VALUES(TRADES),
+				SOURCE = VALUES(SOURCE)`
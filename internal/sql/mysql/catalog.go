@@ -0,0 +1,49 @@
+package mysql
+
+// CatalogExchanges возвращает активные биржи для синхронизации справочника символов
+const CatalogExchanges = `
+SELECT
+    ID,
+    LOWER(NAME) AS EXCHANGE_NAME,
+    URL,
+    BASE_URL
+FROM
+    EXCHANGE
+WHERE
+    ACTIVE = 1
+    AND DELETED = 0
+ORDER BY
+    ID ASC`
+
+// CatalogCoins возвращает все монеты справочника
+const CatalogCoins = "SELECT ID, UPPER(SYMBOL) FROM COIN"
+
+// InsertCoin добавляет монету в справочник
+const InsertCoin = "INSERT INTO COIN (SYMBOL) VALUES (?)"
+
+// CatalogSpotPairs возвращает спотовые пары биржи с символами монет
+const CatalogSpotPairs = `
+SELECT
+    stp.ID,
+    UPPER(c1.SYMBOL) AS BASE_SYMBOL,
+    UPPER(c2.SYMBOL) AS QUOTE_SYMBOL,
+    stp.ACTIVE
+FROM
+    SPOT_TRADE_PAIR stp
+INNER JOIN
+    COIN c1
+        ON stp.BASE_CURRENCY_ID = c1.ID
+INNER JOIN
+    COIN c2
+        ON stp.QUOTE_CURRENCY_ID = c2.ID
+WHERE
+    stp.EXCHANGE_ID = ?`
+
+// InsertSpotPair добавляет спотовую пару биржи
+const InsertSpotPair = `
+INSERT INTO SPOT_TRADE_PAIR (
+    EXCHANGE_ID, BASE_CURRENCY_ID, QUOTE_CURRENCY_ID, ACTIVE
+) VALUES (?, ?, ?, ?)`
+
+// UpdateSpotPairActive включает или выключает спотовую пару
+const UpdateSpotPairActive = "UPDATE SPOT_TRADE_PAIR SET ACTIVE = ? WHERE ID = ?"
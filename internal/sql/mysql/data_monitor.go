@@ -1,6 +1,7 @@
 package mysql
 
-// DataMonitorPairs содержит SQL-запрос для получения пар (EXCHANGE_ID, PAIR_ID, SYMBOL, MARKET_TYPE) для DataMonitor
+// DataMonitorPairs содержит SQL-запрос для получения пар (EXCHANGE_ID, PAIR_ID, SYMBOL, MARKET_TYPE) для DataMonitor:
+// спотовые пары торговли и мониторинга (SPOT, символ BTC/USDT, PAIR_ID из SPOT_TRADE_PAIR)
 const DataMonitorPairs = `
 SELECT 
     stp.EXCHANGE_ID,
@@ -52,4 +53,43 @@ WHERE
 	stp.ACTIVE = 1
 	AND e2.ACTIVE = 1
 ORDER BY 
-	stp.EXCHANGE_ID ASC`
+	EXCHANGE_ID ASC`
+
+// DataMonitorFuturesPairs содержит SQL-запрос бессрочных контрактов мониторинга для DataMonitor
+// (FUTURES, символ BTCUSDT, PAIR_ID из FUTURES_TRADE_PAIR). Выполняется отдельно от DataMonitorPairs:
+// в базах без FUTURES_TRADE_PAIR/MONITORING_FUTURES_ARRAYS он завершается ошибкой, а спот работает
+const DataMonitorFuturesPairs = `
+SELECT 
+    ftp.EXCHANGE_ID,
+    LOWER(e3.NAME) AS EXCHANGE_NAME,
+    ftp.ID AS PAIR_ID,
+    CONCAT(c3.SYMBOL, c4.SYMBOL) AS SYMBOL,
+    'FUTURES' AS MARKET_TYPE
+FROM (
+    SELECT DISTINCT
+		mfa.PAIR_ID
+    FROM 
+		MONITORING m
+    INNER JOIN 
+		MONITORING_FUTURES_ARRAYS mfa 
+			ON m.ID = mfa.MONITOR_ID
+    WHERE 
+		m.ACTIVE = 1
+) f
+INNER JOIN 
+	FUTURES_TRADE_PAIR ftp 
+		ON f.PAIR_ID = ftp.ID
+INNER JOIN 
+	EXCHANGE e3 
+		ON e3.ID = ftp.EXCHANGE_ID
+INNER JOIN 
+	COIN c3 
+		ON ftp.BASE_CURRENCY_ID = c3.ID 
+INNER JOIN 
+	COIN c4 
+		ON ftp.QUOTE_CURRENCY_ID = c4.ID
+WHERE 
+	ftp.ACTIVE = 1
+	AND e3.ACTIVE = 1
+ORDER BY 
+	EXCHANGE_ID ASC`
@@ -0,0 +1,31 @@
+package mysql
+
+// ExchangeFees возвращает комиссии активных аккаунтов бирж из EXCHANGE_FEE.
+// Строка без PAIR_ID задает комиссию аккаунта по умолчанию, SYMBOL для нее пустой
+const ExchangeFees = `
+SELECT
+    LOWER(e.NAME) AS EXCHANGE_NAME,
+    ea.ID AS EAID,
+    CASE WHEN ef.PAIR_ID IS NULL THEN '' ELSE CONCAT(c1.SYMBOL, '/', c2.SYMBOL) END AS SYMBOL,
+    ef.MAKER_FEE,
+    ef.TAKER_FEE
+FROM
+    EXCHANGE_FEE ef
+INNER JOIN
+    EXCHANGE_ACCOUNTS ea
+        ON ea.ID = ef.EAID
+INNER JOIN
+    EXCHANGE e
+        ON e.ID = ea.EXID
+LEFT JOIN
+    SPOT_TRADE_PAIR stp
+        ON stp.ID = ef.PAIR_ID
+LEFT JOIN
+    COIN c1
+        ON stp.BASE_CURRENCY_ID = c1.ID
+LEFT JOIN
+    COIN c2
+        ON stp.QUOTE_CURRENCY_ID = c2.ID
+WHERE
+    ea.ACTIVE = 1
+    AND e.ACTIVE = 1`
@@ -0,0 +1,18 @@
+package mysql
+
+// UpsertFundingRate сохраняет расчет ставки финансирования в FUNDING_RATE_HISTORY. Ключ -
+// (PAIR_ID, FUNDING_TIME): повторная запись того же расчета обновляет ставку и цены
+const UpsertFundingRate = `
+			INSERT INTO FUNDING_RATE_HISTORY (
+				PAIR_ID,
+				FUNDING_TIME,
+				FUNDING_RATE,
+				PREDICTED_RATE,
+				MARK_PRICE,
+				INDEX_PRICE
+			) VALUES(?,?,?,?,?,?)
+			ON DUPLICATE KEY UPDATE
+				FUNDING_RATE = VALUES(FUNDING_RATE),
+				PREDICTED_RATE = VALUES(PREDICTED_RATE),
+				MARK_PRICE = VALUES(MARK_PRICE),
+				INDEX_PRICE = VALUES(INDEX_PRICE)`
@@ -0,0 +1,120 @@
+package mysql
+
+// UpsertOrder сохраняет состояние ордера в журнал ORDER. Ключ идемпотентности -
+// (EXCHANGE, CLIENT_ORDER_ID): повторная запись того же ордера обновляет строку
+const UpsertOrder = "INSERT INTO `ORDER` (" + `
+    EXCHANGE, CLIENT_ORDER_ID, EXTERNAL_ORDER_ID, SYMBOL, SIDE, ORDER_TYPE,
+    PRICE, AMOUNT, FILLED, AVG_PRICE, FEE, FEE_CURRENCY, STATUS, ERROR,
+    CREATED_AT, UPDATED_AT
+) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+ON DUPLICATE KEY UPDATE
+    EXTERNAL_ORDER_ID = VALUES(EXTERNAL_ORDER_ID),
+    FILLED = VALUES(FILLED),
+    AVG_PRICE = VALUES(AVG_PRICE),
+    FEE = VALUES(FEE),
+    FEE_CURRENCY = VALUES(FEE_CURRENCY),
+    STATUS = VALUES(STATUS),
+    ERROR = VALUES(ERROR),
+    UPDATED_AT = VALUES(UPDATED_AT)`
+
+// InsertFill добавляет исполнение ордера в FILL; повтор того же TRADE_ID на бирже игнорируется.
+// Параметры: exchange, trade_id, price, amount, fee, fee_currency, filled_at, exchange, client_order_id
+const InsertFill = `
+INSERT IGNORE INTO FILL (
+    ORDER_ID, EXCHANGE, TRADE_ID, PRICE, AMOUNT, FEE, FEE_CURRENCY, FILLED_AT
+)
+SELECT
+    o.ID, ?, ?, ?, ?, ?, ?, ?
+FROM
+    ` + "`ORDER`" + ` o
+WHERE
+    o.EXCHANGE = ?
+    AND o.CLIENT_ORDER_ID = ?`
+
+// OpenOrders возвращает ордера журнала в нефинальных статусах для сверки с биржами
+const OpenOrders = `
+SELECT
+    EXCHANGE,
+    CLIENT_ORDER_ID,
+    COALESCE(EXTERNAL_ORDER_ID, '') AS EXTERNAL_ORDER_ID,
+    SYMBOL,
+    SIDE,
+    ORDER_TYPE,
+    PRICE,
+    AMOUNT,
+    FILLED,
+    AVG_PRICE,
+    FEE,
+    COALESCE(FEE_CURRENCY, '') AS FEE_CURRENCY,
+    STATUS,
+    CREATED_AT,
+    UPDATED_AT
+FROM
+    ` + "`ORDER`" + `
+WHERE
+    STATUS IN ('new', 'partially_filled')
+ORDER BY
+    ID ASC`
+
+// InsertLegImbalance сохраняет событие дисбаланса ног сделки; DETAILS - хеджирующие ордера в JSON
+const InsertLegImbalance = `
+INSERT INTO LEG_IMBALANCE (
+    TASK_ID, SYMBOL, IMBALANCE, HEDGE_SIDE, HEDGED, RESIDUAL, RESOLVED, DETAILS,
+    DETECTED_AT, FINISHED_AT
+) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+
+// UpsertCarryPosition сохраняет состояние позиции cash-and-carry в CARRY_POSITION. Ключ - POSITION_ID;
+// SPREAD_PNL (исполнения ног) и CARRY_PNL (финансирование) ведутся раздельно,
+// LAST_FUNDING_AT - последний учтенный расчет финансирования биржи
+const UpsertCarryPosition = `
+INSERT INTO CARRY_POSITION (
+    POSITION_ID, SYMBOL, PERP_SYMBOL, SPOT_EXCHANGE, PERP_EXCHANGE, VOLUME, CLOSED_VOLUME,
+    SPOT_ENTRY_PRICE, PERP_ENTRY_PRICE, SPOT_EXIT_PRICE, PERP_EXIT_PRICE, SPREAD_PNL, CARRY_PNL,
+    FUNDING_EVENTS, FUNDING_RATE, NEXT_FUNDING_TIME, LAST_FUNDING_AT, STATUS, OPENED_AT, CLOSED_AT,
+    UPDATED_AT
+) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+ON DUPLICATE KEY UPDATE
+    VOLUME = VALUES(VOLUME),
+    CLOSED_VOLUME = VALUES(CLOSED_VOLUME),
+    SPOT_ENTRY_PRICE = VALUES(SPOT_ENTRY_PRICE),
+    PERP_ENTRY_PRICE = VALUES(PERP_ENTRY_PRICE),
+    SPOT_EXIT_PRICE = VALUES(SPOT_EXIT_PRICE),
+    PERP_EXIT_PRICE = VALUES(PERP_EXIT_PRICE),
+    SPREAD_PNL = VALUES(SPREAD_PNL),
+    CARRY_PNL = VALUES(CARRY_PNL),
+    FUNDING_EVENTS = VALUES(FUNDING_EVENTS),
+    FUNDING_RATE = VALUES(FUNDING_RATE),
+    NEXT_FUNDING_TIME = VALUES(NEXT_FUNDING_TIME),
+    LAST_FUNDING_AT = VALUES(LAST_FUNDING_AT),
+    STATUS = VALUES(STATUS),
+    CLOSED_AT = VALUES(CLOSED_AT),
+    UPDATED_AT = VALUES(UPDATED_AT)`
+
+// OpenCarryPositions возвращает открытые позиции cash-and-carry для восстановления стратегии
+const OpenCarryPositions = `
+SELECT
+    POSITION_ID,
+    SYMBOL,
+    PERP_SYMBOL,
+    SPOT_EXCHANGE,
+    PERP_EXCHANGE,
+    VOLUME,
+    CLOSED_VOLUME,
+    SPOT_ENTRY_PRICE,
+    PERP_ENTRY_PRICE,
+    SPOT_EXIT_PRICE,
+    PERP_EXIT_PRICE,
+    SPREAD_PNL,
+    CARRY_PNL,
+    FUNDING_EVENTS,
+    FUNDING_RATE,
+    NEXT_FUNDING_TIME,
+    LAST_FUNDING_AT,
+    OPENED_AT,
+    UPDATED_AT
+FROM
+    CARRY_POSITION
+WHERE
+    STATUS = 'open'
+ORDER BY
+    ID ASC`
@@ -0,0 +1,22 @@
+package mysql
+
+// PendingTransfers возвращает переводы, еще не обработанные оператором
+const PendingTransfers = `
+SELECT
+    ASSET,
+    FROM_EXCHANGE,
+    TO_EXCHANGE,
+    AMOUNT,
+    NETWORK_FEE,
+    REASON,
+    CREATED_AT
+FROM
+    TRANSFER_PLAN
+WHERE
+    STATUS IN ('proposed', 'approved')`
+
+// InsertTransfer добавляет предложенный перевод между биржами в статусе proposed
+const InsertTransfer = `
+INSERT INTO TRANSFER_PLAN (
+    ASSET, FROM_EXCHANGE, TO_EXCHANGE, AMOUNT, NETWORK_FEE, REASON, STATUS, CREATED_AT
+) VALUES (?, ?, ?, ?, ?, ?, 'proposed', ?)`
@@ -0,0 +1,24 @@
+package postgres
+
+// TradingAccounts возвращает аккаунты бирж с ключами API, включая неактивные
+// (kill switch должен отменить ордера на всех аккаунтах)
+const TradingAccounts = `
+SELECT
+    ea.id AS eaid,
+    e.id AS exid,
+    LOWER(e.name) AS exchange_name,
+    e.url,
+    e.base_url,
+    ea.api_key,
+    ea.secret_key,
+    COALESCE(ea.passphrase, '') AS passphrase,
+    ea.active
+FROM
+    exchange_accounts ea
+INNER JOIN
+    exchange e
+        ON e.id = ea.exid
+WHERE
+    e.deleted = false
+ORDER BY
+    ea.id ASC`
@@ -0,0 +1,40 @@
+package postgres
+
+// BacktestPairs возвращает биржу и символ для списка pair_id; %s - плейсхолдеры IN ($1,$2,...)
+const BacktestPairs = `
+SELECT
+    stp.id,
+    LOWER(e.name) AS exchange_name,
+    c1.symbol || '/' || c2.symbol AS symbol
+FROM
+    spot_trade_pair stp
+INNER JOIN
+    exchange e
+        ON e.id = stp.exchange_id
+INNER JOIN
+    coin c1
+        ON stp.base_currency_id = c1.id
+INNER JOIN
+    coin c2
+        ON stp.quote_currency_id = c2.id
+WHERE
+    stp.id IN (%s)`
+
+// BacktestPriceLog выбирает снимки price_spot_log за период [from, to) по списку пар;
+// $1 и $2 - from и to, %s - плейсхолдеры IN ($3,$4,...)
+const BacktestPriceLog = `
+SELECT
+    pair_id,
+    price_timestamp,
+    asks1_price, asks1_volume, asks2_price, asks2_volume, asks3_price, asks3_volume,
+    asks4_price, asks4_volume, asks5_price, asks5_volume,
+    bids1_price, bids1_volume, bids2_price, bids2_volume, bids3_price, bids3_volume,
+    bids4_price, bids4_volume, bids5_price, bids5_volume
+FROM
+    price_spot_log
+WHERE
+    price_timestamp >= $1
+    AND price_timestamp < $2
+    AND pair_id IN (%s)
+ORDER BY
+    price_timestamp ASC, pair_id ASC`
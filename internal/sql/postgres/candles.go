@@ -0,0 +1,22 @@
+package postgres
+
+// UpsertSpotKline сохраняет свечу в price_spot_kline. Ключ - (pair_id, kline_interval, open_time):
+// повторная запись той же свечи обновляет цены и объемы
+const UpsertSpotKline = `
+			INSERT INTO price_spot_kline (
+				date,
+				open_time,
+				close_time,
+				pair_id,
+				kline_interval,
+				open_price, high_price, low_price, close_price,
+				volume, quote_volume, trades, source
+			) VALUES($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13)
+			ON CONFLICT (pair_id, kline_interval, open_time) DO UPDATE SET
+				high_price = EXCLUDED.high_price,
+				low_price = EXCLUDED.low_price,
+				close_price = EXCLUDED.close_price,
+				volume = EXCLUDED.volume,
+				quote_volume = EXCLUDED.quote_volume,
+				trades = EXCLUDED.trades,
+				source = EXCLUDED.source`
@@ -0,0 +1,49 @@
+package postgres
+
+// CatalogExchanges возвращает активные биржи для синхронизации справочника символов
+const CatalogExchanges = `
+SELECT
+    id,
+    LOWER(name) AS exchange_name,
+    url,
+    base_url
+FROM
+    exchange
+WHERE
+    active = true
+    AND deleted = false
+ORDER BY
+    id ASC`
+
+// CatalogCoins возвращает все монеты справочника
+const CatalogCoins = "SELECT id, UPPER(symbol) FROM coin"
+
+// InsertCoin добавляет монету в справочник
+const InsertCoin = "INSERT INTO coin (symbol) VALUES ($1)"
+
+// CatalogSpotPairs возвращает спотовые пары биржи с символами монет
+const CatalogSpotPairs = `
+SELECT
+    stp.id,
+    UPPER(c1.symbol) AS base_symbol,
+    UPPER(c2.symbol) AS quote_symbol,
+    stp.active
+FROM
+    spot_trade_pair stp
+INNER JOIN
+    coin c1
+        ON stp.base_currency_id = c1.id
+INNER JOIN
+    coin c2
+        ON stp.quote_currency_id = c2.id
+WHERE
+    stp.exchange_id = $1`
+
+// InsertSpotPair добавляет спотовую пару биржи
+const InsertSpotPair = `
+INSERT INTO spot_trade_pair (
+    exchange_id, base_currency_id, quote_currency_id, active
+) VALUES ($1, $2, $3, $4)`
+
+// UpdateSpotPairActive включает или выключает спотовую пару
+const UpdateSpotPairActive = "UPDATE spot_trade_pair SET active = $1 WHERE id = $2"
@@ -0,0 +1,31 @@
+package postgres
+
+// ExchangeFees возвращает комиссии активных аккаунтов бирж из exchange_fee.
+// Строка без pair_id задает комиссию аккаунта по умолчанию, symbol для нее пустой
+const ExchangeFees = `
+SELECT
+    LOWER(e.name) AS exchange_name,
+    ea.id AS eaid,
+    CASE WHEN ef.pair_id IS NULL THEN '' ELSE c1.symbol || '/' || c2.symbol END AS symbol,
+    ef.maker_fee,
+    ef.taker_fee
+FROM
+    exchange_fee ef
+INNER JOIN
+    exchange_accounts ea
+        ON ea.id = ef.eaid
+INNER JOIN
+    exchange e
+        ON e.id = ea.exid
+LEFT JOIN
+    spot_trade_pair stp
+        ON stp.id = ef.pair_id
+LEFT JOIN
+    coin c1
+        ON stp.base_currency_id = c1.id
+LEFT JOIN
+    coin c2
+        ON stp.quote_currency_id = c2.id
+WHERE
+    ea.active = true
+    AND e.active = true`
@@ -0,0 +1,18 @@
+package postgres
+
+// UpsertFundingRate сохраняет расчет ставки финансирования в funding_rate_history. Ключ -
+// (pair_id, funding_time): повторная запись того же расчета обновляет ставку и цены
+const UpsertFundingRate = `
+			INSERT INTO funding_rate_history (
+				pair_id,
+				funding_time,
+				funding_rate,
+				predicted_rate,
+				mark_price,
+				index_price
+			) VALUES($1,$2,$3,$4,$5,$6)
+			ON CONFLICT (pair_id, funding_time) DO UPDATE SET
+				funding_rate = EXCLUDED.funding_rate,
+				predicted_rate = EXCLUDED.predicted_rate,
+				mark_price = EXCLUDED.mark_price,
+				index_price = EXCLUDED.index_price`
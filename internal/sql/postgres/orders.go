@@ -0,0 +1,122 @@
+package postgres
+
+// UpsertOrder сохраняет состояние ордера в журнал "order". Ключ идемпотентности -
+// (exchange, client_order_id): повторная запись того же ордера обновляет строку
+const UpsertOrder = `
+INSERT INTO "order" (
+    exchange, client_order_id, external_order_id, symbol, side, order_type,
+    price, amount, filled, avg_price, fee, fee_currency, status, error,
+    created_at, updated_at
+) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16)
+ON CONFLICT (exchange, client_order_id) DO UPDATE SET
+    external_order_id = EXCLUDED.external_order_id,
+    filled = EXCLUDED.filled,
+    avg_price = EXCLUDED.avg_price,
+    fee = EXCLUDED.fee,
+    fee_currency = EXCLUDED.fee_currency,
+    status = EXCLUDED.status,
+    error = EXCLUDED.error,
+    updated_at = EXCLUDED.updated_at`
+
+// InsertFill добавляет исполнение ордера в fill; повтор того же trade_id на бирже игнорируется.
+// Параметры: exchange, trade_id, price, amount, fee, fee_currency, filled_at, exchange, client_order_id
+const InsertFill = `
+INSERT INTO fill (
+    order_id, exchange, trade_id, price, amount, fee, fee_currency, filled_at
+)
+SELECT
+    o.id, $1, $2, $3, $4, $5, $6, $7
+FROM
+    "order" o
+WHERE
+    o.exchange = $8
+    AND o.client_order_id = $9
+ON CONFLICT (exchange, trade_id) DO NOTHING`
+
+// OpenOrders возвращает ордера журнала в нефинальных статусах для сверки с биржами
+const OpenOrders = `
+SELECT
+    exchange,
+    client_order_id,
+    COALESCE(external_order_id, '') AS external_order_id,
+    symbol,
+    side,
+    order_type,
+    price,
+    amount,
+    filled,
+    avg_price,
+    fee,
+    COALESCE(fee_currency, '') AS fee_currency,
+    status,
+    created_at,
+    updated_at
+FROM
+    "order"
+WHERE
+    status IN ('new', 'partially_filled')
+ORDER BY
+    id ASC`
+
+// InsertLegImbalance сохраняет событие дисбаланса ног сделки; details - хеджирующие ордера в JSON
+const InsertLegImbalance = `
+INSERT INTO leg_imbalance (
+    task_id, symbol, imbalance, hedge_side, hedged, residual, resolved, details,
+    detected_at, finished_at
+) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)`
+
+// UpsertCarryPosition сохраняет состояние позиции cash-and-carry в carry_position. Ключ - position_id;
+// spread_pnl (исполнения ног) и carry_pnl (финансирование) ведутся раздельно,
+// last_funding_at - последний учтенный расчет финансирования биржи
+const UpsertCarryPosition = `
+INSERT INTO carry_position (
+    position_id, symbol, perp_symbol, spot_exchange, perp_exchange, volume, closed_volume,
+    spot_entry_price, perp_entry_price, spot_exit_price, perp_exit_price, spread_pnl, carry_pnl,
+    funding_events, funding_rate, next_funding_time, last_funding_at, status, opened_at, closed_at,
+    updated_at
+) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21)
+ON CONFLICT (position_id) DO UPDATE SET
+    volume = EXCLUDED.volume,
+    closed_volume = EXCLUDED.closed_volume,
+    spot_entry_price = EXCLUDED.spot_entry_price,
+    perp_entry_price = EXCLUDED.perp_entry_price,
+    spot_exit_price = EXCLUDED.spot_exit_price,
+    perp_exit_price = EXCLUDED.perp_exit_price,
+    spread_pnl = EXCLUDED.spread_pnl,
+    carry_pnl = EXCLUDED.carry_pnl,
+    funding_events = EXCLUDED.funding_events,
+    funding_rate = EXCLUDED.funding_rate,
+    next_funding_time = EXCLUDED.next_funding_time,
+    last_funding_at = EXCLUDED.last_funding_at,
+    status = EXCLUDED.status,
+    closed_at = EXCLUDED.closed_at,
+    updated_at = EXCLUDED.updated_at`
+
+// OpenCarryPositions возвращает открытые позиции cash-and-carry для восстановления стратегии
+const OpenCarryPositions = `
+SELECT
+    position_id,
+    symbol,
+    perp_symbol,
+    spot_exchange,
+    perp_exchange,
+    volume,
+    closed_volume,
+    spot_entry_price,
+    perp_entry_price,
+    spot_exit_price,
+    perp_exit_price,
+    spread_pnl,
+    carry_pnl,
+    funding_events,
+    funding_rate,
+    next_funding_time,
+    last_funding_at,
+    opened_at,
+    updated_at
+FROM
+    carry_position
+WHERE
+    status = 'open'
+ORDER BY
+    id ASC`
@@ -0,0 +1,22 @@
+package postgres
+
+// PendingTransfers возвращает переводы, еще не обработанные оператором
+const PendingTransfers = `
+SELECT
+    asset,
+    from_exchange,
+    to_exchange,
+    amount,
+    network_fee,
+    reason,
+    created_at
+FROM
+    transfer_plan
+WHERE
+    status IN ('proposed', 'approved')`
+
+// InsertTransfer добавляет предложенный перевод между биржами в статусе proposed
+const InsertTransfer = `
+INSERT INTO transfer_plan (
+    asset, from_exchange, to_exchange, amount, network_fee, reason, status, created_at
+) VALUES ($1, $2, $3, $4, $5, $6, 'proposed', $7)`
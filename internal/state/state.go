@@ -4,10 +4,16 @@ import (
 	"encoding/json"
 	"os"
 	"path/filepath"
+	"time"
 )
 
 type DaemonState struct {
 	Active bool `json:"active"`
+	// Halted - включен kill switch: новые ордера блокируются до явного re-arm,
+	// флаг переживает перезапуск демона
+	Halted     bool      `json:"halted,omitempty"`
+	HaltReason string    `json:"halt_reason,omitempty"`
+	HaltedAt   time.Time `json:"halted_at,omitempty"`
 }
 
 func LoadState(file string) *DaemonState {
@@ -44,7 +50,8 @@ func SaveState(file string, st *DaemonState) {
 }
 
 func SetActive(stateFile string, active bool) *DaemonState {
-	st := &DaemonState{Active: active}
+	st := LoadState(stateFile)
+	st.Active = active
 	// Создать директорию для state-файла, если не существует
 	dir := filepath.Dir(stateFile)
 	if err := os.MkdirAll(dir, 0755); err != nil {
@@ -54,3 +61,23 @@ func SetActive(stateFile string, active bool) *DaemonState {
 	SaveState(stateFile, st)
 	return st
 }
+
+// SetHalted сохраняет состояние kill switch, не меняя Active
+func SetHalted(stateFile string, halted bool, reason string) *DaemonState {
+	st := LoadState(stateFile)
+	st.Halted = halted
+	st.HaltReason = ""
+	st.HaltedAt = time.Time{}
+	if halted {
+		st.HaltReason = reason
+		st.HaltedAt = time.Now().UTC()
+	}
+	// Создать директорию для state-файла, если не существует
+	dir := filepath.Dir(stateFile)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		// Не критично, флаг kill switch действует в памяти до перезапуска
+		return st
+	}
+	SaveState(stateFile, st)
+	return st
+}
@@ -0,0 +1,330 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"daemon-go/internal/bus"
+	"daemon-go/internal/db"
+	"daemon-go/internal/market"
+	sqlMySQL "daemon-go/internal/sql/mysql"
+	sqlPostgres "daemon-go/internal/sql/postgres"
+	"daemon-go/pkg/log"
+)
+
+// candleKey - свеча пары одного интервала
+type candleKey struct {
+	pairID   int
+	interval string
+}
+
+// candleRecord - свеча пары для записи в БД
+type candleRecord struct {
+	pairID int
+	kline  market.UnifiedKline
+}
+
+// CandleMonitor записывает свечи пар мониторинга в PRICE_SPOT_KLINE рядом с PRICE_SPOT_LOG.
+// Свечи свечных потоков бирж записываются по закрытию (флаг биржи или приход следующей свечи).
+// Для интервалов, которых биржа не присылает (CoinEx - никаких), свечи собираются из публичных
+// сделок CandleAggregator'ом
+type CandleMonitor struct {
+	db            db.DBDriver
+	bus           *bus.MessageBus
+	logger        *log.Logger
+	ctx           context.Context
+	cancel        context.CancelFunc
+	flushInterval time.Duration
+	wg            sync.WaitGroup
+
+	mu              sync.Mutex
+	aggregator      *market.CandleAggregator
+	native          map[string]map[string]bool            // [exchange][interval] биржа присылает свечи
+	current         map[candleKey]market.UnifiedKline     // текущая свеча потока биржи
+	pairIDs         map[string]map[string]int             // [exchange][symbol] PairID для локальных свечей
+	pending         []candleRecord                        // закрытые свечи до записи
+	subscribers     map[string]chan market.UnifiedMessage // [exchange]
+	monitoringPairs map[int]PriceMonitorPair              // [pairID]
+
+	saved  int64
+	errors int64
+}
+
+// NewCandleMonitor создает запись свечей: intervals - интервалы локальных свечей из сделок,
+// flushInterval - период записи в БД (0 - 5 секунд)
+func NewCandleMonitor(dbDriver db.DBDriver, intervals []time.Duration, flushInterval time.Duration) *CandleMonitor {
+	ctx, cancel := context.WithCancel(context.Background())
+	if flushInterval <= 0 {
+		flushInterval = 5 * time.Second
+	}
+
+	return &CandleMonitor{
+		db:              dbDriver,
+		bus:             bus.GetInstance(),
+		logger:          log.New("candle_monitor"),
+		ctx:             ctx,
+		cancel:          cancel,
+		flushInterval:   flushInterval,
+		aggregator:      market.NewCandleAggregator(intervals),
+		native:          make(map[string]map[string]bool),
+		current:         make(map[candleKey]market.UnifiedKline),
+		pairIDs:         make(map[string]map[string]int),
+		subscribers:     make(map[string]chan market.UnifiedMessage),
+		monitoringPairs: make(map[int]PriceMonitorPair),
+	}
+}
+
+// Start загружает пары мониторинга и подписывается на свечи и сделки всех бирж
+func (cm *CandleMonitor) Start() error {
+	pairs, err := cm.getMonitoringPairs()
+	if err != nil {
+		return fmt.Errorf("failed to get monitoring pairs: %w", err)
+	}
+	for _, pair := range pairs {
+		cm.monitoringPairs[pair.PairID] = pair
+	}
+	cm.logger.Info("[CANDLES] Loaded %d pairs, flush interval %v", len(pairs), cm.flushInterval)
+
+	for _, exchange := range []string{"binance", "kucoin", "bybit", "htx", "poloniex", "coinex"} {
+		ch := cm.bus.Subscribe(exchange, 1000)
+		cm.subscribers[exchange] = ch
+		cm.wg.Add(1)
+		go cm.processMessages(ch)
+	}
+
+	cm.wg.Add(1)
+	go cm.flushLoop()
+	return nil
+}
+
+// Stop отписывается от шины и записывает накопленные свечи
+func (cm *CandleMonitor) Stop() {
+	cm.cancel()
+	for exchange, ch := range cm.subscribers {
+		cm.bus.Unsubscribe(exchange, ch)
+	}
+	cm.wg.Wait()
+
+	if err := cm.flush(time.Now()); err != nil {
+		cm.logger.Error("[CANDLES] Final flush failed: %v", err)
+	}
+	cm.logger.Info("[CANDLES] Candle monitor stopped")
+}
+
+// processMessages разбирает свечи и сделки пар мониторинга
+func (cm *CandleMonitor) processMessages(ch chan market.UnifiedMessage) {
+	defer cm.wg.Done()
+
+	for {
+		select {
+		case <-cm.ctx.Done():
+			return
+		case msg, ok := <-ch:
+			if !ok {
+				return
+			}
+			if _, exists := cm.monitoringPairs[msg.PairID]; !exists {
+				continue
+			}
+			switch msg.MessageType {
+			case market.MessageTypeKline:
+				if kline, ok := msg.Data.(market.UnifiedKline); ok {
+					cm.handleKline(msg.Exchange, msg.PairID, kline)
+				}
+			case market.MessageTypeTrade:
+				if trade, ok := msg.Data.(market.UnifiedTrade); ok {
+					cm.handleTrade(msg.Exchange, msg.PairID, trade)
+				}
+			}
+		}
+	}
+}
+
+// handleKline запоминает текущую свечу потока биржи. Предыдущая свеча закрывается, когда
+// приходит следующая: KuCoin, HTX и Poloniex не сообщают о закрытии
+func (cm *CandleMonitor) handleKline(exchange string, pairID int, kline market.UnifiedKline) {
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+
+	if cm.native[exchange] == nil {
+		cm.native[exchange] = make(map[string]bool)
+	}
+	cm.native[exchange][kline.Interval] = true
+
+	key := candleKey{pairID: pairID, interval: kline.Interval}
+	if prev, ok := cm.current[key]; ok {
+		if kline.OpenTime.Before(prev.OpenTime) {
+			return
+		}
+		if kline.OpenTime.After(prev.OpenTime) {
+			prev.Closed = true
+			cm.pending = append(cm.pending, candleRecord{pairID: pairID, kline: prev})
+		}
+	}
+	if kline.Closed {
+		cm.pending = append(cm.pending, candleRecord{pairID: pairID, kline: kline})
+		delete(cm.current, key)
+		return
+	}
+	cm.current[key] = kline
+}
+
+// handleTrade добавляет сделку в локальные свечи
+func (cm *CandleMonitor) handleTrade(exchange string, pairID int, trade market.UnifiedTrade) {
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+
+	if cm.pairIDs[exchange] == nil {
+		cm.pairIDs[exchange] = make(map[string]int)
+	}
+	cm.pairIDs[exchange][trade.Symbol] = pairID
+	cm.addLocal(exchange, cm.aggregator.AddTrade(exchange, trade))
+}
+
+// addLocal ставит в запись локальные свечи интервалов, которых биржа не присылает
+func (cm *CandleMonitor) addLocal(exchange string, klines []market.UnifiedKline) {
+	for _, kline := range klines {
+		if cm.native[exchange][kline.Interval] {
+			continue
+		}
+		pairID, ok := cm.pairIDs[exchange][kline.Symbol]
+		if !ok {
+			continue
+		}
+		cm.pending = append(cm.pending, candleRecord{pairID: pairID, kline: kline})
+	}
+}
+
+// flushLoop периодически записывает закрытые свечи
+func (cm *CandleMonitor) flushLoop() {
+	defer cm.wg.Done()
+
+	ticker := time.NewTicker(cm.flushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-cm.ctx.Done():
+			return
+		case now := <-ticker.C:
+			if err := cm.flush(now); err != nil {
+				cm.logger.Error("[CANDLES] Failed to save candles: %v", err)
+			}
+		}
+	}
+}
+
+// flush закрывает локальные свечи, интервал которых закончился, и записывает накопленные свечи.
+// При ошибке записи свечи остаются в очереди до следующей попытки
+func (cm *CandleMonitor) flush(now time.Time) error {
+	cm.mu.Lock()
+	for exchange, klines := range cm.aggregator.Flush(now) {
+		cm.addLocal(exchange, klines)
+	}
+	records := cm.pending
+	cm.pending = nil
+	cm.mu.Unlock()
+
+	if len(records) == 0 {
+		return nil
+	}
+	if err := cm.save(records); err != nil {
+		cm.mu.Lock()
+		cm.errors++
+		cm.pending = append(records, cm.pending...)
+		cm.mu.Unlock()
+		return err
+	}
+
+	cm.mu.Lock()
+	cm.saved += int64(len(records))
+	cm.mu.Unlock()
+	cm.logger.Debug("[CANDLES] Saved %d candles", len(records))
+	return nil
+}
+
+// save записывает свечи одной транзакцией
+func (cm *CandleMonitor) save(records []candleRecord) error {
+	tx, err := cm.db.BeginTx()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := sqlMySQL.UpsertSpotKline
+	if cm.db.GetType() == "postgres" {
+		query = sqlPostgres.UpsertSpotKline
+	}
+	stmt, err := tx.Prepare(query)
+	if err != nil {
+		return fmt.Errorf("failed to prepare insert statement: %w", err)
+	}
+	defer stmt.Close()
+
+	for _, r := range records {
+		k := r.kline
+		_, err := stmt.Exec(
+			k.OpenTime.Truncate(24*time.Hour),
+			k.OpenTime,
+			k.CloseTime,
+			r.pairID,
+			k.Interval,
+			k.Open, k.High, k.Low, k.Close,
+			k.Volume, k.QuoteVolume, k.Trades, string(k.Source),
+		)
+		if err != nil {
+			return fmt.Errorf("failed to insert candle for pair %d: %w", r.pairID, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// getMonitoringPairs получает список пар мониторинга из БД
+func (cm *CandleMonitor) getMonitoringPairs() ([]PriceMonitorPair, error) {
+	query := sqlMySQL.GetMonitoringPairs
+	if cm.db.GetType() == "postgres" {
+		query = sqlPostgres.GetMonitoringPairs
+	}
+
+	rows, err := cm.db.Query(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute monitoring pairs query: %w", err)
+	}
+	defer rows.Close()
+
+	var pairs []PriceMonitorPair
+	for rows.Next() {
+		var pair PriceMonitorPair
+		if err := rows.Scan(&pair.ExchangeID, &pair.PairID, &pair.ExchangeName); err != nil {
+			return nil, err
+		}
+		pairs = append(pairs, pair)
+	}
+	return pairs, rows.Err()
+}
+
+// GetStats возвращает статистику записи свечей
+func (cm *CandleMonitor) GetStats() map[string]interface{} {
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+
+	native := make(map[string][]string, len(cm.native))
+	for exchange, intervals := range cm.native {
+		for interval := range intervals {
+			native[exchange] = append(native[exchange], interval)
+		}
+	}
+	return map[string]interface{}{
+		"saved":          cm.saved,
+		"pending":        len(cm.pending),
+		"errors":         cm.errors,
+		"late_trades":    cm.aggregator.Late(),
+		"native_streams": native,
+	}
+}
@@ -142,7 +142,7 @@ func (dm *DataMonitor) UpdateWorkersFromPairs(pairs []db.DataMonitorPair) {
 					dm.incErrors()
 					continue
 				}
-				worker := dataworker.NewDataWorker(*ex)
+				worker := dataworker.NewDataWorker(*ex, marketType)
 				// Передать параметры подписки с PairID
 				worker.SetSubscriptionWithPairID(marketPairs, marketType, 5)
 				dm.workers[key] = worker
@@ -3,6 +3,7 @@ package dataworker
 import (
 	"daemon-go/internal/db"
 	"daemon-go/internal/exchange"
+	"daemon-go/internal/market"
 	"daemon-go/pkg/log"
 	"fmt"
 	"strings"
@@ -27,13 +28,13 @@ type DataWorker struct {
 
 var logger = log.New("DataWorker")
 
-// NewDataWorker создаёт DataWorker по данным db.Exchange
-func NewDataWorker(ex db.Exchange) *DataWorker {
-	logger.Info("Creating DataWorker for exchange: %s (ID: %d)", ex.Name, ex.ID)
+// NewDataWorker создаёт DataWorker рынка marketType (spot/futures) по данным db.Exchange
+func NewDataWorker(ex db.Exchange, marketType string) *DataWorker {
+	logger.Info("Creating DataWorker for exchange: %s (ID: %d), market: %s", ex.Name, ex.ID, marketType)
 	return &DataWorker{
-		adapter:     exchange.NewAdapter(ex),
+		adapter:     exchange.NewMarketAdapter(ex, marketType),
 		pairs:       nil,
-		marketType:  "spot",
+		marketType:  market.NormalizeMarketType(marketType),
 		depth:       5,
 		startTime:   time.Now(),
 		restEnabled: true, // по умолчанию REST API включен
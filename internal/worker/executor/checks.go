@@ -0,0 +1,49 @@
+package executor
+
+import "daemon-go/internal/market"
+
+// CheckChain объединяет несколько проверок перед размещением (риск-лимиты, балансы).
+// Ордер проходит, если его пропустили все проверки; при отказе уже пройденные
+// проверки освобождают свои резервы
+type CheckChain []PreTradeCheck
+
+var (
+	_ PreTradeCheck     = CheckChain(nil)
+	_ PlacementObserver = CheckChain(nil)
+)
+
+// Check выполняет проверки по порядку
+func (c CheckChain) Check(exchange string, req market.OrderRequest) error {
+	for i, check := range c {
+		if err := check.Check(exchange, req); err != nil {
+			for j := i - 1; j >= 0; j-- {
+				c[j].Release(exchange, req, nil)
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+// Release освобождает резервы всех проверок
+func (c CheckChain) Release(exchange string, req market.OrderRequest, order *market.Order) {
+	for _, check := range c {
+		check.Release(exchange, req, order)
+	}
+}
+
+// Placed передает подтверждение размещения проверкам, которым оно нужно
+func (c CheckChain) Placed(exchange string, req market.OrderRequest, order *market.Order) {
+	for _, check := range c {
+		if observer, ok := check.(PlacementObserver); ok {
+			observer.Placed(exchange, req, order)
+		}
+	}
+}
+
+// RecordPnL передает результат сделки всем проверкам
+func (c CheckChain) RecordPnL(pnl float64) {
+	for _, check := range c {
+		check.RecordPnL(pnl)
+	}
+}
@@ -0,0 +1,596 @@
+package executor
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"sync"
+	"time"
+
+	"daemon-go/internal/market"
+	"daemon-go/pkg/log"
+)
+
+// Config - конфигурация исполнителя
+type Config struct {
+	FillTimeout     time.Duration // сколько ждать исполнения ордеров
+	PollInterval    time.Duration // интервал опроса статуса ордеров
+	CancelOnTimeout bool          // отменять неисполненный остаток по таймауту
+	HistorySize     int           // сколько результатов хранить в памяти
+	Hedge           HedgePolicy   // политика при дисбалансе ног по умолчанию (без действий - только фиксация)
+}
+
+// DefaultConfig возвращает конфигурацию по умолчанию
+func DefaultConfig() *Config {
+	return &Config{
+		FillTimeout:     10 * time.Second,
+		PollInterval:    250 * time.Millisecond,
+		CancelOnTimeout: true,
+		HistorySize:     100,
+	}
+}
+
+// Executor размещает ноги сделки параллельно, отслеживает их исполнение и сохраняет итог
+type Executor struct {
+	mu       sync.RWMutex
+	config   *Config
+	gateways map[string]OrderGateway // [exchange] -> gateway
+	check    PreTradeCheck           // проверка рисков перед размещением (nil - без проверки)
+	observer OrderObserver           // журнал жизненного цикла ордеров (nil - без журнала)
+	recorder ImbalanceRecorder       // хранилище событий дисбаланса (nil - только история в памяти)
+	rules    SymbolRules             // округление цены и количества к шагам биржи (nil - без округления)
+	history  []ExecutionResult
+	hedges   []ImbalanceEvent
+	logger   *log.Logger
+
+	// Статистика
+	totalTasks     int64
+	completedTasks int64
+	partialTasks   int64
+	failedTasks    int64
+	realizedProfit float64
+	imbalances     int64
+	unresolved     int64
+}
+
+// NewExecutor создает новый исполнитель
+func NewExecutor(config *Config) *Executor {
+	if config == nil {
+		config = DefaultConfig()
+	}
+
+	return &Executor{
+		config:   config,
+		gateways: make(map[string]OrderGateway),
+		history:  make([]ExecutionResult, 0),
+		logger:   log.New("executor"),
+	}
+}
+
+// RegisterGateway регистрирует шлюз ордеров для биржи
+func (e *Executor) RegisterGateway(exchange string, gateway OrderGateway) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.gateways[strings.ToLower(exchange)] = gateway
+}
+
+// SetPreTradeCheck задает проверку, через которую проходит каждый ордер перед отправкой на биржу
+func (e *Executor) SetPreTradeCheck(check PreTradeCheck) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.check = check
+}
+
+// SetOrderObserver задает получателя изменений состояния размещаемых ордеров
+func (e *Executor) SetOrderObserver(observer OrderObserver) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.observer = observer
+}
+
+// SetSymbolRules задает торговые правила, по которым ордера округляются перед отправкой
+func (e *Executor) SetSymbolRules(rules SymbolRules) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.rules = rules
+}
+
+// SetImbalanceRecorder задает хранилище событий дисбаланса ног
+func (e *Executor) SetImbalanceRecorder(recorder ImbalanceRecorder) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.recorder = recorder
+}
+
+// HasGateway проверяет, зарегистрирован ли шлюз для биржи
+func (e *Executor) HasGateway(exchange string) bool {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	_, ok := e.gateways[strings.ToLower(exchange)]
+	return ok
+}
+
+func (e *Executor) preTradeCheck() PreTradeCheck {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.check
+}
+
+func (e *Executor) symbolRules() SymbolRules {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.rules
+}
+
+func (e *Executor) orderObserver() OrderObserver {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.observer
+}
+
+func (e *Executor) gateway(exchange string) (OrderGateway, error) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	gw, ok := e.gateways[strings.ToLower(exchange)]
+	if !ok {
+		return nil, fmt.Errorf("executor: no gateway for exchange %s", exchange)
+	}
+	return gw, nil
+}
+
+// Execute исполняет задачу: все ноги размещаются одновременно, затем
+// исполнитель ждет их заполнения и фиксирует результат
+func (e *Executor) Execute(task Task) ExecutionResult {
+	if task.CreatedAt.IsZero() {
+		task.CreatedAt = time.Now()
+	}
+	if task.ID == "" {
+		task.ID = fmt.Sprintf("task-%d", task.CreatedAt.UnixNano())
+	}
+
+	result := ExecutionResult{
+		TaskID:         task.ID,
+		Symbol:         task.Symbol,
+		Legs:           make([]LegResult, len(task.Legs)),
+		ExpectedProfit: task.ExpectedProfit,
+		StartedAt:      time.Now(),
+	}
+
+	e.logger.Info("[EXECUTOR] Executing task %s (%s): %d legs, expected profit %.6f",
+		task.ID, task.Symbol, len(task.Legs), task.ExpectedProfit)
+
+	task.Legs = append([]LegRequest(nil), task.Legs...)
+	if err := e.normalizeLegs(task); err != nil {
+		e.logger.Warn("[EXECUTOR] Task %s rejected by symbol rules: %v", task.ID, err)
+		for i, leg := range task.Legs {
+			result.Legs[i] = LegResult{Leg: leg, Error: err.Error()}
+		}
+		result.FinishedAt = time.Now()
+		result.Status = ExecutionStatusFailed
+		e.record(result)
+		return result
+	}
+
+	var wg sync.WaitGroup
+	for i, leg := range task.Legs {
+		if leg.Request.ClientOrderID == "" {
+			leg.Request.ClientOrderID = fmt.Sprintf("%s-%d", task.ID, i)
+		}
+		wg.Add(1)
+		go func(i int, leg LegRequest) {
+			defer wg.Done()
+			result.Legs[i] = e.executeLeg(leg, e.config.FillTimeout)
+		}(i, leg)
+	}
+	wg.Wait()
+
+	result.Imbalance = e.resolveImbalance(task, result.Legs)
+
+	result.FinishedAt = time.Now()
+	result.Status = resolveStatus(result.Legs)
+	if task.ProfitCurrency != "" {
+		result.RealizedProfit = currencyFlowProfit(result.Legs, task.ProfitCurrency)
+	} else {
+		result.RealizedProfit = realizedProfit(append(result.Legs, result.Imbalance.Legs()...))
+	}
+	if check := e.preTradeCheck(); check != nil && result.Status != ExecutionStatusFailed {
+		check.RecordPnL(result.RealizedProfit)
+	}
+
+	e.record(result)
+
+	e.logger.Info("[EXECUTOR] Task %s finished: status=%s, realized profit %.6f (expected %.6f), took %v",
+		task.ID, result.Status, result.RealizedProfit, result.ExpectedProfit, result.FinishedAt.Sub(result.StartedAt))
+
+	return result
+}
+
+// normalizeLegs округляет ноги задачи к торговым правилам бирж. В паре покупка/продажа
+// одного символа объем выравнивается по наименьшему допустимому на всех биржах, чтобы
+// округление не создавало дисбаланс ног. Если хотя бы одна нога не проходит правила,
+// задача не исполняется
+func (e *Executor) normalizeLegs(task Task) error {
+	rules := e.symbolRules()
+	if rules == nil {
+		return nil
+	}
+
+	if task.ProfitCurrency == "" && pairedLegs(task.Legs) {
+		volume := task.Legs[0].Request.Volume
+		for _, leg := range task.Legs {
+			volume = math.Min(volume, leg.Request.Volume)
+		}
+		// Несколько проходов: округление вниз на одной бирже может потребовать повторного на другой
+		for pass := 0; pass < 3; pass++ {
+			for _, leg := range task.Legs {
+				if r, ok := rules.Rules(leg.Exchange, leg.Request.Symbol); ok {
+					volume = math.Min(volume, r.RoundQuantity(volume))
+				}
+			}
+		}
+		for i := range task.Legs {
+			task.Legs[i].Request.Volume = volume
+		}
+	}
+
+	for i, leg := range task.Legs {
+		r, ok := rules.Rules(leg.Exchange, leg.Request.Symbol)
+		if !ok {
+			continue
+		}
+		normalized, err := r.Normalize(leg.Request)
+		if err != nil {
+			return fmt.Errorf("leg %d on %s: %w", i, leg.Exchange, err)
+		}
+		task.Legs[i].Request = normalized
+	}
+	return nil
+}
+
+// executeLeg размещает ордер одной ноги и отслеживает его до финального статуса или таймаута
+func (e *Executor) executeLeg(leg LegRequest, timeout time.Duration) LegResult {
+	res := LegResult{Leg: leg}
+
+	gw, err := e.gateway(leg.Exchange)
+	if err != nil {
+		res.Error = err.Error()
+		return res
+	}
+
+	// Цена и количество округляются к шагам биржи до проверок и отправки
+	if rules := e.symbolRules(); rules != nil {
+		if symbolRules, ok := rules.Rules(leg.Exchange, leg.Request.Symbol); ok {
+			normalized, err := symbolRules.Normalize(leg.Request)
+			if err != nil {
+				res.Error = err.Error()
+				return res
+			}
+			leg.Request = normalized
+			res.Leg = leg
+		}
+	}
+
+	check := e.preTradeCheck()
+	if check != nil {
+		if err := check.Check(leg.Exchange, leg.Request); err != nil {
+			res.Error = err.Error()
+			return res
+		}
+	}
+
+	observer := e.orderObserver()
+	if observer != nil {
+		if err := observer.OrderSubmitted(leg.Exchange, leg.Request); err != nil {
+			if check != nil {
+				check.Release(leg.Exchange, leg.Request, nil)
+			}
+			res.Error = err.Error()
+			return res
+		}
+	}
+
+	order, err := gw.PlaceOrder(leg.Request)
+	if err != nil {
+		if check != nil {
+			check.Release(leg.Exchange, leg.Request, nil)
+		}
+		if observer != nil {
+			observer.OrderFailed(leg.Exchange, leg.Request, err)
+		}
+		e.logger.Error("[EXECUTOR] Place order failed on %s (%s %s %.8f@%.8f): %v",
+			leg.Exchange, leg.Request.Side, leg.Request.Symbol, leg.Request.Volume, leg.Request.Price, err)
+		res.Error = err.Error()
+		return res
+	}
+	res.Order = order
+	if placed, ok := check.(PlacementObserver); ok {
+		placed.Placed(leg.Exchange, leg.Request, order)
+	}
+	if observer != nil {
+		observer.OrderUpdated(leg.Exchange, leg.Request, order)
+	}
+
+	order = e.waitForFill(gw, leg, order, timeout, observer)
+	res.Order = order
+	// Лимиты освобождаются на любом выходе, не только по финальному статусу: ордер, оставшийся
+	// на бирже после таймаута (неудачная отмена, CancelOnTimeout=false), дальше ведет журнал ордеров,
+	// а его остаток виден в балансах биржи
+	if check != nil {
+		check.Release(leg.Exchange, leg.Request, order)
+	}
+	if !order.Status.IsFinal() {
+		e.logger.Warn("[EXECUTOR] Order %s on %s left open after %v (filled %.8f of %.8f)",
+			order.OrderID, leg.Exchange, timeout, order.FilledVolume, order.Volume)
+	}
+	res.FilledVolume = order.FilledVolume
+	res.AvgPrice = order.AvgPrice
+	if res.AvgPrice == 0 && res.FilledVolume > 0 {
+		res.AvgPrice = order.Price
+	}
+	res.Fee = order.Fee
+	res.FeeCurrency = order.FeeCurrency
+
+	return res
+}
+
+// waitForFill опрашивает ордер до финального статуса; по таймауту отменяет остаток
+func (e *Executor) waitForFill(gw OrderGateway, leg LegRequest, order *market.Order, timeout time.Duration, observer OrderObserver) *market.Order {
+	deadline := time.Now().Add(timeout)
+
+	for !order.Status.IsFinal() && time.Now().Before(deadline) {
+		time.Sleep(e.config.PollInterval)
+
+		updated, err := gw.GetOrder(leg.Request.Symbol, order.OrderID)
+		if err != nil {
+			e.logger.Warn("[EXECUTOR] Get order %s on %s failed: %v", order.OrderID, leg.Exchange, err)
+			continue
+		}
+		order = carryFee(order, updated)
+		if observer != nil {
+			observer.OrderUpdated(leg.Exchange, leg.Request, order)
+		}
+	}
+
+	if order.Status.IsFinal() || !e.config.CancelOnTimeout {
+		return order
+	}
+
+	e.logger.Warn("[EXECUTOR] Order %s on %s not filled in %v (filled %.8f of %.8f), canceling",
+		order.OrderID, leg.Exchange, timeout, order.FilledVolume, order.Volume)
+
+	if err := gw.CancelOrder(leg.Request.Symbol, order.OrderID); err != nil {
+		e.logger.Error("[EXECUTOR] Cancel order %s on %s failed: %v", order.OrderID, leg.Exchange, err)
+	}
+
+	// Финальное состояние после отмены: объем мог дозаполниться
+	if updated, err := gw.GetOrder(leg.Request.Symbol, order.OrderID); err == nil {
+		order = carryFee(order, updated)
+		if observer != nil {
+			observer.OrderUpdated(leg.Exchange, leg.Request, order)
+		}
+	}
+
+	return order
+}
+
+// carryFee переносит в обновленный ордер комиссию из предыдущего состояния, если биржа
+// ее не вернула: комиссия сделок при размещении (fills ответа на PlaceOrder) в GetOrder
+// не приходит, а накопленная комиссия не уменьшается
+func carryFee(prev, updated *market.Order) *market.Order {
+	if updated.Fee < prev.Fee {
+		updated.Fee = prev.Fee
+		updated.FeeCurrency = prev.FeeCurrency
+	} else if updated.FeeCurrency == "" {
+		updated.FeeCurrency = prev.FeeCurrency
+	}
+	return updated
+}
+
+// resolveStatus определяет итоговый статус задачи по результатам ног
+func resolveStatus(legs []LegResult) ExecutionStatus {
+	filled := 0
+	touched := 0
+	for _, leg := range legs {
+		if leg.Filled() {
+			filled++
+		}
+		if leg.FilledVolume > 0 {
+			touched++
+		}
+	}
+
+	switch {
+	case len(legs) > 0 && filled == len(legs):
+		return ExecutionStatusCompleted
+	case touched > 0:
+		return ExecutionStatusPartial
+	default:
+		return ExecutionStatusFailed
+	}
+}
+
+// realizedProfit считает прибыль по согласованному (минимальному) объему покупок и продаж:
+// выручка продаж минус затраты на покупки и комиссии. Ноги одной стороны (включая
+// хеджирующие ордера) суммируются. Комиссии переводятся в quote валюту (feeIn)
+func realizedProfit(legs []LegResult) float64 {
+	var buyVolume, buyQuote, buyFee, sellVolume, sellQuote, sellFee float64
+	for _, leg := range legs {
+		_, quote, _ := legCurrencies(leg.Leg.Request.Symbol)
+		switch leg.Leg.Request.Side {
+		case market.TradeSideBuy:
+			buyVolume += leg.FilledVolume
+			buyQuote += leg.FilledVolume * leg.AvgPrice
+			buyFee += feeIn(leg, quote)
+		case market.TradeSideSell:
+			sellVolume += leg.FilledVolume
+			sellQuote += leg.FilledVolume * leg.AvgPrice
+			sellFee += feeIn(leg, quote)
+		}
+	}
+	matched := math.Min(buyVolume, sellVolume)
+	if matched <= 0 {
+		return 0
+	}
+
+	buyShare := matched / buyVolume
+	sellShare := matched / sellVolume
+	return (sellQuote-sellFee)*sellShare - (buyQuote+buyFee)*buyShare
+}
+
+// currencyFlowProfit считает изменение остатка валюты currency по всем ногам:
+// покупка тратит quote и дает base, продажа наоборот. Комиссии переводятся в currency (feeIn)
+func currencyFlowProfit(legs []LegResult, currency string) float64 {
+	profit := 0.0
+	for _, leg := range legs {
+		if leg.FilledVolume <= 0 {
+			continue
+		}
+		base, quote, ok := legCurrencies(leg.Leg.Request.Symbol)
+		if !ok {
+			continue
+		}
+		quoteAmount := leg.FilledVolume * leg.AvgPrice
+		switch leg.Leg.Request.Side {
+		case market.TradeSideBuy:
+			if base == currency {
+				profit += leg.FilledVolume
+			}
+			if quote == currency {
+				profit -= quoteAmount
+			}
+		case market.TradeSideSell:
+			if base == currency {
+				profit -= leg.FilledVolume
+			}
+			if quote == currency {
+				profit += quoteAmount
+			}
+		}
+		profit -= feeIn(leg, currency)
+	}
+	return profit
+}
+
+// feeIn переводит комиссию ноги в валюту currency по средней цене исполнения ноги.
+// Комиссия без валюты считается в quote; комиссия в валюте вне пары ноги (например, BNB)
+// и комиссия, которую нельзя перевести в currency по цене ноги, не учитываются
+func feeIn(leg LegResult, currency string) float64 {
+	if leg.Fee == 0 {
+		return 0
+	}
+	base, quote, ok := legCurrencies(leg.Leg.Request.Symbol)
+	if !ok {
+		return 0
+	}
+	feeCurrency := leg.FeeCurrency
+	if feeCurrency == "" {
+		feeCurrency = quote
+	}
+
+	switch {
+	case strings.EqualFold(feeCurrency, currency):
+		return leg.Fee
+	case strings.EqualFold(feeCurrency, base) && quote == currency:
+		return leg.Fee * leg.AvgPrice
+	case strings.EqualFold(feeCurrency, quote) && base == currency && leg.AvgPrice > 0:
+		return leg.Fee / leg.AvgPrice
+	}
+	return 0
+}
+
+// legCurrencies возвращает base и quote валюты символа ноги: спот BTC/USDT или контракт BTCUSDT
+func legCurrencies(symbol string) (string, string, bool) {
+	if base, quote, ok := strings.Cut(symbol, "/"); ok {
+		return base, quote, true
+	}
+	unified, err := market.ParseSymbol(symbol, market.MarketTypeFutures)
+	if err != nil {
+		return "", "", false
+	}
+	return unified.BaseCurrency, unified.QuoteCurrency, true
+}
+
+// record сохраняет результат в историю и обновляет статистику
+func (e *Executor) record(result ExecutionResult) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.totalTasks++
+	switch result.Status {
+	case ExecutionStatusCompleted:
+		e.completedTasks++
+	case ExecutionStatusPartial:
+		e.partialTasks++
+	default:
+		e.failedTasks++
+	}
+	e.realizedProfit += result.RealizedProfit
+
+	e.history = append(e.history, result)
+	if e.config.HistorySize > 0 && len(e.history) > e.config.HistorySize {
+		e.history = e.history[len(e.history)-e.config.HistorySize:]
+	}
+}
+
+// recordImbalance сохраняет событие дисбаланса в историю и хранилище
+func (e *Executor) recordImbalance(event ImbalanceEvent) {
+	e.mu.Lock()
+	e.imbalances++
+	if !event.Resolved {
+		e.unresolved++
+	}
+	e.hedges = append(e.hedges, event)
+	if e.config.HistorySize > 0 && len(e.hedges) > e.config.HistorySize {
+		e.hedges = e.hedges[len(e.hedges)-e.config.HistorySize:]
+	}
+	recorder := e.recorder
+	e.mu.Unlock()
+
+	if recorder != nil {
+		if err := recorder.RecordImbalance(event); err != nil {
+			e.logger.Error("[EXECUTOR] Failed to record imbalance of task %s: %v", event.TaskID, err)
+		}
+	}
+}
+
+// GetImbalances возвращает последние события дисбаланса ног
+func (e *Executor) GetImbalances() []ImbalanceEvent {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	events := make([]ImbalanceEvent, len(e.hedges))
+	copy(events, e.hedges)
+	return events
+}
+
+// GetHistory возвращает последние результаты исполнения
+func (e *Executor) GetHistory() []ExecutionResult {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	history := make([]ExecutionResult, len(e.history))
+	copy(history, e.history)
+	return history
+}
+
+// GetStats возвращает статистику исполнителя
+func (e *Executor) GetStats() map[string]interface{} {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	exchanges := make([]string, 0, len(e.gateways))
+	for name := range e.gateways {
+		exchanges = append(exchanges, name)
+	}
+
+	return map[string]interface{}{
+		"total_tasks":     e.totalTasks,
+		"completed_tasks": e.completedTasks,
+		"partial_tasks":   e.partialTasks,
+		"failed_tasks":    e.failedTasks,
+		"realized_profit": e.realizedProfit,
+		"imbalances":      e.imbalances,
+		"unresolved":      e.unresolved,
+		"exchanges":       exchanges,
+	}
+}
@@ -0,0 +1,195 @@
+package executor
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sync"
+	"testing"
+	"time"
+
+	"daemon-go/internal/market"
+)
+
+// openGateway принимает ордера и оставляет их открытыми; отмена может завершаться ошибкой
+type openGateway struct {
+	cancelErr error
+	placeErr  error
+}
+
+func (g *openGateway) PlaceOrder(req market.OrderRequest) (*market.Order, error) {
+	if g.placeErr != nil {
+		return nil, g.placeErr
+	}
+	return &market.Order{Symbol: req.Symbol, OrderID: req.ClientOrderID, ClientOrderID: req.ClientOrderID,
+		Status: market.OrderStatusNew, Side: req.Side, Price: req.Price, Volume: req.Volume}, nil
+}
+
+func (g *openGateway) CancelOrder(symbol, orderID string) error { return g.cancelErr }
+
+func (g *openGateway) GetOrder(symbol, orderID string) (*market.Order, error) {
+	return &market.Order{Symbol: symbol, OrderID: orderID, Status: market.OrderStatusNew}, nil
+}
+
+// recordingCheck считает резервы и их освобождение по client order ID
+type recordingCheck struct {
+	mu       sync.Mutex
+	reserved map[string]int
+	released map[string]int
+}
+
+func newRecordingCheck() *recordingCheck {
+	return &recordingCheck{reserved: make(map[string]int), released: make(map[string]int)}
+}
+
+func (c *recordingCheck) Check(exchange string, req market.OrderRequest) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.reserved[req.ClientOrderID]++
+	return nil
+}
+
+func (c *recordingCheck) Release(exchange string, req market.OrderRequest, order *market.Order) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.released[req.ClientOrderID]++
+}
+
+func (c *recordingCheck) RecordPnL(pnl float64) {}
+
+// Резерв ноги освобождается на каждом выходе, в том числе когда ордер остался на бирже
+func TestExecuteReleasesReservations(t *testing.T) {
+	tests := []struct {
+		name            string
+		gateway         OrderGateway
+		cancelOnTimeout bool
+	}{
+		{name: "filled", gateway: &fakeGateway{}, cancelOnTimeout: true},
+		{name: "open order kept without cancel", gateway: &openGateway{}, cancelOnTimeout: false},
+		{name: "cancel failed", gateway: &openGateway{cancelErr: errors.New("cancel rejected")}, cancelOnTimeout: true},
+		{name: "place failed", gateway: &openGateway{placeErr: errors.New("insufficient balance")}, cancelOnTimeout: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := NewExecutor(&Config{FillTimeout: 30 * time.Millisecond, PollInterval: 5 * time.Millisecond, CancelOnTimeout: tt.cancelOnTimeout})
+			e.RegisterGateway("binance", tt.gateway)
+			e.RegisterGateway("bybit", tt.gateway)
+			check := newRecordingCheck()
+			e.SetPreTradeCheck(check)
+
+			e.Execute(arbitrageTask(1))
+
+			if len(check.reserved) != 2 {
+				t.Fatalf("%d legs reserved, want 2", len(check.reserved))
+			}
+			for id, n := range check.reserved {
+				if check.released[id] != n {
+					t.Errorf("leg %s: reserved %d, released %d", id, n, check.released[id])
+				}
+			}
+		})
+	}
+}
+
+func TestExecuteCompletedTask(t *testing.T) {
+	e := newHedgeExecutor(HedgePolicy{}, map[string]*fakeGateway{"binance": {}, "bybit": {}})
+
+	result := e.Execute(arbitrageTask(2))
+
+	if result.Status != ExecutionStatusCompleted || result.Imbalance != nil {
+		t.Fatalf("status %s, imbalance %+v", result.Status, result.Imbalance)
+	}
+	for i, leg := range result.Legs {
+		if leg.FilledVolume != 2 || leg.Leg.Request.ClientOrderID != fmt.Sprintf("t1-%d", i) {
+			t.Errorf("leg %d: %+v", i, leg)
+		}
+	}
+	if result.RealizedProfit != 2 {
+		t.Errorf("realized profit %v, want 2", result.RealizedProfit)
+	}
+	if stats := e.GetStats(); stats["completed_tasks"] != int64(1) {
+		t.Errorf("stats %v", stats)
+	}
+}
+
+func TestRealizedProfit(t *testing.T) {
+	leg := func(symbol string, side market.TradeSide, volume, price, fee float64, feeCurrency string) LegResult {
+		return LegResult{
+			Leg:          LegRequest{Request: market.OrderRequest{Symbol: symbol, Side: side}},
+			FilledVolume: volume,
+			AvgPrice:     price,
+			Fee:          fee,
+			FeeCurrency:  feeCurrency,
+		}
+	}
+	tests := []struct {
+		name string
+		legs []LegResult
+		want float64
+	}{
+		{
+			name: "quote fees",
+			legs: []LegResult{
+				leg("BTC/USDT", market.TradeSideBuy, 1, 100, 0.1, "USDT"),
+				leg("BTC/USDT", market.TradeSideSell, 1, 101, 0.101, ""),
+			},
+			want: 101 - 0.101 - 100 - 0.1,
+		},
+		{
+			name: "base fee converted at leg price",
+			legs: []LegResult{
+				leg("BTC/USDT", market.TradeSideBuy, 1, 100, 0.001, "BTC"),
+				leg("BTC/USDT", market.TradeSideSell, 1, 101, 0, ""),
+			},
+			want: 101 - 100 - 0.1,
+		},
+		{
+			name: "fee outside the pair ignored",
+			legs: []LegResult{
+				leg("BTC/USDT", market.TradeSideBuy, 1, 100, 0.0002, "BNB"),
+				leg("BTC/USDT", market.TradeSideSell, 1, 101, 0, ""),
+			},
+			want: 1,
+		},
+		{
+			name: "matched volume only",
+			legs: []LegResult{
+				leg("BTC/USDT", market.TradeSideBuy, 2, 100, 0.2, "USDT"),
+				leg("BTC/USDT", market.TradeSideSell, 1, 101, 0, ""),
+			},
+			want: 101 - (200+0.2)/2,
+		},
+		{
+			name: "hedge legs of one side summed",
+			legs: []LegResult{
+				leg("BTC/USDT", market.TradeSideBuy, 2, 100, 0, ""),
+				leg("BTC/USDT", market.TradeSideSell, 1, 101, 0, ""),
+				leg("BTC/USDT", market.TradeSideSell, 1, 99, 0, ""),
+			},
+			want: 0,
+		},
+		{
+			name: "perpetual leg fee in quote",
+			legs: []LegResult{
+				leg("BTC/USDT", market.TradeSideBuy, 1, 100, 0.1, "USDT"),
+				leg("BTCUSDT", market.TradeSideSell, 1, 102, 0.05, "USDT"),
+			},
+			want: 102 - 0.05 - 100 - 0.1,
+		},
+		{
+			name: "nothing filled on one side",
+			legs: []LegResult{
+				leg("BTC/USDT", market.TradeSideBuy, 1, 100, 0.1, "USDT"),
+				leg("BTC/USDT", market.TradeSideSell, 0, 0, 0, ""),
+			},
+			want: 0,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := realizedProfit(tt.legs); math.Abs(got-tt.want) > 1e-9 {
+				t.Fatalf("realized profit %.10f, want %.10f", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,300 @@
+package executor
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"daemon-go/internal/config"
+	"daemon-go/internal/market"
+)
+
+// imbalanceEpsilon - дисбаланс объема меньше этого значения не хеджируется
+const imbalanceEpsilon = 1e-9
+
+// HedgeAction - способ устранения дисбаланса ног
+type HedgeAction string
+
+const (
+	HedgeActionRetry  HedgeAction = "retry"  // повторить недоисполненную ногу на той же бирже
+	HedgeActionCross  HedgeAction = "cross"  // исполнить остаток на другой бирже
+	HedgeActionUnwind HedgeAction = "unwind" // закрыть исполненную ногу рыночным ордером на ее бирже
+)
+
+// HedgePolicy - политика устранения дисбаланса для сделки. Действия выполняются по порядку,
+// пока остаток не будет закрыт или не истечет Timeout. Без действий дисбаланс только фиксируется
+type HedgePolicy struct {
+	Actions        []HedgeAction `json:"actions"`
+	Timeout        time.Duration `json:"timeout"`                   // на все действия; 0 - FillTimeout исполнителя
+	MaxSlippage    float64       `json:"max_slippage"`              // допуск цены retry/cross относительно цены ноги, доля
+	CrossExchanges []string      `json:"cross_exchanges,omitempty"` // биржи для cross; пусто - все, кроме бирж сделки
+	MinVolume      float64       `json:"min_volume"`                // меньший дисбаланс не хеджируется
+}
+
+// HedgePolicyFromConfig строит политику из секции [hedge] или [hedge.<ID>] конфига
+func HedgePolicyFromConfig(h config.Hedge) HedgePolicy {
+	policy := HedgePolicy{
+		Timeout:     time.Duration(h.TimeoutMs) * time.Millisecond,
+		MaxSlippage: h.MaxSlippage,
+		MinVolume:   h.MinVolume,
+	}
+	for _, action := range strings.Split(h.Actions, ",") {
+		if action = strings.ToLower(strings.TrimSpace(action)); action != "" {
+			policy.Actions = append(policy.Actions, HedgeAction(action))
+		}
+	}
+	for _, exchange := range strings.Split(h.CrossExchanges, ",") {
+		if exchange = strings.ToLower(strings.TrimSpace(exchange)); exchange != "" {
+			policy.CrossExchanges = append(policy.CrossExchanges, exchange)
+		}
+	}
+	return policy
+}
+
+// HedgeAttempt - один хеджирующий ордер
+type HedgeAttempt struct {
+	Action HedgeAction `json:"action"`
+	Result LegResult   `json:"result"`
+}
+
+// ImbalanceEvent - дисбаланс исполненных объемов ног и попытки его устранить
+type ImbalanceEvent struct {
+	TaskID     string           `json:"task_id"`
+	Symbol     string           `json:"symbol"`
+	Imbalance  float64          `json:"imbalance"`  // куплено минус продано, base валюта
+	HedgeSide  market.TradeSide `json:"hedge_side"` // сторона хеджирующих ордеров
+	Attempts   []HedgeAttempt   `json:"attempts,omitempty"`
+	Hedged     float64          `json:"hedged"`   // закрытый хеджем объем
+	Residual   float64          `json:"residual"` // оставшийся открытым объем
+	Resolved   bool             `json:"resolved"`
+	DetectedAt time.Time        `json:"detected_at"`
+	FinishedAt time.Time        `json:"finished_at"`
+}
+
+// Legs возвращает результаты хеджирующих ордеров
+func (ev *ImbalanceEvent) Legs() []LegResult {
+	if ev == nil {
+		return nil
+	}
+	legs := make([]LegResult, len(ev.Attempts))
+	for i, attempt := range ev.Attempts {
+		legs[i] = attempt.Result
+	}
+	return legs
+}
+
+// hedgePolicy возвращает политику задачи или политику исполнителя по умолчанию
+func (e *Executor) hedgePolicy(task Task) HedgePolicy {
+	policy := e.config.Hedge
+	if task.Hedge != nil {
+		policy = *task.Hedge
+	}
+	if policy.Timeout <= 0 {
+		policy.Timeout = e.config.FillTimeout
+	}
+	return policy
+}
+
+// resolveImbalance проверяет, совпали ли исполненные объемы покупок и продаж, и при
+// расхождении выполняет действия политики. Возвращает nil, если дисбаланса нет
+// или задача не является парой покупка/продажа одного символа
+func (e *Executor) resolveImbalance(task Task, legs []LegResult) *ImbalanceEvent {
+	if task.ProfitCurrency != "" {
+		return nil
+	}
+	imbalance, ok := legImbalance(legs)
+	policy := e.hedgePolicy(task)
+	if !ok || math.Abs(imbalance) <= math.Max(policy.MinVolume, imbalanceEpsilon) {
+		return nil
+	}
+
+	event := &ImbalanceEvent{
+		TaskID:     task.ID,
+		Symbol:     task.Symbol,
+		Imbalance:  imbalance,
+		HedgeSide:  market.TradeSideSell,
+		DetectedAt: time.Now(),
+	}
+	if imbalance < 0 {
+		event.HedgeSide = market.TradeSideBuy
+	}
+	lagging, filled := imbalanceLegs(legs, event.HedgeSide)
+
+	e.logger.Warn("[EXECUTOR] Task %s leg imbalance %.8f %s (%s on %s filled %.8f of %.8f), policy %v",
+		task.ID, imbalance, task.Symbol, lagging.Leg.Request.Side, lagging.Leg.Exchange,
+		lagging.FilledVolume, lagging.Leg.Request.Volume, policy.Actions)
+
+	deadline := event.DetectedAt.Add(policy.Timeout)
+	residual := math.Abs(imbalance)
+	for _, action := range policy.Actions {
+		for _, leg := range e.hedgeLegs(action, policy, lagging, filled, event.HedgeSide, residual) {
+			if residual <= imbalanceEpsilon || !time.Now().Before(deadline) {
+				break
+			}
+			leg.Request.Volume = residual
+			leg.Request.ClientOrderID = fmt.Sprintf("%s-h%d", task.ID, len(event.Attempts))
+			res := e.executeLeg(leg, time.Until(deadline))
+			event.Attempts = append(event.Attempts, HedgeAttempt{Action: action, Result: res})
+			residual -= res.FilledVolume
+			event.Hedged += res.FilledVolume
+		}
+	}
+
+	event.Residual = math.Max(residual, 0)
+	event.Resolved = event.Residual <= imbalanceEpsilon
+	event.FinishedAt = time.Now()
+	e.recordImbalance(*event)
+
+	if event.Resolved {
+		e.logger.Info("[EXECUTOR] Task %s imbalance resolved: hedged %.8f in %d orders",
+			task.ID, event.Hedged, len(event.Attempts))
+	} else {
+		e.logger.Error("[EXECUTOR] Task %s imbalance NOT resolved: residual %.8f %s after %d orders",
+			task.ID, event.Residual, task.Symbol, len(event.Attempts))
+	}
+	return event
+}
+
+// hedgeLegs формирует ордера действия политики; объем задается при размещении.
+// Лимитная цена retry/cross - цена отстающей ноги, для рыночной ноги (цена 0) - средняя
+// цена исполненной ноги
+func (e *Executor) hedgeLegs(action HedgeAction, policy HedgePolicy, lagging, filled LegResult, side market.TradeSide, volume float64) []LegRequest {
+	limitPrice := lagging.Leg.Request.Price
+	if limitPrice <= 0 {
+		limitPrice = filled.AvgPrice
+	}
+	if limitPrice <= 0 {
+		limitPrice = filled.Leg.Request.Price
+	}
+	if side == market.TradeSideSell {
+		limitPrice *= 1 - policy.MaxSlippage
+	} else {
+		limitPrice *= 1 + policy.MaxSlippage
+	}
+	limit := func(exchange string) LegRequest {
+		return LegRequest{
+			Exchange: exchange,
+			Request: market.OrderRequest{
+				Symbol:    lagging.Leg.Request.Symbol,
+				Side:      side,
+				OrderType: market.OrderTypeLimit,
+				Price:     limitPrice,
+				Volume:    volume,
+			},
+		}
+	}
+
+	if limitPrice <= 0 && (action == HedgeActionRetry || action == HedgeActionCross) {
+		e.logger.Warn("[EXECUTOR] Hedge action %q skipped: no price for %s limit order", action, lagging.Leg.Request.Symbol)
+		return nil
+	}
+
+	switch action {
+	case HedgeActionRetry:
+		return []LegRequest{limit(lagging.Leg.Exchange)}
+
+	case HedgeActionCross:
+		var legs []LegRequest
+		for _, exchange := range e.crossExchanges(policy, lagging.Leg.Exchange, filled.Leg.Exchange) {
+			legs = append(legs, limit(exchange))
+		}
+		return legs
+
+	case HedgeActionUnwind:
+		// Цена рыночного ордера - ориентир для проверок перед размещением
+		price := filled.AvgPrice
+		if price <= 0 {
+			price = filled.Leg.Request.Price
+		}
+		return []LegRequest{{
+			Exchange: filled.Leg.Exchange,
+			Request: market.OrderRequest{
+				Symbol:    filled.Leg.Request.Symbol,
+				Side:      side,
+				OrderType: market.OrderTypeMarket,
+				Price:     price,
+				Volume:    volume,
+			},
+		}}
+	}
+
+	e.logger.Warn("[EXECUTOR] Unknown hedge action %q skipped", action)
+	return nil
+}
+
+// crossExchanges возвращает биржи для cross: из политики или все зарегистрированные, кроме бирж сделки
+func (e *Executor) crossExchanges(policy HedgePolicy, exclude ...string) []string {
+	skip := make(map[string]bool, len(exclude))
+	for _, exchange := range exclude {
+		skip[strings.ToLower(exchange)] = true
+	}
+
+	candidates := policy.CrossExchanges
+	if len(candidates) == 0 {
+		e.mu.RLock()
+		for exchange := range e.gateways {
+			candidates = append(candidates, exchange)
+		}
+		e.mu.RUnlock()
+		sort.Strings(candidates)
+	}
+
+	var exchanges []string
+	for _, exchange := range candidates {
+		if !skip[strings.ToLower(exchange)] && e.HasGateway(exchange) {
+			exchanges = append(exchanges, exchange)
+		}
+	}
+	return exchanges
+}
+
+// legImbalance возвращает разницу исполненных покупок и продаж. ok=false, если ноги
+// торгуют разными символами или в задаче нет обеих сторон
+func legImbalance(legs []LegResult) (float64, bool) {
+	var bought, sold float64
+	var hasBuy, hasSell bool
+	for _, leg := range legs {
+		if leg.Leg.Request.Symbol != legs[0].Leg.Request.Symbol {
+			return 0, false
+		}
+		switch leg.Leg.Request.Side {
+		case market.TradeSideBuy:
+			hasBuy = true
+			bought += leg.FilledVolume
+		case market.TradeSideSell:
+			hasSell = true
+			sold += leg.FilledVolume
+		}
+	}
+	if !hasBuy || !hasSell {
+		return 0, false
+	}
+	return bought - sold, true
+}
+
+// pairedLegs проверяет, что задача - покупка и продажа одного символа
+func pairedLegs(legs []LegRequest) bool {
+	results := make([]LegResult, len(legs))
+	for i, leg := range legs {
+		results[i].Leg = leg
+	}
+	_, ok := legImbalance(results)
+	return ok
+}
+
+// imbalanceLegs выбирает недоисполненную ногу стороны side и наиболее исполненную ногу противоположной стороны
+func imbalanceLegs(legs []LegResult, side market.TradeSide) (lagging, filled LegResult) {
+	lagGap, fillMax := -1.0, -1.0
+	for _, leg := range legs {
+		if leg.Leg.Request.Side == side {
+			if gap := leg.Leg.Request.Volume - leg.FilledVolume; gap > lagGap {
+				lagging, lagGap = leg, gap
+			}
+		} else if leg.FilledVolume > fillMax {
+			filled, fillMax = leg, leg.FilledVolume
+		}
+	}
+	return lagging, filled
+}
@@ -0,0 +1,223 @@
+package executor
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"daemon-go/internal/config"
+	"daemon-go/internal/market"
+)
+
+// fakeGateway исполняет ордера сразу: доля исполнения берется из очереди fills (по умолчанию 1),
+// неисполненный остаток отменяется
+type fakeGateway struct {
+	mu     sync.Mutex
+	fills  []float64
+	placed []market.OrderRequest
+}
+
+func (g *fakeGateway) PlaceOrder(req market.OrderRequest) (*market.Order, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	ratio := 1.0
+	if len(g.fills) > 0 {
+		ratio, g.fills = g.fills[0], g.fills[1:]
+	}
+	g.placed = append(g.placed, req)
+
+	status := market.OrderStatusFilled
+	if ratio < 1 {
+		status = market.OrderStatusCanceled
+	}
+	return &market.Order{
+		Symbol:        req.Symbol,
+		OrderID:       fmt.Sprintf("%d", len(g.placed)),
+		ClientOrderID: req.ClientOrderID,
+		Status:        status,
+		Side:          req.Side,
+		OrderType:     req.OrderType,
+		Price:         req.Price,
+		Volume:        req.Volume,
+		FilledVolume:  req.Volume * ratio,
+		AvgPrice:      req.Price,
+	}, nil
+}
+
+func (g *fakeGateway) CancelOrder(symbol, orderID string) error { return nil }
+
+func (g *fakeGateway) GetOrder(symbol, orderID string) (*market.Order, error) {
+	return nil, fmt.Errorf("order %s not found", orderID)
+}
+
+func (g *fakeGateway) orders() []market.OrderRequest {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return append([]market.OrderRequest(nil), g.placed...)
+}
+
+func newHedgeExecutor(policy HedgePolicy, gateways map[string]*fakeGateway) *Executor {
+	e := NewExecutor(&Config{FillTimeout: time.Second, PollInterval: 10 * time.Millisecond, CancelOnTimeout: true, Hedge: policy})
+	for exchange, gw := range gateways {
+		e.RegisterGateway(exchange, gw)
+	}
+	return e
+}
+
+func arbitrageTask(volume float64) Task {
+	return Task{
+		ID:     "t1",
+		Symbol: "BTC/USDT",
+		Legs: []LegRequest{
+			{Exchange: "binance", Request: market.OrderRequest{Symbol: "BTC/USDT", Side: market.TradeSideBuy, OrderType: market.OrderTypeLimit, Price: 100, Volume: volume}},
+			{Exchange: "bybit", Request: market.OrderRequest{Symbol: "BTC/USDT", Side: market.TradeSideSell, OrderType: market.OrderTypeLimit, Price: 101, Volume: volume}},
+		},
+	}
+}
+
+func TestHedgeRetryResolvesImbalance(t *testing.T) {
+	buy := &fakeGateway{}
+	sell := &fakeGateway{fills: []float64{0.5}}
+	e := newHedgeExecutor(HedgePolicy{Actions: []HedgeAction{HedgeActionRetry}, MaxSlippage: 0.01},
+		map[string]*fakeGateway{"binance": buy, "bybit": sell})
+
+	result := e.Execute(arbitrageTask(2))
+
+	ev := result.Imbalance
+	if ev == nil {
+		t.Fatal("imbalance not detected")
+	}
+	if ev.Imbalance != 1 || ev.HedgeSide != market.TradeSideSell {
+		t.Fatalf("imbalance %v side %s, want 1 sell", ev.Imbalance, ev.HedgeSide)
+	}
+	if !ev.Resolved || ev.Hedged != 1 || ev.Residual != 0 || len(ev.Attempts) != 1 {
+		t.Fatalf("event %+v, want resolved by one retry", ev)
+	}
+
+	orders := sell.orders()
+	if len(orders) != 2 {
+		t.Fatalf("%d orders on the lagging exchange, want 2", len(orders))
+	}
+	retry := orders[1]
+	if retry.Volume != 1 || retry.Side != market.TradeSideSell || retry.OrderType != market.OrderTypeLimit {
+		t.Errorf("retry order %+v", retry)
+	}
+	if want := 101 * (1 - 0.01); retry.Price != want {
+		t.Errorf("retry price %v, want %v (lagging price minus slippage)", retry.Price, want)
+	}
+	if result.Status != ExecutionStatusPartial {
+		t.Errorf("status %s, want partial", result.Status)
+	}
+}
+
+func TestHedgeCrossThenUnwind(t *testing.T) {
+	buy := &fakeGateway{}
+	sell := &fakeGateway{fills: []float64{0}}
+	cross := &fakeGateway{fills: []float64{0.25}}
+	e := newHedgeExecutor(HedgePolicy{Actions: []HedgeAction{HedgeActionCross, HedgeActionUnwind}},
+		map[string]*fakeGateway{"binance": buy, "bybit": sell, "okx": cross})
+
+	ev := e.Execute(arbitrageTask(1)).Imbalance
+	if ev == nil || !ev.Resolved || len(ev.Attempts) != 2 {
+		t.Fatalf("event %+v, want resolved by cross and unwind", ev)
+	}
+	if ev.Attempts[0].Action != HedgeActionCross || ev.Attempts[0].Result.Leg.Exchange != "okx" {
+		t.Errorf("first attempt %+v, want cross on okx", ev.Attempts[0])
+	}
+
+	unwind := ev.Attempts[1]
+	if unwind.Action != HedgeActionUnwind || unwind.Result.Leg.Exchange != "binance" {
+		t.Fatalf("second attempt %+v, want unwind on the filled exchange", unwind)
+	}
+	req := unwind.Result.Leg.Request
+	if req.OrderType != market.OrderTypeMarket || req.Side != market.TradeSideSell || req.Volume != 0.75 {
+		t.Errorf("unwind order %+v, want market sell of the residual 0.75", req)
+	}
+	if req.Price != 100 {
+		t.Errorf("unwind reference price %v, want the filled leg average price", req.Price)
+	}
+}
+
+func TestHedgeWithoutActionsOnlyRecords(t *testing.T) {
+	buy := &fakeGateway{fills: []float64{0.4}}
+	sell := &fakeGateway{}
+	e := newHedgeExecutor(HedgePolicy{}, map[string]*fakeGateway{"binance": buy, "bybit": sell})
+
+	ev := e.Execute(arbitrageTask(1)).Imbalance
+	if ev == nil {
+		t.Fatal("imbalance not detected")
+	}
+	if ev.Resolved || ev.Residual != 0.6 || ev.HedgeSide != market.TradeSideBuy || len(ev.Attempts) != 0 {
+		t.Fatalf("event %+v, want unresolved buy residual 0.6 without attempts", ev)
+	}
+	if stats := e.GetStats(); stats["imbalances"] != int64(1) || stats["unresolved"] != int64(1) {
+		t.Errorf("stats imbalances=%v unresolved=%v, want 1/1", stats["imbalances"], stats["unresolved"])
+	}
+}
+
+func TestHedgeMinVolumeIgnoresDust(t *testing.T) {
+	buy := &fakeGateway{}
+	sell := &fakeGateway{fills: []float64{0.999}}
+	e := newHedgeExecutor(HedgePolicy{Actions: []HedgeAction{HedgeActionRetry}, MinVolume: 0.01},
+		map[string]*fakeGateway{"binance": buy, "bybit": sell})
+
+	if ev := e.Execute(arbitrageTask(1)).Imbalance; ev != nil {
+		t.Fatalf("dust imbalance hedged: %+v", ev)
+	}
+	if n := len(sell.orders()); n != 1 {
+		t.Fatalf("%d orders on the lagging exchange, want 1", n)
+	}
+}
+
+func TestHedgeTaskPolicyOverridesDefault(t *testing.T) {
+	buy := &fakeGateway{}
+	sell := &fakeGateway{fills: []float64{0.5}}
+	e := newHedgeExecutor(HedgePolicy{Actions: []HedgeAction{HedgeActionRetry}},
+		map[string]*fakeGateway{"binance": buy, "bybit": sell})
+
+	task := arbitrageTask(1)
+	task.Hedge = &HedgePolicy{}
+	ev := e.Execute(task).Imbalance
+	if ev == nil || len(ev.Attempts) != 0 {
+		t.Fatalf("event %+v, want the task policy without actions", ev)
+	}
+}
+
+func TestLegImbalance(t *testing.T) {
+	leg := func(symbol string, side market.TradeSide, filled float64) LegResult {
+		return LegResult{Leg: LegRequest{Request: market.OrderRequest{Symbol: symbol, Side: side}}, FilledVolume: filled}
+	}
+
+	if v, ok := legImbalance([]LegResult{leg("BTC/USDT", market.TradeSideBuy, 1), leg("BTC/USDT", market.TradeSideSell, 0.3)}); !ok || v != 0.7 {
+		t.Errorf("pair imbalance %v %v, want 0.7", v, ok)
+	}
+	if _, ok := legImbalance([]LegResult{leg("BTC/USDT", market.TradeSideBuy, 1), leg("ETH/USDT", market.TradeSideSell, 1)}); ok {
+		t.Error("legs of different symbols treated as a pair")
+	}
+	if _, ok := legImbalance([]LegResult{leg("BTC/USDT", market.TradeSideBuy, 1), leg("BTC/USDT", market.TradeSideBuy, 1)}); ok {
+		t.Error("legs of one side treated as a pair")
+	}
+}
+
+func TestHedgePolicyFromConfig(t *testing.T) {
+	policy := HedgePolicyFromConfig(config.Hedge{
+		Actions:        " Retry, cross ,,unwind",
+		TimeoutMs:      1500,
+		MaxSlippage:    0.002,
+		CrossExchanges: "OKX, bybit",
+		MinVolume:      0.001,
+	})
+
+	want := []HedgeAction{HedgeActionRetry, HedgeActionCross, HedgeActionUnwind}
+	if fmt.Sprint(policy.Actions) != fmt.Sprint(want) {
+		t.Errorf("actions %v, want %v", policy.Actions, want)
+	}
+	if fmt.Sprint(policy.CrossExchanges) != "[okx bybit]" {
+		t.Errorf("cross exchanges %v", policy.CrossExchanges)
+	}
+	if policy.Timeout != 1500*time.Millisecond || policy.MaxSlippage != 0.002 || policy.MinVolume != 0.001 {
+		t.Errorf("policy %+v", policy)
+	}
+}
@@ -0,0 +1,105 @@
+package executor
+
+import (
+	"time"
+
+	"daemon-go/internal/market"
+)
+
+// OrderGateway - минимальный интерфейс биржи, необходимый исполнителю
+type OrderGateway interface {
+	PlaceOrder(req market.OrderRequest) (*market.Order, error)
+	CancelOrder(symbol, orderID string) error
+	GetOrder(symbol, orderID string) (*market.Order, error)
+}
+
+// PreTradeCheck - проверка ордера перед отправкой на биржу (риск-менеджмент).
+// Check резервирует лимиты под ордер, Release освобождает их по завершении ноги: ордер финален,
+// оставлен на бирже после таймаута исполнения или не был размещен (order == nil)
+type PreTradeCheck interface {
+	Check(exchange string, req market.OrderRequest) error
+	Release(exchange string, req market.OrderRequest, order *market.Order)
+	RecordPnL(pnl float64)
+}
+
+// PlacementObserver - PreTradeCheck, которому нужно подтверждение размещения ордера биржей:
+// Placed вызывается после успешного PlaceOrder (balance.Service снимает резерв, когда балансы
+// биржи уже учитывают ордер)
+type PlacementObserver interface {
+	Placed(exchange string, req market.OrderRequest, order *market.Order)
+}
+
+// SymbolRules - торговые правила символов (exchange.SymbolInfoCache); ok=false, если правил нет
+type SymbolRules interface {
+	Rules(exchange, symbol string) (market.SymbolRules, bool)
+}
+
+// OrderObserver - получатель жизненного цикла ордеров исполнителя (журнал ордеров).
+// OrderSubmitted вызывается до отправки на биржу; ошибка отменяет размещение ноги
+type OrderObserver interface {
+	OrderSubmitted(exchange string, req market.OrderRequest) error
+	OrderUpdated(exchange string, req market.OrderRequest, order *market.Order)
+	OrderFailed(exchange string, req market.OrderRequest, err error)
+}
+
+// ImbalanceRecorder - хранилище событий дисбаланса ног для последующего разбора
+type ImbalanceRecorder interface {
+	RecordImbalance(event ImbalanceEvent) error
+}
+
+// LegRequest - одна нога сделки (ордер на конкретной бирже)
+type LegRequest struct {
+	Exchange string              `json:"exchange"`
+	Request  market.OrderRequest `json:"request"`
+}
+
+// Task - задача на исполнение: набор ног, размещаемых параллельно
+type Task struct {
+	ID             string       `json:"id"`
+	Symbol         string       `json:"symbol"`
+	Legs           []LegRequest `json:"legs"`
+	ExpectedProfit float64      `json:"expected_profit"` // оценочная прибыль в quote валюте
+	// ProfitCurrency - валюта, в которой считается прибыль по потокам валют всех ног
+	// (многоногие задачи, например треугольный цикл); пусто - пара покупка/продажа одного символа
+	ProfitCurrency string       `json:"profit_currency,omitempty"`
+	Hedge          *HedgePolicy `json:"hedge,omitempty"` // политика при дисбалансе ног; nil - политика исполнителя
+	CreatedAt      time.Time    `json:"created_at"`
+}
+
+// LegResult - результат исполнения одной ноги
+type LegResult struct {
+	Leg          LegRequest    `json:"leg"`
+	Order        *market.Order `json:"order,omitempty"`
+	FilledVolume float64       `json:"filled_volume"`
+	AvgPrice     float64       `json:"avg_price"`
+	Fee          float64       `json:"fee"`
+	FeeCurrency  string        `json:"fee_currency,omitempty"` // пусто - quote валюта символа
+	Error        string        `json:"error,omitempty"`
+}
+
+// Filled возвращает true, если нога исполнена полностью
+func (r LegResult) Filled() bool {
+	return r.Order != nil && r.Order.Status == market.OrderStatusFilled
+}
+
+// ExecutionStatus - итоговый статус задачи
+type ExecutionStatus string
+
+const (
+	ExecutionStatusCompleted ExecutionStatus = "completed" // все ноги исполнены полностью
+	ExecutionStatusPartial   ExecutionStatus = "partial"   // часть объема исполнена
+	ExecutionStatusFailed    ExecutionStatus = "failed"    // ничего не исполнено
+)
+
+// ExecutionResult - итог исполнения задачи
+type ExecutionResult struct {
+	TaskID         string          `json:"task_id"`
+	Symbol         string          `json:"symbol"`
+	Status         ExecutionStatus `json:"status"`
+	Legs           []LegResult     `json:"legs"`
+	ExpectedProfit float64         `json:"expected_profit"`
+	RealizedProfit float64         `json:"realized_profit"`     // по согласованному объему с учетом комиссий и хеджа
+	Imbalance      *ImbalanceEvent `json:"imbalance,omitempty"` // дисбаланс ног и хеджирующие ордера
+	StartedAt      time.Time       `json:"started_at"`
+	FinishedAt     time.Time       `json:"finished_at"`
+}
@@ -0,0 +1,65 @@
+package worker
+
+import (
+	"fmt"
+
+	"daemon-go/internal/db"
+	"daemon-go/internal/market"
+	sqlMySQL "daemon-go/internal/sql/mysql"
+	sqlPostgres "daemon-go/internal/sql/postgres"
+)
+
+// LoadFeeSchedule загружает комиссии аккаунтов бирж из EXCHANGE_FEE.
+// Если у биржи несколько активных аккаунтов, берется наибольшая комиссия,
+// чтобы оценка прибыли не была завышенной. defaultRate используется для бирж без записей
+func LoadFeeSchedule(driver db.DBDriver, defaultRate market.FeeRate) (*market.FeeSchedule, error) {
+	query := sqlMySQL.ExchangeFees
+	if driver.GetType() == "postgres" {
+		query = sqlPostgres.ExchangeFees
+	}
+
+	rows, err := driver.Query(query)
+	if err != nil {
+		return nil, fmt.Errorf("load exchange fees: %w", err)
+	}
+	defer rows.Close()
+
+	exchanges := make(map[string]market.FeeRate)
+	pairs := make(map[[2]string]market.FeeRate)
+	for rows.Next() {
+		var exchange, symbol string
+		var accountID int
+		var rate market.FeeRate
+		if err := rows.Scan(&exchange, &accountID, &symbol, &rate.Maker, &rate.Taker); err != nil {
+			return nil, fmt.Errorf("scan exchange fee: %w", err)
+		}
+		if symbol == "" {
+			exchanges[exchange] = maxFeeRate(exchanges[exchange], rate)
+		} else {
+			key := [2]string{exchange, symbol}
+			pairs[key] = maxFeeRate(pairs[key], rate)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("load exchange fees: %w", err)
+	}
+
+	schedule := market.NewFeeSchedule(defaultRate)
+	for exchange, rate := range exchanges {
+		schedule.SetExchange(exchange, rate)
+	}
+	for key, rate := range pairs {
+		schedule.SetPair(key[0], key[1], rate)
+	}
+	return schedule, nil
+}
+
+func maxFeeRate(a, b market.FeeRate) market.FeeRate {
+	if b.Maker > a.Maker {
+		a.Maker = b.Maker
+	}
+	if b.Taker > a.Taker {
+		a.Taker = b.Taker
+	}
+	return a
+}
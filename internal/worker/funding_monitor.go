@@ -0,0 +1,320 @@
+package worker
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sync"
+	"time"
+
+	"daemon-go/internal/bus"
+	"daemon-go/internal/db"
+	"daemon-go/internal/market"
+	sqlMySQL "daemon-go/internal/sql/mysql"
+	sqlPostgres "daemon-go/internal/sql/postgres"
+	"daemon-go/pkg/log"
+)
+
+// fundingSettleSlack - допуск времени расчета: KuCoin сообщает остаток до расчета, поэтому
+// время расчета между опросами немного плавает
+const fundingSettleSlack = time.Minute
+
+// FundingSnapshot - последние ставка финансирования, маркировочная и индексная цены контракта
+type FundingSnapshot struct {
+	Exchange        string
+	Symbol          string // унифицированный символ контракта (BTCUSDT)
+	PairID          int    // ID в FUTURES_TRADE_PAIR (0 - контракт не из мониторинга)
+	FundingRate     float64
+	PredictedRate   float64
+	HasPredicted    bool
+	NextFundingTime time.Time
+	MarkPrice       float64
+	IndexPrice      float64
+	UpdatedAt       time.Time
+}
+
+// fundingKey - контракт биржи
+type fundingKey struct {
+	exchange string
+	symbol   string
+}
+
+// fundingRecord - расчет ставки для записи в БД
+type fundingRecord struct {
+	pairID        int
+	fundingTime   time.Time
+	fundingRate   float64
+	predictedRate sql.NullFloat64
+	markPrice     sql.NullFloat64
+	indexPrice    sql.NullFloat64
+}
+
+// FundingMonitor собирает ставки финансирования, маркировочные и индексные цены бессрочных
+// контрактов из bus.FuturesTopic и записывает каждый расчет в FUNDING_RATE_HISTORY. Расчет
+// определяется по переходу NextFundingTime на следующий период: записывается последняя ставка
+// предыдущего периода. Последние значения доступны стратегиям через Snapshot
+type FundingMonitor struct {
+	db            db.DBDriver
+	bus           *bus.MessageBus
+	logger        *log.Logger
+	ctx           context.Context
+	cancel        context.CancelFunc
+	flushInterval time.Duration
+	wg            sync.WaitGroup
+
+	mu          sync.Mutex
+	snapshots   map[fundingKey]*FundingSnapshot
+	pending     []fundingRecord
+	subscribers map[string]chan market.UnifiedMessage // [топик шины]
+
+	settlements int64
+	saved       int64
+	errors      int64
+}
+
+// NewFundingMonitor создает сбор ставок финансирования; flushInterval - период записи в БД (0 - 5 секунд)
+func NewFundingMonitor(dbDriver db.DBDriver, flushInterval time.Duration) *FundingMonitor {
+	ctx, cancel := context.WithCancel(context.Background())
+	if flushInterval <= 0 {
+		flushInterval = 5 * time.Second
+	}
+
+	return &FundingMonitor{
+		db:            dbDriver,
+		bus:           bus.GetInstance(),
+		logger:        log.New("funding_monitor"),
+		ctx:           ctx,
+		cancel:        cancel,
+		flushInterval: flushInterval,
+		snapshots:     make(map[fundingKey]*FundingSnapshot),
+		subscribers:   make(map[string]chan market.UnifiedMessage),
+	}
+}
+
+// Start подписывается на потоки бессрочных контрактов всех бирж
+func (fm *FundingMonitor) Start() error {
+	for _, exchange := range []string{"binance", "bybit", "kucoin", "htx"} {
+		topic := bus.FuturesTopic(exchange)
+		ch := fm.bus.Subscribe(topic, 1000)
+		fm.subscribers[topic] = ch
+		fm.wg.Add(1)
+		go fm.processMessages(ch)
+	}
+
+	fm.wg.Add(1)
+	go fm.flushLoop()
+	fm.logger.Info("[FUNDING] Funding monitor started, flush interval %v", fm.flushInterval)
+	return nil
+}
+
+// Stop отписывается от шины и записывает накопленные расчеты
+func (fm *FundingMonitor) Stop() {
+	fm.cancel()
+	for topic, ch := range fm.subscribers {
+		fm.bus.Unsubscribe(topic, ch)
+	}
+	fm.wg.Wait()
+
+	if err := fm.flush(); err != nil {
+		fm.logger.Error("[FUNDING] Final flush failed: %v", err)
+	}
+	fm.logger.Info("[FUNDING] Funding monitor stopped")
+}
+
+// processMessages обновляет последние значения контрактов
+func (fm *FundingMonitor) processMessages(ch chan market.UnifiedMessage) {
+	defer fm.wg.Done()
+
+	for {
+		select {
+		case <-fm.ctx.Done():
+			return
+		case msg, ok := <-ch:
+			if !ok {
+				return
+			}
+			fm.handleMessage(msg)
+		}
+	}
+}
+
+// handleMessage применяет сообщение к состоянию контракта и ставит в запись расчет,
+// если ставка перешла на следующий период
+func (fm *FundingMonitor) handleMessage(msg market.UnifiedMessage) {
+	switch msg.MessageType {
+	case market.MessageTypeFundingRate, market.MessageTypeMarkPrice, market.MessageTypeIndexPrice:
+	default:
+		return
+	}
+
+	fm.mu.Lock()
+	defer fm.mu.Unlock()
+
+	key := fundingKey{exchange: msg.Exchange, symbol: msg.Symbol}
+	s, ok := fm.snapshots[key]
+	if !ok {
+		s = &FundingSnapshot{Exchange: msg.Exchange, Symbol: msg.Symbol}
+		fm.snapshots[key] = s
+	}
+	prev := *s
+	s.apply(msg)
+
+	if rate, ok := msg.Data.(market.UnifiedFundingRate); ok && fundingSettled(prev.NextFundingTime, rate.NextFundingTime, rate.Timestamp) {
+		fm.settlements++
+		if prev.PairID != 0 {
+			fm.pending = append(fm.pending, settlementRecord(prev))
+		}
+		fm.logger.Info("[FUNDING] %s %s settled at %s: rate %.6f%%", prev.Exchange, prev.Symbol,
+			prev.NextFundingTime.UTC().Format(time.RFC3339), prev.FundingRate*100)
+	}
+}
+
+// apply обновляет состояние контракта ставкой, маркировочной или индексной ценой
+func (s *FundingSnapshot) apply(msg market.UnifiedMessage) {
+	switch data := msg.Data.(type) {
+	case market.UnifiedFundingRate:
+		s.FundingRate = data.FundingRate
+		s.PredictedRate = data.PredictedRate
+		s.HasPredicted = data.HasPredicted
+		if !data.NextFundingTime.IsZero() {
+			s.NextFundingTime = data.NextFundingTime
+		}
+	case market.UnifiedMarkPrice:
+		s.MarkPrice = data.MarkPrice
+	case market.UnifiedIndexPrice:
+		s.IndexPrice = data.IndexPrice
+	default:
+		return
+	}
+	if msg.PairID != 0 {
+		s.PairID = msg.PairID
+	}
+	s.UpdatedAt = msg.Timestamp
+}
+
+// fundingSettled возвращает true, если время расчета перешло с prev на следующий период:
+// ставка prev списана. at - время сообщения с новым временем расчета
+func fundingSettled(prev, next, at time.Time) bool {
+	if prev.IsZero() || next.IsZero() {
+		return false
+	}
+	return next.After(prev.Add(fundingSettleSlack)) && !at.Before(prev.Add(-fundingSettleSlack))
+}
+
+// settlementRecord собирает запись расчета из состояния контракта перед переходом периода
+func settlementRecord(s FundingSnapshot) fundingRecord {
+	record := fundingRecord{
+		pairID:      s.PairID,
+		fundingTime: s.NextFundingTime,
+		fundingRate: s.FundingRate,
+	}
+	if s.HasPredicted {
+		record.predictedRate = sql.NullFloat64{Float64: s.PredictedRate, Valid: true}
+	}
+	if s.MarkPrice > 0 {
+		record.markPrice = sql.NullFloat64{Float64: s.MarkPrice, Valid: true}
+	}
+	if s.IndexPrice > 0 {
+		record.indexPrice = sql.NullFloat64{Float64: s.IndexPrice, Valid: true}
+	}
+	return record
+}
+
+// Snapshot возвращает последние значения контракта биржи; symbol - унифицированный символ (BTCUSDT)
+func (fm *FundingMonitor) Snapshot(exchange, symbol string) (FundingSnapshot, bool) {
+	fm.mu.Lock()
+	defer fm.mu.Unlock()
+	s, ok := fm.snapshots[fundingKey{exchange: exchange, symbol: symbol}]
+	if !ok {
+		return FundingSnapshot{}, false
+	}
+	return *s, true
+}
+
+// flushLoop периодически записывает расчеты
+func (fm *FundingMonitor) flushLoop() {
+	defer fm.wg.Done()
+
+	ticker := time.NewTicker(fm.flushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-fm.ctx.Done():
+			return
+		case <-ticker.C:
+			if err := fm.flush(); err != nil {
+				fm.logger.Error("[FUNDING] Failed to save funding rates: %v", err)
+			}
+		}
+	}
+}
+
+// flush записывает накопленные расчеты; при ошибке они остаются в очереди до следующей попытки
+func (fm *FundingMonitor) flush() error {
+	fm.mu.Lock()
+	records := fm.pending
+	fm.pending = nil
+	fm.mu.Unlock()
+
+	if len(records) == 0 {
+		return nil
+	}
+	if err := fm.save(records); err != nil {
+		fm.mu.Lock()
+		fm.errors++
+		fm.pending = append(records, fm.pending...)
+		fm.mu.Unlock()
+		return err
+	}
+
+	fm.mu.Lock()
+	fm.saved += int64(len(records))
+	fm.mu.Unlock()
+	fm.logger.Debug("[FUNDING] Saved %d funding settlements", len(records))
+	return nil
+}
+
+// save записывает расчеты одной транзакцией
+func (fm *FundingMonitor) save(records []fundingRecord) error {
+	tx, err := fm.db.BeginTx()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := sqlMySQL.UpsertFundingRate
+	if fm.db.GetType() == "postgres" {
+		query = sqlPostgres.UpsertFundingRate
+	}
+	stmt, err := tx.Prepare(query)
+	if err != nil {
+		return fmt.Errorf("failed to prepare insert statement: %w", err)
+	}
+	defer stmt.Close()
+
+	for _, r := range records {
+		if _, err := stmt.Exec(r.pairID, r.fundingTime, r.fundingRate, r.predictedRate, r.markPrice, r.indexPrice); err != nil {
+			return fmt.Errorf("failed to insert funding rate for pair %d: %w", r.pairID, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// GetStats возвращает статистику сбора ставок
+func (fm *FundingMonitor) GetStats() map[string]interface{} {
+	fm.mu.Lock()
+	defer fm.mu.Unlock()
+
+	return map[string]interface{}{
+		"contracts":   len(fm.snapshots),
+		"settlements": fm.settlements,
+		"saved":       fm.saved,
+		"pending":     len(fm.pending),
+		"errors":      fm.errors,
+	}
+}
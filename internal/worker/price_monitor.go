@@ -2,6 +2,7 @@ package worker
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"sync"
 	"time"
@@ -63,6 +64,7 @@ type PriceMonitor struct {
 	// Кэш последних данных orderbook по PairID
 	orderBooksMutex sync.RWMutex
 	orderBooks      map[int]*market.UnifiedOrderBook // [pairID]
+	bookEngine      *market.OrderBookEngine          // локальные стаканы для применения diff обновлений
 	subscriber      chan market.UnifiedMessage       // единый подписчик
 	monitoringPairs map[int]PriceMonitorPair         // [pairID]
 } // NewPriceMonitor создает новый экземпляр PriceMonitor
@@ -77,6 +79,7 @@ func NewPriceMonitor(dbDriver db.DBDriver, interval time.Duration) *PriceMonitor
 		cancel:          cancel,
 		interval:        interval,
 		orderBooks:      make(map[int]*market.UnifiedOrderBook),
+		bookEngine:      market.NewOrderBookEngine(5),
 		monitoringPairs: make(map[int]PriceMonitorPair),
 	}
 }
@@ -199,6 +202,20 @@ func (pm *PriceMonitor) handleOrderBookMessage(msg market.UnifiedMessage) error
 		return fmt.Errorf("invalid orderbook data type")
 	}
 
+	// Применяем снимок или diff к локальному стакану, в кэш кладем срез из 5 уровней
+	view, err := pm.bookEngine.Apply(msg.Exchange, orderBook)
+	if err != nil {
+		pm.orderBooksMutex.Lock()
+		delete(pm.orderBooks, msg.PairID)
+		pm.orderBooksMutex.Unlock()
+		if errors.Is(err, market.ErrOrderBookInvalidated) {
+			// Биржа ресинхронизирует стакан - это не ошибка обработки
+			return nil
+		}
+		return fmt.Errorf("apply orderbook update %s %s: %w", msg.Exchange, msg.Symbol, err)
+	}
+	orderBook = view
+
 	pm.orderBooksMutex.Lock()
 	defer pm.orderBooksMutex.Unlock()
 
@@ -0,0 +1,164 @@
+package worker
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"daemon-go/internal/market"
+	"daemon-go/internal/worker/executor"
+)
+
+// Типы торгов (колонка TYPE таблицы TRADE)
+const (
+	TradeTypeInterExchange = 1 // межбиржевой арбитраж одного символа
+	TradeTypeTriangular    = 2 // треугольный арбитраж внутри биржи
+	TradeTypeCashAndCarry  = 3 // спот против бессрочного контракта (cash-and-carry)
+)
+
+// MarketView - доступ стратегии к кэшам стаканов и лучших цен TradeWorker.
+// Методы вызываются под блокировкой чтения TradeWorker и не должны сохраняться стратегией
+type MarketView interface {
+	Exchanges() []string // разрешенные биржи
+	Symbols() []string   // символы, по которым есть данные (без заблокированных)
+	OrderBook(exchange, symbol string) *market.UnifiedOrderBook
+	BestPrice(exchange, symbol string) *market.UnifiedBestPrice
+	Fees(exchange, symbol string) market.FeeRate // комиссии пары на бирже
+	// Balance возвращает доступный баланс актива на бирже; ok=false - балансы не отслеживаются
+	// (режим мониторинга), объем не ограничивается
+	Balance(exchange, asset string) (available float64, ok bool)
+}
+
+// PerpetualView - доступ стратегии к кэшам бессрочных контрактов TradeWorker (символы BTCUSDT).
+// Реализуется тем же MarketView; стратегии, которым он нужен, получают его приведением типа
+type PerpetualView interface {
+	PerpOrderBook(exchange, symbol string) *market.UnifiedOrderBook
+	Funding(exchange, symbol string) (FundingSnapshot, bool) // ставка, маркировочная и индексная цены
+}
+
+// BalanceView - доступные балансы аккаунтов бирж (balance.Service)
+type BalanceView interface {
+	Available(exchange, asset string) (float64, bool)
+}
+
+// Strategy - стратегия поиска торговых сигналов.
+// TradeWorker уведомляет стратегию об обновлениях стаканов и периодически вызывает Scan;
+// найденные сигналы сохраняются и, если включено исполнение, передаются в executor
+type Strategy interface {
+	Name() string
+	// OnBookUpdate вызывается после обновления стакана или лучшей цены (из горутин обработки бирж)
+	OnBookUpdate(exchange, symbol string)
+	// Scan выполняет проход поиска и возвращает сигналы
+	Scan(view MarketView) []ArbitrageOpportunity
+}
+
+// ExecutionObserver - стратегия, которой нужен итог исполнения собственных сигналов
+// (позиционные стратегии). Вызывается после исполнения, кроме задач без исполненного объема
+type ExecutionObserver interface {
+	OnExecuted(opportunity ArbitrageOpportunity, result executor.ExecutionResult)
+}
+
+// StrategyFactory создает стратегию для конфигурации TradeWorker
+type StrategyFactory func(config *TradeWorkerConfig) Strategy
+
+var (
+	strategiesMu sync.RWMutex
+	strategies   = map[int]StrategyFactory{
+		TradeTypeInterExchange: func(config *TradeWorkerConfig) Strategy { return NewInterExchangeStrategy(config) },
+		TradeTypeTriangular:    func(config *TradeWorkerConfig) Strategy { return NewTriangularStrategy(config) },
+		TradeTypeCashAndCarry:  func(config *TradeWorkerConfig) Strategy { return NewCarryStrategy(config) },
+	}
+)
+
+// RegisterStrategy регистрирует фабрику стратегии для типа торгов
+func RegisterStrategy(tradeType int, factory StrategyFactory) {
+	strategiesMu.Lock()
+	defer strategiesMu.Unlock()
+	strategies[tradeType] = factory
+}
+
+// NewStrategy создает стратегию по типу торгов из TRADE.TYPE
+func NewStrategy(tradeType int, config *TradeWorkerConfig) (Strategy, error) {
+	strategiesMu.RLock()
+	factory, ok := strategies[tradeType]
+	strategiesMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("no strategy registered for trade type %d", tradeType)
+	}
+	if config == nil {
+		config = DefaultTradeWorkerConfig()
+	}
+	return factory(config), nil
+}
+
+// RegisteredTradeTypes возвращает типы торгов, для которых есть стратегия
+func RegisteredTradeTypes() []int {
+	strategiesMu.RLock()
+	defer strategiesMu.RUnlock()
+	types := make([]int, 0, len(strategies))
+	for t := range strategies {
+		types = append(types, t)
+	}
+	sort.Ints(types)
+	return types
+}
+
+// tradeWorkerView - MarketView поверх кэшей TradeWorker (вызывающий держит tw.mu)
+type tradeWorkerView struct {
+	tw *TradeWorker
+}
+
+func (v tradeWorkerView) Exchanges() []string {
+	return v.tw.config.AllowedExchanges
+}
+
+func (v tradeWorkerView) Symbols() []string {
+	return v.tw.getAllSymbols()
+}
+
+func (v tradeWorkerView) OrderBook(exchange, symbol string) *market.UnifiedOrderBook {
+	return v.tw.orderBooks[exchange][symbol]
+}
+
+func (v tradeWorkerView) BestPrice(exchange, symbol string) *market.UnifiedBestPrice {
+	return v.tw.bestPrices[exchange][symbol]
+}
+
+func (v tradeWorkerView) Fees(exchange, symbol string) market.FeeRate {
+	return v.tw.fees.Get(exchange, symbol)
+}
+
+func (v tradeWorkerView) Balance(exchange, asset string) (float64, bool) {
+	if v.tw.balances == nil {
+		return 0, false
+	}
+	return v.tw.balances.Available(exchange, asset)
+}
+
+func (v tradeWorkerView) PerpOrderBook(exchange, symbol string) *market.UnifiedOrderBook {
+	return v.tw.perpBooks[exchange][symbol]
+}
+
+func (v tradeWorkerView) Funding(exchange, symbol string) (FundingSnapshot, bool) {
+	s, ok := v.tw.perpFunding[exchange][symbol]
+	if !ok {
+		return FundingSnapshot{}, false
+	}
+	return *s, true
+}
+
+// bookLevels возвращает уровни стакана; без стакана используется лучшая цена как единственный уровень
+func bookLevels(view MarketView, exchange, symbol string) (bids, asks []market.PriceLevel) {
+	if ob := view.OrderBook(exchange, symbol); ob != nil {
+		return ob.Bids, ob.Asks
+	}
+	if bp := view.BestPrice(exchange, symbol); bp != nil {
+		if bp.BestBid > 0 {
+			bids = []market.PriceLevel{{Price: bp.BestBid, Volume: bp.BidVolume}}
+		}
+		if bp.BestAsk > 0 {
+			asks = []market.PriceLevel{{Price: bp.BestAsk, Volume: bp.AskVolume}}
+		}
+	}
+	return bids, asks
+}
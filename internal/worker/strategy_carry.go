@@ -0,0 +1,638 @@
+package worker
+
+import (
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"daemon-go/internal/bus"
+	"daemon-go/internal/market"
+	"daemon-go/internal/worker/executor"
+	"daemon-go/pkg/log"
+)
+
+// carryDustVolume - остаток объема позиции, при котором она считается закрытой
+const carryDustVolume = 1e-9
+
+const (
+	// carryFundingPollInterval - период запроса расчетов финансирования по контрактам открытых позиций
+	carryFundingPollInterval = 5 * time.Minute
+	// carryFundingSettleDelay - задержка запроса после перехода ставки на следующий период:
+	// биржа записывает расчет в историю не сразу
+	carryFundingSettleDelay = 30 * time.Second
+)
+
+// Статусы позиций cash-and-carry
+const (
+	CarryStatusOpen   = "open"
+	CarryStatusClosed = "closed"
+)
+
+// CarryPosition - позиция cash-and-carry: длинный спот и короткий бессрочный контракт того же
+// объема. PnL ведется раздельно: SpreadPnL - итог исполнений ног входа и выхода с комиссиями,
+// CarryPnL - полученное (отрицательное - уплаченное) шортом финансирование по расчетам биржи
+type CarryPosition struct {
+	ID              string    `json:"id"` // TaskID исполнения, открывшего позицию
+	Symbol          string    `json:"symbol"`
+	PerpSymbol      string    `json:"perp_symbol"`
+	SpotExchange    string    `json:"spot_exchange"`
+	PerpExchange    string    `json:"perp_exchange"`
+	Volume          float64   `json:"volume"`        // открытый объем в base валюте
+	ClosedVolume    float64   `json:"closed_volume"` // закрытый объем
+	SpotEntryPrice  float64   `json:"spot_entry_price"`
+	PerpEntryPrice  float64   `json:"perp_entry_price"`
+	SpotExitPrice   float64   `json:"spot_exit_price"`
+	PerpExitPrice   float64   `json:"perp_exit_price"`
+	SpreadPnL       float64   `json:"spread_pnl"`
+	CarryPnL        float64   `json:"carry_pnl"`
+	FundingEvents   int       `json:"funding_events"`
+	FundingRate     float64   `json:"funding_rate"` // ставка ближайшего расчета
+	NextFundingTime time.Time `json:"next_funding_time"`
+	LastFundingAt   time.Time `json:"last_funding_at,omitempty"` // время последнего учтенного расчета биржи
+	Status          string    `json:"status"`
+	OpenedAt        time.Time `json:"opened_at"`
+	ClosedAt        time.Time `json:"closed_at,omitempty"`
+	UpdatedAt       time.Time `json:"updated_at"`
+}
+
+// CarryJournal - постоянное хранилище позиций cash-and-carry (orders.DBJournal).
+// Позиция ключуется по ID, каждое сохранение перезаписывает ее состояние
+type CarryJournal interface {
+	SaveCarryPosition(position CarryPosition) error
+	LoadOpenCarryPositions() ([]CarryPosition, error)
+}
+
+// CarryStrategy - cash-and-carry: покупка спота и продажа бессрочного контракта того же актива
+// на той же или другой бирже, когда годовая доходность ставки финансирования и базиса выше
+// CarryEntryAPR. Базис раскладывается на CarryHoldingDays, комиссии закрытия вычитаются при входе.
+// Позиция закрывается (продажа спота, откуп контракта), когда доходность опускается ниже
+// CarryExitAPR. ProfitPercent сигнала - доходность в процентах годовых
+type CarryStrategy struct {
+	config *TradeWorkerConfig
+	logger *log.Logger
+
+	mu           sync.Mutex
+	positions    map[string]*CarryPosition // [symbol:spot:perp] открытые позиции
+	saves        chan CarryPosition        // очередь записи в журнал (nil - журнал не задан)
+	funding      map[string]FundingHistory // [биржа контракта] расчеты финансирования аккаунта
+	fundingPolls map[string]time.Time      // [биржа:контракт] время следующего запроса расчетов
+	fetching     map[string]bool           // [биржа:контракт] запрос расчетов выполняется
+}
+
+// FundingHistory - история расчетов финансирования аккаунта на бирже бессрочных контрактов
+// (exchange.FundingHistorySource торгового адаптера контрактов)
+type FundingHistory interface {
+	GetFundingPayments(symbol string, since time.Time) ([]market.FundingPayment, error)
+}
+
+// NewCarryStrategy создает стратегию cash-and-carry
+func NewCarryStrategy(config *TradeWorkerConfig) *CarryStrategy {
+	return &CarryStrategy{
+		config:       config,
+		logger:       log.New("carry_strategy"),
+		positions:    make(map[string]*CarryPosition),
+		funding:      make(map[string]FundingHistory),
+		fundingPolls: make(map[string]time.Time),
+		fetching:     make(map[string]bool),
+	}
+}
+
+// Name возвращает имя стратегии
+func (s *CarryStrategy) Name() string {
+	return "cash_and_carry"
+}
+
+// OnBookUpdate не используется: стратегия пересчитывает все пары на каждом проходе
+func (s *CarryStrategy) OnBookUpdate(exchange, symbol string) {}
+
+// SetJournal загружает открытые позиции из журнала и сохраняет в него изменения позиций.
+// Запись идет в отдельной горутине, чтобы не задерживать проход поиска
+func (s *CarryStrategy) SetJournal(journal CarryJournal) error {
+	positions, err := journal.LoadOpenCarryPositions()
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := range positions {
+		p := positions[i]
+		s.positions[carryKey(p.Symbol, p.SpotExchange, p.PerpExchange)] = &p
+	}
+	if s.saves == nil {
+		s.saves = make(chan CarryPosition, 1000)
+		go s.journalLoop(journal, s.saves)
+	}
+	s.logger.Info("[CARRY] Loaded %d open positions", len(positions))
+	return nil
+}
+
+// SetFundingHistory задает источник расчетов финансирования биржи контрактов. Без него CarryPnL
+// позиций на этой бирже не начисляется: оценка по ставке не заменяет фактический платеж
+func (s *CarryStrategy) SetFundingHistory(exchange string, history FundingHistory) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.funding[exchange] = history
+}
+
+// journalLoop записывает изменения позиций в журнал
+func (s *CarryStrategy) journalLoop(journal CarryJournal, saves chan CarryPosition) {
+	for p := range saves {
+		if err := journal.SaveCarryPosition(p); err != nil {
+			s.logger.Error("[CARRY] Failed to save position %s: %v", p.ID, err)
+		}
+	}
+}
+
+// save ставит состояние позиции в очередь записи (вызывающий держит s.mu)
+func (s *CarryStrategy) save(p *CarryPosition) {
+	if s.saves == nil {
+		return
+	}
+	select {
+	case s.saves <- *p:
+	default:
+		s.logger.Warn("[CARRY] Journal queue is full, position %s not saved", p.ID)
+	}
+}
+
+// Positions возвращает открытые позиции
+func (s *CarryStrategy) Positions() []CarryPosition {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	positions := make([]CarryPosition, 0, len(s.positions))
+	for _, p := range s.positions {
+		positions = append(positions, *p)
+	}
+	sort.Slice(positions, func(i, j int) bool { return positions[i].OpenedAt.Before(positions[j].OpenedAt) })
+	return positions
+}
+
+// Scan запрашивает расчеты финансирования по открытым позициям, ищет позиции для закрытия и новые входы
+func (s *CarryStrategy) Scan(view MarketView) []ArbitrageOpportunity {
+	perps, ok := view.(PerpetualView)
+	if !ok {
+		return nil
+	}
+	now := time.Now()
+	opportunities := make([]ArbitrageOpportunity, 0)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, p := range s.positions {
+		funding, ok := perps.Funding(p.PerpExchange, p.PerpSymbol)
+		if ok {
+			s.trackFunding(p, funding, now)
+		}
+		s.pollFunding(p, now)
+		if !ok {
+			continue
+		}
+		if opp := s.evaluateExit(view, perps, p, funding, now); opp != nil {
+			opportunities = append(opportunities, *opp)
+		}
+	}
+
+	for _, symbol := range view.Symbols() {
+		perpSymbol := carryPerpSymbol(symbol)
+		if perpSymbol == "" {
+			continue
+		}
+		for _, spotExchange := range view.Exchanges() {
+			for _, perpExchange := range view.Exchanges() {
+				if s.positions[carryKey(symbol, spotExchange, perpExchange)] != nil {
+					continue
+				}
+				if opp := s.evaluateEntry(view, perps, symbol, perpSymbol, spotExchange, perpExchange, now); opp != nil {
+					opportunities = append(opportunities, *opp)
+				}
+			}
+		}
+	}
+	return opportunities
+}
+
+// trackFunding запоминает ставку и время ближайшего расчета. Когда ставка переходит на следующий
+// период, расчеты по контракту запрашиваются у биржи через carryFundingSettleDelay
+// (вызывающий держит s.mu)
+func (s *CarryStrategy) trackFunding(p *CarryPosition, funding FundingSnapshot, now time.Time) {
+	if fundingSettled(p.NextFundingTime, funding.NextFundingTime, now) {
+		key := carryContractKey(p.PerpExchange, p.PerpSymbol)
+		if poll := now.Add(carryFundingSettleDelay); poll.Before(s.fundingPolls[key]) {
+			s.fundingPolls[key] = poll
+		}
+	}
+	if !funding.NextFundingTime.IsZero() {
+		p.NextFundingTime = funding.NextFundingTime
+		p.FundingRate = funding.FundingRate
+	}
+}
+
+// pollFunding запрашивает у биржи расчеты финансирования контракта позиции, если подошло время
+// опроса (вызывающий держит s.mu). Первый запрос после запуска догружает расчеты с последнего
+// учтенного; запрос выполняется в отдельной горутине, чтобы не задерживать проход поиска
+func (s *CarryStrategy) pollFunding(p *CarryPosition, now time.Time) {
+	history := s.funding[p.PerpExchange]
+	key := carryContractKey(p.PerpExchange, p.PerpSymbol)
+	if history == nil || s.fetching[key] || now.Before(s.fundingPolls[key]) {
+		return
+	}
+	s.fetching[key] = true
+	s.fundingPolls[key] = now.Add(carryFundingPollInterval)
+	go s.fetchFunding(history, p.PerpExchange, p.PerpSymbol, s.fundingCursor(p.PerpExchange, p.PerpSymbol))
+}
+
+// fetchFunding загружает расчеты финансирования контракта с момента since и начисляет их позициям
+func (s *CarryStrategy) fetchFunding(history FundingHistory, perpExchange, perpSymbol string, since time.Time) {
+	payments, err := history.GetFundingPayments(perpSymbol, since)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.fetching, carryContractKey(perpExchange, perpSymbol))
+	if err != nil {
+		s.logger.Warn("[CARRY] Failed to load funding payments %s %s: %v", perpExchange, perpSymbol, err)
+		return
+	}
+	s.applyFunding(perpExchange, perpSymbol, payments, time.Now())
+}
+
+// fundingCursor возвращает момент, с которого нужны расчеты контракта: самый ранний из последних
+// учтенных расчетов его позиций (для позиций без расчетов - время открытия)
+func (s *CarryStrategy) fundingCursor(perpExchange, perpSymbol string) time.Time {
+	var cursor time.Time
+	for _, p := range s.positions {
+		if p.PerpExchange != perpExchange || p.PerpSymbol != perpSymbol {
+			continue
+		}
+		since := p.LastFundingAt
+		if since.IsZero() {
+			since = p.OpenedAt
+		}
+		if cursor.IsZero() || since.Before(cursor) {
+			cursor = since
+		}
+	}
+	return cursor
+}
+
+// applyFunding начисляет позициям контракта расчеты финансирования биржи (вызывающий держит s.mu).
+// Расчет относится к позициям, открытым до него и еще не получившим его. Биржа начисляет платеж
+// по всей позиции аккаунта в контракте, поэтому он делится между позициями пропорционально объему
+func (s *CarryStrategy) applyFunding(perpExchange, perpSymbol string, payments []market.FundingPayment, now time.Time) {
+	sort.Slice(payments, func(i, j int) bool { return payments[i].Time.Before(payments[j].Time) })
+	for _, payment := range payments {
+		var settled []*CarryPosition
+		volume := 0.0
+		for _, p := range s.positions {
+			if p.PerpExchange != perpExchange || p.PerpSymbol != perpSymbol ||
+				p.OpenedAt.After(payment.Time) || !payment.Time.After(p.LastFundingAt) {
+				continue
+			}
+			settled = append(settled, p)
+			volume += p.Volume
+		}
+		if volume <= 0 {
+			continue
+		}
+		for _, p := range settled {
+			amount := payment.Amount * p.Volume / volume
+			p.CarryPnL += amount
+			p.FundingEvents++
+			p.LastFundingAt = payment.Time
+			p.UpdatedAt = now
+			s.save(p)
+			s.logger.Info("[CARRY] %s %s/%s funding settled at %s: payment %.6f %s, carry PnL %.6f",
+				p.Symbol, p.SpotExchange, p.PerpExchange, payment.Time.Format(time.RFC3339), amount, payment.Asset, p.CarryPnL)
+		}
+	}
+}
+
+// evaluateEntry рассчитывает вход: покупка спота по asks и продажа контракта по bids, пока
+// базис после комиссий вместе со ставкой дает не меньше CarryEntryAPR годовых
+func (s *CarryStrategy) evaluateEntry(view MarketView, perps PerpetualView, symbol, perpSymbol, spotExchange, perpExchange string, now time.Time) *ArbitrageOpportunity {
+	book := perps.PerpOrderBook(perpExchange, perpSymbol)
+	funding, ok := perps.Funding(perpExchange, perpSymbol)
+	if book == nil || !ok || len(book.Bids) == 0 {
+		return nil
+	}
+	_, spotAsks := bookLevels(view, spotExchange, symbol)
+	if len(spotAsks) == 0 {
+		return nil
+	}
+
+	perpVenue := bus.FuturesTopic(perpExchange)
+	spotFee := view.Fees(spotExchange, symbol).Taker
+	perpFee := view.Fees(perpVenue, perpSymbol).Taker
+	exitFees := (spotFee + perpFee) * 100
+
+	// Минимальный базис, при котором ставка и базис за горизонт дают CarryEntryAPR;
+	// комиссии закрытия позиции закладываются сразу
+	fundingAPR := s.fundingAPR(funding)
+	minBasis := (s.config.CarryEntryAPR-fundingAPR)*s.holdingDays()/365 + exitFees
+
+	maxCost, ok := s.entryCost(view, symbol, spotExchange, perpExchange)
+	if !ok {
+		return nil
+	}
+	fill := walkSpread(spotAsks, book.Bids, spotFee, perpFee, minBasis, maxCost, 0)
+	if fill.volume <= 0 || fill.cost < s.config.MinVolumeUSDT {
+		return nil
+	}
+
+	basis := (fill.revenue-fill.cost)/fill.cost*100 - exitFees
+	apr := fundingAPR + s.annualize(basis)
+	if apr < s.config.CarryEntryAPR {
+		return nil
+	}
+
+	return &ArbitrageOpportunity{
+		Strategy:        s.Name(),
+		Symbol:          symbol,
+		BuyExchange:     spotExchange,
+		SellExchange:    perpVenue,
+		BuyPrice:        fill.buyLimit,
+		SellPrice:       fill.sellLimit,
+		Spread:          fill.sellLimit - fill.buyLimit,
+		ProfitPercent:   apr,
+		BuyVolume:       spotAsks[0].Volume,
+		SellVolume:      book.Bids[0].Volume,
+		MaxVolume:       fill.volume,
+		Timestamp:       now,
+		BuyOrderBook:    view.OrderBook(spotExchange, symbol),
+		SellOrderBook:   book,
+		EstimatedProfit: fill.cost * apr / 100 * s.holdingDays() / 365,
+		Legs:            carryLegs(symbol, perpSymbol, spotExchange, perpVenue, market.TradeSideBuy, fill),
+	}
+}
+
+// entryCost возвращает предел затрат на покупку спота (0 - без ограничения): MaxVolumeUSDT,
+// баланс quote валюты на бирже спота и маржа на бирже контракта с учетом CarryLeverage.
+// Если спот и контракт на одной бирже, баланс делится между покупкой и маржой.
+// ok=false - баланса не хватает на вход
+func (s *CarryStrategy) entryCost(view MarketView, symbol, spotExchange, perpExchange string) (float64, bool) {
+	maxCost := s.config.MaxVolumeUSDT
+	limited := maxCost > 0
+	limit := func(cost float64) {
+		if !limited || cost < maxCost {
+			maxCost, limited = cost, true
+		}
+	}
+
+	_, quote := symbolCurrencies(view, spotExchange, symbol)
+	leverage := s.config.CarryLeverage
+	if leverage <= 0 {
+		leverage = 1
+	}
+	spotBalance, spotOK := view.Balance(spotExchange, quote)
+	if spotExchange == perpExchange {
+		if spotOK {
+			limit(spotBalance * leverage / (leverage + 1))
+		}
+	} else {
+		if spotOK {
+			limit(spotBalance)
+		}
+		if margin, ok := view.Balance(perpExchange, quote); ok {
+			limit(margin * leverage)
+		}
+	}
+	return maxCost, !limited || maxCost > 0
+}
+
+// evaluateExit рассчитывает закрытие позиции, если ставка и текущий базис по средним ценам
+// дают меньше CarryExitAPR годовых: продажа спота по bids и откуп контракта по asks с убытком
+// спреда не больше CarryUnwindSlippagePercent
+func (s *CarryStrategy) evaluateExit(view MarketView, perps PerpetualView, p *CarryPosition, funding FundingSnapshot, now time.Time) *ArbitrageOpportunity {
+	book := perps.PerpOrderBook(p.PerpExchange, p.PerpSymbol)
+	if book == nil || len(book.Bids) == 0 || len(book.Asks) == 0 {
+		return nil
+	}
+	spotBids, spotAsks := bookLevels(view, p.SpotExchange, p.Symbol)
+	if len(spotBids) == 0 || len(spotAsks) == 0 {
+		return nil
+	}
+
+	spotMid := (spotBids[0].Price + spotAsks[0].Price) / 2
+	perpMid := (book.Bids[0].Price + book.Asks[0].Price) / 2
+	basis := (perpMid - spotMid) / spotMid * 100
+	apr := s.fundingAPR(funding) + s.annualize(basis)
+	if apr >= s.config.CarryExitAPR {
+		return nil
+	}
+
+	perpVenue := bus.FuturesTopic(p.PerpExchange)
+	spotFee := view.Fees(p.SpotExchange, p.Symbol).Taker
+	perpFee := view.Fees(perpVenue, p.PerpSymbol).Taker
+	fill := walkSpread(book.Asks, spotBids, perpFee, spotFee, -s.config.CarryUnwindSlippagePercent, 0, p.Volume)
+	if fill.volume <= 0 {
+		return nil
+	}
+
+	return &ArbitrageOpportunity{
+		Strategy:        s.Name(),
+		Symbol:          p.Symbol,
+		BuyExchange:     perpVenue,
+		SellExchange:    p.SpotExchange,
+		BuyPrice:        fill.buyLimit,
+		SellPrice:       fill.sellLimit,
+		Spread:          fill.sellLimit - fill.buyLimit,
+		ProfitPercent:   apr,
+		BuyVolume:       book.Asks[0].Volume,
+		SellVolume:      spotBids[0].Volume,
+		MaxVolume:       fill.volume,
+		Timestamp:       now,
+		BuyOrderBook:    book,
+		SellOrderBook:   view.OrderBook(p.SpotExchange, p.Symbol),
+		EstimatedProfit: fill.revenue - fill.cost,
+		Legs:            carryLegs(p.Symbol, p.PerpSymbol, p.SpotExchange, perpVenue, market.TradeSideSell, fill),
+	}
+}
+
+// OnExecuted открывает позицию по исполненному входу или уменьшает ее по исполненному выходу.
+// Объем позиции - хеджированная часть: минимум из спота и контракта с учетом хеджирующих ордеров
+func (s *CarryStrategy) OnExecuted(opportunity ArbitrageOpportunity, result executor.ExecutionResult) {
+	perpExchange, entry := bus.FuturesExchange(opportunity.SellExchange)
+	spotExchange := opportunity.BuyExchange
+	if !entry {
+		var ok bool
+		if perpExchange, ok = bus.FuturesExchange(opportunity.BuyExchange); !ok {
+			return
+		}
+		spotExchange = opportunity.SellExchange
+	}
+	fills := collectCarryFills(append(result.Legs, result.Imbalance.Legs()...))
+	now := time.Now()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := carryKey(opportunity.Symbol, spotExchange, perpExchange)
+	p := s.positions[key]
+
+	if entry {
+		volume := math.Min(fills.spotBuy.volume-fills.spotSell.volume, fills.perpSell.volume-fills.perpBuy.volume)
+		if volume <= carryDustVolume {
+			return
+		}
+		if p == nil {
+			p = &CarryPosition{
+				ID:           result.TaskID,
+				Symbol:       opportunity.Symbol,
+				PerpSymbol:   carryPerpSymbol(opportunity.Symbol),
+				SpotExchange: spotExchange,
+				PerpExchange: perpExchange,
+				Status:       CarryStatusOpen,
+				OpenedAt:     now,
+			}
+			s.positions[key] = p
+		}
+		p.SpotEntryPrice = weightedPrice(p.SpotEntryPrice, p.Volume, fills.spotBuy.price(), volume)
+		p.PerpEntryPrice = weightedPrice(p.PerpEntryPrice, p.Volume, fills.perpSell.price(), volume)
+		p.Volume += volume
+		p.SpreadPnL += result.RealizedProfit
+		p.UpdatedAt = now
+		s.save(p)
+		s.logger.Info("[CARRY] Opened %s %.8f spot %s @ %.8f / perp %s @ %.8f, spread PnL %.6f",
+			p.Symbol, volume, spotExchange, p.SpotEntryPrice, perpExchange, p.PerpEntryPrice, p.SpreadPnL)
+		return
+	}
+
+	if p == nil {
+		return
+	}
+	volume := math.Min(fills.spotSell.volume-fills.spotBuy.volume, fills.perpBuy.volume-fills.perpSell.volume)
+	if volume <= carryDustVolume {
+		return
+	}
+	volume = math.Min(volume, p.Volume)
+	p.SpotExitPrice = weightedPrice(p.SpotExitPrice, p.ClosedVolume, fills.spotSell.price(), volume)
+	p.PerpExitPrice = weightedPrice(p.PerpExitPrice, p.ClosedVolume, fills.perpBuy.price(), volume)
+	p.ClosedVolume += volume
+	p.Volume -= volume
+	p.SpreadPnL += result.RealizedProfit
+	p.UpdatedAt = now
+	if p.Volume <= carryDustVolume {
+		p.Volume = 0
+		p.Status = CarryStatusClosed
+		p.ClosedAt = now
+		delete(s.positions, key)
+	}
+	s.save(p)
+	s.logger.Info("[CARRY] Unwound %s %.8f (%s): spread PnL %.6f, carry PnL %.6f over %d fundings",
+		p.Symbol, volume, p.Status, p.SpreadPnL, p.CarryPnL, p.FundingEvents)
+}
+
+// fundingAPR возвращает ставку финансирования в процентах годовых; при известном прогнозе
+// берется среднее текущей и следующей ставки
+func (s *CarryStrategy) fundingAPR(funding FundingSnapshot) float64 {
+	rate := funding.FundingRate
+	if funding.HasPredicted {
+		rate = (rate + funding.PredictedRate) / 2
+	}
+	interval := s.config.CarryFundingIntervalHours
+	if interval <= 0 {
+		interval = 8
+	}
+	return rate * 365 * 24 / interval * 100
+}
+
+// annualize переводит базис в процентах за горизонт удержания в проценты годовых
+func (s *CarryStrategy) annualize(basisPercent float64) float64 {
+	return basisPercent * 365 / s.holdingDays()
+}
+
+// holdingDays возвращает горизонт удержания позиции в днях
+func (s *CarryStrategy) holdingDays() float64 {
+	if s.config.CarryHoldingDays <= 0 {
+		return 7
+	}
+	return s.config.CarryHoldingDays
+}
+
+// carryKey - позиция по символу, бирже спота и бирже контракта
+func carryKey(symbol, spotExchange, perpExchange string) string {
+	return symbol + ":" + spotExchange + ":" + perpExchange
+}
+
+// carryContractKey - контракт биржи, по которому запрашиваются расчеты финансирования
+func carryContractKey(perpExchange, perpSymbol string) string {
+	return perpExchange + ":" + perpSymbol
+}
+
+// carryPerpSymbol возвращает символ бессрочного контракта для спотового символа (BTC/USDT -> BTCUSDT)
+func carryPerpSymbol(symbol string) string {
+	unified, err := market.ParseSymbol(symbol, market.MarketTypeSpot)
+	if err != nil {
+		return ""
+	}
+	return unified.BaseCurrency + unified.QuoteCurrency
+}
+
+// carryLegs собирает ноги сделки: spotSide на споте и противоположная сторона на контракте
+func carryLegs(symbol, perpSymbol, spotExchange, perpVenue string, spotSide market.TradeSide, fill spreadFill) []executor.LegRequest {
+	spot := market.OrderRequest{Symbol: symbol, Side: spotSide, OrderType: market.OrderTypeLimit, Volume: fill.volume}
+	perp := market.OrderRequest{Symbol: perpSymbol, OrderType: market.OrderTypeLimit, Volume: fill.volume}
+	if spotSide == market.TradeSideBuy {
+		spot.Price, perp.Side, perp.Price = fill.buyLimit, market.TradeSideSell, fill.sellLimit
+		return []executor.LegRequest{{Exchange: spotExchange, Request: spot}, {Exchange: perpVenue, Request: perp}}
+	}
+	spot.Price, perp.Side, perp.Price = fill.sellLimit, market.TradeSideBuy, fill.buyLimit
+	return []executor.LegRequest{{Exchange: perpVenue, Request: perp}, {Exchange: spotExchange, Request: spot}}
+}
+
+// carryFill - исполненный объем одной стороны рынка и его стоимость
+type carryFill struct {
+	volume float64
+	quote  float64
+}
+
+func (f *carryFill) add(volume, price float64) {
+	f.volume += volume
+	f.quote += volume * price
+}
+
+// price возвращает среднюю цену исполнения
+func (f carryFill) price() float64 {
+	if f.volume <= 0 {
+		return 0
+	}
+	return f.quote / f.volume
+}
+
+// carryFills - исполнения сделки по споту и контракту
+type carryFills struct {
+	spotBuy, spotSell, perpBuy, perpSell carryFill
+}
+
+// collectCarryFills разносит исполнения ног по споту и контракту (ноги на FuturesTopic биржи)
+func collectCarryFills(legs []executor.LegResult) carryFills {
+	var fills carryFills
+	for _, leg := range legs {
+		if leg.FilledVolume <= 0 {
+			continue
+		}
+		_, perp := bus.FuturesExchange(leg.Leg.Exchange)
+		buy := leg.Leg.Request.Side == market.TradeSideBuy
+		switch {
+		case perp && buy:
+			fills.perpBuy.add(leg.FilledVolume, leg.AvgPrice)
+		case perp:
+			fills.perpSell.add(leg.FilledVolume, leg.AvgPrice)
+		case buy:
+			fills.spotBuy.add(leg.FilledVolume, leg.AvgPrice)
+		default:
+			fills.spotSell.add(leg.FilledVolume, leg.AvgPrice)
+		}
+	}
+	return fills
+}
+
+// weightedPrice усредняет цену price объема volume с ценой current объема currentVolume
+func weightedPrice(current, currentVolume, price, volume float64) float64 {
+	if currentVolume+volume <= 0 {
+		return 0
+	}
+	return (current*currentVolume + price*volume) / (currentVolume + volume)
+}
@@ -0,0 +1,296 @@
+package worker
+
+import (
+	"math"
+	"sync"
+	"testing"
+	"time"
+
+	"daemon-go/internal/bus"
+	"daemon-go/internal/market"
+	"daemon-go/internal/worker/executor"
+)
+
+// testView - MarketView и PerpetualView поверх заданных стаканов; комиссия taker одна на все пары
+type testView struct {
+	exchanges []string
+	books     map[string]*market.UnifiedOrderBook // [exchange:symbol] спот
+	perps     map[string]*market.UnifiedOrderBook // [exchange:symbol] контракты
+	funding   map[string]FundingSnapshot          // [exchange:symbol]
+	balances  map[string]float64                  // [exchange:asset] (nil - не отслеживаются)
+	fee       float64
+}
+
+func newTestView(exchanges ...string) *testView {
+	return &testView{
+		exchanges: exchanges,
+		books:     make(map[string]*market.UnifiedOrderBook),
+		perps:     make(map[string]*market.UnifiedOrderBook),
+		funding:   make(map[string]FundingSnapshot),
+		fee:       0.001,
+	}
+}
+
+// testBook - стакан из уровней {цена, объем}
+func testBook(symbol string, bids, asks [][2]float64) *market.UnifiedOrderBook {
+	book := &market.UnifiedOrderBook{Symbol: symbol}
+	for _, l := range bids {
+		book.Bids = append(book.Bids, market.PriceLevel{Price: l[0], Volume: l[1]})
+	}
+	for _, l := range asks {
+		book.Asks = append(book.Asks, market.PriceLevel{Price: l[0], Volume: l[1]})
+	}
+	return book
+}
+
+func (v *testView) Exchanges() []string { return v.exchanges }
+
+func (v *testView) Symbols() []string {
+	seen := make(map[string]bool)
+	var symbols []string
+	for _, book := range v.books {
+		if !seen[book.Symbol] {
+			seen[book.Symbol] = true
+			symbols = append(symbols, book.Symbol)
+		}
+	}
+	return symbols
+}
+
+func (v *testView) OrderBook(exchange, symbol string) *market.UnifiedOrderBook {
+	return v.books[exchange+":"+symbol]
+}
+
+func (v *testView) BestPrice(exchange, symbol string) *market.UnifiedBestPrice { return nil }
+
+func (v *testView) Fees(exchange, symbol string) market.FeeRate {
+	return market.FeeRate{Maker: v.fee, Taker: v.fee}
+}
+
+func (v *testView) Balance(exchange, asset string) (float64, bool) {
+	if v.balances == nil {
+		return 0, false
+	}
+	return v.balances[exchange+":"+asset], true
+}
+
+func (v *testView) PerpOrderBook(exchange, symbol string) *market.UnifiedOrderBook {
+	return v.perps[exchange+":"+symbol]
+}
+
+func (v *testView) Funding(exchange, symbol string) (FundingSnapshot, bool) {
+	f, ok := v.funding[exchange+":"+symbol]
+	return f, ok
+}
+
+// testFundingHistory - расчеты финансирования биржи; since запоминается
+type testFundingHistory struct {
+	mu       sync.Mutex
+	payments []market.FundingPayment
+	since    []time.Time
+}
+
+func (h *testFundingHistory) GetFundingPayments(symbol string, since time.Time) ([]market.FundingPayment, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.since = append(h.since, since)
+	return append([]market.FundingPayment(nil), h.payments...), nil
+}
+
+func TestCarryStrategyEntry(t *testing.T) {
+	tests := []struct {
+		name    string
+		perpBid float64
+		rate    float64
+		want    bool
+	}{
+		{name: "basis and funding above entry APR", perpBid: 101, rate: 0.0001, want: true},
+		{name: "basis below fees", perpBid: 100.2, rate: 0.0001, want: false},
+		{name: "negative funding eats basis", perpBid: 100.5, rate: -0.001, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			view := newTestView("binance")
+			view.books["binance:BTC/USDT"] = testBook("BTC/USDT", [][2]float64{{99.9, 5}}, [][2]float64{{100, 5}})
+			view.perps["binance:BTCUSDT"] = testBook("BTCUSDT", [][2]float64{{tt.perpBid, 5}}, [][2]float64{{tt.perpBid + 0.1, 5}})
+			view.funding["binance:BTCUSDT"] = FundingSnapshot{FundingRate: tt.rate}
+
+			opps := NewCarryStrategy(DefaultTradeWorkerConfig()).Scan(view)
+			if !tt.want {
+				if len(opps) != 0 {
+					t.Fatalf("unexpected entry: %+v", opps[0])
+				}
+				return
+			}
+			if len(opps) != 1 {
+				t.Fatalf("got %d opportunities, want 1", len(opps))
+			}
+			opp := opps[0]
+			if opp.BuyExchange != "binance" || opp.SellExchange != bus.FuturesTopic("binance") {
+				t.Fatalf("entry venues %s -> %s", opp.BuyExchange, opp.SellExchange)
+			}
+			if len(opp.Legs) != 2 {
+				t.Fatalf("got %d legs", len(opp.Legs))
+			}
+			spot, perp := opp.Legs[0], opp.Legs[1]
+			if spot.Exchange != "binance" || spot.Request.Symbol != "BTC/USDT" || spot.Request.Side != market.TradeSideBuy {
+				t.Fatalf("spot leg %+v", spot)
+			}
+			if perp.Exchange != "binance.futures" || perp.Request.Symbol != "BTCUSDT" || perp.Request.Side != market.TradeSideSell {
+				t.Fatalf("perp leg %+v", perp)
+			}
+			if spot.Request.Volume != 5 || perp.Request.Volume != 5 {
+				t.Fatalf("leg volumes %.8f / %.8f, want 5", spot.Request.Volume, perp.Request.Volume)
+			}
+			if opp.ProfitPercent < DefaultTradeWorkerConfig().CarryEntryAPR {
+				t.Fatalf("APR %.2f below entry threshold", opp.ProfitPercent)
+			}
+		})
+	}
+}
+
+// Позиция закрывается, когда ставка отрицательна, а базис сошелся: откуп контракта и продажа спота
+func TestCarryStrategyExit(t *testing.T) {
+	view := newTestView("binance")
+	view.books["binance:BTC/USDT"] = testBook("BTC/USDT", [][2]float64{{100, 5}}, [][2]float64{{100.1, 5}})
+	view.perps["binance:BTCUSDT"] = testBook("BTCUSDT", [][2]float64{{100, 5}}, [][2]float64{{100.1, 5}})
+	view.funding["binance:BTCUSDT"] = FundingSnapshot{FundingRate: -0.0001}
+
+	s := NewCarryStrategy(DefaultTradeWorkerConfig())
+	s.positions[carryKey("BTC/USDT", "binance", "binance")] = &CarryPosition{
+		ID: "task-1", Symbol: "BTC/USDT", PerpSymbol: "BTCUSDT", SpotExchange: "binance", PerpExchange: "binance",
+		Volume: 2, Status: CarryStatusOpen, OpenedAt: time.Now(),
+	}
+
+	opps := s.Scan(view)
+	if len(opps) != 1 {
+		t.Fatalf("got %d opportunities, want the exit only", len(opps))
+	}
+	opp := opps[0]
+	if opp.BuyExchange != "binance.futures" || opp.SellExchange != "binance" || opp.MaxVolume != 2 {
+		t.Fatalf("exit %s -> %s volume %.8f", opp.BuyExchange, opp.SellExchange, opp.MaxVolume)
+	}
+	if perp := opp.Legs[0]; perp.Exchange != "binance.futures" || perp.Request.Side != market.TradeSideBuy || perp.Request.Volume != 2 {
+		t.Fatalf("perp leg %+v", perp)
+	}
+	if spot := opp.Legs[1]; spot.Exchange != "binance" || spot.Request.Side != market.TradeSideSell || spot.Request.Volume != 2 {
+		t.Fatalf("spot leg %+v", spot)
+	}
+}
+
+// carryResult - результат исполнения с исполненными объемами ног
+func carryResult(taskID string, profit float64, legs ...executor.LegResult) executor.ExecutionResult {
+	return executor.ExecutionResult{TaskID: taskID, Legs: legs, RealizedProfit: profit}
+}
+
+func carryLeg(exchange string, side market.TradeSide, volume, price float64) executor.LegResult {
+	return executor.LegResult{
+		Leg:          executor.LegRequest{Exchange: exchange, Request: market.OrderRequest{Side: side}},
+		FilledVolume: volume,
+		AvgPrice:     price,
+	}
+}
+
+// Позиция открывается на хеджированный объем и закрывается исполненными выходами
+func TestCarryStrategyOnExecuted(t *testing.T) {
+	s := NewCarryStrategy(DefaultTradeWorkerConfig())
+	entry := ArbitrageOpportunity{Symbol: "BTC/USDT", BuyExchange: "binance", SellExchange: "bybit.futures"}
+	exit := ArbitrageOpportunity{Symbol: "BTC/USDT", BuyExchange: "bybit.futures", SellExchange: "binance"}
+
+	// Контракт исполнен не полностью: позиция - хеджированная часть
+	s.OnExecuted(entry, carryResult("task-1", -0.5,
+		carryLeg("binance", market.TradeSideBuy, 2, 100),
+		carryLeg("bybit.futures", market.TradeSideSell, 1.5, 101)))
+	positions := s.Positions()
+	if len(positions) != 1 {
+		t.Fatalf("got %d positions, want 1", len(positions))
+	}
+	p := positions[0]
+	if p.ID != "task-1" || p.PerpExchange != "bybit" || p.PerpSymbol != "BTCUSDT" || p.Volume != 1.5 || p.PerpEntryPrice != 101 {
+		t.Fatalf("opened position %+v", p)
+	}
+
+	s.OnExecuted(exit, carryResult("task-2", 0.2,
+		carryLeg("bybit.futures", market.TradeSideBuy, 1, 100.5),
+		carryLeg("binance", market.TradeSideSell, 1, 100.4)))
+	p = s.Positions()[0]
+	if p.Volume != 0.5 || p.ClosedVolume != 1 || math.Abs(p.SpreadPnL-(-0.3)) > 1e-9 {
+		t.Fatalf("after partial exit %+v", p)
+	}
+
+	s.OnExecuted(exit, carryResult("task-3", 0,
+		carryLeg("bybit.futures", market.TradeSideBuy, 0.5, 100.5),
+		carryLeg("binance", market.TradeSideSell, 0.5, 100.4)))
+	if len(s.Positions()) != 0 {
+		t.Fatalf("position still open: %+v", s.Positions())
+	}
+}
+
+// CarryPnL начисляется из расчетов биржи: платеж делится между позициями контракта по объему,
+// расчеты до открытия позиции и уже учтенные не начисляются
+func TestCarryStrategyAppliesFundingPayments(t *testing.T) {
+	opened := time.Date(2026, 3, 1, 7, 0, 0, 0, time.UTC)
+	s := NewCarryStrategy(DefaultTradeWorkerConfig())
+	a := &CarryPosition{Symbol: "BTC/USDT", PerpSymbol: "BTCUSDT", SpotExchange: "binance", PerpExchange: "binance", Volume: 1, OpenedAt: opened}
+	b := &CarryPosition{Symbol: "BTC/USDT", PerpSymbol: "BTCUSDT", SpotExchange: "bybit", PerpExchange: "binance", Volume: 3, OpenedAt: opened.Add(2 * time.Hour)}
+	s.positions[carryKey(a.Symbol, a.SpotExchange, a.PerpExchange)] = a
+	s.positions[carryKey(b.Symbol, b.SpotExchange, b.PerpExchange)] = b
+
+	payments := []market.FundingPayment{
+		{Symbol: "BTCUSDT", Amount: 2, Time: opened.Add(9 * time.Hour)},
+		{Symbol: "BTCUSDT", Amount: 5, Time: opened.Add(-time.Hour)},  // до открытия позиций
+		{Symbol: "BTCUSDT", Amount: 0.5, Time: opened.Add(time.Hour)}, // только позиция a
+	}
+	s.applyFunding("binance", "BTCUSDT", payments, opened.Add(10*time.Hour))
+	// Повторная загрузка тех же расчетов ничего не меняет
+	s.applyFunding("binance", "BTCUSDT", payments, opened.Add(11*time.Hour))
+
+	if math.Abs(a.CarryPnL-1) > 1e-9 || a.FundingEvents != 2 {
+		t.Fatalf("position a: carry PnL %.6f over %d fundings, want 1 over 2", a.CarryPnL, a.FundingEvents)
+	}
+	if math.Abs(b.CarryPnL-1.5) > 1e-9 || b.FundingEvents != 1 {
+		t.Fatalf("position b: carry PnL %.6f over %d fundings, want 1.5 over 1", b.CarryPnL, b.FundingEvents)
+	}
+	if !a.LastFundingAt.Equal(opened.Add(9 * time.Hour)) {
+		t.Fatalf("last funding %s", a.LastFundingAt)
+	}
+}
+
+// Scan запрашивает расчеты биржи контракта с последнего учтенного, а не оценивает их по ставке
+func TestCarryStrategyPollsFundingHistory(t *testing.T) {
+	opened := time.Now().Add(-12 * time.Hour)
+	settled := opened.Add(time.Hour)
+	history := &testFundingHistory{payments: []market.FundingPayment{{Symbol: "BTCUSDT", Amount: 0.75, Time: settled}}}
+
+	s := NewCarryStrategy(DefaultTradeWorkerConfig())
+	s.SetFundingHistory("binance", history)
+	s.positions[carryKey("BTC/USDT", "binance", "binance")] = &CarryPosition{
+		Symbol: "BTC/USDT", PerpSymbol: "BTCUSDT", SpotExchange: "binance", PerpExchange: "binance",
+		Volume: 1, Status: CarryStatusOpen, OpenedAt: opened,
+	}
+
+	view := newTestView("binance")
+	// Ставка сменила период: оценка по ставке дала бы другой CarryPnL
+	view.funding["binance:BTCUSDT"] = FundingSnapshot{FundingRate: 0.01, NextFundingTime: time.Now().Add(time.Hour)}
+	s.Scan(view)
+
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		if p := s.Positions()[0]; p.FundingEvents == 1 {
+			if math.Abs(p.CarryPnL-0.75) > 1e-9 || !p.LastFundingAt.Equal(settled) {
+				t.Fatalf("position after settlement %+v", p)
+			}
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("funding payments not applied")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	history.mu.Lock()
+	defer history.mu.Unlock()
+	if len(history.since) != 1 || !history.since[0].Equal(opened) {
+		t.Fatalf("funding history requested since %v, want once since opening", history.since)
+	}
+}
@@ -0,0 +1,277 @@
+package worker
+
+import (
+	"math"
+	"time"
+
+	"daemon-go/internal/market"
+)
+
+// InterExchangeStrategy - межбиржевой арбитраж: покупка символа по asks одной биржи
+// и продажа по bids другой с учетом глубины стаканов и комиссий
+type InterExchangeStrategy struct {
+	config *TradeWorkerConfig
+}
+
+// NewInterExchangeStrategy создает стратегию межбиржевого арбитража
+func NewInterExchangeStrategy(config *TradeWorkerConfig) *InterExchangeStrategy {
+	return &InterExchangeStrategy{config: config}
+}
+
+// Name возвращает имя стратегии
+func (s *InterExchangeStrategy) Name() string {
+	return "inter_exchange"
+}
+
+// OnBookUpdate не используется: стратегия пересчитывает все символы на каждом проходе
+func (s *InterExchangeStrategy) OnBookUpdate(exchange, symbol string) {}
+
+// Scan ищет арбитраж по всем символам
+func (s *InterExchangeStrategy) Scan(view MarketView) []ArbitrageOpportunity {
+	opportunities := make([]ArbitrageOpportunity, 0)
+	for _, symbol := range view.Symbols() {
+		opportunities = append(opportunities, s.findArbitrageForSymbol(view, symbol)...)
+	}
+	return opportunities
+}
+
+// findArbitrageForSymbol ищет арбитраж для конкретного символа
+func (s *InterExchangeStrategy) findArbitrageForSymbol(view MarketView, symbol string) []ArbitrageOpportunity {
+	opportunities := make([]ArbitrageOpportunity, 0)
+
+	// Получаем данные по всем биржам для этого символа
+	exchangeData := make(map[string]*ArbitrageData)
+
+	for _, exchange := range view.Exchanges() {
+		data := GetArbitrageData(view, exchange, symbol)
+		if data != nil {
+			exchangeData[exchange] = data
+		}
+	}
+
+	// Ищем арбитражные возможности между всеми парами бирж
+	exchanges := make([]string, 0, len(exchangeData))
+	for exchange := range exchangeData {
+		exchanges = append(exchanges, exchange)
+	}
+
+	for i, buyExchange := range exchanges {
+		for j, sellExchange := range exchanges {
+			if i >= j {
+				continue // Избегаем дублирования и самоарбитража
+			}
+
+			buyData := exchangeData[buyExchange]
+			sellData := exchangeData[sellExchange]
+
+			// Проверяем возможность арбитража: покупаем на buyExchange, продаем на sellExchange
+			if opp := s.calculateArbitrage(symbol, buyExchange, sellExchange, buyData, sellData); opp != nil {
+				opportunities = append(opportunities, *opp)
+			}
+
+			// Проверяем обратную возможность
+			if opp := s.calculateArbitrage(symbol, sellExchange, buyExchange, sellData, buyData); opp != nil {
+				opportunities = append(opportunities, *opp)
+			}
+		}
+	}
+
+	return opportunities
+}
+
+// ArbitrageData - данные для расчета арбитража
+type ArbitrageData struct {
+	BestBid   float64
+	BestAsk   float64
+	BidVolume float64
+	AskVolume float64
+	OrderBook *market.UnifiedOrderBook
+	BestPrice *market.UnifiedBestPrice
+	Bids      []market.PriceLevel // уровни стакана (или лучшая цена как единственный уровень)
+	Asks      []market.PriceLevel
+	Fee       market.FeeRate // комиссии пары на бирже
+	// Доступные балансы base и quote валют символа на бирже; -1 - балансы не отслеживаются
+	BaseBalance  float64
+	QuoteBalance float64
+}
+
+// GetArbitrageData получает лучшие цены символа на бирже: из стакана, если он есть, иначе из best price
+func GetArbitrageData(view MarketView, exchange, symbol string) *ArbitrageData {
+	data := &ArbitrageData{}
+
+	// Пробуем получить данные из best prices
+	if bestPrice := view.BestPrice(exchange, symbol); bestPrice != nil {
+		data.BestBid = bestPrice.BestBid
+		data.BestAsk = bestPrice.BestAsk
+		data.BidVolume = bestPrice.BidVolume
+		data.AskVolume = bestPrice.AskVolume
+		data.BestPrice = bestPrice
+	}
+
+	// Пробуем получить данные из orderbook
+	if orderBook := view.OrderBook(exchange, symbol); orderBook != nil {
+		data.OrderBook = orderBook
+		if len(orderBook.Bids) > 0 {
+			data.BestBid = orderBook.Bids[0].Price
+			data.BidVolume = orderBook.Bids[0].Volume
+		}
+		if len(orderBook.Asks) > 0 {
+			data.BestAsk = orderBook.Asks[0].Price
+			data.AskVolume = orderBook.Asks[0].Volume
+		}
+	}
+
+	// Проверяем, что у нас есть минимальные данные
+	if data.BestBid <= 0 || data.BestAsk <= 0 {
+		return nil
+	}
+
+	data.Bids, data.Asks = bookLevels(view, exchange, symbol)
+	data.Fee = view.Fees(exchange, symbol)
+	data.BaseBalance, data.QuoteBalance = -1, -1
+	if base, quote := symbolCurrencies(view, exchange, symbol); base != "" && quote != "" {
+		if available, ok := view.Balance(exchange, base); ok {
+			data.BaseBalance = available
+		}
+		if available, ok := view.Balance(exchange, quote); ok {
+			data.QuoteBalance = available
+		}
+	}
+	return data
+}
+
+// calculateArbitrage рассчитывает арбитражную возможность проходом по стаканам:
+// asks биржи покупки и bids биржи продажи сопоставляются уровень за уровнем, пока
+// маржинальная прибыль после комиссий taker не опустится ниже MinProfitPercent.
+// Так ProfitPercent отражает исполнимый объем, а не только верх стакана.
+// Объем ограничен MaxVolumeUSDT, балансом quote валюты на бирже покупки
+// и балансом base валюты на бирже продажи
+func (s *InterExchangeStrategy) calculateArbitrage(symbol, buyExchange, sellExchange string, buyData, sellData *ArbitrageData) *ArbitrageOpportunity {
+	// Быстрая проверка по лучшим ценам без комиссий
+	if sellData.BestBid <= buyData.BestAsk {
+		return nil
+	}
+
+	maxCost := s.config.MaxVolumeUSDT
+	if buyData.QuoteBalance >= 0 && (maxCost <= 0 || buyData.QuoteBalance < maxCost) {
+		maxCost = buyData.QuoteBalance
+		if maxCost <= 0 {
+			return nil
+		}
+	}
+	maxVolume := 0.0
+	if sellData.BaseBalance >= 0 {
+		maxVolume = sellData.BaseBalance
+		if maxVolume <= 0 {
+			return nil
+		}
+	}
+
+	fill := walkSpread(buyData.Asks, sellData.Bids, buyData.Fee.Taker, sellData.Fee.Taker,
+		s.config.MinProfitPercent, maxCost, maxVolume)
+	if fill.volume <= 0 {
+		return nil
+	}
+
+	// Проверяем минимальный объем
+	if fill.cost < s.config.MinVolumeUSDT {
+		return nil
+	}
+
+	profit := fill.revenue - fill.cost
+	profitPercent := profit / fill.cost * 100
+	if profitPercent < s.config.MinProfitPercent {
+		return nil
+	}
+
+	return &ArbitrageOpportunity{
+		Strategy:        s.Name(),
+		Symbol:          symbol,
+		BuyExchange:     buyExchange,
+		SellExchange:    sellExchange,
+		BuyPrice:        fill.buyLimit,
+		SellPrice:       fill.sellLimit,
+		Spread:          fill.sellLimit - fill.buyLimit,
+		ProfitPercent:   profitPercent,
+		BuyVolume:       buyData.AskVolume,
+		SellVolume:      sellData.BidVolume,
+		MaxVolume:       fill.volume,
+		Timestamp:       time.Now(),
+		BuyOrderBook:    buyData.OrderBook,
+		SellOrderBook:   sellData.OrderBook,
+		EstimatedProfit: profit,
+	}
+}
+
+// spreadFill - итог сопоставления стаканов покупки и продажи
+type spreadFill struct {
+	volume    float64 // объем в base валюте
+	cost      float64 // затраты на покупку с комиссией, quote
+	revenue   float64 // выручка от продажи за вычетом комиссии, quote
+	buyLimit  float64 // худшая цена покупки (лимит ордера)
+	sellLimit float64 // худшая цена продажи (лимит ордера)
+}
+
+// walkSpread сопоставляет asks и bids, пока маржинальная прибыль не ниже minProfitPercent,
+// затраты не превышают maxCost, а объем - maxVolume (0 - без ограничения)
+func walkSpread(asks, bids []market.PriceLevel, buyFee, sellFee, minProfitPercent, maxCost, maxVolume float64) spreadFill {
+	var fill spreadFill
+	i, j := 0, 0
+	var askLeft, bidLeft float64
+	if len(asks) > 0 {
+		askLeft = asks[0].Volume
+	}
+	if len(bids) > 0 {
+		bidLeft = bids[0].Volume
+	}
+
+	for i < len(asks) && j < len(bids) {
+		if askLeft <= 0 {
+			i++
+			if i < len(asks) {
+				askLeft = asks[i].Volume
+			}
+			continue
+		}
+		if bidLeft <= 0 {
+			j++
+			if j < len(bids) {
+				bidLeft = bids[j].Volume
+			}
+			continue
+		}
+
+		unitCost := asks[i].Price * (1 + buyFee)
+		unitRevenue := bids[j].Price * (1 - sellFee)
+		if unitCost <= 0 || (unitRevenue-unitCost)/unitCost*100 < minProfitPercent {
+			break
+		}
+
+		volume := math.Min(askLeft, bidLeft)
+		if maxCost > 0 && fill.cost+volume*unitCost > maxCost {
+			volume = (maxCost - fill.cost) / unitCost
+		}
+		if maxVolume > 0 && fill.volume+volume > maxVolume {
+			volume = maxVolume - fill.volume
+		}
+		if volume <= 0 {
+			break
+		}
+
+		fill.volume += volume
+		fill.cost += volume * unitCost
+		fill.revenue += volume * unitRevenue
+		fill.buyLimit = asks[i].Price
+		fill.sellLimit = bids[j].Price
+		askLeft -= volume
+		bidLeft -= volume
+
+		if maxCost > 0 && fill.cost >= maxCost*(1-1e-9) {
+			break
+		}
+		if maxVolume > 0 && fill.volume >= maxVolume*(1-1e-9) {
+			break
+		}
+	}
+	return fill
+}
@@ -0,0 +1,330 @@
+package worker
+
+import (
+	"strings"
+	"time"
+
+	"daemon-go/internal/market"
+	"daemon-go/internal/worker/executor"
+)
+
+// TriangularStrategy - внутрибиржевой треугольный арбитраж.
+// Для каждой биржи строится граф валют по Base/Quote подписанных пар и ищутся циклы
+// из трех обменов, начинающиеся и заканчивающиеся в стартовой валюте (USDT→BTC→ETH→USDT).
+// Цены считаются проходом по стакану с учетом комиссии taker пары; ноги размещаются
+// одновременно, поэтому на бирже должны быть остатки всех трех валют
+type TriangularStrategy struct {
+	config *TradeWorkerConfig
+}
+
+// NewTriangularStrategy создает стратегию треугольного арбитража
+func NewTriangularStrategy(config *TradeWorkerConfig) *TriangularStrategy {
+	return &TriangularStrategy{config: config}
+}
+
+// Name возвращает имя стратегии
+func (s *TriangularStrategy) Name() string {
+	return "triangular"
+}
+
+// OnBookUpdate не используется: граф перестраивается на каждом проходе
+func (s *TriangularStrategy) OnBookUpdate(exchange, symbol string) {}
+
+// conversionEdge - обмен from→to через пару symbol
+type conversionEdge struct {
+	symbol string
+	from   string
+	to     string
+	side   market.TradeSide // sell - продаем base за quote, buy - покупаем base за quote
+	levels []market.PriceLevel
+	fee    float64 // комиссия taker пары
+}
+
+// conversionLeg - результат прохода по стакану одного обмена
+type conversionLeg struct {
+	edge       conversionEdge
+	amountIn   float64 // потрачено валюты from
+	amountOut  float64 // получено валюты to за вычетом комиссии
+	volume     float64 // объем ордера в base валюте
+	limitPrice float64 // худшая цена, до которой дошли по стакану
+}
+
+// Scan ищет прибыльные циклы на каждой бирже
+func (s *TriangularStrategy) Scan(view MarketView) []ArbitrageOpportunity {
+	opportunities := make([]ArbitrageOpportunity, 0)
+	symbols := view.Symbols()
+
+	for _, exchange := range view.Exchanges() {
+		graph := s.buildGraph(view, exchange, symbols)
+		if len(graph) < 3 {
+			continue
+		}
+		balance := func(asset string) (float64, bool) { return view.Balance(exchange, asset) }
+		for _, start := range s.startCurrencies() {
+			// Лимиты объема заданы в USDT: без курса стартовой валюты к USDT циклы не оцениваются
+			rate, ok := usdtRate(graph, start)
+			if !ok {
+				continue
+			}
+			opportunities = append(opportunities, s.findCycles(exchange, start, rate, graph, balance)...)
+		}
+	}
+	return opportunities
+}
+
+// buildGraph строит ребра обмена валют по стаканам биржи: [from] -> ребра
+func (s *TriangularStrategy) buildGraph(view MarketView, exchange string, symbols []string) map[string][]conversionEdge {
+	graph := make(map[string][]conversionEdge)
+
+	for _, symbol := range symbols {
+		base, quote := symbolCurrencies(view, exchange, symbol)
+		if base == "" || quote == "" {
+			continue
+		}
+		bids, asks := bookLevels(view, exchange, symbol)
+		fee := view.Fees(exchange, symbol).Taker
+		if len(bids) > 0 {
+			graph[base] = append(graph[base], conversionEdge{symbol: symbol, from: base, to: quote, side: market.TradeSideSell, levels: bids, fee: fee})
+		}
+		if len(asks) > 0 {
+			graph[quote] = append(graph[quote], conversionEdge{symbol: symbol, from: quote, to: base, side: market.TradeSideBuy, levels: asks, fee: fee})
+		}
+	}
+	return graph
+}
+
+// findCycles перебирает циклы start→a→b→start и оценивает их по стаканам;
+// rate - цена единицы стартовой валюты в USDT
+func (s *TriangularStrategy) findCycles(exchange, start string, rate float64, graph map[string][]conversionEdge, balance func(asset string) (float64, bool)) []ArbitrageOpportunity {
+	opportunities := make([]ArbitrageOpportunity, 0)
+
+	for _, first := range graph[start] {
+		for _, second := range graph[first.to] {
+			if second.to == start || second.to == first.to {
+				continue
+			}
+			for _, third := range graph[second.to] {
+				if third.to != start {
+					continue
+				}
+				if opp := s.evaluateCycle(exchange, start, rate, []conversionEdge{first, second, third}, balance); opp != nil {
+					opportunities = append(opportunities, *opp)
+				}
+			}
+		}
+	}
+	return opportunities
+}
+
+// evaluateCycle подбирает объем цикла и возвращает возможность, если прибыль выше порога.
+// Объем ограничен MaxVolumeUSDT и глубиной стаканов; так как проход по стакану ухудшает цену,
+// дополнительно проверяются меньшие объемы и выбирается максимальная абсолютная прибыль.
+// Лимиты Min/MaxVolumeUSDT переводятся в стартовую валюту по курсу rate.
+// Ноги размещаются одновременно, поэтому каждая нога ограничена балансом своей валюты from
+func (s *TriangularStrategy) evaluateCycle(exchange, start string, rate float64, edges []conversionEdge, balance func(asset string) (float64, bool)) *ArbitrageOpportunity {
+	amount := s.config.MaxVolumeUSDT / rate
+	minAmount := s.config.MinVolumeUSDT / rate
+	if available, ok := balance(start); ok && available < amount {
+		amount = available
+	}
+	if amount <= 0 {
+		return nil
+	}
+
+	var best []conversionLeg
+	bestProfit := 0.0
+	for amount >= minAmount && amount > 0 {
+		legs, ok := s.simulateCycle(edges, amount)
+		if !ok {
+			amount /= 2
+			continue
+		}
+		// Глубины не хватило: legs[0].amountIn уже урезан до доступного объема
+		amount = legs[0].amountIn
+		if amount < minAmount {
+			break
+		}
+		if ratio := balanceRatio(legs, balance); ratio < 1-1e-6 {
+			amount *= ratio
+			continue
+		}
+
+		profit := legs[len(legs)-1].amountOut - amount
+		if profit > bestProfit && profit/amount*100 >= s.config.MinProfitPercent {
+			best, bestProfit = legs, profit
+		}
+		amount /= 2
+	}
+	if best == nil {
+		return nil
+	}
+
+	startAmount := best[0].amountIn
+	path := []string{start}
+	orderLegs := make([]executor.LegRequest, len(best))
+	for i, leg := range best {
+		path = append(path, leg.edge.to)
+		orderLegs[i] = executor.LegRequest{
+			Exchange: exchange,
+			Request: market.OrderRequest{
+				Symbol:    leg.edge.symbol,
+				Side:      leg.edge.side,
+				OrderType: market.OrderTypeLimit,
+				Price:     leg.limitPrice,
+				Volume:    leg.volume,
+			},
+		}
+	}
+
+	return &ArbitrageOpportunity{
+		Strategy:        s.Name(),
+		Symbol:          strings.Join(path, ">"),
+		BuyExchange:     exchange,
+		SellExchange:    exchange,
+		ProfitPercent:   bestProfit / startAmount * 100,
+		MaxVolume:       startAmount,
+		Timestamp:       time.Now(),
+		EstimatedProfit: bestProfit,
+		ProfitCurrency:  start,
+		Legs:            orderLegs,
+	}
+}
+
+// balanceRatio возвращает, во сколько раз нужно уменьшить цикл, чтобы каждой ноге
+// хватило баланса валюты from (1 - балансов достаточно или они не отслеживаются)
+func balanceRatio(legs []conversionLeg, balance func(asset string) (float64, bool)) float64 {
+	ratio := 1.0
+	for _, leg := range legs {
+		available, ok := balance(leg.edge.from)
+		if !ok || leg.amountIn <= 0 {
+			continue
+		}
+		if r := available / leg.amountIn; r < ratio {
+			ratio = r
+		}
+	}
+	return ratio
+}
+
+// simulateCycle проводит amount стартовой валюты через три обмена.
+// Если какой-то стакан не вмещает объем, стартовый объем пропорционально уменьшается;
+// из-за проскальзывания пропорция неточна, поэтому пересчет повторяется несколько раз
+func (s *TriangularStrategy) simulateCycle(edges []conversionEdge, amount float64) ([]conversionLeg, bool) {
+	for attempt := 0; attempt < 10; attempt++ {
+		legs := make([]conversionLeg, len(edges))
+		in := amount
+		scaled := false
+		for i, edge := range edges {
+			legs[i] = walkBook(edge, in)
+			if legs[i].amountIn <= 0 {
+				return nil, false
+			}
+			if legs[i].amountIn < in*(1-1e-6) {
+				// Ограничение глубиной: уменьшаем старт в той же пропорции и пересчитываем
+				amount *= legs[i].amountIn / in
+				scaled = true
+				break
+			}
+			in = legs[i].amountOut
+		}
+		if !scaled {
+			return legs, true
+		}
+	}
+	return nil, false
+}
+
+// walkBook тратит amountIn валюты from по уровням стакана ребра с учетом комиссии taker
+func walkBook(edge conversionEdge, amountIn float64) conversionLeg {
+	leg := conversionLeg{edge: edge}
+	remaining := amountIn
+
+	for _, level := range edge.levels {
+		if remaining <= 0 {
+			break
+		}
+		if level.Price <= 0 || level.Volume <= 0 {
+			continue
+		}
+
+		switch edge.side {
+		case market.TradeSideSell:
+			// Тратим base, получаем quote
+			take := level.Volume
+			if take > remaining {
+				take = remaining
+			}
+			remaining -= take
+			leg.volume += take
+			leg.amountOut += take * level.Price
+		default:
+			// Тратим quote, получаем base
+			cost := level.Volume * level.Price
+			if cost > remaining {
+				cost = remaining
+			}
+			remaining -= cost
+			leg.volume += cost / level.Price
+			leg.amountOut += cost / level.Price
+		}
+		leg.limitPrice = level.Price
+	}
+
+	leg.amountIn = amountIn - remaining
+	leg.amountOut *= 1 - edge.fee
+	return leg
+}
+
+// usdtRate возвращает цену единицы валюты currency в USDT по лучшим уровням пары с USDT на бирже
+// (середина спреда, если есть обе стороны); false - пары с USDT нет
+func usdtRate(graph map[string][]conversionEdge, currency string) (float64, bool) {
+	if currency == "USDT" {
+		return 1, true
+	}
+	var bid, ask float64
+	for _, edge := range graph[currency] {
+		if edge.to == "USDT" && edge.side == market.TradeSideSell && edge.levels[0].Price > 0 {
+			bid = edge.levels[0].Price
+		}
+	}
+	for _, edge := range graph["USDT"] {
+		if edge.to == currency && edge.side == market.TradeSideBuy && edge.levels[0].Price > 0 {
+			ask = edge.levels[0].Price
+		}
+	}
+	switch {
+	case bid > 0 && ask > 0:
+		return (bid + ask) / 2, true
+	case bid > 0:
+		return bid, true
+	case ask > 0:
+		return ask, true
+	}
+	return 0, false
+}
+
+// symbolCurrencies возвращает base и quote валюты символа на бирже
+func symbolCurrencies(view MarketView, exchange, symbol string) (string, string) {
+	var unified *market.UnifiedSymbol
+	if ob := view.OrderBook(exchange, symbol); ob != nil {
+		unified = ob.UnifiedSymbol
+	} else if bp := view.BestPrice(exchange, symbol); bp != nil {
+		unified = bp.UnifiedSymbol
+	}
+	if unified == nil {
+		parsed, err := market.ParseSymbol(symbol, "spot")
+		if err != nil {
+			return "", ""
+		}
+		unified = parsed
+	}
+	return unified.BaseCurrency, unified.QuoteCurrency
+}
+
+// startCurrencies возвращает валюты, с которых начинаются циклы
+func (s *TriangularStrategy) startCurrencies() []string {
+	if len(s.config.TriangularStartCurrencies) == 0 {
+		return []string{"USDT"}
+	}
+	return s.config.TriangularStartCurrencies
+}
@@ -0,0 +1,100 @@
+package worker
+
+import (
+	"math"
+	"testing"
+
+	"daemon-go/internal/market"
+)
+
+func TestTriangularStrategyScan(t *testing.T) {
+	const fee = 0.001
+	keep := (1 - fee) * (1 - fee) * (1 - fee)
+	// USDT→BTC по 100, BTC→ETH по 0.05, ETH→USDT по ethBid: 1 USDT -> ethBid/5 USDT без комиссий
+	tests := []struct {
+		name      string
+		start     string
+		ethBid    float64
+		ethDepth  float64
+		balances  map[string]float64
+		wantPath  string
+		wantStart float64 // объем цикла в стартовой валюте
+		wantLast  float64 // объем последней ноги в base валюте
+	}{
+		{
+			name: "profitable cycle at max volume", start: "USDT", ethBid: 5.2, ethDepth: 1e6,
+			wantPath: "USDT>BTC>ETH>USDT", wantStart: 1000, wantLast: 1000 / 100 * (1 - fee) / 0.05 * (1 - fee),
+		},
+		{
+			name: "fees eat the edge", start: "USDT", ethBid: 5.01, ethDepth: 1e6,
+		},
+		{
+			name: "limited by book depth", start: "USDT", ethBid: 5.2, ethDepth: 50,
+			wantPath: "USDT>BTC>ETH>USDT", wantStart: 50 * 0.05 * 100 / ((1 - fee) * (1 - fee)), wantLast: 50,
+		},
+		{
+			name: "limited by start balance", start: "USDT", ethBid: 5.2, ethDepth: 1e6,
+			balances: map[string]float64{"USDT": 500, "BTC": 100, "ETH": 1e6},
+			wantPath: "USDT>BTC>ETH>USDT", wantStart: 500, wantLast: 500 / 100 * (1 - fee) / 0.05 * (1 - fee),
+		},
+		{
+			name: "BTC start: USDT max volume converted at BTC price", start: "BTC", ethBid: 5.2, ethDepth: 1e6,
+			wantPath: "BTC>ETH>USDT>BTC", wantStart: 10, wantLast: 10 / 0.05 * (1 - fee) * 5.2 * (1 - fee) / 100,
+		},
+		{
+			name: "BTC start: depth below USDT min volume", start: "BTC", ethBid: 5.2, ethDepth: 5,
+		},
+		{
+			name: "start currency without USDT pair", start: "XRP", ethBid: 5.2, ethDepth: 1e6,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			view := newTestView("binance")
+			view.fee = fee
+			view.books["binance:BTC/USDT"] = testBook("BTC/USDT", nil, [][2]float64{{100, 1e6}})
+			view.books["binance:ETH/BTC"] = testBook("ETH/BTC", nil, [][2]float64{{0.05, 1e6}})
+			view.books["binance:ETH/USDT"] = testBook("ETH/USDT", [][2]float64{{tt.ethBid, tt.ethDepth}}, nil)
+			if tt.balances != nil {
+				view.balances = make(map[string]float64)
+				for asset, amount := range tt.balances {
+					view.balances["binance:"+asset] = amount
+				}
+			}
+			config := DefaultTradeWorkerConfig()
+			config.MinVolumeUSDT, config.MaxVolumeUSDT, config.MinProfitPercent = 100, 1000, 0.1
+			config.TriangularStartCurrencies = []string{tt.start}
+
+			opps := NewTriangularStrategy(config).Scan(view)
+
+			if tt.wantPath == "" {
+				if len(opps) != 0 {
+					t.Fatalf("unexpected opportunity %+v", opps[0])
+				}
+				return
+			}
+			if len(opps) != 1 {
+				t.Fatalf("got %d opportunities, want 1", len(opps))
+			}
+			opp := opps[0]
+			if opp.Symbol != tt.wantPath || opp.ProfitCurrency != tt.start || len(opp.Legs) != 3 {
+				t.Fatalf("opportunity %+v", opp)
+			}
+			if math.Abs(opp.MaxVolume-tt.wantStart) > 1e-6*tt.wantStart {
+				t.Errorf("start volume %.8f, want %.8f", opp.MaxVolume, tt.wantStart)
+			}
+			if last := opp.Legs[2].Request.Volume; math.Abs(last-tt.wantLast) > 1e-6*tt.wantLast {
+				t.Errorf("last leg volume %.8f, want %.8f", last, tt.wantLast)
+			}
+			wantProfit := opp.MaxVolume * (tt.ethBid/5*keep - 1)
+			if math.Abs(opp.EstimatedProfit-wantProfit) > 1e-6*opp.MaxVolume {
+				t.Errorf("profit %.8f, want %.8f", opp.EstimatedProfit, wantProfit)
+			}
+			for _, leg := range opp.Legs {
+				if leg.Exchange != "binance" || leg.Request.OrderType != market.OrderTypeLimit || leg.Request.Price <= 0 {
+					t.Errorf("leg %+v", leg)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,165 @@
+package worker
+
+import (
+	"sync"
+
+	"daemon-go/internal/db"
+	"daemon-go/internal/market"
+	"daemon-go/pkg/log"
+)
+
+// TradeGroups - общие TradeWorker записей TRADE по TYPE. Записи одного типа ищут одни и те же
+// сигналы на одних и тех же биржах, поэтому стратегия типа работает в одном TradeWorker:
+// сигнал исполняется один раз, а не в каждой записи. Лимиты группы - самые строгие из ее записей
+type TradeGroups struct {
+	mu     sync.Mutex
+	driver db.DBDriver
+	env    *TradingEnv // исполнение сигналов (nil - только поиск)
+	logger *log.Logger
+	groups map[int]*tradeGroup // [TRADE.TYPE]
+}
+
+// tradeGroup - записи TRADE одного типа и их общий TradeWorker
+type tradeGroup struct {
+	trades   map[int]db.TradeCase // [TRADE.ID]
+	merged   db.TradeCase         // настройки, с которыми создан worker
+	worker   *TradeWorker
+	strategy Strategy
+}
+
+// NewTradeGroups создает реестр групп; logger может быть nil — тогда используется log.New("trade_groups")
+func NewTradeGroups(driver db.DBDriver, logger *log.Logger) *TradeGroups {
+	if logger == nil {
+		logger = log.New("trade_groups")
+	}
+	return &TradeGroups{
+		driver: driver,
+		logger: logger,
+		groups: make(map[int]*tradeGroup),
+	}
+}
+
+// SetTradingEnv задает зависимости исполнения для TradeWorker, создаваемых после вызова
+func (g *TradeGroups) SetTradingEnv(env *TradingEnv) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.env = env
+}
+
+// Join добавляет запись в группу ее типа и возвращает стратегию группы (nil - тип не зарегистрирован).
+// Первая запись типа запускает TradeWorker; если лимиты группы ужесточились, worker пересоздается
+func (g *TradeGroups) Join(trade db.TradeCase) Strategy {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	group, ok := g.groups[trade.Type]
+	if !ok {
+		group = &tradeGroup{trades: make(map[int]db.TradeCase)}
+		g.groups[trade.Type] = group
+	}
+	group.trades[trade.ID] = trade
+	g.rebuild(group)
+	return group.strategy
+}
+
+// Leave убирает запись из группы; последняя запись типа останавливает TradeWorker
+func (g *TradeGroups) Leave(trade db.TradeCase) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	group, ok := g.groups[trade.Type]
+	if !ok {
+		return
+	}
+	delete(group.trades, trade.ID)
+	if len(group.trades) == 0 {
+		g.stop(group)
+		delete(g.groups, trade.Type)
+		return
+	}
+	g.rebuild(group)
+}
+
+// Worker возвращает общий TradeWorker типа (nil - группы нет или тип без стратегии)
+func (g *TradeGroups) Worker(tradeType int) *TradeWorker {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if group, ok := g.groups[tradeType]; ok {
+		return group.worker
+	}
+	return nil
+}
+
+// rebuild пересоздает TradeWorker группы, если настройки записей изменились (вызывается под g.mu)
+func (g *TradeGroups) rebuild(group *tradeGroup) {
+	merged := mergeTradeCases(group.trades)
+	if group.worker != nil && merged == group.merged {
+		return
+	}
+	g.stop(group)
+	group.merged = merged
+
+	config := DefaultTradeWorkerConfig()
+	strategy, err := NewStrategy(merged.Type, config)
+	if err != nil {
+		g.logger.Warn("Trade type %d: %v", merged.Type, err)
+		return
+	}
+
+	tw := NewTradeWorker(config)
+	tw.SetStrategies(strategy)
+	if g.driver != nil {
+		fees, err := LoadFeeSchedule(g.driver, market.FeeRate{Maker: config.MakerFeeRate, Taker: config.TakerFeeRate})
+		if err != nil {
+			g.logger.Warn("Trade type %d: default fees used: %v", merged.Type, err)
+		} else {
+			tw.SetFeeSchedule(fees)
+		}
+	}
+	if g.env != nil {
+		g.env.attach(tw, merged)
+		if err := g.env.attachCarry(strategy); err != nil {
+			g.logger.Error("Trade type %d: failed to load strategy journal: %v", merged.Type, err)
+		}
+	}
+	if err := tw.Start(); err != nil {
+		g.logger.Error("Trade type %d: failed to start trade worker: %v", merged.Type, err)
+		return
+	}
+
+	group.worker = tw
+	group.strategy = strategy
+	g.logger.Info("Trade type %d: trade worker started for %d TRADE records (strategy %s)",
+		merged.Type, len(group.trades), strategy.Name())
+}
+
+// stop останавливает TradeWorker группы (вызывается под g.mu)
+func (g *TradeGroups) stop(group *tradeGroup) {
+	if group.worker == nil {
+		return
+	}
+	_ = group.worker.Stop()
+	group.worker = nil
+	group.strategy = nil
+}
+
+// mergeTradeCases сводит настройки записей одного типа в самые строгие: наименьший ненулевой
+// MAX_AMOUNT_TRADE, FIN_PROTECTION и BBO_ONLY - если включены хотя бы в одной записи.
+// ID - наименьший в группе (по нему берется политика дисбаланса [hedge.<ID>])
+func mergeTradeCases(trades map[int]db.TradeCase) db.TradeCase {
+	var merged db.TradeCase
+	first := true
+	for _, trade := range trades {
+		if first || trade.ID < merged.ID {
+			merged.ID = trade.ID
+		}
+		merged.Type = trade.Type
+		if trade.MaxAmountTrade > 0 && (merged.MaxAmountTrade == 0 || trade.MaxAmountTrade < merged.MaxAmountTrade) {
+			merged.MaxAmountTrade = trade.MaxAmountTrade
+		}
+		merged.FinProtection = merged.FinProtection || trade.FinProtection
+		merged.BBOOnly = merged.BBOOnly || trade.BBOOnly
+		first = false
+	}
+	return merged
+}
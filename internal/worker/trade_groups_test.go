@@ -0,0 +1,56 @@
+package worker
+
+import (
+	"testing"
+
+	"daemon-go/internal/db"
+)
+
+// Записи одного TYPE работают на одном TradeWorker; он останавливается с последней записью
+func TestTradeGroupsShareWorkerPerType(t *testing.T) {
+	groups := NewTradeGroups(nil, nil)
+	first := db.TradeCase{ID: 1, Type: TradeTypeInterExchange}
+	second := db.TradeCase{ID: 2, Type: TradeTypeInterExchange}
+	other := db.TradeCase{ID: 3, Type: TradeTypeTriangular}
+
+	if groups.Join(first) == nil {
+		t.Fatal("no strategy for the inter-exchange type")
+	}
+	shared := groups.Worker(TradeTypeInterExchange)
+	groups.Join(second)
+	if got := groups.Worker(TradeTypeInterExchange); got != shared {
+		t.Fatal("second record of the same type got its own trade worker")
+	}
+	groups.Join(other)
+	if groups.Worker(TradeTypeTriangular) == shared {
+		t.Fatal("different types share a trade worker")
+	}
+
+	groups.Leave(second)
+	if groups.Worker(TradeTypeInterExchange) != shared || !shared.isActive() {
+		t.Fatal("trade worker stopped while a record of its type is still active")
+	}
+	groups.Leave(first)
+	if groups.Worker(TradeTypeInterExchange) != nil || shared.isActive() {
+		t.Fatal("trade worker still running after the last record left")
+	}
+	groups.Leave(other)
+}
+
+func TestMergeTradeCasesTakesStrictestLimits(t *testing.T) {
+	merged := mergeTradeCases(map[int]db.TradeCase{
+		7: {ID: 7, Type: 1, MaxAmountTrade: 500, BBOOnly: true},
+		4: {ID: 4, Type: 1, MaxAmountTrade: 0},
+		9: {ID: 9, Type: 1, MaxAmountTrade: 200, FinProtection: true},
+	})
+	want := db.TradeCase{ID: 4, Type: 1, MaxAmountTrade: 200, FinProtection: true, BBOOnly: true}
+	if merged != want {
+		t.Fatalf("merged %+v, want %+v", merged, want)
+	}
+}
+
+func (tw *TradeWorker) isActive() bool {
+	tw.mu.RLock()
+	defer tw.mu.RUnlock()
+	return tw.active
+}
@@ -16,6 +16,7 @@ type TradeMonitor struct {
 	workersMutex *sync.Mutex
 	stopChan     chan struct{}
 	pollInterval int
+	groups       *TradeGroups // общие TradeWorker записей по TRADE.TYPE
 }
 
 // NewTradeMonitor создает новый монитор торгов
@@ -26,6 +27,7 @@ func NewTradeMonitor(driver db.DBDriver, traderMap *map[int]*TraderWorker, worke
 		workersMutex: workersMutex,
 		stopChan:     stopChan,
 		pollInterval: pollInterval,
+		groups:       NewTradeGroups(driver, tradeMonitorLogger),
 	}
 }
 
@@ -34,6 +36,11 @@ func (tm *TradeMonitor) SetPollInterval(interval int) {
 	tm.pollInterval = interval
 }
 
+// SetTradingEnv задает зависимости исполнения для запускаемых трейдер-воркеров
+func (tm *TradeMonitor) SetTradingEnv(env *TradingEnv) {
+	tm.groups.SetTradingEnv(env)
+}
+
 // Start запускает мониторинг
 func (tm *TradeMonitor) Start() {
 	ticker := time.NewTicker(time.Duration(tm.pollInterval) * time.Second)
@@ -75,7 +82,7 @@ func (tm *TradeMonitor) checkTrades() {
 		if _, exists := (*tm.traderMap)[t.ID]; !exists {
 			tradeMonitorLogger.Debug("[DEBUG] checkTrades: starting new TraderWorker for id=%d", t.ID)
 			// Передаём tradeMonitorLogger как модульный логгер для трейдера
-			tw := NewTraderWorker(t, tm.driver, tradeMonitorLogger)
+			tw := NewTraderWorker(t, tm.driver, tradeMonitorLogger, tm.groups)
 			(*tm.traderMap)[t.ID] = tw
 			go tw.Start()
 			tradeMonitorLogger.Info("TradeMonitor: TraderWorker %d started", t.ID)
@@ -3,6 +3,8 @@ package worker
 import (
 	"daemon-go/internal/bus"
 	"daemon-go/internal/market"
+	"daemon-go/internal/worker/executor"
+	"errors"
 	"fmt"
 	"log"
 	"sync"
@@ -11,27 +13,31 @@ import (
 
 // ArbitrageOpportunity - структура для арбитражной возможности
 type ArbitrageOpportunity struct {
-	Symbol          string                   `json:"symbol"`           // унифицированный символ
-	UnifiedSymbol   *market.UnifiedSymbol    `json:"unified_symbol"`   // полная информация о символе
-	BuyExchange     string                   `json:"buy_exchange"`     // биржа для покупки
-	SellExchange    string                   `json:"sell_exchange"`    // биржа для продажи
-	BuyPrice        float64                  `json:"buy_price"`        // цена покупки
-	SellPrice       float64                  `json:"sell_price"`       // цена продажи
-	Spread          float64                  `json:"spread"`           // спред
-	ProfitPercent   float64                  `json:"profit_percent"`   // процент профита
-	BuyVolume       float64                  `json:"buy_volume"`       // доступный объем для покупки
-	SellVolume      float64                  `json:"sell_volume"`      // доступный объем для продажи
-	MaxVolume       float64                  `json:"max_volume"`       // максимальный объем сделки
-	Timestamp       time.Time                `json:"timestamp"`        // время обнаружения
-	BuyOrderBook    *market.UnifiedOrderBook `json:"buy_orderbook"`    // orderbook биржи покупки
-	SellOrderBook   *market.UnifiedOrderBook `json:"sell_orderbook"`   // orderbook биржи продажи
-	EstimatedProfit float64                  `json:"estimated_profit"` // оценочная прибыль в USDT
+	Strategy        string                   `json:"strategy"`                  // стратегия, выдавшая сигнал
+	Symbol          string                   `json:"symbol"`                    // унифицированный символ
+	UnifiedSymbol   *market.UnifiedSymbol    `json:"unified_symbol"`            // полная информация о символе
+	BuyExchange     string                   `json:"buy_exchange"`              // биржа для покупки
+	SellExchange    string                   `json:"sell_exchange"`             // биржа для продажи
+	BuyPrice        float64                  `json:"buy_price"`                 // цена покупки
+	SellPrice       float64                  `json:"sell_price"`                // цена продажи
+	Spread          float64                  `json:"spread"`                    // спред
+	ProfitPercent   float64                  `json:"profit_percent"`            // процент профита
+	BuyVolume       float64                  `json:"buy_volume"`                // доступный объем для покупки
+	SellVolume      float64                  `json:"sell_volume"`               // доступный объем для продажи
+	MaxVolume       float64                  `json:"max_volume"`                // максимальный объем сделки
+	Timestamp       time.Time                `json:"timestamp"`                 // время обнаружения
+	BuyOrderBook    *market.UnifiedOrderBook `json:"buy_orderbook"`             // orderbook биржи покупки
+	SellOrderBook   *market.UnifiedOrderBook `json:"sell_orderbook"`            // orderbook биржи продажи
+	EstimatedProfit float64                  `json:"estimated_profit"`          // оценочная прибыль в USDT
+	ProfitCurrency  string                   `json:"profit_currency,omitempty"` // валюта прибыли для многоногих сигналов
+	Legs            []executor.LegRequest    `json:"legs,omitempty"`            // ноги сделки; пусто - покупка и продажа Symbol
 }
 
 // TradeWorker - воркер для поиска и исполнения арбитражных сделок
 type TradeWorker struct {
 	mu             sync.RWMutex
 	orderBooks     map[string]map[string]*market.UnifiedOrderBook // [exchange][symbol]
+	bookEngine     *market.OrderBookEngine                        // локальные стаканы для применения diff обновлений
 	bestPrices     map[string]map[string]*market.UnifiedBestPrice // [exchange][symbol]
 	symbolRegistry *market.SymbolRegistry
 	opportunities  []ArbitrageOpportunity
@@ -39,7 +45,15 @@ type TradeWorker struct {
 	active         bool
 	stopChan       chan struct{}
 	messageBus     *bus.MessageBus
-	subscriptions  map[string]chan market.UnifiedMessage // [exchange] -> channel
+	subscriptions  map[string]chan market.UnifiedMessage          // [топик шины: exchange, exchange.futures] -> channel
+	executor       *executor.Executor                             // исполнитель ордеров (nil - только мониторинг)
+	inFlight       map[string]bool                                // [symbol:buy:sell] -> сделка в процессе исполнения
+	strategies     []Strategy                                     // стратегии поиска сигналов
+	fees           *market.FeeSchedule                            // комиссии бирж для расчета прибыли
+	balances       BalanceView                                    // балансы аккаунтов (nil - объем не ограничивается)
+	perpBooks      map[string]map[string]*market.UnifiedOrderBook // [exchange][symbol] стаканы бессрочных контрактов (BTCUSDT)
+	perpBookEngine *market.OrderBookEngine                        // локальные стаканы бессрочных контрактов
+	perpFunding    map[string]map[string]*FundingSnapshot         // [exchange][symbol] ставки и цены контрактов
 
 	// Статистика
 	totalOpportunities  int64
@@ -59,20 +73,42 @@ type TradeWorkerConfig struct {
 	AllowedExchanges   []string      `json:"allowed_exchanges"`   // разрешенные биржи
 	BlacklistedSymbols []string      `json:"blacklisted_symbols"` // заблокированные символы
 	RequiredSpreadBps  int           `json:"required_spread_bps"` // требуемый спред в базисных пунктах
+	MakerFeeRate       float64       `json:"maker_fee_rate"`      // комиссия maker по умолчанию (если нет в таблице комиссий)
+	TakerFeeRate       float64       `json:"taker_fee_rate"`      // комиссия taker по умолчанию (если нет в таблице комиссий)
+	// TriangularStartCurrencies - валюты, с которых начинаются треугольные циклы
+	TriangularStartCurrencies []string `json:"triangular_start_currencies"`
+	// HedgePolicy - действия при дисбалансе ног сделки; nil - политика исполнителя
+	HedgePolicy *executor.HedgePolicy `json:"hedge_policy,omitempty"`
+	// Cash-and-carry: доходность позиции (ставка финансирования + базис) в процентах годовых
+	CarryEntryAPR              float64 `json:"carry_entry_apr"`               // открыть позицию, если доходность выше
+	CarryExitAPR               float64 `json:"carry_exit_apr"`                // закрыть позицию, если доходность ниже
+	CarryFundingIntervalHours  float64 `json:"carry_funding_interval_hours"`  // период расчета ставки финансирования
+	CarryHoldingDays           float64 `json:"carry_holding_days"`            // горизонт, на который годовой базис раскладывается
+	CarryLeverage              float64 `json:"carry_leverage"`                // плечо шорта: маржа = стоимость / плечо
+	CarryUnwindSlippagePercent float64 `json:"carry_unwind_slippage_percent"` // допустимый убыток спреда при закрытии
 }
 
 // DefaultTradeWorkerConfig возвращает конфигурацию по умолчанию
 func DefaultTradeWorkerConfig() *TradeWorkerConfig {
 	return &TradeWorkerConfig{
-		MinProfitPercent:   0.1,   // 0.1%
-		MinVolumeUSDT:      100,   // $100
-		MaxVolumeUSDT:      10000, // $10,000
-		MaxOpportunities:   100,
-		UpdateInterval:     time.Second,
-		EnableExecution:    false, // по умолчанию только мониторинг
-		AllowedExchanges:   []string{"binance", "bybit", "kucoin", "htx", "coinex", "poloniex"},
-		BlacklistedSymbols: []string{},
-		RequiredSpreadBps:  10, // 0.1%
+		MinProfitPercent:           0.1,   // 0.1%
+		MinVolumeUSDT:              100,   // $100
+		MaxVolumeUSDT:              10000, // $10,000
+		MaxOpportunities:           100,
+		UpdateInterval:             time.Second,
+		EnableExecution:            false, // по умолчанию только мониторинг
+		AllowedExchanges:           []string{"binance", "bybit", "kucoin", "htx", "coinex", "poloniex"},
+		BlacklistedSymbols:         []string{},
+		RequiredSpreadBps:          10, // 0.1%
+		MakerFeeRate:               0.001,
+		TakerFeeRate:               0.001,
+		TriangularStartCurrencies:  []string{"USDT"},
+		CarryEntryAPR:              15, // 15% годовых
+		CarryExitAPR:               5,
+		CarryFundingIntervalHours:  8,
+		CarryHoldingDays:           7,
+		CarryLeverage:              1,
+		CarryUnwindSlippagePercent: 0.5,
 	}
 }
 
@@ -84,6 +120,7 @@ func NewTradeWorker(config *TradeWorkerConfig) *TradeWorker {
 
 	return &TradeWorker{
 		orderBooks:     make(map[string]map[string]*market.UnifiedOrderBook),
+		bookEngine:     market.NewOrderBookEngine(20),
 		bestPrices:     make(map[string]map[string]*market.UnifiedBestPrice),
 		symbolRegistry: market.NewSymbolRegistry(),
 		opportunities:  make([]ArbitrageOpportunity, 0),
@@ -91,9 +128,54 @@ func NewTradeWorker(config *TradeWorkerConfig) *TradeWorker {
 		stopChan:       make(chan struct{}),
 		messageBus:     bus.GetInstance(),
 		subscriptions:  make(map[string]chan market.UnifiedMessage),
+		inFlight:       make(map[string]bool),
+		strategies:     []Strategy{NewInterExchangeStrategy(config)},
+		fees:           market.NewFeeSchedule(market.FeeRate{Maker: config.MakerFeeRate, Taker: config.TakerFeeRate}),
+		perpBooks:      make(map[string]map[string]*market.UnifiedOrderBook),
+		perpBookEngine: market.NewOrderBookEngine(20),
+		perpFunding:    make(map[string]map[string]*FundingSnapshot),
 	}
 }
 
+// SetFeeSchedule задает комиссии бирж (например, загруженные LoadFeeSchedule)
+func (tw *TradeWorker) SetFeeSchedule(fees *market.FeeSchedule) {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+	tw.fees = fees
+}
+
+// SetBalances задает балансы аккаунтов: объем сигналов ограничивается доступной quote валютой
+// на бирже покупки и base валютой на бирже продажи
+func (tw *TradeWorker) SetBalances(balances BalanceView) {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+	tw.balances = balances
+}
+
+// SetStrategies заменяет набор стратегий trade worker (по умолчанию - межбиржевой арбитраж)
+func (tw *TradeWorker) SetStrategies(strategies ...Strategy) {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+	tw.strategies = strategies
+}
+
+// notifyStrategies передает стратегиям обновление стакана или лучшей цены
+func (tw *TradeWorker) notifyStrategies(exchange, symbol string) {
+	tw.mu.RLock()
+	strategies := tw.strategies
+	tw.mu.RUnlock()
+	for _, s := range strategies {
+		s.OnBookUpdate(exchange, symbol)
+	}
+}
+
+// SetExecutor задает исполнитель ордеров для trade worker
+func (tw *TradeWorker) SetExecutor(exec *executor.Executor) {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+	tw.executor = exec
+}
+
 // Start запускает trade worker
 func (tw *TradeWorker) Start() error {
 	tw.mu.Lock()
@@ -113,6 +195,12 @@ func (tw *TradeWorker) Start() error {
 		tw.subscriptions[exchange] = ch
 		go tw.messageProcessor(exchange, ch)
 		log.Printf("[TradeWorker] Subscribed to %s", exchange)
+
+		// Бессрочные контракты биржи публикуются отдельным топиком (стратегия cash-and-carry)
+		topic := bus.FuturesTopic(exchange)
+		futuresCh := tw.messageBus.Subscribe(topic, 100)
+		tw.subscriptions[topic] = futuresCh
+		go tw.messageProcessor(topic, futuresCh)
 	}
 
 	// Запускаем фоновый процесс поиска арбитража
@@ -170,6 +258,9 @@ func (tw *TradeWorker) messageProcessor(exchange string, ch chan market.UnifiedM
 
 // HandleMessage обрабатывает унифицированное сообщение (реализует MessageHandler)
 func (tw *TradeWorker) HandleMessage(msg market.UnifiedMessage) error {
+	if msg.UnifiedSymbol != nil && msg.UnifiedSymbol.MarketType == market.MarketTypeFutures {
+		return tw.handlePerpetual(msg)
+	}
 	switch msg.MessageType {
 	case market.MessageTypeOrderBook:
 		return tw.handleOrderBook(msg)
@@ -190,6 +281,22 @@ func (tw *TradeWorker) handleOrderBook(msg market.UnifiedMessage) error {
 		return fmt.Errorf("invalid orderbook data type")
 	}
 
+	// Снимок заменяет локальный стакан, diff применяется по уровням цен
+	orderBook, err := tw.bookEngine.Apply(msg.Exchange, orderBook)
+	if err != nil {
+		// Стакан поврежден или еще не получил снимок: убираем его из расчета арбитража до ресинхронизации
+		tw.mu.Lock()
+		delete(tw.orderBooks[msg.Exchange], msg.Symbol)
+		tw.mu.Unlock()
+		if errors.Is(err, market.ErrOrderBookInvalidated) {
+			return nil
+		}
+		if errors.Is(err, market.ErrChecksumMismatch) {
+			log.Printf("[TradeWorker] Checksum mismatch for %s %s, orderbook dropped until resync", msg.Exchange, msg.Symbol)
+		}
+		return err
+	}
+
 	tw.mu.Lock()
 	if tw.orderBooks[msg.Exchange] == nil {
 		tw.orderBooks[msg.Exchange] = make(map[string]*market.UnifiedOrderBook)
@@ -197,6 +304,7 @@ func (tw *TradeWorker) handleOrderBook(msg market.UnifiedMessage) error {
 	tw.orderBooks[msg.Exchange][msg.Symbol] = &orderBook
 	tw.mu.Unlock()
 
+	tw.notifyStrategies(msg.Exchange, msg.Symbol)
 	return nil
 }
 
@@ -214,6 +322,51 @@ func (tw *TradeWorker) handleBestPrice(msg market.UnifiedMessage) error {
 	tw.bestPrices[msg.Exchange][msg.Symbol] = &bestPrice
 	tw.mu.Unlock()
 
+	tw.notifyStrategies(msg.Exchange, msg.Symbol)
+	return nil
+}
+
+// handlePerpetual сохраняет стакан, ставку финансирования, маркировочную и индексную цены
+// бессрочного контракта в отдельные кэши, чтобы спотовые стратегии их не видели
+func (tw *TradeWorker) handlePerpetual(msg market.UnifiedMessage) error {
+	if msg.MessageType == market.MessageTypeOrderBook {
+		orderBook, ok := msg.Data.(market.UnifiedOrderBook)
+		if !ok {
+			return fmt.Errorf("invalid orderbook data type")
+		}
+		orderBook, err := tw.perpBookEngine.Apply(msg.Exchange, orderBook)
+		tw.mu.Lock()
+		defer tw.mu.Unlock()
+		if err != nil {
+			delete(tw.perpBooks[msg.Exchange], msg.Symbol)
+			if errors.Is(err, market.ErrOrderBookInvalidated) {
+				return nil
+			}
+			return err
+		}
+		if tw.perpBooks[msg.Exchange] == nil {
+			tw.perpBooks[msg.Exchange] = make(map[string]*market.UnifiedOrderBook)
+		}
+		tw.perpBooks[msg.Exchange][msg.Symbol] = &orderBook
+		return nil
+	}
+
+	switch msg.MessageType {
+	case market.MessageTypeFundingRate, market.MessageTypeMarkPrice, market.MessageTypeIndexPrice:
+	default:
+		return nil
+	}
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+	if tw.perpFunding[msg.Exchange] == nil {
+		tw.perpFunding[msg.Exchange] = make(map[string]*FundingSnapshot)
+	}
+	snapshot, ok := tw.perpFunding[msg.Exchange][msg.Symbol]
+	if !ok {
+		snapshot = &FundingSnapshot{Exchange: msg.Exchange, Symbol: msg.Symbol}
+		tw.perpFunding[msg.Exchange][msg.Symbol] = snapshot
+	}
+	snapshot.apply(msg)
 	return nil
 }
 
@@ -241,23 +394,33 @@ func (tw *TradeWorker) arbitrageLoop() {
 	}
 }
 
-// findArbitrageOpportunities ищет арбитражные возможности
+// findArbitrageOpportunities ищет арбитражные возможности и запускает их исполнение
 func (tw *TradeWorker) findArbitrageOpportunities() {
-	tw.mu.RLock()
-	defer tw.mu.RUnlock()
+	opportunities := tw.ScanOpportunities()
 
-	newOpportunities := make([]ArbitrageOpportunity, 0)
+	// Исполняем сделки если включено
+	if !tw.config.EnableExecution {
+		return
+	}
+	for _, opp := range opportunities {
+		go tw.executeTrade(opp)
+	}
+}
 
-	// Получаем все символы
-	symbols := tw.getAllSymbols()
+// ScanOpportunities выполняет один проход поиска арбитража по текущим стаканам
+// и сохраняет найденные возможности, не исполняя их
+func (tw *TradeWorker) ScanOpportunities() []ArbitrageOpportunity {
+	tw.mu.RLock()
+	newOpportunities := make([]ArbitrageOpportunity, 0)
 
-	for _, symbol := range symbols {
-		opportunities := tw.findArbitrageForSymbol(symbol)
-		newOpportunities = append(newOpportunities, opportunities...)
+	// Каждая стратегия просматривает текущие кэши стаканов
+	view := tradeWorkerView{tw: tw}
+	for _, strategy := range tw.strategies {
+		newOpportunities = append(newOpportunities, strategy.Scan(view)...)
 	}
+	tw.mu.RUnlock()
 
 	// Обновляем список возможностей
-	tw.mu.RUnlock()
 	tw.mu.Lock()
 	tw.opportunities = newOpportunities
 	tw.totalOpportunities += int64(len(newOpportunities))
@@ -265,22 +428,18 @@ func (tw *TradeWorker) findArbitrageOpportunities() {
 		tw.lastOpportunityTime = time.Now()
 	}
 	tw.mu.Unlock()
-	tw.mu.RLock()
 
 	// Логируем найденные возможности
 	for _, opp := range newOpportunities {
-		log.Printf("[ARBITRAGE] %s: Buy %s@%.8f → Sell %s@%.8f | Profit: %.4f%% | Volume: $%.2f",
-			opp.Symbol,
+		log.Printf("[ARBITRAGE] %s %s: Buy %s@%.8f → Sell %s@%.8f | Profit: %.4f%% | Volume: $%.2f",
+			opp.Strategy, opp.Symbol,
 			opp.BuyExchange, opp.BuyPrice,
 			opp.SellExchange, opp.SellPrice,
 			opp.ProfitPercent,
 			opp.EstimatedProfit)
-
-		// Исполняем сделку если включено
-		if tw.config.EnableExecution {
-			go tw.executeTrade(opp)
-		}
 	}
+
+	return newOpportunities
 }
 
 // getAllSymbols возвращает все уникальные символы
@@ -323,182 +482,122 @@ func (tw *TradeWorker) isSymbolBlacklisted(symbol string) bool {
 	return false
 }
 
-// findArbitrageForSymbol ищет арбитраж для конкретного символа
-func (tw *TradeWorker) findArbitrageForSymbol(symbol string) []ArbitrageOpportunity {
-	opportunities := make([]ArbitrageOpportunity, 0)
-
-	// Получаем данные по всем биржам для этого символа
-	exchangeData := make(map[string]*ArbitrageData)
-
-	for _, exchange := range tw.config.AllowedExchanges {
-		data := tw.getArbitrageDataForExchange(exchange, symbol)
-		if data != nil {
-			exchangeData[exchange] = data
-		}
-	}
-
-	// Ищем арбитражные возможности между всеми парами бирж
-	exchanges := make([]string, 0, len(exchangeData))
-	for exchange := range exchangeData {
-		exchanges = append(exchanges, exchange)
-	}
-
-	for i, buyExchange := range exchanges {
-		for j, sellExchange := range exchanges {
-			if i >= j {
-				continue // Избегаем дублирования и самоарбитража
-			}
-
-			buyData := exchangeData[buyExchange]
-			sellData := exchangeData[sellExchange]
-
-			// Проверяем возможность арбитража: покупаем на buyExchange, продаем на sellExchange
-			if opp := tw.calculateArbitrage(symbol, buyExchange, sellExchange, buyData, sellData); opp != nil {
-				opportunities = append(opportunities, *opp)
-			}
-
-			// Проверяем обратную возможность
-			if opp := tw.calculateArbitrage(symbol, sellExchange, buyExchange, sellData, buyData); opp != nil {
-				opportunities = append(opportunities, *opp)
-			}
-		}
-	}
-
-	return opportunities
-}
-
-// ArbitrageData - данные для расчета арбитража
-type ArbitrageData struct {
-	BestBid   float64
-	BestAsk   float64
-	BidVolume float64
-	AskVolume float64
-	OrderBook *market.UnifiedOrderBook
-	BestPrice *market.UnifiedBestPrice
+// ExecuteOpportunity синхронно исполняет арбитражную возможность через executor.
+// Возвращает nil, если сделка не размещалась (нет executor или она уже исполняется)
+func (tw *TradeWorker) ExecuteOpportunity(opportunity ArbitrageOpportunity) *executor.ExecutionResult {
+	return tw.executeTrade(opportunity)
 }
 
-// getArbitrageDataForExchange получает данные для арбитража с биржи
-func (tw *TradeWorker) getArbitrageDataForExchange(exchange, symbol string) *ArbitrageData {
-	data := &ArbitrageData{}
-
-	// Пробуем получить данные из best prices
-	if bestPrice, exists := tw.bestPrices[exchange][symbol]; exists {
-		data.BestBid = bestPrice.BestBid
-		data.BestAsk = bestPrice.BestAsk
-		data.BidVolume = bestPrice.BidVolume
-		data.AskVolume = bestPrice.AskVolume
-		data.BestPrice = bestPrice
-	}
-
-	// Пробуем получить данные из orderbook
-	if orderBook, exists := tw.orderBooks[exchange][symbol]; exists {
-		data.OrderBook = orderBook
-		if len(orderBook.Bids) > 0 {
-			data.BestBid = orderBook.Bids[0].Price
-			data.BidVolume = orderBook.Bids[0].Volume
-		}
-		if len(orderBook.Asks) > 0 {
-			data.BestAsk = orderBook.Asks[0].Price
-			data.AskVolume = orderBook.Asks[0].Volume
-		}
-	}
-
-	// Проверяем, что у нас есть минимальные данные
-	if data.BestBid <= 0 || data.BestAsk <= 0 {
-		return nil
-	}
-
-	return data
-}
-
-// calculateArbitrage рассчитывает арбитражную возможность
-func (tw *TradeWorker) calculateArbitrage(symbol, buyExchange, sellExchange string, buyData, sellData *ArbitrageData) *ArbitrageOpportunity {
-	// Цена покупки - лучший ask на бирже покупки
-	buyPrice := buyData.BestAsk
-	// Цена продажи - лучший bid на бирже продажи
-	sellPrice := sellData.BestBid
-
-	// Проверяем, что есть прибыль
-	if sellPrice <= buyPrice {
-		return nil
-	}
-
-	spread := sellPrice - buyPrice
-	profitPercent := (spread / buyPrice) * 100
-
-	// Проверяем минимальную прибыльность
-	if profitPercent < tw.config.MinProfitPercent {
-		return nil
-	}
+// executeTrade исполняет арбитражную сделку: покупка и продажа размещаются параллельно
+// через executor, статистика обновляется по фактическому результату
+func (tw *TradeWorker) executeTrade(opportunity ArbitrageOpportunity) *executor.ExecutionResult {
+	key := opportunity.Symbol + ":" + opportunity.BuyExchange + ":" + opportunity.SellExchange
 
-	// Рассчитываем максимальный объем
-	maxVolume := tw.calculateMaxVolume(buyData, sellData, buyPrice, sellPrice)
-	if maxVolume <= 0 {
+	tw.mu.Lock()
+	exec := tw.executor
+	if exec == nil {
+		tw.mu.Unlock()
+		log.Printf("[TradeWorker] Execution enabled but no executor configured, skipping %s", opportunity.Symbol)
 		return nil
 	}
-
-	// Рассчитываем оценочную прибыль в USDT
-	estimatedProfit := maxVolume * spread
-
-	// Проверяем минимальный объем
-	volumeUSDT := maxVolume * buyPrice
-	if volumeUSDT < tw.config.MinVolumeUSDT {
+	if tw.inFlight[key] {
+		tw.mu.Unlock()
 		return nil
 	}
+	tw.inFlight[key] = true
+	tw.mu.Unlock()
 
-	// Ограничиваем максимальный объем
-	if volumeUSDT > tw.config.MaxVolumeUSDT {
-		maxVolume = tw.config.MaxVolumeUSDT / buyPrice
-		estimatedProfit = maxVolume * spread
-	}
-
-	return &ArbitrageOpportunity{
-		Symbol:          symbol,
-		BuyExchange:     buyExchange,
-		SellExchange:    sellExchange,
-		BuyPrice:        buyPrice,
-		SellPrice:       sellPrice,
-		Spread:          spread,
-		ProfitPercent:   profitPercent,
-		BuyVolume:       buyData.AskVolume,
-		SellVolume:      sellData.BidVolume,
-		MaxVolume:       maxVolume,
-		Timestamp:       time.Now(),
-		BuyOrderBook:    buyData.OrderBook,
-		SellOrderBook:   sellData.OrderBook,
-		EstimatedProfit: estimatedProfit,
+	defer func() {
+		tw.mu.Lock()
+		delete(tw.inFlight, key)
+		tw.mu.Unlock()
+	}()
+
+	task := executor.Task{
+		Symbol:         opportunity.Symbol,
+		ExpectedProfit: opportunity.EstimatedProfit,
+		ProfitCurrency: opportunity.ProfitCurrency,
+		CreatedAt:      time.Now(),
+		Legs:           opportunity.Legs,
+		Hedge:          tw.config.HedgePolicy,
+	}
+	if len(task.Legs) == 0 {
+		task.Legs = []executor.LegRequest{
+			{
+				Exchange: opportunity.BuyExchange,
+				Request: market.OrderRequest{
+					Symbol:    opportunity.Symbol,
+					Side:      market.TradeSideBuy,
+					OrderType: market.OrderTypeLimit,
+					Price:     opportunity.BuyPrice,
+					Volume:    opportunity.MaxVolume,
+				},
+			},
+			{
+				Exchange: opportunity.SellExchange,
+				Request: market.OrderRequest{
+					Symbol:    opportunity.Symbol,
+					Side:      market.TradeSideSell,
+					OrderType: market.OrderTypeLimit,
+					Price:     opportunity.SellPrice,
+					Volume:    opportunity.MaxVolume,
+				},
+			},
+		}
 	}
-}
-
-// calculateMaxVolume рассчитывает максимальный объем для арбитража
-func (tw *TradeWorker) calculateMaxVolume(buyData, sellData *ArbitrageData, buyPrice, sellPrice float64) float64 {
-	// Лимитируем объемом доступным для покупки
-	maxBuyVolume := buyData.AskVolume
-	// Лимитируем объемом доступным для продажи
-	maxSellVolume := sellData.BidVolume
 
-	// Берем минимум
-	maxVolume := maxBuyVolume
-	if maxSellVolume < maxVolume {
-		maxVolume = maxSellVolume
+	// Ноги без шлюза не исполняются: сделка с частью ног оставила бы открытую позицию
+	// (например, спот без хеджа бессрочным контрактом у cash-and-carry)
+	for _, leg := range task.Legs {
+		if !exec.HasGateway(leg.Exchange) {
+			log.Printf("[TradeWorker] No order gateway for %s, skipping %s %s", leg.Exchange, opportunity.Strategy, opportunity.Symbol)
+			return nil
+		}
 	}
 
-	return maxVolume
-}
-
-// executeTrade исполняет арбитражную сделку (заглушка)
-func (tw *TradeWorker) executeTrade(opportunity ArbitrageOpportunity) {
-	// TODO: Реализовать исполнение сделок через exchange adapters
 	log.Printf("[TradeWorker] EXECUTING TRADE: %s %.6f %s→%s (Profit: %.4f%%)",
 		opportunity.Symbol, opportunity.MaxVolume,
 		opportunity.BuyExchange, opportunity.SellExchange,
 		opportunity.ProfitPercent)
 
+	result := exec.Execute(task)
+
+	log.Printf("[TradeWorker] TRADE %s %s: realized profit %.6f (expected %.6f)",
+		result.TaskID, result.Status, result.RealizedProfit, result.ExpectedProfit)
+
 	// Обновляем статистику
+	if result.Status == executor.ExecutionStatusFailed {
+		return &result
+	}
 	tw.mu.Lock()
 	tw.executedTrades++
-	tw.totalProfit += opportunity.EstimatedProfit
+	tw.totalProfit += result.RealizedProfit
 	tw.mu.Unlock()
+	tw.notifyExecution(opportunity, result)
+	return &result
+}
+
+// notifyExecution передает итог исполнения стратегии, выдавшей сигнал, если она его отслеживает
+func (tw *TradeWorker) notifyExecution(opportunity ArbitrageOpportunity, result executor.ExecutionResult) {
+	tw.mu.RLock()
+	strategies := tw.strategies
+	tw.mu.RUnlock()
+	for _, s := range strategies {
+		if observer, ok := s.(ExecutionObserver); ok && s.Name() == opportunity.Strategy {
+			observer.OnExecuted(opportunity, result)
+		}
+	}
+}
+
+// OrderBook возвращает последний стакан символа на бирже (источник стаканов для риск-движка);
+// для топика bus.FuturesTopic - стакан бессрочного контракта
+func (tw *TradeWorker) OrderBook(exchange, symbol string) *market.UnifiedOrderBook {
+	tw.mu.RLock()
+	defer tw.mu.RUnlock()
+	if perpExchange, ok := bus.FuturesExchange(exchange); ok {
+		return tw.perpBooks[perpExchange][symbol]
+	}
+	return tw.orderBooks[exchange][symbol]
 }
 
 // GetOpportunities возвращает текущие арбитражные возможности
@@ -1,47 +1,56 @@
 package worker
 
 import (
-	"time"
-
 	"daemon-go/internal/db"
 	"daemon-go/pkg/log"
 )
 
+// TraderWorker - воркер записи TRADE: на время работы записывает ее в группу TRADE.TYPE,
+// стратегия которой ищет сигналы на общем для записей этого типа TradeWorker
 type TraderWorker struct {
 	Trade  db.TradeCase
 	DB     db.DBDriver
+	groups *TradeGroups
 	active bool
 	stop   chan struct{}
 	Logger *log.Logger
 }
 
 // NewTraderWorker создает нового трейдер-воркера
-// logger может быть nil — тогда используется log.New("trader")
-func NewTraderWorker(trade db.TradeCase, driver db.DBDriver, logger *log.Logger) *TraderWorker {
+// logger может быть nil — тогда используется log.New("trader");
+// groups может быть nil — тогда запись получает собственную группу без исполнения сигналов
+func NewTraderWorker(trade db.TradeCase, driver db.DBDriver, logger *log.Logger, groups *TradeGroups) *TraderWorker {
 	if logger == nil {
 		logger = log.New("trader")
 	}
+	if groups == nil {
+		groups = NewTradeGroups(driver, logger)
+	}
+
 	return &TraderWorker{
 		Trade:  trade,
 		DB:     driver,
+		groups: groups,
 		active: false,
 		stop:   make(chan struct{}),
 		Logger: logger,
 	}
 }
 
-// Start запускает воркер
+// Start запускает воркер и блокируется до Stop
 func (tw *TraderWorker) Start() {
 	tw.active = true
-	tw.Logger.Info("TraderWorker %d started", tw.Trade.ID)
+	if strategy := tw.groups.Join(tw.Trade); strategy != nil {
+		tw.Logger.Info("TraderWorker %d started (type %d, strategy %s)", tw.Trade.ID, tw.Trade.Type, strategy.Name())
+	} else {
+		tw.Logger.Info("TraderWorker %d started (type %d, no strategy)", tw.Trade.ID, tw.Trade.Type)
+	}
 	defer func() {
+		tw.groups.Leave(tw.Trade)
 		tw.active = false
 		tw.Logger.Info("TraderWorker %d stopped", tw.Trade.ID)
 	}()
 
-	ticker := time.NewTicker(5 * time.Second) // пример работы воркера
-	defer ticker.Stop()
-
 	<-tw.stop
 }
 
@@ -0,0 +1,99 @@
+package worker
+
+import (
+	"daemon-go/internal/db"
+	"daemon-go/internal/risk"
+	"daemon-go/internal/worker/executor"
+)
+
+// TradingEnv - общие зависимости исполнения трейдер-воркеров, собранные Manager.StartWork:
+// шлюзы ордеров аккаунтов бирж, настройки исполнителя и лимиты риск-менеджмента. Общий
+// TradeWorker каждого TRADE.TYPE (TradeGroups) создает по ним собственный исполнитель и
+// риск-движок; без TradingEnv воркеры только ищут сигналы
+type TradingEnv struct {
+	EnableExecution bool
+	Executor        executor.Config
+	Gateways        map[string]executor.OrderGateway // [exchange] торговые адаптеры аккаунтов
+	Risk            *risk.Limits                     // лимиты [risk] (nil - без риск-менеджмента)
+	SharedRisk      *risk.Engine                     // общий риск-движок: лимиты Risk.Global по всем воркерам
+	KillSwitch      *risk.KillSwitch                 // глобальный kill switch для всех риск-движков
+	Balances        AccountBalances                  // балансы аккаунтов (nil - объем не ограничивается)
+	Orders          executor.OrderObserver           // журнал ордеров (orders.Manager)
+	SymbolRules     executor.SymbolRules             // торговые правила символов (exchange.SymbolInfoCache)
+	Imbalances      executor.ImbalanceRecorder       // журнал дисбаланса ног LEG_IMBALANCE
+	TradeHedges     map[int]executor.HedgePolicy     // [TRADE.ID] политики дисбаланса; иначе Executor.Hedge
+	CarryJournal    CarryJournal                     // журнал позиций cash-and-carry CARRY_POSITION
+	FundingHistory  map[string]FundingHistory        // [exchange] расчеты финансирования аккаунтов бессрочных контрактов
+}
+
+// AccountBalances - балансы аккаунтов (balance.Service): ограничивают объем сигналов
+// и резервируются под размещаемые ордера
+type AccountBalances interface {
+	BalanceView
+	executor.PreTradeCheck
+}
+
+// newExecutor создает исполнитель трейдер-воркера со шлюзами аккаунтов
+func (env *TradingEnv) newExecutor() *executor.Executor {
+	config := env.Executor
+	exec := executor.NewExecutor(&config)
+	for exchange, gateway := range env.Gateways {
+		exec.RegisterGateway(exchange, gateway)
+	}
+	if env.Orders != nil {
+		exec.SetOrderObserver(env.Orders)
+	}
+	if env.SymbolRules != nil {
+		exec.SetSymbolRules(env.SymbolRules)
+	}
+	if env.Imbalances != nil {
+		exec.SetImbalanceRecorder(env.Imbalances)
+	}
+	return exec
+}
+
+// attach подключает исполнение к TradeWorker группы: лимиты [risk] ужесточаются
+// сведенными настройками записей TRADE (mergeTradeCases), стаканы для проверок цены берутся из самого TradeWorker,
+// политика дисбаланса - из секции [hedge.<ID>] записи, если она задана.
+// Ордер проходит риск-движок воркера (лимиты ордера), общий риск-движок (лимиты бирж и аккаунтов),
+// затем резервирование баланса
+func (env *TradingEnv) attach(tw *TradeWorker, trade db.TradeCase) {
+	tw.config.EnableExecution = env.EnableExecution
+	if policy, ok := env.TradeHedges[trade.ID]; ok {
+		tw.config.HedgePolicy = &policy
+	}
+	exec := env.newExecutor()
+
+	var checks executor.CheckChain
+	if env.Risk != nil {
+		riskEngine := risk.NewEngine(env.Risk.WithTrade(trade.Trade()).PerTrade(), tw)
+		riskEngine.SetKillSwitch(env.KillSwitch)
+		checks = append(checks, riskEngine)
+	}
+	if env.SharedRisk != nil {
+		checks = append(checks, env.SharedRisk)
+	}
+	if env.Balances != nil {
+		tw.SetBalances(env.Balances)
+		checks = append(checks, env.Balances)
+	}
+	if len(checks) > 0 {
+		exec.SetPreTradeCheck(checks)
+	}
+	tw.SetExecutor(exec)
+}
+
+// attachCarry подключает к стратегии cash-and-carry расчеты финансирования бирж и журнал позиций
+func (env *TradingEnv) attachCarry(strategy Strategy) error {
+	carry, ok := strategy.(*CarryStrategy)
+	if !ok {
+		return nil
+	}
+	for exchange, history := range env.FundingHistory {
+		carry.SetFundingHistory(exchange, history)
+	}
+	if env.CarryJournal == nil {
+		return nil
+	}
+	return carry.SetJournal(env.CarryJournal)
+}